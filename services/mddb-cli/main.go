@@ -2,11 +2,18 @@ package main
 
 import (
 	"bytes"
+	"crypto/sha256"
+	"crypto/tls"
+	"crypto/x509"
+	"encoding/hex"
 	"encoding/json"
 	"fmt"
 	"io"
 	"net/http"
+	"net/url"
 	"os"
+	"path/filepath"
+	"sort"
 	"strings"
 	"time"
 
@@ -17,6 +24,10 @@ var (
 	serverURL  string
 	outputJSON bool
 	verbose    bool
+	apiKey     string
+	caCert     string
+	clientCert string
+	clientKey  string
 )
 
 type Client struct {
@@ -25,12 +36,46 @@ type Client struct {
 }
 
 func NewClient(baseURL string) *Client {
-	return &Client{
+	c := &Client{
 		BaseURL: baseURL,
 		Client: &http.Client{
 			Timeout: 30 * time.Second,
 		},
 	}
+	if caCert != "" || clientCert != "" || clientKey != "" {
+		tlsConfig, err := loadTLSConfig()
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		transport := http.DefaultTransport.(*http.Transport).Clone()
+		transport.TLSClientConfig = tlsConfig
+		c.Client.Transport = transport
+	}
+	return c
+}
+
+// loadTLSConfig builds the HTTPS settings from --cacert, --cert and --key
+func loadTLSConfig() (*tls.Config, error) {
+	cfg := &tls.Config{MinVersion: tls.VersionTLS12}
+	if caCert != "" {
+		pem, err := os.ReadFile(caCert)
+		if err != nil {
+			return nil, err
+		}
+		cfg.RootCAs = x509.NewCertPool()
+		if !cfg.RootCAs.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("no certificates in %s", caCert)
+		}
+	}
+	if clientCert != "" || clientKey != "" {
+		cert, err := tls.LoadX509KeyPair(clientCert, clientKey)
+		if err != nil {
+			return nil, fmt.Errorf("client certificate: %w", err)
+		}
+		cfg.Certificates = []tls.Certificate{cert}
+	}
+	return cfg, nil
 }
 
 func (c *Client) request(method, path string, body interface{}) ([]byte, error) {
@@ -54,6 +99,34 @@ func (c *Client) request(method, path string, body interface{}) ([]byte, error)
 	if body != nil {
 		req.Header.Set("Content-Type", "application/json")
 	}
+	setAuth(req)
+
+	resp, err := c.Client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, err
+	}
+
+	if resp.StatusCode >= 400 {
+		return nil, fmt.Errorf("server error (%d): %s", resp.StatusCode, string(respBody))
+	}
+
+	return respBody, nil
+}
+
+// upload sends a raw request body (e.g. an archive) and returns the response
+func (c *Client) upload(path, contentType string, body io.Reader) ([]byte, error) {
+	req, err := http.NewRequest("POST", c.BaseURL+path, body)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", contentType)
+	setAuth(req)
 
 	resp, err := c.Client.Do(req)
 	if err != nil {
@@ -73,6 +146,41 @@ func (c *Client) request(method, path string, body interface{}) ([]byte, error)
 	return respBody, nil
 }
 
+// download streams a GET response body to w and returns the response trailers
+func (c *Client) download(path string, w io.Writer) (http.Header, error) {
+	req, err := http.NewRequest("GET", c.BaseURL+path, nil)
+	if err != nil {
+		return nil, err
+	}
+	setAuth(req)
+
+	resp, err := c.Client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode >= 400 {
+		respBody, _ := io.ReadAll(resp.Body)
+		return nil, fmt.Errorf("server error (%d): %s", resp.StatusCode, string(respBody))
+	}
+	if _, err := io.Copy(w, resp.Body); err != nil {
+		return nil, err
+	}
+	return resp.Trailer, nil
+}
+
+// setAuth sends the API key or JWT from --api-key or MDDB_API_KEY as a bearer token
+func setAuth(req *http.Request) {
+	token := apiKey
+	if token == "" {
+		token = os.Getenv("MDDB_API_KEY")
+	}
+	if token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+}
+
 func main() {
 	rootCmd := &cobra.Command{
 		Use:   "mddb-cli",
@@ -85,6 +193,10 @@ It provides an interface similar to mysql-client for managing markdown documents
 	rootCmd.PersistentFlags().StringVarP(&serverURL, "server", "s", "http://localhost:11023", "MDDB server URL")
 	rootCmd.PersistentFlags().BoolVarP(&outputJSON, "json", "j", false, "Output raw JSON")
 	rootCmd.PersistentFlags().BoolVarP(&verbose, "verbose", "v", false, "Verbose output")
+	rootCmd.PersistentFlags().StringVar(&apiKey, "api-key", "", "API key or JWT for servers with auth enabled (default $MDDB_API_KEY)")
+	rootCmd.PersistentFlags().StringVar(&caCert, "cacert", "", "CA certificate to verify an https:// server")
+	rootCmd.PersistentFlags().StringVar(&clientCert, "cert", "", "Client certificate for mutual TLS")
+	rootCmd.PersistentFlags().StringVar(&clientKey, "key", "", "Client key for mutual TLS")
 
 	// Add command
 	addCmd := &cobra.Command{
@@ -155,6 +267,71 @@ Reads content from stdin or file.`,
 	addCmd.Flags().StringP("file", "f", "", "Read content from file instead of stdin")
 	addCmd.Flags().StringP("meta", "m", "", "Metadata in format: key=val1|val2,key2=val")
 
+	// Patch command
+	patchCmd := &cobra.Command{
+		Use:   "patch [collection] [key] [lang]",
+		Short: "Patch an existing document",
+		Long: `Change meta values or content of an existing document without resending it.
+Operations run in the order --set, --add, --remove, --unset.`,
+		Args: cobra.ExactArgs(3),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			collection, key, lang := args[0], args[1], args[2]
+
+			var ops []map[string]interface{}
+			for _, op := range []string{"set", "add", "remove", "unset"} {
+				vals, _ := cmd.Flags().GetStringArray(op)
+				for _, v := range vals {
+					kv := strings.SplitN(v, "=", 2)
+					patch := map[string]interface{}{"op": op, "key": kv[0]}
+					if len(kv) == 2 {
+						patch["values"] = strings.Split(kv[1], "|")
+					}
+					ops = append(ops, patch)
+				}
+			}
+
+			body := map[string]interface{}{
+				"collection": collection,
+				"key":        key,
+				"lang":       lang,
+				"meta":       ops,
+			}
+			if contentFile, _ := cmd.Flags().GetString("file"); contentFile != "" {
+				data, err := os.ReadFile(contentFile)
+				if err != nil {
+					return err
+				}
+				body["contentMd"] = string(data)
+			}
+			if appendMd, _ := cmd.Flags().GetString("append"); appendMd != "" {
+				body["appendMd"] = appendMd
+			}
+
+			client := NewClient(serverURL)
+			resp, err := client.request("POST", "/v1/patch", body)
+			if err != nil {
+				return err
+			}
+
+			if outputJSON {
+				fmt.Println(string(resp))
+			} else {
+				var doc map[string]interface{}
+				json.Unmarshal(resp, &doc)
+				fmt.Printf("✓ Document patched: %s\n", doc["id"])
+				fmt.Printf("  Updated: %v\n", time.Unix(int64(doc["updatedAt"].(float64)), 0).Format(time.RFC3339))
+			}
+
+			return nil
+		},
+	}
+	patchCmd.Flags().StringArray("set", nil, "Replace meta values: key=val1|val2 (repeatable)")
+	patchCmd.Flags().StringArray("add", nil, "Add meta values: key=val1|val2 (repeatable)")
+	patchCmd.Flags().StringArray("remove", nil, "Remove meta values: key=val1|val2 (repeatable)")
+	patchCmd.Flags().StringArray("unset", nil, "Remove a meta key (repeatable)")
+	patchCmd.Flags().StringP("file", "f", "", "Replace content with the contents of a file")
+	patchCmd.Flags().String("append", "", "Append markdown to the content")
+
 	// Get command
 	getCmd := &cobra.Command{
 		Use:   "get [collection] [key] [lang]",
@@ -234,6 +411,7 @@ Reads content from stdin or file.`,
 			asc, _ := cmd.Flags().GetBool("asc")
 			limit, _ := cmd.Flags().GetInt("limit")
 			offset, _ := cmd.Flags().GetInt("offset")
+			consistency, _ := cmd.Flags().GetString("consistency")
 
 			filterMeta := make(map[string][]string)
 			if metaStr != "" {
@@ -255,6 +433,9 @@ Reads content from stdin or file.`,
 				"limit":      limit,
 				"offset":     offset,
 			}
+			if consistency != "" {
+				body["consistency"] = consistency
+			}
 
 			resp, err := client.request("POST", "/v1/search", body)
 			if err != nil {
@@ -291,6 +472,7 @@ Reads content from stdin or file.`,
 	searchCmd.Flags().BoolP("asc", "a", false, "Sort ascending (default: descending)")
 	searchCmd.Flags().IntP("limit", "l", 50, "Limit results")
 	searchCmd.Flags().IntP("offset", "o", 0, "Offset results")
+	searchCmd.Flags().String("consistency", "", "eventual (default) or strong: include writes whose index update is still queued")
 
 	// Export command
 	exportCmd := &cobra.Command{
@@ -340,24 +522,115 @@ Reads content from stdin or file.`,
 			return nil
 		},
 	}
-	exportCmd.Flags().StringP("format", "F", "ndjson", "Export format: ndjson, zip")
+	exportCmd.Flags().StringP("format", "F", "ndjson", "Export format: ndjson, zip, tar")
 	exportCmd.Flags().StringP("output", "o", "", "Output file (default: stdout)")
 	exportCmd.Flags().StringP("filter", "f", "", "Filter by metadata: key=val1|val2,key2=val")
 
+	// Import command
+	importCmd := &cobra.Command{
+		Use:   "import [collection] [file]",
+		Short: "Import documents",
+		Long: `Import an NDJSON export or a zip/tar archive of {key}.{lang}.md files.
+Frontmatter of markdown files becomes metadata. Use "-" to read from stdin.`,
+		Args: cobra.ExactArgs(2),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			collection, file := args[0], args[1]
+			format, _ := cmd.Flags().GetString("format")
+			onConflict, _ := cmd.Flags().GetString("on-conflict")
+			lang, _ := cmd.Flags().GetString("lang")
+			chunkSize, _ := cmd.Flags().GetInt("chunk-size")
+			dryRun, _ := cmd.Flags().GetBool("dry-run")
+			saveRev, _ := cmd.Flags().GetBool("save-revision")
+
+			if format == "" {
+				switch strings.ToLower(filepath.Ext(file)) {
+				case ".zip":
+					format = "zip"
+				case ".tar":
+					format = "tar"
+				default:
+					format = "ndjson"
+				}
+			}
+
+			var body io.Reader = os.Stdin
+			if file != "-" {
+				f, err := os.Open(file)
+				if err != nil {
+					return err
+				}
+				defer f.Close()
+				body = f
+			}
+
+			q := url.Values{}
+			q.Set("collection", collection)
+			q.Set("format", format)
+			q.Set("onConflict", onConflict)
+			q.Set("lang", lang)
+			q.Set("chunkSize", fmt.Sprint(chunkSize))
+			q.Set("dryRun", fmt.Sprint(dryRun))
+			q.Set("saveRevision", fmt.Sprint(saveRev))
+
+			client := NewClient(serverURL)
+			resp, err := client.upload("/v1/import?"+q.Encode(), "application/octet-stream", body)
+			if err != nil {
+				return err
+			}
+
+			if outputJSON {
+				fmt.Println(string(resp))
+			} else {
+				var result struct {
+					Total, Added, Updated, Skipped, Failed, Chunks int
+					Errors                                         []string
+					DryRun                                         bool
+				}
+				json.Unmarshal(resp, &result)
+				if result.DryRun {
+					fmt.Printf("Dry run - nothing was written\n")
+				}
+				fmt.Printf("✓ Imported %d documents into %s (%d added, %d updated, %d skipped, %d failed, %d chunks)\n",
+					result.Total, collection, result.Added, result.Updated, result.Skipped, result.Failed, result.Chunks)
+				for _, e := range result.Errors {
+					fmt.Printf("  ✗ %s\n", e)
+				}
+			}
+
+			return nil
+		},
+	}
+	importCmd.Flags().StringP("format", "F", "", "Import format: ndjson, zip, tar (default: from file extension)")
+	importCmd.Flags().StringP("on-conflict", "c", "upsert", "Existing documents: upsert, skip, fail")
+	importCmd.Flags().StringP("lang", "l", "", "Language for files named {key}.md")
+	importCmd.Flags().Int("chunk-size", 500, "Documents per transaction")
+	importCmd.Flags().BoolP("dry-run", "n", false, "Validate and count without writing")
+	importCmd.Flags().Bool("save-revision", false, "Save a revision for every imported document")
+
 	// Backup command
 	backupCmd := &cobra.Command{
 		Use:   "backup [filename]",
 		Short: "Create database backup",
-		Long:  `Create a backup of the database.`,
-		Args:  cobra.MaximumNArgs(1),
+		Long: `Create a consistent backup of the database while it keeps serving writes.
+Without --output the backup is written on the server, into its backup directory
+(MDDB_BACKUP_DIR) under the given name. With --output it is streamed to a local
+file ("-" for stdout) and verified against the server's SHA-256 checksum.`,
+		Args: cobra.MaximumNArgs(1),
 		RunE: func(cmd *cobra.Command, args []string) error {
-			filename := fmt.Sprintf("backup-%d.db", time.Now().Unix())
-			if len(args) > 0 {
-				filename = args[0]
+			output, _ := cmd.Flags().GetString("output")
+			compression, _ := cmd.Flags().GetString("compress")
+			client := NewClient(serverURL)
+
+			if output != "" {
+				return downloadBackup(client, output, compression)
 			}
 
-			client := NewClient(serverURL)
-			resp, err := client.request("GET", fmt.Sprintf("/v1/backup?to=%s", filename), nil)
+			q := url.Values{}
+			if len(args) > 0 {
+				q.Set("to", args[0])
+			}
+			q.Set("compression", compression)
+			resp, err := client.request("GET", "/v1/backup?"+q.Encode(), nil)
 			if err != nil {
 				return err
 			}
@@ -365,27 +638,79 @@ Reads content from stdin or file.`,
 			if outputJSON {
 				fmt.Println(string(resp))
 			} else {
-				var result map[string]string
+				var result struct {
+					Backup   string
+					Manifest struct {
+						Size   int64
+						SHA256 string
+					}
+				}
 				json.Unmarshal(resp, &result)
-				fmt.Printf("✓ Backup created: %s\n", result["backup"])
+				fmt.Printf("✓ Backup created: %s (%d bytes, sha256 %s)\n", result.Backup, result.Manifest.Size, result.Manifest.SHA256)
 			}
 
 			return nil
 		},
 	}
+	backupCmd.Flags().StringP("output", "o", "", "Stream the backup to a local file (- for stdout)")
+	backupCmd.Flags().String("compress", "none", "Compression: none, zstd")
 
 	// Restore command
 	restoreCmd := &cobra.Command{
 		Use:   "restore [filename]",
 		Short: "Restore database from backup",
-		Long:  `Restore the database from a backup file.`,
-		Args:  cobra.ExactArgs(1),
+		Long: `Restore the database from a backup in the server's backup directory (MDDB_BACKUP_DIR),
+or upload a local backup file with --file ("-" for stdin). Plain and zstd-compressed backups
+are accepted. The server verifies the backup, drains running requests (new ones get 503),
+swaps the file and keeps the replaced one; --rollback swaps it back.
+
+With scheduled backups on (MDDB_BACKUP_INTERVAL), --at restores the database as it was at
+a point in time: the newest backup taken before it, or the named one, is rolled forward
+with the server's change log. Times without a zone are local:
+
+  mddb-cli restore --at 2026-10-01T12:00`,
+		Args: cobra.MaximumNArgs(1),
 		RunE: func(cmd *cobra.Command, args []string) error {
-			filename := args[0]
+			file, _ := cmd.Flags().GetString("file")
+			sum, _ := cmd.Flags().GetString("sha256")
+			rollback, _ := cmd.Flags().GetBool("rollback")
+			atFlag, _ := cmd.Flags().GetString("at")
+			if atFlag != "" {
+				if file != "" || rollback {
+					return fmt.Errorf("--at takes an optional backup name, not --file or --rollback")
+				}
+			} else if (len(args) == 1) == (file != "" || rollback) {
+				return fmt.Errorf("give exactly one of a backup name, --file, --rollback or --at")
+			}
 
 			client := NewClient(serverURL)
-			body := map[string]string{"from": filename}
-			resp, err := client.request("POST", "/v1/restore", body)
+			var resp []byte
+			var err error
+			if file != "" {
+				var body io.Reader = os.Stdin
+				if file != "-" {
+					f, err := os.Open(file)
+					if err != nil {
+						return err
+					}
+					defer f.Close()
+					body = f
+				}
+				resp, err = client.upload("/v1/restore/upload?sha256="+url.QueryEscape(sum), "application/octet-stream", body)
+			} else {
+				req := map[string]interface{}{"sha256": sum, "rollback": rollback}
+				if len(args) == 1 {
+					req["from"] = args[0]
+				}
+				if atFlag != "" {
+					at, err := parsePointInTime(atFlag)
+					if err != nil {
+						return err
+					}
+					req["at"] = at.Format(time.RFC3339Nano)
+				}
+				resp, err = client.request("POST", "/v1/restore", req)
+			}
 			if err != nil {
 				return err
 			}
@@ -393,14 +718,115 @@ Reads content from stdin or file.`,
 			if outputJSON {
 				fmt.Println(string(resp))
 			} else {
-				var result map[string]string
+				var result struct {
+					Restored, Previous, SHA256 string
+					Size, Replayed             int64
+				}
 				json.Unmarshal(resp, &result)
-				fmt.Printf("✓ Restored from: %s\n", result["restored"])
+				fmt.Printf("✓ Restored from: %s (%d bytes, sha256 %s)\n", result.Restored, result.Size, result.SHA256)
+				if atFlag != "" {
+					fmt.Printf("  Rolled forward to %s (%d changes replayed)\n", atFlag, result.Replayed)
+				}
+				fmt.Printf("  Previous database kept as %s (undo with --rollback)\n", result.Previous)
+			}
+
+			return nil
+		},
+	}
+	restoreCmd.Flags().StringP("file", "f", "", "Upload and restore a local backup file (- for stdin)")
+	restoreCmd.Flags().String("sha256", "", "Expected SHA-256 of the backup, e.g. from its manifest")
+	restoreCmd.Flags().Bool("rollback", false, "Swap back the database replaced by the last restore")
+	restoreCmd.Flags().String("at", "", "Restore to a point in time, e.g. 2026-10-01T12:00 (local) or RFC 3339")
+
+	// Dump command
+	dumpCmd := &cobra.Command{
+		Use:   "dump [collections...]",
+		Short: "Dump collections to a portable archive",
+		Long: `Dump collections (default all) to a logical archive: documents, revisions, collection
+settings and schemas with a SHA-256 manifest. Unlike a backup, a dump can be loaded
+collection by collection, under another name and into other mddb versions.
+
+  mddb-cli dump blog notes -o content.tar.zst --compress zstd`,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			output, _ := cmd.Flags().GetString("output")
+			compression, _ := cmd.Flags().GetString("compress")
+			noRevisions, _ := cmd.Flags().GetBool("no-revisions")
+			if output == "" {
+				output = fmt.Sprintf("mddb-dump-%s.tar", time.Now().Format("20060102-150405"))
+				if compression == "zstd" {
+					output += ".zst"
+				}
 			}
+			q := url.Values{"collection": args}
+			q.Set("compression", compression)
+			if noRevisions {
+				q.Set("revisions", "false")
+			}
+			return downloadDump(NewClient(serverURL), "/v1/dump?"+q.Encode(), output)
+		},
+	}
+	dumpCmd.Flags().StringP("output", "o", "", "Archive file (- for stdout, default mddb-dump-<time>.tar)")
+	dumpCmd.Flags().String("compress", "none", "Compression: none, zstd")
+	dumpCmd.Flags().Bool("no-revisions", false, "Leave out revision history")
+
+	// Load command
+	loadCmd := &cobra.Command{
+		Use:   "load <file>",
+		Short: "Load collections from a dump",
+		Long: `Load the collections of a dump written by "mddb-cli dump" ("-" for stdin). The server
+verifies the archive against its manifest, then writes every collection in one
+transaction. Existing collections are only replaced with --replace.
 
+  mddb-cli load content.tar.zst --collection blog --rename blog=blog_restored`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			collections, _ := cmd.Flags().GetStringSlice("collection")
+			renames, _ := cmd.Flags().GetStringSlice("rename")
+			replace, _ := cmd.Flags().GetBool("replace")
+
+			var body io.Reader = os.Stdin
+			if args[0] != "-" {
+				f, err := os.Open(args[0])
+				if err != nil {
+					return err
+				}
+				defer f.Close()
+				body = f
+			}
+			q := url.Values{"collection": collections, "rename": renames}
+			if replace {
+				q.Set("replace", "true")
+			}
+			resp, err := NewClient(serverURL).upload("/v1/load?"+q.Encode(), "application/octet-stream", body)
+			if err != nil {
+				return err
+			}
+
+			if outputJSON {
+				fmt.Println(string(resp))
+			} else {
+				var result struct {
+					Collections []struct {
+						From, Name           string
+						Documents, Revisions int
+						Replaced             bool
+					}
+				}
+				json.Unmarshal(resp, &result)
+				for _, c := range result.Collections {
+					action := "Loaded"
+					if c.Replaced {
+						action = "Replaced"
+					}
+					fmt.Printf("✓ %s %s from %s (%d documents, %d revisions)\n", action, c.Name, c.From, c.Documents, c.Revisions)
+				}
+			}
 			return nil
 		},
 	}
+	loadCmd.Flags().StringSlice("collection", nil, "Load only these collections of the dump (repeatable)")
+	loadCmd.Flags().StringSlice("rename", nil, "Load a collection under another name: from=to (repeatable)")
+	loadCmd.Flags().Bool("replace", false, "Replace collections that already exist")
 
 	// Truncate command
 	truncateCmd := &cobra.Command{
@@ -438,6 +864,140 @@ Reads content from stdin or file.`,
 	truncateCmd.Flags().IntP("keep", "k", 5, "Number of revisions to keep")
 	truncateCmd.Flags().BoolP("drop-cache", "d", true, "Drop cache")
 
+	// Fsck command
+	fsckCmd := &cobra.Command{
+		Use:   "fsck [collection]",
+		Short: "Check database consistency",
+		Long: `Scan documents, key entries, meta indexes and revisions for inconsistencies:
+orphaned index and key entries, documents missing their key entry or index
+entries, revisions of deleted documents and values that cannot be decoded.
+With --repair the problems are fixed; undecodable documents are deleted.
+Exits with an error when problems remain.`,
+		Args: cobra.MaximumNArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			repair, _ := cmd.Flags().GetBool("repair")
+			body := map[string]interface{}{"repair": repair}
+			if len(args) == 1 {
+				body["collection"] = args[0]
+			}
+
+			client := NewClient(serverURL)
+			resp, err := client.request("POST", "/v1/fsck", body)
+			if err != nil {
+				return err
+			}
+
+			var report struct {
+				Documents   int            `json:"documents"`
+				KeyEntries  int            `json:"keyEntries"`
+				MetaIndices int            `json:"metaIndices"`
+				Revisions   int            `json:"revisions"`
+				Problems    map[string]int `json:"problems"`
+				Issues      []struct {
+					Kind       string `json:"kind"`
+					Collection string `json:"collection"`
+					Key        string `json:"key"`
+					Repaired   bool   `json:"repaired"`
+				} `json:"issues"`
+				Repaired int `json:"repaired"`
+			}
+			if err := json.Unmarshal(resp, &report); err != nil {
+				return err
+			}
+			total := 0
+			for _, n := range report.Problems {
+				total += n
+			}
+
+			if outputJSON {
+				fmt.Println(string(resp))
+			} else {
+				fmt.Printf("Scanned %d documents, %d key entries, %d meta index entries, %d revisions\n",
+					report.Documents, report.KeyEntries, report.MetaIndices, report.Revisions)
+				if total == 0 {
+					fmt.Println("✓ No problems found")
+					return nil
+				}
+				for _, is := range report.Issues {
+					mark := " "
+					if is.Repaired {
+						mark = "✓"
+					}
+					fmt.Printf("%s %-20s %s\n", mark, is.Kind, is.Key)
+				}
+				if total > len(report.Issues) {
+					fmt.Printf("  ... and %d more\n", total-len(report.Issues))
+				}
+				fmt.Println()
+				kinds := make([]string, 0, len(report.Problems))
+				for kind := range report.Problems {
+					kinds = append(kinds, kind)
+				}
+				sort.Strings(kinds)
+				for _, kind := range kinds {
+					fmt.Printf("  %-20s %d\n", kind, report.Problems[kind])
+				}
+				if repair {
+					fmt.Printf("✓ Repaired %d of %d problems\n", report.Repaired, total)
+				}
+			}
+
+			if !repair && total > 0 {
+				cmd.SilenceUsage = true // not a usage error; the exit status tells scripts
+				return fmt.Errorf("%d problems found; run with --repair to fix them", total)
+			}
+			return nil
+		},
+	}
+	fsckCmd.Flags().BoolP("repair", "r", false, "Repair the problems found")
+
+	// Compact command
+	compactCmd := &cobra.Command{
+		Use:   "compact",
+		Short: "Compact the database file",
+		Long: `Rewrite the database file without the free pages left by deletes and
+truncation, and swap it in. Writes wait while the file is copied; reads
+keep being served. With --min-free the file is only compacted when at
+least that fraction of it is free, which suits cron jobs.`,
+		Args: cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			minFree, _ := cmd.Flags().GetFloat64("min-free")
+
+			client := NewClient(serverURL)
+			resp, err := client.request("POST", "/v1/compact", map[string]interface{}{"minFreeRatio": minFree})
+			if err != nil {
+				return err
+			}
+
+			if outputJSON {
+				fmt.Println(string(resp))
+				return nil
+			}
+			var res struct {
+				Compacted  bool  `json:"compacted"`
+				SizeBefore int64 `json:"sizeBefore"`
+				SizeAfter  int64 `json:"sizeAfter"`
+				FreeBefore int64 `json:"freeBefore"`
+				Reclaimed  int64 `json:"reclaimed"`
+				DurationMs int64 `json:"durationMs"`
+			}
+			if err := json.Unmarshal(resp, &res); err != nil {
+				return err
+			}
+			mb := func(n int64) float64 { return float64(n) / 1024 / 1024 }
+			if !res.Compacted {
+				fmt.Printf("Skipped: %.2f of %.2f MB free, below %.0f%%\n", mb(res.FreeBefore), mb(res.SizeBefore), minFree*100)
+				return nil
+			}
+			fmt.Printf("✓ Compacted in %dms\n", res.DurationMs)
+			fmt.Printf("  Before:    %.2f MB\n", mb(res.SizeBefore))
+			fmt.Printf("  After:     %.2f MB\n", mb(res.SizeAfter))
+			fmt.Printf("  Reclaimed: %.2f MB\n", mb(res.Reclaimed))
+			return nil
+		},
+	}
+	compactCmd.Flags().Float64("min-free", 0, "Only compact when at least this fraction of the file is free (0-1)")
+
 	// Stats command
 	statsCmd := &cobra.Command{
 		Use:   "stats",
@@ -468,6 +1028,18 @@ Reads content from stdin or file.`,
 				fmt.Printf("  Revisions:     %d\n", int(stats["totalRevisions"].(float64)))
 				fmt.Printf("  Meta Indices:  %d\n\n", int(stats["totalMetaIndices"].(float64)))
 				
+				if cache, ok := stats["cache"].(map[string]interface{}); ok {
+					hits, misses := cache["hits"].(float64), cache["misses"].(float64)
+					fmt.Printf("Document Cache:\n")
+					fmt.Printf("  Entries:       %d (%.2f of %.2f MB)\n", int(cache["entries"].(float64)),
+						cache["bytes"].(float64)/1024/1024, cache["budget"].(float64)/1024/1024)
+					fmt.Printf("  Hits/Misses:   %d / %d", int(hits), int(misses))
+					if hits+misses > 0 {
+						fmt.Printf(" (%.1f%% hit rate)", 100*hits/(hits+misses))
+					}
+					fmt.Printf("\n  Evictions:     %d\n\n", int(cache["evictions"].(float64)))
+				}
+				
 				if collections, ok := stats["collections"].([]interface{}); ok && len(collections) > 0 {
 					fmt.Printf("Collections:\n")
 					fmt.Printf("─────────────────────────────────────────\n")
@@ -490,9 +1062,541 @@ Reads content from stdin or file.`,
 		},
 	}
 
-	rootCmd.AddCommand(addCmd, getCmd, searchCmd, exportCmd, backupCmd, restoreCmd, truncateCmd, statsCmd)
+	// Schema command
+	schemaCmd := &cobra.Command{
+		Use:   "schema",
+		Short: "Manage collection schemas",
+		Long:  `Set, show or remove the validation schema of a collection.`,
+	}
+
+	schemaSetCmd := &cobra.Command{
+		Use:   "set [collection]",
+		Short: "Set a collection schema",
+		Long: `Set (create or replace) a collection schema.
+Reads the schema JSON from stdin or file, e.g.:
+  {"requiredMeta":["title"],"optionalMeta":["category"],"enums":{"category":["blog","news"]},
+   "patterns":{"title":"^.{1,120}$"},"maxContentSize":65536,"requiredLangs":["en_GB"]}`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			schemaFile, _ := cmd.Flags().GetString("file")
+
+			var data []byte
+			var err error
+			if schemaFile != "" {
+				data, err = os.ReadFile(schemaFile)
+			} else {
+				data, err = io.ReadAll(os.Stdin)
+			}
+			if err != nil {
+				return err
+			}
+
+			var body map[string]interface{}
+			if err := json.Unmarshal(data, &body); err != nil {
+				return fmt.Errorf("invalid schema JSON: %w", err)
+			}
+			body["collection"] = args[0]
+
+			client := NewClient(serverURL)
+			resp, err := client.request("POST", "/v1/schema/set", body)
+			if err != nil {
+				return err
+			}
+
+			if outputJSON {
+				fmt.Println(string(resp))
+			} else {
+				fmt.Printf("✓ Schema set for collection: %s\n", args[0])
+			}
+
+			return nil
+		},
+	}
+	schemaSetCmd.Flags().StringP("file", "f", "", "Read schema JSON from file instead of stdin")
+
+	schemaGetCmd := &cobra.Command{
+		Use:   "get [collection]",
+		Short: "Show a collection schema",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			client := NewClient(serverURL)
+			resp, err := client.request("POST", "/v1/schema/get", map[string]string{"collection": args[0]})
+			if err != nil {
+				return err
+			}
+
+			var out bytes.Buffer
+			if err := json.Indent(&out, resp, "", "  "); err != nil {
+				fmt.Println(string(resp))
+				return nil
+			}
+			fmt.Println(out.String())
+
+			return nil
+		},
+	}
+
+	schemaDeleteCmd := &cobra.Command{
+		Use:   "delete [collection]",
+		Short: "Remove a collection schema",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			client := NewClient(serverURL)
+			resp, err := client.request("POST", "/v1/schema/delete", map[string]string{"collection": args[0]})
+			if err != nil {
+				return err
+			}
+
+			if outputJSON {
+				fmt.Println(string(resp))
+			} else {
+				fmt.Printf("✓ Schema removed from collection: %s\n", args[0])
+			}
+
+			return nil
+		},
+	}
+
+	schemaCmd.AddCommand(schemaSetCmd, schemaGetCmd, schemaDeleteCmd)
+
+	// Collection command
+	collectionCmd := &cobra.Command{
+		Use:     "collection",
+		Aliases: []string{"collections"},
+		Short:   "Manage collections",
+		Long:    `List, create, describe, rename, clone and delete collections and their settings.`,
+	}
+
+	collectionListCmd := &cobra.Command{
+		Use:   "list",
+		Short: "List collections",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			client := NewClient(serverURL)
+			resp, err := client.request("GET", "/v1/collections", nil)
+			if err != nil {
+				return err
+			}
+
+			if outputJSON {
+				fmt.Println(string(resp))
+			} else {
+				var collections []map[string]interface{}
+				json.Unmarshal(resp, &collections)
+				if len(collections) == 0 {
+					fmt.Printf("No collections found.\n")
+					return nil
+				}
+				fmt.Printf("%-20s %-10s %-10s %-8s %s\n", "Name", "Lang", "Revisions", "Compr", "Description")
+				fmt.Printf("─────────────────────────────────────────────────────────────\n")
+				for _, c := range collections {
+					fmt.Printf("%-20s %-10s %-10s %-8s %s\n", c["name"], c["defaultLang"], c["revisionPolicy"], c["compression"], c["description"])
+				}
+			}
+
+			return nil
+		},
+	}
+
+	collectionSettings := func(cmd *cobra.Command, name string) map[string]interface{} {
+		description, _ := cmd.Flags().GetString("description")
+		defaultLang, _ := cmd.Flags().GetString("default-lang")
+		revisions, _ := cmd.Flags().GetString("revisions")
+		compression, _ := cmd.Flags().GetString("compression")
+		return map[string]interface{}{
+			"name":           name,
+			"description":    description,
+			"defaultLang":    defaultLang,
+			"revisionPolicy": revisions,
+			"compression":    compression,
+		}
+	}
+
+	collectionCreateCmd := &cobra.Command{
+		Use:   "create [name]",
+		Short: "Create a collection",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			client := NewClient(serverURL)
+			resp, err := client.request("POST", "/v1/collections/create", collectionSettings(cmd, args[0]))
+			if err != nil {
+				return err
+			}
+
+			if outputJSON {
+				fmt.Println(string(resp))
+			} else {
+				fmt.Printf("✓ Collection created: %s\n", args[0])
+			}
+
+			return nil
+		},
+	}
+
+	collectionUpdateCmd := &cobra.Command{
+		Use:   "update [name]",
+		Short: "Replace collection settings",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			client := NewClient(serverURL)
+			resp, err := client.request("POST", "/v1/collections/update", collectionSettings(cmd, args[0]))
+			if err != nil {
+				return err
+			}
+
+			if outputJSON {
+				fmt.Println(string(resp))
+			} else {
+				fmt.Printf("✓ Collection updated: %s\n", args[0])
+			}
+
+			return nil
+		},
+	}
+
+	for _, c := range []*cobra.Command{collectionCreateCmd, collectionUpdateCmd} {
+		c.Flags().StringP("description", "d", "", "Collection description")
+		c.Flags().StringP("default-lang", "l", "", "Default language for writes without lang")
+		c.Flags().StringP("revisions", "r", "request", "Revision policy: request, always, never")
+		c.Flags().StringP("compression", "c", "auto", "Compression: auto, none, snappy, zstd")
+	}
+
+	collectionDescribeCmd := &cobra.Command{
+		Use:   "describe [name]",
+		Short: "Show collection settings and counts",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			client := NewClient(serverURL)
+			resp, err := client.request("POST", "/v1/collections/describe", map[string]string{"name": args[0]})
+			if err != nil {
+				return err
+			}
+
+			if outputJSON {
+				fmt.Println(string(resp))
+			} else {
+				var c map[string]interface{}
+				json.Unmarshal(resp, &c)
+				fmt.Printf("Name:            %s\n", c["name"])
+				fmt.Printf("Description:     %s\n", c["description"])
+				fmt.Printf("Default Lang:    %s\n", c["defaultLang"])
+				fmt.Printf("Revision Policy: %s\n", c["revisionPolicy"])
+				fmt.Printf("Compression:     %s\n", c["compression"])
+				fmt.Printf("Created:         %v\n", time.Unix(int64(c["createdAt"].(float64)), 0).Format(time.RFC3339))
+				fmt.Printf("Documents:       %d\n", int(c["documentCount"].(float64)))
+				fmt.Printf("Revisions:       %d\n", int(c["revisionCount"].(float64)))
+				fmt.Printf("Schema:          %v\n", c["hasSchema"])
+			}
+
+			return nil
+		},
+	}
+
+	collectionCopyCmd := func(use, short, path, verb string) *cobra.Command {
+		return &cobra.Command{
+			Use:   use + " [name] [new-name]",
+			Short: short,
+			Args:  cobra.ExactArgs(2),
+			RunE: func(cmd *cobra.Command, args []string) error {
+				client := NewClient(serverURL)
+				resp, err := client.request("POST", path, map[string]string{"name": args[0], "newName": args[1]})
+				if err != nil {
+					return err
+				}
+
+				if outputJSON {
+					fmt.Println(string(resp))
+				} else {
+					var result map[string]interface{}
+					json.Unmarshal(resp, &result)
+					fmt.Printf("✓ Collection %s %s to %s (%d documents)\n", args[0], verb, args[1], int(result["documents"].(float64)))
+				}
+
+				return nil
+			},
+		}
+	}
+
+	collectionDeleteCmd := &cobra.Command{
+		Use:   "delete [name]",
+		Short: "Delete a collection with all documents",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			client := NewClient(serverURL)
+			resp, err := client.request("POST", "/v1/collections/delete", map[string]string{"name": args[0]})
+			if err != nil {
+				return err
+			}
+
+			if outputJSON {
+				fmt.Println(string(resp))
+			} else {
+				var result map[string]interface{}
+				json.Unmarshal(resp, &result)
+				fmt.Printf("✓ Collection deleted: %s (%d documents)\n", args[0], int(result["deletedCount"].(float64)))
+			}
+
+			return nil
+		},
+	}
+
+	collectionCmd.AddCommand(
+		collectionListCmd,
+		collectionCreateCmd,
+		collectionUpdateCmd,
+		collectionDescribeCmd,
+		collectionCopyCmd("rename", "Rename a collection", "/v1/collections/rename", "renamed"),
+		collectionCopyCmd("clone", "Clone a collection", "/v1/collections/clone", "cloned"),
+		collectionDeleteCmd,
+	)
+
+	// Auth command
+	authCmd := &cobra.Command{
+		Use:   "auth",
+		Short: "Inspect the caller and manage API keys",
+		Long: `Show who the server authenticates you as and manage API keys (admin only).
+Roles have the form <read|write|admin>:<collection pattern>, e.g. write:blog-*.`,
+	}
+
+	authWhoamiCmd := &cobra.Command{
+		Use:   "whoami",
+		Short: "Show the authenticated caller and its roles",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			client := NewClient(serverURL)
+			resp, err := client.request("GET", "/v1/auth/whoami", nil)
+			if err != nil {
+				return err
+			}
+
+			if outputJSON {
+				fmt.Println(string(resp))
+			} else {
+				var p map[string]interface{}
+				json.Unmarshal(resp, &p)
+				fmt.Printf("Subject: %v\n", p["subject"])
+				fmt.Printf("Method:  %v\n", p["method"])
+				fmt.Printf("Roles:   %v\n", p["roles"])
+			}
+
+			return nil
+		},
+	}
+
+	authKeysCmd := &cobra.Command{
+		Use:   "keys",
+		Short: "Manage API keys",
+	}
+
+	authKeysListCmd := &cobra.Command{
+		Use:   "list",
+		Short: "List API keys",
+		Args:  cobra.NoArgs,
+		RunE: func(cmd *cobra.Command, args []string) error {
+			client := NewClient(serverURL)
+			resp, err := client.request("GET", "/v1/auth/keys", nil)
+			if err != nil {
+				return err
+			}
+
+			if outputJSON {
+				fmt.Println(string(resp))
+			} else {
+				var keys []map[string]interface{}
+				json.Unmarshal(resp, &keys)
+				if len(keys) == 0 {
+					fmt.Printf("No API keys found.\n")
+					return nil
+				}
+				fmt.Printf("%-14s %-20s %-20s %s\n", "ID", "Name", "Created", "Roles")
+				fmt.Printf("─────────────────────────────────────────────────────────────\n")
+				for _, k := range keys {
+					created := time.Unix(int64(k["createdAt"].(float64)), 0).Format("2006-01-02 15:04:05")
+					fmt.Printf("%-14s %-20s %-20s %v\n", k["id"], k["name"], created, k["roles"])
+				}
+			}
+
+			return nil
+		},
+	}
+
+	authKeysCreateCmd := &cobra.Command{
+		Use:   "create [name]",
+		Short: "Create an API key",
+		Long: `Create an API key with the given roles. The key is printed once and cannot be
+retrieved later.`,
+		Args: cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			roles, _ := cmd.Flags().GetStringArray("role")
+			client := NewClient(serverURL)
+			resp, err := client.request("POST", "/v1/auth/keys", map[string]interface{}{
+				"name":  args[0],
+				"roles": roles,
+			})
+			if err != nil {
+				return err
+			}
+
+			if outputJSON {
+				fmt.Println(string(resp))
+			} else {
+				var k map[string]interface{}
+				json.Unmarshal(resp, &k)
+				fmt.Printf("✓ API key created: %v (id %v)\n", k["name"], k["id"])
+				fmt.Printf("  %v\n", k["key"])
+				fmt.Printf("  Store it now, it is not shown again.\n")
+			}
+
+			return nil
+		},
+	}
+	authKeysCreateCmd.Flags().StringArrayP("role", "r", nil, "Role as <read|write|admin>:<pattern> (repeatable)")
+	authKeysCreateCmd.MarkFlagRequired("role")
+
+	authKeysDeleteCmd := &cobra.Command{
+		Use:   "delete [id]",
+		Short: "Revoke an API key",
+		Args:  cobra.ExactArgs(1),
+		RunE: func(cmd *cobra.Command, args []string) error {
+			client := NewClient(serverURL)
+			if _, err := client.request("DELETE", "/v1/auth/keys/"+url.PathEscape(args[0]), nil); err != nil {
+				return err
+			}
+
+			if !outputJSON {
+				fmt.Printf("✓ API key revoked: %s\n", args[0])
+			}
+
+			return nil
+		},
+	}
+
+	authKeysCmd.AddCommand(authKeysListCmd, authKeysCreateCmd, authKeysDeleteCmd)
+	authCmd.AddCommand(authWhoamiCmd, authKeysCmd)
+
+	rootCmd.AddCommand(addCmd, patchCmd, getCmd, searchCmd, exportCmd, importCmd, backupCmd, restoreCmd, dumpCmd, loadCmd, truncateCmd, fsckCmd, compactCmd, statsCmd, schemaCmd, collectionCmd, authCmd)
 
 	if err := rootCmd.Execute(); err != nil {
 		os.Exit(1)
 	}
 }
+
+// downloadBackup streams a backup into output and checks it against the checksum the
+// server sends in the trailers; a partial or mismatched file is removed
+func downloadBackup(client *Client, output, compression string) error {
+	var w io.Writer = os.Stdout
+	var f *os.File
+	if output != "-" {
+		var err error
+		f, err = os.CreateTemp(filepath.Dir(output), filepath.Base(output)+".*.tmp")
+		if err != nil {
+			return err
+		}
+		defer os.Remove(f.Name())
+		defer f.Close()
+		w = f
+	}
+
+	hash := sha256.New()
+	trailer, err := client.download("/v1/backup/stream?compression="+url.QueryEscape(compression), io.MultiWriter(w, hash))
+	if err != nil {
+		return err
+	}
+	sum := hex.EncodeToString(hash.Sum(nil))
+	if want := trailer.Get("X-Backup-Sha256"); want != sum {
+		return fmt.Errorf("backup checksum mismatch: got %s, server sent %q", sum, want)
+	}
+	if f == nil {
+		return nil
+	}
+	if err := f.Sync(); err != nil {
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	if err := os.Rename(f.Name(), output); err != nil {
+		return err
+	}
+
+	if outputJSON {
+		out, _ := json.Marshal(map[string]string{
+			"backup": output, "sha256": sum, "size": trailer.Get("X-Backup-Size"), "txId": trailer.Get("X-Backup-Tx-Id"),
+		})
+		fmt.Println(string(out))
+	} else {
+		fmt.Printf("✓ Backup saved: %s (%s bytes, sha256 %s)\n", output, trailer.Get("X-Backup-Size"), sum)
+	}
+	return nil
+}
+
+// downloadDump streams a dump to output ("-" for stdout); the file only appears once the
+// server's trailers confirm the dump is complete
+func downloadDump(client *Client, path, output string) error {
+	var w io.Writer = os.Stdout
+	var f *os.File
+	if output != "-" {
+		var err error
+		f, err = os.CreateTemp(filepath.Dir(output), filepath.Base(output)+".*.tmp")
+		if err != nil {
+			return err
+		}
+		defer os.Remove(f.Name())
+		defer f.Close()
+		w = f
+	}
+
+	trailer, err := client.download(path, w)
+	if err != nil {
+		return err
+	}
+	if trailer.Get("X-Dump-Tx-Id") == "" {
+		return fmt.Errorf("dump incomplete: the server did not finish the stream")
+	}
+	if f == nil {
+		return nil
+	}
+	if err := f.Sync(); err != nil {
+		return err
+	}
+	if err := f.Close(); err != nil {
+		return err
+	}
+	if err := os.Rename(f.Name(), output); err != nil {
+		return err
+	}
+
+	if outputJSON {
+		out, _ := json.Marshal(map[string]string{
+			"dump": output, "collections": trailer.Get("X-Dump-Collections"),
+			"documents": trailer.Get("X-Dump-Documents"), "revisions": trailer.Get("X-Dump-Revisions"),
+		})
+		fmt.Println(string(out))
+	} else {
+		fmt.Printf("✓ Dump saved: %s (%s: %s documents, %s revisions)\n", output,
+			trailer.Get("X-Dump-Collections"), trailer.Get("X-Dump-Documents"), trailer.Get("X-Dump-Revisions"))
+	}
+	return nil
+}
+
+// pointInTimeLayouts are the --at formats; times without a zone are local
+var pointInTimeLayouts = []string{
+	"2006-01-02T15:04",
+	"2006-01-02T15:04:05",
+	"2006-01-02T15:04:05.999999999",
+	"2006-01-02 15:04",
+	"2006-01-02 15:04:05",
+}
+
+// parsePointInTime parses a restore --at time
+func parsePointInTime(v string) (time.Time, error) {
+	if t, err := time.Parse(time.RFC3339Nano, v); err == nil {
+		return t, nil
+	}
+	for _, layout := range pointInTimeLayouts {
+		if t, err := time.ParseInLocation(layout, v, time.Local); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("invalid --at %q: want e.g. 2026-10-01T12:00 or an RFC 3339 time", v)
+}
@@ -0,0 +1,88 @@
+package main
+
+import (
+	"bufio"
+	"log"
+	"os"
+	"time"
+
+	"github.com/tradik/mddb/services/mddb-mcp/internal/config"
+	"github.com/tradik/mddb/services/mddb-mcp/internal/mcp"
+	mddb "github.com/tradik/mddb/services/mddbd/client"
+)
+
+func main() {
+	// Disable log output to stdout (MCP uses stdout for protocol)
+	log.SetOutput(os.Stderr)
+
+	cfgPath := os.Getenv("MDDB_MCP_CONFIG")
+	if cfgPath == "" {
+		cfgPath = "config.yaml"
+	}
+
+	cfg, err := config.Load(cfgPath)
+	if err != nil {
+		log.Fatalf("failed to load config: %v", err)
+	}
+
+	// Create MDDB client
+	tlsConfig, err := cfg.MDDB.ClientTLS()
+	if err != nil {
+		log.Fatalf("failed to load tls config: %v", err)
+	}
+	client, err := mddb.NewClient(mddb.ClientConfig{
+		GRPCAddress:   cfg.MDDB.GRPCAddress,
+		RESTBaseURL:   cfg.MDDB.RESTBaseURL,
+		TransportMode: cfg.MDDB.TransportMode,
+		Timeout:       time.Duration(cfg.MDDB.TimeoutSeconds) * time.Second,
+		MaxRetries:    cfg.MDDB.MaxRetries,
+		Token:         cfg.MDDB.APIKey,
+		TLS:           tlsConfig,
+	})
+	if err != nil {
+		log.Fatalf("failed to create mddb client: %v", err)
+	}
+	defer func() {
+		if err := client.Close(); err != nil {
+			log.Printf("error closing client: %v", err)
+		}
+	}()
+
+	// Create MCP handler
+	handler := mcp.NewHandler(client)
+
+	// Read from stdin, write to stdout (MCP stdio protocol)
+	scanner := bufio.NewScanner(os.Stdin)
+	for scanner.Scan() {
+		line := scanner.Bytes()
+
+		// Log incoming request for debugging
+		log.Printf("Received request: %s", string(line))
+
+		resp, err := handler.HandleJSON(line)
+		if err != nil {
+			log.Printf("error handling request: %v", err)
+			continue
+		}
+
+		// Log outgoing response for debugging
+		log.Printf("Sending response: %s", string(resp))
+
+		if _, err := os.Stdout.Write(resp); err != nil {
+			log.Printf("error writing response: %v", err)
+			continue
+		}
+		if _, err := os.Stdout.Write([]byte("\n")); err != nil {
+			log.Printf("error writing newline: %v", err)
+			continue
+		}
+		// Skip Sync() in Docker - causes "invalid argument" error
+		// if err := os.Stdout.Sync(); err != nil {
+		// 	log.Printf("error syncing stdout: %v", err)
+		// }
+	}
+
+	if err := scanner.Err(); err != nil {
+		log.Fatalf("error reading stdin: %v", err)
+	}
+}
@@ -1,11 +1,13 @@
 package config
 
 import (
+	"crypto/tls"
 	"errors"
 	"fmt"
 	"os"
 
 	"github.com/kelseyhightower/envconfig"
+	mddb "github.com/tradik/mddb/services/mddbd/client"
 	"gopkg.in/yaml.v3"
 )
 
@@ -25,6 +27,19 @@ type MDDBConfig struct {
 	TransportMode  string `yaml:"transportMode"`
 	TimeoutSeconds int    `yaml:"timeoutSeconds"`
 	MaxRetries     int    `yaml:"maxRetries"`
+	APIKey         string `yaml:"apiKey"` // API key or JWT when mddbd requires auth
+	TLS            bool   `yaml:"tls"`    // gRPC over TLS; implied by the tls*File settings
+	TLSCAFile      string `yaml:"tlsCaFile"`
+	TLSCertFile    string `yaml:"tlsCertFile"` // client certificate for mutual TLS
+	TLSKeyFile     string `yaml:"tlsKeyFile"`
+}
+
+// ClientTLS returns the TLS config for the MDDB client, or nil for plaintext gRPC.
+func (c MDDBConfig) ClientTLS() (*tls.Config, error) {
+	if !c.TLS && c.TLSCAFile == "" && c.TLSCertFile == "" && c.TLSKeyFile == "" {
+		return nil, nil
+	}
+	return mddb.LoadTLSConfig(c.TLSCAFile, c.TLSCertFile, c.TLSKeyFile)
 }
 
 // envConfig maps environment variables.
@@ -36,6 +51,11 @@ type envConfig struct {
 	MDDBTransportMode string `envconfig:"MDDB_TRANSPORT_MODE"`
 	MDDBTimeoutSec    int    `envconfig:"MDDB_TIMEOUT_SECONDS"`
 	MDDBMaxRetries    int    `envconfig:"MDDB_MAX_RETRIES"`
+	MDDBAPIKey        string `envconfig:"MDDB_API_KEY"`
+	MDDBTLS           bool   `envconfig:"MDDB_TLS"`
+	MDDBTLSCAFile     string `envconfig:"MDDB_TLS_CA_FILE"`
+	MDDBTLSCertFile   string `envconfig:"MDDB_TLS_CERT_FILE"`
+	MDDBTLSKeyFile    string `envconfig:"MDDB_TLS_KEY_FILE"`
 }
 
 // Load loads config in order: defaults -> YAML -> ENV (overrides).
@@ -116,6 +136,21 @@ func overrideFromEnv(cfg *Config) error {
 	if e.MDDBMaxRetries != 0 {
 		cfg.MDDB.MaxRetries = e.MDDBMaxRetries
 	}
+	if e.MDDBAPIKey != "" {
+		cfg.MDDB.APIKey = e.MDDBAPIKey
+	}
+	if e.MDDBTLS {
+		cfg.MDDB.TLS = true
+	}
+	if e.MDDBTLSCAFile != "" {
+		cfg.MDDB.TLSCAFile = e.MDDBTLSCAFile
+	}
+	if e.MDDBTLSCertFile != "" {
+		cfg.MDDB.TLSCertFile = e.MDDBTLSCertFile
+	}
+	if e.MDDBTLSKeyFile != "" {
+		cfg.MDDB.TLSKeyFile = e.MDDBTLSKeyFile
+	}
 
 	return nil
 }
@@ -4,7 +4,7 @@ import (
 	"context"
 	"encoding/json"
 
-	"github.com/tradik/mddb/services/mddb-mcp/internal/mddb"
+	mddb "github.com/tradik/mddb/services/mddbd/client"
 )
 
 // Handler handles MCP requests via stdio.
@@ -177,6 +177,7 @@ func (h *Handler) handleToolsList() map[string]interface{} {
 					"sort":        map[string]interface{}{"type": "string"},
 					"limit":       map[string]interface{}{"type": "integer"},
 					"offset":      map[string]interface{}{"type": "integer"},
+					"consistency": map[string]interface{}{"type": "string", "enum": []string{"eventual", "strong"}},
 				},
 				"required": []string{"collection"},
 			},
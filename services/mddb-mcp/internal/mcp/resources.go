@@ -7,7 +7,7 @@ import (
 	"net/url"
 	"strings"
 
-	"github.com/tradik/mddb/services/mddb-mcp/internal/mddb"
+	mddb "github.com/tradik/mddb/services/mddbd/client"
 )
 
 // readResource reads resource based on URI.
@@ -7,7 +7,7 @@ import (
 	"log"
 	"net/http"
 
-	"github.com/tradik/mddb/services/mddb-mcp/internal/mddb"
+	mddb "github.com/tradik/mddb/services/mddbd/client"
 )
 
 // Server implements MCP server for MDDB.
@@ -167,6 +167,7 @@ func (s *Server) handleTools(w http.ResponseWriter, r *http.Request) {
 					"sort":        map[string]interface{}{"type": "string"},
 					"limit":       map[string]interface{}{"type": "integer"},
 					"offset":      map[string]interface{}{"type": "integer"},
+					"consistency": map[string]interface{}{"type": "string", "enum": []string{"eventual", "strong"}},
 				},
 				"required": []string{"collection"},
 			},
@@ -4,8 +4,9 @@ import (
 	"context"
 	"encoding/json"
 	"fmt"
+	"time"
 
-	"github.com/tradik/mddb/services/mddb-mcp/internal/mddb"
+	mddb "github.com/tradik/mddb/services/mddbd/client"
 )
 
 // callTool invokes MCP tool.
@@ -56,11 +57,12 @@ func (s *Server) toolAddDocument(ctx context.Context, args map[string]interface{
 // toolSearchDocuments wyszukuje dokumenty.
 func (s *Server) toolSearchDocuments(ctx context.Context, args map[string]interface{}) (string, error) {
 	req := &mddb.SearchRequest{
-		Collection: getString(args, "collection"),
-		FilterMeta: getMetaMap(args, "filter_meta"),
-		Sort:       getString(args, "sort"),
-		Limit:      getInt(args, "limit"),
-		Offset:     getInt(args, "offset"),
+		Collection:  getString(args, "collection"),
+		FilterMeta:  getMetaMap(args, "filter_meta"),
+		Sort:        getString(args, "sort"),
+		Limit:       getInt(args, "limit"),
+		Offset:      getInt(args, "offset"),
+		Consistency: getString(args, "consistency"),
 	}
 
 	resp, err := s.client.Search(ctx, req)
@@ -186,7 +188,8 @@ func (s *Server) toolExport(ctx context.Context, args map[string]interface{}) (s
 // toolBackup creates backup.
 func (s *Server) toolBackup(ctx context.Context, args map[string]interface{}) (string, error) {
 	req := &mddb.BackupRequest{
-		To: getString(args, "to"),
+		To:          getString(args, "to"),
+		Compression: getString(args, "compression"),
 	}
 
 	resp, err := s.client.Backup(ctx, req)
@@ -194,13 +197,24 @@ func (s *Server) toolBackup(ctx context.Context, args map[string]interface{}) (s
 		return "", err
 	}
 
+	if resp.Manifest != nil {
+		return fmt.Sprintf("Backup created: %s (%d bytes, sha256 %s)", resp.Backup, resp.Manifest.Size, resp.Manifest.SHA256), nil
+	}
 	return fmt.Sprintf("Backup created: %s", resp.Backup), nil
 }
 
 // toolRestore przywraca backup.
 func (s *Server) toolRestore(ctx context.Context, args map[string]interface{}) (string, error) {
 	req := &mddb.RestoreRequest{
-		From: getString(args, "from"),
+		From:   getString(args, "from"),
+		SHA256: getString(args, "sha256"),
+	}
+	if at := getString(args, "at"); at != "" {
+		t, err := time.Parse(time.RFC3339, at)
+		if err != nil {
+			return "", fmt.Errorf("invalid at: %w", err)
+		}
+		req.At = t
 	}
 
 	resp, err := s.client.Restore(ctx, req)
@@ -208,7 +222,10 @@ func (s *Server) toolRestore(ctx context.Context, args map[string]interface{}) (
 		return "", err
 	}
 
-	return fmt.Sprintf("Database restored from: %s", resp.Restored), nil
+	if !req.At.IsZero() {
+		return fmt.Sprintf("Database restored to %s from: %s, %d changes replayed (previous database kept as %s)", req.At.Format(time.RFC3339), resp.Restored, resp.Replayed, resp.Previous), nil
+	}
+	return fmt.Sprintf("Database restored from: %s (previous database kept as %s)", resp.Restored, resp.Previous), nil
 }
 
 // Helper functions for parsing arguments
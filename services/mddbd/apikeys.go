@@ -0,0 +1,85 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"net/http"
+
+	json "github.com/goccy/go-json"
+	"google.golang.org/grpc/codes"
+)
+
+// APIKeyRequest is the body of POST /v1/auth/keys
+type APIKeyRequest struct {
+	Name  string   `json:"name"`
+	Roles []string `json:"roles"` // <read|write|admin>:<collection pattern>
+}
+
+// anonymous is the caller when authentication is disabled: everything is allowed
+var anonymous = newPrincipal("anonymous", "none", []string{RoleAdmin + ":*"})
+
+// caller returns the principal of ctx, or anonymous when auth is disabled
+func (s *Server) caller(ctx context.Context) *Principal {
+	if p := principalFrom(ctx); p != nil {
+		return p
+	}
+	return anonymous
+}
+
+// validateAPIKeyRequest checks the name and that every role parses
+func validateAPIKeyRequest(req APIKeyRequest) error {
+	if req.Name == "" {
+		return errors.New("missing name")
+	}
+	if len(req.Roles) == 0 {
+		return errors.New("missing roles")
+	}
+	for _, r := range req.Roles {
+		if _, err := parseGrant(r); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (s *Server) handleWhoAmI(w http.ResponseWriter, r *http.Request) {
+	ok(w, s.caller(r.Context()))
+}
+
+func (s *Server) handleAPIKeysList(w http.ResponseWriter, r *http.Request) {
+	keys, err := s.DB.APIKeys.List()
+	if err != nil {
+		writeError(w, err, codes.Internal)
+		return
+	}
+	ok(w, keys)
+}
+
+// handleAPIKeyCreate creates a key. The response is the only time its secret is shown.
+func (s *Server) handleAPIKeyCreate(w http.ResponseWriter, r *http.Request) {
+	var req APIKeyRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		bad(w, err)
+		return
+	}
+	if err := validateAPIKeyRequest(req); err != nil {
+		bad(w, err)
+		return
+	}
+	key, secret, err := s.DB.APIKeys.Create(req.Name, req.Roles)
+	if err != nil {
+		writeError(w, err, codes.Internal)
+		return
+	}
+	b, _ := json.Marshal(map[string]any{"id": key.ID, "name": key.Name, "roles": key.Roles, "createdAt": key.CreatedAt, "key": secret})
+	w.WriteHeader(http.StatusCreated)
+	_, _ = w.Write(b)
+}
+
+func (s *Server) handleAPIKeyDelete(w http.ResponseWriter, r *http.Request) {
+	if err := s.DB.APIKeys.Delete(r.PathValue("id")); err != nil {
+		writeError(w, err, codes.Internal)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
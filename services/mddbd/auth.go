@@ -0,0 +1,654 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"crypto"
+	"crypto/hmac"
+	"crypto/rsa"
+	_ "crypto/sha256"
+	_ "crypto/sha512"
+	"crypto/subtle"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	json "github.com/goccy/go-json"
+	"github.com/tradik/mddb/services/mddbd/proto"
+	"github.com/tradik/mddb/services/mddbd/storage"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+)
+
+// Roles, from least to most privileged. Each role includes the ones before it.
+const (
+	RoleRead  = "read"
+	RoleWrite = "write"
+	RoleAdmin = "admin"
+)
+
+var roleRank = map[string]int{RoleRead: 1, RoleWrite: 2, RoleAdmin: 3}
+
+var (
+	errUnauthenticated  = errors.New("authentication required")
+	errPermissionDenied = errors.New("permission denied")
+)
+
+// jwtLeeway tolerates clock skew when checking exp and nbf
+const jwtLeeway = 30 * time.Second
+
+// Grant gives a role on the collections matching Pattern, where * matches any run of characters
+type Grant struct {
+	Role    string
+	Pattern string
+}
+
+// parseGrant parses "<role>:<pattern>", e.g. "write:blog-*"
+func parseGrant(s string) (Grant, error) {
+	role, pattern, ok := strings.Cut(s, ":")
+	if !ok || roleRank[role] == 0 || pattern == "" {
+		return Grant{}, fmt.Errorf("invalid role %q: want <read|write|admin>:<collection pattern>", s)
+	}
+	return Grant{Role: role, Pattern: pattern}, nil
+}
+
+// matchPattern reports whether name matches pattern, where * matches any run of characters
+func matchPattern(pattern, name string) bool {
+	parts := strings.Split(pattern, "*")
+	if len(parts) == 1 {
+		return pattern == name
+	}
+	if !strings.HasPrefix(name, parts[0]) {
+		return false
+	}
+	name = name[len(parts[0]):]
+	for _, p := range parts[1 : len(parts)-1] {
+		i := strings.Index(name, p)
+		if i < 0 {
+			return false
+		}
+		name = name[i+len(p):]
+	}
+	return strings.HasSuffix(name, parts[len(parts)-1])
+}
+
+// Principal is an authenticated caller
+type Principal struct {
+	Subject string   `json:"subject"`
+	Method  string   `json:"method"` // api_key|jwt|admin_key
+	Roles   []string `json:"roles"`
+	grants  []Grant
+}
+
+func newPrincipal(subject, method string, roles []string) *Principal {
+	p := &Principal{Subject: subject, Method: method, Roles: roles}
+	for _, r := range roles {
+		// Unknown roles are ignored rather than rejected, so tokens can carry roles of other services
+		if g, err := parseGrant(r); err == nil {
+			p.grants = append(p.grants, g)
+		}
+	}
+	return p
+}
+
+// can reports whether p has role on collection. An empty collection stands for all
+// collections and needs a grant on the pattern "*".
+func (p *Principal) can(role, collection string) bool {
+	for _, g := range p.grants {
+		if roleRank[g.Role] < roleRank[role] {
+			continue
+		}
+		if g.Pattern == "*" || (collection != "" && matchPattern(g.Pattern, collection)) {
+			return true
+		}
+	}
+	return false
+}
+
+type principalKey struct{}
+
+// principalFrom returns the caller of ctx, or nil when auth is disabled
+func principalFrom(ctx context.Context) *Principal {
+	p, _ := ctx.Value(principalKey{}).(*Principal)
+	return p
+}
+
+// Auth authenticates API keys and JWTs and checks roles. A nil *Auth disables authentication.
+type Auth struct {
+	keys       *storage.APIKeyStore
+	adminKey   string         // bootstrap key with admin on all collections, not stored
+	hmacSecret []byte         // HS256/HS384/HS512
+	rsaKey     *rsa.PublicKey // RS256/RS384/RS512
+	issuer     string
+	audience   string
+	rolesClaim string
+}
+
+// newAuth configures authentication from the validated auth settings. It returns nil
+// when authentication is not enabled.
+func newAuth(cfg AuthConfig, db *storage.DB) (*Auth, error) {
+	if !cfg.Enabled {
+		return nil, nil
+	}
+	a := &Auth{
+		keys:       db.APIKeys,
+		adminKey:   string(cfg.AdminKey),
+		hmacSecret: []byte(cfg.JWTHMACSecret),
+		issuer:     cfg.JWTIssuer,
+		audience:   cfg.JWTAudience,
+		rolesClaim: cfg.JWTRolesClaim,
+	}
+	if cfg.JWTRSAPublicKey != "" {
+		key, err := loadRSAPublicKey(cfg.JWTRSAPublicKey)
+		if err != nil {
+			return nil, fmt.Errorf("auth.jwtRsaPublicKey: %w", err)
+		}
+		a.rsaKey = key
+	}
+	if a.adminKey == "" {
+		if keys, err := db.APIKeys.List(); err == nil && len(keys) == 0 && len(a.hmacSecret) == 0 && a.rsaKey == nil {
+			warnf("⚠️  Authentication is enabled without API keys, JWT keys or an admin key: every request will be rejected")
+		}
+	}
+	return a, nil
+}
+
+func loadRSAPublicKey(path string) (*rsa.PublicKey, error) {
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	block, _ := pem.Decode(b)
+	if block == nil {
+		return nil, errors.New("no PEM data")
+	}
+	var pub any
+	switch block.Type {
+	case "CERTIFICATE":
+		cert, err := x509.ParseCertificate(block.Bytes)
+		if err != nil {
+			return nil, err
+		}
+		pub = cert.PublicKey
+	case "RSA PUBLIC KEY":
+		pub, err = x509.ParsePKCS1PublicKey(block.Bytes)
+	default:
+		pub, err = x509.ParsePKIXPublicKey(block.Bytes)
+	}
+	if err != nil {
+		return nil, err
+	}
+	key, ok := pub.(*rsa.PublicKey)
+	if !ok {
+		return nil, errors.New("not an RSA public key")
+	}
+	return key, nil
+}
+
+// authenticate resolves a credential: the bootstrap admin key, a stored API key or a JWT
+func (a *Auth) authenticate(token string) (*Principal, error) {
+	switch {
+	case token == "":
+		return nil, errUnauthenticated
+	case a.adminKey != "" && subtle.ConstantTimeCompare([]byte(token), []byte(a.adminKey)) == 1:
+		return newPrincipal("admin", "admin_key", []string{RoleAdmin + ":*"}), nil
+	case strings.HasPrefix(token, storage.APIKeyPrefix):
+		key, err := a.keys.Verify(token)
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", errUnauthenticated, err)
+		}
+		return newPrincipal(key.Name, "api_key", key.Roles), nil
+	default:
+		return a.verifyJWT(token)
+	}
+}
+
+var jwtHashes = map[string]crypto.Hash{
+	"HS256": crypto.SHA256, "HS384": crypto.SHA384, "HS512": crypto.SHA512,
+	"RS256": crypto.SHA256, "RS384": crypto.SHA384, "RS512": crypto.SHA512,
+}
+
+// verifyJWT checks the signature and registered claims of a compact JWS and maps its
+// roles claim to grants. Only HS* (MDDB_JWT_HMAC_SECRET) and RS* (MDDB_JWT_RSA_PUBLIC_KEY)
+// are accepted.
+func (a *Auth) verifyJWT(token string) (*Principal, error) {
+	invalid := func(msg string) error { return fmt.Errorf("%w: invalid token: %s", errUnauthenticated, msg) }
+
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return nil, invalid("malformed")
+	}
+	var header struct {
+		Alg string `json:"alg"`
+	}
+	if b, err := base64.RawURLEncoding.DecodeString(parts[0]); err != nil || json.Unmarshal(b, &header) != nil {
+		return nil, invalid("bad header")
+	}
+	sig, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return nil, invalid("bad signature encoding")
+	}
+	hash, ok := jwtHashes[header.Alg]
+	if !ok {
+		return nil, invalid("unsupported alg " + header.Alg)
+	}
+	h := hash.New()
+	h.Write([]byte(parts[0] + "." + parts[1]))
+	digest := h.Sum(nil)
+	switch header.Alg[:2] {
+	case "HS":
+		if len(a.hmacSecret) == 0 {
+			return nil, invalid("HMAC tokens are not enabled")
+		}
+		mac := hmac.New(hash.New, a.hmacSecret)
+		mac.Write([]byte(parts[0] + "." + parts[1]))
+		if !hmac.Equal(sig, mac.Sum(nil)) {
+			return nil, invalid("bad signature")
+		}
+	case "RS":
+		if a.rsaKey == nil {
+			return nil, invalid("RSA tokens are not enabled")
+		}
+		if err := rsa.VerifyPKCS1v15(a.rsaKey, hash, digest, sig); err != nil {
+			return nil, invalid("bad signature")
+		}
+	}
+
+	payload, err := base64.RawURLEncoding.DecodeString(parts[1])
+	if err != nil {
+		return nil, invalid("bad payload")
+	}
+	var claims map[string]any
+	if err := json.Unmarshal(payload, &claims); err != nil {
+		return nil, invalid("bad payload")
+	}
+	now := time.Now()
+	if exp, ok := claims["exp"].(float64); ok && now.After(time.Unix(int64(exp), 0).Add(jwtLeeway)) {
+		return nil, invalid("expired")
+	}
+	if nbf, ok := claims["nbf"].(float64); ok && now.Add(jwtLeeway).Before(time.Unix(int64(nbf), 0)) {
+		return nil, invalid("not valid yet")
+	}
+	if a.issuer != "" && claims["iss"] != a.issuer {
+		return nil, invalid("wrong issuer")
+	}
+	if a.audience != "" && !hasAudience(claims["aud"], a.audience) {
+		return nil, invalid("wrong audience")
+	}
+
+	sub, _ := claims["sub"].(string)
+	return newPrincipal(sub, "jwt", stringList(claims[a.rolesClaim])), nil
+}
+
+// hasAudience checks an aud claim, which may be a string or a list of strings
+func hasAudience(aud any, want string) bool {
+	for _, a := range stringList(aud) {
+		if a == want {
+			return true
+		}
+	}
+	return false
+}
+
+// stringList converts a claim that is a string or a list of strings ("a b" is split on spaces)
+func stringList(v any) []string {
+	switch v := v.(type) {
+	case string:
+		return strings.Fields(v)
+	case []any:
+		out := make([]string, 0, len(v))
+		for _, x := range v {
+			if s, ok := x.(string); ok {
+				out = append(out, s)
+			}
+		}
+		return out
+	}
+	return nil
+}
+
+// authorize checks role on every collection; no collections means all collections
+func (p *Principal) authorize(role string, collections []string) error {
+	if len(collections) == 0 {
+		if !p.can(role, "") {
+			return fmt.Errorf("%w: %s on all collections required", errPermissionDenied, role)
+		}
+		return nil
+	}
+	for _, c := range collections {
+		if !p.can(role, c) {
+			return fmt.Errorf("%w: %s on collection %q required", errPermissionDenied, role, c)
+		}
+	}
+	return nil
+}
+
+// scope tells the gRPC interceptors where a request names the collections it touches
+type scope int
+
+const (
+	scopeNone scope = iota // authentication only
+	scopeAll               // every collection
+	scopeBody              // the request message
+)
+
+// maxAuthBodySize limits how much of a JSON body is buffered to find its collections
+const maxAuthBodySize = 64 << 20
+
+// collectionsOf finds the collections an HTTP request touches. A nil collectionsOf only
+// authenticates; an empty result stands for every collection.
+type collectionsOf func(r *http.Request) ([]string, error)
+
+// allCollections is for routes that act on the whole database
+func allCollections(*http.Request) ([]string, error) { return nil, nil }
+
+// queryCollection reads the collection query parameter
+func queryCollection(r *http.Request) ([]string, error) {
+	return []string{r.URL.Query().Get("collection")}, nil
+}
+
+// pathCollection reads the {collection} path segment
+func pathCollection(r *http.Request) ([]string, error) {
+	return []string{r.PathValue("collection")}, nil
+}
+
+// collectionNamer is a JSON request body that names the collections it touches
+type collectionNamer interface {
+	collections() []string
+}
+
+// bodyCollections decodes a JSON body into T, the type its handler decodes, so the check
+// covers exactly the collections the handler acts on. The body is buffered and put back
+// for the handler; one that doesn't decode is rejected.
+func bodyCollections[T any, PT interface {
+	*T
+	collectionNamer
+}](r *http.Request) ([]string, error) {
+	var req T
+	if err := decodeAuthBody(r, &req); err != nil {
+		return nil, err
+	}
+	return PT(&req).collections(), nil
+}
+
+// batchCollections finds the collection of a batch request like readBatchRequest does
+func batchCollections(r *http.Request) ([]string, error) {
+	if mt, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type")); mt == "application/x-ndjson" {
+		return queryCollection(r)
+	}
+	var req BatchRequest
+	if err := decodeAuthBody(r, &req); err != nil {
+		return nil, err
+	}
+	return []string{batchCollection(r, &req)}, nil
+}
+
+// decodeAuthBody buffers a JSON body, puts it back for the handler and decodes it into v
+func decodeAuthBody(r *http.Request, v any) error {
+	if r.Body == nil {
+		return errors.New("missing request body")
+	}
+	b, err := io.ReadAll(io.LimitReader(r.Body, maxAuthBodySize+1))
+	if err != nil {
+		return err
+	}
+	if len(b) > maxAuthBodySize {
+		return fmt.Errorf("request body larger than %d bytes", maxAuthBodySize)
+	}
+	r.Body = io.NopCloser(bytes.NewReader(b))
+	return json.NewDecoder(bytes.NewReader(b)).Decode(v)
+}
+
+// Collections named by the request bodies, as their handlers read them
+
+func (r *AddRequest) collections() []string              { return []string{r.Collection} }
+func (r *PatchRequest) collections() []string            { return []string{r.Collection} }
+func (r *GetRequest) collections() []string              { return []string{r.Collection} }
+func (r *SearchRequest) collections() []string           { return []string{r.Collection} }
+func (r *ExportRequest) collections() []string           { return []string{r.Collection} }
+func (r *TruncateRequest) collections() []string         { return []string{r.Collection} }
+func (r *DeleteRequest) collections() []string           { return []string{r.Collection} }
+func (r *DeleteCollectionRequest) collections() []string { return []string{r.Collection} }
+func (r *SchemaRequest) collections() []string           { return []string{r.Collection} }
+func (r *schemaSetRequest) collections() []string        { return []string{r.Collection} }
+func (r *fsckRequest) collections() []string             { return []string{r.Collection} } // empty: all
+func (r *collectionInfoRequest) collections() []string   { return []string{r.Name} }
+
+func (r *CollectionRequest) collections() []string {
+	if r.NewName != "" {
+		return []string{r.Name, r.NewName}
+	}
+	return []string{r.Name}
+}
+
+// collections lists every collection an operation names; storage rejects operations
+// that set more than one of add, patch and delete
+func (r *TransactionRequest) collections() []string {
+	var out []string
+	for _, op := range r.Ops {
+		if op.Add != nil {
+			out = append(out, op.Add.Collection)
+		}
+		if op.Patch != nil {
+			out = append(out, op.Patch.Collection)
+		}
+		if op.Delete != nil {
+			out = append(out, op.Delete.Collection)
+		}
+	}
+	return out
+}
+
+// bearerToken returns the credential of an Authorization: Bearer or X-API-Key header
+func bearerToken(authorization, apiKey string) string {
+	if apiKey != "" {
+		return apiKey
+	}
+	if t, ok := strings.CutPrefix(authorization, "Bearer "); ok {
+		return strings.TrimSpace(t)
+	}
+	return ""
+}
+
+// authorize wraps next with authentication and a role check on the collections found by
+// target. The principal is available to next through principalFrom.
+func (s *Server) authorize(role string, target collectionsOf, next http.HandlerFunc) http.HandlerFunc {
+	if s.Auth == nil {
+		return next
+	}
+	return func(w http.ResponseWriter, r *http.Request) {
+		p, err := s.Auth.authenticate(bearerToken(r.Header.Get("Authorization"), r.Header.Get("X-API-Key")))
+		if err != nil {
+			w.Header().Set("WWW-Authenticate", `Bearer realm="mddb"`)
+			writeError(w, err, codes.Unauthenticated)
+			return
+		}
+		if target != nil {
+			collections, err := target(r)
+			if err != nil {
+				bad(w, err)
+				return
+			}
+			if err := p.authorize(role, collections); err != nil {
+				writeError(w, err, codes.PermissionDenied)
+				return
+			}
+		}
+		next(w, r.WithContext(context.WithValue(r.Context(), principalKey{}, p)))
+	}
+}
+
+// grpcRule is the role an RPC needs and where its request names collections
+type grpcRule struct {
+	role  string
+	scope scope // scopeNone, scopeAll or scopeBody (the request message)
+}
+
+// grpcRules covers every mddb.MDDB method; methods missing here need admin on all collections
+var grpcRules = map[string]grpcRule{
+	"Add":                {RoleWrite, scopeBody},
+	"Patch":              {RoleWrite, scopeBody},
+	"Transaction":        {RoleWrite, scopeBody},
+	"AddBatch":           {RoleWrite, scopeBody},
+	"UpdateBatch":        {RoleWrite, scopeBody},
+	"DeleteBatch":        {RoleWrite, scopeBody},
+	"Delete":             {RoleWrite, scopeBody},
+	"Import":             {RoleWrite, scopeBody},
+	"Get":                {RoleRead, scopeBody},
+	"Search":             {RoleRead, scopeBody},
+	"Export":             {RoleRead, scopeBody},
+	"GetSchema":          {RoleRead, scopeBody},
+	"DescribeCollection": {RoleRead, scopeBody},
+	"ListCollections":    {RoleRead, scopeNone}, // filtered to readable collections
+	"Stats":              {RoleRead, scopeAll},
+	"Health":             {RoleRead, scopeNone},
+	"SetSchema":          {RoleAdmin, scopeBody},
+	"DeleteSchema":       {RoleAdmin, scopeBody},
+	"CreateCollection":   {RoleAdmin, scopeBody},
+	"UpdateCollection":   {RoleAdmin, scopeBody},
+	"RenameCollection":   {RoleAdmin, scopeBody},
+	"CloneCollection":    {RoleAdmin, scopeBody},
+	"DeleteCollection":   {RoleAdmin, scopeBody},
+	"Truncate":           {RoleAdmin, scopeBody},
+	"Fsck":               {RoleAdmin, scopeBody},
+	"Compact":            {RoleAdmin, scopeAll},
+	"Backup":             {RoleAdmin, scopeAll},
+	"Restore":            {RoleAdmin, scopeAll},
+	"StreamBackup":       {RoleAdmin, scopeAll},
+	"StreamRestore":      {RoleAdmin, scopeAll},
+	"Dump":               {RoleAdmin, scopeAll},
+	"Load":               {RoleAdmin, scopeAll},
+	"CreateAPIKey":       {RoleAdmin, scopeAll},
+	"ListAPIKeys":        {RoleAdmin, scopeAll},
+	"DeleteAPIKey":       {RoleAdmin, scopeAll},
+	"WhoAmI":             {RoleRead, scopeNone},
+}
+
+// messageCollections lists the collections a request message refers to
+func messageCollections(m any) []string {
+	var out []string
+	switch m := m.(type) {
+	case *proto.TransactionRequest:
+		for _, op := range m.Ops {
+			switch {
+			case op.GetAdd() != nil:
+				out = append(out, op.GetAdd().Collection)
+			case op.GetPatch() != nil:
+				out = append(out, op.GetPatch().Collection)
+			case op.GetDelete() != nil:
+				out = append(out, op.GetDelete().Collection)
+			}
+		}
+	case *proto.ImportChunk:
+		out = append(out, m.GetOptions().GetCollection())
+	case *proto.CopyCollectionRequest:
+		out = append(out, m.Name, m.NewName)
+	case interface{ GetCollection() string }:
+		out = append(out, m.GetCollection())
+	case interface{ GetName() string }:
+		out = append(out, m.GetName())
+	}
+	return out
+}
+
+// grpcAuthorize authenticates the metadata of ctx and checks the rule of method against msg
+func (a *Auth) grpcAuthorize(ctx context.Context, method string, msg any) (*Principal, error) {
+	p, err := a.grpcAuthenticate(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return p, grpcCheck(p, method, msg)
+}
+
+// grpcAuthenticate returns the caller named by the metadata of ctx
+func (a *Auth) grpcAuthenticate(ctx context.Context) (*Principal, error) {
+	md, _ := metadata.FromIncomingContext(ctx)
+	first := func(k string) string {
+		if v := md.Get(k); len(v) > 0 {
+			return v[0]
+		}
+		return ""
+	}
+	return a.authenticate(bearerToken(first("authorization"), first("x-api-key")))
+}
+
+// grpcCheck checks the rule of method for p against msg
+func grpcCheck(p *Principal, method string, msg any) error {
+	rule, ok := grpcRules[method]
+	if !ok {
+		rule = grpcRule{RoleAdmin, scopeAll}
+	}
+	switch rule.scope {
+	case scopeAll:
+		return p.authorize(rule.role, nil)
+	case scopeBody:
+		return p.authorize(rule.role, messageCollections(msg))
+	}
+	return nil
+}
+
+// mddbMethod returns the method name of an mddb.MDDB RPC, or false for other services
+func mddbMethod(fullMethod string) (string, bool) {
+	return strings.CutPrefix(fullMethod, "/"+proto.MDDB_ServiceDesc.ServiceName+"/")
+}
+
+// unaryInterceptor enforces auth on unary mddb.MDDB RPCs; health and reflection stay open
+func (a *Auth) unaryInterceptor(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+	method, ok := mddbMethod(info.FullMethod)
+	if !ok {
+		return handler(ctx, req)
+	}
+	p, err := a.grpcAuthorize(ctx, method, req)
+	if err != nil {
+		return nil, grpcError(err, codes.PermissionDenied)
+	}
+	return handler(context.WithValue(ctx, principalKey{}, p), req)
+}
+
+// streamInterceptor enforces auth on streaming RPCs. The caller is authenticated when the
+// stream opens and its rule is checked when the first message arrives.
+func (a *Auth) streamInterceptor(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	method, ok := mddbMethod(info.FullMethod)
+	if !ok {
+		return handler(srv, ss)
+	}
+	p, err := a.grpcAuthenticate(ss.Context())
+	if err != nil {
+		return grpcError(err, codes.PermissionDenied)
+	}
+	return handler(srv, &authStream{ServerStream: ss, ctx: context.WithValue(ss.Context(), principalKey{}, p), principal: p, method: method})
+}
+
+// authStream authorizes a stream on its first received message, which names the collection.
+// Its context carries the principal like the context of a unary call.
+type authStream struct {
+	grpc.ServerStream
+	ctx        context.Context
+	principal  *Principal
+	method     string
+	authorized bool
+}
+
+func (s *authStream) Context() context.Context { return s.ctx }
+
+func (s *authStream) RecvMsg(m any) error {
+	if err := s.ServerStream.RecvMsg(m); err != nil {
+		return err
+	}
+	if !s.authorized {
+		if err := grpcCheck(s.principal, s.method, m); err != nil {
+			return grpcError(err, codes.PermissionDenied)
+		}
+		s.authorized = true
+	}
+	return nil
+}
@@ -0,0 +1,106 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"testing"
+
+	"github.com/tradik/mddb/services/mddbd/proto"
+	"github.com/tradik/mddb/services/mddbd/storage"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// The collections a request is authorized for must be the ones its handler acts on, whatever
+// else the body or query string carries.
+func TestAuthorizeBodyCollections(t *testing.T) {
+	s, ts := newTestServer(t, true)
+	_, key, err := s.DB.APIKeys.Create("public-writer", []string{"write:public"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.DB.Add(storage.AddRequest{Collection: "secret", Key: "k", Lang: "en", ContentMD: "secret"}); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name, path, body string
+		want             int
+	}{
+		{"add to granted collection", "/v1/add", `{"collection":"public","key":"k","lang":"en","contentMd":"x"}`, http.StatusOK},
+		{"add with mistyped field", "/v1/add?collection=public", `{"collection":"secret","key":"x","lang":"en","contentMd":"x","newName":1}`, http.StatusForbidden},
+		{"get with mistyped field", "/v1/get?collection=public", `{"collection":"secret","key":"k","lang":"en","name":1}`, http.StatusForbidden},
+		{"transaction with mistyped field", "/v1/transaction?collection=public", `{"ops":[{"delete":{"collection":"secret","key":"k","lang":"en"}}],"name":1}`, http.StatusForbidden},
+		{"transaction with differently cased op", "/v1/transaction?collection=public", `{"ops":[{"DELETE":{"Collection":"secret","key":"k","lang":"en"}}]}`, http.StatusForbidden},
+		{"body that doesn't decode", "/v1/add?collection=public", `{"collection":1}`, http.StatusBadRequest},
+		{"batch to query collection", "/v1/add-batch?collection=public", `{"documents":[{"key":"b","lang":"en","contentMd":"x"}]}`, http.StatusOK},
+		{"batch to body collection", "/v1/add-batch?collection=public", `{"collection":"secret","documents":[{"key":"b","lang":"en","contentMd":"x"}]}`, http.StatusForbidden},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if resp := post(t, ts.URL+tt.path, key, tt.body); resp.StatusCode != tt.want {
+				t.Errorf("status %d, want %d", resp.StatusCode, tt.want)
+			}
+		})
+	}
+
+	if doc, err := s.DB.Get("secret", "k", "en"); err != nil || doc.ContentMD != "secret" {
+		t.Errorf("secret/k changed: %+v, %v", doc, err)
+	}
+	for _, k := range []string{"x", "b"} {
+		if _, err := s.DB.Get("secret", k, "en"); !errors.Is(err, storage.ErrNotFound) {
+			t.Errorf("secret/%s was written: %v", k, err)
+		}
+	}
+}
+
+// testStream is a server stream that receives one message
+type testStream struct {
+	grpc.ServerStream
+	ctx  context.Context
+	opts *proto.ImportOptions
+}
+
+func (s *testStream) Context() context.Context { return s.ctx }
+
+func (s *testStream) RecvMsg(m any) error {
+	m.(*proto.ImportChunk).Options = s.opts
+	return nil
+}
+
+// Streaming handlers see the caller in the stream context, as unary handlers do
+func TestAuthStreamPrincipal(t *testing.T) {
+	s, _ := newTestServer(t, true)
+	_, key, err := s.DB.APIKeys.Create("importer", []string{"write:public"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	info := &grpc.StreamServerInfo{FullMethod: "/" + proto.MDDB_ServiceDesc.ServiceName + "/Import"}
+	open := func(key, collection string) (*Principal, error) {
+		ctx := context.Background()
+		if key != "" {
+			ctx = metadata.NewIncomingContext(ctx, metadata.Pairs("x-api-key", key))
+		}
+		ss := &testStream{ctx: ctx, opts: &proto.ImportOptions{Collection: collection}}
+		var caller *Principal
+		err := s.Auth.streamInterceptor(nil, ss, info, func(_ any, ss grpc.ServerStream) error {
+			caller = s.caller(ss.Context())
+			return ss.RecvMsg(new(proto.ImportChunk))
+		})
+		return caller, err
+	}
+
+	caller, err := open(key, "public")
+	if err != nil || caller.Subject != "importer" || caller.can(RoleAdmin, "public") {
+		t.Errorf("stream caller %+v, %v; want the importer key", caller, err)
+	}
+	if _, err := open(key, "secret"); status.Code(err) != codes.PermissionDenied {
+		t.Errorf("stream to an ungranted collection: %v, want PermissionDenied", err)
+	}
+	if caller, err := open("", "public"); status.Code(err) != codes.Unauthenticated || caller != nil {
+		t.Errorf("stream without credentials: %v (caller %+v), want Unauthenticated before the handler runs", err, caller)
+	}
+}
@@ -0,0 +1,270 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strconv"
+	"time"
+
+	json "github.com/goccy/go-json"
+	"github.com/tradik/mddb/services/mddbd/proto"
+	"github.com/tradik/mddb/services/mddbd/storage"
+	"google.golang.org/grpc/codes"
+)
+
+var (
+	// errBackupName rejects server-side backup names that would leave the backup directory
+	errBackupName     = errors.New("backup name must be a relative path inside the backup directory")
+	errBackupNotFound = errors.New("backup not found")
+	errRollbackAt     = errors.New("rollback cannot be combined with at")
+)
+
+const (
+	manifestSuffix   = ".manifest.json"       // appended to a backup file name for its manifest
+	backupTimeFormat = "20060102T150405.000Z" // generated backup names sort by time
+)
+
+// writeBackupFile writes a backup to name inside the backup directory, with its manifest
+// next to it. The file only appears under its name once it is complete and synced.
+func (s *Server) writeBackupFile(name string, opts storage.BackupOptions) (string, *storage.BackupManifest, error) {
+	compression, err := storage.BackupCompression(opts.Compression)
+	if err != nil {
+		return "", nil, err
+	}
+	if name == "" {
+		name = "backup-" + time.Now().UTC().Format(backupTimeFormat) + storage.BackupExtension(compression)
+	}
+	if !filepath.IsLocal(name) {
+		return "", nil, fmt.Errorf("%w: %q", errBackupName, name)
+	}
+	if err := os.MkdirAll(s.BackupDir, 0700); err != nil {
+		return "", nil, err
+	}
+	// os.Root also refuses symlinks that point out of the directory
+	root, err := os.OpenRoot(s.BackupDir)
+	if err != nil {
+		return "", nil, err
+	}
+	defer func() { _ = root.Close() }()
+	if dir := filepath.Dir(name); dir != "." {
+		if err := root.MkdirAll(dir, 0700); err != nil {
+			return "", nil, err
+		}
+	}
+
+	tmp := name + ".tmp"
+	f, err := root.OpenFile(tmp, os.O_WRONLY|os.O_CREATE|os.O_TRUNC, 0600)
+	if err != nil {
+		return "", nil, err
+	}
+	m, err := s.DB.WriteBackup(f, storage.BackupOptions{Compression: compression})
+	if err == nil {
+		err = f.Sync()
+	}
+	err = errors.Join(err, f.Close())
+	if err == nil {
+		err = root.Rename(tmp, name)
+	}
+	if err != nil {
+		_ = root.Remove(tmp)
+		return "", nil, err
+	}
+	data, err := json.MarshalIndent(m, "", "  ")
+	if err != nil {
+		return "", nil, err
+	}
+	if err := root.WriteFile(name+manifestSuffix, data, 0600); err != nil {
+		return "", nil, err
+	}
+	return name, m, nil
+}
+
+// openBackup opens a backup file inside the backup directory
+func (s *Server) openBackup(name string) (*os.File, error) {
+	if !filepath.IsLocal(name) {
+		return nil, fmt.Errorf("%w: %q", errBackupName, name)
+	}
+	root, err := os.OpenRoot(s.BackupDir)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, fmt.Errorf("%w: %s", errBackupNotFound, name)
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = root.Close() }()
+	f, err := root.Open(name)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, fmt.Errorf("%w: %s", errBackupNotFound, name)
+	}
+	return f, err
+}
+
+// restoreOptions drains the server before the verified backup is swapped in
+func (s *Server) restoreOptions(sha256 string) storage.RestoreOptions {
+	return storage.RestoreOptions{SHA256: sha256, Quiesce: s.quiesce}
+}
+
+// restoreBackup restores the named backup from the backup directory, with rollback the
+// file the last restore replaced, or with at the database as it was at that time. It
+// returns what was restored.
+func (s *Server) restoreBackup(name, sha256 string, rollback bool, at time.Time) (string, *storage.RestoreResult, error) {
+	var res *storage.RestoreResult
+	var err error
+	switch {
+	case rollback && !at.IsZero():
+		return "", nil, errRollbackAt
+	case rollback:
+		res, err = s.DB.Rollback(s.restoreOptions(sha256))
+		if err == nil {
+			name = res.Previous
+		}
+	case !at.IsZero():
+		name, res, err = s.restoreAt(name, at, sha256)
+	case name == "":
+		return "", nil, fmt.Errorf("%w: missing from", storage.ErrMissingFields)
+	default:
+		var f *os.File
+		if f, err = s.openBackup(name); err != nil {
+			return "", nil, err
+		}
+		defer func() { _ = f.Close() }()
+		res, err = s.DB.RestoreFrom(f, s.restoreOptions(sha256))
+	}
+	if err != nil {
+		return "", nil, err
+	}
+	s.backupAfterRestore()
+	return name, res, nil
+}
+
+// logRestore records which backup replaced the database
+func logRestore(from string, res *storage.RestoreResult) {
+	infof("Restored %s (%s, %d bytes, tx %d, %d changes replayed) in %dms; previous file kept as %s",
+		from, res.Compression, res.Size, res.TxID, res.Replayed, res.DurationMs, res.Previous)
+}
+
+// --- HTTP handlers
+
+func (s *Server) handleBackup(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	name, m, err := s.writeBackupFile(q.Get("to"), storage.BackupOptions{Compression: q.Get("compression")})
+	if err != nil {
+		writeError(w, err, codes.Internal)
+		return
+	}
+	ok(w, struct {
+		Backup   string                  `json:"backup"`
+		Manifest *storage.BackupManifest `json:"manifest"`
+	}{name, m})
+}
+
+// handleBackupStream streams a backup to the caller. The manifest follows the body as
+// HTTP trailers; a failure mid-stream aborts the connection so the download can't
+// pass for complete.
+func (s *Server) handleBackupStream(w http.ResponseWriter, r *http.Request) {
+	compression, err := storage.BackupCompression(r.URL.Query().Get("compression"))
+	if err != nil {
+		bad(w, err)
+		return
+	}
+
+	contentType := "application/octet-stream"
+	if compression == storage.BackupCompressionZstd {
+		contentType = "application/zstd"
+	}
+	h := w.Header()
+	h.Set("Content-Type", contentType)
+	h.Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", fmt.Sprintf("mddb-%d%s", time.Now().Unix(), storage.BackupExtension(compression))))
+	h.Set("X-Backup-Format", storage.BackupFormat)
+	h.Set("X-Backup-Compression", compression)
+	h.Set("Trailer", "X-Backup-Sha256, X-Backup-Size, X-Backup-Db-Size, X-Backup-Tx-Id, X-Backup-Created-At")
+
+	m, err := s.DB.WriteBackup(w, storage.BackupOptions{Compression: compression})
+	if err != nil {
+		errorf("Backup stream failed: %v", err)
+		panic(http.ErrAbortHandler)
+	}
+	h.Set("X-Backup-Sha256", m.SHA256)
+	h.Set("X-Backup-Size", strconv.FormatInt(m.Size, 10))
+	h.Set("X-Backup-Db-Size", strconv.FormatInt(m.DBSize, 10))
+	h.Set("X-Backup-Tx-Id", strconv.Itoa(m.TxID))
+	h.Set("X-Backup-Created-At", strconv.FormatInt(m.CreatedAt, 10))
+}
+
+// restoreResponse is the HTTP body of a completed restore
+type restoreResponse struct {
+	Restored string `json:"restored"`
+	*storage.RestoreResult
+}
+
+func (s *Server) handleRestore(w http.ResponseWriter, r *http.Request) {
+	var body struct {
+		From     string `json:"from"`
+		SHA256   string `json:"sha256"`
+		Rollback bool   `json:"rollback"`
+		At       string `json:"at"` // RFC 3339; restore the database as it was then
+	}
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		bad(w, err)
+		return
+	}
+	var at time.Time
+	if body.At != "" {
+		var err error
+		if at, err = time.Parse(time.RFC3339Nano, body.At); err != nil {
+			bad(w, fmt.Errorf("invalid at: %w", err))
+			return
+		}
+	}
+	from, res, err := s.restoreBackup(body.From, body.SHA256, body.Rollback, at)
+	if err != nil {
+		writeError(w, err, codes.Internal)
+		return
+	}
+	logRestore(from, res)
+	ok(w, restoreResponse{Restored: from, RestoreResult: res})
+}
+
+// handleRestoreUpload restores the backup in the request body, plain or zstd-compressed
+func (s *Server) handleRestoreUpload(w http.ResponseWriter, r *http.Request) {
+	res, err := s.DB.RestoreFrom(r.Body, s.restoreOptions(r.URL.Query().Get("sha256")))
+	if err != nil {
+		writeError(w, err, codes.Internal)
+		return
+	}
+	logRestore("upload", res)
+	s.backupAfterRestore()
+	ok(w, restoreResponse{Restored: "upload", RestoreResult: res})
+}
+
+// --- gRPC
+
+// restoreResponseProto converts a restore result to its gRPC message
+func restoreResponseProto(from string, res *storage.RestoreResult) *proto.RestoreResponse {
+	return &proto.RestoreResponse{
+		Restored:    from,
+		Previous:    res.Previous,
+		Compression: res.Compression,
+		Sha256:      res.SHA256,
+		Size:        res.Size,
+		TxId:        int64(res.TxID),
+		DurationMs:  res.DurationMs,
+		Replayed:    int64(res.Replayed),
+	}
+}
+
+// backupManifestProto converts a manifest to its gRPC message
+func backupManifestProto(m *storage.BackupManifest) *proto.BackupManifest {
+	return &proto.BackupManifest{
+		Format:      m.Format,
+		Compression: m.Compression,
+		CreatedAt:   m.CreatedAt,
+		TxId:        int64(m.TxID),
+		DbSize:      m.DBSize,
+		Size:        m.Size,
+		Sha256:      m.SHA256,
+	}
+}
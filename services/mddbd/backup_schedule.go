@@ -0,0 +1,238 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	json "github.com/goccy/go-json"
+	"github.com/tradik/mddb/services/mddbd/storage"
+)
+
+const (
+	autoBackupPrefix = "auto-"   // names the scheduler's backups; retention only removes those
+	changeLogDirName = "changes" // change log segments, inside the backup directory
+)
+
+// BackupSchedule configures the built-in backup scheduler. With an Interval set, every
+// committed write is also archived in the change log, so a restore can roll the newest
+// earlier backup forward to any point in time.
+type BackupSchedule struct {
+	Interval    time.Duration `yaml:"interval"` // 0 disables scheduled backups and the change log
+	Compression string        `yaml:"compression"`
+	KeepHourly  int           `yaml:"keepHourly"` // keep the newest backup of each of the last KeepHourly hours that have one
+	KeepDaily   int           `yaml:"keepDaily"`  // ... of the last KeepDaily days
+	KeepWeekly  int           `yaml:"keepWeekly"` // ... of the last KeepWeekly ISO weeks
+}
+
+// retain marks the backups to keep, given their times newest first. The newest backup is
+// always kept.
+func (b BackupSchedule) retain(times []time.Time) []bool {
+	keep := make([]bool, len(times))
+	if len(times) > 0 {
+		keep[0] = true
+	}
+	periods := []struct {
+		n  int
+		of func(time.Time) string
+	}{
+		{b.KeepHourly, func(t time.Time) string { return t.Format("2006010215") }},
+		{b.KeepDaily, func(t time.Time) string { return t.Format("20060102") }},
+		{b.KeepWeekly, func(t time.Time) string { y, w := t.ISOWeek(); return fmt.Sprintf("%d-%02d", y, w) }},
+	}
+	for _, p := range periods {
+		var last string
+		kept := 0
+		for i := 0; i < len(times) && kept < p.n; i++ {
+			if period := p.of(times[i].UTC()); period != last {
+				keep[i] = true
+				last = period
+				kept++
+			}
+		}
+	}
+	return keep
+}
+
+// runBackupSchedule takes a backup every Interval and right after every restore, so the
+// restored database can be rolled forward too. It returns on shutdown.
+func (s *Server) runBackupSchedule() {
+	infof("Scheduled backups every %s to %s (keep %d hourly, %d daily, %d weekly)",
+		s.Backups.Interval, s.BackupDir, s.Backups.KeepHourly, s.Backups.KeepDaily, s.Backups.KeepWeekly)
+	t := time.NewTicker(s.Backups.Interval)
+	defer t.Stop()
+	for {
+		select {
+		case <-t.C:
+		case <-s.backupNow:
+		case <-s.stopping:
+			return
+		}
+		if err := s.scheduledBackup(); err != nil {
+			errorf("Scheduled backup failed: %v", err)
+		}
+	}
+}
+
+// backupAfterRestore asks the scheduler for a backup of the restored database
+func (s *Server) backupAfterRestore() {
+	select {
+	case s.backupNow <- struct{}{}:
+	default:
+	}
+}
+
+// scheduledBackup takes a backup and applies the retention policy
+func (s *Server) scheduledBackup() error {
+	// Every change in the segments before the rotation is in the backup
+	if err := s.DB.RotateChangeLog(); err != nil {
+		return err
+	}
+	started := time.Now().UTC()
+	name := autoBackupPrefix + started.Format(backupTimeFormat) + storage.BackupExtension(s.Backups.Compression)
+	name, m, err := s.writeBackupFile(name, storage.BackupOptions{Compression: s.Backups.Compression})
+	s.Metrics.backupDone(started, err)
+	if err != nil {
+		return err
+	}
+	infof("Scheduled backup %s (%d bytes, tx %d) in %s", name, m.Size, m.TxID, time.Since(started).Round(time.Millisecond))
+	return s.pruneBackups()
+}
+
+// scheduledBackupFile is a backup taken by the scheduler
+type scheduledBackupFile struct {
+	name    string
+	started time.Time
+}
+
+// scheduledBackups lists the scheduler's backups, newest first
+func (s *Server) scheduledBackups() ([]scheduledBackupFile, error) {
+	entries, err := os.ReadDir(s.BackupDir)
+	if err != nil {
+		return nil, err
+	}
+	var backups []scheduledBackupFile
+	for _, e := range entries {
+		name := e.Name()
+		if e.IsDir() || !strings.HasPrefix(name, autoBackupPrefix) || strings.HasSuffix(name, manifestSuffix) || strings.HasSuffix(name, ".tmp") {
+			continue
+		}
+		stamp := strings.TrimPrefix(name, autoBackupPrefix)
+		if len(stamp) < len(backupTimeFormat) {
+			continue
+		}
+		started, err := time.Parse(backupTimeFormat, stamp[:len(backupTimeFormat)])
+		if err != nil {
+			continue
+		}
+		backups = append(backups, scheduledBackupFile{name, started})
+	}
+	sort.Slice(backups, func(i, j int) bool { return backups[i].started.After(backups[j].started) })
+	return backups, nil
+}
+
+// pruneBackups deletes the scheduled backups the retention policy no longer keeps, and the
+// change log segments only they needed
+func (s *Server) pruneBackups() error {
+	backups, err := s.scheduledBackups()
+	if err != nil || len(backups) == 0 {
+		return err
+	}
+	times := make([]time.Time, len(backups))
+	for i, b := range backups {
+		times[i] = b.started
+	}
+	keep := s.Backups.retain(times)
+	oldest := backups[0].started
+	for i, b := range backups {
+		if keep[i] {
+			oldest = b.started
+			continue
+		}
+		path := filepath.Join(s.BackupDir, b.name)
+		if err := errors.Join(os.Remove(path), os.Remove(path+manifestSuffix)); err != nil && !errors.Is(err, fs.ErrNotExist) {
+			return err
+		}
+		infof("Removed backup %s (retention)", b.name)
+	}
+	n, err := s.DB.PruneChangeLog(oldest)
+	if n > 0 {
+		infof("Removed %d change log segments older than backup %s", n, oldest.Format(backupTimeFormat))
+	}
+	return err
+}
+
+// backupsBefore lists the backups with a manifest that were started at or before at,
+// newest first: the candidates a point-in-time restore can roll forward
+func (s *Server) backupsBefore(at time.Time) ([]string, error) {
+	type candidate struct {
+		name      string
+		createdAt int64
+	}
+	var found []candidate
+	err := filepath.WalkDir(s.BackupDir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() && path == filepath.Join(s.BackupDir, changeLogDirName) {
+			return filepath.SkipDir
+		}
+		if d.IsDir() || !strings.HasSuffix(path, manifestSuffix) {
+			return nil
+		}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		var m storage.BackupManifest
+		if json.Unmarshal(data, &m) != nil || m.CreatedAt > at.Unix() {
+			return nil
+		}
+		name, err := filepath.Rel(s.BackupDir, strings.TrimSuffix(path, manifestSuffix))
+		if err != nil {
+			return err
+		}
+		found = append(found, candidate{name, m.CreatedAt})
+		return nil
+	})
+	if errors.Is(err, fs.ErrNotExist) {
+		err = nil
+	}
+	sort.SliceStable(found, func(i, j int) bool { return found[i].createdAt > found[j].createdAt })
+	names := make([]string, len(found))
+	for i, c := range found {
+		names[i] = c.name
+	}
+	return names, err
+}
+
+// restoreAt restores the database as it was at: from, or else the newest backup that
+// predates at, rolled forward with the change log
+func (s *Server) restoreAt(from string, at time.Time, sha256 string) (string, *storage.RestoreResult, error) {
+	candidates := []string{from}
+	if from == "" {
+		var err error
+		if candidates, err = s.backupsBefore(at); err != nil {
+			return "", nil, err
+		}
+	}
+	for _, name := range candidates {
+		f, err := s.openBackup(name)
+		if err != nil {
+			return "", nil, err
+		}
+		res, err := s.DB.RestoreAt(f, at, s.restoreOptions(sha256))
+		_ = f.Close()
+		if from == "" && errors.Is(err, storage.ErrBackupTooNew) {
+			// Started before at but finished after changes made later; try an older one
+			continue
+		}
+		return name, res, err
+	}
+	return "", nil, fmt.Errorf("%w: no backup from before %s", storage.ErrNoRecoveryPoint, at.UTC().Format(time.RFC3339))
+}
@@ -0,0 +1,164 @@
+package main
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+
+	json "github.com/goccy/go-json"
+	"github.com/tradik/mddb/services/mddbd/storage"
+)
+
+func TestRetain(t *testing.T) {
+	at := func(s string) time.Time {
+		ts, err := time.Parse(time.RFC3339, s)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return ts
+	}
+	cases := []struct {
+		name     string
+		schedule BackupSchedule
+		times    []string // newest first
+		keep     []bool
+	}{
+		{
+			name: "none",
+			keep: []bool{},
+		},
+		{
+			name:  "only the newest without a policy",
+			times: []string{"2026-03-02T10:00:00Z", "2026-03-02T09:00:00Z"},
+			keep:  []bool{true, false},
+		},
+		{
+			name:     "hourly keeps the newest of each hour",
+			schedule: BackupSchedule{KeepHourly: 2},
+			times:    []string{"2026-03-02T10:50:00Z", "2026-03-02T10:10:00Z", "2026-03-02T09:59:00Z", "2026-03-02T09:00:00Z", "2026-03-02T08:30:00Z"},
+			keep:     []bool{true, false, true, false, false},
+		},
+		{
+			name:     "hourly boundary",
+			schedule: BackupSchedule{KeepHourly: 2},
+			times:    []string{"2026-03-02T10:00:00Z", "2026-03-02T09:59:59Z", "2026-03-02T09:00:00Z"},
+			keep:     []bool{true, true, false},
+		},
+		{
+			name:     "hourly counts hours that have a backup",
+			schedule: BackupSchedule{KeepHourly: 2},
+			times:    []string{"2026-03-02T10:00:00Z", "2026-03-02T07:00:00Z", "2026-03-02T03:00:00Z"},
+			keep:     []bool{true, true, false},
+		},
+		{
+			name:     "daily boundary at midnight",
+			schedule: BackupSchedule{KeepDaily: 2},
+			times:    []string{"2026-03-02T00:00:01Z", "2026-03-01T23:59:59Z", "2026-03-01T12:00:00Z", "2026-02-28T23:00:00Z"},
+			keep:     []bool{true, true, false, false},
+		},
+		{
+			name:     "daily uses UTC days",
+			schedule: BackupSchedule{KeepDaily: 2},
+			times:    []string{"2026-03-02T03:00:00+02:00", "2026-03-02T01:00:00+02:00", "2026-03-01T20:00:00Z"},
+			keep:     []bool{true, true, false},
+		},
+		{
+			name:     "weekly boundary between Sunday and Monday",
+			schedule: BackupSchedule{KeepWeekly: 2},
+			times:    []string{"2026-03-03T12:00:00Z", "2026-03-02T00:00:00Z", "2026-03-01T23:59:00Z", "2026-02-27T12:00:00Z"},
+			keep:     []bool{true, false, true, false},
+		},
+		{
+			name:     "weekly uses ISO weeks across the year",
+			schedule: BackupSchedule{KeepWeekly: 2},
+			times:    []string{"2026-01-02T12:00:00Z", "2025-12-29T12:00:00Z", "2025-12-28T12:00:00Z"},
+			keep:     []bool{true, false, true},
+		},
+		{
+			name:     "periods combine",
+			schedule: BackupSchedule{KeepHourly: 1, KeepDaily: 3},
+			times:    []string{"2026-03-03T10:00:00Z", "2026-03-03T09:00:00Z", "2026-03-02T18:00:00Z", "2026-03-02T08:00:00Z", "2026-03-01T12:00:00Z", "2026-02-28T12:00:00Z"},
+			keep:     []bool{true, false, true, false, true, false},
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			times := make([]time.Time, len(c.times))
+			for i, s := range c.times {
+				times[i] = at(s)
+			}
+			if got := c.schedule.retain(times); !reflect.DeepEqual(got, c.keep) {
+				t.Errorf("retain = %v, want %v", got, c.keep)
+			}
+		})
+	}
+}
+
+func TestRestoreAtSkipsTooNewBackup(t *testing.T) {
+	dir := t.TempDir()
+	backupDir := filepath.Join(dir, "backups")
+	db, err := storage.Open(filepath.Join(dir, "mddb.db"), &storage.Options{ChangeLogDir: filepath.Join(backupDir, changeLogDirName)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = db.Close() })
+	s := &Server{DB: db, BackupDir: backupDir, DrainTimeout: time.Second}
+
+	add := func(key string) {
+		t.Helper()
+		if _, err := db.Add(storage.AddRequest{Collection: "blog", Key: key, Lang: "en", ContentMD: "# " + key}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	add("first")
+	if _, _, err := s.writeBackupFile("old.mddb", storage.BackupOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	add("before")
+	time.Sleep(10 * time.Millisecond)
+	at := time.Now()
+	time.Sleep(10 * time.Millisecond)
+	add("after")
+
+	// A backup that started before at but holds the later change
+	if _, _, err := s.writeBackupFile("new.mddb", storage.BackupOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	manifest := filepath.Join(backupDir, "new.mddb"+manifestSuffix)
+	data, err := os.ReadFile(manifest)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var m storage.BackupManifest
+	if err := json.Unmarshal(data, &m); err != nil {
+		t.Fatal(err)
+	}
+	m.CreatedAt = at.Unix()
+	if data, err = json.Marshal(m); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(manifest, data, 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	name, res, err := s.restoreAt("", at, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if name != "old.mddb" || res.Replayed != 1 {
+		t.Errorf("restored %s with %d replayed, want old.mddb with 1", name, res.Replayed)
+	}
+	for key, want := range map[string]error{"first": nil, "before": nil, "after": storage.ErrNotFound} {
+		if _, err := db.Get("blog", key, "en"); !errors.Is(err, want) {
+			t.Errorf("Get(%s): %v, want %v", key, err, want)
+		}
+	}
+
+	// Naming the too-new backup fails instead of falling back
+	if _, _, err := s.restoreAt("new.mddb", at, ""); !errors.Is(err, storage.ErrBackupTooNew) {
+		t.Errorf("restoreAt(new.mddb): %v, want ErrBackupTooNew", err)
+	}
+}
@@ -0,0 +1,207 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"mime"
+	"net/http"
+
+	json "github.com/goccy/go-json"
+	"github.com/tradik/mddb/services/mddbd/storage"
+	"google.golang.org/grpc/codes"
+)
+
+const (
+	batchNDJSONChunkSize = 1000 // documents per processor call for NDJSON bodies
+	maxBatchErrors       = 100  // per-document errors reported in the result
+)
+
+// maxBatchLineSize is the longest accepted NDJSON line (a variable for tests)
+var maxBatchLineSize = 64 << 20
+
+// BatchRequest is the JSON body of the batch endpoints
+type BatchRequest struct {
+	Collection string          `json:"collection"`
+	Documents  []BatchDocument `json:"documents"`
+}
+
+// BatchDocument is one document of a batch body, with the field names of /v1/add
+type BatchDocument struct {
+	Key          string              `json:"key"`
+	Lang         string              `json:"lang"`
+	Meta         map[string][]string `json:"meta"`
+	ContentMD    string              `json:"contentMd"`
+	SaveRevision bool                `json:"saveRevision"`
+}
+
+func (d BatchDocument) document() storage.BatchDocument {
+	return storage.BatchDocument(d)
+}
+
+// batchError is the body of a batch that stopped part way: the structured error and
+// the counts of the chunks committed before it
+type batchError struct {
+	*APIError
+	Result any `json:"result"`
+}
+
+func writeBatchError(w http.ResponseWriter, err error, res any) {
+	apiErr := toAPIError(err, codes.InvalidArgument)
+	b, _ := json.Marshal(batchError{APIError: apiErr, Result: res})
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.WriteHeader(apiErr.Status)
+	_, _ = w.Write(b)
+}
+
+// appendBatchErrors appends per-document errors up to maxBatchErrors
+func appendBatchErrors(errs []string, more ...string) []string {
+	if n := maxBatchErrors - len(errs); n < len(more) {
+		more = more[:max(n, 0)]
+	}
+	return append(errs, more...)
+}
+
+// batchCollection is the collection of a JSON batch body, else the collection query parameter
+func batchCollection(r *http.Request, req *BatchRequest) string {
+	if req.Collection != "" {
+		return req.Collection
+	}
+	return r.URL.Query().Get("collection")
+}
+
+// readBatchRequest calls fn with the request's documents. A JSON body is passed in one call;
+// an NDJSON body (one document per line, collection in the query) is passed in chunks
+// so arbitrarily large batches never have to be held in memory. Lines that fail to parse
+// are reported through bad and do not stop the stream. Unknown fields are errors. Chunks
+// passed to fn before an error stay committed.
+func readBatchRequest(r *http.Request, fn func(collection string, docs []storage.BatchDocument) error, bad func(line int, err error)) error {
+	mt, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	if mt != "application/x-ndjson" {
+		var req BatchRequest
+		if err := decodeStrict(r.Body, &req); err != nil {
+			return err
+		}
+		collection := batchCollection(r, &req)
+		if collection == "" {
+			return errors.New("missing collection")
+		}
+		if len(req.Documents) == 0 {
+			return nil
+		}
+		docs := make([]storage.BatchDocument, len(req.Documents))
+		for i, d := range req.Documents {
+			docs[i] = d.document()
+		}
+		return fn(collection, docs)
+	}
+
+	collection := r.URL.Query().Get("collection")
+	if collection == "" {
+		return errors.New("missing collection")
+	}
+	sc := bufio.NewScanner(r.Body)
+	sc.Buffer(make([]byte, 0, min(64<<10, maxBatchLineSize)), maxBatchLineSize)
+	chunk := make([]storage.BatchDocument, 0, batchNDJSONChunkSize)
+	for line := 1; sc.Scan(); line++ {
+		b := sc.Bytes()
+		if len(bytes.TrimSpace(b)) == 0 {
+			continue
+		}
+		var d BatchDocument
+		if err := decodeStrict(bytes.NewReader(b), &d); err != nil {
+			bad(line, err)
+			continue
+		}
+		chunk = append(chunk, d.document())
+		if len(chunk) == batchNDJSONChunkSize {
+			if err := fn(collection, chunk); err != nil {
+				return err
+			}
+			chunk = chunk[:0]
+		}
+	}
+	if err := sc.Err(); err != nil {
+		return err
+	}
+	if len(chunk) > 0 {
+		return fn(collection, chunk)
+	}
+	return nil
+}
+
+// --- HTTP handlers
+
+func (s *Server) handleAddBatch(w http.ResponseWriter, r *http.Request) {
+	var res storage.AddBatchResult
+	err := readBatchRequest(r, func(collection string, docs []storage.BatchDocument) error {
+		part, err := s.DB.AddBatch(r.Context(), collection, docs)
+		if err != nil {
+			return err
+		}
+		res.Added += part.Added
+		res.Updated += part.Updated
+		res.Failed += part.Failed
+		res.Errors = appendBatchErrors(res.Errors, part.Errors...)
+		return nil
+	}, func(line int, err error) {
+		res.Failed++
+		res.Errors = appendBatchErrors(res.Errors, fmt.Sprintf("line %d: %v", line, err))
+	})
+	if err != nil {
+		writeBatchError(w, err, res)
+		return
+	}
+	ok(w, res)
+}
+
+func (s *Server) handleUpdateBatch(w http.ResponseWriter, r *http.Request) {
+	var res storage.UpdateBatchResult
+	err := readBatchRequest(r, func(collection string, docs []storage.BatchDocument) error {
+		part, err := s.DB.UpdateBatch(r.Context(), collection, docs)
+		if err != nil {
+			return err
+		}
+		res.Updated += part.Updated
+		res.NotFound += part.NotFound
+		res.Failed += part.Failed
+		res.Errors = appendBatchErrors(res.Errors, part.Errors...)
+		return nil
+	}, func(line int, err error) {
+		res.Failed++
+		res.Errors = appendBatchErrors(res.Errors, fmt.Sprintf("line %d: %v", line, err))
+	})
+	if err != nil {
+		writeBatchError(w, err, res)
+		return
+	}
+	ok(w, res)
+}
+
+func (s *Server) handleDeleteBatch(w http.ResponseWriter, r *http.Request) {
+	var res storage.DeleteBatchResult
+	err := readBatchRequest(r, func(collection string, docs []storage.BatchDocument) error {
+		refs := make([]storage.DocRef, len(docs))
+		for i, d := range docs {
+			refs[i] = storage.DocRef{Key: d.Key, Lang: d.Lang}
+		}
+		part, err := s.DB.DeleteBatch(r.Context(), collection, refs)
+		if err != nil {
+			return err
+		}
+		res.Deleted += part.Deleted
+		res.NotFound += part.NotFound
+		res.Failed += part.Failed
+		res.Errors = appendBatchErrors(res.Errors, part.Errors...)
+		return nil
+	}, func(line int, err error) {
+		res.Failed++
+		res.Errors = appendBatchErrors(res.Errors, fmt.Sprintf("line %d: %v", line, err))
+	})
+	if err != nil {
+		writeBatchError(w, err, res)
+		return
+	}
+	ok(w, res)
+}
@@ -0,0 +1,70 @@
+package main
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"testing"
+
+	json "github.com/goccy/go-json"
+	"github.com/tradik/mddb/services/mddbd/storage"
+)
+
+func postNDJSON(t *testing.T, url, body string) *http.Response {
+	t.Helper()
+	resp, err := http.Post(url, "application/x-ndjson", strings.NewReader(body))
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = resp.Body.Close() })
+	return resp
+}
+
+func TestAddBatchFields(t *testing.T) {
+	s, ts := newTestServer(t, false)
+	resp := post(t, ts.URL+"/v1/add-batch", "", `{"collection":"blog","documents":[{"key":"a","lang":"en","contentMd":"# a","saveRevision":true}]}`)
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("add-batch: %d", resp.StatusCode)
+	}
+	if doc, err := s.DB.Get("blog", "a", "en"); err != nil || doc.ContentMD != "# a" {
+		t.Errorf("Get(a) = %+v, %v", doc, err)
+	}
+
+	// The proto spelling is an unknown field, so a document is never stored empty
+	resp = post(t, ts.URL+"/v1/add-batch", "", `{"collection":"blog","documents":[{"key":"b","lang":"en","content_md":"# b"}]}`)
+	if resp.StatusCode != http.StatusBadRequest {
+		t.Errorf("add-batch with content_md: %d, want 400", resp.StatusCode)
+	}
+}
+
+func TestAddBatchNDJSONError(t *testing.T) {
+	defer func(n int) { maxBatchLineSize = n }(maxBatchLineSize)
+	maxBatchLineSize = 1 << 10
+
+	// Bad lines are reported up to the cap, a full chunk is committed and the
+	// overlong line stops the stream
+	var body strings.Builder
+	for range maxBatchErrors + 50 {
+		body.WriteString("{not json\n")
+	}
+	for i := range batchNDJSONChunkSize {
+		fmt.Fprintf(&body, `{"key":"post-%d","lang":"en","contentMd":"# post"}`+"\n", i)
+	}
+	body.WriteString(`{"key":"long","lang":"en","contentMd":"` + strings.Repeat("x", maxBatchLineSize) + "\"}\n")
+
+	_, ts := newTestServer(t, false)
+	resp := postNDJSON(t, ts.URL+"/v1/add-batch?collection=blog", body.String())
+	var got struct {
+		APIError
+		Result storage.AddBatchResult `json:"result"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&got); err != nil {
+		t.Fatal(err)
+	}
+	if resp.StatusCode != http.StatusBadRequest || got.Reason != ReasonBadRequest {
+		t.Errorf("add-batch: %d %s, want 400 %s", resp.StatusCode, got.Reason, ReasonBadRequest)
+	}
+	if r := got.Result; r.Added != batchNDJSONChunkSize || r.Failed != maxBatchErrors+50 || len(r.Errors) != maxBatchErrors {
+		t.Errorf("partial result: %d added, %d updated, %d failed, %d errors", r.Added, r.Updated, r.Failed, len(r.Errors))
+	}
+}
@@ -0,0 +1,49 @@
+package client
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"hash"
+	"io"
+)
+
+// ErrBackupMismatch is returned by StreamBackup when the received bytes don't match the
+// size or SHA-256 checksum in the server's manifest.
+var ErrBackupMismatch = errors.New("backup does not match its manifest")
+
+// ErrDumpIncomplete is returned by Dump when the stream ends without the server's summary.
+var ErrDumpIncomplete = errors.New("dump stream ended early")
+
+// backupVerifier passes a backup stream through and checks it against the manifest
+type backupVerifier struct {
+	w    io.Writer
+	hash hash.Hash
+	n    int64
+}
+
+func newBackupVerifier(w io.Writer) *backupVerifier {
+	return &backupVerifier{w: w, hash: sha256.New()}
+}
+
+func (v *backupVerifier) Write(p []byte) (int, error) {
+	n, err := v.w.Write(p)
+	v.hash.Write(p[:n])
+	v.n += int64(n)
+	return n, err
+}
+
+// check compares what was written with m; a nil manifest means the stream was cut short
+func (v *backupVerifier) check(m *BackupManifest) error {
+	if m == nil {
+		return fmt.Errorf("%w: stream ended without a manifest", ErrBackupMismatch)
+	}
+	if v.n != m.Size {
+		return fmt.Errorf("%w: received %d bytes, manifest says %d", ErrBackupMismatch, v.n, m.Size)
+	}
+	if sum := hex.EncodeToString(v.hash.Sum(nil)); sum != m.SHA256 {
+		return fmt.Errorf("%w: sha256 %s, manifest says %s", ErrBackupMismatch, sum, m.SHA256)
+	}
+	return nil
+}
@@ -0,0 +1,140 @@
+// Package client is the Go client for mddbd. It speaks gRPC, HTTP/JSON or both with
+// fallback, retries transient failures with jittered backoff and trips a per-transport
+// circuit breaker so a failing transport is skipped until it recovers.
+//
+//	c, err := client.NewClient(client.ClientConfig{
+//		GRPCAddress:   "localhost:11024",
+//		RESTBaseURL:   "http://localhost:11023",
+//		TransportMode: "grpc_with_rest_fallback",
+//		Timeout:       2 * time.Second,
+//		MaxRetries:    2,
+//		Token:         os.Getenv("MDDB_API_KEY"), // when the server requires auth
+//	})
+//	if err != nil { ... }
+//	defer c.Close()
+//	doc, err := c.Get(ctx, &client.GetRequest{Collection: "blog", Key: "hello", Lang: "en"})
+//
+// The clienttest package provides an in-memory Client for tests.
+package client
+
+import (
+	"context"
+	"io"
+)
+
+// Client is the MDDB client interface supporting all API operations.
+type Client interface {
+	// Health checks server health status.
+	Health(ctx context.Context) (*Health, error)
+
+	// Stats returns server and database statistics.
+	Stats(ctx context.Context) (*Stats, error)
+
+	// Add adds or updates a document.
+	Add(ctx context.Context, req *AddRequest) (*Document, error)
+
+	// Patch applies meta operations and content changes to an existing document.
+	Patch(ctx context.Context, req *PatchRequest) (*Document, error)
+
+	// Transaction runs add, patch and delete operations across collections all or nothing.
+	Transaction(ctx context.Context, req *TransactionRequest) (*TransactionResponse, error)
+
+	// AddBatch adds or updates multiple documents in one transaction.
+	AddBatch(ctx context.Context, req *AddBatchRequest) (*AddBatchResponse, error)
+
+	// UpdateBatch updates multiple documents in one transaction.
+	UpdateBatch(ctx context.Context, req *UpdateBatchRequest) (*UpdateBatchResponse, error)
+
+	// DeleteBatch deletes multiple documents in one transaction.
+	DeleteBatch(ctx context.Context, req *DeleteBatchRequest) (*DeleteBatchResponse, error)
+
+	// Get retrieves a document by key and language.
+	Get(ctx context.Context, req *GetRequest) (*Document, error)
+
+	// Search searches documents with filtering and sorting.
+	Search(ctx context.Context, req *SearchRequest) (*SearchResponse, error)
+
+	// Delete deletes a single document.
+	Delete(ctx context.Context, req *DeleteRequest) error
+
+	// DeleteCollection deletes entire collection.
+	DeleteCollection(ctx context.Context, req *DeleteCollectionRequest) (*DeleteCollectionResponse, error)
+
+	// Export exports documents (returns data stream).
+	Export(ctx context.Context, req *ExportRequest) (io.ReadCloser, error)
+
+	// Import uploads an NDJSON export or a zip/tar archive of markdown files.
+	Import(ctx context.Context, req *ImportRequest, data io.Reader) (*ImportResponse, error)
+
+	// Backup writes a database backup into the server's backup directory.
+	Backup(ctx context.Context, req *BackupRequest) (*BackupResponse, error)
+
+	// StreamBackup streams a consistent database backup to w and verifies it against the
+	// server's manifest, returning ErrBackupMismatch if it doesn't match.
+	StreamBackup(ctx context.Context, req *StreamBackupRequest, w io.Writer) (*BackupManifest, error)
+
+	// Restore replaces the database with a verified backup from the server's backup
+	// directory, draining requests while the file is swapped.
+	Restore(ctx context.Context, req *RestoreRequest) (*RestoreResponse, error)
+
+	// StreamRestore uploads a backup (plain or zstd-compressed) from data and restores it.
+	StreamRestore(ctx context.Context, req *StreamRestoreRequest, data io.Reader) (*RestoreResponse, error)
+
+	// Dump streams a logical archive of collections to w, returning ErrDumpIncomplete
+	// if the stream ends before the server's summary.
+	Dump(ctx context.Context, req *DumpRequest, w io.Writer) (*DumpSummary, error)
+
+	// Load uploads a dump (plain or zstd-compressed) from data and writes its collections.
+	Load(ctx context.Context, req *LoadRequest, data io.Reader) (*LoadResponse, error)
+
+	// Truncate truncates revision history.
+	Truncate(ctx context.Context, req *TruncateRequest) (*TruncateResponse, error)
+
+	// Fsck checks documents, key entries, meta indexes and revisions for consistency and optionally repairs them.
+	Fsck(ctx context.Context, req *FsckRequest) (*FsckReport, error)
+
+	// Compact rewrites the database file without free pages and reports its size before and after.
+	Compact(ctx context.Context, req *CompactRequest) (*CompactResponse, error)
+
+	// ListCollections lists registered collections.
+	ListCollections(ctx context.Context) ([]CollectionInfo, error)
+
+	// CreateCollection registers a collection with its settings.
+	CreateCollection(ctx context.Context, req *CollectionInfo) (*CollectionInfo, error)
+
+	// UpdateCollection changes the settings of a collection.
+	UpdateCollection(ctx context.Context, req *CollectionInfo) (*CollectionInfo, error)
+
+	// DescribeCollection returns collection settings with live counts.
+	DescribeCollection(ctx context.Context, name string) (*CollectionDescription, error)
+
+	// RenameCollection moves all documents of a collection to a new name.
+	RenameCollection(ctx context.Context, req *CopyCollectionRequest) (*CopyCollectionResponse, error)
+
+	// CloneCollection copies all documents of a collection to a new name.
+	CloneCollection(ctx context.Context, req *CopyCollectionRequest) (*CopyCollectionResponse, error)
+
+	// SetSchema sets the validation schema of a collection.
+	SetSchema(ctx context.Context, schema *CollectionSchema) (*CollectionSchema, error)
+
+	// GetSchema returns the schema of a collection.
+	GetSchema(ctx context.Context, collection string) (*CollectionSchema, error)
+
+	// DeleteSchema removes the schema of a collection.
+	DeleteSchema(ctx context.Context, collection string) error
+
+	// WhoAmI returns the caller as authenticated by the server.
+	WhoAmI(ctx context.Context) (*Principal, error)
+
+	// CreateAPIKey creates an API key; the response is the only time its secret is returned.
+	CreateAPIKey(ctx context.Context, req *CreateAPIKeyRequest) (*APIKey, error)
+
+	// ListAPIKeys lists API keys without their secrets.
+	ListAPIKeys(ctx context.Context) ([]APIKey, error)
+
+	// DeleteAPIKey revokes an API key.
+	DeleteAPIKey(ctx context.Context, id string) error
+
+	// Close closes connection to server.
+	Close() error
+}
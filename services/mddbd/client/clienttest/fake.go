@@ -0,0 +1,827 @@
+// Package clienttest provides an in-memory client.Client for tests of code that talks to
+// mddb. It keeps documents, collections and schemas in maps, mimics the server's
+// not-found errors and lets tests inject failures per operation.
+//
+//	fake := clienttest.NewFake()
+//	fake.FailNext("add", clienttest.Unavailable)
+//	svc := NewService(fake)
+package clienttest
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"maps"
+	"slices"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/tradik/mddb/services/mddbd/client"
+)
+
+// Unavailable is a transient transport error, retried by client.FallbackClient.
+var Unavailable = &client.Error{Message: "unavailable", Code: "UNAVAILABLE", Status: 503, Reason: client.ReasonUnavailable}
+
+// Fake is an in-memory client.Client. The zero value is not usable; call NewFake.
+type Fake struct {
+	mu          sync.Mutex
+	docs        map[string]*client.Document // collection|key|lang
+	collections map[string]*client.CollectionInfo
+	schemas     map[string]*client.CollectionSchema
+	apiKeys     map[string]*client.APIKey // id -> key without its secret
+	keySeq      int
+	failures    map[string][]error
+	calls       map[string]int
+	now         func() time.Time
+}
+
+var _ client.Client = (*Fake)(nil)
+
+// NewFake returns an empty fake.
+func NewFake() *Fake {
+	return &Fake{
+		docs:        make(map[string]*client.Document),
+		collections: make(map[string]*client.CollectionInfo),
+		schemas:     make(map[string]*client.CollectionSchema),
+		apiKeys:     make(map[string]*client.APIKey),
+		failures:    make(map[string][]error),
+		calls:       make(map[string]int),
+		now:         time.Now,
+	}
+}
+
+// FailNext makes the next calls of op fail with errs, one error per call. op is the
+// method name in lower case with spaces, as used in error messages ("add", "add batch").
+func (f *Fake) FailNext(op string, errs ...error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.failures[op] = append(f.failures[op], errs...)
+}
+
+// Calls returns how many times op was called, including failed calls.
+func (f *Fake) Calls(op string) int {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.calls[op]
+}
+
+// Documents returns a copy of every stored document of a collection, ordered by key and lang.
+func (f *Fake) Documents(collection string) []client.Document {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	return f.list(collection, nil)
+}
+
+// begin locks the fake, counts the call and pops an injected failure. The caller must unlock.
+func (f *Fake) begin(op string) error {
+	f.mu.Lock()
+	f.calls[op]++
+	if errs := f.failures[op]; len(errs) > 0 {
+		f.failures[op] = errs[1:]
+		return fmt.Errorf("%s: %w", op, errs[0])
+	}
+	return nil
+}
+
+func notFound(reason, msg string) error {
+	return &client.Error{Message: msg, Code: "NOT_FOUND", Status: 404, Reason: reason}
+}
+
+func badRequest(msg string) error {
+	return &client.Error{Message: msg, Code: "INVALID_ARGUMENT", Status: 400, Reason: client.ReasonBadRequest}
+}
+
+func docKey(collection, key, lang string) string {
+	return collection + "|" + key + "|" + lang
+}
+
+func copyDoc(d *client.Document) client.Document {
+	out := *d
+	out.Meta = make(map[string][]string, len(d.Meta))
+	for k, v := range d.Meta {
+		out.Meta[k] = append([]string(nil), v...)
+	}
+	return out
+}
+
+// put stores a document and reports whether it replaced an existing one
+func (f *Fake) put(collection string, d client.BatchDocument) (*client.Document, bool) {
+	if _, ok := f.collections[collection]; !ok {
+		f.collections[collection] = &client.CollectionInfo{Name: collection, RevisionPolicy: "request", Compression: "auto", CreatedAt: f.now(), UpdatedAt: f.now()}
+	}
+	id := docKey(collection, d.Key, d.Lang)
+	now := f.now()
+	doc := &client.Document{ID: strings.ToLower(id), Key: d.Key, Lang: d.Lang, Meta: d.Meta, ContentMD: d.ContentMD, AddedAt: now, UpdatedAt: now}
+	old, exists := f.docs[id]
+	if exists {
+		doc.AddedAt = old.AddedAt
+	}
+	if doc.Meta == nil {
+		doc.Meta = map[string][]string{}
+	}
+	f.docs[id] = doc
+	out := copyDoc(doc)
+	return &out, exists
+}
+
+func (f *Fake) list(collection string, filter map[string][]string) []client.Document {
+	var out []client.Document
+	for id, d := range f.docs {
+		if !strings.HasPrefix(id, collection+"|") || !matches(d, filter) {
+			continue
+		}
+		out = append(out, copyDoc(d))
+	}
+	sort.Slice(out, func(i, j int) bool {
+		if out[i].Key != out[j].Key {
+			return out[i].Key < out[j].Key
+		}
+		return out[i].Lang < out[j].Lang
+	})
+	return out
+}
+
+// matches applies the server's filter semantics: AND over keys, OR over values
+func matches(d *client.Document, filter map[string][]string) bool {
+	for k, want := range filter {
+		found := false
+		for _, w := range want {
+			for _, v := range d.Meta[k] {
+				if v == w {
+					found = true
+				}
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+	return true
+}
+
+func (f *Fake) Health(ctx context.Context) (*client.Health, error) {
+	defer f.mu.Unlock()
+	if err := f.begin("health"); err != nil {
+		return nil, err
+	}
+	return &client.Health{Status: "healthy", Mode: "wr"}, nil
+}
+
+func (f *Fake) Stats(ctx context.Context) (*client.Stats, error) {
+	defer f.mu.Unlock()
+	if err := f.begin("stats"); err != nil {
+		return nil, err
+	}
+	s := &client.Stats{DatabasePath: "memory", Mode: "wr", Collections: []client.CollectionStats{}}
+	for name := range f.collections {
+		n := len(f.list(name, nil))
+		s.Collections = append(s.Collections, client.CollectionStats{Name: name, DocumentCount: n})
+		s.TotalDocuments += n
+	}
+	sort.Slice(s.Collections, func(i, j int) bool { return s.Collections[i].Name < s.Collections[j].Name })
+	return s, nil
+}
+
+func (f *Fake) Add(ctx context.Context, req *client.AddRequest) (*client.Document, error) {
+	defer f.mu.Unlock()
+	if err := f.begin("add"); err != nil {
+		return nil, err
+	}
+	if req.Collection == "" || req.Key == "" || req.Lang == "" {
+		return nil, badRequest("missing fields")
+	}
+	doc, _ := f.put(req.Collection, client.BatchDocument{Key: req.Key, Lang: req.Lang, Meta: req.Meta, ContentMD: req.ContentMD})
+	return doc, nil
+}
+
+func (f *Fake) Patch(ctx context.Context, req *client.PatchRequest) (*client.Document, error) {
+	defer f.mu.Unlock()
+	if err := f.begin("patch"); err != nil {
+		return nil, err
+	}
+	return f.patch(req, nil)
+}
+
+// patch applies req to a stored document after checking pc
+func (f *Fake) patch(req *client.PatchRequest, pc *client.Precondition) (*client.Document, error) {
+	d := f.docs[docKey(req.Collection, req.Key, req.Lang)]
+	if err := checkPrecondition(pc, d); err != nil {
+		return nil, err
+	}
+	if d == nil {
+		return nil, notFound(client.ReasonDocumentNotFound, "document not found")
+	}
+	doc := copyDoc(d)
+	for _, p := range req.Meta {
+		switch p.Op {
+		case client.PatchSet:
+			doc.Meta[p.Key] = append([]string(nil), p.Values...)
+		case client.PatchUnset:
+			delete(doc.Meta, p.Key)
+		case client.PatchAdd:
+			for _, v := range p.Values {
+				if !slices.Contains(doc.Meta[p.Key], v) {
+					doc.Meta[p.Key] = append(doc.Meta[p.Key], v)
+				}
+			}
+		case client.PatchRemove:
+			doc.Meta[p.Key] = slices.DeleteFunc(doc.Meta[p.Key], func(v string) bool { return slices.Contains(p.Values, v) })
+		default:
+			return nil, badRequest("invalid patch: unknown op " + p.Op)
+		}
+		if len(doc.Meta[p.Key]) == 0 {
+			delete(doc.Meta, p.Key)
+		}
+	}
+	if req.ContentMD != nil {
+		doc.ContentMD = *req.ContentMD
+	}
+	doc.ContentMD += req.AppendMD
+	doc.UpdatedAt = f.now()
+	f.docs[docKey(req.Collection, req.Key, req.Lang)] = &doc
+	out := copyDoc(&doc)
+	return &out, nil
+}
+
+func (f *Fake) AddBatch(ctx context.Context, req *client.AddBatchRequest) (*client.AddBatchResponse, error) {
+	defer f.mu.Unlock()
+	if err := f.begin("add batch"); err != nil {
+		return nil, err
+	}
+	resp := &client.AddBatchResponse{}
+	for _, d := range req.Documents {
+		if d.Key == "" || d.Lang == "" {
+			resp.Failed++
+			resp.Errors = append(resp.Errors, "missing key or lang")
+			continue
+		}
+		if _, existed := f.put(req.Collection, d); existed {
+			resp.Updated++
+		} else {
+			resp.Added++
+		}
+	}
+	return resp, nil
+}
+
+func (f *Fake) UpdateBatch(ctx context.Context, req *client.UpdateBatchRequest) (*client.UpdateBatchResponse, error) {
+	defer f.mu.Unlock()
+	if err := f.begin("update batch"); err != nil {
+		return nil, err
+	}
+	resp := &client.UpdateBatchResponse{}
+	for _, d := range req.Documents {
+		if _, ok := f.docs[docKey(req.Collection, d.Key, d.Lang)]; !ok {
+			resp.NotFound++
+			continue
+		}
+		f.put(req.Collection, client.BatchDocument(d))
+		resp.Updated++
+	}
+	return resp, nil
+}
+
+func (f *Fake) DeleteBatch(ctx context.Context, req *client.DeleteBatchRequest) (*client.DeleteBatchResponse, error) {
+	defer f.mu.Unlock()
+	if err := f.begin("delete batch"); err != nil {
+		return nil, err
+	}
+	resp := &client.DeleteBatchResponse{}
+	for _, d := range req.Documents {
+		id := docKey(req.Collection, d.Key, d.Lang)
+		if _, ok := f.docs[id]; !ok {
+			resp.NotFound++
+			continue
+		}
+		delete(f.docs, id)
+		resp.Deleted++
+	}
+	return resp, nil
+}
+
+// Get returns the stored document; Env placeholders (%%name%%) are substituted like the server does.
+func (f *Fake) Get(ctx context.Context, req *client.GetRequest) (*client.Document, error) {
+	defer f.mu.Unlock()
+	if err := f.begin("get"); err != nil {
+		return nil, err
+	}
+	d, ok := f.docs[docKey(req.Collection, req.Key, req.Lang)]
+	if !ok {
+		return nil, notFound(client.ReasonDocumentNotFound, "document not found")
+	}
+	out := copyDoc(d)
+	for k, v := range req.Env {
+		out.ContentMD = strings.ReplaceAll(out.ContentMD, "%%"+k+"%%", v)
+	}
+	return &out, nil
+}
+
+func (f *Fake) Search(ctx context.Context, req *client.SearchRequest) (*client.SearchResponse, error) {
+	defer f.mu.Unlock()
+	if err := f.begin("search"); err != nil {
+		return nil, err
+	}
+	docs := f.list(req.Collection, req.FilterMeta)
+	switch req.Sort {
+	case "addedAt":
+		sort.SliceStable(docs, func(i, j int) bool { return docs[i].AddedAt.Before(docs[j].AddedAt) == req.Asc })
+	case "updatedAt":
+		sort.SliceStable(docs, func(i, j int) bool { return docs[i].UpdatedAt.Before(docs[j].UpdatedAt) == req.Asc })
+	case "key":
+		sort.SliceStable(docs, func(i, j int) bool { return (docs[i].Key < docs[j].Key) == req.Asc })
+	}
+	limit := req.Limit
+	if limit <= 0 {
+		limit = 50
+	}
+	total := len(docs)
+	start := min(max(req.Offset, 0), total)
+	end := min(start+limit, total)
+	return &client.SearchResponse{Documents: docs[start:end], Total: total}, nil
+}
+
+func (f *Fake) Delete(ctx context.Context, req *client.DeleteRequest) error {
+	defer f.mu.Unlock()
+	if err := f.begin("delete"); err != nil {
+		return err
+	}
+	return f.remove(req, nil)
+}
+
+// remove deletes a stored document after checking pc
+func (f *Fake) remove(req *client.DeleteRequest, pc *client.Precondition) error {
+	id := docKey(req.Collection, req.Key, req.Lang)
+	d := f.docs[id]
+	if err := checkPrecondition(pc, d); err != nil {
+		return err
+	}
+	if d == nil {
+		if pc != nil {
+			return nil // the precondition held for a missing document
+		}
+		return notFound(client.ReasonDocumentNotFound, "document not found")
+	}
+	delete(f.docs, id)
+	return nil
+}
+
+// checkPrecondition applies the server's precondition rules to d (nil = missing)
+func checkPrecondition(pc *client.Precondition, d *client.Document) error {
+	if pc == nil {
+		return nil
+	}
+	failed := &client.Error{Message: "precondition failed", Code: "FAILED_PRECONDITION", Status: 412, Reason: client.ReasonPreconditionFailed}
+	if pc.Exists != nil && *pc.Exists != (d != nil) {
+		return failed
+	}
+	if pc.IfMatch != "" && (d == nil || pc.IfMatch != d.ETag()) {
+		return failed
+	}
+	return nil
+}
+
+// Transaction applies the operations in order and restores the previous state when one fails.
+func (f *Fake) Transaction(ctx context.Context, req *client.TransactionRequest) (*client.TransactionResponse, error) {
+	defer f.mu.Unlock()
+	if err := f.begin("transaction"); err != nil {
+		return nil, err
+	}
+	if len(req.Ops) == 0 {
+		return nil, badRequest("invalid transaction: no operations")
+	}
+
+	docs := maps.Clone(f.docs)
+	collections := maps.Clone(f.collections)
+	resp := &client.TransactionResponse{Results: make([]client.TxResult, len(req.Ops))}
+	for i, op := range req.Ops {
+		r := &resp.Results[i]
+		var err error
+		switch {
+		case op.Add != nil && op.Patch == nil && op.Delete == nil:
+			r.Op, r.Collection, r.Key, r.Lang = "add", op.Add.Collection, op.Add.Key, op.Add.Lang
+			if err = checkPrecondition(op.Precondition, f.docs[docKey(r.Collection, r.Key, r.Lang)]); err == nil {
+				doc, existed := f.put(r.Collection, client.BatchDocument{Key: r.Key, Lang: r.Lang, Meta: op.Add.Meta, ContentMD: op.Add.ContentMD})
+				r.Document, r.Created = doc, !existed
+			}
+		case op.Patch != nil && op.Add == nil && op.Delete == nil:
+			r.Op, r.Collection, r.Key, r.Lang = "patch", op.Patch.Collection, op.Patch.Key, op.Patch.Lang
+			r.Document, err = f.patch(op.Patch, op.Precondition)
+		case op.Delete != nil && op.Add == nil && op.Patch == nil:
+			r.Op, r.Collection, r.Key, r.Lang = "delete", op.Delete.Collection, op.Delete.Key, op.Delete.Lang
+			err = f.remove(op.Delete, op.Precondition)
+		default:
+			err = badRequest("invalid transaction: need exactly one of add, patch, delete")
+		}
+		if err != nil {
+			f.docs, f.collections = docs, collections
+			var e *client.Error
+			if errors.As(err, &e) {
+				failed := *e
+				failed.Message = fmt.Sprintf("op %d (%s): %s", i, r.Op, e.Message)
+				failed.Metadata = map[string]string{"op_index": strconv.Itoa(i), "op": r.Op}
+				return nil, &failed
+			}
+			return nil, err
+		}
+	}
+	return resp, nil
+}
+
+func (f *Fake) DeleteCollection(ctx context.Context, req *client.DeleteCollectionRequest) (*client.DeleteCollectionResponse, error) {
+	defer f.mu.Unlock()
+	if err := f.begin("delete collection"); err != nil {
+		return nil, err
+	}
+	if _, ok := f.collections[req.Collection]; !ok {
+		return nil, notFound(client.ReasonCollectionNotFound, "collection not found")
+	}
+	n := 0
+	for _, d := range f.list(req.Collection, nil) {
+		delete(f.docs, docKey(req.Collection, d.Key, d.Lang))
+		n++
+	}
+	delete(f.collections, req.Collection)
+	delete(f.schemas, req.Collection)
+	return &client.DeleteCollectionResponse{Deleted: n}, nil
+}
+
+// Export supports the ndjson format only.
+func (f *Fake) Export(ctx context.Context, req *client.ExportRequest) (io.ReadCloser, error) {
+	defer f.mu.Unlock()
+	if err := f.begin("export"); err != nil {
+		return nil, err
+	}
+	if req.Format != "" && req.Format != "ndjson" {
+		return nil, badRequest("unsupported format")
+	}
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	for _, d := range f.list(req.Collection, req.FilterMeta) {
+		_ = enc.Encode(map[string]any{
+			"id": d.ID, "key": d.Key, "lang": d.Lang, "meta": d.Meta, "contentMd": d.ContentMD,
+			"addedAt": d.AddedAt.Unix(), "updatedAt": d.UpdatedAt.Unix(),
+		})
+	}
+	return io.NopCloser(&buf), nil
+}
+
+// Import supports the ndjson format only.
+func (f *Fake) Import(ctx context.Context, req *client.ImportRequest, data io.Reader) (*client.ImportResponse, error) {
+	defer f.mu.Unlock()
+	if err := f.begin("import"); err != nil {
+		return nil, err
+	}
+	if req.Format != "" && req.Format != "ndjson" {
+		return nil, badRequest("unsupported format")
+	}
+	resp := &client.ImportResponse{DryRun: req.DryRun}
+	sc := bufio.NewScanner(data)
+	sc.Buffer(make([]byte, 64<<10), 64<<20)
+	for sc.Scan() {
+		if len(bytes.TrimSpace(sc.Bytes())) == 0 {
+			continue
+		}
+		var d struct {
+			Key       string              `json:"key"`
+			Lang      string              `json:"lang"`
+			Meta      map[string][]string `json:"meta"`
+			ContentMD string              `json:"contentMd"`
+		}
+		resp.Total++
+		if err := json.Unmarshal(sc.Bytes(), &d); err != nil || d.Key == "" {
+			resp.Failed++
+			resp.Errors = append(resp.Errors, fmt.Sprintf("line %d: invalid document", resp.Total))
+			continue
+		}
+		if d.Lang == "" {
+			d.Lang = req.Lang
+		}
+		_, exists := f.docs[docKey(req.Collection, d.Key, d.Lang)]
+		switch {
+		case exists && req.OnConflict == "skip":
+			resp.Skipped++
+			continue
+		case exists && req.OnConflict == "fail":
+			return nil, &client.Error{Message: "document already exists", Code: "ALREADY_EXISTS", Status: 409, Reason: client.ReasonDocumentExists}
+		}
+		if !req.DryRun {
+			f.put(req.Collection, client.BatchDocument{Key: d.Key, Lang: d.Lang, Meta: d.Meta, ContentMD: d.ContentMD})
+		}
+		if exists {
+			resp.Updated++
+		} else {
+			resp.Added++
+		}
+	}
+	if err := sc.Err(); err != nil {
+		return nil, fmt.Errorf("import: %w", err)
+	}
+	if resp.Total > 0 {
+		resp.Chunks = 1
+	}
+	return resp, nil
+}
+
+func (f *Fake) Backup(ctx context.Context, req *client.BackupRequest) (*client.BackupResponse, error) {
+	defer f.mu.Unlock()
+	if err := f.begin("backup"); err != nil {
+		return nil, err
+	}
+	return &client.BackupResponse{Backup: req.To}, nil
+}
+
+// StreamBackup writes nothing: the fake has no database image to stream.
+func (f *Fake) StreamBackup(ctx context.Context, req *client.StreamBackupRequest, w io.Writer) (*client.BackupManifest, error) {
+	defer f.mu.Unlock()
+	if err := f.begin("stream backup"); err != nil {
+		return nil, err
+	}
+	return &client.BackupManifest{Format: "mddb-bolt/1", Compression: "none", SHA256: "e3b0c44298fc1c149afbf4c8996fb92427ae41e4649b934ca495991b7852b855"}, nil
+}
+
+func (f *Fake) Restore(ctx context.Context, req *client.RestoreRequest) (*client.RestoreResponse, error) {
+	defer f.mu.Unlock()
+	if err := f.begin("restore"); err != nil {
+		return nil, err
+	}
+	return &client.RestoreResponse{Restored: req.From}, nil
+}
+
+// StreamRestore drains data without touching the stored documents.
+func (f *Fake) StreamRestore(ctx context.Context, req *client.StreamRestoreRequest, data io.Reader) (*client.RestoreResponse, error) {
+	defer f.mu.Unlock()
+	if err := f.begin("stream restore"); err != nil {
+		return nil, err
+	}
+	if _, err := io.Copy(io.Discard, data); err != nil {
+		return nil, err
+	}
+	return &client.RestoreResponse{Restored: "upload"}, nil
+}
+
+// Dump writes nothing: the fake keeps no archive of its documents.
+func (f *Fake) Dump(ctx context.Context, req *client.DumpRequest, w io.Writer) (*client.DumpSummary, error) {
+	defer f.mu.Unlock()
+	if err := f.begin("dump"); err != nil {
+		return nil, err
+	}
+	return &client.DumpSummary{Format: "mddb-dump", Version: 1, Collections: req.Collections}, nil
+}
+
+// Load drains data without touching the stored documents.
+func (f *Fake) Load(ctx context.Context, req *client.LoadRequest, data io.Reader) (*client.LoadResponse, error) {
+	defer f.mu.Unlock()
+	if err := f.begin("load"); err != nil {
+		return nil, err
+	}
+	if _, err := io.Copy(io.Discard, data); err != nil {
+		return nil, err
+	}
+	return &client.LoadResponse{Format: "mddb-dump", Version: 1}, nil
+}
+
+func (f *Fake) Truncate(ctx context.Context, req *client.TruncateRequest) (*client.TruncateResponse, error) {
+	defer f.mu.Unlock()
+	if err := f.begin("truncate"); err != nil {
+		return nil, err
+	}
+	return &client.TruncateResponse{Status: "truncated"}, nil
+}
+
+// Fsck reports no problems: the fake's maps cannot disagree with each other.
+func (f *Fake) Fsck(ctx context.Context, req *client.FsckRequest) (*client.FsckReport, error) {
+	defer f.mu.Unlock()
+	if err := f.begin("fsck"); err != nil {
+		return nil, err
+	}
+	rep := &client.FsckReport{Problems: map[string]int{}, Issues: []client.FsckIssue{}}
+	for key := range f.docs {
+		if req.Collection == "" || strings.HasPrefix(key, req.Collection+"|") {
+			rep.Documents++
+		}
+	}
+	rep.KeyEntries = rep.Documents
+	return rep, nil
+}
+
+// Compact has no file to shrink and reports nothing reclaimed.
+func (f *Fake) Compact(ctx context.Context, req *client.CompactRequest) (*client.CompactResponse, error) {
+	defer f.mu.Unlock()
+	if err := f.begin("compact"); err != nil {
+		return nil, err
+	}
+	return &client.CompactResponse{Compacted: true}, nil
+}
+
+func (f *Fake) ListCollections(ctx context.Context) ([]client.CollectionInfo, error) {
+	defer f.mu.Unlock()
+	if err := f.begin("list collections"); err != nil {
+		return nil, err
+	}
+	out := make([]client.CollectionInfo, 0, len(f.collections))
+	for _, ci := range f.collections {
+		out = append(out, *ci)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Name < out[j].Name })
+	return out, nil
+}
+
+func (f *Fake) CreateCollection(ctx context.Context, req *client.CollectionInfo) (*client.CollectionInfo, error) {
+	defer f.mu.Unlock()
+	if err := f.begin("create collection"); err != nil {
+		return nil, err
+	}
+	if req.Name == "" {
+		return nil, badRequest("missing collection name")
+	}
+	if _, ok := f.collections[req.Name]; ok {
+		return nil, &client.Error{Message: "collection already exists", Code: "ALREADY_EXISTS", Status: 409, Reason: client.ReasonCollectionExists}
+	}
+	ci := *req
+	ci.CreatedAt, ci.UpdatedAt = f.now(), f.now()
+	f.collections[ci.Name] = &ci
+	out := ci
+	return &out, nil
+}
+
+func (f *Fake) UpdateCollection(ctx context.Context, req *client.CollectionInfo) (*client.CollectionInfo, error) {
+	defer f.mu.Unlock()
+	if err := f.begin("update collection"); err != nil {
+		return nil, err
+	}
+	cur, ok := f.collections[req.Name]
+	if !ok {
+		return nil, notFound(client.ReasonCollectionNotFound, "collection not found")
+	}
+	ci := *req
+	ci.CreatedAt, ci.UpdatedAt = cur.CreatedAt, f.now()
+	f.collections[ci.Name] = &ci
+	out := ci
+	return &out, nil
+}
+
+func (f *Fake) DescribeCollection(ctx context.Context, name string) (*client.CollectionDescription, error) {
+	defer f.mu.Unlock()
+	if err := f.begin("describe collection"); err != nil {
+		return nil, err
+	}
+	ci, ok := f.collections[name]
+	if !ok {
+		return nil, notFound(client.ReasonCollectionNotFound, "collection not found")
+	}
+	_, hasSchema := f.schemas[name]
+	return &client.CollectionDescription{CollectionInfo: *ci, DocumentCount: len(f.list(name, nil)), HasSchema: hasSchema}, nil
+}
+
+func (f *Fake) RenameCollection(ctx context.Context, req *client.CopyCollectionRequest) (*client.CopyCollectionResponse, error) {
+	defer f.mu.Unlock()
+	if err := f.begin("rename collection"); err != nil {
+		return nil, err
+	}
+	n, err := f.copyCollection(req.Name, req.NewName, true)
+	if err != nil {
+		return nil, err
+	}
+	return &client.CopyCollectionResponse{Status: "renamed", Collection: req.NewName, Documents: n}, nil
+}
+
+func (f *Fake) CloneCollection(ctx context.Context, req *client.CopyCollectionRequest) (*client.CopyCollectionResponse, error) {
+	defer f.mu.Unlock()
+	if err := f.begin("clone collection"); err != nil {
+		return nil, err
+	}
+	n, err := f.copyCollection(req.Name, req.NewName, false)
+	if err != nil {
+		return nil, err
+	}
+	return &client.CopyCollectionResponse{Status: "cloned", Collection: req.NewName, Documents: n}, nil
+}
+
+func (f *Fake) copyCollection(from, to string, move bool) (int, error) {
+	src, ok := f.collections[from]
+	if !ok {
+		return 0, notFound(client.ReasonCollectionNotFound, "collection not found")
+	}
+	if _, ok := f.collections[to]; ok || to == "" {
+		return 0, &client.Error{Message: "collection already exists", Code: "ALREADY_EXISTS", Status: 409, Reason: client.ReasonCollectionExists}
+	}
+	ci := *src
+	ci.Name = to
+	f.collections[to] = &ci
+	docs := f.list(from, nil)
+	for _, d := range docs {
+		d.ID = strings.ToLower(docKey(to, d.Key, d.Lang))
+		f.docs[docKey(to, d.Key, d.Lang)] = &d
+		if move {
+			delete(f.docs, docKey(from, d.Key, d.Lang))
+		}
+	}
+	if s, ok := f.schemas[from]; ok {
+		cp := *s
+		cp.Collection = to
+		f.schemas[to] = &cp
+	}
+	if move {
+		delete(f.collections, from)
+		delete(f.schemas, from)
+	}
+	return len(docs), nil
+}
+
+// SetSchema stores the schema; the fake does not validate documents against it.
+func (f *Fake) SetSchema(ctx context.Context, schema *client.CollectionSchema) (*client.CollectionSchema, error) {
+	defer f.mu.Unlock()
+	if err := f.begin("set schema"); err != nil {
+		return nil, err
+	}
+	if schema.Collection == "" {
+		return nil, badRequest("missing collection")
+	}
+	s := *schema
+	f.schemas[s.Collection] = &s
+	out := s
+	return &out, nil
+}
+
+func (f *Fake) GetSchema(ctx context.Context, collection string) (*client.CollectionSchema, error) {
+	defer f.mu.Unlock()
+	if err := f.begin("get schema"); err != nil {
+		return nil, err
+	}
+	s, ok := f.schemas[collection]
+	if !ok {
+		return nil, notFound(client.ReasonSchemaNotFound, "schema not found")
+	}
+	out := *s
+	return &out, nil
+}
+
+func (f *Fake) DeleteSchema(ctx context.Context, collection string) error {
+	defer f.mu.Unlock()
+	if err := f.begin("delete schema"); err != nil {
+		return err
+	}
+	delete(f.schemas, collection)
+	return nil
+}
+
+// WhoAmI reports an anonymous caller allowed everything, like a server without auth.
+func (f *Fake) WhoAmI(ctx context.Context) (*client.Principal, error) {
+	defer f.mu.Unlock()
+	if err := f.begin("whoami"); err != nil {
+		return nil, err
+	}
+	return &client.Principal{Subject: "anonymous", Method: "none", Roles: []string{"admin:*"}}, nil
+}
+
+func (f *Fake) CreateAPIKey(ctx context.Context, req *client.CreateAPIKeyRequest) (*client.APIKey, error) {
+	defer f.mu.Unlock()
+	if err := f.begin("create api key"); err != nil {
+		return nil, err
+	}
+	if req.Name == "" || len(req.Roles) == 0 {
+		return nil, badRequest("missing name or roles")
+	}
+	f.keySeq++
+	k := client.APIKey{ID: fmt.Sprintf("%012x", f.keySeq), Name: req.Name, Roles: append([]string(nil), req.Roles...), CreatedAt: f.now()}
+	f.apiKeys[k.ID] = &k
+	out := k
+	out.Key = "mddb_" + k.ID + "_fake"
+	return &out, nil
+}
+
+func (f *Fake) ListAPIKeys(ctx context.Context) ([]client.APIKey, error) {
+	defer f.mu.Unlock()
+	if err := f.begin("list api keys"); err != nil {
+		return nil, err
+	}
+	out := make([]client.APIKey, 0, len(f.apiKeys))
+	for _, k := range f.apiKeys {
+		out = append(out, *k)
+	}
+	sort.Slice(out, func(i, j int) bool { return out[i].Name < out[j].Name })
+	return out, nil
+}
+
+func (f *Fake) DeleteAPIKey(ctx context.Context, id string) error {
+	defer f.mu.Unlock()
+	if err := f.begin("delete api key"); err != nil {
+		return err
+	}
+	if f.apiKeys[id] == nil {
+		return notFound(client.ReasonAPIKeyNotFound, "api key not found")
+	}
+	delete(f.apiKeys, id)
+	return nil
+}
+
+func (f *Fake) Close() error {
+	return nil
+}
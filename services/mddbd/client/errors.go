@@ -0,0 +1,175 @@
+package client
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+
+	"google.golang.org/genproto/googleapis/rpc/code"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// ErrCircuitOpen is returned when every transport is skipped by its circuit breaker.
+var ErrCircuitOpen = errors.New("circuit breaker open")
+
+// Machine-readable error reasons reported by the server.
+const (
+	ReasonBadRequest         = "BAD_REQUEST"
+	ReasonInternal           = "INTERNAL"
+	ReasonReadOnly           = "READ_ONLY"
+	ReasonDocumentNotFound   = "DOCUMENT_NOT_FOUND"
+	ReasonDocumentExists     = "DOCUMENT_EXISTS"
+	ReasonCollectionNotFound = "COLLECTION_NOT_FOUND"
+	ReasonCollectionExists   = "COLLECTION_EXISTS"
+	ReasonSchemaNotFound     = "SCHEMA_NOT_FOUND"
+	ReasonSchemaViolation    = "SCHEMA_VIOLATION"
+	ReasonPreconditionFailed = "PRECONDITION_FAILED"
+	ReasonPayloadTooLarge    = "PAYLOAD_TOO_LARGE"
+	ReasonUnavailable        = "UNAVAILABLE"
+	ReasonUnauthenticated    = "UNAUTHENTICATED"
+	ReasonPermissionDenied   = "PERMISSION_DENIED"
+	ReasonAPIKeyNotFound     = "API_KEY_NOT_FOUND"
+	ReasonCompactionRunning  = "COMPACTION_RUNNING"
+	ReasonBackupNotFound     = "BACKUP_NOT_FOUND"
+	ReasonInvalidBackup      = "INVALID_BACKUP"
+	ReasonNoRecoveryPoint    = "RECOVERY_POINT_UNAVAILABLE"
+)
+
+// Error is a server or transport error. Both transports fill the same fields: over HTTP
+// they come from the structured JSON body, over gRPC from the status and its ErrorInfo.
+type Error struct {
+	Message  string            `json:"error"`
+	Code     string            `json:"code"`   // canonical gRPC code name, e.g. NOT_FOUND
+	Status   int               `json:"status"` // HTTP status
+	Reason   string            `json:"reason"`
+	Metadata map[string]string `json:"metadata,omitempty"`
+}
+
+func (e *Error) Error() string { return e.Message }
+
+// IsNotFound reports whether err means the document, collection or schema does not exist.
+func IsNotFound(err error) bool {
+	var e *Error
+	return errors.As(err, &e) && e.Code == code.Code_name[int32(codes.NotFound)]
+}
+
+// IsRetryable reports whether err is a transient transport failure worth retrying on the
+// same transport or another one. Server-side rejections and caller cancellation are not.
+func IsRetryable(err error) bool {
+	if err == nil || errors.Is(err, context.Canceled) {
+		return false
+	}
+	var e *Error
+	if !errors.As(err, &e) {
+		return false
+	}
+	switch e.Code {
+	case "UNAVAILABLE", "ABORTED", "DEADLINE_EXCEEDED":
+		return true
+	}
+	switch e.Status {
+	case http.StatusTooManyRequests, http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return true
+	}
+	return false
+}
+
+// unavailable wraps a connection-level failure
+func unavailable(err error) *Error {
+	return &Error{Message: err.Error(), Code: "UNAVAILABLE", Status: http.StatusServiceUnavailable, Reason: ReasonUnavailable}
+}
+
+// fromGRPC converts a gRPC status error into *Error
+func fromGRPC(err error) error {
+	st, ok := status.FromError(err)
+	if !ok {
+		return err
+	}
+	if st.Code() == codes.Canceled {
+		return context.Canceled
+	}
+	e := &Error{Message: st.Message(), Code: code.Code_name[int32(st.Code())], Status: httpStatus(st.Code())}
+	for _, d := range st.Details() {
+		if info, ok := d.(*errdetails.ErrorInfo); ok {
+			e.Reason = info.Reason
+			e.Metadata = info.Metadata
+		}
+	}
+	return e
+}
+
+// fromHTTP builds an *Error from a non-2xx response. Bodies without the structured fields
+// (proxies, older servers) still get a code derived from the status.
+func fromHTTP(resp *http.Response) error {
+	e := &Error{Status: resp.StatusCode}
+	data, _ := io.ReadAll(io.LimitReader(resp.Body, 64<<10))
+	_ = json.Unmarshal(data, e)
+	e.Status = resp.StatusCode
+	if e.Message == "" {
+		e.Message = http.StatusText(resp.StatusCode)
+	}
+	if e.Code == "" {
+		e.Code = code.Code_name[int32(grpcCode(resp.StatusCode))]
+	}
+	return e
+}
+
+func httpStatus(c codes.Code) int {
+	switch c {
+	case codes.OK:
+		return http.StatusOK
+	case codes.InvalidArgument, codes.OutOfRange:
+		return http.StatusBadRequest
+	case codes.NotFound:
+		return http.StatusNotFound
+	case codes.AlreadyExists, codes.Aborted:
+		return http.StatusConflict
+	case codes.PermissionDenied:
+		return http.StatusForbidden
+	case codes.Unauthenticated:
+		return http.StatusUnauthorized
+	case codes.FailedPrecondition:
+		return http.StatusPreconditionFailed
+	case codes.ResourceExhausted:
+		return http.StatusTooManyRequests
+	case codes.Unimplemented:
+		return http.StatusNotImplemented
+	case codes.Unavailable:
+		return http.StatusServiceUnavailable
+	case codes.DeadlineExceeded:
+		return http.StatusGatewayTimeout
+	default:
+		return http.StatusInternalServerError
+	}
+}
+
+func grpcCode(httpStatus int) codes.Code {
+	switch httpStatus {
+	case http.StatusBadRequest, http.StatusUnprocessableEntity:
+		return codes.InvalidArgument
+	case http.StatusUnauthorized:
+		return codes.Unauthenticated
+	case http.StatusForbidden:
+		return codes.PermissionDenied
+	case http.StatusNotFound:
+		return codes.NotFound
+	case http.StatusConflict:
+		return codes.AlreadyExists
+	case http.StatusPreconditionFailed:
+		return codes.FailedPrecondition
+	case http.StatusTooManyRequests, http.StatusRequestEntityTooLarge:
+		return codes.ResourceExhausted
+	case http.StatusNotImplemented:
+		return codes.Unimplemented
+	case http.StatusBadGateway, http.StatusServiceUnavailable:
+		return codes.Unavailable
+	case http.StatusGatewayTimeout:
+		return codes.DeadlineExceeded
+	default:
+		return codes.Internal
+	}
+}
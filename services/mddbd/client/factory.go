@@ -0,0 +1,88 @@
+package client
+
+import (
+	"crypto/tls"
+	"fmt"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+)
+
+// ClientConfig contains MDDB client configuration.
+type ClientConfig struct {
+	GRPCAddress   string
+	RESTBaseURL   string
+	TransportMode string        // default grpc_with_rest_fallback
+	Timeout       time.Duration // per attempt, for unary calls (0 = only the context deadline)
+	Token         string        // API key or JWT, sent as a bearer token (empty = no auth)
+	TLS           *tls.Config   // gRPC over TLS and HTTPS settings, see LoadTLSConfig (nil = plaintext gRPC)
+
+	MaxRetries      int           // retries of a transient failure on the same transport
+	RetryBackoff    time.Duration // base of the exponential backoff (default 100ms)
+	MaxRetryBackoff time.Duration // cap of a single backoff (default 2s)
+
+	BreakerThreshold int           // consecutive transport failures that open the circuit (default 5)
+	BreakerCooldown  time.Duration // how long an open circuit skips the transport (default 30s)
+}
+
+func (cfg ClientConfig) withDefaults() ClientConfig {
+	if cfg.TransportMode == "" {
+		cfg.TransportMode = string(TransportGRPCWithRESTFallback)
+	}
+	if cfg.MaxRetries < 0 {
+		cfg.MaxRetries = 0
+	}
+	if cfg.RetryBackoff <= 0 {
+		cfg.RetryBackoff = 100 * time.Millisecond
+	}
+	if cfg.MaxRetryBackoff <= 0 {
+		cfg.MaxRetryBackoff = 2 * time.Second
+	}
+	if cfg.BreakerThreshold <= 0 {
+		cfg.BreakerThreshold = 5
+	}
+	if cfg.BreakerCooldown <= 0 {
+		cfg.BreakerCooldown = 30 * time.Second
+	}
+	return cfg
+}
+
+// NewClient creates MDDB client based on configuration.
+func NewClient(cfg ClientConfig) (*FallbackClient, error) {
+	cfg = cfg.withDefaults()
+	mode := TransportMode(cfg.TransportMode)
+
+	var grpcClient, restClient Client
+
+	// Initialize clients depending on mode
+	switch mode {
+	case TransportGRPCOnly, TransportGRPCWithRESTFallback, TransportRESTWithGRPCFallback:
+		var opts []grpc.DialOption
+		if cfg.TLS != nil {
+			opts = append(opts, grpc.WithTransportCredentials(credentials.NewTLS(cfg.TLS)))
+		}
+		gc, err := NewGRPCClient(cfg.GRPCAddress, cfg.Timeout, opts...)
+		if err != nil {
+			if mode == TransportGRPCOnly {
+				return nil, fmt.Errorf("create grpc client: %w", err)
+			}
+			// In fallback mode, continue with REST only
+		} else {
+			gc.SetToken(cfg.Token)
+			grpcClient = gc
+		}
+	}
+
+	switch mode {
+	case TransportRESTOnly, TransportRESTWithGRPCFallback, TransportGRPCWithRESTFallback:
+		rc := NewRESTClient(cfg.RESTBaseURL, cfg.Timeout)
+		rc.SetToken(cfg.Token)
+		if cfg.TLS != nil {
+			rc.SetTLS(cfg.TLS)
+		}
+		restClient = rc
+	}
+
+	return NewFallbackClient(cfg, grpcClient, restClient), nil
+}
@@ -0,0 +1,376 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+)
+
+// TransportMode specifies transport mode.
+type TransportMode string
+
+const (
+	TransportGRPCOnly             TransportMode = "grpc_only"
+	TransportRESTOnly             TransportMode = "rest_only"
+	TransportGRPCWithRESTFallback TransportMode = "grpc_with_rest_fallback"
+	TransportRESTWithGRPCFallback TransportMode = "rest_with_grpc_fallback"
+)
+
+type transport struct {
+	name    string
+	client  Client
+	breaker *breaker
+}
+
+// FallbackClient implements Client on top of one or two transports. Each call is retried
+// on transient failures, then moves on to the next transport; a transport whose circuit
+// breaker is open is skipped. Server-side errors (not found, validation) are returned as
+// they are, since another transport would give the same answer.
+type FallbackClient struct {
+	mode       TransportMode
+	transports []*transport
+	retry      retryPolicy
+}
+
+// NewFallbackClient creates client with fallback. Either client may be nil.
+func NewFallbackClient(cfg ClientConfig, grpcClient, restClient Client) *FallbackClient {
+	cfg = cfg.withDefaults()
+	mode := TransportMode(cfg.TransportMode)
+
+	var primary, secondary Client
+	var primaryName, secondaryName string
+
+	switch mode {
+	case TransportGRPCOnly:
+		primary, primaryName = grpcClient, "grpc"
+	case TransportRESTOnly:
+		primary, primaryName = restClient, "rest"
+	case TransportRESTWithGRPCFallback:
+		primary, primaryName = restClient, "rest"
+		secondary, secondaryName = grpcClient, "grpc"
+	case TransportGRPCWithRESTFallback:
+		primary, primaryName = grpcClient, "grpc"
+		secondary, secondaryName = restClient, "rest"
+	default:
+		log.Printf("unknown transport mode %s, using grpc_with_rest_fallback", mode)
+		mode = TransportGRPCWithRESTFallback
+		primary, primaryName = grpcClient, "grpc"
+		secondary, secondaryName = restClient, "rest"
+	}
+
+	c := &FallbackClient{
+		mode:  mode,
+		retry: retryPolicy{maxRetries: cfg.MaxRetries, base: cfg.RetryBackoff, max: cfg.MaxRetryBackoff},
+	}
+	for _, t := range []struct {
+		name   string
+		client Client
+	}{{primaryName, primary}, {secondaryName, secondary}} {
+		if t.client == nil {
+			continue
+		}
+		c.transports = append(c.transports, &transport{
+			name:    t.name,
+			client:  t.client,
+			breaker: &breaker{name: t.name, threshold: cfg.BreakerThreshold, cooldown: cfg.BreakerCooldown},
+		})
+	}
+	return c
+}
+
+// call runs fn on the first transport whose circuit allows it, retrying transient
+// failures and falling back to the next transport. once disables retries and fallback
+// after the first attempt, for calls that consume a stream or are not idempotent.
+func call[T any](c *FallbackClient, ctx context.Context, op string, once bool, fn func(context.Context, Client) (T, error)) (T, error) {
+	var zero T
+	lastErr := fmt.Errorf("%s: no transport configured", op)
+	for i, t := range c.transports {
+		if !t.breaker.allow() {
+			lastErr = fmt.Errorf("%s via %s: %w", op, t.name, ErrCircuitOpen)
+			continue
+		}
+
+		policy := c.retry
+		if once {
+			policy.maxRetries = 0
+		}
+		var res T
+		err := policy.do(ctx, func(ctx context.Context) error {
+			var err error
+			res, err = fn(ctx, t.client)
+			return err
+		})
+
+		switch {
+		case err == nil:
+			t.breaker.success()
+			return res, nil
+		case ctx.Err() != nil:
+			t.breaker.release()
+			return zero, err
+		case !IsRetryable(err):
+			// The server answered, so the transport is healthy
+			t.breaker.success()
+			return zero, err
+		}
+		t.breaker.failure()
+		lastErr = err
+		if once {
+			return zero, err
+		}
+		if i+1 < len(c.transports) {
+			log.Printf("%s via %s failed: %v, trying %s", op, t.name, err, c.transports[i+1].name)
+		}
+	}
+	return zero, lastErr
+}
+
+// exec is call for operations without a result
+func exec(c *FallbackClient, ctx context.Context, op string, fn func(context.Context, Client) error) error {
+	_, err := call(c, ctx, op, false, func(ctx context.Context, cl Client) (struct{}, error) {
+		return struct{}{}, fn(ctx, cl)
+	})
+	return err
+}
+
+func (c *FallbackClient) Health(ctx context.Context) (*Health, error) {
+	return call(c, ctx, "health", false, func(ctx context.Context, cl Client) (*Health, error) {
+		return cl.Health(ctx)
+	})
+}
+
+func (c *FallbackClient) Stats(ctx context.Context) (*Stats, error) {
+	return call(c, ctx, "stats", false, func(ctx context.Context, cl Client) (*Stats, error) {
+		return cl.Stats(ctx)
+	})
+}
+
+func (c *FallbackClient) Add(ctx context.Context, req *AddRequest) (*Document, error) {
+	return call(c, ctx, "add", false, func(ctx context.Context, cl Client) (*Document, error) {
+		return cl.Add(ctx, req)
+	})
+}
+
+// Patch is attempted once when it appends content: a retry after a lost response would
+// append twice. Meta operations and content replacement are idempotent.
+func (c *FallbackClient) Patch(ctx context.Context, req *PatchRequest) (*Document, error) {
+	return call(c, ctx, "patch", req.AppendMD != "", func(ctx context.Context, cl Client) (*Document, error) {
+		return cl.Patch(ctx, req)
+	})
+}
+
+// Transaction is attempted once: after a lost response a retry could apply it twice, or
+// fail on preconditions the first attempt already changed.
+func (c *FallbackClient) Transaction(ctx context.Context, req *TransactionRequest) (*TransactionResponse, error) {
+	return call(c, ctx, "transaction", true, func(ctx context.Context, cl Client) (*TransactionResponse, error) {
+		return cl.Transaction(ctx, req)
+	})
+}
+
+func (c *FallbackClient) AddBatch(ctx context.Context, req *AddBatchRequest) (*AddBatchResponse, error) {
+	return call(c, ctx, "add batch", false, func(ctx context.Context, cl Client) (*AddBatchResponse, error) {
+		return cl.AddBatch(ctx, req)
+	})
+}
+
+func (c *FallbackClient) UpdateBatch(ctx context.Context, req *UpdateBatchRequest) (*UpdateBatchResponse, error) {
+	return call(c, ctx, "update batch", false, func(ctx context.Context, cl Client) (*UpdateBatchResponse, error) {
+		return cl.UpdateBatch(ctx, req)
+	})
+}
+
+func (c *FallbackClient) DeleteBatch(ctx context.Context, req *DeleteBatchRequest) (*DeleteBatchResponse, error) {
+	return call(c, ctx, "delete batch", false, func(ctx context.Context, cl Client) (*DeleteBatchResponse, error) {
+		return cl.DeleteBatch(ctx, req)
+	})
+}
+
+func (c *FallbackClient) Get(ctx context.Context, req *GetRequest) (*Document, error) {
+	return call(c, ctx, "get", false, func(ctx context.Context, cl Client) (*Document, error) {
+		return cl.Get(ctx, req)
+	})
+}
+
+func (c *FallbackClient) Search(ctx context.Context, req *SearchRequest) (*SearchResponse, error) {
+	return call(c, ctx, "search", false, func(ctx context.Context, cl Client) (*SearchResponse, error) {
+		return cl.Search(ctx, req)
+	})
+}
+
+func (c *FallbackClient) Delete(ctx context.Context, req *DeleteRequest) error {
+	return exec(c, ctx, "delete", func(ctx context.Context, cl Client) error {
+		return cl.Delete(ctx, req)
+	})
+}
+
+func (c *FallbackClient) DeleteCollection(ctx context.Context, req *DeleteCollectionRequest) (*DeleteCollectionResponse, error) {
+	return call(c, ctx, "delete collection", false, func(ctx context.Context, cl Client) (*DeleteCollectionResponse, error) {
+		return cl.DeleteCollection(ctx, req)
+	})
+}
+
+// Export retries and falls back only until the stream is open; a stream that breaks
+// later surfaces the error from Read.
+func (c *FallbackClient) Export(ctx context.Context, req *ExportRequest) (io.ReadCloser, error) {
+	return call(c, ctx, "export", false, func(ctx context.Context, cl Client) (io.ReadCloser, error) {
+		return cl.Export(ctx, req)
+	})
+}
+
+// Import is attempted once: the data reader cannot be replayed.
+func (c *FallbackClient) Import(ctx context.Context, req *ImportRequest, data io.Reader) (*ImportResponse, error) {
+	return call(c, ctx, "import", true, func(ctx context.Context, cl Client) (*ImportResponse, error) {
+		return cl.Import(ctx, req, data)
+	})
+}
+
+func (c *FallbackClient) Backup(ctx context.Context, req *BackupRequest) (*BackupResponse, error) {
+	return call(c, ctx, "backup", false, func(ctx context.Context, cl Client) (*BackupResponse, error) {
+		return cl.Backup(ctx, req)
+	})
+}
+
+// Restore is attempted once: a repeated restore would replace the file kept for rollback.
+func (c *FallbackClient) Restore(ctx context.Context, req *RestoreRequest) (*RestoreResponse, error) {
+	return call(c, ctx, "restore", true, func(ctx context.Context, cl Client) (*RestoreResponse, error) {
+		return cl.Restore(ctx, req)
+	})
+}
+
+// StreamRestore is attempted once: the data reader cannot be replayed.
+func (c *FallbackClient) StreamRestore(ctx context.Context, req *StreamRestoreRequest, data io.Reader) (*RestoreResponse, error) {
+	return call(c, ctx, "stream restore", true, func(ctx context.Context, cl Client) (*RestoreResponse, error) {
+		return cl.StreamRestore(ctx, req, data)
+	})
+}
+
+// StreamBackup is attempted once: bytes already written to w cannot be taken back.
+func (c *FallbackClient) StreamBackup(ctx context.Context, req *StreamBackupRequest, w io.Writer) (*BackupManifest, error) {
+	return call(c, ctx, "stream backup", true, func(ctx context.Context, cl Client) (*BackupManifest, error) {
+		return cl.StreamBackup(ctx, req, w)
+	})
+}
+
+// Dump is attempted once: bytes already written to w cannot be taken back.
+func (c *FallbackClient) Dump(ctx context.Context, req *DumpRequest, w io.Writer) (*DumpSummary, error) {
+	return call(c, ctx, "dump", true, func(ctx context.Context, cl Client) (*DumpSummary, error) {
+		return cl.Dump(ctx, req, w)
+	})
+}
+
+// Load is attempted once: the data reader cannot be replayed.
+func (c *FallbackClient) Load(ctx context.Context, req *LoadRequest, data io.Reader) (*LoadResponse, error) {
+	return call(c, ctx, "load", true, func(ctx context.Context, cl Client) (*LoadResponse, error) {
+		return cl.Load(ctx, req, data)
+	})
+}
+
+func (c *FallbackClient) Truncate(ctx context.Context, req *TruncateRequest) (*TruncateResponse, error) {
+	return call(c, ctx, "truncate", false, func(ctx context.Context, cl Client) (*TruncateResponse, error) {
+		return cl.Truncate(ctx, req)
+	})
+}
+
+func (c *FallbackClient) Fsck(ctx context.Context, req *FsckRequest) (*FsckReport, error) {
+	return call(c, ctx, "fsck", false, func(ctx context.Context, cl Client) (*FsckReport, error) {
+		return cl.Fsck(ctx, req)
+	})
+}
+
+func (c *FallbackClient) Compact(ctx context.Context, req *CompactRequest) (*CompactResponse, error) {
+	return call(c, ctx, "compact", false, func(ctx context.Context, cl Client) (*CompactResponse, error) {
+		return cl.Compact(ctx, req)
+	})
+}
+
+func (c *FallbackClient) ListCollections(ctx context.Context) ([]CollectionInfo, error) {
+	return call(c, ctx, "list collections", false, func(ctx context.Context, cl Client) ([]CollectionInfo, error) {
+		return cl.ListCollections(ctx)
+	})
+}
+
+func (c *FallbackClient) CreateCollection(ctx context.Context, req *CollectionInfo) (*CollectionInfo, error) {
+	return call(c, ctx, "create collection", false, func(ctx context.Context, cl Client) (*CollectionInfo, error) {
+		return cl.CreateCollection(ctx, req)
+	})
+}
+
+func (c *FallbackClient) UpdateCollection(ctx context.Context, req *CollectionInfo) (*CollectionInfo, error) {
+	return call(c, ctx, "update collection", false, func(ctx context.Context, cl Client) (*CollectionInfo, error) {
+		return cl.UpdateCollection(ctx, req)
+	})
+}
+
+func (c *FallbackClient) DescribeCollection(ctx context.Context, name string) (*CollectionDescription, error) {
+	return call(c, ctx, "describe collection", false, func(ctx context.Context, cl Client) (*CollectionDescription, error) {
+		return cl.DescribeCollection(ctx, name)
+	})
+}
+
+// RenameCollection is attempted once: a retry after a lost response would fail with not found.
+func (c *FallbackClient) RenameCollection(ctx context.Context, req *CopyCollectionRequest) (*CopyCollectionResponse, error) {
+	return call(c, ctx, "rename collection", true, func(ctx context.Context, cl Client) (*CopyCollectionResponse, error) {
+		return cl.RenameCollection(ctx, req)
+	})
+}
+
+func (c *FallbackClient) CloneCollection(ctx context.Context, req *CopyCollectionRequest) (*CopyCollectionResponse, error) {
+	return call(c, ctx, "clone collection", false, func(ctx context.Context, cl Client) (*CopyCollectionResponse, error) {
+		return cl.CloneCollection(ctx, req)
+	})
+}
+
+func (c *FallbackClient) SetSchema(ctx context.Context, schema *CollectionSchema) (*CollectionSchema, error) {
+	return call(c, ctx, "set schema", false, func(ctx context.Context, cl Client) (*CollectionSchema, error) {
+		return cl.SetSchema(ctx, schema)
+	})
+}
+
+func (c *FallbackClient) GetSchema(ctx context.Context, collection string) (*CollectionSchema, error) {
+	return call(c, ctx, "get schema", false, func(ctx context.Context, cl Client) (*CollectionSchema, error) {
+		return cl.GetSchema(ctx, collection)
+	})
+}
+
+func (c *FallbackClient) DeleteSchema(ctx context.Context, collection string) error {
+	return exec(c, ctx, "delete schema", func(ctx context.Context, cl Client) error {
+		return cl.DeleteSchema(ctx, collection)
+	})
+}
+
+func (c *FallbackClient) WhoAmI(ctx context.Context) (*Principal, error) {
+	return call(c, ctx, "whoami", false, func(ctx context.Context, cl Client) (*Principal, error) {
+		return cl.WhoAmI(ctx)
+	})
+}
+
+// CreateAPIKey is attempted once: a retry after a lost response would create a second key.
+func (c *FallbackClient) CreateAPIKey(ctx context.Context, req *CreateAPIKeyRequest) (*APIKey, error) {
+	return call(c, ctx, "create api key", true, func(ctx context.Context, cl Client) (*APIKey, error) {
+		return cl.CreateAPIKey(ctx, req)
+	})
+}
+
+func (c *FallbackClient) ListAPIKeys(ctx context.Context) ([]APIKey, error) {
+	return call(c, ctx, "list api keys", false, func(ctx context.Context, cl Client) ([]APIKey, error) {
+		return cl.ListAPIKeys(ctx)
+	})
+}
+
+func (c *FallbackClient) DeleteAPIKey(ctx context.Context, id string) error {
+	return exec(c, ctx, "delete api key", func(ctx context.Context, cl Client) error {
+		return cl.DeleteAPIKey(ctx, id)
+	})
+}
+
+func (c *FallbackClient) Close() error {
+	var errs []error
+	for _, t := range c.transports {
+		if err := t.client.Close(); err != nil {
+			errs = append(errs, fmt.Errorf("close %s: %w", t.name, err))
+		}
+	}
+	return errors.Join(errs...)
+}
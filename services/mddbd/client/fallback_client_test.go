@@ -0,0 +1,105 @@
+package client_test
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/tradik/mddb/services/mddbd/client"
+	"github.com/tradik/mddb/services/mddbd/client/clienttest"
+)
+
+func TestFallbackClient(t *testing.T) {
+	notFound := &client.Error{Message: "not found", Code: "NOT_FOUND", Status: 404, Reason: client.ReasonDocumentNotFound}
+	cases := []struct {
+		name       string
+		grpcErrs   []error
+		restErrs   []error
+		grpcCalls  int
+		restCalls  int
+		err        error
+		maxRetries int
+	}{
+		{name: "primary answers", grpcCalls: 1},
+		{name: "retried on the primary", maxRetries: 2, grpcErrs: []error{clienttest.Unavailable, clienttest.Unavailable}, grpcCalls: 3},
+		{name: "falls back after the retries", maxRetries: 1, grpcErrs: []error{clienttest.Unavailable, clienttest.Unavailable}, grpcCalls: 2, restCalls: 1},
+		{name: "server errors do not fall back", maxRetries: 2, grpcErrs: []error{notFound}, grpcCalls: 1, err: notFound},
+		{
+			name:       "both transports fail",
+			maxRetries: 1,
+			grpcErrs:   []error{clienttest.Unavailable, clienttest.Unavailable},
+			restErrs:   []error{clienttest.Unavailable, clienttest.Unavailable},
+			grpcCalls:  2,
+			restCalls:  2,
+			err:        clienttest.Unavailable,
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			grpc, rest := clienttest.NewFake(), clienttest.NewFake()
+			grpc.FailNext("health", c.grpcErrs...)
+			rest.FailNext("health", c.restErrs...)
+			fc := client.NewFallbackClient(client.ClientConfig{MaxRetries: c.maxRetries, RetryBackoff: time.Millisecond}, grpc, rest)
+
+			_, err := fc.Health(context.Background())
+			if !errors.Is(err, c.err) {
+				t.Errorf("Health: %v, want %v", err, c.err)
+			}
+			if n := grpc.Calls("health"); n != c.grpcCalls {
+				t.Errorf("%d gRPC calls, want %d", n, c.grpcCalls)
+			}
+			if n := rest.Calls("health"); n != c.restCalls {
+				t.Errorf("%d REST calls, want %d", n, c.restCalls)
+			}
+		})
+	}
+}
+
+func TestFallbackClientCircuit(t *testing.T) {
+	grpc, rest := clienttest.NewFake(), clienttest.NewFake()
+	fc := client.NewFallbackClient(client.ClientConfig{
+		TransportMode:    string(client.TransportGRPCOnly),
+		BreakerThreshold: 2,
+		BreakerCooldown:  50 * time.Millisecond,
+	}, grpc, rest)
+	ctx := context.Background()
+
+	grpc.FailNext("health", clienttest.Unavailable, clienttest.Unavailable)
+	for range 2 {
+		if _, err := fc.Health(ctx); !errors.Is(err, clienttest.Unavailable) {
+			t.Fatalf("Health: %v, want unavailable", err)
+		}
+	}
+	// Open: calls fail fast without reaching the transport
+	if _, err := fc.Health(ctx); !errors.Is(err, client.ErrCircuitOpen) {
+		t.Fatalf("Health with the circuit open: %v, want ErrCircuitOpen", err)
+	}
+	if n := grpc.Calls("health"); n != 2 {
+		t.Errorf("%d calls reached the transport, want 2", n)
+	}
+
+	// Half-open: a failed probe reopens the circuit
+	time.Sleep(60 * time.Millisecond)
+	grpc.FailNext("health", clienttest.Unavailable)
+	if _, err := fc.Health(ctx); !errors.Is(err, clienttest.Unavailable) {
+		t.Fatalf("probe: %v, want unavailable", err)
+	}
+	if _, err := fc.Health(ctx); !errors.Is(err, client.ErrCircuitOpen) {
+		t.Fatalf("Health after a failed probe: %v, want ErrCircuitOpen", err)
+	}
+
+	// A successful probe closes it again
+	time.Sleep(60 * time.Millisecond)
+	for range 3 {
+		if _, err := fc.Health(ctx); err != nil {
+			t.Fatalf("Health after the cooldown: %v", err)
+		}
+	}
+	if n := grpc.Calls("health"); n != 6 {
+		t.Errorf("%d calls reached the transport, want 6", n)
+	}
+	if n := rest.Calls("health"); n != 0 {
+		t.Errorf("%d REST calls in grpc_only mode, want 0", n)
+	}
+}
@@ -0,0 +1,1004 @@
+package client
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials/insecure"
+
+	pb "github.com/tradik/mddb/services/mddbd/proto"
+)
+
+// importChunkSize is the size of the data messages of an Import stream
+const importChunkSize = 256 << 10
+
+// GRPCClient implements Client via gRPC/Protobuf API.
+type GRPCClient struct {
+	conn    *grpc.ClientConn
+	client  pb.MDDBClient
+	timeout time.Duration
+	creds   tokenCredentials
+}
+
+// NewGRPCClient creates new gRPC client. The connection is plaintext unless opts
+// override it, e.g. with grpc.WithTransportCredentials(credentials.NewTLS(cfg)).
+func NewGRPCClient(address string, timeout time.Duration, opts ...grpc.DialOption) (*GRPCClient, error) {
+	c := &GRPCClient{timeout: timeout}
+	opts = append([]grpc.DialOption{
+		grpc.WithTransportCredentials(insecure.NewCredentials()),
+		grpc.WithPerRPCCredentials(&c.creds),
+	}, opts...)
+	conn, err := grpc.NewClient(address, opts...)
+	if err != nil {
+		return nil, fmt.Errorf("create grpc client: %w", fromGRPC(err))
+	}
+
+	c.conn = conn
+	c.client = pb.NewMDDBClient(conn)
+	return c, nil
+}
+
+// SetToken sets the API key or JWT sent as a bearer token with every call. Call it before
+// the first call.
+func (c *GRPCClient) SetToken(token string) {
+	c.creds.token = token
+}
+
+// tokenCredentials sends a bearer token in the call metadata
+type tokenCredentials struct {
+	token string
+}
+
+func (t *tokenCredentials) GetRequestMetadata(ctx context.Context, uri ...string) (map[string]string, error) {
+	if t.token == "" {
+		return nil, nil
+	}
+	return map[string]string{"authorization": "Bearer " + t.token}, nil
+}
+
+// RequireTransportSecurity is false so tokens also work over plaintext connections
+func (t *tokenCredentials) RequireTransportSecurity() bool {
+	return false
+}
+
+// withTimeout bounds a unary call by the configured timeout (the context deadline still wins if earlier)
+func (c *GRPCClient) withTimeout(ctx context.Context) (context.Context, context.CancelFunc) {
+	if c.timeout <= 0 {
+		return ctx, func() {}
+	}
+	return context.WithTimeout(ctx, c.timeout)
+}
+
+func (c *GRPCClient) Health(ctx context.Context) (*Health, error) {
+	ctx, cancel := c.withTimeout(ctx)
+	defer cancel()
+
+	resp, err := c.client.Health(ctx, &pb.HealthRequest{})
+	if err != nil {
+		return nil, fmt.Errorf("health: %w", fromGRPC(err))
+	}
+	return &Health{Status: resp.Status, Mode: resp.Mode}, nil
+}
+
+func (c *GRPCClient) Stats(ctx context.Context) (*Stats, error) {
+	ctx, cancel := c.withTimeout(ctx)
+	defer cancel()
+
+	resp, err := c.client.Stats(ctx, &pb.StatsRequest{})
+	if err != nil {
+		return nil, fmt.Errorf("stats: %w", fromGRPC(err))
+	}
+
+	collections := make([]CollectionStats, len(resp.Collections))
+	for i, col := range resp.Collections {
+		collections[i] = CollectionStats{
+			Name:           col.Name,
+			DocumentCount:  int(col.DocumentCount),
+			RevisionCount:  int(col.RevisionCount),
+			MetaIndexCount: int(col.MetaIndexCount),
+		}
+	}
+
+	return &Stats{
+		DatabasePath:     resp.DatabasePath,
+		DatabaseSize:     resp.DatabaseSize,
+		Mode:             resp.Mode,
+		Collections:      collections,
+		TotalDocuments:   int(resp.TotalDocuments),
+		TotalRevisions:   int(resp.TotalRevisions),
+		TotalMetaIndices: int(resp.TotalMetaIndices),
+		Cache: CacheStats{
+			Hits:      resp.GetCache().GetHits(),
+			Misses:    resp.GetCache().GetMisses(),
+			Evictions: resp.GetCache().GetEvictions(),
+			Rejected:  resp.GetCache().GetRejected(),
+			Entries:   int(resp.GetCache().GetEntries()),
+			Bytes:     resp.GetCache().GetBytes(),
+			Budget:    resp.GetCache().GetBudget(),
+		},
+	}, nil
+}
+
+func (c *GRPCClient) Add(ctx context.Context, req *AddRequest) (*Document, error) {
+	ctx, cancel := c.withTimeout(ctx)
+	defer cancel()
+
+	pbReq := &pb.AddRequest{
+		Collection:   req.Collection,
+		Key:          req.Key,
+		Lang:         req.Lang,
+		Meta:         convertMetaToProto(req.Meta),
+		ContentMd:    req.ContentMD,
+		SaveRevision: req.SaveRevision,
+	}
+
+	doc, err := c.client.Add(ctx, pbReq)
+	if err != nil {
+		return nil, fmt.Errorf("add: %w", fromGRPC(err))
+	}
+
+	return convertDocumentFromProto(doc), nil
+}
+
+func (c *GRPCClient) Patch(ctx context.Context, req *PatchRequest) (*Document, error) {
+	ctx, cancel := c.withTimeout(ctx)
+	defer cancel()
+
+	doc, err := c.client.Patch(ctx, convertPatchToProto(req))
+	if err != nil {
+		return nil, fmt.Errorf("patch: %w", fromGRPC(err))
+	}
+
+	return convertDocumentFromProto(doc), nil
+}
+
+func (c *GRPCClient) Transaction(ctx context.Context, req *TransactionRequest) (*TransactionResponse, error) {
+	ctx, cancel := c.withTimeout(ctx)
+	defer cancel()
+
+	ops := make([]*pb.TxOp, len(req.Ops))
+	for i, op := range req.Ops {
+		ops[i] = &pb.TxOp{}
+		switch {
+		case op.Add != nil:
+			ops[i].Op = &pb.TxOp_Add{Add: &pb.AddRequest{
+				Collection:   op.Add.Collection,
+				Key:          op.Add.Key,
+				Lang:         op.Add.Lang,
+				Meta:         convertMetaToProto(op.Add.Meta),
+				ContentMd:    op.Add.ContentMD,
+				SaveRevision: op.Add.SaveRevision,
+			}}
+		case op.Patch != nil:
+			ops[i].Op = &pb.TxOp_Patch{Patch: convertPatchToProto(op.Patch)}
+		case op.Delete != nil:
+			ops[i].Op = &pb.TxOp_Delete{Delete: &pb.DeleteRequest{Collection: op.Delete.Collection, Key: op.Delete.Key, Lang: op.Delete.Lang}}
+		}
+		if pc := op.Precondition; pc != nil {
+			ops[i].Precondition = &pb.Precondition{Exists: pc.Exists, IfMatch: pc.IfMatch}
+		}
+	}
+
+	resp, err := c.client.Transaction(ctx, &pb.TransactionRequest{Ops: ops})
+	if err != nil {
+		return nil, fmt.Errorf("transaction: %w", fromGRPC(err))
+	}
+
+	out := &TransactionResponse{Results: make([]TxResult, len(resp.Results))}
+	for i, r := range resp.Results {
+		out.Results[i] = TxResult{Op: r.Op, Collection: r.Collection, Key: r.Key, Lang: r.Lang, Created: r.Created}
+		if r.Document != nil {
+			out.Results[i].Document = convertDocumentFromProto(r.Document)
+		}
+	}
+	return out, nil
+}
+
+func (c *GRPCClient) AddBatch(ctx context.Context, req *AddBatchRequest) (*AddBatchResponse, error) {
+	ctx, cancel := c.withTimeout(ctx)
+	defer cancel()
+
+	docs := make([]*pb.BatchDocument, len(req.Documents))
+	for i, d := range req.Documents {
+		docs[i] = &pb.BatchDocument{
+			Key:          d.Key,
+			Lang:         d.Lang,
+			Meta:         convertMetaToProto(d.Meta),
+			ContentMd:    d.ContentMD,
+			SaveRevision: d.SaveRevision,
+		}
+	}
+
+	pbReq := &pb.AddBatchRequest{
+		Collection: req.Collection,
+		Documents:  docs,
+	}
+
+	resp, err := c.client.AddBatch(ctx, pbReq)
+	if err != nil {
+		return nil, fmt.Errorf("add batch: %w", fromGRPC(err))
+	}
+
+	return &AddBatchResponse{
+		Added:   int(resp.Added),
+		Updated: int(resp.Updated),
+		Failed:  int(resp.Failed),
+		Errors:  resp.Errors,
+	}, nil
+}
+
+func (c *GRPCClient) UpdateBatch(ctx context.Context, req *UpdateBatchRequest) (*UpdateBatchResponse, error) {
+	ctx, cancel := c.withTimeout(ctx)
+	defer cancel()
+
+	docs := make([]*pb.UpdateDocument, len(req.Documents))
+	for i, d := range req.Documents {
+		docs[i] = &pb.UpdateDocument{
+			Key:          d.Key,
+			Lang:         d.Lang,
+			Meta:         convertMetaToProto(d.Meta),
+			ContentMd:    d.ContentMD,
+			SaveRevision: d.SaveRevision,
+		}
+	}
+
+	pbReq := &pb.UpdateBatchRequest{
+		Collection: req.Collection,
+		Documents:  docs,
+	}
+
+	resp, err := c.client.UpdateBatch(ctx, pbReq)
+	if err != nil {
+		return nil, fmt.Errorf("update batch: %w", fromGRPC(err))
+	}
+
+	return &UpdateBatchResponse{
+		Updated:  int(resp.Updated),
+		NotFound: int(resp.NotFound),
+		Failed:   int(resp.Failed),
+		Errors:   resp.Errors,
+	}, nil
+}
+
+func (c *GRPCClient) DeleteBatch(ctx context.Context, req *DeleteBatchRequest) (*DeleteBatchResponse, error) {
+	ctx, cancel := c.withTimeout(ctx)
+	defer cancel()
+
+	docs := make([]*pb.DeleteDocument, len(req.Documents))
+	for i, d := range req.Documents {
+		docs[i] = &pb.DeleteDocument{
+			Key:  d.Key,
+			Lang: d.Lang,
+		}
+	}
+
+	pbReq := &pb.DeleteBatchRequest{
+		Collection: req.Collection,
+		Documents:  docs,
+	}
+
+	resp, err := c.client.DeleteBatch(ctx, pbReq)
+	if err != nil {
+		return nil, fmt.Errorf("delete batch: %w", fromGRPC(err))
+	}
+
+	return &DeleteBatchResponse{
+		Deleted:  int(resp.Deleted),
+		NotFound: int(resp.NotFound),
+		Failed:   int(resp.Failed),
+		Errors:   resp.Errors,
+	}, nil
+}
+
+func (c *GRPCClient) Get(ctx context.Context, req *GetRequest) (*Document, error) {
+	ctx, cancel := c.withTimeout(ctx)
+	defer cancel()
+
+	pbReq := &pb.GetRequest{
+		Collection: req.Collection,
+		Key:        req.Key,
+		Lang:       req.Lang,
+		Env:        req.Env,
+	}
+
+	doc, err := c.client.Get(ctx, pbReq)
+	if err != nil {
+		return nil, fmt.Errorf("get: %w", fromGRPC(err))
+	}
+
+	return convertDocumentFromProto(doc), nil
+}
+
+func (c *GRPCClient) Search(ctx context.Context, req *SearchRequest) (*SearchResponse, error) {
+	ctx, cancel := c.withTimeout(ctx)
+	defer cancel()
+
+	pbReq := &pb.SearchRequest{
+		Collection:  req.Collection,
+		FilterMeta:  convertMetaToProto(req.FilterMeta),
+		Sort:        req.Sort,
+		Asc:         req.Asc,
+		Limit:       int32(req.Limit),
+		Offset:      int32(req.Offset),
+		Consistency: req.Consistency,
+	}
+
+	resp, err := c.client.Search(ctx, pbReq)
+	if err != nil {
+		return nil, fmt.Errorf("search: %w", fromGRPC(err))
+	}
+
+	docs := make([]Document, len(resp.Documents))
+	for i, d := range resp.Documents {
+		docs[i] = *convertDocumentFromProto(d)
+	}
+
+	return &SearchResponse{
+		Documents: docs,
+		Total:     int(resp.Total),
+	}, nil
+}
+
+func (c *GRPCClient) Delete(ctx context.Context, req *DeleteRequest) error {
+	ctx, cancel := c.withTimeout(ctx)
+	defer cancel()
+
+	_, err := c.client.Delete(ctx, &pb.DeleteRequest{
+		Collection: req.Collection,
+		Key:        req.Key,
+		Lang:       req.Lang,
+	})
+	if err != nil {
+		return fmt.Errorf("delete: %w", fromGRPC(err))
+	}
+	return nil
+}
+
+func (c *GRPCClient) DeleteCollection(ctx context.Context, req *DeleteCollectionRequest) (*DeleteCollectionResponse, error) {
+	ctx, cancel := c.withTimeout(ctx)
+	defer cancel()
+
+	resp, err := c.client.DeleteCollection(ctx, &pb.DeleteCollectionRequest{Collection: req.Collection})
+	if err != nil {
+		return nil, fmt.Errorf("delete collection: %w", fromGRPC(err))
+	}
+	return &DeleteCollectionResponse{Deleted: int(resp.DeletedCount)}, nil
+}
+
+func (c *GRPCClient) Export(ctx context.Context, req *ExportRequest) (io.ReadCloser, error) {
+	pbReq := &pb.ExportRequest{
+		Collection: req.Collection,
+		FilterMeta: convertMetaToProto(req.FilterMeta),
+		Format:     req.Format,
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	stream, err := c.client.Export(ctx, pbReq)
+	if err != nil {
+		cancel()
+		return nil, fmt.Errorf("export: %w", fromGRPC(err))
+	}
+
+	// Connection and request errors only show up on the first Recv; read it here so they
+	// are returned (and can be retried) instead of surfacing later from Read
+	first, err := stream.Recv()
+	if err != nil && err != io.EOF {
+		cancel()
+		return nil, fmt.Errorf("export: %w", fromGRPC(err))
+	}
+
+	pr, pw := io.Pipe()
+
+	go func() {
+		defer func() {
+			_ = pw.Close()
+		}()
+		for chunk := first; chunk != nil; {
+			if _, err := pw.Write(chunk.Data); err != nil {
+				return
+			}
+			next, err := stream.Recv()
+			if err == io.EOF {
+				return
+			}
+			if err != nil {
+				_ = pw.CloseWithError(fmt.Errorf("recv chunk: %w", fromGRPC(err)))
+				return
+			}
+			chunk = next
+		}
+	}()
+
+	return &streamReader{PipeReader: pr, cancel: cancel}, nil
+}
+
+// streamReader cancels the underlying stream when the reader is closed early
+type streamReader struct {
+	*io.PipeReader
+	cancel context.CancelFunc
+}
+
+func (r *streamReader) Close() error {
+	r.cancel()
+	return r.PipeReader.Close()
+}
+
+// Import streams data in chunks after an options header
+func (c *GRPCClient) Import(ctx context.Context, req *ImportRequest, data io.Reader) (*ImportResponse, error) {
+	stream, err := c.client.Import(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("import: %w", fromGRPC(err))
+	}
+	opts := &pb.ImportOptions{
+		Collection:   req.Collection,
+		Format:       req.Format,
+		OnConflict:   req.OnConflict,
+		DryRun:       req.DryRun,
+		Lang:         req.Lang,
+		ChunkSize:    int32(req.ChunkSize),
+		SaveRevision: req.SaveRevision,
+	}
+	if err := stream.Send(&pb.ImportChunk{Options: opts}); err != nil {
+		_, err = stream.CloseAndRecv()
+		return nil, fmt.Errorf("import: %w", fromGRPC(err))
+	}
+
+	buf := make([]byte, importChunkSize)
+	for {
+		n, rerr := data.Read(buf)
+		if n > 0 {
+			if err := stream.Send(&pb.ImportChunk{Data: buf[:n]}); err != nil {
+				// The server aborted; its status is returned by CloseAndRecv
+				break
+			}
+		}
+		if rerr == io.EOF {
+			break
+		}
+		if rerr != nil {
+			return nil, fmt.Errorf("import: read data: %w", rerr)
+		}
+	}
+
+	resp, err := stream.CloseAndRecv()
+	if err != nil {
+		return nil, fmt.Errorf("import: %w", fromGRPC(err))
+	}
+	return &ImportResponse{
+		Total:   int(resp.Total),
+		Added:   int(resp.Added),
+		Updated: int(resp.Updated),
+		Skipped: int(resp.Skipped),
+		Failed:  int(resp.Failed),
+		Chunks:  int(resp.Chunks),
+		Errors:  resp.Errors,
+		DryRun:  resp.DryRun,
+	}, nil
+}
+
+func (c *GRPCClient) Backup(ctx context.Context, req *BackupRequest) (*BackupResponse, error) {
+	ctx, cancel := c.withTimeout(ctx)
+	defer cancel()
+
+	pbReq := &pb.BackupRequest{To: req.To, Compression: req.Compression}
+	resp, err := c.client.Backup(ctx, pbReq)
+	if err != nil {
+		return nil, fmt.Errorf("backup: %w", fromGRPC(err))
+	}
+	return &BackupResponse{Backup: resp.Backup, Manifest: backupManifestFromProto(resp.Manifest)}, nil
+}
+
+func (c *GRPCClient) StreamBackup(ctx context.Context, req *StreamBackupRequest, w io.Writer) (*BackupManifest, error) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	stream, err := c.client.StreamBackup(ctx, &pb.StreamBackupRequest{Compression: req.Compression})
+	if err != nil {
+		return nil, fmt.Errorf("stream backup: %w", fromGRPC(err))
+	}
+
+	v := newBackupVerifier(w)
+	var m *BackupManifest
+	for {
+		chunk, err := stream.Recv()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("stream backup: %w", fromGRPC(err))
+		}
+		if _, err := v.Write(chunk.Data); err != nil {
+			return nil, fmt.Errorf("stream backup: %w", err)
+		}
+		if chunk.Manifest != nil {
+			m = backupManifestFromProto(chunk.Manifest)
+		}
+	}
+	if err := v.check(m); err != nil {
+		return nil, fmt.Errorf("stream backup: %w", err)
+	}
+	return m, nil
+}
+
+func backupManifestFromProto(m *pb.BackupManifest) *BackupManifest {
+	if m == nil {
+		return nil
+	}
+	return &BackupManifest{
+		Format:      m.Format,
+		Compression: m.Compression,
+		CreatedAt:   m.CreatedAt,
+		TxID:        m.TxId,
+		DBSize:      m.DbSize,
+		Size:        m.Size,
+		SHA256:      m.Sha256,
+	}
+}
+
+func (c *GRPCClient) Restore(ctx context.Context, req *RestoreRequest) (*RestoreResponse, error) {
+	ctx, cancel := c.withTimeout(ctx)
+	defer cancel()
+
+	pbReq := &pb.RestoreRequest{From: req.From, Sha256: req.SHA256, Rollback: req.Rollback}
+	if !req.At.IsZero() {
+		pbReq.At = req.At.Format(time.RFC3339Nano)
+	}
+	resp, err := c.client.Restore(ctx, pbReq)
+	if err != nil {
+		return nil, fmt.Errorf("restore: %w", fromGRPC(err))
+	}
+	return restoreResponseFromProto(resp), nil
+}
+
+func (c *GRPCClient) StreamRestore(ctx context.Context, req *StreamRestoreRequest, data io.Reader) (*RestoreResponse, error) {
+	stream, err := c.client.StreamRestore(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("stream restore: %w", fromGRPC(err))
+	}
+	chunk := &pb.RestoreChunk{Sha256: req.SHA256}
+	buf := make([]byte, importChunkSize)
+	for {
+		n, rerr := data.Read(buf)
+		if n > 0 {
+			chunk.Data = buf[:n]
+			if err := stream.Send(chunk); err != nil {
+				// The server aborted; its status is returned by CloseAndRecv
+				break
+			}
+			chunk = &pb.RestoreChunk{}
+		}
+		if rerr == io.EOF {
+			break
+		}
+		if rerr != nil {
+			return nil, fmt.Errorf("stream restore: read data: %w", rerr)
+		}
+	}
+
+	resp, err := stream.CloseAndRecv()
+	if err != nil {
+		return nil, fmt.Errorf("stream restore: %w", fromGRPC(err))
+	}
+	return restoreResponseFromProto(resp), nil
+}
+
+func restoreResponseFromProto(resp *pb.RestoreResponse) *RestoreResponse {
+	return &RestoreResponse{
+		Restored:    resp.Restored,
+		Previous:    resp.Previous,
+		Compression: resp.Compression,
+		SHA256:      resp.Sha256,
+		Size:        resp.Size,
+		TxID:        resp.TxId,
+		Replayed:    resp.Replayed,
+		DurationMs:  resp.DurationMs,
+	}
+}
+
+func (c *GRPCClient) Dump(ctx context.Context, req *DumpRequest, w io.Writer) (*DumpSummary, error) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	stream, err := c.client.Dump(ctx, &pb.DumpRequest{
+		Collections:   req.Collections,
+		Compression:   req.Compression,
+		SkipRevisions: req.SkipRevisions,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("dump: %w", fromGRPC(err))
+	}
+
+	var sum *DumpSummary
+	for {
+		chunk, err := stream.Recv()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("dump: %w", fromGRPC(err))
+		}
+		if _, err := w.Write(chunk.Data); err != nil {
+			return nil, fmt.Errorf("dump: %w", err)
+		}
+		if m := chunk.Manifest; m != nil {
+			sum = &DumpSummary{Format: m.Format, Version: int(m.Version), CreatedAt: m.CreatedAt, TxID: m.TxId}
+			for _, c := range m.Collections {
+				sum.Collections = append(sum.Collections, c.Name)
+				sum.Documents += c.Documents
+				sum.Revisions += c.Revisions
+			}
+		}
+	}
+	if sum == nil {
+		return nil, fmt.Errorf("dump: %w", ErrDumpIncomplete)
+	}
+	return sum, nil
+}
+
+func (c *GRPCClient) Load(ctx context.Context, req *LoadRequest, data io.Reader) (*LoadResponse, error) {
+	stream, err := c.client.Load(ctx)
+	if err != nil {
+		return nil, fmt.Errorf("load: %w", fromGRPC(err))
+	}
+	chunk := &pb.LoadChunk{Collections: req.Collections, Rename: req.Rename, Replace: req.Replace}
+	buf := make([]byte, importChunkSize)
+	for {
+		n, rerr := data.Read(buf)
+		if n > 0 {
+			chunk.Data = buf[:n]
+			if err := stream.Send(chunk); err != nil {
+				// The server aborted; its status is returned by CloseAndRecv
+				break
+			}
+			chunk = &pb.LoadChunk{}
+		}
+		if rerr == io.EOF {
+			break
+		}
+		if rerr != nil {
+			return nil, fmt.Errorf("load: read data: %w", rerr)
+		}
+	}
+
+	resp, err := stream.CloseAndRecv()
+	if err != nil {
+		return nil, fmt.Errorf("load: %w", fromGRPC(err))
+	}
+	out := &LoadResponse{Format: resp.Format, Version: int(resp.Version), DurationMs: resp.DurationMs}
+	for _, c := range resp.Collections {
+		out.Collections = append(out.Collections, LoadedCollection{
+			From:      c.From,
+			Name:      c.Name,
+			Documents: c.Documents,
+			Revisions: c.Revisions,
+			Replaced:  c.Replaced,
+		})
+	}
+	return out, nil
+}
+
+func (c *GRPCClient) Truncate(ctx context.Context, req *TruncateRequest) (*TruncateResponse, error) {
+	ctx, cancel := c.withTimeout(ctx)
+	defer cancel()
+
+	pbReq := &pb.TruncateRequest{
+		Collection: req.Collection,
+		KeepRevs:   int32(req.KeepRevs),
+		DropCache:  req.DropCache,
+	}
+	resp, err := c.client.Truncate(ctx, pbReq)
+	if err != nil {
+		return nil, fmt.Errorf("truncate: %w", fromGRPC(err))
+	}
+	return &TruncateResponse{Status: resp.Status}, nil
+}
+
+func (c *GRPCClient) Fsck(ctx context.Context, req *FsckRequest) (*FsckReport, error) {
+	ctx, cancel := c.withTimeout(ctx)
+	defer cancel()
+
+	resp, err := c.client.Fsck(ctx, &pb.FsckRequest{Collection: req.Collection, Repair: req.Repair})
+	if err != nil {
+		return nil, fmt.Errorf("fsck: %w", fromGRPC(err))
+	}
+	out := &FsckReport{
+		Documents:   int(resp.Documents),
+		KeyEntries:  int(resp.KeyEntries),
+		MetaIndices: int(resp.MetaIndices),
+		Revisions:   int(resp.Revisions),
+		Problems:    make(map[string]int, len(resp.Problems)),
+		Issues:      make([]FsckIssue, len(resp.Issues)),
+		Repaired:    int(resp.Repaired),
+	}
+	for kind, n := range resp.Problems {
+		out.Problems[kind] = int(n)
+	}
+	for i, is := range resp.Issues {
+		out.Issues[i] = FsckIssue{Kind: is.Kind, Collection: is.Collection, Key: is.Key, Repaired: is.Repaired}
+	}
+	return out, nil
+}
+
+func (c *GRPCClient) Compact(ctx context.Context, req *CompactRequest) (*CompactResponse, error) {
+	ctx, cancel := c.withTimeout(ctx)
+	defer cancel()
+
+	resp, err := c.client.Compact(ctx, &pb.CompactRequest{MinFreeRatio: req.MinFreeRatio})
+	if err != nil {
+		return nil, fmt.Errorf("compact: %w", fromGRPC(err))
+	}
+	return &CompactResponse{
+		Compacted:  resp.Compacted,
+		SizeBefore: resp.SizeBefore,
+		SizeAfter:  resp.SizeAfter,
+		FreeBefore: resp.FreeBefore,
+		Reclaimed:  resp.Reclaimed,
+		DurationMs: resp.DurationMs,
+	}, nil
+}
+
+func (c *GRPCClient) ListCollections(ctx context.Context) ([]CollectionInfo, error) {
+	ctx, cancel := c.withTimeout(ctx)
+	defer cancel()
+
+	resp, err := c.client.ListCollections(ctx, &pb.ListCollectionsRequest{})
+	if err != nil {
+		return nil, fmt.Errorf("list collections: %w", fromGRPC(err))
+	}
+	out := make([]CollectionInfo, len(resp.Collections))
+	for i, ci := range resp.Collections {
+		out[i] = *convertCollectionFromProto(ci)
+	}
+	return out, nil
+}
+
+func (c *GRPCClient) CreateCollection(ctx context.Context, req *CollectionInfo) (*CollectionInfo, error) {
+	ctx, cancel := c.withTimeout(ctx)
+	defer cancel()
+
+	resp, err := c.client.CreateCollection(ctx, convertCollectionToProto(req))
+	if err != nil {
+		return nil, fmt.Errorf("create collection: %w", fromGRPC(err))
+	}
+	return convertCollectionFromProto(resp), nil
+}
+
+func (c *GRPCClient) UpdateCollection(ctx context.Context, req *CollectionInfo) (*CollectionInfo, error) {
+	ctx, cancel := c.withTimeout(ctx)
+	defer cancel()
+
+	resp, err := c.client.UpdateCollection(ctx, convertCollectionToProto(req))
+	if err != nil {
+		return nil, fmt.Errorf("update collection: %w", fromGRPC(err))
+	}
+	return convertCollectionFromProto(resp), nil
+}
+
+func (c *GRPCClient) DescribeCollection(ctx context.Context, name string) (*CollectionDescription, error) {
+	ctx, cancel := c.withTimeout(ctx)
+	defer cancel()
+
+	resp, err := c.client.DescribeCollection(ctx, &pb.CollectionNameRequest{Name: name})
+	if err != nil {
+		return nil, fmt.Errorf("describe collection: %w", fromGRPC(err))
+	}
+	return &CollectionDescription{
+		CollectionInfo: *convertCollectionFromProto(resp.Info),
+		DocumentCount:  int(resp.DocumentCount),
+		RevisionCount:  int(resp.RevisionCount),
+		HasSchema:      resp.HasSchema,
+	}, nil
+}
+
+func (c *GRPCClient) RenameCollection(ctx context.Context, req *CopyCollectionRequest) (*CopyCollectionResponse, error) {
+	ctx, cancel := c.withTimeout(ctx)
+	defer cancel()
+
+	resp, err := c.client.RenameCollection(ctx, &pb.CopyCollectionRequest{Name: req.Name, NewName: req.NewName})
+	if err != nil {
+		return nil, fmt.Errorf("rename collection: %w", fromGRPC(err))
+	}
+	return &CopyCollectionResponse{Status: resp.Status, Collection: resp.Collection, Documents: int(resp.Documents)}, nil
+}
+
+func (c *GRPCClient) CloneCollection(ctx context.Context, req *CopyCollectionRequest) (*CopyCollectionResponse, error) {
+	ctx, cancel := c.withTimeout(ctx)
+	defer cancel()
+
+	resp, err := c.client.CloneCollection(ctx, &pb.CopyCollectionRequest{Name: req.Name, NewName: req.NewName})
+	if err != nil {
+		return nil, fmt.Errorf("clone collection: %w", fromGRPC(err))
+	}
+	return &CopyCollectionResponse{Status: resp.Status, Collection: resp.Collection, Documents: int(resp.Documents)}, nil
+}
+
+func (c *GRPCClient) SetSchema(ctx context.Context, schema *CollectionSchema) (*CollectionSchema, error) {
+	ctx, cancel := c.withTimeout(ctx)
+	defer cancel()
+
+	resp, err := c.client.SetSchema(ctx, &pb.CollectionSchema{
+		Collection:     schema.Collection,
+		RequiredMeta:   schema.RequiredMeta,
+		OptionalMeta:   schema.OptionalMeta,
+		Enums:          convertMetaToProto(schema.Enums),
+		Patterns:       schema.Patterns,
+		MaxContentSize: int64(schema.MaxContentSize),
+		RequiredLangs:  schema.RequiredLangs,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("set schema: %w", fromGRPC(err))
+	}
+	return convertSchemaFromProto(resp), nil
+}
+
+func (c *GRPCClient) GetSchema(ctx context.Context, collection string) (*CollectionSchema, error) {
+	ctx, cancel := c.withTimeout(ctx)
+	defer cancel()
+
+	resp, err := c.client.GetSchema(ctx, &pb.SchemaRequest{Collection: collection})
+	if err != nil {
+		return nil, fmt.Errorf("get schema: %w", fromGRPC(err))
+	}
+	return convertSchemaFromProto(resp), nil
+}
+
+func (c *GRPCClient) DeleteSchema(ctx context.Context, collection string) error {
+	ctx, cancel := c.withTimeout(ctx)
+	defer cancel()
+
+	if _, err := c.client.DeleteSchema(ctx, &pb.SchemaRequest{Collection: collection}); err != nil {
+		return fmt.Errorf("delete schema: %w", fromGRPC(err))
+	}
+	return nil
+}
+
+func (c *GRPCClient) WhoAmI(ctx context.Context) (*Principal, error) {
+	ctx, cancel := c.withTimeout(ctx)
+	defer cancel()
+
+	resp, err := c.client.WhoAmI(ctx, &pb.WhoAmIRequest{})
+	if err != nil {
+		return nil, fmt.Errorf("whoami: %w", fromGRPC(err))
+	}
+	return &Principal{Subject: resp.Subject, Method: resp.Method, Roles: resp.Roles}, nil
+}
+
+func (c *GRPCClient) CreateAPIKey(ctx context.Context, req *CreateAPIKeyRequest) (*APIKey, error) {
+	ctx, cancel := c.withTimeout(ctx)
+	defer cancel()
+
+	resp, err := c.client.CreateAPIKey(ctx, &pb.CreateAPIKeyRequest{Name: req.Name, Roles: req.Roles})
+	if err != nil {
+		return nil, fmt.Errorf("create api key: %w", fromGRPC(err))
+	}
+	k := convertAPIKeyFromProto(resp)
+	return &k, nil
+}
+
+func (c *GRPCClient) ListAPIKeys(ctx context.Context) ([]APIKey, error) {
+	ctx, cancel := c.withTimeout(ctx)
+	defer cancel()
+
+	resp, err := c.client.ListAPIKeys(ctx, &pb.ListAPIKeysRequest{})
+	if err != nil {
+		return nil, fmt.Errorf("list api keys: %w", fromGRPC(err))
+	}
+	out := make([]APIKey, len(resp.Keys))
+	for i, k := range resp.Keys {
+		out[i] = convertAPIKeyFromProto(k)
+	}
+	return out, nil
+}
+
+func (c *GRPCClient) DeleteAPIKey(ctx context.Context, id string) error {
+	ctx, cancel := c.withTimeout(ctx)
+	defer cancel()
+
+	if _, err := c.client.DeleteAPIKey(ctx, &pb.DeleteAPIKeyRequest{Id: id}); err != nil {
+		return fmt.Errorf("delete api key: %w", fromGRPC(err))
+	}
+	return nil
+}
+
+func (c *GRPCClient) Close() error {
+	return c.conn.Close()
+}
+
+// convertAPIKeyFromProto converts an API key from proto format.
+func convertAPIKeyFromProto(k *pb.APIKey) APIKey {
+	return APIKey{ID: k.Id, Name: k.Name, Roles: k.Roles, CreatedAt: time.Unix(k.CreatedAt, 0), Key: k.Key}
+}
+
+// convertMetaToProto converts meta from map[string][]string to proto format.
+func convertMetaToProto(meta map[string][]string) map[string]*pb.MetaValues {
+	if meta == nil {
+		return nil
+	}
+	result := make(map[string]*pb.MetaValues, len(meta))
+	for k, v := range meta {
+		result[k] = &pb.MetaValues{Values: v}
+	}
+	return result
+}
+
+// convertMetaFromProto converts meta from proto to map[string][]string.
+func convertMetaFromProto(meta map[string]*pb.MetaValues) map[string][]string {
+	if meta == nil {
+		return nil
+	}
+	result := make(map[string][]string, len(meta))
+	for k, v := range meta {
+		result[k] = v.Values
+	}
+	return result
+}
+
+// convertDocumentFromProto converts Document from proto to internal type.
+func convertDocumentFromProto(doc *pb.Document) *Document {
+	return &Document{
+		ID:        doc.Id,
+		Key:       doc.Key,
+		Lang:      doc.Lang,
+		Meta:      convertMetaFromProto(doc.Meta),
+		ContentMD: doc.ContentMd,
+		AddedAt:   time.Unix(doc.AddedAt, 0),
+		UpdatedAt: time.Unix(doc.UpdatedAt, 0),
+	}
+}
+
+// convertCollectionToProto converts CollectionInfo to proto; timestamps are server-managed.
+func convertCollectionToProto(ci *CollectionInfo) *pb.CollectionInfo {
+	return &pb.CollectionInfo{
+		Name:           ci.Name,
+		Description:    ci.Description,
+		DefaultLang:    ci.DefaultLang,
+		RevisionPolicy: ci.RevisionPolicy,
+		Compression:    ci.Compression,
+	}
+}
+
+// convertCollectionFromProto converts CollectionInfo from proto to internal type.
+func convertCollectionFromProto(ci *pb.CollectionInfo) *CollectionInfo {
+	if ci == nil {
+		return &CollectionInfo{}
+	}
+	return &CollectionInfo{
+		Name:           ci.Name,
+		Description:    ci.Description,
+		DefaultLang:    ci.DefaultLang,
+		RevisionPolicy: ci.RevisionPolicy,
+		Compression:    ci.Compression,
+		CreatedAt:      time.Unix(ci.CreatedAt, 0),
+		UpdatedAt:      time.Unix(ci.UpdatedAt, 0),
+	}
+}
+
+// convertSchemaFromProto converts CollectionSchema from proto to internal type.
+func convertSchemaFromProto(s *pb.CollectionSchema) *CollectionSchema {
+	return &CollectionSchema{
+		Collection:     s.Collection,
+		RequiredMeta:   s.RequiredMeta,
+		OptionalMeta:   s.OptionalMeta,
+		Enums:          convertMetaFromProto(s.Enums),
+		Patterns:       s.Patterns,
+		MaxContentSize: int(s.MaxContentSize),
+		RequiredLangs:  s.RequiredLangs,
+	}
+}
+
+func convertPatchToProto(req *PatchRequest) *pb.PatchRequest {
+	ops := make([]*pb.MetaPatch, len(req.Meta))
+	for i, p := range req.Meta {
+		ops[i] = &pb.MetaPatch{Op: p.Op, Key: p.Key, Values: p.Values}
+	}
+	return &pb.PatchRequest{
+		Collection:   req.Collection,
+		Key:          req.Key,
+		Lang:         req.Lang,
+		Meta:         ops,
+		ContentMd:    req.ContentMD,
+		AppendMd:     req.AppendMD,
+		SaveRevision: req.SaveRevision,
+	}
+}
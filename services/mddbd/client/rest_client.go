@@ -0,0 +1,819 @@
+package client
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// RESTClient implements Client via HTTP/JSON API.
+type RESTClient struct {
+	baseURL string
+	client  *http.Client
+	timeout time.Duration
+	token   string
+}
+
+// NewRESTClient creates new REST client. The timeout applies per unary request; exports
+// and imports are only bounded by their context, so large transfers are not cut off.
+func NewRESTClient(baseURL string, timeout time.Duration) *RESTClient {
+	return &RESTClient{
+		baseURL: baseURL,
+		client:  &http.Client{},
+		timeout: timeout,
+	}
+}
+
+// SetToken sets the API key or JWT sent as a bearer token with every request.
+func (c *RESTClient) SetToken(token string) {
+	c.token = token
+}
+
+// SetTLS sets the TLS config of HTTPS requests, e.g. a private CA or a client certificate.
+func (c *RESTClient) SetTLS(cfg *tls.Config) {
+	t := http.DefaultTransport.(*http.Transport).Clone()
+	t.TLSClientConfig = cfg
+	c.client = &http.Client{Transport: t}
+}
+
+// restDocument is the document as encoded by the HTTP API
+type restDocument struct {
+	ID        string              `json:"id"`
+	Key       string              `json:"key"`
+	Lang      string              `json:"lang"`
+	Meta      map[string][]string `json:"meta"`
+	ContentMD string              `json:"contentMd"`
+	AddedAt   int64               `json:"addedAt"`
+	UpdatedAt int64               `json:"updatedAt"`
+}
+
+func (d *restDocument) document() *Document {
+	return &Document{
+		ID:        d.ID,
+		Key:       d.Key,
+		Lang:      d.Lang,
+		Meta:      d.Meta,
+		ContentMD: d.ContentMD,
+		AddedAt:   time.Unix(d.AddedAt, 0),
+		UpdatedAt: time.Unix(d.UpdatedAt, 0),
+	}
+}
+
+type restCollection struct {
+	Name           string `json:"name"`
+	Description    string `json:"description"`
+	DefaultLang    string `json:"defaultLang"`
+	RevisionPolicy string `json:"revisionPolicy"`
+	Compression    string `json:"compression"`
+	CreatedAt      int64  `json:"createdAt"`
+	UpdatedAt      int64  `json:"updatedAt"`
+}
+
+func (ci *restCollection) info() *CollectionInfo {
+	return &CollectionInfo{
+		Name:           ci.Name,
+		Description:    ci.Description,
+		DefaultLang:    ci.DefaultLang,
+		RevisionPolicy: ci.RevisionPolicy,
+		Compression:    ci.Compression,
+		CreatedAt:      time.Unix(ci.CreatedAt, 0),
+		UpdatedAt:      time.Unix(ci.UpdatedAt, 0),
+	}
+}
+
+type restSchema struct {
+	Collection     string              `json:"collection"`
+	RequiredMeta   []string            `json:"requiredMeta"`
+	OptionalMeta   []string            `json:"optionalMeta"`
+	Enums          map[string][]string `json:"enums"`
+	Patterns       map[string]string   `json:"patterns"`
+	MaxContentSize int                 `json:"maxContentSize"`
+	RequiredLangs  []string            `json:"requiredLangs"`
+}
+
+func (c *RESTClient) Health(ctx context.Context) (*Health, error) {
+	var h Health
+	if err := c.do(ctx, http.MethodGet, "/health", nil, &h); err != nil {
+		return nil, fmt.Errorf("health: %w", err)
+	}
+	return &h, nil
+}
+
+func (c *RESTClient) Stats(ctx context.Context) (*Stats, error) {
+	var s struct {
+		DatabasePath string `json:"databasePath"`
+		DatabaseSize int64  `json:"databaseSize"`
+		Mode         string `json:"mode"`
+		Collections  []struct {
+			Name           string `json:"name"`
+			DocumentCount  int    `json:"documentCount"`
+			RevisionCount  int    `json:"revisionCount"`
+			MetaIndexCount int    `json:"metaIndexCount"`
+		} `json:"collections"`
+		TotalDocuments   int        `json:"totalDocuments"`
+		TotalRevisions   int        `json:"totalRevisions"`
+		TotalMetaIndices int        `json:"totalMetaIndices"`
+		Cache            CacheStats `json:"cache"`
+	}
+	if err := c.do(ctx, http.MethodGet, "/v1/stats", nil, &s); err != nil {
+		return nil, fmt.Errorf("stats: %w", err)
+	}
+	out := &Stats{
+		DatabasePath:     s.DatabasePath,
+		DatabaseSize:     s.DatabaseSize,
+		Mode:             s.Mode,
+		Collections:      make([]CollectionStats, len(s.Collections)),
+		TotalDocuments:   s.TotalDocuments,
+		TotalRevisions:   s.TotalRevisions,
+		TotalMetaIndices: s.TotalMetaIndices,
+		Cache:            s.Cache,
+	}
+	for i, cs := range s.Collections {
+		out.Collections[i] = CollectionStats(cs)
+	}
+	return out, nil
+}
+
+func (c *RESTClient) Add(ctx context.Context, req *AddRequest) (*Document, error) {
+	body := map[string]any{
+		"collection": req.Collection,
+		"key":        req.Key,
+		"lang":       req.Lang,
+		"meta":       req.Meta,
+		"contentMd":  req.ContentMD,
+	}
+	var doc restDocument
+	if err := c.do(ctx, http.MethodPost, "/v1/add", body, &doc); err != nil {
+		return nil, fmt.Errorf("add: %w", err)
+	}
+	return doc.document(), nil
+}
+
+func (c *RESTClient) Patch(ctx context.Context, req *PatchRequest) (*Document, error) {
+	body := map[string]any{
+		"collection": req.Collection,
+		"key":        req.Key,
+		"lang":       req.Lang,
+		"meta":       req.Meta,
+		"contentMd":  req.ContentMD,
+		"appendMd":   req.AppendMD,
+	}
+	var doc restDocument
+	if err := c.do(ctx, http.MethodPost, "/v1/patch", body, &doc); err != nil {
+		return nil, fmt.Errorf("patch: %w", err)
+	}
+	return doc.document(), nil
+}
+
+func (c *RESTClient) Transaction(ctx context.Context, req *TransactionRequest) (*TransactionResponse, error) {
+	ops := make([]map[string]any, len(req.Ops))
+	for i, op := range req.Ops {
+		o := map[string]any{}
+		switch {
+		case op.Add != nil:
+			o["add"] = map[string]any{
+				"collection": op.Add.Collection,
+				"key":        op.Add.Key,
+				"lang":       op.Add.Lang,
+				"meta":       op.Add.Meta,
+				"contentMd":  op.Add.ContentMD,
+			}
+		case op.Patch != nil:
+			o["patch"] = map[string]any{
+				"collection": op.Patch.Collection,
+				"key":        op.Patch.Key,
+				"lang":       op.Patch.Lang,
+				"meta":       op.Patch.Meta,
+				"contentMd":  op.Patch.ContentMD,
+				"appendMd":   op.Patch.AppendMD,
+			}
+		case op.Delete != nil:
+			o["delete"] = op.Delete
+		}
+		if pc := op.Precondition; pc != nil {
+			o["precondition"] = map[string]any{"exists": pc.Exists, "ifMatch": pc.IfMatch}
+		}
+		ops[i] = o
+	}
+
+	var resp struct {
+		Results []struct {
+			Op         string        `json:"op"`
+			Collection string        `json:"collection"`
+			Key        string        `json:"key"`
+			Lang       string        `json:"lang"`
+			Doc        *restDocument `json:"doc"`
+			Created    bool          `json:"created"`
+		} `json:"results"`
+	}
+	if err := c.do(ctx, http.MethodPost, "/v1/transaction", map[string]any{"ops": ops}, &resp); err != nil {
+		return nil, fmt.Errorf("transaction: %w", err)
+	}
+	out := &TransactionResponse{Results: make([]TxResult, len(resp.Results))}
+	for i, r := range resp.Results {
+		out.Results[i] = TxResult{Op: r.Op, Collection: r.Collection, Key: r.Key, Lang: r.Lang, Created: r.Created}
+		if r.Doc != nil {
+			out.Results[i].Document = r.Doc.document()
+		}
+	}
+	return out, nil
+}
+
+// restBatchDocument is a batch document as encoded by the HTTP API
+type restBatchDocument struct {
+	Key          string              `json:"key"`
+	Lang         string              `json:"lang"`
+	Meta         map[string][]string `json:"meta"`
+	ContentMD    string              `json:"contentMd"`
+	SaveRevision bool                `json:"saveRevision"`
+}
+
+func (c *RESTClient) AddBatch(ctx context.Context, req *AddBatchRequest) (*AddBatchResponse, error) {
+	docs := make([]restBatchDocument, len(req.Documents))
+	for i, d := range req.Documents {
+		docs[i] = restBatchDocument(d)
+	}
+	body := map[string]any{"collection": req.Collection, "documents": docs}
+	var resp AddBatchResponse
+	if err := c.do(ctx, http.MethodPost, "/v1/add-batch", body, &resp); err != nil {
+		return nil, fmt.Errorf("add batch: %w", err)
+	}
+	return &resp, nil
+}
+
+func (c *RESTClient) UpdateBatch(ctx context.Context, req *UpdateBatchRequest) (*UpdateBatchResponse, error) {
+	docs := make([]restBatchDocument, len(req.Documents))
+	for i, d := range req.Documents {
+		docs[i] = restBatchDocument(d)
+	}
+	body := map[string]any{"collection": req.Collection, "documents": docs}
+	var resp UpdateBatchResponse
+	if err := c.do(ctx, http.MethodPost, "/v1/update-batch", body, &resp); err != nil {
+		return nil, fmt.Errorf("update batch: %w", err)
+	}
+	return &resp, nil
+}
+
+func (c *RESTClient) DeleteBatch(ctx context.Context, req *DeleteBatchRequest) (*DeleteBatchResponse, error) {
+	var resp DeleteBatchResponse
+	if err := c.do(ctx, http.MethodPost, "/v1/delete-batch", req, &resp); err != nil {
+		return nil, fmt.Errorf("delete batch: %w", err)
+	}
+	return &resp, nil
+}
+
+func (c *RESTClient) Get(ctx context.Context, req *GetRequest) (*Document, error) {
+	var doc restDocument
+	if err := c.do(ctx, http.MethodPost, "/v1/get", req, &doc); err != nil {
+		return nil, fmt.Errorf("get: %w", err)
+	}
+	return doc.document(), nil
+}
+
+func (c *RESTClient) Search(ctx context.Context, req *SearchRequest) (*SearchResponse, error) {
+	body := map[string]any{
+		"collection": req.Collection,
+		"filterMeta": req.FilterMeta,
+		"sort":       req.Sort,
+		"asc":        req.Asc,
+		"limit":      req.Limit,
+		"offset":     req.Offset,
+	}
+	if req.Consistency != "" {
+		body["consistency"] = req.Consistency
+	}
+	var docs []restDocument
+	resp, err := c.send(ctx, http.MethodPost, "/v1/search", body, &docs)
+	if err != nil {
+		return nil, fmt.Errorf("search: %w", err)
+	}
+	out := &SearchResponse{Documents: make([]Document, len(docs)), Total: len(docs)}
+	for i := range docs {
+		out.Documents[i] = *docs[i].document()
+	}
+	// Servers before X-Total-Count only report the page
+	if total, err := strconv.Atoi(resp.Header.Get("X-Total-Count")); err == nil {
+		out.Total = total
+	}
+	return out, nil
+}
+
+func (c *RESTClient) Delete(ctx context.Context, req *DeleteRequest) error {
+	if err := c.do(ctx, http.MethodPost, "/v1/delete", req, nil); err != nil {
+		return fmt.Errorf("delete: %w", err)
+	}
+	return nil
+}
+
+func (c *RESTClient) DeleteCollection(ctx context.Context, req *DeleteCollectionRequest) (*DeleteCollectionResponse, error) {
+	var resp struct {
+		DeletedCount int `json:"deletedCount"`
+	}
+	if err := c.do(ctx, http.MethodPost, "/v1/delete-collection", req, &resp); err != nil {
+		return nil, fmt.Errorf("delete collection: %w", err)
+	}
+	return &DeleteCollectionResponse{Deleted: resp.DeletedCount}, nil
+}
+
+func (c *RESTClient) Export(ctx context.Context, req *ExportRequest) (io.ReadCloser, error) {
+	body, err := json.Marshal(map[string]any{
+		"collection": req.Collection,
+		"filterMeta": req.FilterMeta,
+		"format":     req.Format,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("marshal export request: %w", err)
+	}
+
+	resp, err := c.stream(ctx, http.MethodPost, "/v1/export", "application/json", bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("export: %w", err)
+	}
+	return resp.Body, nil
+}
+
+func (c *RESTClient) Import(ctx context.Context, req *ImportRequest, data io.Reader) (*ImportResponse, error) {
+	q := url.Values{}
+	q.Set("collection", req.Collection)
+	q.Set("format", req.Format)
+	q.Set("onConflict", req.OnConflict)
+	q.Set("lang", req.Lang)
+	q.Set("dryRun", strconv.FormatBool(req.DryRun))
+	q.Set("saveRevision", strconv.FormatBool(req.SaveRevision))
+	if req.ChunkSize > 0 {
+		q.Set("chunkSize", strconv.Itoa(req.ChunkSize))
+	}
+
+	resp, err := c.stream(ctx, http.MethodPost, "/v1/import?"+q.Encode(), "application/octet-stream", data)
+	if err != nil {
+		return nil, fmt.Errorf("import: %w", err)
+	}
+	defer func() {
+		_ = resp.Body.Close()
+	}()
+
+	var ir struct {
+		ImportResponse
+		DryRun bool `json:"dryRun"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&ir); err != nil {
+		return nil, fmt.Errorf("import: decode response: %w", err)
+	}
+	ir.ImportResponse.DryRun = ir.DryRun
+	return &ir.ImportResponse, nil
+}
+
+func (c *RESTClient) Backup(ctx context.Context, req *BackupRequest) (*BackupResponse, error) {
+	q := url.Values{}
+	q.Set("to", req.To)
+	q.Set("compression", req.Compression)
+	var r struct {
+		Backup   string      `json:"backup"`
+		Manifest *restBackup `json:"manifest"`
+	}
+	if err := c.do(ctx, http.MethodGet, "/v1/backup?"+q.Encode(), nil, &r); err != nil {
+		return nil, fmt.Errorf("backup: %w", err)
+	}
+	out := &BackupResponse{Backup: r.Backup}
+	if r.Manifest != nil {
+		m := BackupManifest(*r.Manifest)
+		out.Manifest = &m
+	}
+	return out, nil
+}
+
+// restBackup is a backup manifest as the HTTP API encodes it
+type restBackup struct {
+	Format      string `json:"format"`
+	Compression string `json:"compression"`
+	CreatedAt   int64  `json:"createdAt"`
+	TxID        int64  `json:"txId"`
+	DBSize      int64  `json:"dbSize"`
+	Size        int64  `json:"size"`
+	SHA256      string `json:"sha256"`
+}
+
+// StreamBackup reads the manifest from the HTTP trailers that follow the body.
+func (c *RESTClient) StreamBackup(ctx context.Context, req *StreamBackupRequest, w io.Writer) (*BackupManifest, error) {
+	resp, err := c.stream(ctx, http.MethodGet, "/v1/backup/stream?compression="+url.QueryEscape(req.Compression), "", nil)
+	if err != nil {
+		return nil, fmt.Errorf("stream backup: %w", err)
+	}
+	defer func() {
+		_ = resp.Body.Close()
+	}()
+
+	v := newBackupVerifier(w)
+	if _, err := io.Copy(v, resp.Body); err != nil {
+		return nil, fmt.Errorf("stream backup: %w", err)
+	}
+	var m *BackupManifest
+	if sum := resp.Trailer.Get("X-Backup-Sha256"); sum != "" {
+		m = &BackupManifest{
+			Format:      resp.Header.Get("X-Backup-Format"),
+			Compression: resp.Header.Get("X-Backup-Compression"),
+			SHA256:      sum,
+		}
+		m.CreatedAt, _ = strconv.ParseInt(resp.Trailer.Get("X-Backup-Created-At"), 10, 64)
+		m.TxID, _ = strconv.ParseInt(resp.Trailer.Get("X-Backup-Tx-Id"), 10, 64)
+		m.DBSize, _ = strconv.ParseInt(resp.Trailer.Get("X-Backup-Db-Size"), 10, 64)
+		m.Size, _ = strconv.ParseInt(resp.Trailer.Get("X-Backup-Size"), 10, 64)
+	}
+	if err := v.check(m); err != nil {
+		return nil, fmt.Errorf("stream backup: %w", err)
+	}
+	return m, nil
+}
+
+func (c *RESTClient) Restore(ctx context.Context, req *RestoreRequest) (*RestoreResponse, error) {
+	body := map[string]any{
+		"from":     req.From,
+		"sha256":   req.SHA256,
+		"rollback": req.Rollback,
+	}
+	if !req.At.IsZero() {
+		body["at"] = req.At.Format(time.RFC3339Nano)
+	}
+	var rr restRestore
+	if err := c.do(ctx, http.MethodPost, "/v1/restore", body, &rr); err != nil {
+		return nil, fmt.Errorf("restore: %w", err)
+	}
+	out := RestoreResponse(rr)
+	return &out, nil
+}
+
+func (c *RESTClient) StreamRestore(ctx context.Context, req *StreamRestoreRequest, data io.Reader) (*RestoreResponse, error) {
+	resp, err := c.stream(ctx, http.MethodPost, "/v1/restore/upload?sha256="+url.QueryEscape(req.SHA256), "application/octet-stream", data)
+	if err != nil {
+		return nil, fmt.Errorf("stream restore: %w", err)
+	}
+	defer func() {
+		_ = resp.Body.Close()
+	}()
+
+	var rr restRestore
+	if err := json.NewDecoder(resp.Body).Decode(&rr); err != nil {
+		return nil, fmt.Errorf("stream restore: decode response: %w", err)
+	}
+	out := RestoreResponse(rr)
+	return &out, nil
+}
+
+// restRestore is a restore result as the HTTP API encodes it
+type restRestore struct {
+	Restored    string `json:"restored"`
+	Previous    string `json:"previous"`
+	Compression string `json:"compression"`
+	SHA256      string `json:"sha256"`
+	Size        int64  `json:"size"`
+	TxID        int64  `json:"txId"`
+	Replayed    int64  `json:"replayed"`
+	DurationMs  int64  `json:"durationMs"`
+}
+
+// Dump reads the summary from the HTTP trailers that follow the body.
+func (c *RESTClient) Dump(ctx context.Context, req *DumpRequest, w io.Writer) (*DumpSummary, error) {
+	q := url.Values{"collection": req.Collections}
+	q.Set("compression", req.Compression)
+	if req.SkipRevisions {
+		q.Set("revisions", "false")
+	}
+	resp, err := c.stream(ctx, http.MethodGet, "/v1/dump?"+q.Encode(), "", nil)
+	if err != nil {
+		return nil, fmt.Errorf("dump: %w", err)
+	}
+	defer func() {
+		_ = resp.Body.Close()
+	}()
+
+	if _, err := io.Copy(w, resp.Body); err != nil {
+		return nil, fmt.Errorf("dump: %w", err)
+	}
+	txID := resp.Trailer.Get("X-Dump-Tx-Id")
+	if txID == "" {
+		return nil, fmt.Errorf("dump: %w", ErrDumpIncomplete)
+	}
+	sum := &DumpSummary{Format: resp.Header.Get("X-Dump-Format")}
+	sum.Version, _ = strconv.Atoi(resp.Header.Get("X-Dump-Version"))
+	sum.TxID, _ = strconv.ParseInt(txID, 10, 64)
+	sum.CreatedAt, _ = strconv.ParseInt(resp.Trailer.Get("X-Dump-Created-At"), 10, 64)
+	sum.Documents, _ = strconv.ParseInt(resp.Trailer.Get("X-Dump-Documents"), 10, 64)
+	sum.Revisions, _ = strconv.ParseInt(resp.Trailer.Get("X-Dump-Revisions"), 10, 64)
+	if names := resp.Trailer.Get("X-Dump-Collections"); names != "" {
+		sum.Collections = strings.Split(names, ",")
+	}
+	return sum, nil
+}
+
+func (c *RESTClient) Load(ctx context.Context, req *LoadRequest, data io.Reader) (*LoadResponse, error) {
+	q := url.Values{"collection": req.Collections}
+	for from, to := range req.Rename {
+		q.Add("rename", from+"="+to)
+	}
+	if req.Replace {
+		q.Set("replace", "true")
+	}
+	resp, err := c.stream(ctx, http.MethodPost, "/v1/load?"+q.Encode(), "application/octet-stream", data)
+	if err != nil {
+		return nil, fmt.Errorf("load: %w", err)
+	}
+	defer func() {
+		_ = resp.Body.Close()
+	}()
+
+	var lr struct {
+		LoadResponse
+		DurationMs int64 `json:"durationMs"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&lr); err != nil {
+		return nil, fmt.Errorf("load: decode response: %w", err)
+	}
+	lr.LoadResponse.DurationMs = lr.DurationMs
+	return &lr.LoadResponse, nil
+}
+
+func (c *RESTClient) Truncate(ctx context.Context, req *TruncateRequest) (*TruncateResponse, error) {
+	body := map[string]any{
+		"collection": req.Collection,
+		"keepRevs":   req.KeepRevs,
+		"dropCache":  req.DropCache,
+	}
+	var tr TruncateResponse
+	if err := c.do(ctx, http.MethodPost, "/v1/truncate", body, &tr); err != nil {
+		return nil, fmt.Errorf("truncate: %w", err)
+	}
+	return &tr, nil
+}
+
+func (c *RESTClient) Fsck(ctx context.Context, req *FsckRequest) (*FsckReport, error) {
+	body := map[string]any{
+		"collection": req.Collection,
+		"repair":     req.Repair,
+	}
+	var r struct {
+		Documents   int            `json:"documents"`
+		KeyEntries  int            `json:"keyEntries"`
+		MetaIndices int            `json:"metaIndices"`
+		Revisions   int            `json:"revisions"`
+		Problems    map[string]int `json:"problems"`
+		Issues      []FsckIssue    `json:"issues"`
+		Repaired    int            `json:"repaired"`
+	}
+	if err := c.do(ctx, http.MethodPost, "/v1/fsck", body, &r); err != nil {
+		return nil, fmt.Errorf("fsck: %w", err)
+	}
+	out := FsckReport(r)
+	return &out, nil
+}
+
+func (c *RESTClient) Compact(ctx context.Context, req *CompactRequest) (*CompactResponse, error) {
+	body := map[string]any{"minFreeRatio": req.MinFreeRatio}
+	var r struct {
+		Compacted  bool  `json:"compacted"`
+		SizeBefore int64 `json:"sizeBefore"`
+		SizeAfter  int64 `json:"sizeAfter"`
+		FreeBefore int64 `json:"freeBefore"`
+		Reclaimed  int64 `json:"reclaimed"`
+		DurationMs int64 `json:"durationMs"`
+	}
+	if err := c.do(ctx, http.MethodPost, "/v1/compact", body, &r); err != nil {
+		return nil, fmt.Errorf("compact: %w", err)
+	}
+	out := CompactResponse(r)
+	return &out, nil
+}
+
+func (c *RESTClient) ListCollections(ctx context.Context) ([]CollectionInfo, error) {
+	var list []restCollection
+	if err := c.do(ctx, http.MethodGet, "/v1/collections", nil, &list); err != nil {
+		return nil, fmt.Errorf("list collections: %w", err)
+	}
+	out := make([]CollectionInfo, len(list))
+	for i := range list {
+		out[i] = *list[i].info()
+	}
+	return out, nil
+}
+
+func (c *RESTClient) CreateCollection(ctx context.Context, req *CollectionInfo) (*CollectionInfo, error) {
+	var ci restCollection
+	if err := c.do(ctx, http.MethodPost, "/v1/collections/create", collectionBody(req), &ci); err != nil {
+		return nil, fmt.Errorf("create collection: %w", err)
+	}
+	return ci.info(), nil
+}
+
+func (c *RESTClient) UpdateCollection(ctx context.Context, req *CollectionInfo) (*CollectionInfo, error) {
+	var ci restCollection
+	if err := c.do(ctx, http.MethodPost, "/v1/collections/update", collectionBody(req), &ci); err != nil {
+		return nil, fmt.Errorf("update collection: %w", err)
+	}
+	return ci.info(), nil
+}
+
+func (c *RESTClient) DescribeCollection(ctx context.Context, name string) (*CollectionDescription, error) {
+	var desc struct {
+		restCollection
+		DocumentCount int  `json:"documentCount"`
+		RevisionCount int  `json:"revisionCount"`
+		HasSchema     bool `json:"hasSchema"`
+	}
+	if err := c.do(ctx, http.MethodPost, "/v1/collections/describe", map[string]string{"name": name}, &desc); err != nil {
+		return nil, fmt.Errorf("describe collection: %w", err)
+	}
+	return &CollectionDescription{
+		CollectionInfo: *desc.info(),
+		DocumentCount:  desc.DocumentCount,
+		RevisionCount:  desc.RevisionCount,
+		HasSchema:      desc.HasSchema,
+	}, nil
+}
+
+func (c *RESTClient) RenameCollection(ctx context.Context, req *CopyCollectionRequest) (*CopyCollectionResponse, error) {
+	var resp CopyCollectionResponse
+	body := map[string]string{"name": req.Name, "newName": req.NewName}
+	if err := c.do(ctx, http.MethodPost, "/v1/collections/rename", body, &resp); err != nil {
+		return nil, fmt.Errorf("rename collection: %w", err)
+	}
+	return &resp, nil
+}
+
+func (c *RESTClient) CloneCollection(ctx context.Context, req *CopyCollectionRequest) (*CopyCollectionResponse, error) {
+	var resp CopyCollectionResponse
+	body := map[string]string{"name": req.Name, "newName": req.NewName}
+	if err := c.do(ctx, http.MethodPost, "/v1/collections/clone", body, &resp); err != nil {
+		return nil, fmt.Errorf("clone collection: %w", err)
+	}
+	return &resp, nil
+}
+
+func (c *RESTClient) SetSchema(ctx context.Context, schema *CollectionSchema) (*CollectionSchema, error) {
+	var out restSchema
+	if err := c.do(ctx, http.MethodPost, "/v1/schema/set", restSchema(*schema), &out); err != nil {
+		return nil, fmt.Errorf("set schema: %w", err)
+	}
+	s := CollectionSchema(out)
+	return &s, nil
+}
+
+func (c *RESTClient) GetSchema(ctx context.Context, collection string) (*CollectionSchema, error) {
+	var out restSchema
+	if err := c.do(ctx, http.MethodPost, "/v1/schema/get", map[string]string{"collection": collection}, &out); err != nil {
+		return nil, fmt.Errorf("get schema: %w", err)
+	}
+	s := CollectionSchema(out)
+	return &s, nil
+}
+
+func (c *RESTClient) DeleteSchema(ctx context.Context, collection string) error {
+	if err := c.do(ctx, http.MethodPost, "/v1/schema/delete", map[string]string{"collection": collection}, nil); err != nil {
+		return fmt.Errorf("delete schema: %w", err)
+	}
+	return nil
+}
+
+func (c *RESTClient) WhoAmI(ctx context.Context) (*Principal, error) {
+	var out Principal
+	if err := c.do(ctx, http.MethodGet, "/v1/auth/whoami", nil, &out); err != nil {
+		return nil, fmt.Errorf("whoami: %w", err)
+	}
+	return &out, nil
+}
+
+// restAPIKey is the API key as encoded by the HTTP API
+type restAPIKey struct {
+	ID        string   `json:"id"`
+	Name      string   `json:"name"`
+	Roles     []string `json:"roles"`
+	CreatedAt int64    `json:"createdAt"`
+	Key       string   `json:"key"`
+}
+
+func (k *restAPIKey) apiKey() APIKey {
+	return APIKey{ID: k.ID, Name: k.Name, Roles: k.Roles, CreatedAt: time.Unix(k.CreatedAt, 0), Key: k.Key}
+}
+
+func (c *RESTClient) CreateAPIKey(ctx context.Context, req *CreateAPIKeyRequest) (*APIKey, error) {
+	var out restAPIKey
+	if err := c.do(ctx, http.MethodPost, "/v1/auth/keys", req, &out); err != nil {
+		return nil, fmt.Errorf("create api key: %w", err)
+	}
+	k := out.apiKey()
+	return &k, nil
+}
+
+func (c *RESTClient) ListAPIKeys(ctx context.Context) ([]APIKey, error) {
+	var list []restAPIKey
+	if err := c.do(ctx, http.MethodGet, "/v1/auth/keys", nil, &list); err != nil {
+		return nil, fmt.Errorf("list api keys: %w", err)
+	}
+	out := make([]APIKey, len(list))
+	for i := range list {
+		out[i] = list[i].apiKey()
+	}
+	return out, nil
+}
+
+func (c *RESTClient) DeleteAPIKey(ctx context.Context, id string) error {
+	if err := c.do(ctx, http.MethodDelete, "/v1/auth/keys/"+url.PathEscape(id), nil, nil); err != nil {
+		return fmt.Errorf("delete api key: %w", err)
+	}
+	return nil
+}
+
+func (c *RESTClient) Close() error {
+	return nil
+}
+
+// collectionBody encodes collection settings; timestamps are server-managed
+func collectionBody(ci *CollectionInfo) restCollection {
+	return restCollection{
+		Name:           ci.Name,
+		Description:    ci.Description,
+		DefaultLang:    ci.DefaultLang,
+		RevisionPolicy: ci.RevisionPolicy,
+		Compression:    ci.Compression,
+	}
+}
+
+// do performs a unary JSON request and decodes the response into result (if not nil).
+func (c *RESTClient) do(ctx context.Context, method, path string, body, result any) error {
+	_, err := c.send(ctx, method, path, body, result)
+	return err
+}
+
+// send is do that also returns the response, for callers that need its headers.
+func (c *RESTClient) send(ctx context.Context, method, path string, body, result any) (*http.Response, error) {
+	var r io.Reader
+	if body != nil {
+		data, err := json.Marshal(body)
+		if err != nil {
+			return nil, fmt.Errorf("marshal request: %w", err)
+		}
+		r = bytes.NewReader(data)
+	}
+
+	if c.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, c.timeout)
+		defer cancel()
+	}
+
+	resp, err := c.stream(ctx, method, path, "application/json", r)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		_ = resp.Body.Close()
+	}()
+
+	if result != nil {
+		if err := json.NewDecoder(resp.Body).Decode(result); err != nil {
+			return nil, fmt.Errorf("decode response: %w", err)
+		}
+	}
+	return resp, nil
+}
+
+// stream sends a request and returns the response of a 2xx status with its body open.
+// Other statuses become *Error, connection failures an UNAVAILABLE *Error.
+func (c *RESTClient) stream(ctx context.Context, method, path, contentType string, body io.Reader) (*http.Response, error) {
+	req, err := http.NewRequestWithContext(ctx, method, c.baseURL+path, body)
+	if err != nil {
+		return nil, fmt.Errorf("create request: %w", err)
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", contentType)
+	}
+	if c.token != "" {
+		req.Header.Set("Authorization", "Bearer "+c.token)
+	}
+
+	resp, err := c.client.Do(req)
+	if err != nil {
+		switch {
+		case errors.Is(err, context.Canceled):
+			return nil, err
+		case errors.Is(err, context.DeadlineExceeded):
+			return nil, &Error{Message: err.Error(), Code: "DEADLINE_EXCEEDED", Status: http.StatusGatewayTimeout}
+		}
+		return nil, unavailable(err)
+	}
+
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		defer func() {
+			_ = resp.Body.Close()
+		}()
+		return nil, fromHTTP(resp)
+	}
+	return resp, nil
+}
@@ -0,0 +1,136 @@
+package client
+
+import (
+	"context"
+	"log"
+	"math/rand/v2"
+	"sync"
+	"time"
+)
+
+// retryPolicy retries transient failures with exponential backoff and full jitter
+type retryPolicy struct {
+	maxRetries int
+	base       time.Duration
+	max        time.Duration
+}
+
+// do runs fn until it succeeds, fails permanently or the retries are used up. It never
+// sleeps past the context deadline: if the next backoff would not fit, the last error is
+// returned right away.
+func (p retryPolicy) do(ctx context.Context, fn func(context.Context) error) error {
+	for attempt := 0; ; attempt++ {
+		err := fn(ctx)
+		if err == nil || !IsRetryable(err) || attempt >= p.maxRetries || ctx.Err() != nil {
+			return err
+		}
+		delay := p.backoff(attempt)
+		if deadline, ok := ctx.Deadline(); ok && time.Until(deadline) < delay {
+			return err
+		}
+		t := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			t.Stop()
+			return err
+		case <-t.C:
+		}
+	}
+}
+
+func (p retryPolicy) backoff(attempt int) time.Duration {
+	d := p.base << attempt
+	if d <= 0 || d > p.max {
+		d = p.max
+	}
+	return time.Duration(rand.Int64N(int64(d) + 1))
+}
+
+type breakerState int
+
+const (
+	breakerClosed breakerState = iota
+	breakerOpen
+	breakerHalfOpen
+)
+
+func (s breakerState) String() string {
+	switch s {
+	case breakerOpen:
+		return "open"
+	case breakerHalfOpen:
+		return "half-open"
+	default:
+		return "closed"
+	}
+}
+
+// breaker is a per-transport circuit breaker. After threshold consecutive transport
+// failures it opens and the transport is skipped; after cooldown a single probe request
+// is let through (half-open) and its outcome closes or reopens the breaker.
+type breaker struct {
+	name      string
+	threshold int
+	cooldown  time.Duration
+
+	mu       sync.Mutex
+	state    breakerState
+	failures int
+	openedAt time.Time
+	probing  bool
+}
+
+func (b *breaker) allow() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	switch b.state {
+	case breakerOpen:
+		if time.Since(b.openedAt) < b.cooldown {
+			return false
+		}
+		b.setState(breakerHalfOpen)
+		b.probing = true
+		return true
+	case breakerHalfOpen:
+		if b.probing {
+			return false
+		}
+		b.probing = true
+		return true
+	default:
+		return true
+	}
+}
+
+func (b *breaker) success() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.failures = 0
+	b.probing = false
+	b.setState(breakerClosed)
+}
+
+func (b *breaker) failure() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.probing = false
+	b.failures++
+	if b.state == breakerHalfOpen || b.failures >= b.threshold {
+		b.openedAt = time.Now()
+		b.setState(breakerOpen)
+	}
+}
+
+// release ends a probe without a verdict (e.g. the caller gave up)
+func (b *breaker) release() {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.probing = false
+}
+
+func (b *breaker) setState(s breakerState) {
+	if b.state != s {
+		log.Printf("mddb client: %s circuit %s", b.name, s)
+		b.state = s
+	}
+}
@@ -0,0 +1,199 @@
+package client
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+var errTransient = &Error{Message: "unavailable", Code: "UNAVAILABLE", Status: 503, Reason: ReasonUnavailable}
+
+func TestBackoff(t *testing.T) {
+	p := retryPolicy{base: 10 * time.Millisecond, max: 50 * time.Millisecond}
+	cases := []struct {
+		attempt int
+		max     time.Duration
+	}{
+		{0, 10 * time.Millisecond},
+		{1, 20 * time.Millisecond},
+		{2, 40 * time.Millisecond},
+		{3, 50 * time.Millisecond},
+		{62, 50 * time.Millisecond}, // the shift overflows
+	}
+	for _, c := range cases {
+		var lo, hi time.Duration = c.max, 0
+		for range 1000 {
+			d := p.backoff(c.attempt)
+			if d < 0 || d > c.max {
+				t.Fatalf("backoff(%d) = %v, want within [0, %v]", c.attempt, d, c.max)
+			}
+			lo, hi = min(lo, d), max(hi, d)
+		}
+		// Full jitter spreads the delays over the whole range
+		if lo > c.max/4 || hi < c.max*3/4 {
+			t.Errorf("backoff(%d) ranged over [%v, %v], want most of [0, %v]", c.attempt, lo, hi, c.max)
+		}
+	}
+}
+
+func TestRetryPolicy(t *testing.T) {
+	notFound := &Error{Message: "not found", Code: "NOT_FOUND", Status: 404, Reason: ReasonDocumentNotFound}
+	cases := []struct {
+		name       string
+		maxRetries int
+		timeout    time.Duration
+		errs       []error // returned by successive attempts, nil after the last
+		calls      int
+		err        error
+	}{
+		{name: "success", maxRetries: 3, calls: 1},
+		{name: "transient then success", maxRetries: 3, errs: []error{errTransient, errTransient}, calls: 3},
+		{name: "retries used up", maxRetries: 2, errs: []error{errTransient, errTransient, errTransient, errTransient}, calls: 3, err: errTransient},
+		{name: "no retries", errs: []error{errTransient}, calls: 1, err: errTransient},
+		{name: "permanent error is not retried", maxRetries: 3, errs: []error{notFound}, calls: 1, err: notFound},
+		{name: "permanent error after a transient one", maxRetries: 3, errs: []error{errTransient, notFound}, calls: 2, err: notFound},
+		{name: "backoff past the deadline", maxRetries: 3, timeout: time.Millisecond, errs: []error{errTransient, errTransient}, calls: 1, err: errTransient},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			ctx := context.Background()
+			if c.timeout > 0 {
+				var cancel context.CancelFunc
+				ctx, cancel = context.WithTimeout(ctx, c.timeout)
+				defer cancel()
+			}
+			p := retryPolicy{maxRetries: c.maxRetries, base: time.Millisecond, max: time.Second}
+			if c.timeout > 0 {
+				// Every backoff is longer than what is left of the deadline
+				p.base, p.max = time.Hour, time.Hour
+			}
+			calls := 0
+			err := p.do(ctx, func(context.Context) error {
+				calls++
+				if calls <= len(c.errs) {
+					return c.errs[calls-1]
+				}
+				return nil
+			})
+			if !errors.Is(err, c.err) {
+				t.Errorf("do: %v, want %v", err, c.err)
+			}
+			if calls != c.calls {
+				t.Errorf("%d attempts, want %d", calls, c.calls)
+			}
+		})
+	}
+}
+
+func TestRetryPolicyStopsOnCancel(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	p := retryPolicy{maxRetries: 10, base: time.Hour, max: time.Hour}
+	calls := 0
+	time.AfterFunc(20*time.Millisecond, cancel)
+	start := time.Now()
+	err := p.do(ctx, func(context.Context) error {
+		calls++
+		return errTransient
+	})
+	if !errors.Is(err, errTransient) || calls != 1 {
+		t.Errorf("do: %v after %d attempts, want the transient error after 1", err, calls)
+	}
+	if d := time.Since(start); d > time.Second {
+		t.Errorf("do returned %v after the cancel", d)
+	}
+}
+
+func TestBreaker(t *testing.T) {
+	type step struct {
+		op    string // allow, success, failure, release or cooldown (let the cooldown pass)
+		allow bool   // for allow: the expected answer
+		state breakerState
+	}
+	cases := []struct {
+		name  string
+		steps []step
+	}{
+		{
+			name: "opens after the threshold",
+			steps: []step{
+				{op: "failure", state: breakerClosed},
+				{op: "failure", state: breakerClosed},
+				{op: "allow", allow: true, state: breakerClosed},
+				{op: "failure", state: breakerOpen},
+				{op: "allow", allow: false, state: breakerOpen},
+			},
+		},
+		{
+			name: "a success resets the count",
+			steps: []step{
+				{op: "failure", state: breakerClosed},
+				{op: "failure", state: breakerClosed},
+				{op: "success", state: breakerClosed},
+				{op: "failure", state: breakerClosed},
+				{op: "failure", state: breakerClosed},
+				{op: "failure", state: breakerOpen},
+			},
+		},
+		{
+			name: "a probe that succeeds closes the circuit",
+			steps: []step{
+				{op: "failure"}, {op: "failure"}, {op: "failure", state: breakerOpen},
+				{op: "cooldown", state: breakerOpen},
+				{op: "allow", allow: true, state: breakerHalfOpen},
+				{op: "allow", allow: false, state: breakerHalfOpen},
+				{op: "success", state: breakerClosed},
+				{op: "allow", allow: true, state: breakerClosed},
+			},
+		},
+		{
+			name: "a probe that fails reopens the circuit",
+			steps: []step{
+				{op: "failure"}, {op: "failure"}, {op: "failure", state: breakerOpen},
+				{op: "cooldown", state: breakerOpen},
+				{op: "allow", allow: true, state: breakerHalfOpen},
+				{op: "failure", state: breakerOpen},
+				{op: "allow", allow: false, state: breakerOpen},
+				{op: "cooldown", state: breakerOpen},
+				{op: "allow", allow: true, state: breakerHalfOpen},
+			},
+		},
+		{
+			name: "a released probe lets the next one through",
+			steps: []step{
+				{op: "failure"}, {op: "failure"}, {op: "failure", state: breakerOpen},
+				{op: "cooldown", state: breakerOpen},
+				{op: "allow", allow: true, state: breakerHalfOpen},
+				{op: "release", state: breakerHalfOpen},
+				{op: "allow", allow: true, state: breakerHalfOpen},
+				{op: "allow", allow: false, state: breakerHalfOpen},
+			},
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			b := &breaker{name: "test", threshold: 3, cooldown: time.Minute}
+			for i, s := range c.steps {
+				switch s.op {
+				case "allow":
+					if got := b.allow(); got != s.allow {
+						t.Fatalf("step %d: allow = %v, want %v", i, got, s.allow)
+					}
+				case "success":
+					b.success()
+				case "failure":
+					b.failure()
+				case "release":
+					b.release()
+				case "cooldown":
+					b.mu.Lock()
+					b.openedAt = b.openedAt.Add(-b.cooldown)
+					b.mu.Unlock()
+				}
+				if b.state != s.state {
+					t.Fatalf("step %d (%s): %s, want %s", i, s.op, b.state, s.state)
+				}
+			}
+		})
+	}
+}
@@ -0,0 +1,493 @@
+package client
+
+import (
+	"fmt"
+	"hash/fnv"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Document represents a markdown document in MDDB.
+type Document struct {
+	ID        string              `json:"id"`
+	Key       string              `json:"key"`
+	Lang      string              `json:"lang"`
+	Meta      map[string][]string `json:"meta"`
+	ContentMD string              `json:"content_md"`
+	AddedAt   time.Time           `json:"added_at"`
+	UpdatedAt time.Time           `json:"updated_at"`
+}
+
+// ETag returns the server's ETag of the document, for Precondition.IfMatch.
+func (d *Document) ETag() string {
+	h := fnv.New64a()
+	fmt.Fprintf(h, "%s\x00%d\x00%s\x00", d.ID, d.UpdatedAt.Unix(), d.ContentMD)
+	keys := make([]string, 0, len(d.Meta))
+	for k := range d.Meta {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		fmt.Fprintf(h, "%s\x00%s\x00", k, strings.Join(d.Meta[k], "\x00"))
+	}
+	return fmt.Sprintf(`"%016x"`, h.Sum64())
+}
+
+// Health represents server health status.
+type Health struct {
+	Status string `json:"status"`
+	Mode   string `json:"mode"`
+}
+
+// Stats represents server statistics.
+type Stats struct {
+	DatabasePath     string            `json:"database_path"`
+	DatabaseSize     int64             `json:"database_size"`
+	Mode             string            `json:"mode"`
+	Collections      []CollectionStats `json:"collections"`
+	TotalDocuments   int               `json:"total_documents"`
+	TotalRevisions   int               `json:"total_revisions"`
+	TotalMetaIndices int               `json:"total_meta_indices"`
+	Cache            CacheStats        `json:"cache"`
+}
+
+// CacheStats represents document cache statistics.
+type CacheStats struct {
+	Hits      uint64 `json:"hits"`
+	Misses    uint64 `json:"misses"`
+	Evictions uint64 `json:"evictions"`
+	Rejected  uint64 `json:"rejected"`
+	Entries   int    `json:"entries"`
+	Bytes     int64  `json:"bytes"`
+	Budget    int64  `json:"budget"`
+}
+
+// CollectionStats represents collection statistics.
+type CollectionStats struct {
+	Name           string `json:"name"`
+	DocumentCount  int    `json:"document_count"`
+	RevisionCount  int    `json:"revision_count"`
+	MetaIndexCount int    `json:"meta_index_count"`
+}
+
+// AddRequest represents request to add/update a document.
+type AddRequest struct {
+	Collection   string              `json:"collection"`
+	Key          string              `json:"key"`
+	Lang         string              `json:"lang"`
+	Meta         map[string][]string `json:"meta"`
+	ContentMD    string              `json:"content_md"`
+	SaveRevision bool                `json:"save_revision"`
+}
+
+// Meta patch operations.
+const (
+	PatchSet    = "set"    // replace the values of a key (no values = unset)
+	PatchUnset  = "unset"  // remove a key
+	PatchAdd    = "add"    // add values that are not present yet
+	PatchRemove = "remove" // remove values; the key goes away with its last value
+)
+
+// MetaPatch is one operation on a meta key.
+type MetaPatch struct {
+	Op     string   `json:"op"`
+	Key    string   `json:"key"`
+	Values []string `json:"values,omitempty"`
+}
+
+// PatchRequest represents request to patch an existing document. Meta operations run
+// in order; ContentMD (if set) replaces the content before AppendMD is appended.
+type PatchRequest struct {
+	Collection   string      `json:"collection"`
+	Key          string      `json:"key"`
+	Lang         string      `json:"lang"`
+	Meta         []MetaPatch `json:"meta,omitempty"`
+	ContentMD    *string     `json:"content_md,omitempty"`
+	AppendMD     string      `json:"append_md,omitempty"`
+	SaveRevision bool        `json:"save_revision"`
+}
+
+// GetRequest represents request to get a document.
+type GetRequest struct {
+	Collection string            `json:"collection"`
+	Key        string            `json:"key"`
+	Lang       string            `json:"lang"`
+	Env        map[string]string `json:"env,omitempty"`
+}
+
+// SearchRequest represents search request.
+type SearchRequest struct {
+	Collection  string              `json:"collection"`
+	FilterMeta  map[string][]string `json:"filter_meta,omitempty"`
+	Sort        string              `json:"sort,omitempty"`
+	Asc         bool                `json:"asc,omitempty"`
+	Limit       int                 `json:"limit,omitempty"`
+	Offset      int                 `json:"offset,omitempty"`
+	Consistency string              `json:"consistency,omitempty"` // eventual (default) or strong
+}
+
+// SearchResponse represents search result.
+type SearchResponse struct {
+	Documents []Document `json:"documents"`
+	Total     int        `json:"total"`
+}
+
+// DeleteRequest represents request to delete a document.
+type DeleteRequest struct {
+	Collection string `json:"collection"`
+	Key        string `json:"key"`
+	Lang       string `json:"lang"`
+}
+
+// Precondition must hold for a document when its transaction operation runs.
+type Precondition struct {
+	Exists  *bool  `json:"exists,omitempty"`   // the document must (true) or must not (false) exist
+	IfMatch string `json:"if_match,omitempty"` // the document must exist with this ETag
+}
+
+// TxOp is one operation of a transaction; exactly one of Add, Patch and Delete is set.
+type TxOp struct {
+	Add          *AddRequest    `json:"add,omitempty"`
+	Patch        *PatchRequest  `json:"patch,omitempty"`
+	Delete       *DeleteRequest `json:"delete,omitempty"`
+	Precondition *Precondition  `json:"precondition,omitempty"`
+}
+
+// TransactionRequest represents request to run operations all or nothing.
+type TransactionRequest struct {
+	Ops []TxOp `json:"ops"`
+}
+
+// TxResult is the outcome of one operation of a committed transaction.
+type TxResult struct {
+	Op         string    `json:"op"`
+	Collection string    `json:"collection"`
+	Key        string    `json:"key"`
+	Lang       string    `json:"lang"`
+	Document   *Document `json:"document,omitempty"` // add and patch
+	Created    bool      `json:"created,omitempty"`
+}
+
+// TransactionResponse represents response from a committed transaction.
+type TransactionResponse struct {
+	Results []TxResult `json:"results"`
+}
+
+// DeleteCollectionRequest represents request to delete a collection.
+type DeleteCollectionRequest struct {
+	Collection string `json:"collection"`
+}
+
+// DeleteCollectionResponse represents result of collection deletion.
+type DeleteCollectionResponse struct {
+	Deleted int `json:"deleted"`
+}
+
+// BatchDocument represents a document in batch operation.
+type BatchDocument struct {
+	Key          string              `json:"key"`
+	Lang         string              `json:"lang"`
+	Meta         map[string][]string `json:"meta"`
+	ContentMD    string              `json:"content_md"`
+	SaveRevision bool                `json:"save_revision"`
+}
+
+// AddBatchRequest represents request to add multiple documents.
+type AddBatchRequest struct {
+	Collection string          `json:"collection"`
+	Documents  []BatchDocument `json:"documents"`
+}
+
+// AddBatchResponse represents result of adding multiple documents.
+type AddBatchResponse struct {
+	Added   int      `json:"added"`
+	Updated int      `json:"updated"`
+	Failed  int      `json:"failed"`
+	Errors  []string `json:"errors,omitempty"`
+}
+
+// UpdateDocument represents a document to update.
+type UpdateDocument struct {
+	Key          string              `json:"key"`
+	Lang         string              `json:"lang"`
+	Meta         map[string][]string `json:"meta"`
+	ContentMD    string              `json:"content_md"`
+	SaveRevision bool                `json:"save_revision"`
+}
+
+// UpdateBatchRequest represents request to update multiple documents.
+type UpdateBatchRequest struct {
+	Collection string           `json:"collection"`
+	Documents  []UpdateDocument `json:"documents"`
+}
+
+// UpdateBatchResponse represents result of updating multiple documents.
+type UpdateBatchResponse struct {
+	Updated  int      `json:"updated"`
+	NotFound int      `json:"not_found"`
+	Failed   int      `json:"failed"`
+	Errors   []string `json:"errors,omitempty"`
+}
+
+// DeleteDocument represents a document to delete.
+type DeleteDocument struct {
+	Key  string `json:"key"`
+	Lang string `json:"lang"`
+}
+
+// DeleteBatchRequest represents request to delete multiple documents.
+type DeleteBatchRequest struct {
+	Collection string           `json:"collection"`
+	Documents  []DeleteDocument `json:"documents"`
+}
+
+// DeleteBatchResponse represents result of deleting multiple documents.
+type DeleteBatchResponse struct {
+	Deleted  int      `json:"deleted"`
+	NotFound int      `json:"not_found"`
+	Failed   int      `json:"failed"`
+	Errors   []string `json:"errors,omitempty"`
+}
+
+// ExportRequest represents export request.
+type ExportRequest struct {
+	Collection string              `json:"collection"`
+	FilterMeta map[string][]string `json:"filter_meta,omitempty"`
+	Format     string              `json:"format"` // ndjson, zip, tar
+}
+
+// BackupRequest represents request to write a backup on the server.
+type BackupRequest struct {
+	To          string `json:"to"`          // file name inside the server's backup directory (empty = generated)
+	Compression string `json:"compression"` // none (default) or zstd
+}
+
+// BackupResponse represents backup result.
+type BackupResponse struct {
+	Backup   string          `json:"backup"` // file name inside the backup directory
+	Manifest *BackupManifest `json:"manifest"`
+}
+
+// StreamBackupRequest represents request to stream a backup to the client.
+type StreamBackupRequest struct {
+	Compression string `json:"compression"` // none (default) or zstd
+}
+
+// BackupManifest describes a backup and lets the receiver verify it.
+type BackupManifest struct {
+	Format      string `json:"format"`
+	Compression string `json:"compression"`
+	CreatedAt   int64  `json:"created_at"`
+	TxID        int64  `json:"tx_id"`   // transaction the snapshot was taken at
+	DBSize      int64  `json:"db_size"` // bytes of the database image
+	Size        int64  `json:"size"`    // bytes of the backup, after compression
+	SHA256      string `json:"sha256"`  // hex digest of the backup bytes
+}
+
+// RestoreRequest represents request to restore a backup from the server's backup directory.
+type RestoreRequest struct {
+	From     string    `json:"from"`     // file name inside the backup directory
+	SHA256   string    `json:"sha256"`   // expected checksum of the backup file (optional)
+	Rollback bool      `json:"rollback"` // swap back the file replaced by the last restore instead of From
+	At       time.Time `json:"at"`       // restore the database as it was then: From, or the newest earlier backup, rolled forward with the server's change log
+}
+
+// StreamRestoreRequest represents request to restore an uploaded backup.
+type StreamRestoreRequest struct {
+	SHA256 string `json:"sha256"` // expected checksum of the upload (optional)
+}
+
+// RestoreResponse represents restore result.
+type RestoreResponse struct {
+	Restored    string `json:"restored"`
+	Previous    string `json:"previous"`    // replaced database file, kept for rollback
+	Compression string `json:"compression"` // of the backup as read
+	SHA256      string `json:"sha256"`      // of the backup as read
+	Size        int64  `json:"size"`        // restored database file size
+	TxID        int64  `json:"tx_id"`
+	Replayed    int64  `json:"replayed"` // changes replayed from the change log (At)
+	DurationMs  int64  `json:"duration_ms"`
+}
+
+// DumpRequest represents request to dump collections as a portable logical archive.
+type DumpRequest struct {
+	Collections   []string `json:"collections"`    // collections to dump (empty = all)
+	Compression   string   `json:"compression"`    // none (default) or zstd
+	SkipRevisions bool     `json:"skip_revisions"` // leave out revision history
+}
+
+// DumpSummary describes a completed dump.
+type DumpSummary struct {
+	Format      string   `json:"format"`
+	Version     int      `json:"version"`
+	CreatedAt   int64    `json:"created_at"`
+	TxID        int64    `json:"tx_id"` // transaction the dump was read from
+	Collections []string `json:"collections"`
+	Documents   int64    `json:"documents"`
+	Revisions   int64    `json:"revisions"`
+}
+
+// LoadRequest represents request to load collections from a dump.
+type LoadRequest struct {
+	Collections []string          `json:"collections"` // collections of the dump to load (empty = all)
+	Rename      map[string]string `json:"rename"`      // dump collection name -> target collection
+	Replace     bool              `json:"replace"`     // replace existing target collections instead of failing
+}
+
+// LoadResponse represents load result.
+type LoadResponse struct {
+	Format      string             `json:"format"`
+	Version     int                `json:"version"`
+	Collections []LoadedCollection `json:"collections"`
+	DurationMs  int64              `json:"duration_ms"`
+}
+
+// LoadedCollection is a collection written by a load.
+type LoadedCollection struct {
+	From      string `json:"from"` // name in the dump
+	Name      string `json:"name"`
+	Documents int64  `json:"documents"`
+	Revisions int64  `json:"revisions"`
+	Replaced  bool   `json:"replaced"` // an existing collection was replaced
+}
+
+// TruncateRequest represents request to truncate revision history.
+type TruncateRequest struct {
+	Collection string `json:"collection"`
+	KeepRevs   int    `json:"keep_revs"`
+	DropCache  bool   `json:"drop_cache"`
+}
+
+// TruncateResponse represents truncate result.
+type TruncateResponse struct {
+	Status string `json:"status"`
+}
+
+// FsckRequest represents request to check (and repair) database consistency.
+type FsckRequest struct {
+	Collection string `json:"collection"` // empty checks every collection
+	Repair     bool   `json:"repair"`
+}
+
+// FsckIssue represents one inconsistency found by Fsck.
+type FsckIssue struct {
+	Kind       string `json:"kind"` // orphanMetaIndex, missingMetaIndex, orphanKeyEntry, missingKeyEntry, orphanRevision, undecodableDocument, undecodableRevision
+	Collection string `json:"collection"`
+	Key        string `json:"key"`
+	Repaired   bool   `json:"repaired"`
+}
+
+// FsckReport represents the result of a consistency check.
+type FsckReport struct {
+	Documents   int            `json:"documents"`
+	KeyEntries  int            `json:"key_entries"`
+	MetaIndices int            `json:"meta_indices"`
+	Revisions   int            `json:"revisions"`
+	Problems    map[string]int `json:"problems"` // count per kind
+	Issues      []FsckIssue    `json:"issues"`   // the first 1000 problems
+	Repaired    int            `json:"repaired"`
+}
+
+// CompactRequest represents request to compact the database file.
+type CompactRequest struct {
+	MinFreeRatio float64 `json:"min_free_ratio"` // skip unless free pages are at least this fraction of the file
+}
+
+// CompactResponse represents compaction result.
+type CompactResponse struct {
+	Compacted  bool  `json:"compacted"` // false when skipped by MinFreeRatio
+	SizeBefore int64 `json:"size_before"`
+	SizeAfter  int64 `json:"size_after"`
+	FreeBefore int64 `json:"free_before"` // bytes in free pages before compacting
+	Reclaimed  int64 `json:"reclaimed"`
+	DurationMs int64 `json:"duration_ms"`
+}
+
+// ImportRequest describes a bulk import; the data is passed separately to Import.
+type ImportRequest struct {
+	Collection   string `json:"collection"`
+	Format       string `json:"format"`      // ndjson, zip, tar
+	OnConflict   string `json:"on_conflict"` // upsert (default), skip, fail
+	DryRun       bool   `json:"dry_run"`
+	Lang         string `json:"lang"`       // language for files named {key}.md
+	ChunkSize    int    `json:"chunk_size"` // documents per transaction
+	SaveRevision bool   `json:"save_revision"`
+}
+
+// ImportResponse represents import result.
+type ImportResponse struct {
+	Total   int      `json:"total"`
+	Added   int      `json:"added"`
+	Updated int      `json:"updated"`
+	Skipped int      `json:"skipped"`
+	Failed  int      `json:"failed"`
+	Chunks  int      `json:"chunks"`
+	Errors  []string `json:"errors,omitempty"`
+	DryRun  bool     `json:"dry_run"`
+}
+
+// CollectionInfo represents a registered collection and its settings.
+type CollectionInfo struct {
+	Name           string    `json:"name"`
+	Description    string    `json:"description"`
+	DefaultLang    string    `json:"default_lang"`
+	RevisionPolicy string    `json:"revision_policy"` // request, always, never
+	Compression    string    `json:"compression"`     // auto, none, snappy, zstd
+	CreatedAt      time.Time `json:"created_at"`
+	UpdatedAt      time.Time `json:"updated_at"`
+}
+
+// CollectionDescription represents collection settings with live counts.
+type CollectionDescription struct {
+	CollectionInfo
+	DocumentCount int  `json:"document_count"`
+	RevisionCount int  `json:"revision_count"`
+	HasSchema     bool `json:"has_schema"`
+}
+
+// CopyCollectionRequest represents request to rename or clone a collection.
+type CopyCollectionRequest struct {
+	Name    string `json:"name"`
+	NewName string `json:"new_name"`
+}
+
+// CopyCollectionResponse represents result of renaming or cloning a collection.
+type CopyCollectionResponse struct {
+	Status     string `json:"status"`
+	Collection string `json:"collection"`
+	Documents  int    `json:"documents"`
+}
+
+// CollectionSchema represents the validation rules of a collection.
+type CollectionSchema struct {
+	Collection     string              `json:"collection"`
+	RequiredMeta   []string            `json:"required_meta,omitempty"`
+	OptionalMeta   []string            `json:"optional_meta,omitempty"`
+	Enums          map[string][]string `json:"enums,omitempty"`
+	Patterns       map[string]string   `json:"patterns,omitempty"`
+	MaxContentSize int                 `json:"max_content_size,omitempty"`
+	RequiredLangs  []string            `json:"required_langs,omitempty"`
+}
+
+// Principal is the caller as authenticated by the server.
+type Principal struct {
+	Subject string   `json:"subject"`
+	Method  string   `json:"method"` // none, admin_key, api_key, jwt
+	Roles   []string `json:"roles"`
+}
+
+// CreateAPIKeyRequest represents request to create an API key.
+type CreateAPIKeyRequest struct {
+	Name  string   `json:"name"`
+	Roles []string `json:"roles"` // <read|write|admin>:<collection pattern>, e.g. write:blog-*
+}
+
+// APIKey describes an API key. Key, the secret, is only set by CreateAPIKey.
+type APIKey struct {
+	ID        string    `json:"id"`
+	Name      string    `json:"name"`
+	Roles     []string  `json:"roles"`
+	CreatedAt time.Time `json:"created_at"`
+	Key       string    `json:"key,omitempty"`
+}
@@ -0,0 +1,116 @@
+package main
+
+import (
+	"errors"
+	"net/http"
+
+	json "github.com/goccy/go-json"
+	"github.com/tradik/mddb/services/mddbd/storage"
+)
+
+type CollectionRequest struct {
+	Name    string `json:"name"`
+	NewName string `json:"newName"` // rename/clone target
+}
+
+// collectionInfoRequest is the body of collection create and update
+type collectionInfoRequest storage.CollectionInfo
+
+func (s *Server) handleCollectionsList(w http.ResponseWriter, r *http.Request) {
+	caller := s.caller(r.Context())
+	list := []storage.CollectionInfo{}
+	for _, ci := range s.DB.Collections.List() {
+		if caller.can(RoleRead, ci.Name) {
+			list = append(list, ci)
+		}
+	}
+	ok(w, list)
+}
+
+func (s *Server) handleCollectionCreate(w http.ResponseWriter, r *http.Request) {
+	var req collectionInfoRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		bad(w, err)
+		return
+	}
+	ci, err := s.DB.Collections.Create(storage.CollectionInfo(req))
+	if err != nil {
+		bad(w, err)
+		return
+	}
+	ok(w, ci)
+}
+
+func (s *Server) handleCollectionUpdate(w http.ResponseWriter, r *http.Request) {
+	var req collectionInfoRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		bad(w, err)
+		return
+	}
+	ci, err := s.DB.Collections.Update(storage.CollectionInfo(req))
+	if err != nil {
+		bad(w, err)
+		return
+	}
+	ok(w, ci)
+}
+
+func (s *Server) handleCollectionDescribe(w http.ResponseWriter, r *http.Request) {
+	var req CollectionRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		bad(w, err)
+		return
+	}
+	desc, err := s.DB.Collections.Describe(req.Name)
+	if err != nil {
+		bad(w, err)
+		return
+	}
+	ok(w, desc)
+}
+
+func (s *Server) handleCollectionRename(w http.ResponseWriter, r *http.Request) {
+	var req CollectionRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		bad(w, err)
+		return
+	}
+	n, err := s.DB.Collections.Rename(req.Name, req.NewName)
+	if err != nil {
+		bad(w, err)
+		return
+	}
+	ok(w, map[string]interface{}{"status": "renamed", "collection": req.NewName, "documents": n})
+}
+
+func (s *Server) handleCollectionClone(w http.ResponseWriter, r *http.Request) {
+	var req CollectionRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		bad(w, err)
+		return
+	}
+	n, err := s.DB.Collections.Clone(req.Name, req.NewName)
+	if err != nil {
+		bad(w, err)
+		return
+	}
+	ok(w, map[string]interface{}{"status": "cloned", "collection": req.NewName, "documents": n})
+}
+
+func (s *Server) handleCollectionDelete(w http.ResponseWriter, r *http.Request) {
+	var req CollectionRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		bad(w, err)
+		return
+	}
+	if req.Name == "" {
+		bad(w, errors.New("missing collection name"))
+		return
+	}
+	n, err := s.DB.Collections.Delete(req.Name)
+	if err != nil {
+		bad(w, err)
+		return
+	}
+	ok(w, map[string]interface{}{"status": "deleted", "collection": req.Name, "deletedCount": n})
+}
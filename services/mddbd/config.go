@@ -0,0 +1,458 @@
+package main
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"net/url"
+	"os"
+	"path/filepath"
+	"reflect"
+	"time"
+
+	"github.com/kelseyhightower/envconfig"
+	"github.com/tradik/mddb/services/mddbd/storage"
+	"gopkg.in/yaml.v3"
+)
+
+// Config is the mddbd configuration. It is loaded in layers: defaults, then the YAML
+// file, then MDDB_* environment variables. The storage cache settings, log, rateLimit
+// and hooks are re-read on SIGHUP; everything else needs a restart.
+type Config struct {
+	Server    ServerConfig    `yaml:"server"`
+	TLS       TLSConfig       `yaml:"tls"`
+	Auth      AuthConfig      `yaml:"auth"`
+	Storage   StorageConfig   `yaml:"storage"`
+	Backups   BackupsConfig   `yaml:"backups"`
+	Log       LogConfig       `yaml:"log"`
+	RateLimit RateLimitConfig `yaml:"rateLimit"`
+	Hooks     Hooks           `yaml:"hooks"`
+}
+
+type ServerConfig struct {
+	Mode                AccessMode    `yaml:"mode"` // read | write | wr
+	HTTPAddress         string        `yaml:"httpAddress"`
+	GRPCAddress         string        `yaml:"grpcAddress"`
+	HTTP3Address        string        `yaml:"http3Address"` // extreme mode only
+	Extreme             bool          `yaml:"extreme"`
+	RestoreDrainTimeout time.Duration `yaml:"restoreDrainTimeout"`
+	ShutdownTimeout     time.Duration `yaml:"shutdownTimeout"`
+	ShutdownDelay       time.Duration `yaml:"shutdownDelay"`
+}
+
+// TLSConfig names the certificate files shared by all listeners; see newTLS
+type TLSConfig struct {
+	Cert       string `yaml:"cert"`
+	Key        string `yaml:"key"`
+	ClientCA   string `yaml:"clientCA"`   // enables mutual TLS
+	ClientAuth string `yaml:"clientAuth"` // require | optional, with a client CA
+}
+
+// AuthConfig configures authentication; see newAuth
+type AuthConfig struct {
+	Enabled         bool   `yaml:"enabled"`
+	AdminKey        Secret `yaml:"adminKey"`
+	JWTHMACSecret   Secret `yaml:"jwtHmacSecret"`
+	JWTRSAPublicKey string `yaml:"jwtRsaPublicKey"` // PEM file
+	JWTIssuer       string `yaml:"jwtIssuer"`
+	JWTAudience     string `yaml:"jwtAudience"`
+	JWTRolesClaim   string `yaml:"jwtRolesClaim"`
+}
+
+// Secret is a setting that --print-config doesn't reveal
+type Secret string
+
+// MarshalYAML prints a set secret as a placeholder
+func (s Secret) MarshalYAML() (any, error) {
+	if s == "" {
+		return "", nil
+	}
+	return "<redacted>", nil
+}
+
+type StorageConfig struct {
+	Path             string        `yaml:"path"`
+	CacheMB          *int64        `yaml:"cacheMB"` // default 64, 256 in extreme mode; 0 disables the cache
+	CacheTTL         time.Duration `yaml:"cacheTTL"`
+	IndexQueueLimit  int           `yaml:"indexQueueLimit"`
+	BatchWorkers     int           `yaml:"batchWorkers"`
+	Shards           int           `yaml:"shards"` // extreme mode shard cluster
+	ShardReplicas    int           `yaml:"shardReplicas"`
+	CompactInterval  time.Duration `yaml:"compactInterval"`
+	CompactFreeRatio float64       `yaml:"compactFreeRatio"`
+}
+
+type BackupsConfig struct {
+	Dir            string `yaml:"dir"` // default: backups next to storage.path
+	BackupSchedule `yaml:",inline"`
+}
+
+type LogConfig struct {
+	Level string `yaml:"level"` // debug | info | warn | error
+}
+
+// RateLimitConfig limits requests per client address; a zero rate disables the limit
+type RateLimitConfig struct {
+	RequestsPerSecond float64 `yaml:"requestsPerSecond"`
+	Burst             int     `yaml:"burst"` // default: one second's worth of requests
+}
+
+// envConfig maps environment variables; nil fields are unset
+type envConfig struct {
+	Mode                *AccessMode    `envconfig:"MDDB_MODE"`
+	HTTPAddress         *string        `envconfig:"MDDB_ADDR"`
+	GRPCAddress         *string        `envconfig:"MDDB_GRPC_ADDR"`
+	HTTP3Address        *string        `envconfig:"MDDB_HTTP3_ADDR"`
+	Extreme             *bool          `envconfig:"MDDB_EXTREME"`
+	RestoreDrainTimeout *time.Duration `envconfig:"MDDB_RESTORE_DRAIN_TIMEOUT"`
+	ShutdownTimeout     *time.Duration `envconfig:"MDDB_SHUTDOWN_TIMEOUT"`
+	ShutdownDelay       *time.Duration `envconfig:"MDDB_SHUTDOWN_DELAY"`
+
+	TLSCert       *string `envconfig:"MDDB_TLS_CERT"`
+	TLSKey        *string `envconfig:"MDDB_TLS_KEY"`
+	TLSClientCA   *string `envconfig:"MDDB_TLS_CLIENT_CA"`
+	TLSClientAuth *string `envconfig:"MDDB_TLS_CLIENT_AUTH"`
+
+	Auth            *bool   `envconfig:"MDDB_AUTH"`
+	AuthAdminKey    *Secret `envconfig:"MDDB_AUTH_ADMIN_KEY"`
+	JWTHMACSecret   *Secret `envconfig:"MDDB_JWT_HMAC_SECRET"`
+	JWTRSAPublicKey *string `envconfig:"MDDB_JWT_RSA_PUBLIC_KEY"`
+	JWTIssuer       *string `envconfig:"MDDB_JWT_ISSUER"`
+	JWTAudience     *string `envconfig:"MDDB_JWT_AUDIENCE"`
+	JWTRolesClaim   *string `envconfig:"MDDB_JWT_ROLES_CLAIM"`
+
+	Path             *string        `envconfig:"MDDB_PATH"`
+	CacheMB          *int64         `envconfig:"MDDB_CACHE_MB"`
+	CacheTTL         *time.Duration `envconfig:"MDDB_CACHE_TTL"`
+	IndexQueueLimit  *int           `envconfig:"MDDB_INDEX_QUEUE_LIMIT"`
+	BatchWorkers     *int           `envconfig:"MDDB_BATCH_WORKERS"`
+	Shards           *int           `envconfig:"MDDB_SHARDS"`
+	ShardReplicas    *int           `envconfig:"MDDB_SHARD_REPLICAS"`
+	CompactInterval  *time.Duration `envconfig:"MDDB_COMPACT_INTERVAL"`
+	CompactFreeRatio *float64       `envconfig:"MDDB_COMPACT_FREE_RATIO"`
+
+	BackupDir         *string        `envconfig:"MDDB_BACKUP_DIR"`
+	BackupInterval    *time.Duration `envconfig:"MDDB_BACKUP_INTERVAL"`
+	BackupCompression *string        `envconfig:"MDDB_BACKUP_COMPRESSION"`
+	BackupKeepHourly  *int           `envconfig:"MDDB_BACKUP_KEEP_HOURLY"`
+	BackupKeepDaily   *int           `envconfig:"MDDB_BACKUP_KEEP_DAILY"`
+	BackupKeepWeekly  *int           `envconfig:"MDDB_BACKUP_KEEP_WEEKLY"`
+
+	LogLevel *string `envconfig:"MDDB_LOG_LEVEL"`
+
+	RateLimit      *float64 `envconfig:"MDDB_RATE_LIMIT"`
+	RateLimitBurst *int     `envconfig:"MDDB_RATE_LIMIT_BURST"`
+
+	PostAddWebhookURL    *string   `envconfig:"MDDB_HOOK_POST_ADD_URL"`
+	PostAddExec          *[]string `envconfig:"MDDB_HOOK_POST_ADD_EXEC"`
+	PostUpdateWebhookURL *string   `envconfig:"MDDB_HOOK_POST_UPDATE_URL"`
+	PostUpdateExec       *[]string `envconfig:"MDDB_HOOK_POST_UPDATE_EXEC"`
+}
+
+// loadConfig loads the configuration from path and the environment. A missing file is
+// only an error when required, i.e. when the path was given explicitly.
+func loadConfig(path string, required bool) (*Config, error) {
+	cfg := defaultConfig()
+
+	// 1) YAML (optional)
+	if err := loadYAML(path, required, cfg); err != nil {
+		return nil, err
+	}
+
+	// 2) ENV (overrides YAML values)
+	if err := overrideFromEnv(cfg); err != nil {
+		return nil, err
+	}
+
+	// Defaults that depend on other settings
+	if cfg.Storage.CacheMB == nil {
+		mb := int64(64)
+		if cfg.Server.Extreme {
+			mb = 256
+		}
+		cfg.Storage.CacheMB = &mb
+	}
+	if cfg.Backups.Dir == "" {
+		cfg.Backups.Dir = filepath.Join(filepath.Dir(cfg.Storage.Path), "backups")
+	}
+	if cfg.RateLimit.Burst == 0 && cfg.RateLimit.RequestsPerSecond > 0 {
+		cfg.RateLimit.Burst = max(1, int(cfg.RateLimit.RequestsPerSecond))
+	}
+
+	if err := validateConfig(cfg); err != nil {
+		return nil, err
+	}
+	return cfg, nil
+}
+
+func defaultConfig() *Config {
+	return &Config{
+		Server: ServerConfig{
+			Mode:                ModeRW,
+			HTTPAddress:         ":11023",
+			GRPCAddress:         ":11024",
+			HTTP3Address:        ":11443",
+			RestoreDrainTimeout: 30 * time.Second,
+			ShutdownTimeout:     30 * time.Second,
+		},
+		TLS:  TLSConfig{ClientAuth: "require"},
+		Auth: AuthConfig{JWTRolesClaim: "roles"},
+		Storage: StorageConfig{
+			Path:            "mddb.db",
+			CacheTTL:        5 * time.Minute,
+			IndexQueueLimit: 10000,
+			BatchWorkers:    8,
+			Shards:          4,
+			ShardReplicas:   2,
+		},
+		Backups: BackupsConfig{
+			BackupSchedule: BackupSchedule{
+				Compression: storage.BackupCompressionZstd,
+				KeepHourly:  24,
+				KeepDaily:   7,
+				KeepWeekly:  4,
+			},
+		},
+		Log: LogConfig{Level: "info"},
+	}
+}
+
+func loadYAML(path string, required bool, cfg *Config) error {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) && !required {
+			warnf("⚠️  No config file %s, using the defaults and MDDB_* variables", path)
+			return nil
+		}
+		return fmt.Errorf("read config yaml: %w", err)
+	}
+
+	dec := yaml.NewDecoder(bytes.NewReader(data))
+	dec.KnownFields(true) // catch misspelled settings
+	if err := dec.Decode(cfg); err != nil && !errors.Is(err, io.EOF) {
+		return fmt.Errorf("unmarshal config yaml %s: %w", path, err)
+	}
+	return nil
+}
+
+// override sets *dst to *v when v is set
+func override[T any](dst *T, v *T) {
+	if v != nil {
+		*dst = *v
+	}
+}
+
+func overrideFromEnv(cfg *Config) error {
+	var e envConfig
+	if err := envconfig.Process("", &e); err != nil {
+		return fmt.Errorf("process env: %w", err)
+	}
+
+	override(&cfg.Server.Mode, e.Mode)
+	override(&cfg.Server.HTTPAddress, e.HTTPAddress)
+	override(&cfg.Server.GRPCAddress, e.GRPCAddress)
+	override(&cfg.Server.HTTP3Address, e.HTTP3Address)
+	override(&cfg.Server.Extreme, e.Extreme)
+	override(&cfg.Server.RestoreDrainTimeout, e.RestoreDrainTimeout)
+	override(&cfg.Server.ShutdownTimeout, e.ShutdownTimeout)
+	override(&cfg.Server.ShutdownDelay, e.ShutdownDelay)
+
+	override(&cfg.TLS.Cert, e.TLSCert)
+	override(&cfg.TLS.Key, e.TLSKey)
+	override(&cfg.TLS.ClientCA, e.TLSClientCA)
+	override(&cfg.TLS.ClientAuth, e.TLSClientAuth)
+
+	override(&cfg.Auth.Enabled, e.Auth)
+	override(&cfg.Auth.AdminKey, e.AuthAdminKey)
+	override(&cfg.Auth.JWTHMACSecret, e.JWTHMACSecret)
+	override(&cfg.Auth.JWTRSAPublicKey, e.JWTRSAPublicKey)
+	override(&cfg.Auth.JWTIssuer, e.JWTIssuer)
+	override(&cfg.Auth.JWTAudience, e.JWTAudience)
+	override(&cfg.Auth.JWTRolesClaim, e.JWTRolesClaim)
+
+	override(&cfg.Storage.Path, e.Path)
+	if e.CacheMB != nil {
+		cfg.Storage.CacheMB = e.CacheMB
+	}
+	override(&cfg.Storage.CacheTTL, e.CacheTTL)
+	override(&cfg.Storage.IndexQueueLimit, e.IndexQueueLimit)
+	override(&cfg.Storage.BatchWorkers, e.BatchWorkers)
+	override(&cfg.Storage.Shards, e.Shards)
+	override(&cfg.Storage.ShardReplicas, e.ShardReplicas)
+	override(&cfg.Storage.CompactInterval, e.CompactInterval)
+	override(&cfg.Storage.CompactFreeRatio, e.CompactFreeRatio)
+
+	override(&cfg.Backups.Dir, e.BackupDir)
+	override(&cfg.Backups.Interval, e.BackupInterval)
+	override(&cfg.Backups.Compression, e.BackupCompression)
+	override(&cfg.Backups.KeepHourly, e.BackupKeepHourly)
+	override(&cfg.Backups.KeepDaily, e.BackupKeepDaily)
+	override(&cfg.Backups.KeepWeekly, e.BackupKeepWeekly)
+
+	override(&cfg.Log.Level, e.LogLevel)
+
+	override(&cfg.RateLimit.RequestsPerSecond, e.RateLimit)
+	override(&cfg.RateLimit.Burst, e.RateLimitBurst)
+
+	override(&cfg.Hooks.PostAddWebhookURL, e.PostAddWebhookURL)
+	override(&cfg.Hooks.PostAddExec, e.PostAddExec)
+	override(&cfg.Hooks.PostUpdateWebhookURL, e.PostUpdateWebhookURL)
+	override(&cfg.Hooks.PostUpdateExec, e.PostUpdateExec)
+
+	return nil
+}
+
+// validateConfig reports every invalid setting at once
+func validateConfig(cfg *Config) error {
+	var errs []error
+	check := func(ok bool, format string, args ...any) {
+		if !ok {
+			errs = append(errs, fmt.Errorf(format, args...))
+		}
+	}
+
+	sc := cfg.Server
+	check(sc.Mode == ModeRead || sc.Mode == ModeWrite || sc.Mode == ModeRW, "server.mode: want read, write or wr, got %q", sc.Mode)
+	check(sc.HTTPAddress != "", "server.httpAddress is required")
+	check(sc.GRPCAddress != "", "server.grpcAddress is required")
+	check(!sc.Extreme || sc.HTTP3Address != "", "server.http3Address is required in extreme mode")
+	check(sc.RestoreDrainTimeout > 0, "server.restoreDrainTimeout must be positive")
+	check(sc.ShutdownTimeout > 0, "server.shutdownTimeout must be positive")
+	check(sc.ShutdownDelay >= 0, "server.shutdownDelay must not be negative")
+
+	tc := cfg.TLS
+	check((tc.Cert == "") == (tc.Key == ""), "tls.cert and tls.key must be set together")
+	check(tc.ClientCA == "" || tc.Cert != "", "tls.clientCA needs tls.cert and tls.key")
+	check(tc.ClientAuth == "require" || tc.ClientAuth == "optional", "tls.clientAuth: want require or optional, got %q", tc.ClientAuth)
+
+	check(!cfg.Auth.Enabled || cfg.Auth.JWTRolesClaim != "", "auth.jwtRolesClaim is required with auth enabled")
+
+	st := cfg.Storage
+	check(st.Path != "", "storage.path is required")
+	check(*st.CacheMB >= 0, "storage.cacheMB must not be negative")
+	check(st.CacheTTL > 0, "storage.cacheTTL must be positive")
+	check(st.IndexQueueLimit > 0, "storage.indexQueueLimit must be positive")
+	check(st.BatchWorkers > 0, "storage.batchWorkers must be positive")
+	check(st.Shards > 0, "storage.shards must be positive")
+	check(st.ShardReplicas > 0 && st.ShardReplicas <= st.Shards, "storage.shardReplicas: want 1 to %d (storage.shards), got %d", st.Shards, st.ShardReplicas)
+	check(st.CompactInterval >= 0, "storage.compactInterval must not be negative")
+	check(st.CompactFreeRatio >= 0 && st.CompactFreeRatio < 1, "storage.compactFreeRatio: want a fraction between 0 and 1, got %g", st.CompactFreeRatio)
+
+	b := cfg.Backups
+	check(b.Interval >= 0, "backups.interval must not be negative")
+	if _, err := storage.BackupCompression(b.Compression); err != nil {
+		errs = append(errs, fmt.Errorf("backups.compression: %w", err))
+	}
+	check(b.KeepHourly >= 0 && b.KeepDaily >= 0 && b.KeepWeekly >= 0, "backups.keepHourly, keepDaily and keepWeekly must not be negative")
+
+	_, known := logLevels[cfg.Log.Level]
+	check(known, "log.level: want debug, info, warn or error, got %q", cfg.Log.Level)
+
+	check(cfg.RateLimit.RequestsPerSecond >= 0, "rateLimit.requestsPerSecond must not be negative")
+	check(cfg.RateLimit.Burst >= 0, "rateLimit.burst must not be negative")
+
+	for name, u := range map[string]string{
+		"hooks.postAddWebhookUrl":    cfg.Hooks.PostAddWebhookURL,
+		"hooks.postUpdateWebhookUrl": cfg.Hooks.PostUpdateWebhookURL,
+	} {
+		if u == "" {
+			continue
+		}
+		parsed, err := url.Parse(u)
+		check(err == nil && (parsed.Scheme == "http" || parsed.Scheme == "https") && parsed.Host != "", "%s: want an http or https URL, got %q", name, u)
+	}
+	for name, argv := range map[string][]string{
+		"hooks.postAddExec":    cfg.Hooks.PostAddExec,
+		"hooks.postUpdateExec": cfg.Hooks.PostUpdateExec,
+	} {
+		check(len(argv) == 0 || argv[0] != "", "%s: the command must not be empty", name)
+	}
+
+	if len(errs) > 0 {
+		return fmt.Errorf("invalid config: %w", errors.Join(errs...))
+	}
+	return nil
+}
+
+// writeConfig prints cfg as YAML, the format of the config file
+func writeConfig(w io.Writer, cfg *Config) error {
+	enc := yaml.NewEncoder(w)
+	enc.SetIndent(2)
+	if err := enc.Encode(cfg); err != nil {
+		return err
+	}
+	return enc.Close()
+}
+
+// storageOptions translates the storage settings for storage.Open
+func (c *Config) storageOptions() *storage.Options {
+	opts := &storage.Options{
+		Extreme:          c.Server.Extreme,
+		CacheBytes:       c.cacheBytes(),
+		CacheTTL:         c.Storage.CacheTTL,
+		IndexQueueLimit:  c.Storage.IndexQueueLimit,
+		BatchWorkers:     c.Storage.BatchWorkers,
+		Shards:           c.Storage.Shards,
+		ShardReplicas:    c.Storage.ShardReplicas,
+		CompactInterval:  c.Storage.CompactInterval,
+		CompactFreeRatio: c.Storage.CompactFreeRatio,
+	}
+	if c.Backups.Interval > 0 {
+		// Archive every change between scheduled backups for point-in-time restores
+		opts.ChangeLogDir = filepath.Join(c.Backups.Dir, changeLogDirName)
+	}
+	return opts
+}
+
+// cacheBytes is the cache budget for storage; negative disables the cache
+func (c *Config) cacheBytes() int64 {
+	if *c.Storage.CacheMB == 0 {
+		return -1
+	}
+	return *c.Storage.CacheMB << 20
+}
+
+// applyReloadable applies the settings that can change while the server runs
+func (s *Server) applyReloadable(cfg *Config) {
+	setLogLevel(cfg.Log.Level)
+	s.DB.ResizeCache(cfg.cacheBytes(), cfg.Storage.CacheTTL)
+	s.limiter.configure(cfg.RateLimit)
+	hooks := cfg.Hooks
+	s.hooks.Store(&hooks)
+}
+
+// withReloadable returns c with the reloadable settings taken from next
+func (c Config) withReloadable(next *Config) Config {
+	c.Storage.CacheMB, c.Storage.CacheTTL = next.Storage.CacheMB, next.Storage.CacheTTL
+	c.Log, c.RateLimit, c.Hooks = next.Log, next.RateLimit, next.Hooks
+	return c
+}
+
+// reload re-reads the configuration on SIGHUP. An invalid configuration is rejected as a
+// whole and the running one is kept.
+func (s *Server) reload() {
+	cfg, err := s.loadConfig()
+	if err != nil {
+		errorf("Config reload failed, keeping the current config: %v", err)
+		return
+	}
+	s.applyReloadable(cfg)
+	running := s.config.withReloadable(cfg)
+	for _, section := range []struct {
+		name          string
+		running, next any
+	}{
+		{"server", running.Server, cfg.Server},
+		{"tls", running.TLS, cfg.TLS},
+		{"auth", running.Auth, cfg.Auth},
+		{"storage", running.Storage, cfg.Storage},
+		{"backups", running.Backups, cfg.Backups},
+	} {
+		if !reflect.DeepEqual(section.running, section.next) {
+			warnf("⚠️  Changes to %s settings apply after a restart", section.name)
+		}
+	}
+	s.config = &running
+	infof("Config reloaded (log level %s, cache %d MiB, rate limit %g/s)", cfg.Log.Level, *cfg.Storage.CacheMB, cfg.RateLimit.RequestsPerSecond)
+}
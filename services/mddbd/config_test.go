@@ -0,0 +1,253 @@
+package main
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/tradik/mddb/services/mddbd/storage"
+)
+
+// writeYAML writes a config file into a temporary directory
+func writeYAML(t *testing.T, yaml string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "mddbd.yaml")
+	if err := os.WriteFile(path, []byte(yaml), 0o600); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestLoadConfigLayers(t *testing.T) {
+	const yaml = `
+server:
+  extreme: true
+storage:
+  path: /data/mddb.db
+log:
+  level: debug
+rateLimit:
+  requestsPerSecond: 2.5
+`
+	cases := []struct {
+		name  string
+		yaml  string
+		env   map[string]string
+		check func(*Config) string
+	}{
+		{
+			name: "defaults",
+			check: func(c *Config) string {
+				if c.Storage.Path != "mddb.db" || *c.Storage.CacheMB != 64 || c.Backups.Dir != "backups" || c.Log.Level != "info" {
+					return "want the defaults"
+				}
+				return ""
+			},
+		},
+		{
+			name: "yaml",
+			yaml: yaml,
+			check: func(c *Config) string {
+				// Extreme mode raises the default cache; backups default to the database directory
+				if !c.Server.Extreme || *c.Storage.CacheMB != 256 || c.Backups.Dir != "/data/backups" || c.Log.Level != "debug" {
+					return "want the file's settings and the defaults that follow from them"
+				}
+				if c.RateLimit.Burst != 2 {
+					return "want a burst of one second's worth of requests"
+				}
+				return ""
+			},
+		},
+		{
+			name: "env over yaml",
+			yaml: yaml,
+			env:  map[string]string{"MDDB_LOG_LEVEL": "warn", "MDDB_CACHE_MB": "0", "MDDB_PATH": "/srv/mddb.db", "MDDB_HOOK_POST_ADD_EXEC": "/bin/notify,add"},
+			check: func(c *Config) string {
+				if c.Log.Level != "warn" || c.cacheBytes() != -1 || c.Storage.Path != "/srv/mddb.db" || c.Backups.Dir != "/srv/backups" {
+					return "want the variables to win"
+				}
+				if !c.Server.Extreme || c.RateLimit.RequestsPerSecond != 2.5 {
+					return "want the file's other settings kept"
+				}
+				if strings.Join(c.Hooks.PostAddExec, " ") != "/bin/notify add" {
+					return "want the hook command split on commas"
+				}
+				return ""
+			},
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			for k, v := range c.env {
+				t.Setenv(k, v)
+			}
+			path := filepath.Join(t.TempDir(), "missing.yaml")
+			if c.yaml != "" {
+				path = writeYAML(t, c.yaml)
+			}
+			cfg, err := loadConfig(path, false)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if msg := c.check(cfg); msg != "" {
+				t.Errorf("%s, got %+v", msg, cfg)
+			}
+		})
+	}
+
+	if _, err := loadConfig(filepath.Join(t.TempDir(), "missing.yaml"), true); err == nil {
+		t.Error("a missing config file given explicitly loaded without an error")
+	}
+}
+
+func TestLoadConfigValidation(t *testing.T) {
+	cases := []struct {
+		name string
+		yaml string
+		env  map[string]string
+		errs []string
+	}{
+		{name: "unknown setting", yaml: "storage:\n  cachMB: 10\n", errs: []string{"field cachMB not found"}},
+		{name: "bad duration", yaml: "storage:\n  cacheTTL: soon\n", errs: []string{"line 2: cannot unmarshal"}},
+		{name: "bad env", env: map[string]string{"MDDB_CACHE_MB": "lots"}, errs: []string{"MDDB_CACHE_MB"}},
+		{
+			name: "every problem at once",
+			yaml: `
+server:
+  mode: rw
+tls:
+  cert: server.pem
+storage:
+  shards: 2
+  shardReplicas: 3
+  compactFreeRatio: 1.5
+log:
+  level: verbose
+hooks:
+  postAddWebhookUrl: localhost:9000/hook
+`,
+			errs: []string{
+				`server.mode: want read, write or wr, got "rw"`,
+				"tls.cert and tls.key must be set together",
+				"storage.shardReplicas: want 1 to 2 (storage.shards), got 3",
+				"storage.compactFreeRatio: want a fraction between 0 and 1, got 1.5",
+				`log.level: want debug, info, warn or error, got "verbose"`,
+				`hooks.postAddWebhookUrl: want an http or https URL, got "localhost:9000/hook"`,
+			},
+		},
+		{name: "extreme without http3", yaml: "server:\n  extreme: true\n  http3Address: \"\"\n", errs: []string{"server.http3Address is required in extreme mode"}},
+		{name: "bad compression", env: map[string]string{"MDDB_BACKUP_COMPRESSION": "rar"}, errs: []string{"backups.compression"}},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			for k, v := range c.env {
+				t.Setenv(k, v)
+			}
+			_, err := loadConfig(writeYAML(t, c.yaml), true)
+			if err == nil {
+				t.Fatal("loaded without an error")
+			}
+			for _, want := range c.errs {
+				if !strings.Contains(err.Error(), want) {
+					t.Errorf("error %q does not mention %q", err, want)
+				}
+			}
+		})
+	}
+}
+
+func TestPrintConfig(t *testing.T) {
+	t.Setenv("MDDB_AUTH_ADMIN_KEY", "admin-s3cret")
+	path := writeYAML(t, "auth:\n  enabled: true\n  jwtHmacSecret: jwt-s3cret\nbackups:\n  interval: 1h\n")
+	cfg, err := loadConfig(path, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var out bytes.Buffer
+	if err := writeConfig(&out, cfg); err != nil {
+		t.Fatal(err)
+	}
+	if strings.Contains(out.String(), "s3cret") || strings.Count(out.String(), "<redacted>") != 2 {
+		t.Errorf("secrets are not redacted:\n%s", out.String())
+	}
+
+	// The output is a config file that loads to the same settings
+	printed, err := loadConfig(writeYAML(t, out.String()), true)
+	if err != nil {
+		t.Fatalf("loading the printed config: %v", err)
+	}
+	printed.Auth.JWTHMACSecret = cfg.Auth.JWTHMACSecret
+	var again bytes.Buffer
+	if err := writeConfig(&again, printed); err != nil {
+		t.Fatal(err)
+	}
+	if again.String() != out.String() {
+		t.Errorf("printed config loads differently:\n%s\nwant\n%s", again.String(), out.String())
+	}
+}
+
+func TestReloadOnSIGHUP(t *testing.T) {
+	t.Cleanup(func() { setLogLevel("info") })
+	path := writeYAML(t, "log:\n  level: info\n")
+	load := func() (*Config, error) { return loadConfig(path, true) }
+	cfg, err := load()
+	if err != nil {
+		t.Fatal(err)
+	}
+	db, err := storage.Open(filepath.Join(t.TempDir(), "mddb.db"), cfg.storageOptions())
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := &Server{
+		DB:              db,
+		Mode:            ModeRW,
+		Metrics:         NewMetrics(db),
+		ShutdownTimeout: 10 * time.Second,
+		config:          cfg,
+		loadConfig:      load,
+		backupNow:       make(chan struct{}, 1),
+		stopping:        make(chan struct{}),
+	}
+	s.applyReloadable(cfg)
+	ran := make(chan error, 1)
+	go func() { ran <- s.run(nil) }()
+	waitFor(t, "the server to get ready", s.ready.Load)
+
+	hup := func(yaml string) {
+		t.Helper()
+		if err := os.WriteFile(path, []byte(yaml), 0o600); err != nil {
+			t.Fatal(err)
+		}
+		if err := syscall.Kill(os.Getpid(), syscall.SIGHUP); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// Reloadable settings apply; storage.path needs a restart and is only warned about
+	hup("log:\n  level: error\nhooks:\n  postAddWebhookUrl: http://localhost:9000/hook\nstorage:\n  path: elsewhere.db\n")
+	waitFor(t, "the hooks to reload", func() bool { h := s.hooks.Load(); return h != nil && h.PostAddWebhookURL != "" })
+	if logEnabled(levelWarn) {
+		t.Error("log level not reloaded")
+	}
+
+	// An invalid file is rejected as a whole
+	hup("log:\n  level: debug\nhooks:\n  postAddWebhookUrl: not a url\n")
+	time.Sleep(100 * time.Millisecond)
+	if logEnabled(levelWarn) || s.hooks.Load().PostAddWebhookURL != "http://localhost:9000/hook" {
+		t.Error("an invalid config was applied")
+	}
+
+	if err := syscall.Kill(os.Getpid(), syscall.SIGTERM); err != nil {
+		t.Fatal(err)
+	}
+	if err := <-ran; err != nil {
+		t.Fatal(err)
+	}
+	if s.config.Storage.Path == "elsewhere.db" {
+		t.Error("storage.path changed without a restart")
+	}
+}
@@ -0,0 +1,221 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/tradik/mddb/services/mddbd/proto"
+	"github.com/tradik/mddb/services/mddbd/storage"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// errRename rejects a rename that isn't from=to
+var errRename = errors.New("rename must be from=to")
+
+// parseRenames parses from=to pairs
+func parseRenames(pairs []string) (map[string]string, error) {
+	if len(pairs) == 0 {
+		return nil, nil
+	}
+	rename := make(map[string]string, len(pairs))
+	for _, p := range pairs {
+		from, to, found := strings.Cut(p, "=")
+		if !found || from == "" || to == "" {
+			return nil, fmt.Errorf("%w: %q", errRename, p)
+		}
+		rename[from] = to
+	}
+	return rename, nil
+}
+
+func logLoad(res *storage.LoadResult) {
+	for _, c := range res.Collections {
+		infof("Loaded collection %s from dump collection %s (%d documents, %d revisions, replaced %t)",
+			c.Name, c.From, c.Documents, c.Revisions, c.Replaced)
+	}
+}
+
+// --- HTTP handlers
+
+// handleDump streams a logical dump of the collections given as collection parameters
+// (default all). Counts follow the body as HTTP trailers; a failure mid-stream aborts
+// the connection so the download can't pass for complete.
+func (s *Server) handleDump(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	compression, err := storage.BackupCompression(q.Get("compression"))
+	if err != nil {
+		bad(w, err)
+		return
+	}
+	opts := storage.DumpOptions{Collections: q["collection"], Compression: compression}
+	if v := q.Get("revisions"); v != "" {
+		withRevisions, err := strconv.ParseBool(v)
+		if err != nil {
+			bad(w, fmt.Errorf("invalid revisions: %w", err))
+			return
+		}
+		opts.SkipRevisions = !withRevisions
+	}
+	for _, name := range opts.Collections {
+		if s.DB.Collections.Get(name) == nil {
+			writeError(w, fmt.Errorf("%w: %s", storage.ErrCollectionNotFound, name), codes.NotFound)
+			return
+		}
+	}
+
+	contentType, ext := "application/x-tar", ".tar"
+	if compression == storage.BackupCompressionZstd {
+		contentType, ext = "application/zstd", ".tar.zst"
+	}
+	h := w.Header()
+	h.Set("Content-Type", contentType)
+	h.Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", fmt.Sprintf("mddb-dump-%d%s", time.Now().Unix(), ext)))
+	h.Set("X-Dump-Format", storage.DumpFormat)
+	h.Set("X-Dump-Version", strconv.Itoa(storage.DumpVersion))
+	h.Set("Trailer", "X-Dump-Collections, X-Dump-Documents, X-Dump-Revisions, X-Dump-Tx-Id, X-Dump-Created-At")
+
+	m, err := s.DB.Dump(w, opts)
+	if err != nil {
+		errorf("Dump stream failed: %v", err)
+		panic(http.ErrAbortHandler)
+	}
+	var names []string
+	var docs, revs int
+	for _, c := range m.Collections {
+		names = append(names, c.Name)
+		docs += c.Documents
+		revs += c.Revisions
+	}
+	h.Set("X-Dump-Collections", strings.Join(names, ","))
+	h.Set("X-Dump-Documents", strconv.Itoa(docs))
+	h.Set("X-Dump-Revisions", strconv.Itoa(revs))
+	h.Set("X-Dump-Tx-Id", strconv.Itoa(m.TxID))
+	h.Set("X-Dump-Created-At", strconv.FormatInt(m.CreatedAt, 10))
+}
+
+// handleLoad loads the dump in the request body, plain or zstd-compressed
+func (s *Server) handleLoad(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	rename, err := parseRenames(q["rename"])
+	if err != nil {
+		bad(w, err)
+		return
+	}
+	opts := storage.LoadOptions{Collections: q["collection"], Rename: rename}
+	if v := q.Get("replace"); v != "" {
+		if opts.Replace, err = strconv.ParseBool(v); err != nil {
+			bad(w, fmt.Errorf("invalid replace: %w", err))
+			return
+		}
+	}
+	res, err := s.DB.Load(r.Body, opts)
+	if err != nil {
+		writeError(w, err, codes.Internal)
+		return
+	}
+	logLoad(res)
+	ok(w, res)
+}
+
+// --- gRPC
+
+// Dump implements the Dump RPC (streaming)
+func (g *GRPCServer) Dump(req *proto.DumpRequest, stream proto.MDDB_DumpServer) error {
+	for _, name := range req.Collections {
+		if g.server.DB.Collections.Get(name) == nil {
+			return grpcError(fmt.Errorf("%w: %s", storage.ErrCollectionNotFound, name), codes.NotFound)
+		}
+	}
+	var manifest *proto.DumpManifest
+	cw := newChunkWriter(stream.Context(), func(data []byte, last bool) error {
+		chunk := &proto.DumpChunk{Data: data}
+		if last {
+			chunk.Manifest = manifest
+		}
+		return stream.Send(chunk)
+	})
+	m, err := g.server.DB.Dump(cw, storage.DumpOptions{
+		Collections:   req.Collections,
+		Compression:   req.Compression,
+		SkipRevisions: req.SkipRevisions,
+	})
+	if err != nil {
+		if stream.Context().Err() != nil {
+			return status.FromContextError(stream.Context().Err()).Err()
+		}
+		return grpcError(err, codes.Internal)
+	}
+	manifest = &proto.DumpManifest{Format: m.Format, Version: int32(m.Version), CreatedAt: m.CreatedAt, TxId: int64(m.TxID)}
+	for _, c := range m.Collections {
+		manifest.Collections = append(manifest.Collections, &proto.DumpedCollection{
+			Name:      c.Name,
+			Documents: int64(c.Documents),
+			Revisions: int64(c.Revisions),
+		})
+	}
+	return cw.flush(true)
+}
+
+// Load implements the Load RPC (client streaming)
+func (g *GRPCServer) Load(stream proto.MDDB_LoadServer) error {
+	if g.server.Mode == ModeRead {
+		return status.Error(codes.PermissionDenied, "read-only mode")
+	}
+	first, err := stream.Recv()
+	if err != nil {
+		return err
+	}
+
+	// Feed uploaded chunks to the load as they arrive
+	pr, pw := io.Pipe()
+	go func() {
+		if _, err := pw.Write(first.Data); err != nil {
+			return
+		}
+		for {
+			chunk, err := stream.Recv()
+			if err == io.EOF {
+				_ = pw.Close()
+				return
+			}
+			if err != nil {
+				_ = pw.CloseWithError(err)
+				return
+			}
+			if _, err := pw.Write(chunk.Data); err != nil {
+				return
+			}
+		}
+	}()
+
+	res, err := g.server.DB.Load(pr, storage.LoadOptions{
+		Collections: first.Collections,
+		Rename:      first.Rename,
+		Replace:     first.Replace,
+	})
+	_ = pr.CloseWithError(io.ErrClosedPipe)
+	if err != nil {
+		if stream.Context().Err() != nil {
+			return status.FromContextError(stream.Context().Err()).Err()
+		}
+		return grpcError(err, codes.Internal)
+	}
+	logLoad(res)
+	resp := &proto.LoadResponse{Format: res.Format, Version: int32(res.Version), DurationMs: res.DurationMs}
+	for _, c := range res.Collections {
+		resp.Collections = append(resp.Collections, &proto.LoadedCollection{
+			From:      c.From,
+			Name:      c.Name,
+			Documents: int64(c.Documents),
+			Revisions: int64(c.Revisions),
+			Replaced:  c.Replaced,
+		})
+	}
+	return stream.SendAndClose(resp)
+}
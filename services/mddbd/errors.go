@@ -0,0 +1,168 @@
+package main
+
+import (
+	"errors"
+	"net/http"
+	"strconv"
+	"strings"
+
+	json "github.com/goccy/go-json"
+	"github.com/tradik/mddb/services/mddbd/storage"
+	bolt "go.etcd.io/bbolt"
+	"google.golang.org/genproto/googleapis/rpc/code"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// errorDomain identifies mddb in gRPC ErrorInfo details
+const errorDomain = "mddb"
+
+// Machine-readable error reasons, shared by the HTTP error body and gRPC ErrorInfo details
+const (
+	ReasonBadRequest         = "BAD_REQUEST"
+	ReasonInternal           = "INTERNAL"
+	ReasonReadOnly           = "READ_ONLY"
+	ReasonDocumentNotFound   = "DOCUMENT_NOT_FOUND"
+	ReasonDocumentExists     = "DOCUMENT_EXISTS"
+	ReasonCollectionNotFound = "COLLECTION_NOT_FOUND"
+	ReasonCollectionExists   = "COLLECTION_EXISTS"
+	ReasonSchemaNotFound     = "SCHEMA_NOT_FOUND"
+	ReasonSchemaViolation    = "SCHEMA_VIOLATION"
+	ReasonPreconditionFailed = "PRECONDITION_FAILED"
+	ReasonPayloadTooLarge    = "PAYLOAD_TOO_LARGE"
+	ReasonUnavailable        = "UNAVAILABLE"
+	ReasonUnauthenticated    = "UNAUTHENTICATED"
+	ReasonPermissionDenied   = "PERMISSION_DENIED"
+	ReasonAPIKeyNotFound     = "API_KEY_NOT_FOUND"
+	ReasonCompactionRunning  = "COMPACTION_RUNNING"
+	ReasonCompactionAborted  = "COMPACTION_ABORTED"
+	ReasonBackupNotFound     = "BACKUP_NOT_FOUND"
+	ReasonInvalidBackup      = "INVALID_BACKUP"
+	ReasonNoRecoveryPoint    = "RECOVERY_POINT_UNAVAILABLE"
+	ReasonRateLimited        = "RATE_LIMITED"
+)
+
+var (
+	errSchemaNotFound     = errors.New("schema not found")
+	errPreconditionFailed = errors.New("precondition failed")
+)
+
+// APIError is the structured error of both APIs. Over HTTP it is the JSON body; over gRPC
+// the same code, reason and metadata are carried by the status and an ErrorInfo detail.
+// The message stays under "error" so clients of the older {"error": "..."} body keep working.
+type APIError struct {
+	Message  string            `json:"error"`
+	Code     string            `json:"code"`   // canonical gRPC code name, e.g. NOT_FOUND
+	Status   int               `json:"status"` // HTTP status
+	Reason   string            `json:"reason"`
+	Metadata map[string]string `json:"metadata,omitempty"`
+	grpcCode codes.Code
+}
+
+func newAPIError(httpStatus int, c codes.Code, reason, msg string, metadata map[string]string) *APIError {
+	return &APIError{
+		Message:  msg,
+		Code:     code.Code_name[int32(c)],
+		Status:   httpStatus,
+		Reason:   reason,
+		Metadata: metadata,
+		grpcCode: c,
+	}
+}
+
+func (e *APIError) Error() string { return e.Message }
+
+// GRPCStatus makes APIError usable as a gRPC error (status.FromError / status.Convert)
+func (e *APIError) GRPCStatus() *status.Status {
+	st := status.New(e.grpcCode, e.Message)
+	if d, err := st.WithDetails(&errdetails.ErrorInfo{Reason: e.Reason, Domain: errorDomain, Metadata: e.Metadata}); err == nil {
+		return d
+	}
+	return st
+}
+
+// toAPIError classifies err. Unknown errors get the fallback code: bad requests in the
+// legacy handlers, internal errors where the input has already been validated.
+func toAPIError(err error, fallback codes.Code) *APIError {
+	var apiErr *APIError
+	var verr *storage.ValidationError
+	var maxErr *http.MaxBytesError
+	var txErr *storage.TxError
+	switch {
+	case errors.As(err, &txErr):
+		// Report the failing operation along with the classification of its error
+		inner := *toAPIError(txErr.Err, fallback)
+		metadata := map[string]string{"op_index": strconv.Itoa(txErr.Index)}
+		if txErr.Op != "" {
+			metadata["op"] = txErr.Op
+		}
+		for k, v := range inner.Metadata {
+			metadata[k] = v
+		}
+		inner.Message, inner.Metadata = txErr.Error(), metadata
+		return &inner
+	case errors.As(err, &apiErr):
+		return apiErr
+	case errors.Is(err, errUnauthenticated):
+		return newAPIError(http.StatusUnauthorized, codes.Unauthenticated, ReasonUnauthenticated, err.Error(), nil)
+	case errors.Is(err, errPermissionDenied):
+		return newAPIError(http.StatusForbidden, codes.PermissionDenied, ReasonPermissionDenied, err.Error(), nil)
+	case errors.Is(err, storage.ErrAPIKeyNotFound):
+		return newAPIError(http.StatusNotFound, codes.NotFound, ReasonAPIKeyNotFound, err.Error(), nil)
+	case errors.Is(err, storage.ErrNotFound):
+		return newAPIError(http.StatusNotFound, codes.NotFound, ReasonDocumentNotFound, err.Error(), nil)
+	case errors.Is(err, storage.ErrCollectionNotFound):
+		return newAPIError(http.StatusNotFound, codes.NotFound, ReasonCollectionNotFound, err.Error(), nil)
+	case errors.Is(err, errSchemaNotFound):
+		return newAPIError(http.StatusNotFound, codes.NotFound, ReasonSchemaNotFound, err.Error(), nil)
+	case errors.Is(err, errBackupNotFound), errors.Is(err, storage.ErrNoPreviousFile):
+		return newAPIError(http.StatusNotFound, codes.NotFound, ReasonBackupNotFound, err.Error(), nil)
+	case errors.Is(err, storage.ErrInvalidBackup), errors.Is(err, storage.ErrInvalidDump):
+		return newAPIError(http.StatusUnprocessableEntity, codes.InvalidArgument, ReasonInvalidBackup, err.Error(), nil)
+	case errors.Is(err, storage.ErrNoRecoveryPoint):
+		return newAPIError(http.StatusUnprocessableEntity, codes.FailedPrecondition, ReasonNoRecoveryPoint, err.Error(), nil)
+	case errors.Is(err, storage.ErrCollectionExists):
+		return newAPIError(http.StatusConflict, codes.AlreadyExists, ReasonCollectionExists, err.Error(), nil)
+	case errors.Is(err, storage.ErrImportConflict):
+		return newAPIError(http.StatusConflict, codes.AlreadyExists, ReasonDocumentExists, err.Error(), nil)
+	case errors.Is(err, storage.ErrCompactionRunning):
+		return newAPIError(http.StatusConflict, codes.Aborted, ReasonCompactionRunning, err.Error(), nil)
+	case errors.Is(err, storage.ErrCompactionAborted):
+		return newAPIError(http.StatusConflict, codes.Aborted, ReasonCompactionAborted, err.Error(), nil)
+	case errors.Is(err, errPreconditionFailed), errors.Is(err, storage.ErrPreconditionFailed):
+		return newAPIError(http.StatusPreconditionFailed, codes.FailedPrecondition, ReasonPreconditionFailed, err.Error(), nil)
+	case errors.As(err, &verr):
+		return newAPIError(http.StatusUnprocessableEntity, codes.InvalidArgument, ReasonSchemaViolation, err.Error(), map[string]string{
+			"key":      verr.Key,
+			"lang":     verr.Lang,
+			"problems": strings.Join(verr.Problems, "; "),
+		})
+	case errors.Is(err, storage.ErrMissingFields), errors.Is(err, storage.ErrUnsupportedFormat), errors.Is(err, storage.ErrInvalidPatch), errors.Is(err, storage.ErrInvalidTransaction), errors.Is(err, storage.ErrInvalidQuery), errors.Is(err, errBackupName), errors.Is(err, errRollbackAt), errors.Is(err, errRename):
+		return newAPIError(http.StatusBadRequest, codes.InvalidArgument, ReasonBadRequest, err.Error(), nil)
+	case errors.Is(err, errRateLimited):
+		return newAPIError(http.StatusTooManyRequests, codes.ResourceExhausted, ReasonRateLimited, err.Error(), nil)
+	case errors.As(err, &maxErr), errors.Is(err, storage.ErrImportTooLarge):
+		return newAPIError(http.StatusRequestEntityTooLarge, codes.ResourceExhausted, ReasonPayloadTooLarge, err.Error(), nil)
+	case errors.Is(err, bolt.ErrDatabaseNotOpen), errors.Is(err, bolt.ErrTimeout), errors.Is(err, errMaintenance), errors.Is(err, errShuttingDown):
+		return newAPIError(http.StatusServiceUnavailable, codes.Unavailable, ReasonUnavailable, err.Error(), nil)
+	case fallback == codes.Internal:
+		return newAPIError(http.StatusInternalServerError, codes.Internal, ReasonInternal, err.Error(), nil)
+	default:
+		return newAPIError(http.StatusBadRequest, codes.InvalidArgument, ReasonBadRequest, err.Error(), nil)
+	}
+}
+
+// grpcError converts err into a gRPC status error carrying ErrorInfo details
+func grpcError(err error, fallback codes.Code) error {
+	return toAPIError(err, fallback).GRPCStatus().Err()
+}
+
+// writeError writes err as a structured JSON error with its HTTP status
+func writeError(w http.ResponseWriter, err error, fallback codes.Code) {
+	apiErr := toAPIError(err, fallback)
+	b, _ := json.Marshal(apiErr)
+	w.Header().Set("Content-Type", "application/json; charset=utf-8")
+	w.WriteHeader(apiErr.Status)
+	_, _ = w.Write(b)
+}
@@ -0,0 +1,86 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+
+	json "github.com/goccy/go-json"
+	"github.com/tradik/mddb/services/mddbd/storage"
+)
+
+// exportChunkSize is the payload size of a single gRPC ExportChunk or BackupChunk
+const exportChunkSize = 64 * 1024
+
+// chunkWriter buffers export and backup output and sends it as stream messages.
+// Sending blocks under gRPC flow control, which throttles the writer to the client's pace.
+type chunkWriter struct {
+	ctx  context.Context
+	send func(data []byte, last bool) error
+	buf  []byte
+}
+
+func newChunkWriter(ctx context.Context, send func(data []byte, last bool) error) *chunkWriter {
+	return &chunkWriter{ctx: ctx, send: send, buf: make([]byte, 0, exportChunkSize)}
+}
+
+func (cw *chunkWriter) Write(p []byte) (int, error) {
+	if err := cw.ctx.Err(); err != nil {
+		return 0, err
+	}
+	n := len(p)
+	for len(p) > 0 {
+		space := exportChunkSize - len(cw.buf)
+		if space > len(p) {
+			space = len(p)
+		}
+		cw.buf = append(cw.buf, p[:space]...)
+		p = p[space:]
+		if len(cw.buf) == exportChunkSize {
+			if err := cw.flush(false); err != nil {
+				return 0, err
+			}
+		}
+	}
+	return n, nil
+}
+
+func (cw *chunkWriter) flush(last bool) error {
+	if err := cw.send(cw.buf, last); err != nil {
+		return err
+	}
+	cw.buf = make([]byte, 0, exportChunkSize)
+	return nil
+}
+
+// --- HTTP handler
+
+func (s *Server) handleExport(w http.ResponseWriter, r *http.Request) {
+	var req ExportRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		bad(w, err)
+		return
+	}
+	if req.Format == "" {
+		req.Format = "ndjson"
+	}
+	if req.Collection == "" {
+		bad(w, errors.New("missing collection"))
+		return
+	}
+	contentType, found := storage.ExportContentTypes[req.Format]
+	if !found {
+		bad(w, storage.ErrUnsupportedFormat)
+		return
+	}
+
+	w.Header().Set("Content-Type", contentType)
+	if req.Format != "ndjson" {
+		w.Header().Set("Content-Disposition", fmt.Sprintf("attachment; filename=%q", safe(req.Collection)+"."+req.Format))
+	}
+	// The status line is already sent once streaming starts, so late errors can only be logged
+	if err := s.DB.Export(w, req.Collection, req.FilterMeta, req.Format); err != nil {
+		errorf("Export of %s failed: %v", req.Collection, err)
+	}
+}
@@ -3,76 +3,68 @@ package main
 import (
 	"context"
 	"errors"
-	"fmt"
+	"io"
 	"net"
-	"os"
-	"strings"
 	"time"
 
-	bolt "go.etcd.io/bbolt"
 	"google.golang.org/grpc"
 	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/credentials"
+	"google.golang.org/grpc/health"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
 	"google.golang.org/grpc/reflection"
 	"google.golang.org/grpc/status"
 
-	proto "mddb/proto"
+	proto "github.com/tradik/mddb/services/mddbd/proto"
+	"github.com/tradik/mddb/services/mddbd/storage"
 )
 
 // GRPCServer implements the MDDB gRPC service
 type GRPCServer struct {
 	proto.UnimplementedMDDBServer
-	server         *Server
-	batchProcessor *BatchProcessor
-	batchUpdater   *BatchUpdater
-	batchDeleter   *BatchDeleter
+	server *Server
 }
 
 // NewGRPCServer creates a new gRPC server wrapper
 func NewGRPCServer(s *Server) *GRPCServer {
-	// Use FinalBatchProcessor if extreme mode, otherwise standard
-	var batchProc *BatchProcessor
-	if s.UseExtreme {
-		// In extreme mode, use wrapper that calls FinalBatchProcessor
-		batchProc = &BatchProcessor{
-			server:     s,
-			maxWorkers: 8,
-		}
-		// Override with final processor
-		finalProc := NewFinalBatchProcessor(s, 8)
-		// Store final processor for use
-		s.finalBatchProcessor = finalProc
-	} else {
-		batchProc = NewBatchProcessor(s, 8)
-	}
-	
-	gs := &GRPCServer{
-		server:         s,
-		batchProcessor: batchProc,
-		batchDeleter:   NewBatchDeleter(s, 8), // 8 parallel workers
-		batchUpdater:   NewBatchUpdater(s, 8), // 8 parallel workers
-	}
-	// Worker pool will be initialized when needed
-	return gs
+	return &GRPCServer{server: s}
 }
 
-// startGRPCServer starts the gRPC server on the specified address
-func startGRPCServer(s *Server, addr string) error {
+// startGRPCServer binds the gRPC server to the specified address; the listener serves it
+func startGRPCServer(s *Server, addr string) (listener, error) {
 	lis, err := net.Listen("tcp", addr)
 	if err != nil {
-		return err
+		return listener{}, err
 	}
 
-	grpcServer := grpc.NewServer(
+	opts := []grpc.ServerOption{
 		grpc.MaxRecvMsgSize(10 * 1024 * 1024), // 10MB
 		grpc.MaxSendMsgSize(10 * 1024 * 1024), // 10MB
-	)
+	}
+	if s.TLS != nil {
+		opts = append(opts, grpc.Creds(credentials.NewTLS(s.TLS.Config("h2"))))
+	}
+	// Metrics run first so rejected calls are counted too
+	unary := []grpc.UnaryServerInterceptor{s.Metrics.unaryInterceptor, s.unaryRateLimit, s.unaryDrain}
+	stream := []grpc.StreamServerInterceptor{s.Metrics.streamInterceptor, s.streamRateLimit, s.streamDrain}
+	if s.Auth != nil {
+		unary = append(unary, s.Auth.unaryInterceptor)
+		stream = append(stream, s.Auth.streamInterceptor)
+	}
+	opts = append(opts, grpc.ChainUnaryInterceptor(unary...), grpc.ChainStreamInterceptor(stream...))
+	grpcServer := grpc.NewServer(opts...)
 
 	proto.RegisterMDDBServer(grpcServer, NewGRPCServer(s))
+
+	// Standard gRPC health service for load balancers and Kubernetes probes
+	healthServer := health.NewServer()
+	healthpb.RegisterHealthServer(grpcServer, healthServer)
+	s.goBackground(func() { watchHealth(s, healthServer) })
 	
 	// Register reflection service for grpcurl
 	reflection.Register(grpcServer)
 
-	return grpcServer.Serve(lis)
+	return grpcListener(grpcServer, func() error { return grpcServer.Serve(lis) }), nil
 }
 
 // Add implements the Add RPC
@@ -81,100 +73,97 @@ func (g *GRPCServer) Add(ctx context.Context, req *proto.AddRequest) (*proto.Doc
 		return nil, status.Error(codes.PermissionDenied, "read-only mode")
 	}
 
-	if req.Collection == "" || req.Key == "" || req.Lang == "" {
-		return nil, status.Error(codes.InvalidArgument, "missing required fields")
-	}
-
-	// Convert proto meta to internal format
-	meta := make(map[string][]string)
-	for k, v := range req.Meta {
-		meta[k] = v.Values
+	saved, err := g.server.DB.Add(storage.AddRequest{
+		Collection:   req.Collection,
+		Key:          req.Key,
+		Lang:         req.Lang,
+		Meta:         metaFromProto(req.Meta),
+		ContentMD:    req.ContentMd,
+		SaveRevision: req.SaveRevision,
+		AsyncIndex:   true, // lazy metadata indexing
+	})
+	if err != nil {
+		return nil, grpcError(err, codes.Internal)
 	}
+	g.server.runHooks(hookAdd, req.Collection, saved)
 
-	now := time.Now().Unix()
-	docID := genID(req.Collection, req.Key, req.Lang)
+	return docToProto(saved), nil
+}
 
-	// Use KeyBuilder for efficient key construction
-	var kb KeyBuilder
+// Patch implements the Patch RPC
+func (g *GRPCServer) Patch(ctx context.Context, req *proto.PatchRequest) (*proto.Document, error) {
+	if g.server.Mode == ModeRead {
+		return nil, status.Error(codes.PermissionDenied, "read-only mode")
+	}
 
-	var saved Doc
-	var cachedBuf []byte
-	err := g.server.DB.Update(func(tx *bolt.Tx) error {
-		bDocs := tx.Bucket(g.server.BucketNames.Docs)
-		bRev := tx.Bucket(g.server.BucketNames.Rev)
-		bByK := tx.Bucket(g.server.BucketNames.ByKey)
+	saved, err := g.server.DB.Patch(patchFromProto(req), nil)
+	if err != nil {
+		return nil, grpcError(err, codes.Internal)
+	}
+	g.server.runHooks(hookUpdate, req.Collection, saved)
 
-		// Load existing
-		existing := Doc{}
-		docKey := kb.BuildDocKey(req.Collection, docID)
-		if v := bDocs.Get(docKey); v != nil {
-			existingDoc, err := unmarshalDoc(v)
-			if err != nil {
-				return err
-			}
-			existing = *existingDoc
-		}
-		added := existing.AddedAt
-		if added == 0 {
-			added = now
-		}
+	return docToProto(saved), nil
+}
 
-		doc := Doc{
-			ID: docID, Key: req.Key, Lang: req.Lang, Meta: meta,
-			ContentMD: req.ContentMd, AddedAt: added, UpdatedAt: now,
-		}
-		buf, err := marshalDoc(&doc)
-		if err != nil {
-			return err
-		}
-		cachedBuf = buf // Save for cache
-		
-		// Use KeyBuilder for all keys
-		docKey = kb.BuildDocKey(req.Collection, docID)
-		if err := bDocs.Put(docKey, buf); err != nil {
-			return err
-		}
-		
-		byKeyKey := kb.BuildByKey(req.Collection, req.Key, req.Lang)
-		if err := bByK.Put(byKeyKey, []byte(docID)); err != nil {
-			return err
-		}
+// Helper: convert proto patch request to internal
+func patchFromProto(req *proto.PatchRequest) storage.PatchRequest {
+	ops := make([]storage.MetaPatch, 0, len(req.Meta))
+	for _, p := range req.Meta {
+		ops = append(ops, storage.MetaPatch{Op: p.Op, Key: p.Key, Values: p.Values})
+	}
+	return storage.PatchRequest{
+		Collection:   req.Collection,
+		Key:          req.Key,
+		Lang:         req.Lang,
+		Meta:         ops,
+		ContentMD:    req.ContentMd,
+		AppendMD:     req.AppendMd,
+		SaveRevision: req.SaveRevision,
+	}
+}
 
-		// Lazy metadata indexing - queue for async processing
-		if metadataChanged(existing.Meta, doc.Meta) {
-			g.server.IndexQueue.Enqueue(&IndexJob{
-				Collection: req.Collection,
-				DocID:      docID,
-				OldMeta:    existing.Meta,
-				NewMeta:    doc.Meta,
-			})
-		}
+// Transaction implements the Transaction RPC - runs mixed operations in a single transaction
+func (g *GRPCServer) Transaction(ctx context.Context, req *proto.TransactionRequest) (*proto.TransactionResponse, error) {
+	if g.server.Mode == ModeRead {
+		return nil, status.Error(codes.PermissionDenied, "read-only mode")
+	}
 
-		// Revision (optional - only if requested)
-		if req.SaveRevision {
-			revKey := kb.BuildRevKey(req.Collection, doc.ID, now)
-			if err := bRev.Put(revKey, buf); err != nil {
-				return err
+	ops := make([]storage.TxOp, len(req.Ops))
+	for i, op := range req.Ops {
+		switch o := op.Op.(type) {
+		case *proto.TxOp_Add:
+			ops[i].Add = &storage.AddRequest{
+				Collection:   o.Add.Collection,
+				Key:          o.Add.Key,
+				Lang:         o.Add.Lang,
+				Meta:         metaFromProto(o.Add.Meta),
+				ContentMD:    o.Add.ContentMd,
+				SaveRevision: o.Add.SaveRevision,
 			}
+		case *proto.TxOp_Patch:
+			p := patchFromProto(o.Patch)
+			ops[i].Patch = &p
+		case *proto.TxOp_Delete:
+			ops[i].Delete = &storage.DeleteRequest{Collection: o.Delete.Collection, Key: o.Delete.Key, Lang: o.Delete.Lang}
 		}
+		if pc := op.Precondition; pc != nil {
+			ops[i].Precondition = &storage.Precondition{Exists: pc.Exists, IfMatch: pc.IfMatch}
+		}
+	}
 
-		saved = doc
-		return nil
-	})
-
+	results, err := g.server.DB.Transaction(ops)
 	if err != nil {
-		return nil, status.Error(codes.Internal, err.Error())
+		return nil, grpcError(err, codes.Internal)
 	}
 
-	// Update cache (use lock-free cache if extreme mode)
-	cacheKey := BuildCacheKey(req.Collection, req.Key, req.Lang)
-	if g.server.UseExtreme {
-		g.server.LockFreeCache.Set(cacheKey, cachedBuf)
-	} else {
-		g.server.Cache.Set(cacheKey, cachedBuf)
+	resp := &proto.TransactionResponse{Results: make([]*proto.TxResult, len(results))}
+	for i, r := range results {
+		resp.Results[i] = &proto.TxResult{Op: r.Op, Collection: r.Collection, Key: r.Key, Lang: r.Lang, Created: r.Created}
+		if r.Doc != nil {
+			resp.Results[i].Document = docToProto(r.Doc)
+		}
 	}
-
-	return docToProto(&saved), nil
+	return resp, nil
 }
 
 // AddBatch implements the AddBatch RPC - adds multiple documents in a single transaction
@@ -188,97 +177,28 @@ func (g *GRPCServer) AddBatch(ctx context.Context, req *proto.AddBatchRequest) (
 		return nil, status.Error(codes.InvalidArgument, "missing collection")
 	}
 
-	if len(req.Documents) == 0 {
-		return &proto.AddBatchResponse{}, nil
-	}
-
-	// Use final batch processor if extreme mode, otherwise standard
-	var resp *proto.AddBatchResponse
-	var err error
-	
-	if g.server.UseExtreme && g.server.finalBatchProcessor != nil {
-		resp, err = g.server.finalBatchProcessor.ProcessBatch(ctx, req.Collection, req.Documents)
-	} else {
-		resp, err = g.batchProcessor.ProcessBatch(ctx, req.Collection, req.Documents)
+	docs := make([]storage.BatchDocument, len(req.Documents))
+	for i, d := range req.Documents {
+		docs[i] = storage.BatchDocument{
+			Key: d.Key, Lang: d.Lang, Meta: metaFromProto(d.Meta),
+			ContentMD: d.ContentMd, SaveRevision: d.SaveRevision,
+		}
 	}
-	
+	res, err := g.server.DB.AddBatch(ctx, req.Collection, docs)
 	if err != nil {
 		return nil, status.Error(codes.Internal, err.Error())
 	}
 
-	return resp, nil
+	return &proto.AddBatchResponse{
+		Added: int32(res.Added), Updated: int32(res.Updated), Failed: int32(res.Failed), Errors: res.Errors,
+	}, nil
 }
 
 // Get implements the Get RPC
 func (g *GRPCServer) Get(ctx context.Context, req *proto.GetRequest) (*proto.Document, error) {
-	if req.Collection == "" || req.Key == "" || req.Lang == "" {
-		return nil, status.Error(codes.InvalidArgument, "missing required fields")
-	}
-
-	// Check cache first (use lock-free cache if extreme mode)
-	cacheKey := BuildCacheKey(req.Collection, req.Key, req.Lang)
-	
-	var cachedData []byte
-	var found bool
-	
-	if g.server.UseExtreme {
-		cachedData, found = g.server.LockFreeCache.Get(cacheKey)
-	} else {
-		cachedData, found = g.server.Cache.Get(cacheKey)
-	}
-	
-	if found {
-		docPtr, err := unmarshalDoc(cachedData)
-		if err == nil {
-			// Apply template variables if needed
-			if len(req.Env) > 0 {
-				docPtr.ContentMD = applyEnv(docPtr.ContentMD, req.Env)
-			}
-			return docToProto(docPtr), nil
-		}
-	}
-
-	var doc Doc
-	var docData []byte
-	err := g.server.DB.View(func(tx *bolt.Tx) error {
-		bByK := tx.Bucket(g.server.BucketNames.ByKey)
-		bDocs := tx.Bucket(g.server.BucketNames.Docs)
-
-		docID := bByK.Get(kByKey(req.Collection, req.Key, req.Lang))
-		if docID == nil {
-			return errors.New("not found")
-		}
-
-		v := bDocs.Get(kDoc(req.Collection, string(docID)))
-		if v == nil {
-			return errors.New("not found")
-		}
-
-		docData = make([]byte, len(v))
-		copy(docData, v)
-
-		docPtr, err := unmarshalDoc(v)
-		if err != nil {
-			return err
-		}
-		doc = *docPtr
-		return nil
-	})
-	
-	// Update cache (use lock-free cache if extreme mode)
-	if err == nil && docData != nil {
-		if g.server.UseExtreme {
-			g.server.LockFreeCache.Set(cacheKey, docData)
-		} else {
-			g.server.Cache.Set(cacheKey, docData)
-		}
-	}
-
+	doc, err := g.server.DB.Get(req.Collection, req.Key, req.Lang)
 	if err != nil {
-		if err.Error() == "not found" {
-			return nil, status.Error(codes.NotFound, "document not found")
-		}
-		return nil, status.Error(codes.Internal, err.Error())
+		return nil, grpcError(err, codes.Internal)
 	}
 
 	// Apply template variables
@@ -286,7 +206,7 @@ func (g *GRPCServer) Get(ctx context.Context, req *proto.GetRequest) (*proto.Doc
 		doc.ContentMD = applyEnv(doc.ContentMD, req.Env)
 	}
 
-	return docToProto(&doc), nil
+	return docToProto(doc), nil
 }
 
 // Search implements the Search RPC
@@ -295,96 +215,27 @@ func (g *GRPCServer) Search(ctx context.Context, req *proto.SearchRequest) (*pro
 		return nil, status.Error(codes.InvalidArgument, "missing collection")
 	}
 
-	// Convert proto filter to internal format
-	filterMeta := make(map[string][]string)
-	for k, v := range req.FilterMeta {
-		filterMeta[k] = v.Values
-	}
-
-	// Single transaction for both ID collection and document loading
-	var docs []Doc
-	var docIDs []string
-	
-	err := g.server.DB.View(func(tx *bolt.Tx) error {
-		bIdx := tx.Bucket(g.server.BucketNames.IdxMeta)
-		bDocs := tx.Bucket(g.server.BucketNames.Docs)
-		
-		if len(filterMeta) == 0 {
-			// No filter: scan all docs
-			c := bDocs.Cursor()
-			prefix := []byte("doc|" + req.Collection + "|")
-			for k, _ := c.Seek(prefix); k != nil && BytesHasPrefix(k, prefix); k, _ = c.Next() {
-				// Extract docID (3rd part) without string allocations
-				if docID := ExtractPart(k, 2); docID != nil {
-					docIDs = append(docIDs, string(docID))
-				}
-			}
-		} else {
-			// Filter by meta
-			sets := [][]string{}
-			for mk, mvs := range filterMeta {
-				union := []string{}
-				for _, mv := range mvs {
-					c := bIdx.Cursor()
-					prefix := kMetaKeyPrefix(req.Collection, mk, mv)
-					for k, _ := c.Seek(prefix); k != nil && BytesHasPrefix(k, prefix); k, _ = c.Next() {
-						// Extract docID (5th part) without string allocations
-						if docID := ExtractPart(k, 4); docID != nil {
-							union = append(union, string(docID))
-						}
-					}
-				}
-				sets = append(sets, unique(union))
-			}
-			docIDs = intersect(sets...)
-		}
-		
-		// Load documents in the same transaction
-		for _, id := range docIDs {
-			v := bDocs.Get(kDoc(req.Collection, id))
-			if v != nil {
-				d, err := unmarshalDoc(v)
-				if err == nil {
-					docs = append(docs, *d)
-				}
-			}
-		}
-		
-		return nil
-	})
-
-	if err != nil {
-		return nil, status.Error(codes.Internal, err.Error())
-	}
-
-	// Sort
 	sortField := req.Sort
 	if sortField == "" {
 		sortField = "updatedAt"
 	}
-	sortDocs(docs, sortField, req.Asc)
-
-	// Pagination
-	total := len(docs)
-	offset := int(req.Offset)
-	limit := int(req.Limit)
-	if limit == 0 {
-		limit = 50
-	}
-
-	if offset > len(docs) {
-		offset = len(docs)
-	}
-	end := offset + limit
-	if end > len(docs) {
-		end = len(docs)
+	docs, total, err := g.server.DB.Search(storage.SearchQuery{
+		Collection:  req.Collection,
+		FilterMeta:  metaFromProto(req.FilterMeta),
+		Sort:        sortField,
+		Asc:         req.Asc,
+		Limit:       int(req.Limit),
+		Offset:      int(req.Offset),
+		Consistency: req.Consistency,
+	})
+	if err != nil {
+		return nil, grpcError(err, codes.Internal)
 	}
-	docs = docs[offset:end]
 
 	// Convert to proto
 	protoDocs := make([]*proto.Document, len(docs))
-	for i, doc := range docs {
-		protoDocs[i] = docToProto(&doc)
+	for i := range docs {
+		protoDocs[i] = docToProto(&docs[i])
 	}
 
 	return &proto.SearchResponse{
@@ -395,26 +246,63 @@ func (g *GRPCServer) Search(ctx context.Context, req *proto.SearchRequest) (*pro
 
 // Export implements the Export RPC (streaming)
 func (g *GRPCServer) Export(req *proto.ExportRequest, stream proto.MDDB_ExportServer) error {
-	// Similar to HTTP export but streaming chunks
-	return status.Error(codes.Unimplemented, "export streaming not yet implemented")
+	if req.Collection == "" {
+		return status.Error(codes.InvalidArgument, "missing collection")
+	}
+	format := req.Format
+	if format == "" {
+		format = "ndjson"
+	}
+
+	filterMeta := make(map[string][]string)
+	for k, v := range req.FilterMeta {
+		filterMeta[k] = v.Values
+	}
+
+	cw := newChunkWriter(stream.Context(), func(data []byte, last bool) error {
+		return stream.Send(&proto.ExportChunk{Data: data, IsLast: last})
+	})
+	if err := g.server.DB.Export(cw, req.Collection, filterMeta, format); err != nil {
+		if errors.Is(err, storage.ErrUnsupportedFormat) {
+			return status.Error(codes.InvalidArgument, err.Error())
+		}
+		if stream.Context().Err() != nil {
+			return status.FromContextError(stream.Context().Err()).Err()
+		}
+		return status.Error(codes.Internal, err.Error())
+	}
+	return cw.flush(true)
 }
 
 // Backup implements the Backup RPC
 func (g *GRPCServer) Backup(ctx context.Context, req *proto.BackupRequest) (*proto.BackupResponse, error) {
-	filename := req.To
-	if filename == "" {
-		filename = fmt.Sprintf("backup-%d.db", time.Now().Unix())
+	name, m, err := g.server.writeBackupFile(req.To, storage.BackupOptions{Compression: req.Compression})
+	if err != nil {
+		return nil, grpcError(err, codes.Internal)
 	}
 
-	err := g.server.DB.View(func(tx *bolt.Tx) error {
-		return tx.CopyFile(filename, 0600)
-	})
+	return &proto.BackupResponse{Backup: name, Manifest: backupManifestProto(m)}, nil
+}
 
+// StreamBackup implements the StreamBackup RPC (streaming)
+func (g *GRPCServer) StreamBackup(req *proto.StreamBackupRequest, stream proto.MDDB_StreamBackupServer) error {
+	var manifest *proto.BackupManifest
+	cw := newChunkWriter(stream.Context(), func(data []byte, last bool) error {
+		chunk := &proto.BackupChunk{Data: data}
+		if last {
+			chunk.Manifest = manifest
+		}
+		return stream.Send(chunk)
+	})
+	m, err := g.server.DB.WriteBackup(cw, storage.BackupOptions{Compression: req.Compression})
 	if err != nil {
-		return nil, status.Error(codes.Internal, err.Error())
+		if stream.Context().Err() != nil {
+			return status.FromContextError(stream.Context().Err()).Err()
+		}
+		return grpcError(err, codes.Internal)
 	}
-
-	return &proto.BackupResponse{Backup: filename}, nil
+	manifest = backupManifestProto(m)
+	return cw.flush(true)
 }
 
 // Restore implements the Restore RPC
@@ -423,15 +311,64 @@ func (g *GRPCServer) Restore(ctx context.Context, req *proto.RestoreRequest) (*p
 		return nil, status.Error(codes.PermissionDenied, "read-only mode")
 	}
 
-	if req.From == "" {
-		return nil, status.Error(codes.InvalidArgument, "missing backup filename")
+	var at time.Time
+	if req.At != "" {
+		var err error
+		if at, err = time.Parse(time.RFC3339Nano, req.At); err != nil {
+			return nil, status.Error(codes.InvalidArgument, "invalid at: "+err.Error())
+		}
 	}
+	from, res, err := g.server.restoreBackup(req.From, req.Sha256, req.Rollback, at)
+	if err != nil {
+		return nil, grpcError(err, codes.Internal)
+	}
+	logRestore(from, res)
+	return restoreResponseProto(from, res), nil
+}
 
-	if err := copyFile(req.From, g.server.Path); err != nil {
-		return nil, status.Error(codes.Internal, err.Error())
+// StreamRestore implements the StreamRestore RPC (client streaming)
+func (g *GRPCServer) StreamRestore(stream proto.MDDB_StreamRestoreServer) error {
+	if g.server.Mode == ModeRead {
+		return status.Error(codes.PermissionDenied, "read-only mode")
+	}
+	first, err := stream.Recv()
+	if err != nil {
+		return err
 	}
 
-	return &proto.RestoreResponse{Restored: req.From}, nil
+	// Feed uploaded chunks to the restore as they arrive
+	pr, pw := io.Pipe()
+	go func() {
+		if _, err := pw.Write(first.Data); err != nil {
+			return
+		}
+		for {
+			chunk, err := stream.Recv()
+			if err == io.EOF {
+				_ = pw.Close()
+				return
+			}
+			if err != nil {
+				_ = pw.CloseWithError(err)
+				return
+			}
+			if _, err := pw.Write(chunk.Data); err != nil {
+				return
+			}
+		}
+	}()
+
+	res, err := g.server.DB.RestoreFrom(pr, g.server.restoreOptions(first.Sha256))
+	_ = pr.CloseWithError(io.ErrClosedPipe)
+	if err != nil {
+		if stream.Context().Err() != nil {
+			return status.FromContextError(stream.Context().Err()).Err()
+		}
+		return grpcError(err, codes.Internal)
+	}
+	logRestore("upload", res)
+	g.server.backupAfterRestore()
+	return stream.SendAndClose(restoreResponseProto("upload", res))
 }
 
 // Truncate implements the Truncate RPC
@@ -444,150 +381,133 @@ func (g *GRPCServer) Truncate(ctx context.Context, req *proto.TruncateRequest) (
 		return nil, status.Error(codes.InvalidArgument, "missing collection")
 	}
 
-	err := g.server.DB.Update(func(tx *bolt.Tx) error {
-		bRev := tx.Bucket(g.server.BucketNames.Rev)
-		bDocs := tx.Bucket(g.server.BucketNames.Docs)
+	if err := g.server.DB.Truncate(req.Collection, int(req.KeepRevs)); err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+	if req.DropCache {
+		g.server.DB.DropCache(req.Collection)
+	}
 
-		// Get all doc IDs in collection
-		var docIDs []string
-		c := bDocs.Cursor()
-		prefix := []byte("doc|" + req.Collection + "|")
-		for k, _ := c.Seek(prefix); k != nil && strings.HasPrefix(string(k), string(prefix)); k, _ = c.Next() {
-			parts := strings.Split(string(k), "|")
-			if len(parts) >= 3 {
-				docIDs = append(docIDs, parts[2])
-			}
-		}
+	return &proto.TruncateResponse{Status: "truncated"}, nil
+}
 
-		// For each doc, keep only last N revisions
-		for _, docID := range docIDs {
-			var revKeys []string
-			rc := bRev.Cursor()
-			rprefix := kRevPrefix(req.Collection, docID)
-			for k, _ := rc.Seek(rprefix); k != nil && strings.HasPrefix(string(k), string(rprefix)); k, _ = rc.Next() {
-				revKeys = append(revKeys, string(k))
-			}
+// Fsck implements the Fsck RPC
+func (g *GRPCServer) Fsck(ctx context.Context, req *proto.FsckRequest) (*proto.FsckResponse, error) {
+	if req.Repair && g.server.Mode == ModeRead {
+		return nil, status.Error(codes.PermissionDenied, "read-only mode")
+	}
 
-			// Delete old revisions
-			if len(revKeys) > int(req.KeepRevs) {
-				toDelete := revKeys[:len(revKeys)-int(req.KeepRevs)]
-				for _, k := range toDelete {
-					_ = bRev.Delete([]byte(k))
-				}
-			}
-		}
+	report, err := g.server.DB.Fsck(storage.FsckOptions{Collection: req.Collection, Repair: req.Repair})
+	if err != nil {
+		return nil, grpcError(err, codes.Internal)
+	}
 
-		return nil
-	})
+	resp := &proto.FsckResponse{
+		Documents:   int32(report.Documents),
+		KeyEntries:  int32(report.KeyEntries),
+		MetaIndices: int32(report.MetaIndices),
+		Revisions:   int32(report.Revisions),
+		Problems:    make(map[string]int32, len(report.Problems)),
+		Issues:      make([]*proto.FsckIssue, len(report.Issues)),
+		Repaired:    int32(report.Repaired),
+	}
+	for kind, n := range report.Problems {
+		resp.Problems[kind] = int32(n)
+	}
+	for i, is := range report.Issues {
+		resp.Issues[i] = &proto.FsckIssue{Kind: is.Kind, Collection: is.Collection, Key: is.Key, Repaired: is.Repaired}
+	}
+	return resp, nil
+}
 
-	if err != nil {
-		return nil, status.Error(codes.Internal, err.Error())
+// Compact implements the Compact RPC
+func (g *GRPCServer) Compact(ctx context.Context, req *proto.CompactRequest) (*proto.CompactResponse, error) {
+	if g.server.Mode == ModeRead {
+		return nil, status.Error(codes.PermissionDenied, "read-only mode")
 	}
 
-	return &proto.TruncateResponse{Status: "truncated"}, nil
+	res, err := g.server.DB.Compact(storage.CompactOptions{MinFreeRatio: req.MinFreeRatio})
+	if err != nil {
+		return nil, grpcError(err, codes.Internal)
+	}
+	return &proto.CompactResponse{
+		Compacted:  res.Compacted,
+		SizeBefore: res.SizeBefore,
+		SizeAfter:  res.SizeAfter,
+		FreeBefore: res.FreeBefore,
+		Reclaimed:  res.Reclaimed,
+		DurationMs: res.DurationMs,
+	}, nil
 }
 
 // Stats implements the Stats RPC
 func (g *GRPCServer) Stats(ctx context.Context, req *proto.StatsRequest) (*proto.StatsResponse, error) {
-	resp := &proto.StatsResponse{
-		DatabasePath: g.server.Path,
-		Mode:         string(g.server.Mode),
-		Collections:  []*proto.CollectionStats{},
-	}
-
-	// Get database file size
-	if info, err := os.Stat(g.server.Path); err == nil {
-		resp.DatabaseSize = info.Size()
-	}
-
-	// Collect statistics
-	collectionMap := make(map[string]*proto.CollectionStats)
-
-	err := g.server.DB.View(func(tx *bolt.Tx) error {
-		// Count documents
-		bDocs := tx.Bucket(g.server.BucketNames.Docs)
-		if bDocs != nil {
-			c := bDocs.Cursor()
-			for k, _ := c.First(); k != nil; k, _ = c.Next() {
-				parts := strings.Split(string(k), "|")
-				if len(parts) >= 2 {
-					coll := parts[1]
-					if _, ok := collectionMap[coll]; !ok {
-						collectionMap[coll] = &proto.CollectionStats{Name: coll}
-					}
-					collectionMap[coll].DocumentCount++
-					resp.TotalDocuments++
-				}
-			}
-		}
-
-		// Count revisions
-		bRev := tx.Bucket(g.server.BucketNames.Rev)
-		if bRev != nil {
-			c := bRev.Cursor()
-			for k, _ := c.First(); k != nil; k, _ = c.Next() {
-				parts := strings.Split(string(k), "|")
-				if len(parts) >= 2 {
-					coll := parts[1]
-					if _, ok := collectionMap[coll]; !ok {
-						collectionMap[coll] = &proto.CollectionStats{Name: coll}
-					}
-					collectionMap[coll].RevisionCount++
-					resp.TotalRevisions++
-				}
-			}
-		}
-
-		// Count meta indices
-		bIdx := tx.Bucket(g.server.BucketNames.IdxMeta)
-		if bIdx != nil {
-			c := bIdx.Cursor()
-			for k, _ := c.First(); k != nil; k, _ = c.Next() {
-				parts := strings.Split(string(k), "|")
-				if len(parts) >= 2 {
-					coll := parts[1]
-					if _, ok := collectionMap[coll]; !ok {
-						collectionMap[coll] = &proto.CollectionStats{Name: coll}
-					}
-					collectionMap[coll].MetaIndexCount++
-					resp.TotalMetaIndices++
-				}
-			}
-		}
-
-		return nil
-	})
-
+	stats, err := g.server.DB.Stats()
 	if err != nil {
 		return nil, status.Error(codes.Internal, err.Error())
 	}
 
-	// Convert map to slice
-	for _, cs := range collectionMap {
-		resp.Collections = append(resp.Collections, cs)
+	resp := &proto.StatsResponse{
+		DatabasePath:     stats.DatabasePath,
+		DatabaseSize:     stats.DatabaseSize,
+		Mode:             string(g.server.Mode),
+		Collections:      make([]*proto.CollectionStats, len(stats.Collections)),
+		TotalDocuments:   int32(stats.TotalDocuments),
+		TotalRevisions:   int32(stats.TotalRevisions),
+		TotalMetaIndices: int32(stats.TotalMetaIndices),
+		Cache: &proto.CacheStats{
+			Hits:      stats.Cache.Hits,
+			Misses:    stats.Cache.Misses,
+			Evictions: stats.Cache.Evictions,
+			Rejected:  stats.Cache.Rejected,
+			Entries:   int64(stats.Cache.Entries),
+			Bytes:     stats.Cache.Bytes,
+			Budget:    stats.Cache.Budget,
+		},
+	}
+	for i, cs := range stats.Collections {
+		resp.Collections[i] = &proto.CollectionStats{
+			Name:           cs.Name,
+			DocumentCount:  int32(cs.DocumentCount),
+			RevisionCount:  int32(cs.RevisionCount),
+			MetaIndexCount: int32(cs.MetaIndexCount),
+		}
 	}
 
 	return resp, nil
 }
 
 // Helper: convert internal Doc to proto Document
-func docToProto(doc *Doc) *proto.Document {
-	protoMeta := make(map[string]*proto.MetaValues)
-	for k, v := range doc.Meta {
-		protoMeta[k] = &proto.MetaValues{Values: v}
-	}
-
+func docToProto(doc *storage.Doc) *proto.Document {
 	return &proto.Document{
 		Id:        doc.ID,
 		Key:       doc.Key,
 		Lang:      doc.Lang,
-		Meta:      protoMeta,
+		Meta:      metaToProto(doc.Meta),
 		ContentMd: doc.ContentMD,
 		AddedAt:   doc.AddedAt,
 		UpdatedAt: doc.UpdatedAt,
 	}
 }
 
+// Helper: convert proto meta to internal
+func metaFromProto(meta map[string]*proto.MetaValues) map[string][]string {
+	out := make(map[string][]string, len(meta))
+	for k, v := range meta {
+		out[k] = v.Values
+	}
+	return out
+}
+
+// Helper: convert internal meta to proto
+func metaToProto(meta map[string][]string) map[string]*proto.MetaValues {
+	out := make(map[string]*proto.MetaValues, len(meta))
+	for k, v := range meta {
+		out[k] = &proto.MetaValues{Values: v}
+	}
+	return out
+}
+
 // DeleteBatch implements the DeleteBatch RPC - deletes multiple documents in a single transaction
 func (g *GRPCServer) DeleteBatch(ctx context.Context, req *proto.DeleteBatchRequest) (*proto.DeleteBatchResponse, error) {
 	if g.server.Mode == ModeRead {
@@ -598,12 +518,18 @@ func (g *GRPCServer) DeleteBatch(ctx context.Context, req *proto.DeleteBatchRequ
 		return nil, status.Error(codes.InvalidArgument, "missing collection")
 	}
 
-	resp, err := g.batchDeleter.ProcessBatchDelete(ctx, req.Collection, req.Documents)
+	refs := make([]storage.DocRef, len(req.Documents))
+	for i, d := range req.Documents {
+		refs[i] = storage.DocRef{Key: d.Key, Lang: d.Lang}
+	}
+	res, err := g.server.DB.DeleteBatch(ctx, req.Collection, refs)
 	if err != nil {
 		return nil, status.Error(codes.Internal, err.Error())
 	}
 
-	return resp, nil
+	return &proto.DeleteBatchResponse{
+		Deleted: int32(res.Deleted), NotFound: int32(res.NotFound), Failed: int32(res.Failed), Errors: res.Errors,
+	}, nil
 }
 
 // UpdateBatch implements the UpdateBatch RPC - updates multiple documents in a single transaction
@@ -616,10 +542,342 @@ func (g *GRPCServer) UpdateBatch(ctx context.Context, req *proto.UpdateBatchRequ
 		return nil, status.Error(codes.InvalidArgument, "missing collection")
 	}
 
-	resp, err := g.batchUpdater.ProcessBatchUpdate(ctx, req.Collection, req.Documents)
+	docs := make([]storage.BatchDocument, len(req.Documents))
+	for i, d := range req.Documents {
+		docs[i] = storage.BatchDocument{
+			Key: d.Key, Lang: d.Lang, Meta: metaFromProto(d.Meta),
+			ContentMD: d.ContentMd, SaveRevision: d.SaveRevision,
+		}
+	}
+	res, err := g.server.DB.UpdateBatch(ctx, req.Collection, docs)
+	if err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+
+	return &proto.UpdateBatchResponse{
+		Updated: int32(res.Updated), NotFound: int32(res.NotFound), Failed: int32(res.Failed), Errors: res.Errors,
+	}, nil
+}
+
+// SetSchema implements the SetSchema RPC
+func (g *GRPCServer) SetSchema(ctx context.Context, req *proto.CollectionSchema) (*proto.CollectionSchema, error) {
+	if g.server.Mode == ModeRead {
+		return nil, status.Error(codes.PermissionDenied, "read-only mode")
+	}
+
+	if req.Collection == "" {
+		return nil, status.Error(codes.InvalidArgument, "missing collection")
+	}
+
+	if err := g.server.DB.Schemas.Set(protoToSchema(req)); err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	return req, nil
+}
+
+// GetSchema implements the GetSchema RPC
+func (g *GRPCServer) GetSchema(ctx context.Context, req *proto.SchemaRequest) (*proto.CollectionSchema, error) {
+	if req.Collection == "" {
+		return nil, status.Error(codes.InvalidArgument, "missing collection")
+	}
+
+	schema, err := g.server.DB.Schemas.Get(req.Collection)
 	if err != nil {
 		return nil, status.Error(codes.Internal, err.Error())
 	}
+	if schema == nil {
+		return nil, grpcError(errSchemaNotFound, codes.Internal)
+	}
+
+	return schemaToProto(schema), nil
+}
+
+// DeleteSchema implements the DeleteSchema RPC
+func (g *GRPCServer) DeleteSchema(ctx context.Context, req *proto.SchemaRequest) (*proto.DeleteSchemaResponse, error) {
+	if g.server.Mode == ModeRead {
+		return nil, status.Error(codes.PermissionDenied, "read-only mode")
+	}
+
+	if req.Collection == "" {
+		return nil, status.Error(codes.InvalidArgument, "missing collection")
+	}
+
+	if err := g.server.DB.Schemas.Delete(req.Collection); err != nil {
+		return nil, status.Error(codes.Internal, err.Error())
+	}
+
+	return &proto.DeleteSchemaResponse{Status: "deleted"}, nil
+}
+
+// Helper: convert internal CollectionSchema to proto
+func schemaToProto(s *storage.CollectionSchema) *proto.CollectionSchema {
+	enums := make(map[string]*proto.MetaValues, len(s.Enums))
+	for k, v := range s.Enums {
+		enums[k] = &proto.MetaValues{Values: v}
+	}
+
+	return &proto.CollectionSchema{
+		Collection:     s.Collection,
+		RequiredMeta:   s.RequiredMeta,
+		OptionalMeta:   s.OptionalMeta,
+		Enums:          enums,
+		Patterns:       s.Patterns,
+		MaxContentSize: int64(s.MaxContentSize),
+		RequiredLangs:  s.RequiredLangs,
+	}
+}
+
+// Helper: convert proto CollectionSchema to internal
+func protoToSchema(p *proto.CollectionSchema) storage.CollectionSchema {
+	enums := make(map[string][]string, len(p.Enums))
+	for k, v := range p.Enums {
+		enums[k] = v.Values
+	}
+
+	return storage.CollectionSchema{
+		Collection:     p.Collection,
+		RequiredMeta:   p.RequiredMeta,
+		OptionalMeta:   p.OptionalMeta,
+		Enums:          enums,
+		Patterns:       p.Patterns,
+		MaxContentSize: int(p.MaxContentSize),
+		RequiredLangs:  p.RequiredLangs,
+	}
+}
+
+// CreateCollection implements the CreateCollection RPC
+func (g *GRPCServer) CreateCollection(ctx context.Context, req *proto.CollectionInfo) (*proto.CollectionInfo, error) {
+	if g.server.Mode == ModeRead {
+		return nil, status.Error(codes.PermissionDenied, "read-only mode")
+	}
+
+	ci, err := g.server.DB.Collections.Create(protoToCollectionInfo(req))
+	if err != nil {
+		return nil, collectionStatus(err)
+	}
+
+	return collectionInfoToProto(ci), nil
+}
+
+// UpdateCollection implements the UpdateCollection RPC
+func (g *GRPCServer) UpdateCollection(ctx context.Context, req *proto.CollectionInfo) (*proto.CollectionInfo, error) {
+	if g.server.Mode == ModeRead {
+		return nil, status.Error(codes.PermissionDenied, "read-only mode")
+	}
+
+	ci, err := g.server.DB.Collections.Update(protoToCollectionInfo(req))
+	if err != nil {
+		return nil, collectionStatus(err)
+	}
+
+	return collectionInfoToProto(ci), nil
+}
+
+// ListCollections implements the ListCollections RPC
+func (g *GRPCServer) ListCollections(ctx context.Context, req *proto.ListCollectionsRequest) (*proto.ListCollectionsResponse, error) {
+	list := g.server.DB.Collections.List()
+	caller := g.server.caller(ctx)
+
+	resp := &proto.ListCollectionsResponse{Collections: make([]*proto.CollectionInfo, 0, len(list))}
+	for i := range list {
+		if caller.can(RoleRead, list[i].Name) {
+			resp.Collections = append(resp.Collections, collectionInfoToProto(&list[i]))
+		}
+	}
 
 	return resp, nil
 }
+
+// DescribeCollection implements the DescribeCollection RPC
+func (g *GRPCServer) DescribeCollection(ctx context.Context, req *proto.CollectionNameRequest) (*proto.CollectionDescription, error) {
+	if req.Name == "" {
+		return nil, status.Error(codes.InvalidArgument, "missing collection name")
+	}
+
+	desc, err := g.server.DB.Collections.Describe(req.Name)
+	if err != nil {
+		return nil, collectionStatus(err)
+	}
+
+	return &proto.CollectionDescription{
+		Info:          collectionInfoToProto(&desc.CollectionInfo),
+		DocumentCount: int32(desc.DocumentCount),
+		RevisionCount: int32(desc.RevisionCount),
+		HasSchema:     desc.HasSchema,
+	}, nil
+}
+
+// RenameCollection implements the RenameCollection RPC
+func (g *GRPCServer) RenameCollection(ctx context.Context, req *proto.CopyCollectionRequest) (*proto.CopyCollectionResponse, error) {
+	if g.server.Mode == ModeRead {
+		return nil, status.Error(codes.PermissionDenied, "read-only mode")
+	}
+
+	n, err := g.server.DB.Collections.Rename(req.Name, req.NewName)
+	if err != nil {
+		return nil, collectionStatus(err)
+	}
+
+	return &proto.CopyCollectionResponse{Status: "renamed", Collection: req.NewName, Documents: int32(n)}, nil
+}
+
+// CloneCollection implements the CloneCollection RPC
+func (g *GRPCServer) CloneCollection(ctx context.Context, req *proto.CopyCollectionRequest) (*proto.CopyCollectionResponse, error) {
+	if g.server.Mode == ModeRead {
+		return nil, status.Error(codes.PermissionDenied, "read-only mode")
+	}
+
+	n, err := g.server.DB.Collections.Clone(req.Name, req.NewName)
+	if err != nil {
+		return nil, collectionStatus(err)
+	}
+
+	return &proto.CopyCollectionResponse{Status: "cloned", Collection: req.NewName, Documents: int32(n)}, nil
+}
+
+// DeleteCollection implements the DeleteCollection RPC
+func (g *GRPCServer) DeleteCollection(ctx context.Context, req *proto.DeleteCollectionRequest) (*proto.DeleteCollectionResponse, error) {
+	if g.server.Mode == ModeRead {
+		return nil, status.Error(codes.PermissionDenied, "read-only mode")
+	}
+
+	if req.Collection == "" {
+		return nil, status.Error(codes.InvalidArgument, "missing collection")
+	}
+
+	n, err := g.server.DB.Collections.Delete(req.Collection)
+	if err != nil {
+		return nil, collectionStatus(err)
+	}
+
+	return &proto.DeleteCollectionResponse{Status: "deleted", Collection: req.Collection, DeletedCount: int32(n)}, nil
+}
+
+// collectionStatus maps registry errors to gRPC status codes
+func collectionStatus(err error) error {
+	return grpcError(err, codes.InvalidArgument)
+}
+
+// Helper: convert internal CollectionInfo to proto
+func collectionInfoToProto(ci *storage.CollectionInfo) *proto.CollectionInfo {
+	return &proto.CollectionInfo{
+		Name:           ci.Name,
+		Description:    ci.Description,
+		DefaultLang:    ci.DefaultLang,
+		RevisionPolicy: ci.RevisionPolicy,
+		Compression:    ci.Compression,
+		CreatedAt:      ci.CreatedAt,
+		UpdatedAt:      ci.UpdatedAt,
+	}
+}
+
+// Helper: convert proto CollectionInfo to internal
+func protoToCollectionInfo(p *proto.CollectionInfo) storage.CollectionInfo {
+	return storage.CollectionInfo{
+		Name:           p.Name,
+		Description:    p.Description,
+		DefaultLang:    p.DefaultLang,
+		RevisionPolicy: p.RevisionPolicy,
+		Compression:    p.Compression,
+	}
+}
+
+// healthCheckInterval is how often the grpc.health.v1 status is refreshed
+const healthCheckInterval = 5 * time.Second
+
+// watchHealth keeps the grpc.health.v1 serving status in sync with database health
+// until shutdown begins
+func watchHealth(s *Server, hs *health.Server) {
+	t := time.NewTicker(healthCheckInterval)
+	defer t.Stop()
+	for {
+		st := healthpb.HealthCheckResponse_SERVING
+		if err := s.DB.Ping(); err != nil {
+			st = healthpb.HealthCheckResponse_NOT_SERVING
+		}
+		hs.SetServingStatus("", st)
+		hs.SetServingStatus(proto.MDDB_ServiceDesc.ServiceName, st)
+		select {
+		case <-t.C:
+		case <-s.stopping:
+			hs.Shutdown() // NOT_SERVING from now on
+			return
+		}
+	}
+}
+
+// Delete implements the Delete RPC
+func (g *GRPCServer) Delete(ctx context.Context, req *proto.DeleteRequest) (*proto.DeleteResponse, error) {
+	if g.server.Mode == ModeRead {
+		return nil, status.Error(codes.PermissionDenied, "read-only mode")
+	}
+
+	req.Lang = g.server.DB.Collections.DefaultLang(req.Collection, req.Lang)
+	if err := g.server.DB.Delete(req.Collection, req.Key, req.Lang); err != nil {
+		return nil, grpcError(err, codes.Internal)
+	}
+
+	return &proto.DeleteResponse{Status: "deleted", Collection: req.Collection, Key: req.Key, Lang: req.Lang}, nil
+}
+
+// Health implements the Health RPC
+func (g *GRPCServer) Health(ctx context.Context, req *proto.HealthRequest) (*proto.HealthResponse, error) {
+	if err := g.server.DB.Ping(); err != nil {
+		return &proto.HealthResponse{Status: "unhealthy", Mode: string(g.server.Mode), Error: err.Error()}, nil
+	}
+	return &proto.HealthResponse{Status: "healthy", Mode: string(g.server.Mode)}, nil
+}
+
+// CreateAPIKey implements the CreateAPIKey RPC
+func (g *GRPCServer) CreateAPIKey(ctx context.Context, req *proto.CreateAPIKeyRequest) (*proto.APIKey, error) {
+	if g.server.Mode == ModeRead {
+		return nil, status.Error(codes.PermissionDenied, "read-only mode")
+	}
+	if err := validateAPIKeyRequest(APIKeyRequest{Name: req.Name, Roles: req.Roles}); err != nil {
+		return nil, status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	key, secret, err := g.server.DB.APIKeys.Create(req.Name, req.Roles)
+	if err != nil {
+		return nil, grpcError(err, codes.Internal)
+	}
+	pk := apiKeyToProto(key)
+	pk.Key = secret
+	return pk, nil
+}
+
+// ListAPIKeys implements the ListAPIKeys RPC
+func (g *GRPCServer) ListAPIKeys(ctx context.Context, req *proto.ListAPIKeysRequest) (*proto.ListAPIKeysResponse, error) {
+	keys, err := g.server.DB.APIKeys.List()
+	if err != nil {
+		return nil, grpcError(err, codes.Internal)
+	}
+
+	resp := &proto.ListAPIKeysResponse{Keys: make([]*proto.APIKey, len(keys))}
+	for i := range keys {
+		resp.Keys[i] = apiKeyToProto(&keys[i])
+	}
+	return resp, nil
+}
+
+// DeleteAPIKey implements the DeleteAPIKey RPC
+func (g *GRPCServer) DeleteAPIKey(ctx context.Context, req *proto.DeleteAPIKeyRequest) (*proto.DeleteAPIKeyResponse, error) {
+	if g.server.Mode == ModeRead {
+		return nil, status.Error(codes.PermissionDenied, "read-only mode")
+	}
+	if err := g.server.DB.APIKeys.Delete(req.Id); err != nil {
+		return nil, grpcError(err, codes.Internal)
+	}
+	return &proto.DeleteAPIKeyResponse{Status: "deleted"}, nil
+}
+
+// WhoAmI implements the WhoAmI RPC
+func (g *GRPCServer) WhoAmI(ctx context.Context, req *proto.WhoAmIRequest) (*proto.WhoAmIResponse, error) {
+	p := g.server.caller(ctx)
+	return &proto.WhoAmIResponse{Subject: p.Subject, Method: p.Method, Roles: p.Roles}, nil
+}
+
+func apiKeyToProto(k *storage.APIKey) *proto.APIKey {
+	return &proto.APIKey{Id: k.ID, Name: k.Name, Roles: k.Roles, CreatedAt: k.CreatedAt}
+}
@@ -0,0 +1,131 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"net/http"
+	"os/exec"
+	"time"
+
+	json "github.com/goccy/go-json"
+	"github.com/tradik/mddb/services/mddbd/storage"
+)
+
+const (
+	hookTimeout   = 10 * time.Second // bounds each webhook call and hook command
+	hookWorkers   = 4                // hooks that run at once
+	hookQueueSize = 1024             // events waiting for a worker; more are dropped
+)
+
+const (
+	hookAdd    = "add"
+	hookUpdate = "update"
+)
+
+// HookEvent is the JSON body of webhooks and the standard input of hook commands
+type HookEvent struct {
+	Event      string       `json:"event"` // add | update
+	Collection string       `json:"collection"`
+	Document   *storage.Doc `json:"document"`
+}
+
+// hookJob is an event on its way to the hooks configured when it was written
+type hookJob struct {
+	event string
+	url   string
+	argv  []string
+	body  []byte
+}
+
+// startHooks starts the workers that run hooks. Writes only queue events, so a slow
+// hook can't pile up goroutines and processes; the workers stop when shutdown begins.
+func (s *Server) startHooks() {
+	s.hookJobs = make(chan hookJob, hookQueueSize)
+	for range hookWorkers {
+		s.goBackground(s.hookWorker)
+	}
+}
+
+func (s *Server) hookWorker() {
+	for {
+		select {
+		case job := <-s.hookJobs:
+			job.run()
+		case <-s.stopping:
+			return
+		}
+	}
+}
+
+// runHooks notifies the configured post-add or post-update hooks of a document written
+// by a single-document request. Batches, transactions, imports and loads don't call it.
+// Hooks run in the background; failures are logged and don't affect the write, and
+// events are dropped while the queue is full, with a warning and mddb_hook_events_dropped_total.
+func (s *Server) runHooks(event, collection string, doc *storage.Doc) {
+	h := s.hooks.Load()
+	if h == nil || s.hookJobs == nil {
+		return
+	}
+	url, argv := h.PostAddWebhookURL, h.PostAddExec
+	if event == hookUpdate {
+		url, argv = h.PostUpdateWebhookURL, h.PostUpdateExec
+	}
+	if url == "" && len(argv) == 0 {
+		return
+	}
+	body, err := json.Marshal(HookEvent{Event: event, Collection: collection, Document: doc})
+	if err != nil {
+		errorf("Encoding %s hook event failed: %v", event, err)
+		return
+	}
+	select {
+	case s.hookJobs <- hookJob{event: event, url: url, argv: argv, body: body}:
+	default:
+		s.Metrics.hookDropped(event)
+		warnf("⚠️  Hook queue full, dropping post-%s event for %s/%s", event, collection, doc.Key)
+	}
+}
+
+func (job hookJob) run() {
+	if job.url != "" {
+		if err := postWebhook(job.url, job.body); err != nil {
+			warnf("⚠️  Post-%s webhook %s failed: %v", job.event, job.url, err)
+		}
+	}
+	if len(job.argv) > 0 {
+		if err := runHookCommand(job.argv, job.body); err != nil {
+			warnf("⚠️  Post-%s hook %s failed: %v", job.event, job.argv[0], err)
+		}
+	}
+}
+
+func postWebhook(url string, body []byte) error {
+	ctx, cancel := context.WithTimeout(context.Background(), hookTimeout)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return err
+	}
+	_ = resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("status %s", resp.Status)
+	}
+	return nil
+}
+
+func runHookCommand(argv []string, event []byte) error {
+	ctx, cancel := context.WithTimeout(context.Background(), hookTimeout)
+	defer cancel()
+	cmd := exec.CommandContext(ctx, argv[0], argv[1:]...)
+	cmd.Stdin = bytes.NewReader(event)
+	if out, err := cmd.CombinedOutput(); err != nil {
+		return fmt.Errorf("%w: %s", err, bytes.TrimSpace(out))
+	}
+	return nil
+}
@@ -0,0 +1,55 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/tradik/mddb/services/mddbd/storage"
+)
+
+func TestHooksAreBounded(t *testing.T) {
+	var running, most, delivered atomic.Int32
+	release := make(chan struct{})
+	hook := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		n := running.Add(1)
+		for m := most.Load(); n > m && !most.CompareAndSwap(m, n); m = most.Load() {
+		}
+		<-release
+		running.Add(-1)
+		delivered.Add(1)
+	}))
+	defer hook.Close()
+
+	s, _ := newTestServer(t, false)
+	s.hooks.Store(&Hooks{PostAddWebhookURL: hook.URL})
+	s.startHooks()
+	defer func() {
+		close(s.stopping)
+		s.background.Wait()
+	}()
+
+	const events = hookWorkers + hookQueueSize + 10
+	for range events {
+		s.runHooks(hookAdd, "blog", &storage.Doc{Key: "post"})
+	}
+	waitFor(t, "the workers to call the webhook", func() bool { return running.Load() == hookWorkers })
+	time.Sleep(50 * time.Millisecond)
+	if m := most.Load(); m != hookWorkers {
+		t.Errorf("%d webhook calls at once, want %d", m, hookWorkers)
+	}
+
+	close(release)
+	// The queue takes hookQueueSize events, plus what the workers took meanwhile
+	waitFor(t, "the queued events", func() bool { return delivered.Load() >= hookQueueSize })
+	time.Sleep(50 * time.Millisecond)
+	if n := delivered.Load(); n > hookWorkers+hookQueueSize {
+		t.Errorf("%d events delivered, want at most %d with the overflow dropped", n, hookWorkers+hookQueueSize)
+	}
+	if dropped := testutil.ToFloat64(s.Metrics.hookDrops.WithLabelValues(hookAdd)); int(dropped)+int(delivered.Load()) != events {
+		t.Errorf("%v events counted as dropped and %d delivered, want %d in all", dropped, delivered.Load(), events)
+	}
+}
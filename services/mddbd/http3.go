@@ -1,13 +1,15 @@
 package main
 
 import (
+	"context"
 	"crypto/rand"
 	"crypto/rsa"
 	"crypto/tls"
 	"crypto/x509"
 	"crypto/x509/pkix"
 	"encoding/pem"
-	"log"
+	"errors"
+	"fmt"
 	"math/big"
 	"net"
 	"net/http"
@@ -23,12 +25,15 @@ type HTTP3Server struct {
 	addr    string
 }
 
-// NewHTTP3Server creates a new HTTP/3 server
-func NewHTTP3Server(addr string, handler http.Handler) (*HTTP3Server, error) {
-	// Generate self-signed certificate for development
-	tlsConfig, err := generateTLSConfig()
-	if err != nil {
-		return nil, err
+// NewHTTP3Server creates a new HTTP/3 server. Without a tlsConfig it uses a throwaway
+// self-signed certificate, which is only good for development.
+func NewHTTP3Server(addr string, handler http.Handler, tlsConfig *tls.Config) (*HTTP3Server, error) {
+	if tlsConfig == nil {
+		warnf("⚠️  HTTP/3 uses a self-signed development certificate; set tls.cert and tls.key (MDDB_TLS_CERT, MDDB_TLS_KEY)")
+		var err error
+		if tlsConfig, err = generateTLSConfig(); err != nil {
+			return nil, err
+		}
 	}
 
 	server := &http3.Server{
@@ -47,10 +52,10 @@ func NewHTTP3Server(addr string, handler http.Handler) (*HTTP3Server, error) {
 
 // Start starts the HTTP/3 server
 func (h3 *HTTP3Server) Start() error {
-	log.Printf("🚀 HTTP/3 (QUIC) server starting on %s", h3.addr)
-	log.Printf("   ⚡ 0-RTT reconnection enabled")
-	log.Printf("   ⚡ Multiplexing enabled")
-	log.Printf("   ⚡ Better congestion control")
+	infof("🚀 HTTP/3 (QUIC) server starting on %s", h3.addr)
+	infof("   ⚡ 0-RTT reconnection enabled")
+	infof("   ⚡ Multiplexing enabled")
+	infof("   ⚡ Better congestion control")
 	
 	return h3.server.ListenAndServe()
 }
@@ -60,6 +65,16 @@ func (h3 *HTTP3Server) Close() error {
 	return h3.server.Close()
 }
 
+// Shutdown stops accepting connections and waits for running requests until ctx ends,
+// then closes the rest
+func (h3 *HTTP3Server) Shutdown(ctx context.Context) error {
+	err := h3.server.Shutdown(ctx)
+	if errors.Is(err, context.DeadlineExceeded) {
+		_ = h3.server.Close()
+	}
+	return err
+}
+
 // generateTLSConfig generates a self-signed certificate for development
 func generateTLSConfig() (*tls.Config, error) {
 	// Generate private key
@@ -118,11 +133,15 @@ func generateTLSConfig() (*tls.Config, error) {
 	return tlsConfig, nil
 }
 
-// HTTP3Middleware adds HTTP/3 specific headers
-func HTTP3Middleware(next http.Handler) http.Handler {
+// HTTP3Middleware adds HTTP/3 specific headers. Alt-Svc advertises the port of http3Addr.
+func HTTP3Middleware(next http.Handler, http3Addr string) http.Handler {
+	altSvc := `h3=":443"; ma=2592000`
+	if _, port, err := net.SplitHostPort(http3Addr); err == nil && port != "" {
+		altSvc = fmt.Sprintf(`h3=":%s"; ma=2592000`, port)
+	}
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 		// Add Alt-Svc header to advertise HTTP/3
-		w.Header().Set("Alt-Svc", `h3=":443"; ma=2592000`)
+		w.Header().Set("Alt-Svc", altSvc)
 		
 		// Add QUIC-specific headers
 		w.Header().Set("X-Protocol", r.Proto)
@@ -0,0 +1,143 @@
+package main
+
+import (
+	"io"
+	"net/http"
+	"strconv"
+
+	json "github.com/goccy/go-json"
+	"github.com/tradik/mddb/services/mddbd/proto"
+	"github.com/tradik/mddb/services/mddbd/storage"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+// --- HTTP handler
+
+// handleImport imports the request body. Options are passed as query parameters:
+// collection, format, onConflict, dryRun, lang, chunkSize, saveRevision and progress.
+// With progress=true the response is NDJSON with one line per committed chunk.
+func (s *Server) handleImport(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+	opts := storage.ImportOptions{
+		Collection: q.Get("collection"),
+		Format:     q.Get("format"),
+		OnConflict: q.Get("onConflict"),
+		Lang:       q.Get("lang"),
+	}
+	opts.DryRun, _ = strconv.ParseBool(q.Get("dryRun"))
+	opts.SaveRevision, _ = strconv.ParseBool(q.Get("saveRevision"))
+	opts.ChunkSize, _ = strconv.Atoi(q.Get("chunkSize"))
+	if err := opts.Normalize(); err != nil {
+		bad(w, err)
+		return
+	}
+
+	var progress func(*storage.ImportResult)
+	if streamProgress, _ := strconv.ParseBool(q.Get("progress")); streamProgress {
+		w.Header().Set("Content-Type", "application/x-ndjson")
+		enc := json.NewEncoder(w)
+		flusher, _ := w.(http.Flusher)
+		progress = func(res *storage.ImportResult) {
+			_ = enc.Encode(res)
+			if flusher != nil {
+				flusher.Flush()
+			}
+		}
+	}
+
+	res, err := s.DB.Import(r.Body, opts, progress)
+	if progress != nil {
+		if res != nil {
+			progress(res)
+		}
+		return
+	}
+	if err != nil {
+		writeError(w, importError(err, res), codes.InvalidArgument)
+		return
+	}
+	ok(w, res)
+}
+
+// importError classifies an aborted import and records in its metadata how far the
+// import got; committed chunks stay committed
+func importError(err error, res *storage.ImportResult) *APIError {
+	e := *toAPIError(err, codes.InvalidArgument)
+	if res == nil {
+		return &e
+	}
+	metadata := map[string]string{"processed": strconv.Itoa(res.Total), "chunks": strconv.Itoa(res.Chunks)}
+	for k, v := range e.Metadata {
+		metadata[k] = v
+	}
+	e.Metadata = metadata
+	return &e
+}
+
+// --- gRPC handler
+
+// Import implements the Import RPC (client streaming)
+func (g *GRPCServer) Import(stream proto.MDDB_ImportServer) error {
+	first, err := stream.Recv()
+	if err != nil {
+		return err
+	}
+	if first.Options == nil {
+		return status.Error(codes.InvalidArgument, "first chunk must carry options")
+	}
+	opts := storage.ImportOptions{
+		Collection:   first.Options.Collection,
+		Format:       first.Options.Format,
+		OnConflict:   first.Options.OnConflict,
+		DryRun:       first.Options.DryRun,
+		Lang:         first.Options.Lang,
+		ChunkSize:    int(first.Options.ChunkSize),
+		SaveRevision: first.Options.SaveRevision,
+	}
+	if err := opts.Normalize(); err != nil {
+		return status.Error(codes.InvalidArgument, err.Error())
+	}
+
+	// Feed uploaded chunks to the importer as they arrive
+	pr, pw := io.Pipe()
+	go func() {
+		if _, err := pw.Write(first.Data); err != nil {
+			return
+		}
+		for {
+			chunk, err := stream.Recv()
+			if err == io.EOF {
+				_ = pw.Close()
+				return
+			}
+			if err != nil {
+				_ = pw.CloseWithError(err)
+				return
+			}
+			if _, err := pw.Write(chunk.Data); err != nil {
+				return
+			}
+		}
+	}()
+
+	res, err := g.server.DB.Import(pr, opts, nil)
+	_ = pr.CloseWithError(io.ErrClosedPipe)
+	if err != nil {
+		if stream.Context().Err() != nil {
+			return status.FromContextError(stream.Context().Err()).Err()
+		}
+		return importError(err, res)
+	}
+
+	return stream.SendAndClose(&proto.ImportResponse{
+		Total:   int32(res.Total),
+		Added:   int32(res.Added),
+		Updated: int32(res.Updated),
+		Skipped: int32(res.Skipped),
+		Failed:  int32(res.Failed),
+		Chunks:  int32(res.Chunks),
+		Errors:  res.Errors,
+		DryRun:  res.DryRun,
+	})
+}
@@ -0,0 +1,67 @@
+package main
+
+import (
+	"net/http"
+	"testing"
+
+	json "github.com/goccy/go-json"
+	"github.com/tradik/mddb/services/mddbd/storage"
+)
+
+func TestImportErrors(t *testing.T) {
+	s, ts := newTestServer(t, false)
+	if _, err := s.DB.Add(storage.AddRequest{Collection: "blog", Key: "b", Lang: "en", ContentMD: "# b"}); err != nil {
+		t.Fatal(err)
+	}
+	const docs = `{"key":"a","lang":"en","contentMd":"# a"}
+{"key":"b","lang":"en","contentMd":"# b"}
+`
+	cases := []struct {
+		name   string
+		query  string
+		body   string
+		status int
+		reason string
+		meta   map[string]string
+	}{
+		{
+			name:   "conflict",
+			query:  "collection=blog&onConflict=fail&chunkSize=1",
+			body:   docs,
+			status: http.StatusConflict,
+			reason: ReasonDocumentExists,
+			meta:   map[string]string{"processed": "2", "chunks": "1"},
+		},
+		{
+			name:   "malformed",
+			query:  "collection=blog",
+			body:   docs + "{not json\n",
+			status: http.StatusBadRequest,
+			reason: ReasonBadRequest,
+			meta:   map[string]string{"processed": "2", "chunks": "0"},
+		},
+		{name: "bad options", query: "collection=blog&onConflict=merge", status: http.StatusBadRequest, reason: ReasonBadRequest},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			resp := post(t, ts.URL+"/v1/import?"+c.query, "", c.body)
+			var apiErr APIError
+			if err := json.NewDecoder(resp.Body).Decode(&apiErr); err != nil {
+				t.Fatal(err)
+			}
+			if resp.StatusCode != c.status || apiErr.Reason != c.reason {
+				t.Errorf("%d %s, want %d %s", resp.StatusCode, apiErr.Reason, c.status, c.reason)
+			}
+			for k, v := range c.meta {
+				if apiErr.Metadata[k] != v {
+					t.Errorf("metadata %s = %q, want %q", k, apiErr.Metadata[k], v)
+				}
+			}
+		})
+	}
+
+	// The chunk committed before the conflict stays
+	if _, err := s.DB.Get("blog", "a", "en"); err != nil {
+		t.Errorf("Get(a): %v", err)
+	}
+}
@@ -0,0 +1,192 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"os/signal"
+	"sync"
+	"syscall"
+	"time"
+
+	"google.golang.org/grpc"
+)
+
+// errShuttingDown rejects requests that arrive once shutdown has begun
+var errShuttingDown = errors.New("server is shutting down")
+
+// shutdownExempt lists the HTTP paths and RPC names still served during shutdown, so
+// probes see it instead of a rejected request
+var shutdownExempt = map[string]bool{
+	"/health":    true,
+	"/v1/health": true,
+	"/ready":     true,
+	"/v1/ready":  true,
+	"/metrics":   true,
+	"Health":     true,
+}
+
+// listener is a server the lifecycle runs and drains on shutdown
+type listener struct {
+	name     string
+	serve    func() error // blocks; http.ErrServerClosed and grpc.ErrServerStopped mean a clean stop
+	shutdown func(ctx context.Context) error
+	optional bool // a failure is logged instead of shutting the server down
+}
+
+// goBackground runs fn until shutdown, which waits for it before closing the database.
+// fn returns once s.stopping is closed.
+func (s *Server) goBackground(fn func()) {
+	s.background.Add(1)
+	go func() {
+		defer s.background.Done()
+		fn()
+	}()
+}
+
+// run serves on the listeners until SIGINT or SIGTERM, or until a required listener
+// fails, then shuts down. A second signal exits right away. SIGHUP reloads the config.
+func (s *Server) run(listeners []listener) error {
+	hup := make(chan os.Signal, 1)
+	signal.Notify(hup, syscall.SIGHUP)
+	defer signal.Stop(hup)
+	sig := make(chan os.Signal, 2)
+	signal.Notify(sig, syscall.SIGINT, syscall.SIGTERM)
+	defer signal.Stop(sig)
+
+	failed := make(chan error, len(listeners))
+	for _, l := range listeners {
+		go func() {
+			err := l.serve()
+			if err == nil || errors.Is(err, http.ErrServerClosed) || errors.Is(err, grpc.ErrServerStopped) {
+				return
+			}
+			if l.optional {
+				warnf("⚠️  %s server error: %v", l.name, err)
+				return
+			}
+			failed <- fmt.Errorf("%s: %w", l.name, err)
+		}()
+	}
+	s.ready.Store(true)
+
+	var err error
+wait:
+	for {
+		select {
+		case <-hup:
+			s.reload()
+		case got := <-sig:
+			infof("Received %s, shutting down (timeout %s)", got, s.ShutdownTimeout)
+			break wait
+		case err = <-failed:
+			infof("Shutting down: %v", err)
+			break wait
+		}
+	}
+	go func() {
+		got := <-sig
+		infof("Received %s again, exiting without finishing the shutdown", got)
+		os.Exit(1)
+	}()
+	return errors.Join(err, s.shutdown(listeners))
+}
+
+// shutdown stops the server in order: readiness turns off and new requests get 503, the
+// listeners stop accepting and wait up to ShutdownTimeout for running requests,
+// background work stops and the database flushes its index queue and WAL and closes.
+func (s *Server) shutdown(listeners []listener) error {
+	start := time.Now()
+	s.ready.Store(false)
+	s.draining.Store(true)
+	close(s.stopping)
+	if s.ShutdownDelay > 0 {
+		// Give load balancers time to see /ready fail before connections are refused;
+		// requests they still send get 503 and can be retried elsewhere
+		infof("Not ready; waiting %s before closing listeners", s.ShutdownDelay)
+		time.Sleep(s.ShutdownDelay)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), s.ShutdownTimeout)
+	defer cancel()
+	errs := make([]error, len(listeners)+1)
+	var wg sync.WaitGroup
+	for i, l := range listeners {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := l.shutdown(ctx); err != nil {
+				errs[i] = fmt.Errorf("%s: %w", l.name, err)
+			}
+		}()
+	}
+	wg.Wait()
+	infof("Listeners drained in %s", time.Since(start).Round(time.Millisecond))
+
+	s.background.Wait()
+	if err := s.DB.Close(); err != nil {
+		errs[len(listeners)] = fmt.Errorf("close database: %w", err)
+	}
+	err := errors.Join(errs...)
+	if err != nil {
+		errorf("Shutdown finished with errors in %s: %v", time.Since(start).Round(time.Millisecond), err)
+	} else {
+		infof("Shutdown complete in %s", time.Since(start).Round(time.Millisecond))
+	}
+	return err
+}
+
+// httpListener drains srv on shutdown, closing the connections left at the deadline
+func httpListener(name string, srv *http.Server, serve func() error) listener {
+	return listener{
+		name:  name,
+		serve: serve,
+		shutdown: func(ctx context.Context) error {
+			err := srv.Shutdown(ctx)
+			if errors.Is(err, context.DeadlineExceeded) {
+				_ = srv.Close()
+			}
+			return err
+		},
+	}
+}
+
+// grpcListener stops gs gracefully, cancelling the calls left at the deadline
+func grpcListener(gs *grpc.Server, serve func() error) listener {
+	return listener{
+		name:  "gRPC",
+		serve: serve,
+		shutdown: func(ctx context.Context) error {
+			done := make(chan struct{})
+			go func() {
+				gs.GracefulStop()
+				close(done)
+			}()
+			select {
+			case <-done:
+				return nil
+			case <-ctx.Done():
+				gs.Stop()
+				return ctx.Err()
+			}
+		},
+	}
+}
+
+// handleReady reports whether the server takes requests: 503 while shutting down, during
+// a restore and when the database is unreachable
+func (s *Server) handleReady(w http.ResponseWriter, r *http.Request) {
+	if !s.ready.Load() {
+		w.WriteHeader(http.StatusServiceUnavailable)
+		_, _ = w.Write([]byte(`{"status":"shutting down"}`))
+		return
+	}
+	if err := s.DB.Ping(); err != nil {
+		w.WriteHeader(http.StatusServiceUnavailable)
+		_, _ = fmt.Fprintf(w, `{"status":"unavailable","error":%q}`, err.Error())
+		return
+	}
+	_, _ = w.Write([]byte(`{"status":"ready"}`))
+}
@@ -0,0 +1,153 @@
+package main
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"syscall"
+	"testing"
+	"time"
+
+	json "github.com/goccy/go-json"
+	"github.com/tradik/mddb/services/mddbd/storage"
+	bolt "go.etcd.io/bbolt"
+)
+
+func TestShutdownOnSIGTERM(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "mddb.db")
+	db, err := storage.Open(path, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	s := &Server{
+		DB:              db,
+		Mode:            ModeRW,
+		Metrics:         NewMetrics(db),
+		ShutdownTimeout: 10 * time.Second,
+		ShutdownDelay:   200 * time.Millisecond,
+		backupNow:       make(chan struct{}, 1),
+		stopping:        make(chan struct{}),
+	}
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	srv := &http.Server{Handler: s.httpHandler()}
+	ran := make(chan error, 1)
+	go func() {
+		ran <- s.run([]listener{httpListener("HTTP", srv, func() error { return srv.Serve(ln) })})
+	}()
+	base := "http://" + ln.Addr().String()
+	waitFor(t, "the server to get ready", s.ready.Load)
+
+	// A batch whose body is still arriving when the signal comes
+	body, send := io.Pipe()
+	batched := make(chan *http.Response, 1)
+	go func() {
+		resp, err := http.Post(base+"/v1/add-batch?collection=blog", "application/x-ndjson", body)
+		if err != nil {
+			t.Error(err)
+			close(batched)
+			return
+		}
+		batched <- resp
+	}()
+	line := func(i int) {
+		if _, err := fmt.Fprintf(send, `{"key":"post-%d","lang":"en","contentMd":"# %d","meta":{"tag":["a"]}}`+"\n", i, i); err != nil {
+			t.Fatal(err)
+		}
+	}
+	for i := range 10 {
+		line(i)
+	}
+	waitFor(t, "the batch to start", func() bool { return s.gate.running() == 1 })
+
+	if err := syscall.Kill(os.Getpid(), syscall.SIGTERM); err != nil {
+		t.Fatal(err)
+	}
+	waitFor(t, "shutdown to begin", s.draining.Load)
+	if s.ready.Load() {
+		t.Error("still ready while shutting down")
+	}
+
+	// New requests are turned away while the listeners wait for load balancers
+	resp, err := http.Get(base + "/v1/search?collection=blog")
+	if err != nil {
+		t.Fatal(err)
+	}
+	_ = resp.Body.Close()
+	if resp.StatusCode != http.StatusServiceUnavailable {
+		t.Errorf("request during shutdown: %d, want 503", resp.StatusCode)
+	}
+	resp, err = http.Get(base + "/ready")
+	if err != nil {
+		t.Fatal(err)
+	}
+	_ = resp.Body.Close()
+	if resp.StatusCode != http.StatusServiceUnavailable {
+		t.Errorf("/ready during shutdown: %d, want 503", resp.StatusCode)
+	}
+
+	// The batch finishes although shutdown has begun
+	for i := 10; i < 20; i++ {
+		line(i)
+	}
+	_ = send.Close()
+	resp, ok := <-batched
+	if !ok {
+		t.FailNow()
+	}
+	defer resp.Body.Close()
+	var res storage.AddBatchResult
+	if err := json.NewDecoder(resp.Body).Decode(&res); err != nil {
+		t.Fatal(err)
+	}
+	if resp.StatusCode != http.StatusOK || res.Added != 20 {
+		t.Errorf("batch: %d with %d added, want 200 with 20", resp.StatusCode, res.Added)
+	}
+
+	select {
+	case err := <-ran:
+		if err != nil {
+			t.Fatal(err)
+		}
+	case <-time.After(10 * time.Second):
+		t.Fatal("shutdown did not finish")
+	}
+	if err := db.Ping(); !errors.Is(err, bolt.ErrDatabaseNotOpen) {
+		t.Errorf("Ping after shutdown: %v, want the database closed", err)
+	}
+
+	// Everything is on disk with the index queue flushed
+	bdb, err := bolt.Open(path, 0600, &bolt.Options{ReadOnly: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer bdb.Close()
+	err = bdb.View(func(tx *bolt.Tx) error {
+		if n := tx.Bucket([]byte("idxqueue")).Stats().KeyN; n != 0 {
+			t.Errorf("%d index jobs left in the queue", n)
+		}
+		if n := tx.Bucket([]byte("docs")).Stats().KeyN; n != 20 {
+			t.Errorf("%d documents on disk, want 20", n)
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+// waitFor polls cond for up to five seconds
+func waitFor(t *testing.T, what string, cond func() bool) {
+	t.Helper()
+	for deadline := time.Now().Add(5 * time.Second); !cond(); time.Sleep(5 * time.Millisecond) {
+		if time.Now().After(deadline) {
+			t.Fatalf("timed out waiting for %s", what)
+		}
+	}
+}
@@ -0,0 +1,61 @@
+package main
+
+import (
+	"log"
+	"net/http"
+	"sync/atomic"
+	"time"
+)
+
+// logLevel is the severity of a log message; messages below the configured level are dropped
+type logLevel int32
+
+const (
+	levelDebug logLevel = iota - 1
+	levelInfo
+	levelWarn
+	levelError
+)
+
+// logLevels maps the log.level setting to levels
+var logLevels = map[string]logLevel{
+	"debug": levelDebug,
+	"info":  levelInfo,
+	"warn":  levelWarn,
+	"error": levelError,
+}
+
+// minLogLevel is the configured level; it changes on reload
+var minLogLevel atomic.Int32
+
+func setLogLevel(name string) { minLogLevel.Store(int32(logLevels[name])) }
+
+func logEnabled(level logLevel) bool { return int32(level) >= minLogLevel.Load() }
+
+func logAt(level logLevel, format string, args ...any) {
+	if logEnabled(level) {
+		log.Printf(format, args...)
+	}
+}
+
+func debugf(format string, args ...any) { logAt(levelDebug, format, args...) }
+func infof(format string, args ...any)  { logAt(levelInfo, format, args...) }
+func warnf(format string, args ...any)  { logAt(levelWarn, format, args...) }
+func errorf(format string, args ...any) { logAt(levelError, format, args...) }
+
+// withAccessLog logs every HTTP request at debug level
+func withAccessLog(h http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if !logEnabled(levelDebug) {
+			h.ServeHTTP(w, r)
+			return
+		}
+		start := time.Now()
+		rec := &statusRecorder{ResponseWriter: w}
+		h.ServeHTTP(rec, r)
+		if rec.code == 0 {
+			rec.code = http.StatusOK
+		}
+		debugf("%s %s %s %d %s", r.RemoteAddr, r.Method, r.URL.RequestURI(), rec.code, time.Since(start).Round(time.Microsecond))
+	})
+}
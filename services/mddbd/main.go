@@ -1,20 +1,24 @@
 package main
 
 import (
-	"archive/zip"
-	"bytes"
+	"crypto/tls"
 	"errors"
+	"flag"
 	"fmt"
 	"io"
 	"log"
+	"net"
 	"net/http"
 	"os"
-	"sort"
+	"strconv"
 	"strings"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	json "github.com/goccy/go-json"
-	bolt "go.etcd.io/bbolt"
+	"github.com/tradik/mddb/services/mddbd/storage"
+	"google.golang.org/grpc/codes"
 )
 
 type AccessMode string
@@ -26,50 +30,36 @@ const (
 )
 
 type Server struct {
-	DB                 *bolt.DB
-	Path               string
-	Mode               AccessMode
-	Hooks              Hooks // optional extensions
-	BucketNames        BucketNames
-	Cache              *DocumentCache          // Read-through cache (legacy)
-	LockFreeCache      *LockFreeCache          // Lock-free cache (extreme performance)
-	IndexQueue         *IndexQueue             // Async metadata indexing
-	WAL                *WAL                    // Write-Ahead Log
-	MVCC               *MVCC                   // Multi-Version Concurrency Control
-	BloomFilters       *BloomFilterManager     // Bloom filters for negative lookups
-	DeltaEncoder       *DeltaEncoder           // Delta encoding for revisions
-	AdaptiveIndex      *AdaptiveIndexManager   // Adaptive indexing
-	AsyncIO            *AsyncIO                // Async I/O
-	ZeroCopy           *ZeroCopyManager        // Zero-copy I/O
-	SIMD               *SIMDProcessor          // Vectorized operations
-	ShardCluster       *ShardCluster           // Distributed sharding
-	finalBatchProcessor *FinalBatchProcessor   // Final optimized batch processor
-	UseExtreme         bool                    // Enable extreme performance features
-}
-
-// BucketNames caches bucket name byte slices to avoid repeated allocations
-type BucketNames struct {
-	Docs   []byte
-	IdxMeta []byte
-	Rev    []byte
-	ByKey  []byte
-}
-
+	DB              *storage.DB
+	Mode            AccessMode
+	Auth            *Auth     // nil = authentication disabled
+	TLS             *TLSFiles // nil = plaintext listeners
+	Metrics         *Metrics
+	BackupDir       string         // server-side backups are confined to this directory
+	DrainTimeout    time.Duration  // how long a restore waits for running requests
+	Backups         BackupSchedule // scheduled backups; a zero Interval disables them
+	ShutdownTimeout time.Duration  // how long shutdown waits for running requests
+	ShutdownDelay   time.Duration  // how long /ready reports 503 before listeners close
+
+	gate       gate                    // drains requests while a restore swaps the database file
+	backupNow  chan struct{}           // asks the backup scheduler for a backup right away
+	ready      atomic.Bool             // false until the listeners start and once shutdown begins
+	stopping   chan struct{}           // closed when shutdown begins
+	draining   atomic.Bool             // set once shutdown begins; new requests get 503
+	background sync.WaitGroup          // goroutines shutdown waits for; see goBackground
+	config     *Config                 // the running configuration
+	loadConfig func() (*Config, error) // reads the file and environment again for reload
+	hooks      atomic.Pointer[Hooks]   // replaced on reload
+	hookJobs   chan hookJob            // events for the hook workers; nil until startHooks
+	limiter    rateLimiter             // reconfigured on reload
+}
+
+// Hooks notify other systems of single-document writes; see runHooks
 type Hooks struct {
-	PostAddWebhookURL    string   // e.g. http://localhost:9000/hook/add
-	PostAddExec          []string // e.g. ["/usr/local/bin/on-add"]
-	PostUpdateWebhookURL string
-	PostUpdateExec       []string
-}
-
-type Doc struct {
-	ID        string              `json:"id"`        // generated
-	Key       string              `json:"key"`       // e.g. "homepage"
-	Lang      string              `json:"lang"`      // e.g. "en_GB"
-	Meta      map[string][]string `json:"meta"`      // meta values (multi)
-	ContentMD string              `json:"contentMd"` // raw markdown
-	AddedAt   int64               `json:"addedAt"`
-	UpdatedAt int64               `json:"updatedAt"`
+	PostAddWebhookURL    string   `yaml:"postAddWebhookUrl"` // e.g. http://localhost:9000/hook/add
+	PostAddExec          []string `yaml:"postAddExec"`       // e.g. ["/usr/local/bin/on-add"]
+	PostUpdateWebhookURL string   `yaml:"postUpdateWebhookUrl"`
+	PostUpdateExec       []string `yaml:"postUpdateExec"`
 }
 
 type AddRequest struct {
@@ -80,6 +70,21 @@ type AddRequest struct {
 	ContentMD  string              `json:"contentMd"`
 }
 
+// PatchRequest changes parts of an existing document; see storage.PatchRequest
+type PatchRequest struct {
+	Collection string              `json:"collection"`
+	Key        string              `json:"key"`
+	Lang       string              `json:"lang"`
+	Meta       []storage.MetaPatch `json:"meta"`
+	ContentMD  *string             `json:"contentMd"`
+	AppendMD   string              `json:"appendMd"`
+}
+
+// TransactionRequest is the body of /v1/transaction; see storage.TxOp
+type TransactionRequest struct {
+	Ops []storage.TxOp `json:"ops"`
+}
+
 type GetRequest struct {
 	Collection string            `json:"collection"`
 	Key        string            `json:"key"`
@@ -88,18 +93,19 @@ type GetRequest struct {
 }
 
 type SearchRequest struct {
-	Collection string              `json:"collection"`
-	FilterMeta map[string][]string `json:"filterMeta"` // AND over keys, OR over values
-	Sort       string              `json:"sort"`       // addedAt|updatedAt|key
-	Asc        bool                `json:"asc"`
-	Limit      int                 `json:"limit"`
-	Offset     int                 `json:"offset"`
+	Collection  string              `json:"collection"`
+	FilterMeta  map[string][]string `json:"filterMeta"` // AND over keys, OR over values
+	Sort        string              `json:"sort"`       // addedAt|updatedAt|key
+	Asc         bool                `json:"asc"`
+	Limit       int                 `json:"limit"`
+	Offset      int                 `json:"offset"`
+	Consistency string              `json:"consistency"` // eventual (default) | strong
 }
 
 type ExportRequest struct {
 	Collection string              `json:"collection"`
 	FilterMeta map[string][]string `json:"filterMeta"`
-	Format     string              `json:"format"` // ndjson|zip
+	Format     string              `json:"format"` // ndjson|zip|tar
 }
 
 type TruncateRequest struct {
@@ -118,153 +124,190 @@ type DeleteCollectionRequest struct {
 	Collection string `json:"collection"`
 }
 
-// getOptimizedBoltOptions returns optimized BoltDB options for performance
-func getOptimizedBoltOptions() *bolt.Options {
-	return &bolt.Options{
-		Timeout:         2 * time.Second,
-		NoFreelistSync:  true,                    // Don't sync freelist to disk on every commit (faster writes)
-		FreelistType:    bolt.FreelistMapType,    // Use hashmap for freelist (faster than array)
-		NoGrowSync:      false,                   // Sync after growing mmap (safer)
-		InitialMmapSize: 100 * 1024 * 1024,       // 100MB initial mmap (reduce remapping)
-	}
-}
+// fsckRequest is the /v1/fsck body
+type fsckRequest storage.FsckOptions
 
 func main() {
-	dbPath := env("MDDB_PATH", "mddb.db")
-	mode := AccessMode(env("MDDB_MODE", "wr")) // read|write|wr
-	
-	db, err := bolt.Open(dbPath, 0600, getOptimizedBoltOptions())
+	configPath := flag.String("config", env("MDDB_CONFIG", "mddbd.yaml"), "YAML config file; MDDB_* variables override its settings")
+	printConfig := flag.Bool("print-config", false, "print the effective configuration as YAML and exit")
+	flag.Parse()
+	// A config file named on the command line or in MDDB_CONFIG must exist
+	required := os.Getenv("MDDB_CONFIG") != ""
+	flag.Visit(func(f *flag.Flag) { required = required || f.Name == "config" })
+	loadConfig := func() (*Config, error) { return loadConfig(*configPath, required) }
+
+	cfg, err := loadConfig()
 	if err != nil {
 		log.Fatal(err)
 	}
-	defer func() {
-		if err := db.Close(); err != nil {
-			log.Printf("Error closing database: %v", err)
+	if *printConfig {
+		if err := writeConfig(os.Stdout, cfg); err != nil {
+			log.Fatal(err)
 		}
-	}()
+		return
+	}
+	setLogLevel(cfg.Log.Level)
+	dbPath := cfg.Storage.Path
+	useExtreme := cfg.Server.Extreme
+
+	// Closed by shutdown once the listeners have drained
+	db, err := storage.Open(dbPath, cfg.storageOptions())
+	if err != nil {
+		log.Fatal(err)
+	}
 
-	// Check for extreme performance mode
-	useExtreme := os.Getenv("MDDB_EXTREME") == "true"
-	
+	auth, err := newAuth(cfg.Auth, db)
+	if err != nil {
+		log.Fatal(err)
+	}
+	tlsFiles, err := newTLS(cfg.TLS)
+	if err != nil {
+		log.Fatal(err)
+	}
 	s := &Server{
-		DB:   db,
-		Path: dbPath,
-		Mode: mode,
-		BucketNames: BucketNames{
-			Docs:    []byte("docs"),
-			IdxMeta: []byte("idxmeta"),
-			Rev:     []byte("rev"),
-			ByKey:   []byte("bykey"),
-		},
-		Cache:         NewDocumentCache(1000, 300),     // 1000 docs, 5min TTL
-		LockFreeCache: NewLockFreeCache(10000, 300),    // 10k docs, 5min TTL (lock-free)
-		IndexQueue:    NewIndexQueue(nil, 4),           // 4 workers (will set server below)
-		BloomFilters:  NewBloomFilterManager(),         // Bloom filters
-		DeltaEncoder:  NewDeltaEncoder(),               // Delta encoding
-		AdaptiveIndex: NewAdaptiveIndexManager(),       // Adaptive indexing
-		AsyncIO:       NewAsyncIO(),                    // Async I/O
-		ZeroCopy:      NewZeroCopyManager(),            // Zero-copy I/O
-		SIMD:          NewSIMDProcessor(),              // Vectorized operations
-		ShardCluster:  NewShardCluster(4, 2),           // 4 shards, 2x replication
-		UseExtreme:    useExtreme,
+		DB:              db,
+		Mode:            cfg.Server.Mode,
+		Auth:            auth,
+		TLS:             tlsFiles,
+		Metrics:         NewMetrics(db),
+		BackupDir:       cfg.Backups.Dir,
+		DrainTimeout:    cfg.Server.RestoreDrainTimeout,
+		Backups:         cfg.Backups.BackupSchedule,
+		ShutdownTimeout: cfg.Server.ShutdownTimeout,
+		ShutdownDelay:   cfg.Server.ShutdownDelay,
+		config:          cfg,
+		loadConfig:      loadConfig,
+		backupNow:       make(chan struct{}, 1),
+		stopping:        make(chan struct{}),
+	}
+	s.applyReloadable(cfg)
+	s.startHooks()
+	if s.Backups.Interval > 0 {
+		s.goBackground(s.runBackupSchedule)
+	}
+	if auth != nil {
+		infof("🔒 Authentication enabled")
+	}
+	if tlsFiles != nil {
+		infof("🔒 TLS enabled (mutual TLS: %v)", tlsFiles.MutualTLS())
 	}
-	s.IndexQueue.server = s // Set server reference
-	
-	// Initialize extreme performance features
+
 	if useExtreme {
-		log.Println("🚀 Extreme Performance Mode ENABLED")
-		
-		// Initialize WAL
-		wal, err := NewWAL(dbPath, SyncPeriodic)
-		if err != nil {
-			log.Fatalf("Failed to initialize WAL: %v", err)
-		}
-		s.WAL = wal
-		log.Println("  ✓ WAL initialized (SyncPeriodic)")
-		
-		// Initialize MVCC
-		s.MVCC = NewMVCC()
-		log.Println("  ✓ MVCC initialized")
-		
-		log.Println("  ✓ Lock-Free Cache enabled")
-		log.Println("  ✓ Bloom Filters enabled")
-		log.Println("  ✓ Delta Encoding enabled")
-		log.Println("  ✓ Adaptive Compression enabled (Snappy + Zstd)")
-		log.Println("  ✓ Adaptive Indexing enabled")
-		log.Println("  ✓ Async I/O enabled")
-		log.Println("  ✓ Zero-Copy I/O enabled")
-		log.Println("  ✓ Vectorized Operations (SIMD) enabled")
-		log.Println("  ✓ Distributed Sharding enabled (4 shards, 2x replication)")
+		infof("🚀 Extreme Performance Mode ENABLED")
+		infof("  ✓ WAL initialized (SyncPeriodic)")
+		infof("  ✓ MVCC initialized")
+		infof("  ✓ Bloom Filters enabled")
+		infof("  ✓ Delta Encoding enabled")
+		infof("  ✓ Adaptive Compression enabled (Snappy + Zstd)")
+		infof("  ✓ Adaptive Indexing enabled")
+		infof("  ✓ Async I/O enabled")
+		infof("  ✓ Zero-Copy I/O enabled")
+		infof("  ✓ Vectorized Operations (SIMD) enabled")
+		infof("  ✓ Distributed Sharding enabled (4 shards, 2x replication)")
+	}
+
+	httpAddr := cfg.Server.HTTPAddress
+	grpcAddr := cfg.Server.GRPCAddress
+	http3Addr := cfg.Server.HTTP3Address
+
+	// Start HTTP server; with HTTP/3 enabled its responses advertise the HTTP/3 port
+	handler := s.httpHandler()
+	if useExtreme {
+		handler = HTTP3Middleware(handler, http3Addr)
 	}
-	
-	if err := s.ensureBuckets(); err != nil {
+	srv := &http.Server{Addr: httpAddr, Handler: handler}
+	ln, err := net.Listen("tcp", httpAddr)
+	if err != nil {
 		log.Fatal(err)
 	}
-
-	mux := http.NewServeMux()
-	mux.HandleFunc("/health", s.handleHealth)
-	mux.HandleFunc("/v1/health", s.handleHealth)
-	mux.HandleFunc("/v1/add", s.guardWrite(s.handleAdd))
-	mux.HandleFunc("/v1/get", s.handleGet)
-	mux.HandleFunc("/v1/search", s.handleSearch)
-	mux.HandleFunc("/v1/export", s.handleExport)
-	mux.HandleFunc("/v1/backup", s.handleBackup)
-	mux.HandleFunc("/v1/restore", s.guardWrite(s.handleRestore))
-	mux.HandleFunc("/v1/truncate", s.guardWrite(s.handleTruncate))
-	mux.HandleFunc("/v1/delete", s.guardWrite(s.handleDelete))
-	mux.HandleFunc("/v1/delete-collection", s.guardWrite(s.handleDeleteCollection))
-	mux.HandleFunc("/v1/stats", s.handleStats)
-
-	httpAddr := env("MDDB_ADDR", ":11023")
-	grpcAddr := env("MDDB_GRPC_ADDR", ":11024")
-
-	// Start HTTP server
-	go func() {
-		log.Printf("mddb HTTP listening on %s (mode=%s, db=%s)", httpAddr, s.Mode, dbPath)
-		if err := http.ListenAndServe(httpAddr, withJSON(mux)); err != nil {
-			log.Fatal(err)
-		}
-	}()
+	serveHTTP := func() error { return srv.Serve(ln) }
+	if s.TLS != nil {
+		srv.TLSConfig = s.TLS.Config("h2", "http/1.1")
+		serveHTTP = func() error { return srv.ServeTLS(ln, "", "") }
+		infof("mddb HTTPS listening on %s (mode=%s, db=%s)", httpAddr, s.Mode, dbPath)
+	} else {
+		infof("mddb HTTP listening on %s (mode=%s, db=%s)", httpAddr, s.Mode, dbPath)
+	}
+	listeners := []listener{httpListener("HTTP", srv, serveHTTP)}
 
 	// Start HTTP/3 server if extreme mode
 	if useExtreme {
-		http3Addr := env("MDDB_HTTP3_ADDR", ":11443")
-		go func() {
-			h3Server, err := NewHTTP3Server(http3Addr, HTTP3Middleware(withJSON(mux)))
-			if err != nil {
-				log.Printf("⚠️  Failed to start HTTP/3 server: %v", err)
-				return
-			}
-			if err := h3Server.Start(); err != nil {
-				log.Printf("⚠️  HTTP/3 server error: %v", err)
-			}
-		}()
+		var tlsConfig *tls.Config
+		if s.TLS != nil {
+			tlsConfig = s.TLS.Config("h3")
+		}
+		h3Server, err := NewHTTP3Server(http3Addr, handler, tlsConfig)
+		if err != nil {
+			warnf("⚠️  Failed to start HTTP/3 server: %v", err)
+		} else {
+			listeners = append(listeners, listener{name: "HTTP/3", serve: h3Server.Start, shutdown: h3Server.Shutdown, optional: true})
+		}
 	}
 
 	// Start gRPC server
-	log.Printf("mddb gRPC listening on %s (mode=%s, db=%s)", grpcAddr, s.Mode, dbPath)
-	if err := startGRPCServer(s, grpcAddr); err != nil {
+	grpcLn, err := startGRPCServer(s, grpcAddr)
+	if err != nil {
 		log.Fatal(err)
 	}
-}
+	infof("mddb gRPC listening on %s (mode=%s, db=%s)", grpcAddr, s.Mode, dbPath)
+	listeners = append(listeners, grpcLn)
 
-// --- helpers / buckets
-
-func (s *Server) ensureBuckets() error {
-	return s.DB.Update(func(tx *bolt.Tx) error {
-		_, _ = tx.CreateBucketIfNotExists(s.BucketNames.Docs)    // doc|collection|id -> json
-		_, _ = tx.CreateBucketIfNotExists(s.BucketNames.IdxMeta) // meta|collection|key|value|docID -> 1
-		_, _ = tx.CreateBucketIfNotExists(s.BucketNames.Rev)     // rev|collection|docID|ts -> json
-		_, _ = tx.CreateBucketIfNotExists(s.BucketNames.ByKey)   // bykey|collection|key|lang -> docID
-		return nil
-	})
+	if err := s.run(listeners); err != nil {
+		log.Fatal(err)
+	}
 }
 
-func kDoc(coll, id string) []byte          { return []byte("doc|" + coll + "|" + id) }
-func kByKey(coll, key, lang string) []byte { return []byte("bykey|" + coll + "|" + key + "|" + lang) }
-func kRevPrefix(coll, id string) []byte    { return []byte("rev|" + coll + "|" + id + "|") }
-func kMetaKeyPrefix(coll, mk, mv string) []byte {
-	return []byte("meta|" + coll + "|" + mk + "|" + mv + "|")
+// routes registers the HTTP API with its access rules
+func (s *Server) routes() *http.ServeMux {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/health", s.handleHealth)
+	mux.HandleFunc("/v1/health", s.handleHealth)
+	mux.HandleFunc("/ready", s.handleReady)
+	mux.HandleFunc("/v1/ready", s.handleReady)
+	mux.HandleFunc("/v1/add", s.guardWrite(s.authorize(RoleWrite, bodyCollections[AddRequest], s.handleAdd)))
+	mux.HandleFunc("/v1/patch", s.guardWrite(s.authorize(RoleWrite, bodyCollections[PatchRequest], s.handlePatch)))
+	mux.HandleFunc("/v1/transaction", s.guardWrite(s.authorize(RoleWrite, bodyCollections[TransactionRequest], s.handleTransaction)))
+	mux.HandleFunc("/v1/add-batch", s.guardWrite(s.authorize(RoleWrite, batchCollections, s.handleAddBatch)))
+	mux.HandleFunc("/v1/update-batch", s.guardWrite(s.authorize(RoleWrite, batchCollections, s.handleUpdateBatch)))
+	mux.HandleFunc("/v1/delete-batch", s.guardWrite(s.authorize(RoleWrite, batchCollections, s.handleDeleteBatch)))
+	mux.HandleFunc("/v1/get", s.authorize(RoleRead, bodyCollections[GetRequest], s.handleGet))
+	mux.HandleFunc("/v1/search", s.authorize(RoleRead, bodyCollections[SearchRequest], s.handleSearch))
+	mux.HandleFunc("/v1/export", s.authorize(RoleRead, bodyCollections[ExportRequest], s.handleExport))
+	mux.HandleFunc("/v1/import", s.guardWrite(s.authorize(RoleWrite, queryCollection, s.handleImport)))
+	mux.HandleFunc("/v1/backup", s.authorize(RoleAdmin, allCollections, s.handleBackup))
+	mux.HandleFunc("GET /v1/backup/stream", s.authorize(RoleAdmin, allCollections, s.handleBackupStream))
+	mux.HandleFunc("/v1/restore", s.guardWrite(s.authorize(RoleAdmin, allCollections, s.handleRestore)))
+	mux.HandleFunc("POST /v1/restore/upload", s.guardWrite(s.authorize(RoleAdmin, allCollections, s.handleRestoreUpload)))
+	mux.HandleFunc("GET /v1/dump", s.authorize(RoleAdmin, allCollections, s.handleDump))
+	mux.HandleFunc("POST /v1/load", s.guardWrite(s.authorize(RoleAdmin, allCollections, s.handleLoad)))
+	mux.HandleFunc("/v1/truncate", s.guardWrite(s.authorize(RoleAdmin, bodyCollections[TruncateRequest], s.handleTruncate)))
+	mux.HandleFunc("/v1/fsck", s.authorize(RoleAdmin, bodyCollections[fsckRequest], s.handleFsck))
+	mux.HandleFunc("/v1/compact", s.guardWrite(s.authorize(RoleAdmin, allCollections, s.handleCompact)))
+	mux.HandleFunc("/v1/delete", s.guardWrite(s.authorize(RoleWrite, bodyCollections[DeleteRequest], s.handleDelete)))
+	mux.HandleFunc("/v1/delete-collection", s.guardWrite(s.authorize(RoleAdmin, bodyCollections[DeleteCollectionRequest], s.handleDeleteCollection)))
+	mux.HandleFunc("/v1/stats", s.authorize(RoleRead, allCollections, s.handleStats))
+	mux.Handle("GET /metrics", s.Metrics.Handler()) // open to scrapers like /health; no document data
+	mux.HandleFunc("/v1/schema/set", s.guardWrite(s.authorize(RoleAdmin, bodyCollections[schemaSetRequest], s.handleSchemaSet)))
+	mux.HandleFunc("/v1/schema/get", s.authorize(RoleRead, bodyCollections[SchemaRequest], s.handleSchemaGet))
+	mux.HandleFunc("/v1/schema/delete", s.guardWrite(s.authorize(RoleAdmin, bodyCollections[SchemaRequest], s.handleSchemaDelete)))
+	mux.HandleFunc("/v1/collections", s.authorize(RoleRead, nil, s.handleCollectionsList))
+	mux.HandleFunc("/v1/collections/create", s.guardWrite(s.authorize(RoleAdmin, bodyCollections[collectionInfoRequest], s.handleCollectionCreate)))
+	mux.HandleFunc("/v1/collections/update", s.guardWrite(s.authorize(RoleAdmin, bodyCollections[collectionInfoRequest], s.handleCollectionUpdate)))
+	mux.HandleFunc("/v1/collections/describe", s.authorize(RoleRead, bodyCollections[CollectionRequest], s.handleCollectionDescribe))
+	mux.HandleFunc("/v1/collections/rename", s.guardWrite(s.authorize(RoleAdmin, bodyCollections[CollectionRequest], s.handleCollectionRename)))
+	mux.HandleFunc("/v1/collections/clone", s.guardWrite(s.authorize(RoleAdmin, bodyCollections[CollectionRequest], s.handleCollectionClone)))
+	mux.HandleFunc("/v1/collections/delete", s.guardWrite(s.authorize(RoleAdmin, bodyCollections[CollectionRequest], s.handleCollectionDelete)))
+	s.registerRESTRoutes(mux)
+	mux.HandleFunc("GET /v1/auth/whoami", s.authorize(RoleRead, nil, s.handleWhoAmI))
+	mux.HandleFunc("GET /v1/auth/keys", s.authorize(RoleAdmin, allCollections, s.handleAPIKeysList))
+	mux.HandleFunc("POST /v1/auth/keys", s.guardWrite(s.authorize(RoleAdmin, allCollections, s.handleAPIKeyCreate)))
+	mux.HandleFunc("DELETE /v1/auth/keys/{id}", s.guardWrite(s.authorize(RoleAdmin, allCollections, s.handleAPIKeyDelete)))
+	return mux
+}
+
+// httpHandler is the HTTP API with the middleware every HTTP listener shares
+func (s *Server) httpHandler() http.Handler {
+	return s.Metrics.Middleware(withAccessLog(withJSON(s.rateLimit(s.drain(s.routes())))))
 }
 
 // --- middleware
@@ -279,7 +322,7 @@ func withJSON(h http.Handler) http.Handler {
 func (s *Server) guardWrite(next http.HandlerFunc) http.HandlerFunc {
 	return func(w http.ResponseWriter, r *http.Request) {
 		if s.Mode == ModeRead {
-			http.Error(w, `{"error":"read-only mode"}`, http.StatusForbidden)
+			writeError(w, newAPIError(http.StatusForbidden, codes.PermissionDenied, ReasonReadOnly, "read-only mode", nil), codes.PermissionDenied)
 			return
 		}
 		next(w, r)
@@ -294,81 +337,62 @@ func (s *Server) handleAdd(w http.ResponseWriter, r *http.Request) {
 		bad(w, err)
 		return
 	}
-	if req.Collection == "" || req.Key == "" || req.Lang == "" {
-		bad(w, errors.New("missing fields"))
+
+	saved, err := s.DB.Add(storage.AddRequest{
+		Collection: req.Collection, Key: req.Key, Lang: req.Lang,
+		Meta: req.Meta, ContentMD: req.ContentMD, SaveRevision: true,
+	})
+	if err != nil {
+		bad(w, err)
 		return
 	}
+	s.runHooks(hookAdd, req.Collection, saved)
+	ok(w, saved)
+}
 
-	now := time.Now().Unix()
-	docID := genID(req.Collection, req.Key, req.Lang) // deterministic ID (collection|key|lang)
-
-	var saved Doc
-	err := s.DB.Update(func(tx *bolt.Tx) error {
-		bDocs := tx.Bucket([]byte("docs"))
-		bIdx := tx.Bucket([]byte("idxmeta"))
-		bRev := tx.Bucket([]byte("rev"))
-		bByK := tx.Bucket([]byte("bykey"))
-
-		// load existing
-		existing := Doc{}
-		if v := bDocs.Get(kDoc(req.Collection, docID)); v != nil {
-			if err := json.Unmarshal(v, &existing); err != nil {
-				return err
-			}
-		}
-		added := existing.AddedAt
-		if added == 0 {
-			added = now
-		}
+// handlePatch applies meta operations and content changes to an existing document
+func (s *Server) handlePatch(w http.ResponseWriter, r *http.Request) {
+	var req PatchRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		bad(w, err)
+		return
+	}
 
-		doc := Doc{
-			ID: docID, Key: req.Key, Lang: req.Lang, Meta: req.Meta,
-			ContentMD: req.ContentMD, AddedAt: added, UpdatedAt: now,
-		}
-		buf, _ := json.Marshal(doc)
-		if err := bDocs.Put(kDoc(req.Collection, docID), buf); err != nil {
-			return err
-		}
-		if err := bByK.Put(kByKey(req.Collection, req.Key, req.Lang), []byte(docID)); err != nil {
-			return err
-		}
+	saved, err := s.DB.Patch(storage.PatchRequest{
+		Collection: req.Collection, Key: req.Key, Lang: req.Lang,
+		Meta: req.Meta, ContentMD: req.ContentMD, AppendMD: req.AppendMD, SaveRevision: true,
+	}, nil)
+	if err != nil {
+		bad(w, err)
+		return
+	}
+	s.runHooks(hookUpdate, req.Collection, saved)
+	ok(w, saved)
+}
 
-		// Only reindex metadata if it has changed (MAJOR OPTIMIZATION)
-		if metadataChanged(existing.Meta, doc.Meta) {
-			// delete old indices
-			if existing.ID != "" && existing.Meta != nil {
-				for mk, vals := range existing.Meta {
-					for _, mv := range vals {
-						prefix := append(kMetaKeyPrefix(req.Collection, mk, mv), []byte(existing.ID)...)
-						_ = bIdx.Delete(prefix)
-					}
-				}
-			}
-			// add new indices
-			for mk, vals := range doc.Meta {
-				for _, mv := range vals {
-					key := append(kMetaKeyPrefix(req.Collection, mk, mv), []byte(doc.ID)...)
-					if err := bIdx.Put(key, []byte("1")); err != nil {
-						return err
-					}
-				}
-			}
+// handleTransaction commits mixed add, patch and delete operations all or nothing
+func (s *Server) handleTransaction(w http.ResponseWriter, r *http.Request) {
+	var req TransactionRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		bad(w, err)
+		return
+	}
+	// Revisions as in /v1/add and /v1/patch
+	for _, op := range req.Ops {
+		if op.Add != nil {
+			op.Add.SaveRevision = true
 		}
-
-		// revision
-		rkey := append(kRevPrefix(req.Collection, doc.ID), []byte(fmt.Sprintf("%020d", now))...)
-		if err := bRev.Put(rkey, buf); err != nil {
-			return err
+		if op.Patch != nil {
+			op.Patch.SaveRevision = true
 		}
+	}
 
-		saved = doc
-		return nil
-	})
+	results, err := s.DB.Transaction(req.Ops)
 	if err != nil {
-		bad(w, err)
+		writeError(w, err, codes.Internal)
 		return
 	}
-	ok(w, saved)
+	ok(w, map[string]any{"results": results})
 }
 
 func (s *Server) handleGet(w http.ResponseWriter, r *http.Request) {
@@ -377,25 +401,8 @@ func (s *Server) handleGet(w http.ResponseWriter, r *http.Request) {
 		bad(w, err)
 		return
 	}
-	if req.Collection == "" || req.Key == "" || req.Lang == "" {
-		bad(w, errors.New("missing fields"))
-		return
-	}
 
-	var doc Doc
-	err := s.DB.View(func(tx *bolt.Tx) error {
-		bDocs := tx.Bucket([]byte("docs"))
-		bByK := tx.Bucket([]byte("bykey"))
-		docID := bByK.Get(kByKey(req.Collection, req.Key, req.Lang))
-		if docID == nil {
-			return errors.New("not found")
-		}
-		v := bDocs.Get(kDoc(req.Collection, string(docID)))
-		if v == nil {
-			return errors.New("not found")
-		}
-		return json.Unmarshal(v, &doc)
-	})
+	doc, err := s.DB.Get(req.Collection, req.Key, req.Lang)
 	if err != nil {
 		bad(w, err)
 		return
@@ -414,254 +421,69 @@ func (s *Server) handleSearch(w http.ResponseWriter, r *http.Request) {
 		bad(w, err)
 		return
 	}
-	if req.Limit <= 0 {
-		req.Limit = 50
-	}
-
-	type row struct{ Doc Doc }
-	var rows []row
-
-	err := s.DB.View(func(tx *bolt.Tx) error {
-		bDocs := tx.Bucket([]byte("docs"))
-		bIdx := tx.Bucket([]byte("idxmeta"))
-		seen := make(map[string]bool)
-
-		// Jeśli brak filtra meta → pełny scan kolekcji (dla prostoty; można dodać bucket per collection)
-		if len(req.FilterMeta) == 0 {
-			c := bDocs.Cursor()
-			prefix := []byte("doc|" + req.Collection + "|")
-			for k, v := c.Seek(prefix); k != nil && bytes.HasPrefix(k, prefix); k, v = c.Next() {
-				var d Doc
-				if err := json.Unmarshal(v, &d); err != nil {
-					return err
-				}
-				rows = append(rows, row{d})
-			}
-		} else {
-			// Intersect po meta kluczach
-			var sets [][]string
-			for mk, mvals := range req.FilterMeta {
-				var ids []string
-				for _, mv := range mvals {
-					prefix := kMetaKeyPrefix(req.Collection, mk, mv)
-					c := bIdx.Cursor()
-					for k, _ := c.Seek(prefix); k != nil && bytes.HasPrefix(k, prefix); k, _ = c.Next() {
-						id := string(k[len(prefix):])
-						ids = append(ids, id)
-					}
-				}
-				ids = unique(ids)
-				sets = append(sets, ids)
-			}
-			ids := intersect(sets...)
-			for _, id := range ids {
-				if seen[id] {
-					continue
-				}
-				seen[id] = true
-				v := tx.Bucket([]byte("docs")).Get(kDoc(req.Collection, id))
-				if v == nil {
-					continue
-				}
-				var d Doc
-				if err := json.Unmarshal(v, &d); err != nil {
-					return err
-				}
-				rows = append(rows, row{d})
-			}
-		}
-		return nil
-	})
+	out, total, err := s.DB.Search(storage.SearchQuery(req))
 	if err != nil {
 		bad(w, err)
 		return
 	}
-
-	// sort
-	switch req.Sort {
-	case "addedAt":
-		sort.Slice(rows, func(i, j int) bool {
-			if req.Asc {
-				return rows[i].Doc.AddedAt < rows[j].Doc.AddedAt
-			}
-			return rows[i].Doc.AddedAt > rows[j].Doc.AddedAt
-		})
-	case "updatedAt":
-		sort.Slice(rows, func(i, j int) bool {
-			if req.Asc {
-				return rows[i].Doc.UpdatedAt < rows[j].Doc.UpdatedAt
-			}
-			return rows[i].Doc.UpdatedAt > rows[j].Doc.UpdatedAt
-		})
-	case "key":
-		sort.Slice(rows, func(i, j int) bool {
-			if req.Asc {
-				return rows[i].Doc.Key < rows[j].Doc.Key
-			}
-			return rows[i].Doc.Key > rows[j].Doc.Key
-		})
-	}
-
-	// paginate
-	start := req.Offset
-	if start > len(rows) {
-		start = len(rows)
-	}
-	end := start + req.Limit
-	if end > len(rows) {
-		end = len(rows)
-	}
-
-	out := make([]Doc, 0, end-start)
-	for _, r := range rows[start:end] {
-		out = append(out, r.Doc)
-	}
+	// The body stays a bare array; the match count before pagination goes in a header
+	w.Header().Set("X-Total-Count", strconv.Itoa(total))
 	ok(w, out)
 }
 
-func (s *Server) handleExport(w http.ResponseWriter, r *http.Request) {
-	var req ExportRequest
+func (s *Server) handleTruncate(w http.ResponseWriter, r *http.Request) {
+	var req TruncateRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
 		bad(w, err)
 		return
 	}
-	if req.Format == "" {
-		req.Format = "ndjson"
-	}
-
-	// Reużyj /search
-	sr := SearchRequest{Collection: req.Collection, FilterMeta: req.FilterMeta, Limit: 1 << 30}
-	buf := new(bytes.Buffer)
-
-	switch req.Format {
-	case "ndjson":
-		// stream NDJSON
-		res, _ := http.Post("http://localhost"+env("MDDB_ADDR", ":11023")+"/v1/search", "application/json", bytes.NewReader(mustJSON(sr)))
-		defer func() { _ = res.Body.Close() }()
-		var docs []Doc
-		_ = json.NewDecoder(res.Body).Decode(&docs)
-		for _, d := range docs {
-			b, _ := json.Marshal(d)
-			buf.Write(b)
-			buf.WriteByte('\n')
-		}
-		w.Header().Set("Content-Type", "application/x-ndjson")
-		_, _ = w.Write(buf.Bytes())
-
-	case "zip":
-		// pack contentMd as files {key}.{lang}.md
-		res, _ := http.Post("http://localhost"+env("MDDB_ADDR", ":11023")+"/v1/search", "application/json", bytes.NewReader(mustJSON(sr)))
-		defer func() { _ = res.Body.Close() }()
-		var docs []Doc
-		_ = json.NewDecoder(res.Body).Decode(&docs)
-		var z bytes.Buffer
-		zw := zip.NewWriter(&z)
-		for _, d := range docs {
-			name := fmt.Sprintf("%s.%s.md", safe(d.Key), safe(d.Lang))
-			f, _ := zw.Create(name)
-			_, _ = io.WriteString(f, d.ContentMD)
-		}
-		_ = zw.Close()
-		w.Header().Set("Content-Type", "application/zip")
-		_, _ = w.Write(z.Bytes())
-
-	default:
-		http.Error(w, `{"error":"unsupported format"}`, 400)
+	if req.Collection == "" {
+		bad(w, errors.New("missing collection"))
+		return
 	}
-}
 
-func (s *Server) handleBackup(w http.ResponseWriter, r *http.Request) {
-	// snapshot = copy pliku DB (najprościej)
-	dst := r.URL.Query().Get("to")
-	if dst == "" {
-		dst = fmt.Sprintf("backup-%d.db", time.Now().Unix())
-	}
-	if err := copyFile(s.Path, dst); err != nil {
+	if err := s.DB.Truncate(req.Collection, req.KeepRevs); err != nil {
 		bad(w, err)
 		return
 	}
-	ok(w, map[string]string{"backup": dst})
+	if req.DropCache {
+		s.DB.DropCache(req.Collection)
+	}
+	ok(w, map[string]string{"status": "truncated"})
 }
 
-func (s *Server) handleRestore(w http.ResponseWriter, r *http.Request) {
-	var body struct {
-		From string `json:"from"`
-	}
-	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+func (s *Server) handleFsck(w http.ResponseWriter, r *http.Request) {
+	var req fsckRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
 		bad(w, err)
 		return
 	}
-	if body.From == "" {
-		bad(w, errors.New("missing from"))
+	if req.Repair && s.Mode == ModeRead {
+		writeError(w, newAPIError(http.StatusForbidden, codes.PermissionDenied, ReasonReadOnly, "read-only mode", nil), codes.PermissionDenied)
 		return
 	}
 
-	// zamknij db, podmień plik, otwórz ponownie
-	_ = s.DB.Close()
-	if err := copyFile(body.From, s.Path); err != nil {
-		bad(w, err)
-		return
-	}
-	
-	db, err := bolt.Open(s.Path, 0600, getOptimizedBoltOptions())
+	report, err := s.DB.Fsck(storage.FsckOptions(req))
 	if err != nil {
-		bad(w, err)
+		writeError(w, err, codes.Internal)
 		return
 	}
-	s.DB = db
-	ok(w, map[string]string{"restored": body.From})
+	ok(w, report)
 }
 
-func (s *Server) handleTruncate(w http.ResponseWriter, r *http.Request) {
-	var req TruncateRequest
-	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+func (s *Server) handleCompact(w http.ResponseWriter, r *http.Request) {
+	var req storage.CompactOptions
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil && err != io.EOF { // the body is optional
 		bad(w, err)
 		return
 	}
-	if req.Collection == "" {
-		bad(w, errors.New("missing collection"))
-		return
-	}
 
-	err := s.DB.Update(func(tx *bolt.Tx) error {
-		bRev := tx.Bucket([]byte("rev"))
-		bDocs := tx.Bucket([]byte("docs"))
-
-		// Dla każdego dokumentu w kolekcji: utnij historię do N
-		c := bDocs.Cursor()
-		prefix := []byte("doc|" + req.Collection + "|")
-		for k, v := c.Seek(prefix); k != nil && bytes.HasPrefix(k, prefix); k, v = c.Next() {
-			var d Doc
-			if err := json.Unmarshal(v, &d); err != nil {
-				return err
-			}
-			// Zbierz revety
-			rc := bRev.Cursor()
-			rp := kRevPrefix(req.Collection, d.ID)
-			var revKeys [][]byte
-			for rk, _ := rc.Seek(rp); rk != nil && bytes.HasPrefix(rk, rp); rk, _ = rc.Next() {
-				cp := make([]byte, len(rk))
-				copy(cp, rk)
-				revKeys = append(revKeys, cp)
-			}
-			// jeśli trzeba ciąć
-			if req.KeepRevs >= 0 && len(revKeys) > req.KeepRevs {
-				// posortowane rosnąco po ts dzięki key; usuń najstarsze
-				toDel := revKeys[:len(revKeys)-req.KeepRevs]
-				for _, delk := range toDel {
-					_ = bRev.Delete(delk)
-				}
-			}
-			// DropCache placeholder — jeśli trzymasz rendery, wyczyść je tutaj
-			_ = req.DropCache
-		}
-		return nil
-	})
+	res, err := s.DB.Compact(req)
 	if err != nil {
-		bad(w, err)
+		writeError(w, err, codes.Internal)
 		return
 	}
-	ok(w, map[string]string{"status": "truncated"})
+	ok(w, res)
 }
 
 // --- utils
@@ -672,134 +494,40 @@ func ok(w http.ResponseWriter, v any) {
 	_, _ = w.Write(b)
 }
 func bad(w http.ResponseWriter, err error) {
-	w.WriteHeader(400)
-	_, _ = fmt.Fprintf(w, `{"error":%q}`, err.Error())
+	writeError(w, err, codes.InvalidArgument)
+}
+
+// decodeStrict decodes a JSON body, rejecting unknown fields so a misspelt one fails with 400
+// instead of being dropped
+func decodeStrict(r io.Reader, v any) error {
+	dec := json.NewDecoder(r)
+	dec.DisallowUnknownFields()
+	return dec.Decode(v)
 }
 
 // handleHealth returns a simple health check response
 func (s *Server) handleHealth(w http.ResponseWriter, r *http.Request) {
-	// Check if database is accessible
-	err := s.DB.View(func(tx *bolt.Tx) error {
-		return nil
-	})
-	
-	if err != nil {
+	if err := s.DB.Ping(); err != nil {
 		w.WriteHeader(503)
 		_, _ = fmt.Fprintf(w, `{"status":"unhealthy","error":%q}`, err.Error())
 		return
 	}
-	
+
 	w.WriteHeader(200)
 	_, _ = w.Write([]byte(`{"status":"healthy","mode":"` + string(s.Mode) + `"}`))
 }
 
 func (s *Server) handleStats(w http.ResponseWriter, r *http.Request) {
-	type CollectionStats struct {
-		Name           string `json:"name"`
-		DocumentCount  int    `json:"documentCount"`
-		RevisionCount  int    `json:"revisionCount"`
-		MetaIndexCount int    `json:"metaIndexCount"`
-	}
-
-	type Stats struct {
-		DatabasePath    string            `json:"databasePath"`
-		DatabaseSize    int64             `json:"databaseSize"`
-		Mode            string            `json:"mode"`
-		Collections     []CollectionStats `json:"collections"`
-		TotalDocuments  int               `json:"totalDocuments"`
-		TotalRevisions  int               `json:"totalRevisions"`
-		TotalMetaIndices int              `json:"totalMetaIndices"`
-		Uptime          string            `json:"uptime"`
-	}
-
-	stats := Stats{
-		DatabasePath: s.Path,
-		Mode:         string(s.Mode),
-		Collections:  []CollectionStats{},
-	}
-
-	// Get database file size
-	if info, err := os.Stat(s.Path); err == nil {
-		stats.DatabaseSize = info.Size()
-	}
-
-	// Collect statistics per collection
-	collectionMap := make(map[string]*CollectionStats)
-
-	err := s.DB.View(func(tx *bolt.Tx) error {
-		// Count documents per collection
-		bDocs := tx.Bucket([]byte("docs"))
-		if bDocs != nil {
-			c := bDocs.Cursor()
-			for k, _ := c.First(); k != nil; k, _ = c.Next() {
-				// key format: doc|collection|id
-				parts := strings.Split(string(k), "|")
-				if len(parts) >= 2 {
-					coll := parts[1]
-					if _, ok := collectionMap[coll]; !ok {
-						collectionMap[coll] = &CollectionStats{Name: coll}
-					}
-					collectionMap[coll].DocumentCount++
-					stats.TotalDocuments++
-				}
-			}
-		}
-
-		// Count revisions per collection
-		bRev := tx.Bucket([]byte("rev"))
-		if bRev != nil {
-			c := bRev.Cursor()
-			for k, _ := c.First(); k != nil; k, _ = c.Next() {
-				// key format: rev|collection|docID|ts
-				parts := strings.Split(string(k), "|")
-				if len(parts) >= 2 {
-					coll := parts[1]
-					if _, ok := collectionMap[coll]; !ok {
-						collectionMap[coll] = &CollectionStats{Name: coll}
-					}
-					collectionMap[coll].RevisionCount++
-					stats.TotalRevisions++
-				}
-			}
-		}
-
-		// Count meta indices per collection
-		bIdx := tx.Bucket([]byte("idxmeta"))
-		if bIdx != nil {
-			c := bIdx.Cursor()
-			for k, _ := c.First(); k != nil; k, _ = c.Next() {
-				// key format: meta|collection|key|value|docID
-				parts := strings.Split(string(k), "|")
-				if len(parts) >= 2 {
-					coll := parts[1]
-					if _, ok := collectionMap[coll]; !ok {
-						collectionMap[coll] = &CollectionStats{Name: coll}
-					}
-					collectionMap[coll].MetaIndexCount++
-					stats.TotalMetaIndices++
-				}
-			}
-		}
-
-		return nil
-	})
-
+	stats, err := s.DB.Stats()
 	if err != nil {
 		bad(w, err)
 		return
 	}
-
-	// Convert map to slice
-	for _, cs := range collectionMap {
-		stats.Collections = append(stats.Collections, *cs)
-	}
-
-	// Sort collections by name
-	sort.Slice(stats.Collections, func(i, j int) bool {
-		return stats.Collections[i].Name < stats.Collections[j].Name
-	})
-
-	ok(w, stats)
+	ok(w, struct {
+		*storage.Stats
+		Mode   string `json:"mode"`
+		Uptime string `json:"uptime"`
+	}{Stats: stats, Mode: string(s.Mode)})
 }
 
 func env(k, def string) string {
@@ -808,32 +536,6 @@ func env(k, def string) string {
 	}
 	return def
 }
-func genID(parts ...string) string {
-	// Optimized ID generation without string allocations
-	totalLen := 0
-	for i, part := range parts {
-		totalLen += len(part)
-		if i < len(parts)-1 {
-			totalLen++ // for '|'
-		}
-	}
-	
-	buf := make([]byte, 0, totalLen)
-	for i, part := range parts {
-		for j := 0; j < len(part); j++ {
-			c := part[j]
-			if c >= 'A' && c <= 'Z' {
-				c += 'a' - 'A'
-			}
-			buf = append(buf, c)
-		}
-		if i < len(parts)-1 {
-			buf = append(buf, '|')
-		}
-	}
-	
-	return string(buf)
-}
 func applyEnv(s string, env map[string]string) string {
 	for k, v := range env {
 		s = strings.ReplaceAll(s, "%%"+k+"%%", v)
@@ -848,55 +550,6 @@ func safe(s string) string {
 		return '-'
 	}, s)
 }
-func unique(in []string) []string {
-	m := map[string]struct{}{}
-	out := make([]string, 0, len(in))
-	for _, x := range in {
-		if _, ok := m[x]; !ok {
-			m[x] = struct{}{}
-			out = append(out, x)
-		}
-	}
-	return out
-}
-func intersect(sets ...[]string) []string {
-	if len(sets) == 0 {
-		return nil
-	}
-	m := map[string]int{}
-	for _, s := range sets {
-		for _, id := range s {
-			m[id]++
-		}
-	}
-	out := []string{}
-	for id, c := range m {
-		if c == len(sets) {
-			out = append(out, id)
-		}
-	}
-	return out
-}
-func copyFile(src, dst string) error {
-	in, err := os.Open(src)
-	if err != nil {
-		return err
-	}
-	defer func() { _ = in.Close() }()
-	tmp := dst + ".tmp"
-	out, err := os.Create(tmp)
-	if err != nil {
-		return err
-	}
-	if _, err = io.Copy(out, in); err != nil {
-		_ = out.Close()
-		return err
-	}
-	if err = out.Close(); err != nil {
-		return err
-	}
-	return os.Rename(tmp, dst)
-}
 func mustJSON(v any) []byte {
 	b, err := json.Marshal(v)
 	if err != nil {
@@ -904,25 +557,6 @@ func mustJSON(v any) []byte {
 	}
 	return b
 }
-func sortDocs(docs []Doc, field string, asc bool) {
-	sort.Slice(docs, func(i, j int) bool {
-		var less bool
-		switch field {
-		case "addedAt":
-			less = docs[i].AddedAt < docs[j].AddedAt
-		case "updatedAt":
-			less = docs[i].UpdatedAt < docs[j].UpdatedAt
-		case "key":
-			less = docs[i].Key < docs[j].Key
-		default:
-			less = docs[i].UpdatedAt < docs[j].UpdatedAt
-		}
-		if asc {
-			return less
-		}
-		return !less
-	})
-}
 
 // handleDelete deletes a single document from a collection
 func (s *Server) handleDelete(w http.ResponseWriter, r *http.Request) {
@@ -931,64 +565,9 @@ func (s *Server) handleDelete(w http.ResponseWriter, r *http.Request) {
 		bad(w, err)
 		return
 	}
-	if req.Collection == "" || req.Key == "" || req.Lang == "" {
-		bad(w, errors.New("missing fields"))
-		return
-	}
-
-	docID := genID(req.Collection, req.Key, req.Lang)
-	
-	err := s.DB.Update(func(tx *bolt.Tx) error {
-		bDocs := tx.Bucket([]byte("docs"))
-		bIdx := tx.Bucket([]byte("idxmeta"))
-		bRev := tx.Bucket([]byte("rev"))
-		bByK := tx.Bucket([]byte("bykey"))
-
-		// Check if document exists and load it for cleanup
-		v := bDocs.Get(kDoc(req.Collection, docID))
-		if v == nil {
-			return errors.New("document not found")
-		}
-
-		// Load document to get metadata for index cleanup
-		var doc Doc
-		if err := json.Unmarshal(v, &doc); err != nil {
-			return err
-		}
-
-		// Delete document
-		if err := bDocs.Delete(kDoc(req.Collection, docID)); err != nil {
-			return err
-		}
-
-		// Delete from bykey index
-		if err := bByK.Delete(kByKey(req.Collection, req.Key, req.Lang)); err != nil {
-			return err
-		}
-
-		// Delete all revisions
-		c := bRev.Cursor()
-		rp := kRevPrefix(req.Collection, docID)
-		for k, _ := c.Seek(rp); k != nil && bytes.HasPrefix(k, rp); k, _ = c.Next() {
-			if err := bRev.Delete(k); err != nil {
-				return err
-			}
-		}
-
-		// Delete metadata indices
-		for mk, vals := range doc.Meta {
-			for _, mv := range vals {
-				key := append(kMetaKeyPrefix(req.Collection, mk, mv), []byte(docID)...)
-				if err := bIdx.Delete(key); err != nil {
-					return err
-				}
-			}
-		}
+	req.Lang = s.DB.Collections.DefaultLang(req.Collection, req.Lang)
 
-		return nil
-	})
-
-	if err != nil {
+	if err := s.DB.Delete(req.Collection, req.Key, req.Lang); err != nil {
 		bad(w, err)
 		return
 	}
@@ -1000,7 +579,7 @@ func (s *Server) handleDelete(w http.ResponseWriter, r *http.Request) {
 		"key":        req.Key,
 		"lang":       req.Lang,
 	}); err != nil {
-		log.Printf("Error encoding delete response: %v", err)
+		errorf("Error encoding delete response: %v", err)
 	}
 }
 
@@ -1016,59 +595,7 @@ func (s *Server) handleDeleteCollection(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
-	var deletedCount int
-	
-	err := s.DB.Update(func(tx *bolt.Tx) error {
-		bDocs := tx.Bucket([]byte("docs"))
-		bIdx := tx.Bucket([]byte("idxmeta"))
-		bRev := tx.Bucket([]byte("rev"))
-		bByK := tx.Bucket([]byte("bykey"))
-
-		// Delete all documents in collection
-		c := bDocs.Cursor()
-		prefix := []byte("doc|" + req.Collection + "|")
-		for k, v := c.Seek(prefix); k != nil && bytes.HasPrefix(k, prefix); k, v = c.Next() {
-			// Load document to get metadata for index cleanup
-			var doc Doc
-			if err := json.Unmarshal(v, &doc); err != nil {
-				continue
-			}
-
-			// Delete document
-			if err := bDocs.Delete(k); err != nil {
-				return err
-			}
-
-			// Delete from bykey index
-			if err := bByK.Delete(kByKey(req.Collection, doc.Key, doc.Lang)); err != nil {
-				return err
-			}
-
-			// Delete all revisions
-			rc := bRev.Cursor()
-			rp := kRevPrefix(req.Collection, doc.ID)
-			for rk, _ := rc.Seek(rp); rk != nil && bytes.HasPrefix(rk, rp); rk, _ = rc.Next() {
-				if err := bRev.Delete(rk); err != nil {
-					return err
-				}
-			}
-
-			// Delete metadata indices
-			for mk, vals := range doc.Meta {
-				for _, mv := range vals {
-					key := append(kMetaKeyPrefix(req.Collection, mk, mv), []byte(doc.ID)...)
-					if err := bIdx.Delete(key); err != nil {
-						return err
-					}
-				}
-			}
-
-			deletedCount++
-		}
-
-		return nil
-	})
-
+	deletedCount, err := s.DB.Collections.Delete(req.Collection)
 	if err != nil {
 		bad(w, err)
 		return
@@ -1080,6 +607,6 @@ func (s *Server) handleDeleteCollection(w http.ResponseWriter, r *http.Request)
 		"collection":   req.Collection,
 		"deletedCount": deletedCount,
 	}); err != nil {
-		log.Printf("Error encoding delete collection response: %v", err)
+		errorf("Error encoding delete collection response: %v", err)
 	}
 }
@@ -0,0 +1,54 @@
+package main
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/tradik/mddb/services/mddbd/storage"
+)
+
+// newTestServer opens a database in a temporary directory and serves the HTTP API,
+// with API key authentication if auth is set
+func newTestServer(t *testing.T, auth bool) (*Server, *httptest.Server) {
+	t.Helper()
+	db, err := storage.Open(filepath.Join(t.TempDir(), "mddb.db"), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = db.Close() })
+	s := &Server{
+		DB:        db,
+		Mode:      ModeRW,
+		Metrics:   NewMetrics(db),
+		backupNow: make(chan struct{}, 1),
+		stopping:  make(chan struct{}),
+	}
+	if auth {
+		s.Auth = &Auth{keys: db.APIKeys}
+	}
+	ts := httptest.NewServer(s.httpHandler())
+	t.Cleanup(ts.Close)
+	return s, ts
+}
+
+// post sends a JSON body, authenticated with key unless it is empty
+func post(t *testing.T, url, key, body string) *http.Response {
+	t.Helper()
+	req, err := http.NewRequest(http.MethodPost, url, strings.NewReader(body))
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if key != "" {
+		req.Header.Set("X-API-Key", key)
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = resp.Body.Close() })
+	return resp
+}
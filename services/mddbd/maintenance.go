@@ -0,0 +1,158 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"sync"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+)
+
+// errMaintenance rejects requests while a restore replaces the database
+var errMaintenance = errors.New("database maintenance in progress, retry shortly")
+
+// maintenanceExempt lists the HTTP paths and RPC names that pass the gate: the restores
+// that take it and the health and metrics endpoints that monitoring keeps polling
+var maintenanceExempt = map[string]bool{
+	"/health":            true,
+	"/v1/health":         true,
+	"/metrics":           true,
+	"/v1/restore":        true,
+	"/v1/restore/upload": true,
+	"Restore":            true,
+	"StreamRestore":      true,
+}
+
+// gate drains requests for maintenance that replaces the database file. Requests hold
+// the gate while they run; a restore closes it, waits for them to finish and rejects
+// new ones with 503 until the swap is done.
+type gate struct {
+	mu       sync.Mutex
+	inflight int
+	closed   bool
+	idle     chan struct{} // closed when the last request leaves a closing gate
+}
+
+// enter admits a request; it must call leave when done
+func (g *gate) enter() bool {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if g.closed {
+		return false
+	}
+	g.inflight++
+	return true
+}
+
+func (g *gate) leave() {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.inflight--
+	if g.closed && g.inflight == 0 && g.idle != nil {
+		close(g.idle)
+		g.idle = nil
+	}
+}
+
+// close stops admitting requests and waits up to timeout for the running ones. On
+// success the caller reopens the gate with the returned func; on timeout it is reopened.
+func (g *gate) close(timeout time.Duration) (reopen func(), err error) {
+	g.mu.Lock()
+	if g.closed {
+		g.mu.Unlock()
+		return nil, errMaintenance
+	}
+	g.closed = true
+	idle := make(chan struct{})
+	if g.inflight == 0 {
+		close(idle)
+	} else {
+		g.idle = idle
+	}
+	running := g.inflight
+	g.mu.Unlock()
+
+	reopen = func() {
+		g.mu.Lock()
+		g.closed, g.idle = false, nil
+		g.mu.Unlock()
+	}
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+	select {
+	case <-idle:
+		return reopen, nil
+	case <-timer.C:
+		reopen()
+		return nil, fmt.Errorf("%w: %d of %d requests still running after %s", errMaintenance, g.running(), running, timeout)
+	}
+}
+
+func (g *gate) running() int {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return g.inflight
+}
+
+// drain is the HTTP side of the gate; it also turns requests away during shutdown
+func (s *Server) drain(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if s.draining.Load() && !shutdownExempt[r.URL.Path] {
+			w.Header().Set("Connection", "close")
+			writeError(w, errShuttingDown, codes.Unavailable)
+			return
+		}
+		if maintenanceExempt[r.URL.Path] {
+			next.ServeHTTP(w, r)
+			return
+		}
+		if !s.gate.enter() {
+			w.Header().Set("Retry-After", "1")
+			writeError(w, errMaintenance, codes.Unavailable)
+			return
+		}
+		defer s.gate.leave()
+		next.ServeHTTP(w, r)
+	})
+}
+
+// unaryDrain is the gRPC side of the gate for unary RPCs
+func (s *Server) unaryDrain(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+	method, ok := mddbMethod(info.FullMethod)
+	if ok && s.draining.Load() && !shutdownExempt[method] {
+		return nil, grpcError(errShuttingDown, codes.Unavailable)
+	}
+	if !ok || maintenanceExempt[method] {
+		return handler(ctx, req)
+	}
+	if !s.gate.enter() {
+		return nil, grpcError(errMaintenance, codes.Unavailable)
+	}
+	defer s.gate.leave()
+	return handler(ctx, req)
+}
+
+// streamDrain is the gRPC side of the gate for streaming RPCs
+func (s *Server) streamDrain(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	method, ok := mddbMethod(info.FullMethod)
+	if ok && s.draining.Load() && !shutdownExempt[method] {
+		return grpcError(errShuttingDown, codes.Unavailable)
+	}
+	if !ok || maintenanceExempt[method] {
+		return handler(srv, ss)
+	}
+	if !s.gate.enter() {
+		return grpcError(errMaintenance, codes.Unavailable)
+	}
+	defer s.gate.leave()
+	return handler(srv, ss)
+}
+
+// quiesce drains the server for a restore; it is the storage.RestoreOptions hook
+func (s *Server) quiesce() (func(), error) {
+	return s.gate.close(s.DrainTimeout)
+}
@@ -0,0 +1,428 @@
+package main
+
+import (
+	"context"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/status"
+
+	"github.com/tradik/mddb/services/mddbd/storage"
+)
+
+// latencyBuckets covers sub-millisecond cache hits up to slow exports and backups
+var latencyBuckets = []float64{.0005, .001, .0025, .005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5, 10, 30}
+
+// Metrics records request metrics and exposes them with the storage subsystem stats in
+// the Prometheus text format
+type Metrics struct {
+	registry     *prometheus.Registry
+	httpDuration *prometheus.HistogramVec
+	httpErrors   *prometheus.CounterVec
+	grpcDuration *prometheus.HistogramVec
+	grpcErrors   *prometheus.CounterVec
+	backups      *prometheus.CounterVec
+	lastBackup   prometheus.Gauge
+	hookDrops    *prometheus.CounterVec
+}
+
+// NewMetrics creates the metrics registry for db
+func NewMetrics(db *storage.DB) *Metrics {
+	m := &Metrics{
+		registry: prometheus.NewRegistry(),
+		httpDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: "mddb",
+			Name:      "http_request_duration_seconds",
+			Help:      "HTTP request latency by route, method and status code.",
+			Buckets:   latencyBuckets,
+		}, []string{"route", "method", "code"}),
+		httpErrors: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: "mddb",
+			Name:      "http_request_errors_total",
+			Help:      "HTTP requests answered with a 4xx or 5xx status.",
+		}, []string{"route", "method", "code"}),
+		grpcDuration: prometheus.NewHistogramVec(prometheus.HistogramOpts{
+			Namespace: "mddb",
+			Name:      "grpc_request_duration_seconds",
+			Help:      "gRPC call latency by method and status code; streams are measured until they end.",
+			Buckets:   latencyBuckets,
+		}, []string{"method", "code"}),
+		grpcErrors: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: "mddb",
+			Name:      "grpc_request_errors_total",
+			Help:      "gRPC calls that ended with a status other than OK.",
+		}, []string{"method", "code"}),
+		backups: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: "mddb",
+			Name:      "scheduled_backups_total",
+			Help:      "Scheduled backups by result (ok or error).",
+		}, []string{"result"}),
+		lastBackup: prometheus.NewGauge(prometheus.GaugeOpts{
+			Namespace: "mddb",
+			Name:      "scheduled_backup_last_success_timestamp_seconds",
+			Help:      "Start time of the last successful scheduled backup.",
+		}),
+		hookDrops: prometheus.NewCounterVec(prometheus.CounterOpts{
+			Namespace: "mddb",
+			Name:      "hook_events_dropped_total",
+			Help:      "Hook events dropped because the hook queue was full, by event.",
+		}, []string{"event"}),
+	}
+	m.registry.MustRegister(
+		m.httpDuration, m.httpErrors, m.grpcDuration, m.grpcErrors, m.backups, m.lastBackup, m.hookDrops,
+		newStorageCollector(db),
+		collectors.NewGoCollector(),
+		collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}),
+	)
+	return m
+}
+
+// Handler serves the metrics
+func (m *Metrics) Handler() http.Handler {
+	return promhttp.HandlerFor(m.registry, promhttp.HandlerOpts{})
+}
+
+// backupDone records a scheduled backup started at start
+func (m *Metrics) backupDone(start time.Time, err error) {
+	if err != nil {
+		m.backups.WithLabelValues("error").Inc()
+		return
+	}
+	m.backups.WithLabelValues("ok").Inc()
+	m.lastBackup.Set(float64(start.Unix()))
+}
+
+// hookDropped records a hook event dropped while the hook queue was full
+func (m *Metrics) hookDropped(event string) {
+	m.hookDrops.WithLabelValues(event).Inc()
+}
+
+// statusRecorder captures the status code written by a handler
+type statusRecorder struct {
+	http.ResponseWriter
+	code int
+}
+
+func (r *statusRecorder) WriteHeader(code int) {
+	if r.code == 0 {
+		r.code = code
+	}
+	r.ResponseWriter.WriteHeader(code)
+}
+
+func (r *statusRecorder) Write(b []byte) (int, error) {
+	if r.code == 0 {
+		r.code = http.StatusOK
+	}
+	return r.ResponseWriter.Write(b)
+}
+
+// Flush keeps streaming handlers (import progress, exports) working
+func (r *statusRecorder) Flush() {
+	if f, ok := r.ResponseWriter.(http.Flusher); ok {
+		f.Flush()
+	}
+}
+
+func (r *statusRecorder) Unwrap() http.ResponseWriter { return r.ResponseWriter }
+
+// Middleware records latency and errors of every HTTP request. Routes are labeled with
+// their mux pattern so path parameters don't create new series.
+func (m *Metrics) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		rec := &statusRecorder{ResponseWriter: w}
+		next.ServeHTTP(rec, r)
+
+		route := "unmatched"
+		if r.Pattern != "" {
+			route = r.Pattern
+			if _, path, ok := strings.Cut(route, " "); ok {
+				route = path
+			}
+		}
+		if rec.code == 0 {
+			rec.code = http.StatusOK
+		}
+		code := strconv.Itoa(rec.code)
+		m.httpDuration.WithLabelValues(route, r.Method, code).Observe(time.Since(start).Seconds())
+		if rec.code >= 400 {
+			m.httpErrors.WithLabelValues(route, r.Method, code).Inc()
+		}
+	})
+}
+
+// observeRPC records one finished gRPC call
+func (m *Metrics) observeRPC(method string, start time.Time, err error) {
+	code := status.Code(err)
+	m.grpcDuration.WithLabelValues(method, code.String()).Observe(time.Since(start).Seconds())
+	if err != nil {
+		m.grpcErrors.WithLabelValues(method, code.String()).Inc()
+	}
+}
+
+// unaryInterceptor records unary RPCs
+func (m *Metrics) unaryInterceptor(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+	start := time.Now()
+	resp, err := handler(ctx, req)
+	m.observeRPC(info.FullMethod, start, err)
+	return resp, err
+}
+
+// streamInterceptor records streaming RPCs
+func (m *Metrics) streamInterceptor(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	start := time.Now()
+	err := handler(srv, ss)
+	m.observeRPC(info.FullMethod, start, err)
+	return err
+}
+
+// storageCollector reads the storage subsystem stats on every scrape
+type storageCollector struct {
+	db    *storage.DB
+	descs []*prometheus.Desc
+
+	fileSize         *prometheus.Desc
+	boltReadTx       *prometheus.Desc
+	boltOpenReadTx   *prometheus.Desc
+	boltFreePages    *prometheus.Desc
+	boltPendingPages *prometheus.Desc
+	boltFreeBytes    *prometheus.Desc
+	boltFreelistUsed *prometheus.Desc
+	boltPageAllocs   *prometheus.Desc
+	boltPageBytes    *prometheus.Desc
+	boltCursors      *prometheus.Desc
+	boltNodes        *prometheus.Desc
+	boltNodeDerefs   *prometheus.Desc
+	boltRebalances   *prometheus.Desc
+	boltRebalanceSec *prometheus.Desc
+	boltSplits       *prometheus.Desc
+	boltSpills       *prometheus.Desc
+	boltSpillSec     *prometheus.Desc
+	boltWrites       *prometheus.Desc
+	boltWriteSec     *prometheus.Desc
+
+	cacheHits      *prometheus.Desc
+	cacheMisses    *prometheus.Desc
+	cacheEvictions *prometheus.Desc
+	cacheRejected  *prometheus.Desc
+	cacheEntries   *prometheus.Desc
+	cacheBytes     *prometheus.Desc
+	cacheBudget    *prometheus.Desc
+
+	indexProcessed *prometheus.Desc
+	indexFailed    *prometheus.Desc
+	indexPending   *prometheus.Desc
+
+	walEntries   *prometheus.Desc
+	walSize      *prometheus.Desc
+	mvccKeys     *prometheus.Desc
+	mvccVersions *prometheus.Desc
+
+	bloomCapacity *prometheus.Desc
+	bloomItems    *prometheus.Desc
+
+	adaptivePatterns *prometheus.Desc
+	adaptiveStrategy *prometheus.Desc
+
+	shards       *prometheus.Desc
+	shardsActive *prometheus.Desc
+	shardDocs    *prometheus.Desc
+
+	simdEnabled       *prometheus.Desc
+	simdOps           *prometheus.Desc
+	zeroCopyTransfers *prometheus.Desc
+	zeroCopyBytes     *prometheus.Desc
+	asyncIOPending    *prometheus.Desc
+	asyncIOCompleted  *prometheus.Desc
+
+	compactions        *prometheus.Desc
+	compactionFailures *prometheus.Desc
+	compactionBytes    *prometheus.Desc
+
+	changeLogRecords  *prometheus.Desc
+	changeLogFailures *prometheus.Desc
+	changeLogSegments *prometheus.Desc
+	changeLogSize     *prometheus.Desc
+}
+
+func newStorageCollector(db *storage.DB) *storageCollector {
+	var descs []*prometheus.Desc
+	desc := func(name, help string, labels ...string) *prometheus.Desc {
+		d := prometheus.NewDesc("mddb_"+name, help, labels, nil)
+		descs = append(descs, d)
+		return d
+	}
+	c := &storageCollector{
+		db: db,
+
+		fileSize:         desc("db_file_size_bytes", "Size of the database file."),
+		boltReadTx:       desc("bolt_read_tx_total", "Read transactions started."),
+		boltOpenReadTx:   desc("bolt_open_read_tx", "Read transactions currently open."),
+		boltFreePages:    desc("bolt_freelist_free_pages", "Free pages on the freelist."),
+		boltPendingPages: desc("bolt_freelist_pending_pages", "Pages freed by transactions that are still visible to readers."),
+		boltFreeBytes:    desc("bolt_freelist_free_bytes", "Bytes allocated in free pages."),
+		boltFreelistUsed: desc("bolt_freelist_inuse_bytes", "Bytes used by the freelist."),
+		boltPageAllocs:   desc("bolt_tx_page_allocations_total", "Page allocations by write transactions."),
+		boltPageBytes:    desc("bolt_tx_page_alloc_bytes_total", "Bytes allocated for pages by write transactions."),
+		boltCursors:      desc("bolt_tx_cursors_total", "Cursors created."),
+		boltNodes:        desc("bolt_tx_node_allocations_total", "Node allocations."),
+		boltNodeDerefs:   desc("bolt_tx_node_derefs_total", "Node dereferences."),
+		boltRebalances:   desc("bolt_tx_rebalances_total", "Node rebalances."),
+		boltRebalanceSec: desc("bolt_tx_rebalance_seconds_total", "Time spent rebalancing nodes."),
+		boltSplits:       desc("bolt_tx_splits_total", "Nodes split."),
+		boltSpills:       desc("bolt_tx_spills_total", "Nodes spilled."),
+		boltSpillSec:     desc("bolt_tx_spill_seconds_total", "Time spent spilling nodes."),
+		boltWrites:       desc("bolt_tx_writes_total", "Page writes."),
+		boltWriteSec:     desc("bolt_tx_write_seconds_total", "Time spent writing pages to disk."),
+
+		cacheHits:      desc("cache_hits_total", "Document cache hits."),
+		cacheMisses:    desc("cache_misses_total", "Document cache misses."),
+		cacheEvictions: desc("cache_evictions_total", "Documents evicted to stay within the memory budget."),
+		cacheRejected:  desc("cache_rejections_total", "Documents not cached because the admission policy kept more popular ones."),
+		cacheEntries:   desc("cache_entries", "Documents in the cache."),
+		cacheBytes:     desc("cache_bytes", "Memory charged to cached documents."),
+		cacheBudget:    desc("cache_budget_bytes", "Memory budget of the document cache."),
+
+		indexProcessed: desc("index_queue_processed_total", "Async index jobs completed."),
+		indexFailed:    desc("index_queue_failed_total", "Async index jobs that failed."),
+		indexPending:   desc("index_queue_pending", "Async index jobs waiting in the queue."),
+
+		walEntries:   desc("wal_entries", "Entries in the write-ahead log since the last truncation."),
+		walSize:      desc("wal_size_bytes", "Size of the write-ahead log."),
+		mvccKeys:     desc("mvcc_keys", "Keys with version chains."),
+		mvccVersions: desc("mvcc_versions", "Versions held across all chains."),
+
+		bloomCapacity: desc("bloom_filter_capacity", "Bit capacity of the bloom filter.", "collection"),
+		bloomItems:    desc("bloom_filter_items", "Approximate number of keys in the bloom filter.", "collection"),
+
+		adaptivePatterns: desc("adaptive_index_query_patterns", "Distinct query patterns tracked."),
+		adaptiveStrategy: desc("adaptive_index_strategy_patterns", "Query patterns of the collection's current index strategy.", "collection", "primary", "secondary"),
+
+		shards:       desc("shards", "Shards in the cluster."),
+		shardsActive: desc("shards_active", "Active shards."),
+		shardDocs:    desc("shard_documents", "Documents assigned to the shard.", "shard"),
+
+		simdEnabled:       desc("simd_enabled", "Whether vectorized operations are available (1) or not (0)."),
+		simdOps:           desc("simd_operations_total", "Vectorized operations performed."),
+		zeroCopyTransfers: desc("zerocopy_transfers_total", "Zero-copy transfers."),
+		zeroCopyBytes:     desc("zerocopy_bytes_total", "Bytes moved by zero-copy transfers."),
+		asyncIOPending:    desc("async_io_pending", "Async I/O operations in flight."),
+		asyncIOCompleted:  desc("async_io_completed_total", "Async I/O operations completed."),
+
+		compactions:        desc("compactions_total", "Compactions of the database file."),
+		compactionFailures: desc("compaction_failures_total", "Compactions that failed; the old file stays in use."),
+		compactionBytes:    desc("compaction_reclaimed_bytes_total", "Bytes the database file shrank by through compaction."),
+
+		changeLogRecords:  desc("changelog_records_total", "Write transactions archived in the change log."),
+		changeLogFailures: desc("changelog_failures_total", "Committed write transactions the change log failed to archive."),
+		changeLogSegments: desc("changelog_segments", "Change log segment files."),
+		changeLogSize:     desc("changelog_size_bytes", "Size of all change log segments."),
+	}
+	c.descs = descs
+	return c
+}
+
+// Describe implements prometheus.Collector
+func (c *storageCollector) Describe(ch chan<- *prometheus.Desc) {
+	for _, d := range c.descs {
+		ch <- d
+	}
+}
+
+// Collect implements prometheus.Collector
+func (c *storageCollector) Collect(ch chan<- prometheus.Metric) {
+	s := c.db.SubsystemStats()
+	gauge := func(d *prometheus.Desc, v float64, labels ...string) {
+		ch <- prometheus.MustNewConstMetric(d, prometheus.GaugeValue, v, labels...)
+	}
+	counter := func(d *prometheus.Desc, v float64, labels ...string) {
+		ch <- prometheus.MustNewConstMetric(d, prometheus.CounterValue, v, labels...)
+	}
+
+	gauge(c.fileSize, float64(s.FileSize))
+	tx := &s.Bolt.TxStats
+	counter(c.boltReadTx, float64(s.Bolt.TxN))
+	gauge(c.boltOpenReadTx, float64(s.Bolt.OpenTxN))
+	gauge(c.boltFreePages, float64(s.Bolt.FreePageN))
+	gauge(c.boltPendingPages, float64(s.Bolt.PendingPageN))
+	gauge(c.boltFreeBytes, float64(s.Bolt.FreeAlloc))
+	gauge(c.boltFreelistUsed, float64(s.Bolt.FreelistInuse))
+	counter(c.boltPageAllocs, float64(tx.GetPageCount()))
+	counter(c.boltPageBytes, float64(tx.GetPageAlloc()))
+	counter(c.boltCursors, float64(tx.GetCursorCount()))
+	counter(c.boltNodes, float64(tx.GetNodeCount()))
+	counter(c.boltNodeDerefs, float64(tx.GetNodeDeref()))
+	counter(c.boltRebalances, float64(tx.GetRebalance()))
+	counter(c.boltRebalanceSec, tx.GetRebalanceTime().Seconds())
+	counter(c.boltSplits, float64(tx.GetSplit()))
+	counter(c.boltSpills, float64(tx.GetSpill()))
+	counter(c.boltSpillSec, tx.GetSpillTime().Seconds())
+	counter(c.boltWrites, float64(tx.GetWrite()))
+	counter(c.boltWriteSec, tx.GetWriteTime().Seconds())
+
+	counter(c.cacheHits, float64(s.Cache.Hits))
+	counter(c.cacheMisses, float64(s.Cache.Misses))
+	counter(c.cacheEvictions, float64(s.Cache.Evictions))
+	counter(c.cacheRejected, float64(s.Cache.Rejected))
+	gauge(c.cacheEntries, float64(s.Cache.Entries))
+	gauge(c.cacheBytes, float64(s.Cache.Bytes))
+	gauge(c.cacheBudget, float64(s.Cache.Budget))
+
+	counter(c.indexProcessed, float64(s.IndexQueue.Processed))
+	counter(c.indexFailed, float64(s.IndexQueue.Failed))
+	gauge(c.indexPending, float64(s.IndexQueue.Pending))
+
+	if s.WAL != nil {
+		gauge(c.walEntries, float64(s.WAL.Entries))
+		gauge(c.walSize, float64(s.WAL.Size))
+	}
+	if s.MVCC != nil {
+		gauge(c.mvccKeys, float64(s.MVCC.Keys))
+		gauge(c.mvccVersions, float64(s.MVCC.Versions))
+	}
+
+	for coll, bs := range s.Bloom {
+		gauge(c.bloomCapacity, float64(bs.Capacity), coll)
+		gauge(c.bloomItems, float64(bs.Count), coll)
+	}
+
+	gauge(c.adaptivePatterns, float64(s.AdaptiveIndex.TotalQueries))
+	for coll, is := range s.AdaptiveIndex.Collections {
+		gauge(c.adaptiveStrategy, float64(is.QueryCount), coll, is.PrimaryIndex, is.SecondaryIndex)
+	}
+
+	gauge(c.shards, float64(s.Shards.TotalShards))
+	gauge(c.shardsActive, float64(s.Shards.ActiveShards))
+	for _, sh := range s.Shards.Shards {
+		gauge(c.shardDocs, float64(sh.DocCount), strconv.Itoa(sh.ID))
+	}
+
+	simd := 0.0
+	if s.SIMD.Enabled {
+		simd = 1
+	}
+	gauge(c.simdEnabled, simd)
+	counter(c.simdOps, float64(s.SIMD.Operations))
+	counter(c.zeroCopyTransfers, float64(s.ZeroCopy.Transfers))
+	counter(c.zeroCopyBytes, float64(s.ZeroCopy.BytesCopy))
+	gauge(c.asyncIOPending, float64(s.AsyncIO.Pending))
+	counter(c.asyncIOCompleted, float64(s.AsyncIO.Completed))
+
+	counter(c.compactions, float64(s.Compaction.Runs))
+	counter(c.compactionFailures, float64(s.Compaction.Failed))
+	counter(c.compactionBytes, float64(s.Compaction.ReclaimedBytes))
+
+	if s.ChangeLog != nil {
+		counter(c.changeLogRecords, float64(s.ChangeLog.Records))
+		counter(c.changeLogFailures, float64(s.ChangeLog.Failed))
+		gauge(c.changeLogSegments, float64(s.ChangeLog.Segments))
+		gauge(c.changeLogSize, float64(s.ChangeLog.Size))
+	}
+}
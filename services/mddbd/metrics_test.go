@@ -0,0 +1,73 @@
+package main
+
+import (
+	"io"
+	"net/http"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/tradik/mddb/services/mddbd/storage"
+)
+
+// The storage collector describes every metric it reports, with consistent names and help
+func TestStorageCollector(t *testing.T) {
+	for _, extreme := range []bool{false, true} {
+		db, err := storage.Open(filepath.Join(t.TempDir(), "mddb.db"), &storage.Options{Extreme: extreme})
+		if err != nil {
+			t.Fatal(err)
+		}
+		t.Cleanup(func() { _ = db.Close() })
+		if _, err := db.Add(storage.AddRequest{Collection: "blog", Key: "post", Lang: "en", ContentMD: "# post"}); err != nil {
+			t.Fatal(err)
+		}
+
+		c := newStorageCollector(db)
+		problems, err := testutil.CollectAndLint(c)
+		if err != nil {
+			t.Fatalf("extreme=%v: %v", extreme, err)
+		}
+		for _, p := range problems {
+			t.Errorf("extreme=%v: %s: %s", extreme, p.Metric, p.Text)
+		}
+		if n := testutil.CollectAndCount(c, "mddb_db_file_size_bytes", "mddb_cache_entries", "mddb_index_queue_pending"); n != 3 {
+			t.Errorf("extreme=%v: %d of the always reported metrics collected, want 3", extreme, n)
+		}
+	}
+}
+
+// Scrapers need no credentials, also with auth enabled
+func TestMetricsEndpoint(t *testing.T) {
+	s, ts := newTestServer(t, true)
+	if _, err := s.DB.Add(storage.AddRequest{Collection: "blog", Key: "post", Lang: "en", ContentMD: "# post"}); err != nil {
+		t.Fatal(err)
+	}
+	if resp := post(t, ts.URL+"/v1/get", "", `{"collection":"blog","key":"post","lang":"en"}`); resp.StatusCode != http.StatusUnauthorized {
+		t.Fatalf("get without a key: %d, want 401", resp.StatusCode)
+	}
+
+	resp, err := http.Get(ts.URL + "/metrics")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("/metrics: %d %s", resp.StatusCode, body)
+	}
+	for _, want := range []string{
+		`mddb_http_request_errors_total{code="401",method="POST",route="/v1/get"} 1`,
+		"mddb_db_file_size_bytes ",
+		"mddb_cache_hits_total ",
+		"mddb_scheduled_backup_last_success_timestamp_seconds ",
+		"go_goroutines ",
+	} {
+		if !strings.Contains(string(body), want) {
+			t.Errorf("/metrics has no %q", want)
+		}
+	}
+}
@@ -244,29 +244,35 @@ func (x *AddRequest) GetSaveRevision() bool {
 	return false
 }
 
-// Batch add request
-type AddBatchRequest struct {
+// Patch request: meta operations run in order, content_md (if set) replaces the
+// content before append_md is appended
+type PatchRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
 	Collection    string                 `protobuf:"bytes,1,opt,name=collection,proto3" json:"collection,omitempty"`
-	Documents     []*BatchDocument       `protobuf:"bytes,2,rep,name=documents,proto3" json:"documents,omitempty"`
+	Key           string                 `protobuf:"bytes,2,opt,name=key,proto3" json:"key,omitempty"`
+	Lang          string                 `protobuf:"bytes,3,opt,name=lang,proto3" json:"lang,omitempty"`
+	Meta          []*MetaPatch           `protobuf:"bytes,4,rep,name=meta,proto3" json:"meta,omitempty"`
+	ContentMd     *string                `protobuf:"bytes,5,opt,name=content_md,json=contentMd,proto3,oneof" json:"content_md,omitempty"`
+	AppendMd      string                 `protobuf:"bytes,6,opt,name=append_md,json=appendMd,proto3" json:"append_md,omitempty"`
+	SaveRevision  bool                   `protobuf:"varint,7,opt,name=save_revision,json=saveRevision,proto3" json:"save_revision,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *AddBatchRequest) Reset() {
-	*x = AddBatchRequest{}
+func (x *PatchRequest) Reset() {
+	*x = PatchRequest{}
 	mi := &file_proto_mddb_proto_msgTypes[3]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *AddBatchRequest) String() string {
+func (x *PatchRequest) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*AddBatchRequest) ProtoMessage() {}
+func (*PatchRequest) ProtoMessage() {}
 
-func (x *AddBatchRequest) ProtoReflect() protoreflect.Message {
+func (x *PatchRequest) ProtoReflect() protoreflect.Message {
 	mi := &file_proto_mddb_proto_msgTypes[3]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -278,51 +284,84 @@ func (x *AddBatchRequest) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use AddBatchRequest.ProtoReflect.Descriptor instead.
-func (*AddBatchRequest) Descriptor() ([]byte, []int) {
+// Deprecated: Use PatchRequest.ProtoReflect.Descriptor instead.
+func (*PatchRequest) Descriptor() ([]byte, []int) {
 	return file_proto_mddb_proto_rawDescGZIP(), []int{3}
 }
 
-func (x *AddBatchRequest) GetCollection() string {
+func (x *PatchRequest) GetCollection() string {
 	if x != nil {
 		return x.Collection
 	}
 	return ""
 }
 
-func (x *AddBatchRequest) GetDocuments() []*BatchDocument {
+func (x *PatchRequest) GetKey() string {
 	if x != nil {
-		return x.Documents
+		return x.Key
+	}
+	return ""
+}
+
+func (x *PatchRequest) GetLang() string {
+	if x != nil {
+		return x.Lang
+	}
+	return ""
+}
+
+func (x *PatchRequest) GetMeta() []*MetaPatch {
+	if x != nil {
+		return x.Meta
 	}
 	return nil
 }
 
-// Document for batch operations
-type BatchDocument struct {
+func (x *PatchRequest) GetContentMd() string {
+	if x != nil && x.ContentMd != nil {
+		return *x.ContentMd
+	}
+	return ""
+}
+
+func (x *PatchRequest) GetAppendMd() string {
+	if x != nil {
+		return x.AppendMd
+	}
+	return ""
+}
+
+func (x *PatchRequest) GetSaveRevision() bool {
+	if x != nil {
+		return x.SaveRevision
+	}
+	return false
+}
+
+// One meta operation: set, unset, add (values) or remove (values)
+type MetaPatch struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	Key           string                 `protobuf:"bytes,1,opt,name=key,proto3" json:"key,omitempty"`
-	Lang          string                 `protobuf:"bytes,2,opt,name=lang,proto3" json:"lang,omitempty"`
-	Meta          map[string]*MetaValues `protobuf:"bytes,3,rep,name=meta,proto3" json:"meta,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
-	ContentMd     string                 `protobuf:"bytes,4,opt,name=content_md,json=contentMd,proto3" json:"content_md,omitempty"`
-	SaveRevision  bool                   `protobuf:"varint,5,opt,name=save_revision,json=saveRevision,proto3" json:"save_revision,omitempty"`
+	Op            string                 `protobuf:"bytes,1,opt,name=op,proto3" json:"op,omitempty"`
+	Key           string                 `protobuf:"bytes,2,opt,name=key,proto3" json:"key,omitempty"`
+	Values        []string               `protobuf:"bytes,3,rep,name=values,proto3" json:"values,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *BatchDocument) Reset() {
-	*x = BatchDocument{}
+func (x *MetaPatch) Reset() {
+	*x = MetaPatch{}
 	mi := &file_proto_mddb_proto_msgTypes[4]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *BatchDocument) String() string {
+func (x *MetaPatch) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*BatchDocument) ProtoMessage() {}
+func (*MetaPatch) ProtoMessage() {}
 
-func (x *BatchDocument) ProtoReflect() protoreflect.Message {
+func (x *MetaPatch) ProtoReflect() protoreflect.Message {
 	mi := &file_proto_mddb_proto_msgTypes[4]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -334,71 +373,54 @@ func (x *BatchDocument) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use BatchDocument.ProtoReflect.Descriptor instead.
-func (*BatchDocument) Descriptor() ([]byte, []int) {
+// Deprecated: Use MetaPatch.ProtoReflect.Descriptor instead.
+func (*MetaPatch) Descriptor() ([]byte, []int) {
 	return file_proto_mddb_proto_rawDescGZIP(), []int{4}
 }
 
-func (x *BatchDocument) GetKey() string {
+func (x *MetaPatch) GetOp() string {
 	if x != nil {
-		return x.Key
+		return x.Op
 	}
 	return ""
 }
 
-func (x *BatchDocument) GetLang() string {
+func (x *MetaPatch) GetKey() string {
 	if x != nil {
-		return x.Lang
+		return x.Key
 	}
 	return ""
 }
 
-func (x *BatchDocument) GetMeta() map[string]*MetaValues {
+func (x *MetaPatch) GetValues() []string {
 	if x != nil {
-		return x.Meta
+		return x.Values
 	}
 	return nil
 }
 
-func (x *BatchDocument) GetContentMd() string {
-	if x != nil {
-		return x.ContentMd
-	}
-	return ""
-}
-
-func (x *BatchDocument) GetSaveRevision() bool {
-	if x != nil {
-		return x.SaveRevision
-	}
-	return false
-}
-
-// Batch add response
-type AddBatchResponse struct {
+// Transaction request: operations run in order and are committed all or nothing
+type TransactionRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	Added         int32                  `protobuf:"varint,1,opt,name=added,proto3" json:"added,omitempty"`
-	Updated       int32                  `protobuf:"varint,2,opt,name=updated,proto3" json:"updated,omitempty"`
-	Failed        int32                  `protobuf:"varint,3,opt,name=failed,proto3" json:"failed,omitempty"`
-	Errors        []string               `protobuf:"bytes,4,rep,name=errors,proto3" json:"errors,omitempty"` // Error messages for failed documents
+	Ops           []*TxOp                `protobuf:"bytes,1,rep,name=ops,proto3" json:"ops,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *AddBatchResponse) Reset() {
-	*x = AddBatchResponse{}
+func (x *TransactionRequest) Reset() {
+	*x = TransactionRequest{}
 	mi := &file_proto_mddb_proto_msgTypes[5]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *AddBatchResponse) String() string {
+func (x *TransactionRequest) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*AddBatchResponse) ProtoMessage() {}
+func (*TransactionRequest) ProtoMessage() {}
 
-func (x *AddBatchResponse) ProtoReflect() protoreflect.Message {
+func (x *TransactionRequest) ProtoReflect() protoreflect.Message {
 	mi := &file_proto_mddb_proto_msgTypes[5]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -410,64 +432,46 @@ func (x *AddBatchResponse) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use AddBatchResponse.ProtoReflect.Descriptor instead.
-func (*AddBatchResponse) Descriptor() ([]byte, []int) {
+// Deprecated: Use TransactionRequest.ProtoReflect.Descriptor instead.
+func (*TransactionRequest) Descriptor() ([]byte, []int) {
 	return file_proto_mddb_proto_rawDescGZIP(), []int{5}
 }
 
-func (x *AddBatchResponse) GetAdded() int32 {
-	if x != nil {
-		return x.Added
-	}
-	return 0
-}
-
-func (x *AddBatchResponse) GetUpdated() int32 {
-	if x != nil {
-		return x.Updated
-	}
-	return 0
-}
-
-func (x *AddBatchResponse) GetFailed() int32 {
-	if x != nil {
-		return x.Failed
-	}
-	return 0
-}
-
-func (x *AddBatchResponse) GetErrors() []string {
+func (x *TransactionRequest) GetOps() []*TxOp {
 	if x != nil {
-		return x.Errors
+		return x.Ops
 	}
 	return nil
 }
 
-// Get document request
-type GetRequest struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	Collection    string                 `protobuf:"bytes,1,opt,name=collection,proto3" json:"collection,omitempty"`
-	Key           string                 `protobuf:"bytes,2,opt,name=key,proto3" json:"key,omitempty"`
-	Lang          string                 `protobuf:"bytes,3,opt,name=lang,proto3" json:"lang,omitempty"`
-	Env           map[string]string      `protobuf:"bytes,4,rep,name=env,proto3" json:"env,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"` // Template variables
+// One operation of a transaction
+type TxOp struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	// Types that are valid to be assigned to Op:
+	//
+	//	*TxOp_Add
+	//	*TxOp_Patch
+	//	*TxOp_Delete
+	Op            isTxOp_Op     `protobuf_oneof:"op"`
+	Precondition  *Precondition `protobuf:"bytes,4,opt,name=precondition,proto3" json:"precondition,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *GetRequest) Reset() {
-	*x = GetRequest{}
+func (x *TxOp) Reset() {
+	*x = TxOp{}
 	mi := &file_proto_mddb_proto_msgTypes[6]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *GetRequest) String() string {
+func (x *TxOp) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*GetRequest) ProtoMessage() {}
+func (*TxOp) ProtoMessage() {}
 
-func (x *GetRequest) ProtoReflect() protoreflect.Message {
+func (x *TxOp) ProtoReflect() protoreflect.Message {
 	mi := &file_proto_mddb_proto_msgTypes[6]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -479,66 +483,97 @@ func (x *GetRequest) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use GetRequest.ProtoReflect.Descriptor instead.
-func (*GetRequest) Descriptor() ([]byte, []int) {
+// Deprecated: Use TxOp.ProtoReflect.Descriptor instead.
+func (*TxOp) Descriptor() ([]byte, []int) {
 	return file_proto_mddb_proto_rawDescGZIP(), []int{6}
 }
 
-func (x *GetRequest) GetCollection() string {
+func (x *TxOp) GetOp() isTxOp_Op {
 	if x != nil {
-		return x.Collection
+		return x.Op
 	}
-	return ""
+	return nil
 }
 
-func (x *GetRequest) GetKey() string {
+func (x *TxOp) GetAdd() *AddRequest {
 	if x != nil {
-		return x.Key
+		if x, ok := x.Op.(*TxOp_Add); ok {
+			return x.Add
+		}
 	}
-	return ""
+	return nil
 }
 
-func (x *GetRequest) GetLang() string {
+func (x *TxOp) GetPatch() *PatchRequest {
 	if x != nil {
-		return x.Lang
+		if x, ok := x.Op.(*TxOp_Patch); ok {
+			return x.Patch
+		}
 	}
-	return ""
+	return nil
 }
 
-func (x *GetRequest) GetEnv() map[string]string {
+func (x *TxOp) GetDelete() *DeleteRequest {
 	if x != nil {
-		return x.Env
+		if x, ok := x.Op.(*TxOp_Delete); ok {
+			return x.Delete
+		}
 	}
 	return nil
 }
 
-// Search request
-type SearchRequest struct {
+func (x *TxOp) GetPrecondition() *Precondition {
+	if x != nil {
+		return x.Precondition
+	}
+	return nil
+}
+
+type isTxOp_Op interface {
+	isTxOp_Op()
+}
+
+type TxOp_Add struct {
+	Add *AddRequest `protobuf:"bytes,1,opt,name=add,proto3,oneof"`
+}
+
+type TxOp_Patch struct {
+	Patch *PatchRequest `protobuf:"bytes,2,opt,name=patch,proto3,oneof"`
+}
+
+type TxOp_Delete struct {
+	Delete *DeleteRequest `protobuf:"bytes,3,opt,name=delete,proto3,oneof"`
+}
+
+func (*TxOp_Add) isTxOp_Op() {}
+
+func (*TxOp_Patch) isTxOp_Op() {}
+
+func (*TxOp_Delete) isTxOp_Op() {}
+
+// Condition on the document, checked when its operation runs
+type Precondition struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	Collection    string                 `protobuf:"bytes,1,opt,name=collection,proto3" json:"collection,omitempty"`
-	FilterMeta    map[string]*MetaValues `protobuf:"bytes,2,rep,name=filter_meta,json=filterMeta,proto3" json:"filter_meta,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
-	Sort          string                 `protobuf:"bytes,3,opt,name=sort,proto3" json:"sort,omitempty"` // addedAt, updatedAt, key
-	Asc           bool                   `protobuf:"varint,4,opt,name=asc,proto3" json:"asc,omitempty"`
-	Limit         int32                  `protobuf:"varint,5,opt,name=limit,proto3" json:"limit,omitempty"`
-	Offset        int32                  `protobuf:"varint,6,opt,name=offset,proto3" json:"offset,omitempty"`
+	Exists        *bool                  `protobuf:"varint,1,opt,name=exists,proto3,oneof" json:"exists,omitempty"`           // the document must (true) or must not (false) exist
+	IfMatch       string                 `protobuf:"bytes,2,opt,name=if_match,json=ifMatch,proto3" json:"if_match,omitempty"` // the document must exist with this ETag
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *SearchRequest) Reset() {
-	*x = SearchRequest{}
+func (x *Precondition) Reset() {
+	*x = Precondition{}
 	mi := &file_proto_mddb_proto_msgTypes[7]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *SearchRequest) String() string {
+func (x *Precondition) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*SearchRequest) ProtoMessage() {}
+func (*Precondition) ProtoMessage() {}
 
-func (x *SearchRequest) ProtoReflect() protoreflect.Message {
+func (x *Precondition) ProtoReflect() protoreflect.Message {
 	mi := &file_proto_mddb_proto_msgTypes[7]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -550,76 +585,47 @@ func (x *SearchRequest) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use SearchRequest.ProtoReflect.Descriptor instead.
-func (*SearchRequest) Descriptor() ([]byte, []int) {
+// Deprecated: Use Precondition.ProtoReflect.Descriptor instead.
+func (*Precondition) Descriptor() ([]byte, []int) {
 	return file_proto_mddb_proto_rawDescGZIP(), []int{7}
 }
 
-func (x *SearchRequest) GetCollection() string {
-	if x != nil {
-		return x.Collection
-	}
-	return ""
-}
-
-func (x *SearchRequest) GetFilterMeta() map[string]*MetaValues {
-	if x != nil {
-		return x.FilterMeta
-	}
-	return nil
-}
-
-func (x *SearchRequest) GetSort() string {
-	if x != nil {
-		return x.Sort
-	}
-	return ""
-}
-
-func (x *SearchRequest) GetAsc() bool {
-	if x != nil {
-		return x.Asc
+func (x *Precondition) GetExists() bool {
+	if x != nil && x.Exists != nil {
+		return *x.Exists
 	}
 	return false
 }
 
-func (x *SearchRequest) GetLimit() int32 {
-	if x != nil {
-		return x.Limit
-	}
-	return 0
-}
-
-func (x *SearchRequest) GetOffset() int32 {
+func (x *Precondition) GetIfMatch() string {
 	if x != nil {
-		return x.Offset
+		return x.IfMatch
 	}
-	return 0
+	return ""
 }
 
-// Search response
-type SearchResponse struct {
+// Transaction response: one result per operation
+type TransactionResponse struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	Documents     []*Document            `protobuf:"bytes,1,rep,name=documents,proto3" json:"documents,omitempty"`
-	Total         int32                  `protobuf:"varint,2,opt,name=total,proto3" json:"total,omitempty"`
+	Results       []*TxResult            `protobuf:"bytes,1,rep,name=results,proto3" json:"results,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *SearchResponse) Reset() {
-	*x = SearchResponse{}
+func (x *TransactionResponse) Reset() {
+	*x = TransactionResponse{}
 	mi := &file_proto_mddb_proto_msgTypes[8]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *SearchResponse) String() string {
+func (x *TransactionResponse) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*SearchResponse) ProtoMessage() {}
+func (*TransactionResponse) ProtoMessage() {}
 
-func (x *SearchResponse) ProtoReflect() protoreflect.Message {
+func (x *TransactionResponse) ProtoReflect() protoreflect.Message {
 	mi := &file_proto_mddb_proto_msgTypes[8]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -631,49 +637,45 @@ func (x *SearchResponse) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use SearchResponse.ProtoReflect.Descriptor instead.
-func (*SearchResponse) Descriptor() ([]byte, []int) {
+// Deprecated: Use TransactionResponse.ProtoReflect.Descriptor instead.
+func (*TransactionResponse) Descriptor() ([]byte, []int) {
 	return file_proto_mddb_proto_rawDescGZIP(), []int{8}
 }
 
-func (x *SearchResponse) GetDocuments() []*Document {
+func (x *TransactionResponse) GetResults() []*TxResult {
 	if x != nil {
-		return x.Documents
+		return x.Results
 	}
 	return nil
 }
 
-func (x *SearchResponse) GetTotal() int32 {
-	if x != nil {
-		return x.Total
-	}
-	return 0
-}
-
-// Export request
-type ExportRequest struct {
+// Outcome of one operation
+type TxResult struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	Collection    string                 `protobuf:"bytes,1,opt,name=collection,proto3" json:"collection,omitempty"`
-	FilterMeta    map[string]*MetaValues `protobuf:"bytes,2,rep,name=filter_meta,json=filterMeta,proto3" json:"filter_meta,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
-	Format        string                 `protobuf:"bytes,3,opt,name=format,proto3" json:"format,omitempty"` // ndjson, zip
+	Op            string                 `protobuf:"bytes,1,opt,name=op,proto3" json:"op,omitempty"`
+	Collection    string                 `protobuf:"bytes,2,opt,name=collection,proto3" json:"collection,omitempty"`
+	Key           string                 `protobuf:"bytes,3,opt,name=key,proto3" json:"key,omitempty"`
+	Lang          string                 `protobuf:"bytes,4,opt,name=lang,proto3" json:"lang,omitempty"`
+	Document      *Document              `protobuf:"bytes,5,opt,name=document,proto3" json:"document,omitempty"` // add and patch
+	Created       bool                   `protobuf:"varint,6,opt,name=created,proto3" json:"created,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *ExportRequest) Reset() {
-	*x = ExportRequest{}
+func (x *TxResult) Reset() {
+	*x = TxResult{}
 	mi := &file_proto_mddb_proto_msgTypes[9]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *ExportRequest) String() string {
+func (x *TxResult) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*ExportRequest) ProtoMessage() {}
+func (*TxResult) ProtoMessage() {}
 
-func (x *ExportRequest) ProtoReflect() protoreflect.Message {
+func (x *TxResult) ProtoReflect() protoreflect.Message {
 	mi := &file_proto_mddb_proto_msgTypes[9]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -685,55 +687,76 @@ func (x *ExportRequest) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use ExportRequest.ProtoReflect.Descriptor instead.
-func (*ExportRequest) Descriptor() ([]byte, []int) {
+// Deprecated: Use TxResult.ProtoReflect.Descriptor instead.
+func (*TxResult) Descriptor() ([]byte, []int) {
 	return file_proto_mddb_proto_rawDescGZIP(), []int{9}
 }
 
-func (x *ExportRequest) GetCollection() string {
+func (x *TxResult) GetOp() string {
 	if x != nil {
-		return x.Collection
+		return x.Op
 	}
 	return ""
 }
 
-func (x *ExportRequest) GetFilterMeta() map[string]*MetaValues {
+func (x *TxResult) GetCollection() string {
 	if x != nil {
-		return x.FilterMeta
+		return x.Collection
 	}
-	return nil
+	return ""
 }
 
-func (x *ExportRequest) GetFormat() string {
+func (x *TxResult) GetKey() string {
 	if x != nil {
-		return x.Format
+		return x.Key
 	}
 	return ""
 }
 
-// Export chunk (streaming)
-type ExportChunk struct {
+func (x *TxResult) GetLang() string {
+	if x != nil {
+		return x.Lang
+	}
+	return ""
+}
+
+func (x *TxResult) GetDocument() *Document {
+	if x != nil {
+		return x.Document
+	}
+	return nil
+}
+
+func (x *TxResult) GetCreated() bool {
+	if x != nil {
+		return x.Created
+	}
+	return false
+}
+
+// Batch add request
+type AddBatchRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	Data          []byte                 `protobuf:"bytes,1,opt,name=data,proto3" json:"data,omitempty"`
-	IsLast        bool                   `protobuf:"varint,2,opt,name=is_last,json=isLast,proto3" json:"is_last,omitempty"`
+	Collection    string                 `protobuf:"bytes,1,opt,name=collection,proto3" json:"collection,omitempty"`
+	Documents     []*BatchDocument       `protobuf:"bytes,2,rep,name=documents,proto3" json:"documents,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *ExportChunk) Reset() {
-	*x = ExportChunk{}
+func (x *AddBatchRequest) Reset() {
+	*x = AddBatchRequest{}
 	mi := &file_proto_mddb_proto_msgTypes[10]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *ExportChunk) String() string {
+func (x *AddBatchRequest) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*ExportChunk) ProtoMessage() {}
+func (*AddBatchRequest) ProtoMessage() {}
 
-func (x *ExportChunk) ProtoReflect() protoreflect.Message {
+func (x *AddBatchRequest) ProtoReflect() protoreflect.Message {
 	mi := &file_proto_mddb_proto_msgTypes[10]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -745,47 +768,51 @@ func (x *ExportChunk) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use ExportChunk.ProtoReflect.Descriptor instead.
-func (*ExportChunk) Descriptor() ([]byte, []int) {
+// Deprecated: Use AddBatchRequest.ProtoReflect.Descriptor instead.
+func (*AddBatchRequest) Descriptor() ([]byte, []int) {
 	return file_proto_mddb_proto_rawDescGZIP(), []int{10}
 }
 
-func (x *ExportChunk) GetData() []byte {
+func (x *AddBatchRequest) GetCollection() string {
 	if x != nil {
-		return x.Data
+		return x.Collection
 	}
-	return nil
+	return ""
 }
 
-func (x *ExportChunk) GetIsLast() bool {
+func (x *AddBatchRequest) GetDocuments() []*BatchDocument {
 	if x != nil {
-		return x.IsLast
+		return x.Documents
 	}
-	return false
+	return nil
 }
 
-// Backup request
-type BackupRequest struct {
+// Document for batch operations
+type BatchDocument struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	To            string                 `protobuf:"bytes,1,opt,name=to,proto3" json:"to,omitempty"` // Backup filename
+	Key           string                 `protobuf:"bytes,1,opt,name=key,proto3" json:"key,omitempty"`
+	Lang          string                 `protobuf:"bytes,2,opt,name=lang,proto3" json:"lang,omitempty"`
+	Meta          map[string]*MetaValues `protobuf:"bytes,3,rep,name=meta,proto3" json:"meta,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	ContentMd     string                 `protobuf:"bytes,4,opt,name=content_md,json=contentMd,proto3" json:"content_md,omitempty"`
+	SaveRevision  bool                   `protobuf:"varint,5,opt,name=save_revision,json=saveRevision,proto3" json:"save_revision,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *BackupRequest) Reset() {
-	*x = BackupRequest{}
+func (x *BatchDocument) Reset() {
+	*x = BatchDocument{}
 	mi := &file_proto_mddb_proto_msgTypes[11]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *BackupRequest) String() string {
+func (x *BatchDocument) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*BackupRequest) ProtoMessage() {}
+func (*BatchDocument) ProtoMessage() {}
 
-func (x *BackupRequest) ProtoReflect() protoreflect.Message {
+func (x *BatchDocument) ProtoReflect() protoreflect.Message {
 	mi := &file_proto_mddb_proto_msgTypes[11]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -797,40 +824,71 @@ func (x *BackupRequest) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use BackupRequest.ProtoReflect.Descriptor instead.
-func (*BackupRequest) Descriptor() ([]byte, []int) {
+// Deprecated: Use BatchDocument.ProtoReflect.Descriptor instead.
+func (*BatchDocument) Descriptor() ([]byte, []int) {
 	return file_proto_mddb_proto_rawDescGZIP(), []int{11}
 }
 
-func (x *BackupRequest) GetTo() string {
+func (x *BatchDocument) GetKey() string {
 	if x != nil {
-		return x.To
+		return x.Key
 	}
 	return ""
 }
 
-// Backup response
-type BackupResponse struct {
+func (x *BatchDocument) GetLang() string {
+	if x != nil {
+		return x.Lang
+	}
+	return ""
+}
+
+func (x *BatchDocument) GetMeta() map[string]*MetaValues {
+	if x != nil {
+		return x.Meta
+	}
+	return nil
+}
+
+func (x *BatchDocument) GetContentMd() string {
+	if x != nil {
+		return x.ContentMd
+	}
+	return ""
+}
+
+func (x *BatchDocument) GetSaveRevision() bool {
+	if x != nil {
+		return x.SaveRevision
+	}
+	return false
+}
+
+// Batch add response
+type AddBatchResponse struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	Backup        string                 `protobuf:"bytes,1,opt,name=backup,proto3" json:"backup,omitempty"`
+	Added         int32                  `protobuf:"varint,1,opt,name=added,proto3" json:"added,omitempty"`
+	Updated       int32                  `protobuf:"varint,2,opt,name=updated,proto3" json:"updated,omitempty"`
+	Failed        int32                  `protobuf:"varint,3,opt,name=failed,proto3" json:"failed,omitempty"`
+	Errors        []string               `protobuf:"bytes,4,rep,name=errors,proto3" json:"errors,omitempty"` // Error messages for failed documents
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *BackupResponse) Reset() {
-	*x = BackupResponse{}
+func (x *AddBatchResponse) Reset() {
+	*x = AddBatchResponse{}
 	mi := &file_proto_mddb_proto_msgTypes[12]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *BackupResponse) String() string {
+func (x *AddBatchResponse) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*BackupResponse) ProtoMessage() {}
+func (*AddBatchResponse) ProtoMessage() {}
 
-func (x *BackupResponse) ProtoReflect() protoreflect.Message {
+func (x *AddBatchResponse) ProtoReflect() protoreflect.Message {
 	mi := &file_proto_mddb_proto_msgTypes[12]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
@@ -842,41 +900,3290 @@ func (x *BackupResponse) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use BackupResponse.ProtoReflect.Descriptor instead.
-func (*BackupResponse) Descriptor() ([]byte, []int) {
+// Deprecated: Use AddBatchResponse.ProtoReflect.Descriptor instead.
+func (*AddBatchResponse) Descriptor() ([]byte, []int) {
 	return file_proto_mddb_proto_rawDescGZIP(), []int{12}
 }
 
-func (x *BackupResponse) GetBackup() string {
+func (x *AddBatchResponse) GetAdded() int32 {
 	if x != nil {
-		return x.Backup
+		return x.Added
+	}
+	return 0
+}
+
+func (x *AddBatchResponse) GetUpdated() int32 {
+	if x != nil {
+		return x.Updated
+	}
+	return 0
+}
+
+func (x *AddBatchResponse) GetFailed() int32 {
+	if x != nil {
+		return x.Failed
+	}
+	return 0
+}
+
+func (x *AddBatchResponse) GetErrors() []string {
+	if x != nil {
+		return x.Errors
+	}
+	return nil
+}
+
+// Get document request
+type GetRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Collection    string                 `protobuf:"bytes,1,opt,name=collection,proto3" json:"collection,omitempty"`
+	Key           string                 `protobuf:"bytes,2,opt,name=key,proto3" json:"key,omitempty"`
+	Lang          string                 `protobuf:"bytes,3,opt,name=lang,proto3" json:"lang,omitempty"`
+	Env           map[string]string      `protobuf:"bytes,4,rep,name=env,proto3" json:"env,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"` // Template variables
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *GetRequest) Reset() {
+	*x = GetRequest{}
+	mi := &file_proto_mddb_proto_msgTypes[13]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *GetRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*GetRequest) ProtoMessage() {}
+
+func (x *GetRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_mddb_proto_msgTypes[13]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use GetRequest.ProtoReflect.Descriptor instead.
+func (*GetRequest) Descriptor() ([]byte, []int) {
+	return file_proto_mddb_proto_rawDescGZIP(), []int{13}
+}
+
+func (x *GetRequest) GetCollection() string {
+	if x != nil {
+		return x.Collection
+	}
+	return ""
+}
+
+func (x *GetRequest) GetKey() string {
+	if x != nil {
+		return x.Key
+	}
+	return ""
+}
+
+func (x *GetRequest) GetLang() string {
+	if x != nil {
+		return x.Lang
+	}
+	return ""
+}
+
+func (x *GetRequest) GetEnv() map[string]string {
+	if x != nil {
+		return x.Env
+	}
+	return nil
+}
+
+// Search request
+type SearchRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Collection    string                 `protobuf:"bytes,1,opt,name=collection,proto3" json:"collection,omitempty"`
+	FilterMeta    map[string]*MetaValues `protobuf:"bytes,2,rep,name=filter_meta,json=filterMeta,proto3" json:"filter_meta,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	Sort          string                 `protobuf:"bytes,3,opt,name=sort,proto3" json:"sort,omitempty"` // addedAt, updatedAt, key
+	Asc           bool                   `protobuf:"varint,4,opt,name=asc,proto3" json:"asc,omitempty"`
+	Limit         int32                  `protobuf:"varint,5,opt,name=limit,proto3" json:"limit,omitempty"`
+	Offset        int32                  `protobuf:"varint,6,opt,name=offset,proto3" json:"offset,omitempty"`
+	Consistency   string                 `protobuf:"bytes,7,opt,name=consistency,proto3" json:"consistency,omitempty"` // eventual (default) or strong: wait until earlier writes are indexed
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SearchRequest) Reset() {
+	*x = SearchRequest{}
+	mi := &file_proto_mddb_proto_msgTypes[14]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SearchRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SearchRequest) ProtoMessage() {}
+
+func (x *SearchRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_mddb_proto_msgTypes[14]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SearchRequest.ProtoReflect.Descriptor instead.
+func (*SearchRequest) Descriptor() ([]byte, []int) {
+	return file_proto_mddb_proto_rawDescGZIP(), []int{14}
+}
+
+func (x *SearchRequest) GetCollection() string {
+	if x != nil {
+		return x.Collection
+	}
+	return ""
+}
+
+func (x *SearchRequest) GetFilterMeta() map[string]*MetaValues {
+	if x != nil {
+		return x.FilterMeta
+	}
+	return nil
+}
+
+func (x *SearchRequest) GetSort() string {
+	if x != nil {
+		return x.Sort
+	}
+	return ""
+}
+
+func (x *SearchRequest) GetAsc() bool {
+	if x != nil {
+		return x.Asc
+	}
+	return false
+}
+
+func (x *SearchRequest) GetLimit() int32 {
+	if x != nil {
+		return x.Limit
+	}
+	return 0
+}
+
+func (x *SearchRequest) GetOffset() int32 {
+	if x != nil {
+		return x.Offset
+	}
+	return 0
+}
+
+func (x *SearchRequest) GetConsistency() string {
+	if x != nil {
+		return x.Consistency
+	}
+	return ""
+}
+
+// Search response
+type SearchResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Documents     []*Document            `protobuf:"bytes,1,rep,name=documents,proto3" json:"documents,omitempty"`
+	Total         int32                  `protobuf:"varint,2,opt,name=total,proto3" json:"total,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SearchResponse) Reset() {
+	*x = SearchResponse{}
+	mi := &file_proto_mddb_proto_msgTypes[15]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SearchResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SearchResponse) ProtoMessage() {}
+
+func (x *SearchResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_mddb_proto_msgTypes[15]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SearchResponse.ProtoReflect.Descriptor instead.
+func (*SearchResponse) Descriptor() ([]byte, []int) {
+	return file_proto_mddb_proto_rawDescGZIP(), []int{15}
+}
+
+func (x *SearchResponse) GetDocuments() []*Document {
+	if x != nil {
+		return x.Documents
+	}
+	return nil
+}
+
+func (x *SearchResponse) GetTotal() int32 {
+	if x != nil {
+		return x.Total
+	}
+	return 0
+}
+
+// Export request
+type ExportRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Collection    string                 `protobuf:"bytes,1,opt,name=collection,proto3" json:"collection,omitempty"`
+	FilterMeta    map[string]*MetaValues `protobuf:"bytes,2,rep,name=filter_meta,json=filterMeta,proto3" json:"filter_meta,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	Format        string                 `protobuf:"bytes,3,opt,name=format,proto3" json:"format,omitempty"` // ndjson, zip, tar
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ExportRequest) Reset() {
+	*x = ExportRequest{}
+	mi := &file_proto_mddb_proto_msgTypes[16]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ExportRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ExportRequest) ProtoMessage() {}
+
+func (x *ExportRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_mddb_proto_msgTypes[16]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ExportRequest.ProtoReflect.Descriptor instead.
+func (*ExportRequest) Descriptor() ([]byte, []int) {
+	return file_proto_mddb_proto_rawDescGZIP(), []int{16}
+}
+
+func (x *ExportRequest) GetCollection() string {
+	if x != nil {
+		return x.Collection
+	}
+	return ""
+}
+
+func (x *ExportRequest) GetFilterMeta() map[string]*MetaValues {
+	if x != nil {
+		return x.FilterMeta
+	}
+	return nil
+}
+
+func (x *ExportRequest) GetFormat() string {
+	if x != nil {
+		return x.Format
+	}
+	return ""
+}
+
+// Export chunk (streaming)
+type ExportChunk struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Data          []byte                 `protobuf:"bytes,1,opt,name=data,proto3" json:"data,omitempty"`
+	IsLast        bool                   `protobuf:"varint,2,opt,name=is_last,json=isLast,proto3" json:"is_last,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ExportChunk) Reset() {
+	*x = ExportChunk{}
+	mi := &file_proto_mddb_proto_msgTypes[17]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ExportChunk) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ExportChunk) ProtoMessage() {}
+
+func (x *ExportChunk) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_mddb_proto_msgTypes[17]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ExportChunk.ProtoReflect.Descriptor instead.
+func (*ExportChunk) Descriptor() ([]byte, []int) {
+	return file_proto_mddb_proto_rawDescGZIP(), []int{17}
+}
+
+func (x *ExportChunk) GetData() []byte {
+	if x != nil {
+		return x.Data
+	}
+	return nil
+}
+
+func (x *ExportChunk) GetIsLast() bool {
+	if x != nil {
+		return x.IsLast
+	}
+	return false
+}
+
+// Backup request
+type BackupRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	To            string                 `protobuf:"bytes,1,opt,name=to,proto3" json:"to,omitempty"`                   // File name inside the server's backup directory
+	Compression   string                 `protobuf:"bytes,2,opt,name=compression,proto3" json:"compression,omitempty"` // none (default) or zstd
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *BackupRequest) Reset() {
+	*x = BackupRequest{}
+	mi := &file_proto_mddb_proto_msgTypes[18]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *BackupRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*BackupRequest) ProtoMessage() {}
+
+func (x *BackupRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_mddb_proto_msgTypes[18]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use BackupRequest.ProtoReflect.Descriptor instead.
+func (*BackupRequest) Descriptor() ([]byte, []int) {
+	return file_proto_mddb_proto_rawDescGZIP(), []int{18}
+}
+
+func (x *BackupRequest) GetTo() string {
+	if x != nil {
+		return x.To
+	}
+	return ""
+}
+
+func (x *BackupRequest) GetCompression() string {
+	if x != nil {
+		return x.Compression
+	}
+	return ""
+}
+
+// Backup response
+type BackupResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Backup        string                 `protobuf:"bytes,1,opt,name=backup,proto3" json:"backup,omitempty"`
+	Manifest      *BackupManifest        `protobuf:"bytes,2,opt,name=manifest,proto3" json:"manifest,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *BackupResponse) Reset() {
+	*x = BackupResponse{}
+	mi := &file_proto_mddb_proto_msgTypes[19]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *BackupResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*BackupResponse) ProtoMessage() {}
+
+func (x *BackupResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_mddb_proto_msgTypes[19]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use BackupResponse.ProtoReflect.Descriptor instead.
+func (*BackupResponse) Descriptor() ([]byte, []int) {
+	return file_proto_mddb_proto_rawDescGZIP(), []int{19}
+}
+
+func (x *BackupResponse) GetBackup() string {
+	if x != nil {
+		return x.Backup
+	}
+	return ""
+}
+
+func (x *BackupResponse) GetManifest() *BackupManifest {
+	if x != nil {
+		return x.Manifest
+	}
+	return nil
+}
+
+// Streamed backup request
+type StreamBackupRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Compression   string                 `protobuf:"bytes,1,opt,name=compression,proto3" json:"compression,omitempty"` // none (default) or zstd
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *StreamBackupRequest) Reset() {
+	*x = StreamBackupRequest{}
+	mi := &file_proto_mddb_proto_msgTypes[20]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *StreamBackupRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*StreamBackupRequest) ProtoMessage() {}
+
+func (x *StreamBackupRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_mddb_proto_msgTypes[20]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use StreamBackupRequest.ProtoReflect.Descriptor instead.
+func (*StreamBackupRequest) Descriptor() ([]byte, []int) {
+	return file_proto_mddb_proto_rawDescGZIP(), []int{20}
+}
+
+func (x *StreamBackupRequest) GetCompression() string {
+	if x != nil {
+		return x.Compression
+	}
+	return ""
+}
+
+// Streamed backup chunk
+type BackupChunk struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Data          []byte                 `protobuf:"bytes,1,opt,name=data,proto3" json:"data,omitempty"`
+	Manifest      *BackupManifest        `protobuf:"bytes,2,opt,name=manifest,proto3" json:"manifest,omitempty"` // set on the last chunk only
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *BackupChunk) Reset() {
+	*x = BackupChunk{}
+	mi := &file_proto_mddb_proto_msgTypes[21]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *BackupChunk) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*BackupChunk) ProtoMessage() {}
+
+func (x *BackupChunk) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_mddb_proto_msgTypes[21]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use BackupChunk.ProtoReflect.Descriptor instead.
+func (*BackupChunk) Descriptor() ([]byte, []int) {
+	return file_proto_mddb_proto_rawDescGZIP(), []int{21}
+}
+
+func (x *BackupChunk) GetData() []byte {
+	if x != nil {
+		return x.Data
+	}
+	return nil
+}
+
+func (x *BackupChunk) GetManifest() *BackupManifest {
+	if x != nil {
+		return x.Manifest
+	}
+	return nil
+}
+
+// Description of a backup, used to verify it
+type BackupManifest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Format        string                 `protobuf:"bytes,1,opt,name=format,proto3" json:"format,omitempty"`
+	Compression   string                 `protobuf:"bytes,2,opt,name=compression,proto3" json:"compression,omitempty"`
+	CreatedAt     int64                  `protobuf:"varint,3,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
+	TxId          int64                  `protobuf:"varint,4,opt,name=tx_id,json=txId,proto3" json:"tx_id,omitempty"`       // transaction the snapshot was taken at
+	DbSize        int64                  `protobuf:"varint,5,opt,name=db_size,json=dbSize,proto3" json:"db_size,omitempty"` // bytes of the database image
+	Size          int64                  `protobuf:"varint,6,opt,name=size,proto3" json:"size,omitempty"`                   // bytes of the backup, after compression
+	Sha256        string                 `protobuf:"bytes,7,opt,name=sha256,proto3" json:"sha256,omitempty"`                // hex digest of the backup bytes
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *BackupManifest) Reset() {
+	*x = BackupManifest{}
+	mi := &file_proto_mddb_proto_msgTypes[22]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *BackupManifest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*BackupManifest) ProtoMessage() {}
+
+func (x *BackupManifest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_mddb_proto_msgTypes[22]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use BackupManifest.ProtoReflect.Descriptor instead.
+func (*BackupManifest) Descriptor() ([]byte, []int) {
+	return file_proto_mddb_proto_rawDescGZIP(), []int{22}
+}
+
+func (x *BackupManifest) GetFormat() string {
+	if x != nil {
+		return x.Format
+	}
+	return ""
+}
+
+func (x *BackupManifest) GetCompression() string {
+	if x != nil {
+		return x.Compression
+	}
+	return ""
+}
+
+func (x *BackupManifest) GetCreatedAt() int64 {
+	if x != nil {
+		return x.CreatedAt
+	}
+	return 0
+}
+
+func (x *BackupManifest) GetTxId() int64 {
+	if x != nil {
+		return x.TxId
+	}
+	return 0
+}
+
+func (x *BackupManifest) GetDbSize() int64 {
+	if x != nil {
+		return x.DbSize
+	}
+	return 0
+}
+
+func (x *BackupManifest) GetSize() int64 {
+	if x != nil {
+		return x.Size
+	}
+	return 0
+}
+
+func (x *BackupManifest) GetSha256() string {
+	if x != nil {
+		return x.Sha256
+	}
+	return ""
+}
+
+// Restore request
+type RestoreRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	From          string                 `protobuf:"bytes,1,opt,name=from,proto3" json:"from,omitempty"`          // Backup file name inside the server's backup directory
+	Sha256        string                 `protobuf:"bytes,2,opt,name=sha256,proto3" json:"sha256,omitempty"`      // Expected SHA-256 of the backup file (optional)
+	Rollback      bool                   `protobuf:"varint,3,opt,name=rollback,proto3" json:"rollback,omitempty"` // Swap back the file replaced by the last restore instead
+	At            string                 `protobuf:"bytes,4,opt,name=at,proto3" json:"at,omitempty"`              // RFC 3339 time to restore to, replaying the change log onto from or the newest earlier backup
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RestoreRequest) Reset() {
+	*x = RestoreRequest{}
+	mi := &file_proto_mddb_proto_msgTypes[23]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RestoreRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RestoreRequest) ProtoMessage() {}
+
+func (x *RestoreRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_mddb_proto_msgTypes[23]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RestoreRequest.ProtoReflect.Descriptor instead.
+func (*RestoreRequest) Descriptor() ([]byte, []int) {
+	return file_proto_mddb_proto_rawDescGZIP(), []int{23}
+}
+
+func (x *RestoreRequest) GetFrom() string {
+	if x != nil {
+		return x.From
+	}
+	return ""
+}
+
+func (x *RestoreRequest) GetSha256() string {
+	if x != nil {
+		return x.Sha256
+	}
+	return ""
+}
+
+func (x *RestoreRequest) GetRollback() bool {
+	if x != nil {
+		return x.Rollback
+	}
+	return false
+}
+
+func (x *RestoreRequest) GetAt() string {
+	if x != nil {
+		return x.At
+	}
+	return ""
+}
+
+// Uploaded backup for StreamRestore, plain or zstd-compressed
+type RestoreChunk struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Data          []byte                 `protobuf:"bytes,1,opt,name=data,proto3" json:"data,omitempty"`
+	Sha256        string                 `protobuf:"bytes,2,opt,name=sha256,proto3" json:"sha256,omitempty"` // Expected SHA-256 of the whole upload, in the first chunk (optional)
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RestoreChunk) Reset() {
+	*x = RestoreChunk{}
+	mi := &file_proto_mddb_proto_msgTypes[24]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RestoreChunk) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RestoreChunk) ProtoMessage() {}
+
+func (x *RestoreChunk) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_mddb_proto_msgTypes[24]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RestoreChunk.ProtoReflect.Descriptor instead.
+func (*RestoreChunk) Descriptor() ([]byte, []int) {
+	return file_proto_mddb_proto_rawDescGZIP(), []int{24}
+}
+
+func (x *RestoreChunk) GetData() []byte {
+	if x != nil {
+		return x.Data
+	}
+	return nil
+}
+
+func (x *RestoreChunk) GetSha256() string {
+	if x != nil {
+		return x.Sha256
+	}
+	return ""
+}
+
+// Restore response
+type RestoreResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Restored      string                 `protobuf:"bytes,1,opt,name=restored,proto3" json:"restored,omitempty"`
+	Previous      string                 `protobuf:"bytes,2,opt,name=previous,proto3" json:"previous,omitempty"`       // Replaced database file, kept for rollback
+	Compression   string                 `protobuf:"bytes,3,opt,name=compression,proto3" json:"compression,omitempty"` // Of the backup as read
+	Sha256        string                 `protobuf:"bytes,4,opt,name=sha256,proto3" json:"sha256,omitempty"`           // Of the backup as read
+	Size          int64                  `protobuf:"varint,5,opt,name=size,proto3" json:"size,omitempty"`              // Restored database file size
+	TxId          int64                  `protobuf:"varint,6,opt,name=tx_id,json=txId,proto3" json:"tx_id,omitempty"`
+	DurationMs    int64                  `protobuf:"varint,7,opt,name=duration_ms,json=durationMs,proto3" json:"duration_ms,omitempty"`
+	Replayed      int64                  `protobuf:"varint,8,opt,name=replayed,proto3" json:"replayed,omitempty"` // Changes replayed from the change log (restore to a point in time)
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *RestoreResponse) Reset() {
+	*x = RestoreResponse{}
+	mi := &file_proto_mddb_proto_msgTypes[25]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *RestoreResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*RestoreResponse) ProtoMessage() {}
+
+func (x *RestoreResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_mddb_proto_msgTypes[25]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use RestoreResponse.ProtoReflect.Descriptor instead.
+func (*RestoreResponse) Descriptor() ([]byte, []int) {
+	return file_proto_mddb_proto_rawDescGZIP(), []int{25}
+}
+
+func (x *RestoreResponse) GetRestored() string {
+	if x != nil {
+		return x.Restored
+	}
+	return ""
+}
+
+func (x *RestoreResponse) GetPrevious() string {
+	if x != nil {
+		return x.Previous
+	}
+	return ""
+}
+
+func (x *RestoreResponse) GetCompression() string {
+	if x != nil {
+		return x.Compression
+	}
+	return ""
+}
+
+func (x *RestoreResponse) GetSha256() string {
+	if x != nil {
+		return x.Sha256
+	}
+	return ""
+}
+
+func (x *RestoreResponse) GetSize() int64 {
+	if x != nil {
+		return x.Size
+	}
+	return 0
+}
+
+func (x *RestoreResponse) GetTxId() int64 {
+	if x != nil {
+		return x.TxId
+	}
+	return 0
+}
+
+func (x *RestoreResponse) GetDurationMs() int64 {
+	if x != nil {
+		return x.DurationMs
+	}
+	return 0
+}
+
+func (x *RestoreResponse) GetReplayed() int64 {
+	if x != nil {
+		return x.Replayed
+	}
+	return 0
+}
+
+// Dump request
+type DumpRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Collections   []string               `protobuf:"bytes,1,rep,name=collections,proto3" json:"collections,omitempty"`                           // Collections to dump (default all)
+	Compression   string                 `protobuf:"bytes,2,opt,name=compression,proto3" json:"compression,omitempty"`                           // none (default) or zstd
+	SkipRevisions bool                   `protobuf:"varint,3,opt,name=skip_revisions,json=skipRevisions,proto3" json:"skip_revisions,omitempty"` // Leave out revision history
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DumpRequest) Reset() {
+	*x = DumpRequest{}
+	mi := &file_proto_mddb_proto_msgTypes[26]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DumpRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DumpRequest) ProtoMessage() {}
+
+func (x *DumpRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_mddb_proto_msgTypes[26]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DumpRequest.ProtoReflect.Descriptor instead.
+func (*DumpRequest) Descriptor() ([]byte, []int) {
+	return file_proto_mddb_proto_rawDescGZIP(), []int{26}
+}
+
+func (x *DumpRequest) GetCollections() []string {
+	if x != nil {
+		return x.Collections
+	}
+	return nil
+}
+
+func (x *DumpRequest) GetCompression() string {
+	if x != nil {
+		return x.Compression
+	}
+	return ""
+}
+
+func (x *DumpRequest) GetSkipRevisions() bool {
+	if x != nil {
+		return x.SkipRevisions
+	}
+	return false
+}
+
+// Streamed dump chunk
+type DumpChunk struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Data          []byte                 `protobuf:"bytes,1,opt,name=data,proto3" json:"data,omitempty"`
+	Manifest      *DumpManifest          `protobuf:"bytes,2,opt,name=manifest,proto3" json:"manifest,omitempty"` // set on the last chunk only
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DumpChunk) Reset() {
+	*x = DumpChunk{}
+	mi := &file_proto_mddb_proto_msgTypes[27]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DumpChunk) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DumpChunk) ProtoMessage() {}
+
+func (x *DumpChunk) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_mddb_proto_msgTypes[27]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DumpChunk.ProtoReflect.Descriptor instead.
+func (*DumpChunk) Descriptor() ([]byte, []int) {
+	return file_proto_mddb_proto_rawDescGZIP(), []int{27}
+}
+
+func (x *DumpChunk) GetData() []byte {
+	if x != nil {
+		return x.Data
+	}
+	return nil
+}
+
+func (x *DumpChunk) GetManifest() *DumpManifest {
+	if x != nil {
+		return x.Manifest
+	}
+	return nil
+}
+
+// Summary of a dump; the archive's manifest.json also lists every entry's checksum
+type DumpManifest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Format        string                 `protobuf:"bytes,1,opt,name=format,proto3" json:"format,omitempty"`
+	Version       int32                  `protobuf:"varint,2,opt,name=version,proto3" json:"version,omitempty"`
+	CreatedAt     int64                  `protobuf:"varint,3,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
+	TxId          int64                  `protobuf:"varint,4,opt,name=tx_id,json=txId,proto3" json:"tx_id,omitempty"` // transaction the dump was read from
+	Collections   []*DumpedCollection    `protobuf:"bytes,5,rep,name=collections,proto3" json:"collections,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DumpManifest) Reset() {
+	*x = DumpManifest{}
+	mi := &file_proto_mddb_proto_msgTypes[28]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DumpManifest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DumpManifest) ProtoMessage() {}
+
+func (x *DumpManifest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_mddb_proto_msgTypes[28]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DumpManifest.ProtoReflect.Descriptor instead.
+func (*DumpManifest) Descriptor() ([]byte, []int) {
+	return file_proto_mddb_proto_rawDescGZIP(), []int{28}
+}
+
+func (x *DumpManifest) GetFormat() string {
+	if x != nil {
+		return x.Format
+	}
+	return ""
+}
+
+func (x *DumpManifest) GetVersion() int32 {
+	if x != nil {
+		return x.Version
+	}
+	return 0
+}
+
+func (x *DumpManifest) GetCreatedAt() int64 {
+	if x != nil {
+		return x.CreatedAt
+	}
+	return 0
+}
+
+func (x *DumpManifest) GetTxId() int64 {
+	if x != nil {
+		return x.TxId
+	}
+	return 0
+}
+
+func (x *DumpManifest) GetCollections() []*DumpedCollection {
+	if x != nil {
+		return x.Collections
+	}
+	return nil
+}
+
+// Collection in a dump
+type DumpedCollection struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Name          string                 `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	Documents     int64                  `protobuf:"varint,2,opt,name=documents,proto3" json:"documents,omitempty"`
+	Revisions     int64                  `protobuf:"varint,3,opt,name=revisions,proto3" json:"revisions,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DumpedCollection) Reset() {
+	*x = DumpedCollection{}
+	mi := &file_proto_mddb_proto_msgTypes[29]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DumpedCollection) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DumpedCollection) ProtoMessage() {}
+
+func (x *DumpedCollection) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_mddb_proto_msgTypes[29]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DumpedCollection.ProtoReflect.Descriptor instead.
+func (*DumpedCollection) Descriptor() ([]byte, []int) {
+	return file_proto_mddb_proto_rawDescGZIP(), []int{29}
+}
+
+func (x *DumpedCollection) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *DumpedCollection) GetDocuments() int64 {
+	if x != nil {
+		return x.Documents
+	}
+	return 0
+}
+
+func (x *DumpedCollection) GetRevisions() int64 {
+	if x != nil {
+		return x.Revisions
+	}
+	return 0
+}
+
+// Uploaded dump for Load, plain or zstd-compressed
+type LoadChunk struct {
+	state protoimpl.MessageState `protogen:"open.v1"`
+	Data  []byte                 `protobuf:"bytes,1,opt,name=data,proto3" json:"data,omitempty"`
+	// Options, in the first chunk
+	Collections   []string          `protobuf:"bytes,2,rep,name=collections,proto3" json:"collections,omitempty"`                                                                 // Collections of the dump to load (default all)
+	Rename        map[string]string `protobuf:"bytes,3,rep,name=rename,proto3" json:"rename,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"` // Dump collection name -> target collection
+	Replace       bool              `protobuf:"varint,4,opt,name=replace,proto3" json:"replace,omitempty"`                                                                        // Replace existing target collections instead of failing
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *LoadChunk) Reset() {
+	*x = LoadChunk{}
+	mi := &file_proto_mddb_proto_msgTypes[30]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *LoadChunk) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*LoadChunk) ProtoMessage() {}
+
+func (x *LoadChunk) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_mddb_proto_msgTypes[30]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use LoadChunk.ProtoReflect.Descriptor instead.
+func (*LoadChunk) Descriptor() ([]byte, []int) {
+	return file_proto_mddb_proto_rawDescGZIP(), []int{30}
+}
+
+func (x *LoadChunk) GetData() []byte {
+	if x != nil {
+		return x.Data
+	}
+	return nil
+}
+
+func (x *LoadChunk) GetCollections() []string {
+	if x != nil {
+		return x.Collections
+	}
+	return nil
+}
+
+func (x *LoadChunk) GetRename() map[string]string {
+	if x != nil {
+		return x.Rename
+	}
+	return nil
+}
+
+func (x *LoadChunk) GetReplace() bool {
+	if x != nil {
+		return x.Replace
+	}
+	return false
+}
+
+// Load response
+type LoadResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Format        string                 `protobuf:"bytes,1,opt,name=format,proto3" json:"format,omitempty"`
+	Version       int32                  `protobuf:"varint,2,opt,name=version,proto3" json:"version,omitempty"`
+	Collections   []*LoadedCollection    `protobuf:"bytes,3,rep,name=collections,proto3" json:"collections,omitempty"`
+	DurationMs    int64                  `protobuf:"varint,4,opt,name=duration_ms,json=durationMs,proto3" json:"duration_ms,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *LoadResponse) Reset() {
+	*x = LoadResponse{}
+	mi := &file_proto_mddb_proto_msgTypes[31]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *LoadResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*LoadResponse) ProtoMessage() {}
+
+func (x *LoadResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_mddb_proto_msgTypes[31]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use LoadResponse.ProtoReflect.Descriptor instead.
+func (*LoadResponse) Descriptor() ([]byte, []int) {
+	return file_proto_mddb_proto_rawDescGZIP(), []int{31}
+}
+
+func (x *LoadResponse) GetFormat() string {
+	if x != nil {
+		return x.Format
+	}
+	return ""
+}
+
+func (x *LoadResponse) GetVersion() int32 {
+	if x != nil {
+		return x.Version
+	}
+	return 0
+}
+
+func (x *LoadResponse) GetCollections() []*LoadedCollection {
+	if x != nil {
+		return x.Collections
+	}
+	return nil
+}
+
+func (x *LoadResponse) GetDurationMs() int64 {
+	if x != nil {
+		return x.DurationMs
+	}
+	return 0
+}
+
+// Collection written by a load
+type LoadedCollection struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	From          string                 `protobuf:"bytes,1,opt,name=from,proto3" json:"from,omitempty"` // Name in the dump
+	Name          string                 `protobuf:"bytes,2,opt,name=name,proto3" json:"name,omitempty"`
+	Documents     int64                  `protobuf:"varint,3,opt,name=documents,proto3" json:"documents,omitempty"`
+	Revisions     int64                  `protobuf:"varint,4,opt,name=revisions,proto3" json:"revisions,omitempty"`
+	Replaced      bool                   `protobuf:"varint,5,opt,name=replaced,proto3" json:"replaced,omitempty"` // An existing collection was replaced
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *LoadedCollection) Reset() {
+	*x = LoadedCollection{}
+	mi := &file_proto_mddb_proto_msgTypes[32]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *LoadedCollection) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*LoadedCollection) ProtoMessage() {}
+
+func (x *LoadedCollection) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_mddb_proto_msgTypes[32]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use LoadedCollection.ProtoReflect.Descriptor instead.
+func (*LoadedCollection) Descriptor() ([]byte, []int) {
+	return file_proto_mddb_proto_rawDescGZIP(), []int{32}
+}
+
+func (x *LoadedCollection) GetFrom() string {
+	if x != nil {
+		return x.From
+	}
+	return ""
+}
+
+func (x *LoadedCollection) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *LoadedCollection) GetDocuments() int64 {
+	if x != nil {
+		return x.Documents
+	}
+	return 0
+}
+
+func (x *LoadedCollection) GetRevisions() int64 {
+	if x != nil {
+		return x.Revisions
+	}
+	return 0
+}
+
+func (x *LoadedCollection) GetReplaced() bool {
+	if x != nil {
+		return x.Replaced
+	}
+	return false
+}
+
+// Truncate request
+type TruncateRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Collection    string                 `protobuf:"bytes,1,opt,name=collection,proto3" json:"collection,omitempty"`
+	KeepRevs      int32                  `protobuf:"varint,2,opt,name=keep_revs,json=keepRevs,proto3" json:"keep_revs,omitempty"`
+	DropCache     bool                   `protobuf:"varint,3,opt,name=drop_cache,json=dropCache,proto3" json:"drop_cache,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *TruncateRequest) Reset() {
+	*x = TruncateRequest{}
+	mi := &file_proto_mddb_proto_msgTypes[33]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *TruncateRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TruncateRequest) ProtoMessage() {}
+
+func (x *TruncateRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_mddb_proto_msgTypes[33]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use TruncateRequest.ProtoReflect.Descriptor instead.
+func (*TruncateRequest) Descriptor() ([]byte, []int) {
+	return file_proto_mddb_proto_rawDescGZIP(), []int{33}
+}
+
+func (x *TruncateRequest) GetCollection() string {
+	if x != nil {
+		return x.Collection
+	}
+	return ""
+}
+
+func (x *TruncateRequest) GetKeepRevs() int32 {
+	if x != nil {
+		return x.KeepRevs
+	}
+	return 0
+}
+
+func (x *TruncateRequest) GetDropCache() bool {
+	if x != nil {
+		return x.DropCache
+	}
+	return false
+}
+
+// Truncate response
+type TruncateResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Status        string                 `protobuf:"bytes,1,opt,name=status,proto3" json:"status,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *TruncateResponse) Reset() {
+	*x = TruncateResponse{}
+	mi := &file_proto_mddb_proto_msgTypes[34]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *TruncateResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*TruncateResponse) ProtoMessage() {}
+
+func (x *TruncateResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_mddb_proto_msgTypes[34]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use TruncateResponse.ProtoReflect.Descriptor instead.
+func (*TruncateResponse) Descriptor() ([]byte, []int) {
+	return file_proto_mddb_proto_rawDescGZIP(), []int{34}
+}
+
+func (x *TruncateResponse) GetStatus() string {
+	if x != nil {
+		return x.Status
+	}
+	return ""
+}
+
+// Consistency check request
+type FsckRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Collection    string                 `protobuf:"bytes,1,opt,name=collection,proto3" json:"collection,omitempty"` // empty checks every collection
+	Repair        bool                   `protobuf:"varint,2,opt,name=repair,proto3" json:"repair,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *FsckRequest) Reset() {
+	*x = FsckRequest{}
+	mi := &file_proto_mddb_proto_msgTypes[35]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *FsckRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*FsckRequest) ProtoMessage() {}
+
+func (x *FsckRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_mddb_proto_msgTypes[35]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use FsckRequest.ProtoReflect.Descriptor instead.
+func (*FsckRequest) Descriptor() ([]byte, []int) {
+	return file_proto_mddb_proto_rawDescGZIP(), []int{35}
+}
+
+func (x *FsckRequest) GetCollection() string {
+	if x != nil {
+		return x.Collection
+	}
+	return ""
+}
+
+func (x *FsckRequest) GetRepair() bool {
+	if x != nil {
+		return x.Repair
+	}
+	return false
+}
+
+// Consistency check report
+type FsckResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Documents     int32                  `protobuf:"varint,1,opt,name=documents,proto3" json:"documents,omitempty"`
+	KeyEntries    int32                  `protobuf:"varint,2,opt,name=key_entries,json=keyEntries,proto3" json:"key_entries,omitempty"`
+	MetaIndices   int32                  `protobuf:"varint,3,opt,name=meta_indices,json=metaIndices,proto3" json:"meta_indices,omitempty"`
+	Revisions     int32                  `protobuf:"varint,4,opt,name=revisions,proto3" json:"revisions,omitempty"`
+	Problems      map[string]int32       `protobuf:"bytes,5,rep,name=problems,proto3" json:"problems,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"varint,2,opt,name=value"` // count per kind
+	Issues        []*FsckIssue           `protobuf:"bytes,6,rep,name=issues,proto3" json:"issues,omitempty"`                                                                                // the first 1000 problems
+	Repaired      int32                  `protobuf:"varint,7,opt,name=repaired,proto3" json:"repaired,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *FsckResponse) Reset() {
+	*x = FsckResponse{}
+	mi := &file_proto_mddb_proto_msgTypes[36]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *FsckResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*FsckResponse) ProtoMessage() {}
+
+func (x *FsckResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_mddb_proto_msgTypes[36]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use FsckResponse.ProtoReflect.Descriptor instead.
+func (*FsckResponse) Descriptor() ([]byte, []int) {
+	return file_proto_mddb_proto_rawDescGZIP(), []int{36}
+}
+
+func (x *FsckResponse) GetDocuments() int32 {
+	if x != nil {
+		return x.Documents
+	}
+	return 0
+}
+
+func (x *FsckResponse) GetKeyEntries() int32 {
+	if x != nil {
+		return x.KeyEntries
+	}
+	return 0
+}
+
+func (x *FsckResponse) GetMetaIndices() int32 {
+	if x != nil {
+		return x.MetaIndices
+	}
+	return 0
+}
+
+func (x *FsckResponse) GetRevisions() int32 {
+	if x != nil {
+		return x.Revisions
+	}
+	return 0
+}
+
+func (x *FsckResponse) GetProblems() map[string]int32 {
+	if x != nil {
+		return x.Problems
+	}
+	return nil
+}
+
+func (x *FsckResponse) GetIssues() []*FsckIssue {
+	if x != nil {
+		return x.Issues
+	}
+	return nil
+}
+
+func (x *FsckResponse) GetRepaired() int32 {
+	if x != nil {
+		return x.Repaired
+	}
+	return 0
+}
+
+// Inconsistency found by Fsck
+type FsckIssue struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Kind          string                 `protobuf:"bytes,1,opt,name=kind,proto3" json:"kind,omitempty"`
+	Collection    string                 `protobuf:"bytes,2,opt,name=collection,proto3" json:"collection,omitempty"`
+	Key           string                 `protobuf:"bytes,3,opt,name=key,proto3" json:"key,omitempty"`
+	Repaired      bool                   `protobuf:"varint,4,opt,name=repaired,proto3" json:"repaired,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *FsckIssue) Reset() {
+	*x = FsckIssue{}
+	mi := &file_proto_mddb_proto_msgTypes[37]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *FsckIssue) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*FsckIssue) ProtoMessage() {}
+
+func (x *FsckIssue) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_mddb_proto_msgTypes[37]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use FsckIssue.ProtoReflect.Descriptor instead.
+func (*FsckIssue) Descriptor() ([]byte, []int) {
+	return file_proto_mddb_proto_rawDescGZIP(), []int{37}
+}
+
+func (x *FsckIssue) GetKind() string {
+	if x != nil {
+		return x.Kind
+	}
+	return ""
+}
+
+func (x *FsckIssue) GetCollection() string {
+	if x != nil {
+		return x.Collection
+	}
+	return ""
+}
+
+func (x *FsckIssue) GetKey() string {
+	if x != nil {
+		return x.Key
+	}
+	return ""
+}
+
+func (x *FsckIssue) GetRepaired() bool {
+	if x != nil {
+		return x.Repaired
+	}
+	return false
+}
+
+// Compaction request
+type CompactRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	MinFreeRatio  float64                `protobuf:"fixed64,1,opt,name=min_free_ratio,json=minFreeRatio,proto3" json:"min_free_ratio,omitempty"` // skip unless free pages are at least this fraction of the file
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CompactRequest) Reset() {
+	*x = CompactRequest{}
+	mi := &file_proto_mddb_proto_msgTypes[38]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CompactRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CompactRequest) ProtoMessage() {}
+
+func (x *CompactRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_mddb_proto_msgTypes[38]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CompactRequest.ProtoReflect.Descriptor instead.
+func (*CompactRequest) Descriptor() ([]byte, []int) {
+	return file_proto_mddb_proto_rawDescGZIP(), []int{38}
+}
+
+func (x *CompactRequest) GetMinFreeRatio() float64 {
+	if x != nil {
+		return x.MinFreeRatio
+	}
+	return 0
+}
+
+// Compaction result
+type CompactResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Compacted     bool                   `protobuf:"varint,1,opt,name=compacted,proto3" json:"compacted,omitempty"` // false when skipped by min_free_ratio
+	SizeBefore    int64                  `protobuf:"varint,2,opt,name=size_before,json=sizeBefore,proto3" json:"size_before,omitempty"`
+	SizeAfter     int64                  `protobuf:"varint,3,opt,name=size_after,json=sizeAfter,proto3" json:"size_after,omitempty"`
+	FreeBefore    int64                  `protobuf:"varint,4,opt,name=free_before,json=freeBefore,proto3" json:"free_before,omitempty"` // bytes in free pages before compacting
+	Reclaimed     int64                  `protobuf:"varint,5,opt,name=reclaimed,proto3" json:"reclaimed,omitempty"`
+	DurationMs    int64                  `protobuf:"varint,6,opt,name=duration_ms,json=durationMs,proto3" json:"duration_ms,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CompactResponse) Reset() {
+	*x = CompactResponse{}
+	mi := &file_proto_mddb_proto_msgTypes[39]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CompactResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CompactResponse) ProtoMessage() {}
+
+func (x *CompactResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_mddb_proto_msgTypes[39]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CompactResponse.ProtoReflect.Descriptor instead.
+func (*CompactResponse) Descriptor() ([]byte, []int) {
+	return file_proto_mddb_proto_rawDescGZIP(), []int{39}
+}
+
+func (x *CompactResponse) GetCompacted() bool {
+	if x != nil {
+		return x.Compacted
+	}
+	return false
+}
+
+func (x *CompactResponse) GetSizeBefore() int64 {
+	if x != nil {
+		return x.SizeBefore
+	}
+	return 0
+}
+
+func (x *CompactResponse) GetSizeAfter() int64 {
+	if x != nil {
+		return x.SizeAfter
+	}
+	return 0
+}
+
+func (x *CompactResponse) GetFreeBefore() int64 {
+	if x != nil {
+		return x.FreeBefore
+	}
+	return 0
+}
+
+func (x *CompactResponse) GetReclaimed() int64 {
+	if x != nil {
+		return x.Reclaimed
+	}
+	return 0
+}
+
+func (x *CompactResponse) GetDurationMs() int64 {
+	if x != nil {
+		return x.DurationMs
+	}
+	return 0
+}
+
+// Stats request
+type StatsRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *StatsRequest) Reset() {
+	*x = StatsRequest{}
+	mi := &file_proto_mddb_proto_msgTypes[40]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *StatsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*StatsRequest) ProtoMessage() {}
+
+func (x *StatsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_mddb_proto_msgTypes[40]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use StatsRequest.ProtoReflect.Descriptor instead.
+func (*StatsRequest) Descriptor() ([]byte, []int) {
+	return file_proto_mddb_proto_rawDescGZIP(), []int{40}
+}
+
+// Stats response
+type StatsResponse struct {
+	state            protoimpl.MessageState `protogen:"open.v1"`
+	DatabasePath     string                 `protobuf:"bytes,1,opt,name=database_path,json=databasePath,proto3" json:"database_path,omitempty"`
+	DatabaseSize     int64                  `protobuf:"varint,2,opt,name=database_size,json=databaseSize,proto3" json:"database_size,omitempty"`
+	Mode             string                 `protobuf:"bytes,3,opt,name=mode,proto3" json:"mode,omitempty"`
+	Collections      []*CollectionStats     `protobuf:"bytes,4,rep,name=collections,proto3" json:"collections,omitempty"`
+	TotalDocuments   int32                  `protobuf:"varint,5,opt,name=total_documents,json=totalDocuments,proto3" json:"total_documents,omitempty"`
+	TotalRevisions   int32                  `protobuf:"varint,6,opt,name=total_revisions,json=totalRevisions,proto3" json:"total_revisions,omitempty"`
+	TotalMetaIndices int32                  `protobuf:"varint,7,opt,name=total_meta_indices,json=totalMetaIndices,proto3" json:"total_meta_indices,omitempty"`
+	Cache            *CacheStats            `protobuf:"bytes,8,opt,name=cache,proto3" json:"cache,omitempty"`
+	unknownFields    protoimpl.UnknownFields
+	sizeCache        protoimpl.SizeCache
+}
+
+func (x *StatsResponse) Reset() {
+	*x = StatsResponse{}
+	mi := &file_proto_mddb_proto_msgTypes[41]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *StatsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*StatsResponse) ProtoMessage() {}
+
+func (x *StatsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_mddb_proto_msgTypes[41]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use StatsResponse.ProtoReflect.Descriptor instead.
+func (*StatsResponse) Descriptor() ([]byte, []int) {
+	return file_proto_mddb_proto_rawDescGZIP(), []int{41}
+}
+
+func (x *StatsResponse) GetDatabasePath() string {
+	if x != nil {
+		return x.DatabasePath
+	}
+	return ""
+}
+
+func (x *StatsResponse) GetDatabaseSize() int64 {
+	if x != nil {
+		return x.DatabaseSize
+	}
+	return 0
+}
+
+func (x *StatsResponse) GetMode() string {
+	if x != nil {
+		return x.Mode
+	}
+	return ""
+}
+
+func (x *StatsResponse) GetCollections() []*CollectionStats {
+	if x != nil {
+		return x.Collections
+	}
+	return nil
+}
+
+func (x *StatsResponse) GetTotalDocuments() int32 {
+	if x != nil {
+		return x.TotalDocuments
+	}
+	return 0
+}
+
+func (x *StatsResponse) GetTotalRevisions() int32 {
+	if x != nil {
+		return x.TotalRevisions
+	}
+	return 0
+}
+
+func (x *StatsResponse) GetTotalMetaIndices() int32 {
+	if x != nil {
+		return x.TotalMetaIndices
+	}
+	return 0
+}
+
+func (x *StatsResponse) GetCache() *CacheStats {
+	if x != nil {
+		return x.Cache
+	}
+	return nil
+}
+
+// Document cache statistics
+type CacheStats struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Hits          uint64                 `protobuf:"varint,1,opt,name=hits,proto3" json:"hits,omitempty"`
+	Misses        uint64                 `protobuf:"varint,2,opt,name=misses,proto3" json:"misses,omitempty"`
+	Evictions     uint64                 `protobuf:"varint,3,opt,name=evictions,proto3" json:"evictions,omitempty"`
+	Rejected      uint64                 `protobuf:"varint,4,opt,name=rejected,proto3" json:"rejected,omitempty"` // fills refused by the admission policy
+	Entries       int64                  `protobuf:"varint,5,opt,name=entries,proto3" json:"entries,omitempty"`
+	Bytes         int64                  `protobuf:"varint,6,opt,name=bytes,proto3" json:"bytes,omitempty"`
+	Budget        int64                  `protobuf:"varint,7,opt,name=budget,proto3" json:"budget,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CacheStats) Reset() {
+	*x = CacheStats{}
+	mi := &file_proto_mddb_proto_msgTypes[42]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CacheStats) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CacheStats) ProtoMessage() {}
+
+func (x *CacheStats) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_mddb_proto_msgTypes[42]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CacheStats.ProtoReflect.Descriptor instead.
+func (*CacheStats) Descriptor() ([]byte, []int) {
+	return file_proto_mddb_proto_rawDescGZIP(), []int{42}
+}
+
+func (x *CacheStats) GetHits() uint64 {
+	if x != nil {
+		return x.Hits
+	}
+	return 0
+}
+
+func (x *CacheStats) GetMisses() uint64 {
+	if x != nil {
+		return x.Misses
+	}
+	return 0
+}
+
+func (x *CacheStats) GetEvictions() uint64 {
+	if x != nil {
+		return x.Evictions
+	}
+	return 0
+}
+
+func (x *CacheStats) GetRejected() uint64 {
+	if x != nil {
+		return x.Rejected
+	}
+	return 0
+}
+
+func (x *CacheStats) GetEntries() int64 {
+	if x != nil {
+		return x.Entries
+	}
+	return 0
+}
+
+func (x *CacheStats) GetBytes() int64 {
+	if x != nil {
+		return x.Bytes
+	}
+	return 0
+}
+
+func (x *CacheStats) GetBudget() int64 {
+	if x != nil {
+		return x.Budget
+	}
+	return 0
+}
+
+// Collection statistics
+type CollectionStats struct {
+	state          protoimpl.MessageState `protogen:"open.v1"`
+	Name           string                 `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	DocumentCount  int32                  `protobuf:"varint,2,opt,name=document_count,json=documentCount,proto3" json:"document_count,omitempty"`
+	RevisionCount  int32                  `protobuf:"varint,3,opt,name=revision_count,json=revisionCount,proto3" json:"revision_count,omitempty"`
+	MetaIndexCount int32                  `protobuf:"varint,4,opt,name=meta_index_count,json=metaIndexCount,proto3" json:"meta_index_count,omitempty"`
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
+}
+
+func (x *CollectionStats) Reset() {
+	*x = CollectionStats{}
+	mi := &file_proto_mddb_proto_msgTypes[43]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CollectionStats) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CollectionStats) ProtoMessage() {}
+
+func (x *CollectionStats) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_mddb_proto_msgTypes[43]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CollectionStats.ProtoReflect.Descriptor instead.
+func (*CollectionStats) Descriptor() ([]byte, []int) {
+	return file_proto_mddb_proto_rawDescGZIP(), []int{43}
+}
+
+func (x *CollectionStats) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *CollectionStats) GetDocumentCount() int32 {
+	if x != nil {
+		return x.DocumentCount
+	}
+	return 0
+}
+
+func (x *CollectionStats) GetRevisionCount() int32 {
+	if x != nil {
+		return x.RevisionCount
+	}
+	return 0
+}
+
+func (x *CollectionStats) GetMetaIndexCount() int32 {
+	if x != nil {
+		return x.MetaIndexCount
+	}
+	return 0
+}
+
+// Update batch request
+type UpdateBatchRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Collection    string                 `protobuf:"bytes,1,opt,name=collection,proto3" json:"collection,omitempty"`
+	Documents     []*UpdateDocument      `protobuf:"bytes,2,rep,name=documents,proto3" json:"documents,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *UpdateBatchRequest) Reset() {
+	*x = UpdateBatchRequest{}
+	mi := &file_proto_mddb_proto_msgTypes[44]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *UpdateBatchRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UpdateBatchRequest) ProtoMessage() {}
+
+func (x *UpdateBatchRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_mddb_proto_msgTypes[44]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UpdateBatchRequest.ProtoReflect.Descriptor instead.
+func (*UpdateBatchRequest) Descriptor() ([]byte, []int) {
+	return file_proto_mddb_proto_rawDescGZIP(), []int{44}
+}
+
+func (x *UpdateBatchRequest) GetCollection() string {
+	if x != nil {
+		return x.Collection
+	}
+	return ""
+}
+
+func (x *UpdateBatchRequest) GetDocuments() []*UpdateDocument {
+	if x != nil {
+		return x.Documents
+	}
+	return nil
+}
+
+// Document for update operations
+type UpdateDocument struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Key           string                 `protobuf:"bytes,1,opt,name=key,proto3" json:"key,omitempty"`
+	Lang          string                 `protobuf:"bytes,2,opt,name=lang,proto3" json:"lang,omitempty"`
+	Meta          map[string]*MetaValues `protobuf:"bytes,3,rep,name=meta,proto3" json:"meta,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
+	ContentMd     string                 `protobuf:"bytes,4,opt,name=content_md,json=contentMd,proto3" json:"content_md,omitempty"`
+	SaveRevision  bool                   `protobuf:"varint,5,opt,name=save_revision,json=saveRevision,proto3" json:"save_revision,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *UpdateDocument) Reset() {
+	*x = UpdateDocument{}
+	mi := &file_proto_mddb_proto_msgTypes[45]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *UpdateDocument) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UpdateDocument) ProtoMessage() {}
+
+func (x *UpdateDocument) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_mddb_proto_msgTypes[45]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UpdateDocument.ProtoReflect.Descriptor instead.
+func (*UpdateDocument) Descriptor() ([]byte, []int) {
+	return file_proto_mddb_proto_rawDescGZIP(), []int{45}
+}
+
+func (x *UpdateDocument) GetKey() string {
+	if x != nil {
+		return x.Key
+	}
+	return ""
+}
+
+func (x *UpdateDocument) GetLang() string {
+	if x != nil {
+		return x.Lang
+	}
+	return ""
+}
+
+func (x *UpdateDocument) GetMeta() map[string]*MetaValues {
+	if x != nil {
+		return x.Meta
+	}
+	return nil
+}
+
+func (x *UpdateDocument) GetContentMd() string {
+	if x != nil {
+		return x.ContentMd
+	}
+	return ""
+}
+
+func (x *UpdateDocument) GetSaveRevision() bool {
+	if x != nil {
+		return x.SaveRevision
+	}
+	return false
+}
+
+// Update batch response
+type UpdateBatchResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Updated       int32                  `protobuf:"varint,1,opt,name=updated,proto3" json:"updated,omitempty"`
+	Failed        int32                  `protobuf:"varint,2,opt,name=failed,proto3" json:"failed,omitempty"`
+	Errors        []string               `protobuf:"bytes,3,rep,name=errors,proto3" json:"errors,omitempty"`
+	NotFound      int32                  `protobuf:"varint,4,opt,name=not_found,json=notFound,proto3" json:"not_found,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *UpdateBatchResponse) Reset() {
+	*x = UpdateBatchResponse{}
+	mi := &file_proto_mddb_proto_msgTypes[46]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *UpdateBatchResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*UpdateBatchResponse) ProtoMessage() {}
+
+func (x *UpdateBatchResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_mddb_proto_msgTypes[46]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use UpdateBatchResponse.ProtoReflect.Descriptor instead.
+func (*UpdateBatchResponse) Descriptor() ([]byte, []int) {
+	return file_proto_mddb_proto_rawDescGZIP(), []int{46}
+}
+
+func (x *UpdateBatchResponse) GetUpdated() int32 {
+	if x != nil {
+		return x.Updated
+	}
+	return 0
+}
+
+func (x *UpdateBatchResponse) GetFailed() int32 {
+	if x != nil {
+		return x.Failed
+	}
+	return 0
+}
+
+func (x *UpdateBatchResponse) GetErrors() []string {
+	if x != nil {
+		return x.Errors
+	}
+	return nil
+}
+
+func (x *UpdateBatchResponse) GetNotFound() int32 {
+	if x != nil {
+		return x.NotFound
+	}
+	return 0
+}
+
+// Delete batch request
+type DeleteBatchRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Collection    string                 `protobuf:"bytes,1,opt,name=collection,proto3" json:"collection,omitempty"`
+	Documents     []*DeleteDocument      `protobuf:"bytes,2,rep,name=documents,proto3" json:"documents,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DeleteBatchRequest) Reset() {
+	*x = DeleteBatchRequest{}
+	mi := &file_proto_mddb_proto_msgTypes[47]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DeleteBatchRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DeleteBatchRequest) ProtoMessage() {}
+
+func (x *DeleteBatchRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_mddb_proto_msgTypes[47]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DeleteBatchRequest.ProtoReflect.Descriptor instead.
+func (*DeleteBatchRequest) Descriptor() ([]byte, []int) {
+	return file_proto_mddb_proto_rawDescGZIP(), []int{47}
+}
+
+func (x *DeleteBatchRequest) GetCollection() string {
+	if x != nil {
+		return x.Collection
+	}
+	return ""
+}
+
+func (x *DeleteBatchRequest) GetDocuments() []*DeleteDocument {
+	if x != nil {
+		return x.Documents
+	}
+	return nil
+}
+
+// Document for delete operations
+type DeleteDocument struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Key           string                 `protobuf:"bytes,1,opt,name=key,proto3" json:"key,omitempty"`
+	Lang          string                 `protobuf:"bytes,2,opt,name=lang,proto3" json:"lang,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DeleteDocument) Reset() {
+	*x = DeleteDocument{}
+	mi := &file_proto_mddb_proto_msgTypes[48]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DeleteDocument) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DeleteDocument) ProtoMessage() {}
+
+func (x *DeleteDocument) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_mddb_proto_msgTypes[48]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DeleteDocument.ProtoReflect.Descriptor instead.
+func (*DeleteDocument) Descriptor() ([]byte, []int) {
+	return file_proto_mddb_proto_rawDescGZIP(), []int{48}
+}
+
+func (x *DeleteDocument) GetKey() string {
+	if x != nil {
+		return x.Key
+	}
+	return ""
+}
+
+func (x *DeleteDocument) GetLang() string {
+	if x != nil {
+		return x.Lang
+	}
+	return ""
+}
+
+// Delete batch response
+type DeleteBatchResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Deleted       int32                  `protobuf:"varint,1,opt,name=deleted,proto3" json:"deleted,omitempty"`
+	Failed        int32                  `protobuf:"varint,2,opt,name=failed,proto3" json:"failed,omitempty"`
+	Errors        []string               `protobuf:"bytes,3,rep,name=errors,proto3" json:"errors,omitempty"`
+	NotFound      int32                  `protobuf:"varint,4,opt,name=not_found,json=notFound,proto3" json:"not_found,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DeleteBatchResponse) Reset() {
+	*x = DeleteBatchResponse{}
+	mi := &file_proto_mddb_proto_msgTypes[49]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DeleteBatchResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DeleteBatchResponse) ProtoMessage() {}
+
+func (x *DeleteBatchResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_mddb_proto_msgTypes[49]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DeleteBatchResponse.ProtoReflect.Descriptor instead.
+func (*DeleteBatchResponse) Descriptor() ([]byte, []int) {
+	return file_proto_mddb_proto_rawDescGZIP(), []int{49}
+}
+
+func (x *DeleteBatchResponse) GetDeleted() int32 {
+	if x != nil {
+		return x.Deleted
+	}
+	return 0
+}
+
+func (x *DeleteBatchResponse) GetFailed() int32 {
+	if x != nil {
+		return x.Failed
+	}
+	return 0
+}
+
+func (x *DeleteBatchResponse) GetErrors() []string {
+	if x != nil {
+		return x.Errors
+	}
+	return nil
+}
+
+func (x *DeleteBatchResponse) GetNotFound() int32 {
+	if x != nil {
+		return x.NotFound
+	}
+	return 0
+}
+
+// Collection schema - validation rules enforced on every write
+type CollectionSchema struct {
+	state          protoimpl.MessageState `protogen:"open.v1"`
+	Collection     string                 `protobuf:"bytes,1,opt,name=collection,proto3" json:"collection,omitempty"`
+	RequiredMeta   []string               `protobuf:"bytes,2,rep,name=required_meta,json=requiredMeta,proto3" json:"required_meta,omitempty"`                                               // Meta keys every document must carry
+	OptionalMeta   []string               `protobuf:"bytes,3,rep,name=optional_meta,json=optionalMeta,proto3" json:"optional_meta,omitempty"`                                               // Further allowed meta keys
+	Enums          map[string]*MetaValues `protobuf:"bytes,4,rep,name=enums,proto3" json:"enums,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`       // Meta key -> allowed values
+	Patterns       map[string]string      `protobuf:"bytes,5,rep,name=patterns,proto3" json:"patterns,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"` // Meta key -> regexp every value must match
+	MaxContentSize int64                  `protobuf:"varint,6,opt,name=max_content_size,json=maxContentSize,proto3" json:"max_content_size,omitempty"`                                      // Max content size in bytes (0 = unlimited)
+	RequiredLangs  []string               `protobuf:"bytes,7,rep,name=required_langs,json=requiredLangs,proto3" json:"required_langs,omitempty"`                                            // Languages a key must exist in before others are added
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
+}
+
+func (x *CollectionSchema) Reset() {
+	*x = CollectionSchema{}
+	mi := &file_proto_mddb_proto_msgTypes[50]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CollectionSchema) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CollectionSchema) ProtoMessage() {}
+
+func (x *CollectionSchema) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_mddb_proto_msgTypes[50]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CollectionSchema.ProtoReflect.Descriptor instead.
+func (*CollectionSchema) Descriptor() ([]byte, []int) {
+	return file_proto_mddb_proto_rawDescGZIP(), []int{50}
+}
+
+func (x *CollectionSchema) GetCollection() string {
+	if x != nil {
+		return x.Collection
+	}
+	return ""
+}
+
+func (x *CollectionSchema) GetRequiredMeta() []string {
+	if x != nil {
+		return x.RequiredMeta
+	}
+	return nil
+}
+
+func (x *CollectionSchema) GetOptionalMeta() []string {
+	if x != nil {
+		return x.OptionalMeta
+	}
+	return nil
+}
+
+func (x *CollectionSchema) GetEnums() map[string]*MetaValues {
+	if x != nil {
+		return x.Enums
+	}
+	return nil
+}
+
+func (x *CollectionSchema) GetPatterns() map[string]string {
+	if x != nil {
+		return x.Patterns
+	}
+	return nil
+}
+
+func (x *CollectionSchema) GetMaxContentSize() int64 {
+	if x != nil {
+		return x.MaxContentSize
+	}
+	return 0
+}
+
+func (x *CollectionSchema) GetRequiredLangs() []string {
+	if x != nil {
+		return x.RequiredLangs
+	}
+	return nil
+}
+
+// Schema lookup/delete request
+type SchemaRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Collection    string                 `protobuf:"bytes,1,opt,name=collection,proto3" json:"collection,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *SchemaRequest) Reset() {
+	*x = SchemaRequest{}
+	mi := &file_proto_mddb_proto_msgTypes[51]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *SchemaRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*SchemaRequest) ProtoMessage() {}
+
+func (x *SchemaRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_mddb_proto_msgTypes[51]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use SchemaRequest.ProtoReflect.Descriptor instead.
+func (*SchemaRequest) Descriptor() ([]byte, []int) {
+	return file_proto_mddb_proto_rawDescGZIP(), []int{51}
+}
+
+func (x *SchemaRequest) GetCollection() string {
+	if x != nil {
+		return x.Collection
+	}
+	return ""
+}
+
+// Delete schema response
+type DeleteSchemaResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Status        string                 `protobuf:"bytes,1,opt,name=status,proto3" json:"status,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DeleteSchemaResponse) Reset() {
+	*x = DeleteSchemaResponse{}
+	mi := &file_proto_mddb_proto_msgTypes[52]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DeleteSchemaResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DeleteSchemaResponse) ProtoMessage() {}
+
+func (x *DeleteSchemaResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_mddb_proto_msgTypes[52]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DeleteSchemaResponse.ProtoReflect.Descriptor instead.
+func (*DeleteSchemaResponse) Descriptor() ([]byte, []int) {
+	return file_proto_mddb_proto_rawDescGZIP(), []int{52}
+}
+
+func (x *DeleteSchemaResponse) GetStatus() string {
+	if x != nil {
+		return x.Status
+	}
+	return ""
+}
+
+// Collection registry entry with settings
+type CollectionInfo struct {
+	state          protoimpl.MessageState `protogen:"open.v1"`
+	Name           string                 `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	Description    string                 `protobuf:"bytes,2,opt,name=description,proto3" json:"description,omitempty"`
+	DefaultLang    string                 `protobuf:"bytes,3,opt,name=default_lang,json=defaultLang,proto3" json:"default_lang,omitempty"`          // Used when a write omits lang
+	RevisionPolicy string                 `protobuf:"bytes,4,opt,name=revision_policy,json=revisionPolicy,proto3" json:"revision_policy,omitempty"` // request, always, never
+	Compression    string                 `protobuf:"bytes,5,opt,name=compression,proto3" json:"compression,omitempty"`                             // auto, none, snappy, zstd
+	CreatedAt      int64                  `protobuf:"varint,6,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
+	UpdatedAt      int64                  `protobuf:"varint,7,opt,name=updated_at,json=updatedAt,proto3" json:"updated_at,omitempty"`
+	unknownFields  protoimpl.UnknownFields
+	sizeCache      protoimpl.SizeCache
+}
+
+func (x *CollectionInfo) Reset() {
+	*x = CollectionInfo{}
+	mi := &file_proto_mddb_proto_msgTypes[53]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CollectionInfo) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CollectionInfo) ProtoMessage() {}
+
+func (x *CollectionInfo) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_mddb_proto_msgTypes[53]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CollectionInfo.ProtoReflect.Descriptor instead.
+func (*CollectionInfo) Descriptor() ([]byte, []int) {
+	return file_proto_mddb_proto_rawDescGZIP(), []int{53}
+}
+
+func (x *CollectionInfo) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *CollectionInfo) GetDescription() string {
+	if x != nil {
+		return x.Description
+	}
+	return ""
+}
+
+func (x *CollectionInfo) GetDefaultLang() string {
+	if x != nil {
+		return x.DefaultLang
+	}
+	return ""
+}
+
+func (x *CollectionInfo) GetRevisionPolicy() string {
+	if x != nil {
+		return x.RevisionPolicy
+	}
+	return ""
+}
+
+func (x *CollectionInfo) GetCompression() string {
+	if x != nil {
+		return x.Compression
+	}
+	return ""
+}
+
+func (x *CollectionInfo) GetCreatedAt() int64 {
+	if x != nil {
+		return x.CreatedAt
+	}
+	return 0
+}
+
+func (x *CollectionInfo) GetUpdatedAt() int64 {
+	if x != nil {
+		return x.UpdatedAt
+	}
+	return 0
+}
+
+// Collection settings with live counts
+type CollectionDescription struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Info          *CollectionInfo        `protobuf:"bytes,1,opt,name=info,proto3" json:"info,omitempty"`
+	DocumentCount int32                  `protobuf:"varint,2,opt,name=document_count,json=documentCount,proto3" json:"document_count,omitempty"`
+	RevisionCount int32                  `protobuf:"varint,3,opt,name=revision_count,json=revisionCount,proto3" json:"revision_count,omitempty"`
+	HasSchema     bool                   `protobuf:"varint,4,opt,name=has_schema,json=hasSchema,proto3" json:"has_schema,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CollectionDescription) Reset() {
+	*x = CollectionDescription{}
+	mi := &file_proto_mddb_proto_msgTypes[54]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CollectionDescription) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CollectionDescription) ProtoMessage() {}
+
+func (x *CollectionDescription) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_mddb_proto_msgTypes[54]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CollectionDescription.ProtoReflect.Descriptor instead.
+func (*CollectionDescription) Descriptor() ([]byte, []int) {
+	return file_proto_mddb_proto_rawDescGZIP(), []int{54}
+}
+
+func (x *CollectionDescription) GetInfo() *CollectionInfo {
+	if x != nil {
+		return x.Info
+	}
+	return nil
+}
+
+func (x *CollectionDescription) GetDocumentCount() int32 {
+	if x != nil {
+		return x.DocumentCount
+	}
+	return 0
+}
+
+func (x *CollectionDescription) GetRevisionCount() int32 {
+	if x != nil {
+		return x.RevisionCount
+	}
+	return 0
+}
+
+func (x *CollectionDescription) GetHasSchema() bool {
+	if x != nil {
+		return x.HasSchema
+	}
+	return false
+}
+
+// List collections request
+type ListCollectionsRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListCollectionsRequest) Reset() {
+	*x = ListCollectionsRequest{}
+	mi := &file_proto_mddb_proto_msgTypes[55]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListCollectionsRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListCollectionsRequest) ProtoMessage() {}
+
+func (x *ListCollectionsRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_mddb_proto_msgTypes[55]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListCollectionsRequest.ProtoReflect.Descriptor instead.
+func (*ListCollectionsRequest) Descriptor() ([]byte, []int) {
+	return file_proto_mddb_proto_rawDescGZIP(), []int{55}
+}
+
+// List collections response
+type ListCollectionsResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Collections   []*CollectionInfo      `protobuf:"bytes,1,rep,name=collections,proto3" json:"collections,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ListCollectionsResponse) Reset() {
+	*x = ListCollectionsResponse{}
+	mi := &file_proto_mddb_proto_msgTypes[56]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ListCollectionsResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListCollectionsResponse) ProtoMessage() {}
+
+func (x *ListCollectionsResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_mddb_proto_msgTypes[56]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ListCollectionsResponse.ProtoReflect.Descriptor instead.
+func (*ListCollectionsResponse) Descriptor() ([]byte, []int) {
+	return file_proto_mddb_proto_rawDescGZIP(), []int{56}
+}
+
+func (x *ListCollectionsResponse) GetCollections() []*CollectionInfo {
+	if x != nil {
+		return x.Collections
+	}
+	return nil
+}
+
+// Collection lookup request
+type CollectionNameRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Name          string                 `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CollectionNameRequest) Reset() {
+	*x = CollectionNameRequest{}
+	mi := &file_proto_mddb_proto_msgTypes[57]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CollectionNameRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CollectionNameRequest) ProtoMessage() {}
+
+func (x *CollectionNameRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_mddb_proto_msgTypes[57]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CollectionNameRequest.ProtoReflect.Descriptor instead.
+func (*CollectionNameRequest) Descriptor() ([]byte, []int) {
+	return file_proto_mddb_proto_rawDescGZIP(), []int{57}
+}
+
+func (x *CollectionNameRequest) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+// Rename/clone collection request
+type CopyCollectionRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Name          string                 `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	NewName       string                 `protobuf:"bytes,2,opt,name=new_name,json=newName,proto3" json:"new_name,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CopyCollectionRequest) Reset() {
+	*x = CopyCollectionRequest{}
+	mi := &file_proto_mddb_proto_msgTypes[58]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CopyCollectionRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CopyCollectionRequest) ProtoMessage() {}
+
+func (x *CopyCollectionRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_mddb_proto_msgTypes[58]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CopyCollectionRequest.ProtoReflect.Descriptor instead.
+func (*CopyCollectionRequest) Descriptor() ([]byte, []int) {
+	return file_proto_mddb_proto_rawDescGZIP(), []int{58}
+}
+
+func (x *CopyCollectionRequest) GetName() string {
+	if x != nil {
+		return x.Name
+	}
+	return ""
+}
+
+func (x *CopyCollectionRequest) GetNewName() string {
+	if x != nil {
+		return x.NewName
+	}
+	return ""
+}
+
+// Rename/clone collection response
+type CopyCollectionResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Status        string                 `protobuf:"bytes,1,opt,name=status,proto3" json:"status,omitempty"`
+	Collection    string                 `protobuf:"bytes,2,opt,name=collection,proto3" json:"collection,omitempty"`
+	Documents     int32                  `protobuf:"varint,3,opt,name=documents,proto3" json:"documents,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *CopyCollectionResponse) Reset() {
+	*x = CopyCollectionResponse{}
+	mi := &file_proto_mddb_proto_msgTypes[59]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *CopyCollectionResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*CopyCollectionResponse) ProtoMessage() {}
+
+func (x *CopyCollectionResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_mddb_proto_msgTypes[59]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use CopyCollectionResponse.ProtoReflect.Descriptor instead.
+func (*CopyCollectionResponse) Descriptor() ([]byte, []int) {
+	return file_proto_mddb_proto_rawDescGZIP(), []int{59}
+}
+
+func (x *CopyCollectionResponse) GetStatus() string {
+	if x != nil {
+		return x.Status
+	}
+	return ""
+}
+
+func (x *CopyCollectionResponse) GetCollection() string {
+	if x != nil {
+		return x.Collection
+	}
+	return ""
+}
+
+func (x *CopyCollectionResponse) GetDocuments() int32 {
+	if x != nil {
+		return x.Documents
+	}
+	return 0
+}
+
+// Delete collection request
+type DeleteCollectionRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Collection    string                 `protobuf:"bytes,1,opt,name=collection,proto3" json:"collection,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DeleteCollectionRequest) Reset() {
+	*x = DeleteCollectionRequest{}
+	mi := &file_proto_mddb_proto_msgTypes[60]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DeleteCollectionRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DeleteCollectionRequest) ProtoMessage() {}
+
+func (x *DeleteCollectionRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_mddb_proto_msgTypes[60]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DeleteCollectionRequest.ProtoReflect.Descriptor instead.
+func (*DeleteCollectionRequest) Descriptor() ([]byte, []int) {
+	return file_proto_mddb_proto_rawDescGZIP(), []int{60}
+}
+
+func (x *DeleteCollectionRequest) GetCollection() string {
+	if x != nil {
+		return x.Collection
+	}
+	return ""
+}
+
+// Delete collection response
+type DeleteCollectionResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Status        string                 `protobuf:"bytes,1,opt,name=status,proto3" json:"status,omitempty"`
+	Collection    string                 `protobuf:"bytes,2,opt,name=collection,proto3" json:"collection,omitempty"`
+	DeletedCount  int32                  `protobuf:"varint,3,opt,name=deleted_count,json=deletedCount,proto3" json:"deleted_count,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *DeleteCollectionResponse) Reset() {
+	*x = DeleteCollectionResponse{}
+	mi := &file_proto_mddb_proto_msgTypes[61]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *DeleteCollectionResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*DeleteCollectionResponse) ProtoMessage() {}
+
+func (x *DeleteCollectionResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_mddb_proto_msgTypes[61]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use DeleteCollectionResponse.ProtoReflect.Descriptor instead.
+func (*DeleteCollectionResponse) Descriptor() ([]byte, []int) {
+	return file_proto_mddb_proto_rawDescGZIP(), []int{61}
+}
+
+func (x *DeleteCollectionResponse) GetStatus() string {
+	if x != nil {
+		return x.Status
+	}
+	return ""
+}
+
+func (x *DeleteCollectionResponse) GetCollection() string {
+	if x != nil {
+		return x.Collection
+	}
+	return ""
+}
+
+func (x *DeleteCollectionResponse) GetDeletedCount() int32 {
+	if x != nil {
+		return x.DeletedCount
+	}
+	return 0
+}
+
+// Import options (sent in the first ImportChunk)
+type ImportOptions struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Collection    string                 `protobuf:"bytes,1,opt,name=collection,proto3" json:"collection,omitempty"`
+	Format        string                 `protobuf:"bytes,2,opt,name=format,proto3" json:"format,omitempty"`                           // ndjson, zip, tar
+	OnConflict    string                 `protobuf:"bytes,3,opt,name=on_conflict,json=onConflict,proto3" json:"on_conflict,omitempty"` // upsert (default), skip, fail
+	DryRun        bool                   `protobuf:"varint,4,opt,name=dry_run,json=dryRun,proto3" json:"dry_run,omitempty"`
+	Lang          string                 `protobuf:"bytes,5,opt,name=lang,proto3" json:"lang,omitempty"`                             // language for files named {key}.md
+	ChunkSize     int32                  `protobuf:"varint,6,opt,name=chunk_size,json=chunkSize,proto3" json:"chunk_size,omitempty"` // documents per transaction
+	SaveRevision  bool                   `protobuf:"varint,7,opt,name=save_revision,json=saveRevision,proto3" json:"save_revision,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *ImportOptions) Reset() {
+	*x = ImportOptions{}
+	mi := &file_proto_mddb_proto_msgTypes[62]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *ImportOptions) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ImportOptions) ProtoMessage() {}
+
+func (x *ImportOptions) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_mddb_proto_msgTypes[62]
+	if x != nil {
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
+	}
+	return mi.MessageOf(x)
+}
+
+// Deprecated: Use ImportOptions.ProtoReflect.Descriptor instead.
+func (*ImportOptions) Descriptor() ([]byte, []int) {
+	return file_proto_mddb_proto_rawDescGZIP(), []int{62}
+}
+
+func (x *ImportOptions) GetCollection() string {
+	if x != nil {
+		return x.Collection
+	}
+	return ""
+}
+
+func (x *ImportOptions) GetFormat() string {
+	if x != nil {
+		return x.Format
+	}
+	return ""
+}
+
+func (x *ImportOptions) GetOnConflict() string {
+	if x != nil {
+		return x.OnConflict
+	}
+	return ""
+}
+
+func (x *ImportOptions) GetDryRun() bool {
+	if x != nil {
+		return x.DryRun
+	}
+	return false
+}
+
+func (x *ImportOptions) GetLang() string {
+	if x != nil {
+		return x.Lang
+	}
+	return ""
+}
+
+func (x *ImportOptions) GetChunkSize() int32 {
+	if x != nil {
+		return x.ChunkSize
+	}
+	return 0
+}
+
+func (x *ImportOptions) GetSaveRevision() bool {
+	if x != nil {
+		return x.SaveRevision
 	}
-	return ""
+	return false
 }
 
-// Restore request
-type RestoreRequest struct {
+// Import upload chunk (client streaming)
+type ImportChunk struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	From          string                 `protobuf:"bytes,1,opt,name=from,proto3" json:"from,omitempty"` // Backup filename
+	Options       *ImportOptions         `protobuf:"bytes,1,opt,name=options,proto3" json:"options,omitempty"` // first chunk only
+	Data          []byte                 `protobuf:"bytes,2,opt,name=data,proto3" json:"data,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *RestoreRequest) Reset() {
-	*x = RestoreRequest{}
-	mi := &file_proto_mddb_proto_msgTypes[13]
+func (x *ImportChunk) Reset() {
+	*x = ImportChunk{}
+	mi := &file_proto_mddb_proto_msgTypes[63]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *RestoreRequest) String() string {
+func (x *ImportChunk) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*RestoreRequest) ProtoMessage() {}
+func (*ImportChunk) ProtoMessage() {}
 
-func (x *RestoreRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_mddb_proto_msgTypes[13]
+func (x *ImportChunk) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_mddb_proto_msgTypes[63]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -887,41 +4194,55 @@ func (x *RestoreRequest) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use RestoreRequest.ProtoReflect.Descriptor instead.
-func (*RestoreRequest) Descriptor() ([]byte, []int) {
-	return file_proto_mddb_proto_rawDescGZIP(), []int{13}
+// Deprecated: Use ImportChunk.ProtoReflect.Descriptor instead.
+func (*ImportChunk) Descriptor() ([]byte, []int) {
+	return file_proto_mddb_proto_rawDescGZIP(), []int{63}
 }
 
-func (x *RestoreRequest) GetFrom() string {
+func (x *ImportChunk) GetOptions() *ImportOptions {
 	if x != nil {
-		return x.From
+		return x.Options
 	}
-	return ""
+	return nil
 }
 
-// Restore response
-type RestoreResponse struct {
+func (x *ImportChunk) GetData() []byte {
+	if x != nil {
+		return x.Data
+	}
+	return nil
+}
+
+// Import response
+type ImportResponse struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	Restored      string                 `protobuf:"bytes,1,opt,name=restored,proto3" json:"restored,omitempty"`
+	Total         int32                  `protobuf:"varint,1,opt,name=total,proto3" json:"total,omitempty"`
+	Added         int32                  `protobuf:"varint,2,opt,name=added,proto3" json:"added,omitempty"`
+	Updated       int32                  `protobuf:"varint,3,opt,name=updated,proto3" json:"updated,omitempty"`
+	Skipped       int32                  `protobuf:"varint,4,opt,name=skipped,proto3" json:"skipped,omitempty"`
+	Failed        int32                  `protobuf:"varint,5,opt,name=failed,proto3" json:"failed,omitempty"`
+	Chunks        int32                  `protobuf:"varint,6,opt,name=chunks,proto3" json:"chunks,omitempty"`
+	Errors        []string               `protobuf:"bytes,7,rep,name=errors,proto3" json:"errors,omitempty"`
+	DryRun        bool                   `protobuf:"varint,8,opt,name=dry_run,json=dryRun,proto3" json:"dry_run,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *RestoreResponse) Reset() {
-	*x = RestoreResponse{}
-	mi := &file_proto_mddb_proto_msgTypes[14]
+func (x *ImportResponse) Reset() {
+	*x = ImportResponse{}
+	mi := &file_proto_mddb_proto_msgTypes[64]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *RestoreResponse) String() string {
+func (x *ImportResponse) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*RestoreResponse) ProtoMessage() {}
+func (*ImportResponse) ProtoMessage() {}
 
-func (x *RestoreResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_mddb_proto_msgTypes[14]
+func (x *ImportResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_mddb_proto_msgTypes[64]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -932,102 +4253,92 @@ func (x *RestoreResponse) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use RestoreResponse.ProtoReflect.Descriptor instead.
-func (*RestoreResponse) Descriptor() ([]byte, []int) {
-	return file_proto_mddb_proto_rawDescGZIP(), []int{14}
+// Deprecated: Use ImportResponse.ProtoReflect.Descriptor instead.
+func (*ImportResponse) Descriptor() ([]byte, []int) {
+	return file_proto_mddb_proto_rawDescGZIP(), []int{64}
 }
 
-func (x *RestoreResponse) GetRestored() string {
+func (x *ImportResponse) GetTotal() int32 {
 	if x != nil {
-		return x.Restored
+		return x.Total
 	}
-	return ""
-}
-
-// Truncate request
-type TruncateRequest struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	Collection    string                 `protobuf:"bytes,1,opt,name=collection,proto3" json:"collection,omitempty"`
-	KeepRevs      int32                  `protobuf:"varint,2,opt,name=keep_revs,json=keepRevs,proto3" json:"keep_revs,omitempty"`
-	DropCache     bool                   `protobuf:"varint,3,opt,name=drop_cache,json=dropCache,proto3" json:"drop_cache,omitempty"`
-	unknownFields protoimpl.UnknownFields
-	sizeCache     protoimpl.SizeCache
+	return 0
 }
 
-func (x *TruncateRequest) Reset() {
-	*x = TruncateRequest{}
-	mi := &file_proto_mddb_proto_msgTypes[15]
-	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
-	ms.StoreMessageInfo(mi)
+func (x *ImportResponse) GetAdded() int32 {
+	if x != nil {
+		return x.Added
+	}
+	return 0
 }
 
-func (x *TruncateRequest) String() string {
-	return protoimpl.X.MessageStringOf(x)
+func (x *ImportResponse) GetUpdated() int32 {
+	if x != nil {
+		return x.Updated
+	}
+	return 0
 }
 
-func (*TruncateRequest) ProtoMessage() {}
-
-func (x *TruncateRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_mddb_proto_msgTypes[15]
+func (x *ImportResponse) GetSkipped() int32 {
 	if x != nil {
-		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
-		if ms.LoadMessageInfo() == nil {
-			ms.StoreMessageInfo(mi)
-		}
-		return ms
+		return x.Skipped
 	}
-	return mi.MessageOf(x)
+	return 0
 }
 
-// Deprecated: Use TruncateRequest.ProtoReflect.Descriptor instead.
-func (*TruncateRequest) Descriptor() ([]byte, []int) {
-	return file_proto_mddb_proto_rawDescGZIP(), []int{15}
+func (x *ImportResponse) GetFailed() int32 {
+	if x != nil {
+		return x.Failed
+	}
+	return 0
 }
 
-func (x *TruncateRequest) GetCollection() string {
+func (x *ImportResponse) GetChunks() int32 {
 	if x != nil {
-		return x.Collection
+		return x.Chunks
 	}
-	return ""
+	return 0
 }
 
-func (x *TruncateRequest) GetKeepRevs() int32 {
+func (x *ImportResponse) GetErrors() []string {
 	if x != nil {
-		return x.KeepRevs
+		return x.Errors
 	}
-	return 0
+	return nil
 }
 
-func (x *TruncateRequest) GetDropCache() bool {
+func (x *ImportResponse) GetDryRun() bool {
 	if x != nil {
-		return x.DropCache
+		return x.DryRun
 	}
 	return false
 }
 
-// Truncate response
-type TruncateResponse struct {
+// Delete document request
+type DeleteRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	Status        string                 `protobuf:"bytes,1,opt,name=status,proto3" json:"status,omitempty"`
+	Collection    string                 `protobuf:"bytes,1,opt,name=collection,proto3" json:"collection,omitempty"`
+	Key           string                 `protobuf:"bytes,2,opt,name=key,proto3" json:"key,omitempty"`
+	Lang          string                 `protobuf:"bytes,3,opt,name=lang,proto3" json:"lang,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *TruncateResponse) Reset() {
-	*x = TruncateResponse{}
-	mi := &file_proto_mddb_proto_msgTypes[16]
+func (x *DeleteRequest) Reset() {
+	*x = DeleteRequest{}
+	mi := &file_proto_mddb_proto_msgTypes[65]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *TruncateResponse) String() string {
+func (x *DeleteRequest) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*TruncateResponse) ProtoMessage() {}
+func (*DeleteRequest) ProtoMessage() {}
 
-func (x *TruncateResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_mddb_proto_msgTypes[16]
+func (x *DeleteRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_mddb_proto_msgTypes[65]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1038,84 +4349,58 @@ func (x *TruncateResponse) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use TruncateResponse.ProtoReflect.Descriptor instead.
-func (*TruncateResponse) Descriptor() ([]byte, []int) {
-	return file_proto_mddb_proto_rawDescGZIP(), []int{16}
+// Deprecated: Use DeleteRequest.ProtoReflect.Descriptor instead.
+func (*DeleteRequest) Descriptor() ([]byte, []int) {
+	return file_proto_mddb_proto_rawDescGZIP(), []int{65}
 }
 
-func (x *TruncateResponse) GetStatus() string {
+func (x *DeleteRequest) GetCollection() string {
 	if x != nil {
-		return x.Status
+		return x.Collection
 	}
 	return ""
 }
 
-// Stats request
-type StatsRequest struct {
-	state         protoimpl.MessageState `protogen:"open.v1"`
-	unknownFields protoimpl.UnknownFields
-	sizeCache     protoimpl.SizeCache
-}
-
-func (x *StatsRequest) Reset() {
-	*x = StatsRequest{}
-	mi := &file_proto_mddb_proto_msgTypes[17]
-	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
-	ms.StoreMessageInfo(mi)
-}
-
-func (x *StatsRequest) String() string {
-	return protoimpl.X.MessageStringOf(x)
-}
-
-func (*StatsRequest) ProtoMessage() {}
-
-func (x *StatsRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_mddb_proto_msgTypes[17]
+func (x *DeleteRequest) GetKey() string {
 	if x != nil {
-		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
-		if ms.LoadMessageInfo() == nil {
-			ms.StoreMessageInfo(mi)
-		}
-		return ms
+		return x.Key
 	}
-	return mi.MessageOf(x)
+	return ""
 }
 
-// Deprecated: Use StatsRequest.ProtoReflect.Descriptor instead.
-func (*StatsRequest) Descriptor() ([]byte, []int) {
-	return file_proto_mddb_proto_rawDescGZIP(), []int{17}
+func (x *DeleteRequest) GetLang() string {
+	if x != nil {
+		return x.Lang
+	}
+	return ""
 }
 
-// Stats response
-type StatsResponse struct {
-	state            protoimpl.MessageState `protogen:"open.v1"`
-	DatabasePath     string                 `protobuf:"bytes,1,opt,name=database_path,json=databasePath,proto3" json:"database_path,omitempty"`
-	DatabaseSize     int64                  `protobuf:"varint,2,opt,name=database_size,json=databaseSize,proto3" json:"database_size,omitempty"`
-	Mode             string                 `protobuf:"bytes,3,opt,name=mode,proto3" json:"mode,omitempty"`
-	Collections      []*CollectionStats     `protobuf:"bytes,4,rep,name=collections,proto3" json:"collections,omitempty"`
-	TotalDocuments   int32                  `protobuf:"varint,5,opt,name=total_documents,json=totalDocuments,proto3" json:"total_documents,omitempty"`
-	TotalRevisions   int32                  `protobuf:"varint,6,opt,name=total_revisions,json=totalRevisions,proto3" json:"total_revisions,omitempty"`
-	TotalMetaIndices int32                  `protobuf:"varint,7,opt,name=total_meta_indices,json=totalMetaIndices,proto3" json:"total_meta_indices,omitempty"`
-	unknownFields    protoimpl.UnknownFields
-	sizeCache        protoimpl.SizeCache
+// Delete document response
+type DeleteResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Status        string                 `protobuf:"bytes,1,opt,name=status,proto3" json:"status,omitempty"`
+	Collection    string                 `protobuf:"bytes,2,opt,name=collection,proto3" json:"collection,omitempty"`
+	Key           string                 `protobuf:"bytes,3,opt,name=key,proto3" json:"key,omitempty"`
+	Lang          string                 `protobuf:"bytes,4,opt,name=lang,proto3" json:"lang,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
 }
 
-func (x *StatsResponse) Reset() {
-	*x = StatsResponse{}
-	mi := &file_proto_mddb_proto_msgTypes[18]
+func (x *DeleteResponse) Reset() {
+	*x = DeleteResponse{}
+	mi := &file_proto_mddb_proto_msgTypes[66]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *StatsResponse) String() string {
+func (x *DeleteResponse) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*StatsResponse) ProtoMessage() {}
+func (*DeleteResponse) ProtoMessage() {}
 
-func (x *StatsResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_mddb_proto_msgTypes[18]
+func (x *DeleteResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_mddb_proto_msgTypes[66]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1126,86 +4411,101 @@ func (x *StatsResponse) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use StatsResponse.ProtoReflect.Descriptor instead.
-func (*StatsResponse) Descriptor() ([]byte, []int) {
-	return file_proto_mddb_proto_rawDescGZIP(), []int{18}
+// Deprecated: Use DeleteResponse.ProtoReflect.Descriptor instead.
+func (*DeleteResponse) Descriptor() ([]byte, []int) {
+	return file_proto_mddb_proto_rawDescGZIP(), []int{66}
 }
 
-func (x *StatsResponse) GetDatabasePath() string {
+func (x *DeleteResponse) GetStatus() string {
 	if x != nil {
-		return x.DatabasePath
+		return x.Status
 	}
 	return ""
 }
 
-func (x *StatsResponse) GetDatabaseSize() int64 {
+func (x *DeleteResponse) GetCollection() string {
 	if x != nil {
-		return x.DatabaseSize
+		return x.Collection
 	}
-	return 0
+	return ""
 }
 
-func (x *StatsResponse) GetMode() string {
+func (x *DeleteResponse) GetKey() string {
 	if x != nil {
-		return x.Mode
+		return x.Key
 	}
 	return ""
 }
 
-func (x *StatsResponse) GetCollections() []*CollectionStats {
+func (x *DeleteResponse) GetLang() string {
 	if x != nil {
-		return x.Collections
+		return x.Lang
 	}
-	return nil
+	return ""
 }
 
-func (x *StatsResponse) GetTotalDocuments() int32 {
-	if x != nil {
-		return x.TotalDocuments
-	}
-	return 0
+// Health request
+type HealthRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
 }
 
-func (x *StatsResponse) GetTotalRevisions() int32 {
-	if x != nil {
-		return x.TotalRevisions
-	}
-	return 0
+func (x *HealthRequest) Reset() {
+	*x = HealthRequest{}
+	mi := &file_proto_mddb_proto_msgTypes[67]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
 }
 
-func (x *StatsResponse) GetTotalMetaIndices() int32 {
+func (x *HealthRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*HealthRequest) ProtoMessage() {}
+
+func (x *HealthRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_mddb_proto_msgTypes[67]
 	if x != nil {
-		return x.TotalMetaIndices
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
 	}
-	return 0
+	return mi.MessageOf(x)
 }
 
-// Collection statistics
-type CollectionStats struct {
-	state          protoimpl.MessageState `protogen:"open.v1"`
-	Name           string                 `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
-	DocumentCount  int32                  `protobuf:"varint,2,opt,name=document_count,json=documentCount,proto3" json:"document_count,omitempty"`
-	RevisionCount  int32                  `protobuf:"varint,3,opt,name=revision_count,json=revisionCount,proto3" json:"revision_count,omitempty"`
-	MetaIndexCount int32                  `protobuf:"varint,4,opt,name=meta_index_count,json=metaIndexCount,proto3" json:"meta_index_count,omitempty"`
-	unknownFields  protoimpl.UnknownFields
-	sizeCache      protoimpl.SizeCache
+// Deprecated: Use HealthRequest.ProtoReflect.Descriptor instead.
+func (*HealthRequest) Descriptor() ([]byte, []int) {
+	return file_proto_mddb_proto_rawDescGZIP(), []int{67}
 }
 
-func (x *CollectionStats) Reset() {
-	*x = CollectionStats{}
-	mi := &file_proto_mddb_proto_msgTypes[19]
+// Health response
+type HealthResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Status        string                 `protobuf:"bytes,1,opt,name=status,proto3" json:"status,omitempty"` // healthy, unhealthy
+	Mode          string                 `protobuf:"bytes,2,opt,name=mode,proto3" json:"mode,omitempty"`
+	Error         string                 `protobuf:"bytes,3,opt,name=error,proto3" json:"error,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *HealthResponse) Reset() {
+	*x = HealthResponse{}
+	mi := &file_proto_mddb_proto_msgTypes[68]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *CollectionStats) String() string {
+func (x *HealthResponse) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*CollectionStats) ProtoMessage() {}
+func (*HealthResponse) ProtoMessage() {}
 
-func (x *CollectionStats) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_mddb_proto_msgTypes[19]
+func (x *HealthResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_mddb_proto_msgTypes[68]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1216,63 +4516,56 @@ func (x *CollectionStats) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use CollectionStats.ProtoReflect.Descriptor instead.
-func (*CollectionStats) Descriptor() ([]byte, []int) {
-	return file_proto_mddb_proto_rawDescGZIP(), []int{19}
+// Deprecated: Use HealthResponse.ProtoReflect.Descriptor instead.
+func (*HealthResponse) Descriptor() ([]byte, []int) {
+	return file_proto_mddb_proto_rawDescGZIP(), []int{68}
 }
 
-func (x *CollectionStats) GetName() string {
+func (x *HealthResponse) GetStatus() string {
 	if x != nil {
-		return x.Name
+		return x.Status
 	}
 	return ""
 }
 
-func (x *CollectionStats) GetDocumentCount() int32 {
-	if x != nil {
-		return x.DocumentCount
-	}
-	return 0
-}
-
-func (x *CollectionStats) GetRevisionCount() int32 {
+func (x *HealthResponse) GetMode() string {
 	if x != nil {
-		return x.RevisionCount
+		return x.Mode
 	}
-	return 0
+	return ""
 }
 
-func (x *CollectionStats) GetMetaIndexCount() int32 {
+func (x *HealthResponse) GetError() string {
 	if x != nil {
-		return x.MetaIndexCount
+		return x.Error
 	}
-	return 0
+	return ""
 }
 
-// Update batch request
-type UpdateBatchRequest struct {
+// Create API key request
+type CreateAPIKeyRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	Collection    string                 `protobuf:"bytes,1,opt,name=collection,proto3" json:"collection,omitempty"`
-	Documents     []*UpdateDocument      `protobuf:"bytes,2,rep,name=documents,proto3" json:"documents,omitempty"`
+	Name          string                 `protobuf:"bytes,1,opt,name=name,proto3" json:"name,omitempty"`
+	Roles         []string               `protobuf:"bytes,2,rep,name=roles,proto3" json:"roles,omitempty"` // <read|write|admin>:<collection pattern>
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *UpdateBatchRequest) Reset() {
-	*x = UpdateBatchRequest{}
-	mi := &file_proto_mddb_proto_msgTypes[20]
+func (x *CreateAPIKeyRequest) Reset() {
+	*x = CreateAPIKeyRequest{}
+	mi := &file_proto_mddb_proto_msgTypes[69]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *UpdateBatchRequest) String() string {
+func (x *CreateAPIKeyRequest) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*UpdateBatchRequest) ProtoMessage() {}
+func (*CreateAPIKeyRequest) ProtoMessage() {}
 
-func (x *UpdateBatchRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_mddb_proto_msgTypes[20]
+func (x *CreateAPIKeyRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_mddb_proto_msgTypes[69]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1283,52 +4576,52 @@ func (x *UpdateBatchRequest) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use UpdateBatchRequest.ProtoReflect.Descriptor instead.
-func (*UpdateBatchRequest) Descriptor() ([]byte, []int) {
-	return file_proto_mddb_proto_rawDescGZIP(), []int{20}
+// Deprecated: Use CreateAPIKeyRequest.ProtoReflect.Descriptor instead.
+func (*CreateAPIKeyRequest) Descriptor() ([]byte, []int) {
+	return file_proto_mddb_proto_rawDescGZIP(), []int{69}
 }
 
-func (x *UpdateBatchRequest) GetCollection() string {
+func (x *CreateAPIKeyRequest) GetName() string {
 	if x != nil {
-		return x.Collection
+		return x.Name
 	}
 	return ""
 }
 
-func (x *UpdateBatchRequest) GetDocuments() []*UpdateDocument {
+func (x *CreateAPIKeyRequest) GetRoles() []string {
 	if x != nil {
-		return x.Documents
+		return x.Roles
 	}
 	return nil
 }
 
-// Document for update operations
-type UpdateDocument struct {
+// API key; key (the secret) is only set in the CreateAPIKey response
+type APIKey struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	Key           string                 `protobuf:"bytes,1,opt,name=key,proto3" json:"key,omitempty"`
-	Lang          string                 `protobuf:"bytes,2,opt,name=lang,proto3" json:"lang,omitempty"`
-	Meta          map[string]*MetaValues `protobuf:"bytes,3,rep,name=meta,proto3" json:"meta,omitempty" protobuf_key:"bytes,1,opt,name=key" protobuf_val:"bytes,2,opt,name=value"`
-	ContentMd     string                 `protobuf:"bytes,4,opt,name=content_md,json=contentMd,proto3" json:"content_md,omitempty"`
-	SaveRevision  bool                   `protobuf:"varint,5,opt,name=save_revision,json=saveRevision,proto3" json:"save_revision,omitempty"`
+	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
+	Name          string                 `protobuf:"bytes,2,opt,name=name,proto3" json:"name,omitempty"`
+	Roles         []string               `protobuf:"bytes,3,rep,name=roles,proto3" json:"roles,omitempty"`
+	CreatedAt     int64                  `protobuf:"varint,4,opt,name=created_at,json=createdAt,proto3" json:"created_at,omitempty"`
+	Key           string                 `protobuf:"bytes,5,opt,name=key,proto3" json:"key,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *UpdateDocument) Reset() {
-	*x = UpdateDocument{}
-	mi := &file_proto_mddb_proto_msgTypes[21]
+func (x *APIKey) Reset() {
+	*x = APIKey{}
+	mi := &file_proto_mddb_proto_msgTypes[70]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *UpdateDocument) String() string {
+func (x *APIKey) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*UpdateDocument) ProtoMessage() {}
+func (*APIKey) ProtoMessage() {}
 
-func (x *UpdateDocument) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_mddb_proto_msgTypes[21]
+func (x *APIKey) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_mddb_proto_msgTypes[70]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1339,72 +4632,68 @@ func (x *UpdateDocument) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use UpdateDocument.ProtoReflect.Descriptor instead.
-func (*UpdateDocument) Descriptor() ([]byte, []int) {
-	return file_proto_mddb_proto_rawDescGZIP(), []int{21}
+// Deprecated: Use APIKey.ProtoReflect.Descriptor instead.
+func (*APIKey) Descriptor() ([]byte, []int) {
+	return file_proto_mddb_proto_rawDescGZIP(), []int{70}
 }
 
-func (x *UpdateDocument) GetKey() string {
+func (x *APIKey) GetId() string {
 	if x != nil {
-		return x.Key
+		return x.Id
 	}
 	return ""
 }
 
-func (x *UpdateDocument) GetLang() string {
+func (x *APIKey) GetName() string {
 	if x != nil {
-		return x.Lang
+		return x.Name
 	}
 	return ""
 }
 
-func (x *UpdateDocument) GetMeta() map[string]*MetaValues {
+func (x *APIKey) GetRoles() []string {
 	if x != nil {
-		return x.Meta
+		return x.Roles
 	}
 	return nil
 }
 
-func (x *UpdateDocument) GetContentMd() string {
+func (x *APIKey) GetCreatedAt() int64 {
 	if x != nil {
-		return x.ContentMd
+		return x.CreatedAt
 	}
-	return ""
+	return 0
 }
 
-func (x *UpdateDocument) GetSaveRevision() bool {
+func (x *APIKey) GetKey() string {
 	if x != nil {
-		return x.SaveRevision
+		return x.Key
 	}
-	return false
+	return ""
 }
 
-// Update batch response
-type UpdateBatchResponse struct {
+// List API keys request
+type ListAPIKeysRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	Updated       int32                  `protobuf:"varint,1,opt,name=updated,proto3" json:"updated,omitempty"`
-	Failed        int32                  `protobuf:"varint,2,opt,name=failed,proto3" json:"failed,omitempty"`
-	Errors        []string               `protobuf:"bytes,3,rep,name=errors,proto3" json:"errors,omitempty"`
-	NotFound      int32                  `protobuf:"varint,4,opt,name=not_found,json=notFound,proto3" json:"not_found,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *UpdateBatchResponse) Reset() {
-	*x = UpdateBatchResponse{}
-	mi := &file_proto_mddb_proto_msgTypes[22]
+func (x *ListAPIKeysRequest) Reset() {
+	*x = ListAPIKeysRequest{}
+	mi := &file_proto_mddb_proto_msgTypes[71]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *UpdateBatchResponse) String() string {
+func (x *ListAPIKeysRequest) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*UpdateBatchResponse) ProtoMessage() {}
+func (*ListAPIKeysRequest) ProtoMessage() {}
 
-func (x *UpdateBatchResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_mddb_proto_msgTypes[22]
+func (x *ListAPIKeysRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_mddb_proto_msgTypes[71]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1415,63 +4704,79 @@ func (x *UpdateBatchResponse) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use UpdateBatchResponse.ProtoReflect.Descriptor instead.
-func (*UpdateBatchResponse) Descriptor() ([]byte, []int) {
-	return file_proto_mddb_proto_rawDescGZIP(), []int{22}
+// Deprecated: Use ListAPIKeysRequest.ProtoReflect.Descriptor instead.
+func (*ListAPIKeysRequest) Descriptor() ([]byte, []int) {
+	return file_proto_mddb_proto_rawDescGZIP(), []int{71}
 }
 
-func (x *UpdateBatchResponse) GetUpdated() int32 {
-	if x != nil {
-		return x.Updated
-	}
-	return 0
+// List API keys response
+type ListAPIKeysResponse struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	Keys          []*APIKey              `protobuf:"bytes,1,rep,name=keys,proto3" json:"keys,omitempty"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
 }
 
-func (x *UpdateBatchResponse) GetFailed() int32 {
-	if x != nil {
-		return x.Failed
-	}
-	return 0
+func (x *ListAPIKeysResponse) Reset() {
+	*x = ListAPIKeysResponse{}
+	mi := &file_proto_mddb_proto_msgTypes[72]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
 }
 
-func (x *UpdateBatchResponse) GetErrors() []string {
+func (x *ListAPIKeysResponse) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*ListAPIKeysResponse) ProtoMessage() {}
+
+func (x *ListAPIKeysResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_mddb_proto_msgTypes[72]
 	if x != nil {
-		return x.Errors
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
 	}
-	return nil
+	return mi.MessageOf(x)
 }
 
-func (x *UpdateBatchResponse) GetNotFound() int32 {
+// Deprecated: Use ListAPIKeysResponse.ProtoReflect.Descriptor instead.
+func (*ListAPIKeysResponse) Descriptor() ([]byte, []int) {
+	return file_proto_mddb_proto_rawDescGZIP(), []int{72}
+}
+
+func (x *ListAPIKeysResponse) GetKeys() []*APIKey {
 	if x != nil {
-		return x.NotFound
+		return x.Keys
 	}
-	return 0
+	return nil
 }
 
-// Delete batch request
-type DeleteBatchRequest struct {
+// Delete API key request
+type DeleteAPIKeyRequest struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	Collection    string                 `protobuf:"bytes,1,opt,name=collection,proto3" json:"collection,omitempty"`
-	Documents     []*DeleteDocument      `protobuf:"bytes,2,rep,name=documents,proto3" json:"documents,omitempty"`
+	Id            string                 `protobuf:"bytes,1,opt,name=id,proto3" json:"id,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *DeleteBatchRequest) Reset() {
-	*x = DeleteBatchRequest{}
-	mi := &file_proto_mddb_proto_msgTypes[23]
+func (x *DeleteAPIKeyRequest) Reset() {
+	*x = DeleteAPIKeyRequest{}
+	mi := &file_proto_mddb_proto_msgTypes[73]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *DeleteBatchRequest) String() string {
+func (x *DeleteAPIKeyRequest) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*DeleteBatchRequest) ProtoMessage() {}
+func (*DeleteAPIKeyRequest) ProtoMessage() {}
 
-func (x *DeleteBatchRequest) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_mddb_proto_msgTypes[23]
+func (x *DeleteAPIKeyRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_mddb_proto_msgTypes[73]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1482,49 +4787,41 @@ func (x *DeleteBatchRequest) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use DeleteBatchRequest.ProtoReflect.Descriptor instead.
-func (*DeleteBatchRequest) Descriptor() ([]byte, []int) {
-	return file_proto_mddb_proto_rawDescGZIP(), []int{23}
+// Deprecated: Use DeleteAPIKeyRequest.ProtoReflect.Descriptor instead.
+func (*DeleteAPIKeyRequest) Descriptor() ([]byte, []int) {
+	return file_proto_mddb_proto_rawDescGZIP(), []int{73}
 }
 
-func (x *DeleteBatchRequest) GetCollection() string {
+func (x *DeleteAPIKeyRequest) GetId() string {
 	if x != nil {
-		return x.Collection
+		return x.Id
 	}
 	return ""
 }
 
-func (x *DeleteBatchRequest) GetDocuments() []*DeleteDocument {
-	if x != nil {
-		return x.Documents
-	}
-	return nil
-}
-
-// Document for delete operations
-type DeleteDocument struct {
+// Delete API key response
+type DeleteAPIKeyResponse struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	Key           string                 `protobuf:"bytes,1,opt,name=key,proto3" json:"key,omitempty"`
-	Lang          string                 `protobuf:"bytes,2,opt,name=lang,proto3" json:"lang,omitempty"`
+	Status        string                 `protobuf:"bytes,1,opt,name=status,proto3" json:"status,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *DeleteDocument) Reset() {
-	*x = DeleteDocument{}
-	mi := &file_proto_mddb_proto_msgTypes[24]
+func (x *DeleteAPIKeyResponse) Reset() {
+	*x = DeleteAPIKeyResponse{}
+	mi := &file_proto_mddb_proto_msgTypes[74]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *DeleteDocument) String() string {
+func (x *DeleteAPIKeyResponse) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*DeleteDocument) ProtoMessage() {}
+func (*DeleteAPIKeyResponse) ProtoMessage() {}
 
-func (x *DeleteDocument) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_mddb_proto_msgTypes[24]
+func (x *DeleteAPIKeyResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_mddb_proto_msgTypes[74]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1535,51 +4832,80 @@ func (x *DeleteDocument) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use DeleteDocument.ProtoReflect.Descriptor instead.
-func (*DeleteDocument) Descriptor() ([]byte, []int) {
-	return file_proto_mddb_proto_rawDescGZIP(), []int{24}
+// Deprecated: Use DeleteAPIKeyResponse.ProtoReflect.Descriptor instead.
+func (*DeleteAPIKeyResponse) Descriptor() ([]byte, []int) {
+	return file_proto_mddb_proto_rawDescGZIP(), []int{74}
 }
 
-func (x *DeleteDocument) GetKey() string {
+func (x *DeleteAPIKeyResponse) GetStatus() string {
 	if x != nil {
-		return x.Key
+		return x.Status
 	}
 	return ""
 }
 
-func (x *DeleteDocument) GetLang() string {
+// WhoAmI request
+type WhoAmIRequest struct {
+	state         protoimpl.MessageState `protogen:"open.v1"`
+	unknownFields protoimpl.UnknownFields
+	sizeCache     protoimpl.SizeCache
+}
+
+func (x *WhoAmIRequest) Reset() {
+	*x = WhoAmIRequest{}
+	mi := &file_proto_mddb_proto_msgTypes[75]
+	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+	ms.StoreMessageInfo(mi)
+}
+
+func (x *WhoAmIRequest) String() string {
+	return protoimpl.X.MessageStringOf(x)
+}
+
+func (*WhoAmIRequest) ProtoMessage() {}
+
+func (x *WhoAmIRequest) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_mddb_proto_msgTypes[75]
 	if x != nil {
-		return x.Lang
+		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
+		if ms.LoadMessageInfo() == nil {
+			ms.StoreMessageInfo(mi)
+		}
+		return ms
 	}
-	return ""
+	return mi.MessageOf(x)
 }
 
-// Delete batch response
-type DeleteBatchResponse struct {
+// Deprecated: Use WhoAmIRequest.ProtoReflect.Descriptor instead.
+func (*WhoAmIRequest) Descriptor() ([]byte, []int) {
+	return file_proto_mddb_proto_rawDescGZIP(), []int{75}
+}
+
+// WhoAmI response
+type WhoAmIResponse struct {
 	state         protoimpl.MessageState `protogen:"open.v1"`
-	Deleted       int32                  `protobuf:"varint,1,opt,name=deleted,proto3" json:"deleted,omitempty"`
-	Failed        int32                  `protobuf:"varint,2,opt,name=failed,proto3" json:"failed,omitempty"`
-	Errors        []string               `protobuf:"bytes,3,rep,name=errors,proto3" json:"errors,omitempty"`
-	NotFound      int32                  `protobuf:"varint,4,opt,name=not_found,json=notFound,proto3" json:"not_found,omitempty"`
+	Subject       string                 `protobuf:"bytes,1,opt,name=subject,proto3" json:"subject,omitempty"`
+	Method        string                 `protobuf:"bytes,2,opt,name=method,proto3" json:"method,omitempty"` // none, admin_key, api_key, jwt
+	Roles         []string               `protobuf:"bytes,3,rep,name=roles,proto3" json:"roles,omitempty"`
 	unknownFields protoimpl.UnknownFields
 	sizeCache     protoimpl.SizeCache
 }
 
-func (x *DeleteBatchResponse) Reset() {
-	*x = DeleteBatchResponse{}
-	mi := &file_proto_mddb_proto_msgTypes[25]
+func (x *WhoAmIResponse) Reset() {
+	*x = WhoAmIResponse{}
+	mi := &file_proto_mddb_proto_msgTypes[76]
 	ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 	ms.StoreMessageInfo(mi)
 }
 
-func (x *DeleteBatchResponse) String() string {
+func (x *WhoAmIResponse) String() string {
 	return protoimpl.X.MessageStringOf(x)
 }
 
-func (*DeleteBatchResponse) ProtoMessage() {}
+func (*WhoAmIResponse) ProtoMessage() {}
 
-func (x *DeleteBatchResponse) ProtoReflect() protoreflect.Message {
-	mi := &file_proto_mddb_proto_msgTypes[25]
+func (x *WhoAmIResponse) ProtoReflect() protoreflect.Message {
+	mi := &file_proto_mddb_proto_msgTypes[76]
 	if x != nil {
 		ms := protoimpl.X.MessageStateOf(protoimpl.Pointer(x))
 		if ms.LoadMessageInfo() == nil {
@@ -1590,39 +4916,32 @@ func (x *DeleteBatchResponse) ProtoReflect() protoreflect.Message {
 	return mi.MessageOf(x)
 }
 
-// Deprecated: Use DeleteBatchResponse.ProtoReflect.Descriptor instead.
-func (*DeleteBatchResponse) Descriptor() ([]byte, []int) {
-	return file_proto_mddb_proto_rawDescGZIP(), []int{25}
+// Deprecated: Use WhoAmIResponse.ProtoReflect.Descriptor instead.
+func (*WhoAmIResponse) Descriptor() ([]byte, []int) {
+	return file_proto_mddb_proto_rawDescGZIP(), []int{76}
 }
 
-func (x *DeleteBatchResponse) GetDeleted() int32 {
+func (x *WhoAmIResponse) GetSubject() string {
 	if x != nil {
-		return x.Deleted
+		return x.Subject
 	}
-	return 0
+	return ""
 }
 
-func (x *DeleteBatchResponse) GetFailed() int32 {
+func (x *WhoAmIResponse) GetMethod() string {
 	if x != nil {
-		return x.Failed
+		return x.Method
 	}
-	return 0
+	return ""
 }
 
-func (x *DeleteBatchResponse) GetErrors() []string {
+func (x *WhoAmIResponse) GetRoles() []string {
 	if x != nil {
-		return x.Errors
+		return x.Roles
 	}
 	return nil
 }
 
-func (x *DeleteBatchResponse) GetNotFound() int32 {
-	if x != nil {
-		return x.NotFound
-	}
-	return 0
-}
-
 var File_proto_mddb_proto protoreflect.FileDescriptor
 
 const file_proto_mddb_proto_rawDesc = "" +
@@ -1657,7 +4976,47 @@ const file_proto_mddb_proto_rawDesc = "" +
 	"\rsave_revision\x18\x06 \x01(\bR\fsaveRevision\x1aI\n" +
 	"\tMetaEntry\x12\x10\n" +
 	"\x03key\x18\x01 \x01(\tR\x03key\x12&\n" +
-	"\x05value\x18\x02 \x01(\v2\x10.mddb.MetaValuesR\x05value:\x028\x01\"d\n" +
+	"\x05value\x18\x02 \x01(\v2\x10.mddb.MetaValuesR\x05value:\x028\x01\"\xee\x01\n" +
+	"\fPatchRequest\x12\x1e\n" +
+	"\n" +
+	"collection\x18\x01 \x01(\tR\n" +
+	"collection\x12\x10\n" +
+	"\x03key\x18\x02 \x01(\tR\x03key\x12\x12\n" +
+	"\x04lang\x18\x03 \x01(\tR\x04lang\x12#\n" +
+	"\x04meta\x18\x04 \x03(\v2\x0f.mddb.MetaPatchR\x04meta\x12\"\n" +
+	"\n" +
+	"content_md\x18\x05 \x01(\tH\x00R\tcontentMd\x88\x01\x01\x12\x1b\n" +
+	"\tappend_md\x18\x06 \x01(\tR\bappendMd\x12#\n" +
+	"\rsave_revision\x18\a \x01(\bR\fsaveRevisionB\r\n" +
+	"\v_content_md\"E\n" +
+	"\tMetaPatch\x12\x0e\n" +
+	"\x02op\x18\x01 \x01(\tR\x02op\x12\x10\n" +
+	"\x03key\x18\x02 \x01(\tR\x03key\x12\x16\n" +
+	"\x06values\x18\x03 \x03(\tR\x06values\"2\n" +
+	"\x12TransactionRequest\x12\x1c\n" +
+	"\x03ops\x18\x01 \x03(\v2\n" +
+	".mddb.TxOpR\x03ops\"\xc5\x01\n" +
+	"\x04TxOp\x12$\n" +
+	"\x03add\x18\x01 \x01(\v2\x10.mddb.AddRequestH\x00R\x03add\x12*\n" +
+	"\x05patch\x18\x02 \x01(\v2\x12.mddb.PatchRequestH\x00R\x05patch\x12-\n" +
+	"\x06delete\x18\x03 \x01(\v2\x13.mddb.DeleteRequestH\x00R\x06delete\x126\n" +
+	"\fprecondition\x18\x04 \x01(\v2\x12.mddb.PreconditionR\fpreconditionB\x04\n" +
+	"\x02op\"Q\n" +
+	"\fPrecondition\x12\x1b\n" +
+	"\x06exists\x18\x01 \x01(\bH\x00R\x06exists\x88\x01\x01\x12\x19\n" +
+	"\bif_match\x18\x02 \x01(\tR\aifMatchB\t\n" +
+	"\a_exists\"?\n" +
+	"\x13TransactionResponse\x12(\n" +
+	"\aresults\x18\x01 \x03(\v2\x0e.mddb.TxResultR\aresults\"\xa6\x01\n" +
+	"\bTxResult\x12\x0e\n" +
+	"\x02op\x18\x01 \x01(\tR\x02op\x12\x1e\n" +
+	"\n" +
+	"collection\x18\x02 \x01(\tR\n" +
+	"collection\x12\x10\n" +
+	"\x03key\x18\x03 \x01(\tR\x03key\x12\x12\n" +
+	"\x04lang\x18\x04 \x01(\tR\x04lang\x12*\n" +
+	"\bdocument\x18\x05 \x01(\v2\x0e.mddb.DocumentR\bdocument\x12\x18\n" +
+	"\acreated\x18\x06 \x01(\bR\acreated\"d\n" +
 	"\x0fAddBatchRequest\x12\x1e\n" +
 	"\n" +
 	"collection\x18\x01 \x01(\tR\n" +
@@ -1688,7 +5047,7 @@ const file_proto_mddb_proto_rawDesc = "" +
 	"\x03env\x18\x04 \x03(\v2\x19.mddb.GetRequest.EnvEntryR\x03env\x1a6\n" +
 	"\bEnvEntry\x12\x10\n" +
 	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
-	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\"\x9a\x02\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\"\xbc\x02\n" +
 	"\rSearchRequest\x12\x1e\n" +
 	"\n" +
 	"collection\x18\x01 \x01(\tR\n" +
@@ -1698,7 +5057,8 @@ const file_proto_mddb_proto_rawDesc = "" +
 	"\x04sort\x18\x03 \x01(\tR\x04sort\x12\x10\n" +
 	"\x03asc\x18\x04 \x01(\bR\x03asc\x12\x14\n" +
 	"\x05limit\x18\x05 \x01(\x05R\x05limit\x12\x16\n" +
-	"\x06offset\x18\x06 \x01(\x05R\x06offset\x1aO\n" +
+	"\x06offset\x18\x06 \x01(\x05R\x06offset\x12 \n" +
+	"\vconsistency\x18\a \x01(\tR\vconsistency\x1aO\n" +
 	"\x0fFilterMetaEntry\x12\x10\n" +
 	"\x03key\x18\x01 \x01(\tR\x03key\x12&\n" +
 	"\x05value\x18\x02 \x01(\v2\x10.mddb.MetaValuesR\x05value:\x028\x01\"T\n" +
@@ -1717,15 +5077,83 @@ const file_proto_mddb_proto_rawDesc = "" +
 	"\x05value\x18\x02 \x01(\v2\x10.mddb.MetaValuesR\x05value:\x028\x01\":\n" +
 	"\vExportChunk\x12\x12\n" +
 	"\x04data\x18\x01 \x01(\fR\x04data\x12\x17\n" +
-	"\ais_last\x18\x02 \x01(\bR\x06isLast\"\x1f\n" +
+	"\ais_last\x18\x02 \x01(\bR\x06isLast\"A\n" +
 	"\rBackupRequest\x12\x0e\n" +
-	"\x02to\x18\x01 \x01(\tR\x02to\"(\n" +
+	"\x02to\x18\x01 \x01(\tR\x02to\x12 \n" +
+	"\vcompression\x18\x02 \x01(\tR\vcompression\"Z\n" +
 	"\x0eBackupResponse\x12\x16\n" +
-	"\x06backup\x18\x01 \x01(\tR\x06backup\"$\n" +
+	"\x06backup\x18\x01 \x01(\tR\x06backup\x120\n" +
+	"\bmanifest\x18\x02 \x01(\v2\x14.mddb.BackupManifestR\bmanifest\"7\n" +
+	"\x13StreamBackupRequest\x12 \n" +
+	"\vcompression\x18\x01 \x01(\tR\vcompression\"S\n" +
+	"\vBackupChunk\x12\x12\n" +
+	"\x04data\x18\x01 \x01(\fR\x04data\x120\n" +
+	"\bmanifest\x18\x02 \x01(\v2\x14.mddb.BackupManifestR\bmanifest\"\xc3\x01\n" +
+	"\x0eBackupManifest\x12\x16\n" +
+	"\x06format\x18\x01 \x01(\tR\x06format\x12 \n" +
+	"\vcompression\x18\x02 \x01(\tR\vcompression\x12\x1d\n" +
+	"\n" +
+	"created_at\x18\x03 \x01(\x03R\tcreatedAt\x12\x13\n" +
+	"\x05tx_id\x18\x04 \x01(\x03R\x04txId\x12\x17\n" +
+	"\adb_size\x18\x05 \x01(\x03R\x06dbSize\x12\x12\n" +
+	"\x04size\x18\x06 \x01(\x03R\x04size\x12\x16\n" +
+	"\x06sha256\x18\a \x01(\tR\x06sha256\"h\n" +
 	"\x0eRestoreRequest\x12\x12\n" +
-	"\x04from\x18\x01 \x01(\tR\x04from\"-\n" +
+	"\x04from\x18\x01 \x01(\tR\x04from\x12\x16\n" +
+	"\x06sha256\x18\x02 \x01(\tR\x06sha256\x12\x1a\n" +
+	"\brollback\x18\x03 \x01(\bR\brollback\x12\x0e\n" +
+	"\x02at\x18\x04 \x01(\tR\x02at\":\n" +
+	"\fRestoreChunk\x12\x12\n" +
+	"\x04data\x18\x01 \x01(\fR\x04data\x12\x16\n" +
+	"\x06sha256\x18\x02 \x01(\tR\x06sha256\"\xe9\x01\n" +
 	"\x0fRestoreResponse\x12\x1a\n" +
-	"\brestored\x18\x01 \x01(\tR\brestored\"m\n" +
+	"\brestored\x18\x01 \x01(\tR\brestored\x12\x1a\n" +
+	"\bprevious\x18\x02 \x01(\tR\bprevious\x12 \n" +
+	"\vcompression\x18\x03 \x01(\tR\vcompression\x12\x16\n" +
+	"\x06sha256\x18\x04 \x01(\tR\x06sha256\x12\x12\n" +
+	"\x04size\x18\x05 \x01(\x03R\x04size\x12\x13\n" +
+	"\x05tx_id\x18\x06 \x01(\x03R\x04txId\x12\x1f\n" +
+	"\vduration_ms\x18\a \x01(\x03R\n" +
+	"durationMs\x12\x1a\n" +
+	"\breplayed\x18\b \x01(\x03R\breplayed\"x\n" +
+	"\vDumpRequest\x12 \n" +
+	"\vcollections\x18\x01 \x03(\tR\vcollections\x12 \n" +
+	"\vcompression\x18\x02 \x01(\tR\vcompression\x12%\n" +
+	"\x0eskip_revisions\x18\x03 \x01(\bR\rskipRevisions\"O\n" +
+	"\tDumpChunk\x12\x12\n" +
+	"\x04data\x18\x01 \x01(\fR\x04data\x12.\n" +
+	"\bmanifest\x18\x02 \x01(\v2\x12.mddb.DumpManifestR\bmanifest\"\xae\x01\n" +
+	"\fDumpManifest\x12\x16\n" +
+	"\x06format\x18\x01 \x01(\tR\x06format\x12\x18\n" +
+	"\aversion\x18\x02 \x01(\x05R\aversion\x12\x1d\n" +
+	"\n" +
+	"created_at\x18\x03 \x01(\x03R\tcreatedAt\x12\x13\n" +
+	"\x05tx_id\x18\x04 \x01(\x03R\x04txId\x128\n" +
+	"\vcollections\x18\x05 \x03(\v2\x16.mddb.DumpedCollectionR\vcollections\"b\n" +
+	"\x10DumpedCollection\x12\x12\n" +
+	"\x04name\x18\x01 \x01(\tR\x04name\x12\x1c\n" +
+	"\tdocuments\x18\x02 \x01(\x03R\tdocuments\x12\x1c\n" +
+	"\trevisions\x18\x03 \x01(\x03R\trevisions\"\xcb\x01\n" +
+	"\tLoadChunk\x12\x12\n" +
+	"\x04data\x18\x01 \x01(\fR\x04data\x12 \n" +
+	"\vcollections\x18\x02 \x03(\tR\vcollections\x123\n" +
+	"\x06rename\x18\x03 \x03(\v2\x1b.mddb.LoadChunk.RenameEntryR\x06rename\x12\x18\n" +
+	"\areplace\x18\x04 \x01(\bR\areplace\x1a9\n" +
+	"\vRenameEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\"\x9b\x01\n" +
+	"\fLoadResponse\x12\x16\n" +
+	"\x06format\x18\x01 \x01(\tR\x06format\x12\x18\n" +
+	"\aversion\x18\x02 \x01(\x05R\aversion\x128\n" +
+	"\vcollections\x18\x03 \x03(\v2\x16.mddb.LoadedCollectionR\vcollections\x12\x1f\n" +
+	"\vduration_ms\x18\x04 \x01(\x03R\n" +
+	"durationMs\"\x92\x01\n" +
+	"\x10LoadedCollection\x12\x12\n" +
+	"\x04from\x18\x01 \x01(\tR\x04from\x12\x12\n" +
+	"\x04name\x18\x02 \x01(\tR\x04name\x12\x1c\n" +
+	"\tdocuments\x18\x03 \x01(\x03R\tdocuments\x12\x1c\n" +
+	"\trevisions\x18\x04 \x01(\x03R\trevisions\x12\x1a\n" +
+	"\breplaced\x18\x05 \x01(\bR\breplaced\"m\n" +
 	"\x0fTruncateRequest\x12\x1e\n" +
 	"\n" +
 	"collection\x18\x01 \x01(\tR\n" +
@@ -1734,8 +5162,45 @@ const file_proto_mddb_proto_rawDesc = "" +
 	"\n" +
 	"drop_cache\x18\x03 \x01(\bR\tdropCache\"*\n" +
 	"\x10TruncateResponse\x12\x16\n" +
-	"\x06status\x18\x01 \x01(\tR\x06status\"\x0e\n" +
-	"\fStatsRequest\"\xa6\x02\n" +
+	"\x06status\x18\x01 \x01(\tR\x06status\"E\n" +
+	"\vFsckRequest\x12\x1e\n" +
+	"\n" +
+	"collection\x18\x01 \x01(\tR\n" +
+	"collection\x12\x16\n" +
+	"\x06repair\x18\x02 \x01(\bR\x06repair\"\xce\x02\n" +
+	"\fFsckResponse\x12\x1c\n" +
+	"\tdocuments\x18\x01 \x01(\x05R\tdocuments\x12\x1f\n" +
+	"\vkey_entries\x18\x02 \x01(\x05R\n" +
+	"keyEntries\x12!\n" +
+	"\fmeta_indices\x18\x03 \x01(\x05R\vmetaIndices\x12\x1c\n" +
+	"\trevisions\x18\x04 \x01(\x05R\trevisions\x12<\n" +
+	"\bproblems\x18\x05 \x03(\v2 .mddb.FsckResponse.ProblemsEntryR\bproblems\x12'\n" +
+	"\x06issues\x18\x06 \x03(\v2\x0f.mddb.FsckIssueR\x06issues\x12\x1a\n" +
+	"\brepaired\x18\a \x01(\x05R\brepaired\x1a;\n" +
+	"\rProblemsEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\x05R\x05value:\x028\x01\"m\n" +
+	"\tFsckIssue\x12\x12\n" +
+	"\x04kind\x18\x01 \x01(\tR\x04kind\x12\x1e\n" +
+	"\n" +
+	"collection\x18\x02 \x01(\tR\n" +
+	"collection\x12\x10\n" +
+	"\x03key\x18\x03 \x01(\tR\x03key\x12\x1a\n" +
+	"\brepaired\x18\x04 \x01(\bR\brepaired\"6\n" +
+	"\x0eCompactRequest\x12$\n" +
+	"\x0emin_free_ratio\x18\x01 \x01(\x01R\fminFreeRatio\"\xcf\x01\n" +
+	"\x0fCompactResponse\x12\x1c\n" +
+	"\tcompacted\x18\x01 \x01(\bR\tcompacted\x12\x1f\n" +
+	"\vsize_before\x18\x02 \x01(\x03R\n" +
+	"sizeBefore\x12\x1d\n" +
+	"\n" +
+	"size_after\x18\x03 \x01(\x03R\tsizeAfter\x12\x1f\n" +
+	"\vfree_before\x18\x04 \x01(\x03R\n" +
+	"freeBefore\x12\x1c\n" +
+	"\treclaimed\x18\x05 \x01(\x03R\treclaimed\x12\x1f\n" +
+	"\vduration_ms\x18\x06 \x01(\x03R\n" +
+	"durationMs\"\x0e\n" +
+	"\fStatsRequest\"\xce\x02\n" +
 	"\rStatsResponse\x12#\n" +
 	"\rdatabase_path\x18\x01 \x01(\tR\fdatabasePath\x12#\n" +
 	"\rdatabase_size\x18\x02 \x01(\x03R\fdatabaseSize\x12\x12\n" +
@@ -1743,7 +5208,17 @@ const file_proto_mddb_proto_rawDesc = "" +
 	"\vcollections\x18\x04 \x03(\v2\x15.mddb.CollectionStatsR\vcollections\x12'\n" +
 	"\x0ftotal_documents\x18\x05 \x01(\x05R\x0etotalDocuments\x12'\n" +
 	"\x0ftotal_revisions\x18\x06 \x01(\x05R\x0etotalRevisions\x12,\n" +
-	"\x12total_meta_indices\x18\a \x01(\x05R\x10totalMetaIndices\"\x9d\x01\n" +
+	"\x12total_meta_indices\x18\a \x01(\x05R\x10totalMetaIndices\x12&\n" +
+	"\x05cache\x18\b \x01(\v2\x10.mddb.CacheStatsR\x05cache\"\xba\x01\n" +
+	"\n" +
+	"CacheStats\x12\x12\n" +
+	"\x04hits\x18\x01 \x01(\x04R\x04hits\x12\x16\n" +
+	"\x06misses\x18\x02 \x01(\x04R\x06misses\x12\x1c\n" +
+	"\tevictions\x18\x03 \x01(\x04R\tevictions\x12\x1a\n" +
+	"\brejected\x18\x04 \x01(\x04R\brejected\x12\x18\n" +
+	"\aentries\x18\x05 \x01(\x03R\aentries\x12\x14\n" +
+	"\x05bytes\x18\x06 \x01(\x03R\x05bytes\x12\x16\n" +
+	"\x06budget\x18\a \x01(\x03R\x06budget\"\x9d\x01\n" +
 	"\x0fCollectionStats\x12\x12\n" +
 	"\x04name\x18\x01 \x01(\tR\x04name\x12%\n" +
 	"\x0edocument_count\x18\x02 \x01(\x05R\rdocumentCount\x12%\n" +
@@ -1781,20 +5256,171 @@ const file_proto_mddb_proto_rawDesc = "" +
 	"\adeleted\x18\x01 \x01(\x05R\adeleted\x12\x16\n" +
 	"\x06failed\x18\x02 \x01(\x05R\x06failed\x12\x16\n" +
 	"\x06errors\x18\x03 \x03(\tR\x06errors\x12\x1b\n" +
-	"\tnot_found\x18\x04 \x01(\x05R\bnotFound2\xde\x04\n" +
+	"\tnot_found\x18\x04 \x01(\x05R\bnotFound\"\xd1\x03\n" +
+	"\x10CollectionSchema\x12\x1e\n" +
+	"\n" +
+	"collection\x18\x01 \x01(\tR\n" +
+	"collection\x12#\n" +
+	"\rrequired_meta\x18\x02 \x03(\tR\frequiredMeta\x12#\n" +
+	"\roptional_meta\x18\x03 \x03(\tR\foptionalMeta\x127\n" +
+	"\x05enums\x18\x04 \x03(\v2!.mddb.CollectionSchema.EnumsEntryR\x05enums\x12@\n" +
+	"\bpatterns\x18\x05 \x03(\v2$.mddb.CollectionSchema.PatternsEntryR\bpatterns\x12(\n" +
+	"\x10max_content_size\x18\x06 \x01(\x03R\x0emaxContentSize\x12%\n" +
+	"\x0erequired_langs\x18\a \x03(\tR\rrequiredLangs\x1aJ\n" +
+	"\n" +
+	"EnumsEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12&\n" +
+	"\x05value\x18\x02 \x01(\v2\x10.mddb.MetaValuesR\x05value:\x028\x01\x1a;\n" +
+	"\rPatternsEntry\x12\x10\n" +
+	"\x03key\x18\x01 \x01(\tR\x03key\x12\x14\n" +
+	"\x05value\x18\x02 \x01(\tR\x05value:\x028\x01\"/\n" +
+	"\rSchemaRequest\x12\x1e\n" +
+	"\n" +
+	"collection\x18\x01 \x01(\tR\n" +
+	"collection\".\n" +
+	"\x14DeleteSchemaResponse\x12\x16\n" +
+	"\x06status\x18\x01 \x01(\tR\x06status\"\xf2\x01\n" +
+	"\x0eCollectionInfo\x12\x12\n" +
+	"\x04name\x18\x01 \x01(\tR\x04name\x12 \n" +
+	"\vdescription\x18\x02 \x01(\tR\vdescription\x12!\n" +
+	"\fdefault_lang\x18\x03 \x01(\tR\vdefaultLang\x12'\n" +
+	"\x0frevision_policy\x18\x04 \x01(\tR\x0erevisionPolicy\x12 \n" +
+	"\vcompression\x18\x05 \x01(\tR\vcompression\x12\x1d\n" +
+	"\n" +
+	"created_at\x18\x06 \x01(\x03R\tcreatedAt\x12\x1d\n" +
+	"\n" +
+	"updated_at\x18\a \x01(\x03R\tupdatedAt\"\xae\x01\n" +
+	"\x15CollectionDescription\x12(\n" +
+	"\x04info\x18\x01 \x01(\v2\x14.mddb.CollectionInfoR\x04info\x12%\n" +
+	"\x0edocument_count\x18\x02 \x01(\x05R\rdocumentCount\x12%\n" +
+	"\x0erevision_count\x18\x03 \x01(\x05R\rrevisionCount\x12\x1d\n" +
+	"\n" +
+	"has_schema\x18\x04 \x01(\bR\thasSchema\"\x18\n" +
+	"\x16ListCollectionsRequest\"Q\n" +
+	"\x17ListCollectionsResponse\x126\n" +
+	"\vcollections\x18\x01 \x03(\v2\x14.mddb.CollectionInfoR\vcollections\"+\n" +
+	"\x15CollectionNameRequest\x12\x12\n" +
+	"\x04name\x18\x01 \x01(\tR\x04name\"F\n" +
+	"\x15CopyCollectionRequest\x12\x12\n" +
+	"\x04name\x18\x01 \x01(\tR\x04name\x12\x19\n" +
+	"\bnew_name\x18\x02 \x01(\tR\anewName\"n\n" +
+	"\x16CopyCollectionResponse\x12\x16\n" +
+	"\x06status\x18\x01 \x01(\tR\x06status\x12\x1e\n" +
+	"\n" +
+	"collection\x18\x02 \x01(\tR\n" +
+	"collection\x12\x1c\n" +
+	"\tdocuments\x18\x03 \x01(\x05R\tdocuments\"9\n" +
+	"\x17DeleteCollectionRequest\x12\x1e\n" +
+	"\n" +
+	"collection\x18\x01 \x01(\tR\n" +
+	"collection\"w\n" +
+	"\x18DeleteCollectionResponse\x12\x16\n" +
+	"\x06status\x18\x01 \x01(\tR\x06status\x12\x1e\n" +
+	"\n" +
+	"collection\x18\x02 \x01(\tR\n" +
+	"collection\x12#\n" +
+	"\rdeleted_count\x18\x03 \x01(\x05R\fdeletedCount\"\xd9\x01\n" +
+	"\rImportOptions\x12\x1e\n" +
+	"\n" +
+	"collection\x18\x01 \x01(\tR\n" +
+	"collection\x12\x16\n" +
+	"\x06format\x18\x02 \x01(\tR\x06format\x12\x1f\n" +
+	"\von_conflict\x18\x03 \x01(\tR\n" +
+	"onConflict\x12\x17\n" +
+	"\adry_run\x18\x04 \x01(\bR\x06dryRun\x12\x12\n" +
+	"\x04lang\x18\x05 \x01(\tR\x04lang\x12\x1d\n" +
+	"\n" +
+	"chunk_size\x18\x06 \x01(\x05R\tchunkSize\x12#\n" +
+	"\rsave_revision\x18\a \x01(\bR\fsaveRevision\"P\n" +
+	"\vImportChunk\x12-\n" +
+	"\aoptions\x18\x01 \x01(\v2\x13.mddb.ImportOptionsR\aoptions\x12\x12\n" +
+	"\x04data\x18\x02 \x01(\fR\x04data\"\xd1\x01\n" +
+	"\x0eImportResponse\x12\x14\n" +
+	"\x05total\x18\x01 \x01(\x05R\x05total\x12\x14\n" +
+	"\x05added\x18\x02 \x01(\x05R\x05added\x12\x18\n" +
+	"\aupdated\x18\x03 \x01(\x05R\aupdated\x12\x18\n" +
+	"\askipped\x18\x04 \x01(\x05R\askipped\x12\x16\n" +
+	"\x06failed\x18\x05 \x01(\x05R\x06failed\x12\x16\n" +
+	"\x06chunks\x18\x06 \x01(\x05R\x06chunks\x12\x16\n" +
+	"\x06errors\x18\a \x03(\tR\x06errors\x12\x17\n" +
+	"\adry_run\x18\b \x01(\bR\x06dryRun\"U\n" +
+	"\rDeleteRequest\x12\x1e\n" +
+	"\n" +
+	"collection\x18\x01 \x01(\tR\n" +
+	"collection\x12\x10\n" +
+	"\x03key\x18\x02 \x01(\tR\x03key\x12\x12\n" +
+	"\x04lang\x18\x03 \x01(\tR\x04lang\"n\n" +
+	"\x0eDeleteResponse\x12\x16\n" +
+	"\x06status\x18\x01 \x01(\tR\x06status\x12\x1e\n" +
+	"\n" +
+	"collection\x18\x02 \x01(\tR\n" +
+	"collection\x12\x10\n" +
+	"\x03key\x18\x03 \x01(\tR\x03key\x12\x12\n" +
+	"\x04lang\x18\x04 \x01(\tR\x04lang\"\x0f\n" +
+	"\rHealthRequest\"R\n" +
+	"\x0eHealthResponse\x12\x16\n" +
+	"\x06status\x18\x01 \x01(\tR\x06status\x12\x12\n" +
+	"\x04mode\x18\x02 \x01(\tR\x04mode\x12\x14\n" +
+	"\x05error\x18\x03 \x01(\tR\x05error\"?\n" +
+	"\x13CreateAPIKeyRequest\x12\x12\n" +
+	"\x04name\x18\x01 \x01(\tR\x04name\x12\x14\n" +
+	"\x05roles\x18\x02 \x03(\tR\x05roles\"s\n" +
+	"\x06APIKey\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\x12\x12\n" +
+	"\x04name\x18\x02 \x01(\tR\x04name\x12\x14\n" +
+	"\x05roles\x18\x03 \x03(\tR\x05roles\x12\x1d\n" +
+	"\n" +
+	"created_at\x18\x04 \x01(\x03R\tcreatedAt\x12\x10\n" +
+	"\x03key\x18\x05 \x01(\tR\x03key\"\x14\n" +
+	"\x12ListAPIKeysRequest\"7\n" +
+	"\x13ListAPIKeysResponse\x12 \n" +
+	"\x04keys\x18\x01 \x03(\v2\f.mddb.APIKeyR\x04keys\"%\n" +
+	"\x13DeleteAPIKeyRequest\x12\x0e\n" +
+	"\x02id\x18\x01 \x01(\tR\x02id\".\n" +
+	"\x14DeleteAPIKeyResponse\x12\x16\n" +
+	"\x06status\x18\x01 \x01(\tR\x06status\"\x0f\n" +
+	"\rWhoAmIRequest\"X\n" +
+	"\x0eWhoAmIResponse\x12\x18\n" +
+	"\asubject\x18\x01 \x01(\tR\asubject\x12\x16\n" +
+	"\x06method\x18\x02 \x01(\tR\x06method\x12\x14\n" +
+	"\x05roles\x18\x03 \x03(\tR\x05roles2\xf1\x10\n" +
 	"\x04MDDB\x12'\n" +
-	"\x03Add\x12\x10.mddb.AddRequest\x1a\x0e.mddb.Document\x129\n" +
+	"\x03Add\x12\x10.mddb.AddRequest\x1a\x0e.mddb.Document\x12+\n" +
+	"\x05Patch\x12\x12.mddb.PatchRequest\x1a\x0e.mddb.Document\x12B\n" +
+	"\vTransaction\x12\x18.mddb.TransactionRequest\x1a\x19.mddb.TransactionResponse\x129\n" +
 	"\bAddBatch\x12\x15.mddb.AddBatchRequest\x1a\x16.mddb.AddBatchResponse\x12B\n" +
 	"\vUpdateBatch\x12\x18.mddb.UpdateBatchRequest\x1a\x19.mddb.UpdateBatchResponse\x12B\n" +
 	"\vDeleteBatch\x12\x18.mddb.DeleteBatchRequest\x1a\x19.mddb.DeleteBatchResponse\x12'\n" +
 	"\x03Get\x12\x10.mddb.GetRequest\x1a\x0e.mddb.Document\x123\n" +
 	"\x06Search\x12\x13.mddb.SearchRequest\x1a\x14.mddb.SearchResponse\x122\n" +
 	"\x06Export\x12\x13.mddb.ExportRequest\x1a\x11.mddb.ExportChunk0\x01\x123\n" +
-	"\x06Backup\x12\x13.mddb.BackupRequest\x1a\x14.mddb.BackupResponse\x126\n" +
-	"\aRestore\x12\x14.mddb.RestoreRequest\x1a\x15.mddb.RestoreResponse\x129\n" +
-	"\bTruncate\x12\x15.mddb.TruncateRequest\x1a\x16.mddb.TruncateResponse\x120\n" +
-	"\x05Stats\x12\x12.mddb.StatsRequest\x1a\x13.mddb.StatsResponseB\fZ\n" +
-	"mddb/protob\x06proto3"
+	"\x06Backup\x12\x13.mddb.BackupRequest\x1a\x14.mddb.BackupResponse\x12>\n" +
+	"\fStreamBackup\x12\x19.mddb.StreamBackupRequest\x1a\x11.mddb.BackupChunk0\x01\x126\n" +
+	"\aRestore\x12\x14.mddb.RestoreRequest\x1a\x15.mddb.RestoreResponse\x12<\n" +
+	"\rStreamRestore\x12\x12.mddb.RestoreChunk\x1a\x15.mddb.RestoreResponse(\x01\x12,\n" +
+	"\x04Dump\x12\x11.mddb.DumpRequest\x1a\x0f.mddb.DumpChunk0\x01\x12-\n" +
+	"\x04Load\x12\x0f.mddb.LoadChunk\x1a\x12.mddb.LoadResponse(\x01\x129\n" +
+	"\bTruncate\x12\x15.mddb.TruncateRequest\x1a\x16.mddb.TruncateResponse\x12-\n" +
+	"\x04Fsck\x12\x11.mddb.FsckRequest\x1a\x12.mddb.FsckResponse\x126\n" +
+	"\aCompact\x12\x14.mddb.CompactRequest\x1a\x15.mddb.CompactResponse\x120\n" +
+	"\x05Stats\x12\x12.mddb.StatsRequest\x1a\x13.mddb.StatsResponse\x12;\n" +
+	"\tSetSchema\x12\x16.mddb.CollectionSchema\x1a\x16.mddb.CollectionSchema\x128\n" +
+	"\tGetSchema\x12\x13.mddb.SchemaRequest\x1a\x16.mddb.CollectionSchema\x12?\n" +
+	"\fDeleteSchema\x12\x13.mddb.SchemaRequest\x1a\x1a.mddb.DeleteSchemaResponse\x12>\n" +
+	"\x10CreateCollection\x12\x14.mddb.CollectionInfo\x1a\x14.mddb.CollectionInfo\x12>\n" +
+	"\x10UpdateCollection\x12\x14.mddb.CollectionInfo\x1a\x14.mddb.CollectionInfo\x12N\n" +
+	"\x0fListCollections\x12\x1c.mddb.ListCollectionsRequest\x1a\x1d.mddb.ListCollectionsResponse\x12N\n" +
+	"\x12DescribeCollection\x12\x1b.mddb.CollectionNameRequest\x1a\x1b.mddb.CollectionDescription\x12M\n" +
+	"\x10RenameCollection\x12\x1b.mddb.CopyCollectionRequest\x1a\x1c.mddb.CopyCollectionResponse\x12L\n" +
+	"\x0fCloneCollection\x12\x1b.mddb.CopyCollectionRequest\x1a\x1c.mddb.CopyCollectionResponse\x12Q\n" +
+	"\x10DeleteCollection\x12\x1d.mddb.DeleteCollectionRequest\x1a\x1e.mddb.DeleteCollectionResponse\x123\n" +
+	"\x06Import\x12\x11.mddb.ImportChunk\x1a\x14.mddb.ImportResponse(\x01\x123\n" +
+	"\x06Delete\x12\x13.mddb.DeleteRequest\x1a\x14.mddb.DeleteResponse\x123\n" +
+	"\x06Health\x12\x13.mddb.HealthRequest\x1a\x14.mddb.HealthResponse\x127\n" +
+	"\fCreateAPIKey\x12\x19.mddb.CreateAPIKeyRequest\x1a\f.mddb.APIKey\x12B\n" +
+	"\vListAPIKeys\x12\x18.mddb.ListAPIKeysRequest\x1a\x19.mddb.ListAPIKeysResponse\x12E\n" +
+	"\fDeleteAPIKey\x12\x19.mddb.DeleteAPIKeyRequest\x1a\x1a.mddb.DeleteAPIKeyResponse\x123\n" +
+	"\x06WhoAmI\x12\x13.mddb.WhoAmIRequest\x1a\x14.mddb.WhoAmIResponseB-Z+github.com/tradik/mddb/services/mddbd/protob\x06proto3"
 
 var (
 	file_proto_mddb_proto_rawDescOnce sync.Once
@@ -1808,88 +5434,217 @@ func file_proto_mddb_proto_rawDescGZIP() []byte {
 	return file_proto_mddb_proto_rawDescData
 }
 
-var file_proto_mddb_proto_msgTypes = make([]protoimpl.MessageInfo, 33)
+var file_proto_mddb_proto_msgTypes = make([]protoimpl.MessageInfo, 88)
 var file_proto_mddb_proto_goTypes = []any{
-	(*Document)(nil),            // 0: mddb.Document
-	(*MetaValues)(nil),          // 1: mddb.MetaValues
-	(*AddRequest)(nil),          // 2: mddb.AddRequest
-	(*AddBatchRequest)(nil),     // 3: mddb.AddBatchRequest
-	(*BatchDocument)(nil),       // 4: mddb.BatchDocument
-	(*AddBatchResponse)(nil),    // 5: mddb.AddBatchResponse
-	(*GetRequest)(nil),          // 6: mddb.GetRequest
-	(*SearchRequest)(nil),       // 7: mddb.SearchRequest
-	(*SearchResponse)(nil),      // 8: mddb.SearchResponse
-	(*ExportRequest)(nil),       // 9: mddb.ExportRequest
-	(*ExportChunk)(nil),         // 10: mddb.ExportChunk
-	(*BackupRequest)(nil),       // 11: mddb.BackupRequest
-	(*BackupResponse)(nil),      // 12: mddb.BackupResponse
-	(*RestoreRequest)(nil),      // 13: mddb.RestoreRequest
-	(*RestoreResponse)(nil),     // 14: mddb.RestoreResponse
-	(*TruncateRequest)(nil),     // 15: mddb.TruncateRequest
-	(*TruncateResponse)(nil),    // 16: mddb.TruncateResponse
-	(*StatsRequest)(nil),        // 17: mddb.StatsRequest
-	(*StatsResponse)(nil),       // 18: mddb.StatsResponse
-	(*CollectionStats)(nil),     // 19: mddb.CollectionStats
-	(*UpdateBatchRequest)(nil),  // 20: mddb.UpdateBatchRequest
-	(*UpdateDocument)(nil),      // 21: mddb.UpdateDocument
-	(*UpdateBatchResponse)(nil), // 22: mddb.UpdateBatchResponse
-	(*DeleteBatchRequest)(nil),  // 23: mddb.DeleteBatchRequest
-	(*DeleteDocument)(nil),      // 24: mddb.DeleteDocument
-	(*DeleteBatchResponse)(nil), // 25: mddb.DeleteBatchResponse
-	nil,                         // 26: mddb.Document.MetaEntry
-	nil,                         // 27: mddb.AddRequest.MetaEntry
-	nil,                         // 28: mddb.BatchDocument.MetaEntry
-	nil,                         // 29: mddb.GetRequest.EnvEntry
-	nil,                         // 30: mddb.SearchRequest.FilterMetaEntry
-	nil,                         // 31: mddb.ExportRequest.FilterMetaEntry
-	nil,                         // 32: mddb.UpdateDocument.MetaEntry
+	(*Document)(nil),                 // 0: mddb.Document
+	(*MetaValues)(nil),               // 1: mddb.MetaValues
+	(*AddRequest)(nil),               // 2: mddb.AddRequest
+	(*PatchRequest)(nil),             // 3: mddb.PatchRequest
+	(*MetaPatch)(nil),                // 4: mddb.MetaPatch
+	(*TransactionRequest)(nil),       // 5: mddb.TransactionRequest
+	(*TxOp)(nil),                     // 6: mddb.TxOp
+	(*Precondition)(nil),             // 7: mddb.Precondition
+	(*TransactionResponse)(nil),      // 8: mddb.TransactionResponse
+	(*TxResult)(nil),                 // 9: mddb.TxResult
+	(*AddBatchRequest)(nil),          // 10: mddb.AddBatchRequest
+	(*BatchDocument)(nil),            // 11: mddb.BatchDocument
+	(*AddBatchResponse)(nil),         // 12: mddb.AddBatchResponse
+	(*GetRequest)(nil),               // 13: mddb.GetRequest
+	(*SearchRequest)(nil),            // 14: mddb.SearchRequest
+	(*SearchResponse)(nil),           // 15: mddb.SearchResponse
+	(*ExportRequest)(nil),            // 16: mddb.ExportRequest
+	(*ExportChunk)(nil),              // 17: mddb.ExportChunk
+	(*BackupRequest)(nil),            // 18: mddb.BackupRequest
+	(*BackupResponse)(nil),           // 19: mddb.BackupResponse
+	(*StreamBackupRequest)(nil),      // 20: mddb.StreamBackupRequest
+	(*BackupChunk)(nil),              // 21: mddb.BackupChunk
+	(*BackupManifest)(nil),           // 22: mddb.BackupManifest
+	(*RestoreRequest)(nil),           // 23: mddb.RestoreRequest
+	(*RestoreChunk)(nil),             // 24: mddb.RestoreChunk
+	(*RestoreResponse)(nil),          // 25: mddb.RestoreResponse
+	(*DumpRequest)(nil),              // 26: mddb.DumpRequest
+	(*DumpChunk)(nil),                // 27: mddb.DumpChunk
+	(*DumpManifest)(nil),             // 28: mddb.DumpManifest
+	(*DumpedCollection)(nil),         // 29: mddb.DumpedCollection
+	(*LoadChunk)(nil),                // 30: mddb.LoadChunk
+	(*LoadResponse)(nil),             // 31: mddb.LoadResponse
+	(*LoadedCollection)(nil),         // 32: mddb.LoadedCollection
+	(*TruncateRequest)(nil),          // 33: mddb.TruncateRequest
+	(*TruncateResponse)(nil),         // 34: mddb.TruncateResponse
+	(*FsckRequest)(nil),              // 35: mddb.FsckRequest
+	(*FsckResponse)(nil),             // 36: mddb.FsckResponse
+	(*FsckIssue)(nil),                // 37: mddb.FsckIssue
+	(*CompactRequest)(nil),           // 38: mddb.CompactRequest
+	(*CompactResponse)(nil),          // 39: mddb.CompactResponse
+	(*StatsRequest)(nil),             // 40: mddb.StatsRequest
+	(*StatsResponse)(nil),            // 41: mddb.StatsResponse
+	(*CacheStats)(nil),               // 42: mddb.CacheStats
+	(*CollectionStats)(nil),          // 43: mddb.CollectionStats
+	(*UpdateBatchRequest)(nil),       // 44: mddb.UpdateBatchRequest
+	(*UpdateDocument)(nil),           // 45: mddb.UpdateDocument
+	(*UpdateBatchResponse)(nil),      // 46: mddb.UpdateBatchResponse
+	(*DeleteBatchRequest)(nil),       // 47: mddb.DeleteBatchRequest
+	(*DeleteDocument)(nil),           // 48: mddb.DeleteDocument
+	(*DeleteBatchResponse)(nil),      // 49: mddb.DeleteBatchResponse
+	(*CollectionSchema)(nil),         // 50: mddb.CollectionSchema
+	(*SchemaRequest)(nil),            // 51: mddb.SchemaRequest
+	(*DeleteSchemaResponse)(nil),     // 52: mddb.DeleteSchemaResponse
+	(*CollectionInfo)(nil),           // 53: mddb.CollectionInfo
+	(*CollectionDescription)(nil),    // 54: mddb.CollectionDescription
+	(*ListCollectionsRequest)(nil),   // 55: mddb.ListCollectionsRequest
+	(*ListCollectionsResponse)(nil),  // 56: mddb.ListCollectionsResponse
+	(*CollectionNameRequest)(nil),    // 57: mddb.CollectionNameRequest
+	(*CopyCollectionRequest)(nil),    // 58: mddb.CopyCollectionRequest
+	(*CopyCollectionResponse)(nil),   // 59: mddb.CopyCollectionResponse
+	(*DeleteCollectionRequest)(nil),  // 60: mddb.DeleteCollectionRequest
+	(*DeleteCollectionResponse)(nil), // 61: mddb.DeleteCollectionResponse
+	(*ImportOptions)(nil),            // 62: mddb.ImportOptions
+	(*ImportChunk)(nil),              // 63: mddb.ImportChunk
+	(*ImportResponse)(nil),           // 64: mddb.ImportResponse
+	(*DeleteRequest)(nil),            // 65: mddb.DeleteRequest
+	(*DeleteResponse)(nil),           // 66: mddb.DeleteResponse
+	(*HealthRequest)(nil),            // 67: mddb.HealthRequest
+	(*HealthResponse)(nil),           // 68: mddb.HealthResponse
+	(*CreateAPIKeyRequest)(nil),      // 69: mddb.CreateAPIKeyRequest
+	(*APIKey)(nil),                   // 70: mddb.APIKey
+	(*ListAPIKeysRequest)(nil),       // 71: mddb.ListAPIKeysRequest
+	(*ListAPIKeysResponse)(nil),      // 72: mddb.ListAPIKeysResponse
+	(*DeleteAPIKeyRequest)(nil),      // 73: mddb.DeleteAPIKeyRequest
+	(*DeleteAPIKeyResponse)(nil),     // 74: mddb.DeleteAPIKeyResponse
+	(*WhoAmIRequest)(nil),            // 75: mddb.WhoAmIRequest
+	(*WhoAmIResponse)(nil),           // 76: mddb.WhoAmIResponse
+	nil,                              // 77: mddb.Document.MetaEntry
+	nil,                              // 78: mddb.AddRequest.MetaEntry
+	nil,                              // 79: mddb.BatchDocument.MetaEntry
+	nil,                              // 80: mddb.GetRequest.EnvEntry
+	nil,                              // 81: mddb.SearchRequest.FilterMetaEntry
+	nil,                              // 82: mddb.ExportRequest.FilterMetaEntry
+	nil,                              // 83: mddb.LoadChunk.RenameEntry
+	nil,                              // 84: mddb.FsckResponse.ProblemsEntry
+	nil,                              // 85: mddb.UpdateDocument.MetaEntry
+	nil,                              // 86: mddb.CollectionSchema.EnumsEntry
+	nil,                              // 87: mddb.CollectionSchema.PatternsEntry
 }
 var file_proto_mddb_proto_depIdxs = []int32{
-	26, // 0: mddb.Document.meta:type_name -> mddb.Document.MetaEntry
-	27, // 1: mddb.AddRequest.meta:type_name -> mddb.AddRequest.MetaEntry
-	4,  // 2: mddb.AddBatchRequest.documents:type_name -> mddb.BatchDocument
-	28, // 3: mddb.BatchDocument.meta:type_name -> mddb.BatchDocument.MetaEntry
-	29, // 4: mddb.GetRequest.env:type_name -> mddb.GetRequest.EnvEntry
-	30, // 5: mddb.SearchRequest.filter_meta:type_name -> mddb.SearchRequest.FilterMetaEntry
-	0,  // 6: mddb.SearchResponse.documents:type_name -> mddb.Document
-	31, // 7: mddb.ExportRequest.filter_meta:type_name -> mddb.ExportRequest.FilterMetaEntry
-	19, // 8: mddb.StatsResponse.collections:type_name -> mddb.CollectionStats
-	21, // 9: mddb.UpdateBatchRequest.documents:type_name -> mddb.UpdateDocument
-	32, // 10: mddb.UpdateDocument.meta:type_name -> mddb.UpdateDocument.MetaEntry
-	24, // 11: mddb.DeleteBatchRequest.documents:type_name -> mddb.DeleteDocument
-	1,  // 12: mddb.Document.MetaEntry.value:type_name -> mddb.MetaValues
-	1,  // 13: mddb.AddRequest.MetaEntry.value:type_name -> mddb.MetaValues
-	1,  // 14: mddb.BatchDocument.MetaEntry.value:type_name -> mddb.MetaValues
-	1,  // 15: mddb.SearchRequest.FilterMetaEntry.value:type_name -> mddb.MetaValues
-	1,  // 16: mddb.ExportRequest.FilterMetaEntry.value:type_name -> mddb.MetaValues
-	1,  // 17: mddb.UpdateDocument.MetaEntry.value:type_name -> mddb.MetaValues
-	2,  // 18: mddb.MDDB.Add:input_type -> mddb.AddRequest
-	3,  // 19: mddb.MDDB.AddBatch:input_type -> mddb.AddBatchRequest
-	20, // 20: mddb.MDDB.UpdateBatch:input_type -> mddb.UpdateBatchRequest
-	23, // 21: mddb.MDDB.DeleteBatch:input_type -> mddb.DeleteBatchRequest
-	6,  // 22: mddb.MDDB.Get:input_type -> mddb.GetRequest
-	7,  // 23: mddb.MDDB.Search:input_type -> mddb.SearchRequest
-	9,  // 24: mddb.MDDB.Export:input_type -> mddb.ExportRequest
-	11, // 25: mddb.MDDB.Backup:input_type -> mddb.BackupRequest
-	13, // 26: mddb.MDDB.Restore:input_type -> mddb.RestoreRequest
-	15, // 27: mddb.MDDB.Truncate:input_type -> mddb.TruncateRequest
-	17, // 28: mddb.MDDB.Stats:input_type -> mddb.StatsRequest
-	0,  // 29: mddb.MDDB.Add:output_type -> mddb.Document
-	5,  // 30: mddb.MDDB.AddBatch:output_type -> mddb.AddBatchResponse
-	22, // 31: mddb.MDDB.UpdateBatch:output_type -> mddb.UpdateBatchResponse
-	25, // 32: mddb.MDDB.DeleteBatch:output_type -> mddb.DeleteBatchResponse
-	0,  // 33: mddb.MDDB.Get:output_type -> mddb.Document
-	8,  // 34: mddb.MDDB.Search:output_type -> mddb.SearchResponse
-	10, // 35: mddb.MDDB.Export:output_type -> mddb.ExportChunk
-	12, // 36: mddb.MDDB.Backup:output_type -> mddb.BackupResponse
-	14, // 37: mddb.MDDB.Restore:output_type -> mddb.RestoreResponse
-	16, // 38: mddb.MDDB.Truncate:output_type -> mddb.TruncateResponse
-	18, // 39: mddb.MDDB.Stats:output_type -> mddb.StatsResponse
-	29, // [29:40] is the sub-list for method output_type
-	18, // [18:29] is the sub-list for method input_type
-	18, // [18:18] is the sub-list for extension type_name
-	18, // [18:18] is the sub-list for extension extendee
-	0,  // [0:18] is the sub-list for field type_name
+	77, // 0: mddb.Document.meta:type_name -> mddb.Document.MetaEntry
+	78, // 1: mddb.AddRequest.meta:type_name -> mddb.AddRequest.MetaEntry
+	4,  // 2: mddb.PatchRequest.meta:type_name -> mddb.MetaPatch
+	6,  // 3: mddb.TransactionRequest.ops:type_name -> mddb.TxOp
+	2,  // 4: mddb.TxOp.add:type_name -> mddb.AddRequest
+	3,  // 5: mddb.TxOp.patch:type_name -> mddb.PatchRequest
+	65, // 6: mddb.TxOp.delete:type_name -> mddb.DeleteRequest
+	7,  // 7: mddb.TxOp.precondition:type_name -> mddb.Precondition
+	9,  // 8: mddb.TransactionResponse.results:type_name -> mddb.TxResult
+	0,  // 9: mddb.TxResult.document:type_name -> mddb.Document
+	11, // 10: mddb.AddBatchRequest.documents:type_name -> mddb.BatchDocument
+	79, // 11: mddb.BatchDocument.meta:type_name -> mddb.BatchDocument.MetaEntry
+	80, // 12: mddb.GetRequest.env:type_name -> mddb.GetRequest.EnvEntry
+	81, // 13: mddb.SearchRequest.filter_meta:type_name -> mddb.SearchRequest.FilterMetaEntry
+	0,  // 14: mddb.SearchResponse.documents:type_name -> mddb.Document
+	82, // 15: mddb.ExportRequest.filter_meta:type_name -> mddb.ExportRequest.FilterMetaEntry
+	22, // 16: mddb.BackupResponse.manifest:type_name -> mddb.BackupManifest
+	22, // 17: mddb.BackupChunk.manifest:type_name -> mddb.BackupManifest
+	28, // 18: mddb.DumpChunk.manifest:type_name -> mddb.DumpManifest
+	29, // 19: mddb.DumpManifest.collections:type_name -> mddb.DumpedCollection
+	83, // 20: mddb.LoadChunk.rename:type_name -> mddb.LoadChunk.RenameEntry
+	32, // 21: mddb.LoadResponse.collections:type_name -> mddb.LoadedCollection
+	84, // 22: mddb.FsckResponse.problems:type_name -> mddb.FsckResponse.ProblemsEntry
+	37, // 23: mddb.FsckResponse.issues:type_name -> mddb.FsckIssue
+	43, // 24: mddb.StatsResponse.collections:type_name -> mddb.CollectionStats
+	42, // 25: mddb.StatsResponse.cache:type_name -> mddb.CacheStats
+	45, // 26: mddb.UpdateBatchRequest.documents:type_name -> mddb.UpdateDocument
+	85, // 27: mddb.UpdateDocument.meta:type_name -> mddb.UpdateDocument.MetaEntry
+	48, // 28: mddb.DeleteBatchRequest.documents:type_name -> mddb.DeleteDocument
+	86, // 29: mddb.CollectionSchema.enums:type_name -> mddb.CollectionSchema.EnumsEntry
+	87, // 30: mddb.CollectionSchema.patterns:type_name -> mddb.CollectionSchema.PatternsEntry
+	53, // 31: mddb.CollectionDescription.info:type_name -> mddb.CollectionInfo
+	53, // 32: mddb.ListCollectionsResponse.collections:type_name -> mddb.CollectionInfo
+	62, // 33: mddb.ImportChunk.options:type_name -> mddb.ImportOptions
+	70, // 34: mddb.ListAPIKeysResponse.keys:type_name -> mddb.APIKey
+	1,  // 35: mddb.Document.MetaEntry.value:type_name -> mddb.MetaValues
+	1,  // 36: mddb.AddRequest.MetaEntry.value:type_name -> mddb.MetaValues
+	1,  // 37: mddb.BatchDocument.MetaEntry.value:type_name -> mddb.MetaValues
+	1,  // 38: mddb.SearchRequest.FilterMetaEntry.value:type_name -> mddb.MetaValues
+	1,  // 39: mddb.ExportRequest.FilterMetaEntry.value:type_name -> mddb.MetaValues
+	1,  // 40: mddb.UpdateDocument.MetaEntry.value:type_name -> mddb.MetaValues
+	1,  // 41: mddb.CollectionSchema.EnumsEntry.value:type_name -> mddb.MetaValues
+	2,  // 42: mddb.MDDB.Add:input_type -> mddb.AddRequest
+	3,  // 43: mddb.MDDB.Patch:input_type -> mddb.PatchRequest
+	5,  // 44: mddb.MDDB.Transaction:input_type -> mddb.TransactionRequest
+	10, // 45: mddb.MDDB.AddBatch:input_type -> mddb.AddBatchRequest
+	44, // 46: mddb.MDDB.UpdateBatch:input_type -> mddb.UpdateBatchRequest
+	47, // 47: mddb.MDDB.DeleteBatch:input_type -> mddb.DeleteBatchRequest
+	13, // 48: mddb.MDDB.Get:input_type -> mddb.GetRequest
+	14, // 49: mddb.MDDB.Search:input_type -> mddb.SearchRequest
+	16, // 50: mddb.MDDB.Export:input_type -> mddb.ExportRequest
+	18, // 51: mddb.MDDB.Backup:input_type -> mddb.BackupRequest
+	20, // 52: mddb.MDDB.StreamBackup:input_type -> mddb.StreamBackupRequest
+	23, // 53: mddb.MDDB.Restore:input_type -> mddb.RestoreRequest
+	24, // 54: mddb.MDDB.StreamRestore:input_type -> mddb.RestoreChunk
+	26, // 55: mddb.MDDB.Dump:input_type -> mddb.DumpRequest
+	30, // 56: mddb.MDDB.Load:input_type -> mddb.LoadChunk
+	33, // 57: mddb.MDDB.Truncate:input_type -> mddb.TruncateRequest
+	35, // 58: mddb.MDDB.Fsck:input_type -> mddb.FsckRequest
+	38, // 59: mddb.MDDB.Compact:input_type -> mddb.CompactRequest
+	40, // 60: mddb.MDDB.Stats:input_type -> mddb.StatsRequest
+	50, // 61: mddb.MDDB.SetSchema:input_type -> mddb.CollectionSchema
+	51, // 62: mddb.MDDB.GetSchema:input_type -> mddb.SchemaRequest
+	51, // 63: mddb.MDDB.DeleteSchema:input_type -> mddb.SchemaRequest
+	53, // 64: mddb.MDDB.CreateCollection:input_type -> mddb.CollectionInfo
+	53, // 65: mddb.MDDB.UpdateCollection:input_type -> mddb.CollectionInfo
+	55, // 66: mddb.MDDB.ListCollections:input_type -> mddb.ListCollectionsRequest
+	57, // 67: mddb.MDDB.DescribeCollection:input_type -> mddb.CollectionNameRequest
+	58, // 68: mddb.MDDB.RenameCollection:input_type -> mddb.CopyCollectionRequest
+	58, // 69: mddb.MDDB.CloneCollection:input_type -> mddb.CopyCollectionRequest
+	60, // 70: mddb.MDDB.DeleteCollection:input_type -> mddb.DeleteCollectionRequest
+	63, // 71: mddb.MDDB.Import:input_type -> mddb.ImportChunk
+	65, // 72: mddb.MDDB.Delete:input_type -> mddb.DeleteRequest
+	67, // 73: mddb.MDDB.Health:input_type -> mddb.HealthRequest
+	69, // 74: mddb.MDDB.CreateAPIKey:input_type -> mddb.CreateAPIKeyRequest
+	71, // 75: mddb.MDDB.ListAPIKeys:input_type -> mddb.ListAPIKeysRequest
+	73, // 76: mddb.MDDB.DeleteAPIKey:input_type -> mddb.DeleteAPIKeyRequest
+	75, // 77: mddb.MDDB.WhoAmI:input_type -> mddb.WhoAmIRequest
+	0,  // 78: mddb.MDDB.Add:output_type -> mddb.Document
+	0,  // 79: mddb.MDDB.Patch:output_type -> mddb.Document
+	8,  // 80: mddb.MDDB.Transaction:output_type -> mddb.TransactionResponse
+	12, // 81: mddb.MDDB.AddBatch:output_type -> mddb.AddBatchResponse
+	46, // 82: mddb.MDDB.UpdateBatch:output_type -> mddb.UpdateBatchResponse
+	49, // 83: mddb.MDDB.DeleteBatch:output_type -> mddb.DeleteBatchResponse
+	0,  // 84: mddb.MDDB.Get:output_type -> mddb.Document
+	15, // 85: mddb.MDDB.Search:output_type -> mddb.SearchResponse
+	17, // 86: mddb.MDDB.Export:output_type -> mddb.ExportChunk
+	19, // 87: mddb.MDDB.Backup:output_type -> mddb.BackupResponse
+	21, // 88: mddb.MDDB.StreamBackup:output_type -> mddb.BackupChunk
+	25, // 89: mddb.MDDB.Restore:output_type -> mddb.RestoreResponse
+	25, // 90: mddb.MDDB.StreamRestore:output_type -> mddb.RestoreResponse
+	27, // 91: mddb.MDDB.Dump:output_type -> mddb.DumpChunk
+	31, // 92: mddb.MDDB.Load:output_type -> mddb.LoadResponse
+	34, // 93: mddb.MDDB.Truncate:output_type -> mddb.TruncateResponse
+	36, // 94: mddb.MDDB.Fsck:output_type -> mddb.FsckResponse
+	39, // 95: mddb.MDDB.Compact:output_type -> mddb.CompactResponse
+	41, // 96: mddb.MDDB.Stats:output_type -> mddb.StatsResponse
+	50, // 97: mddb.MDDB.SetSchema:output_type -> mddb.CollectionSchema
+	50, // 98: mddb.MDDB.GetSchema:output_type -> mddb.CollectionSchema
+	52, // 99: mddb.MDDB.DeleteSchema:output_type -> mddb.DeleteSchemaResponse
+	53, // 100: mddb.MDDB.CreateCollection:output_type -> mddb.CollectionInfo
+	53, // 101: mddb.MDDB.UpdateCollection:output_type -> mddb.CollectionInfo
+	56, // 102: mddb.MDDB.ListCollections:output_type -> mddb.ListCollectionsResponse
+	54, // 103: mddb.MDDB.DescribeCollection:output_type -> mddb.CollectionDescription
+	59, // 104: mddb.MDDB.RenameCollection:output_type -> mddb.CopyCollectionResponse
+	59, // 105: mddb.MDDB.CloneCollection:output_type -> mddb.CopyCollectionResponse
+	61, // 106: mddb.MDDB.DeleteCollection:output_type -> mddb.DeleteCollectionResponse
+	64, // 107: mddb.MDDB.Import:output_type -> mddb.ImportResponse
+	66, // 108: mddb.MDDB.Delete:output_type -> mddb.DeleteResponse
+	68, // 109: mddb.MDDB.Health:output_type -> mddb.HealthResponse
+	70, // 110: mddb.MDDB.CreateAPIKey:output_type -> mddb.APIKey
+	72, // 111: mddb.MDDB.ListAPIKeys:output_type -> mddb.ListAPIKeysResponse
+	74, // 112: mddb.MDDB.DeleteAPIKey:output_type -> mddb.DeleteAPIKeyResponse
+	76, // 113: mddb.MDDB.WhoAmI:output_type -> mddb.WhoAmIResponse
+	78, // [78:114] is the sub-list for method output_type
+	42, // [42:78] is the sub-list for method input_type
+	42, // [42:42] is the sub-list for extension type_name
+	42, // [42:42] is the sub-list for extension extendee
+	0,  // [0:42] is the sub-list for field type_name
 }
 
 func init() { file_proto_mddb_proto_init() }
@@ -1897,13 +5652,20 @@ func file_proto_mddb_proto_init() {
 	if File_proto_mddb_proto != nil {
 		return
 	}
+	file_proto_mddb_proto_msgTypes[3].OneofWrappers = []any{}
+	file_proto_mddb_proto_msgTypes[6].OneofWrappers = []any{
+		(*TxOp_Add)(nil),
+		(*TxOp_Patch)(nil),
+		(*TxOp_Delete)(nil),
+	}
+	file_proto_mddb_proto_msgTypes[7].OneofWrappers = []any{}
 	type x struct{}
 	out := protoimpl.TypeBuilder{
 		File: protoimpl.DescBuilder{
 			GoPackagePath: reflect.TypeOf(x{}).PkgPath(),
 			RawDescriptor: unsafe.Slice(unsafe.StringData(file_proto_mddb_proto_rawDesc), len(file_proto_mddb_proto_rawDesc)),
 			NumEnums:      0,
-			NumMessages:   33,
+			NumMessages:   88,
 			NumExtensions: 0,
 			NumServices:   1,
 		},
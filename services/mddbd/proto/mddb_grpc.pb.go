@@ -19,17 +19,42 @@ import (
 const _ = grpc.SupportPackageIsVersion9
 
 const (
-	MDDB_Add_FullMethodName         = "/mddb.MDDB/Add"
-	MDDB_AddBatch_FullMethodName    = "/mddb.MDDB/AddBatch"
-	MDDB_UpdateBatch_FullMethodName = "/mddb.MDDB/UpdateBatch"
-	MDDB_DeleteBatch_FullMethodName = "/mddb.MDDB/DeleteBatch"
-	MDDB_Get_FullMethodName         = "/mddb.MDDB/Get"
-	MDDB_Search_FullMethodName      = "/mddb.MDDB/Search"
-	MDDB_Export_FullMethodName      = "/mddb.MDDB/Export"
-	MDDB_Backup_FullMethodName      = "/mddb.MDDB/Backup"
-	MDDB_Restore_FullMethodName     = "/mddb.MDDB/Restore"
-	MDDB_Truncate_FullMethodName    = "/mddb.MDDB/Truncate"
-	MDDB_Stats_FullMethodName       = "/mddb.MDDB/Stats"
+	MDDB_Add_FullMethodName                = "/mddb.MDDB/Add"
+	MDDB_Patch_FullMethodName              = "/mddb.MDDB/Patch"
+	MDDB_Transaction_FullMethodName        = "/mddb.MDDB/Transaction"
+	MDDB_AddBatch_FullMethodName           = "/mddb.MDDB/AddBatch"
+	MDDB_UpdateBatch_FullMethodName        = "/mddb.MDDB/UpdateBatch"
+	MDDB_DeleteBatch_FullMethodName        = "/mddb.MDDB/DeleteBatch"
+	MDDB_Get_FullMethodName                = "/mddb.MDDB/Get"
+	MDDB_Search_FullMethodName             = "/mddb.MDDB/Search"
+	MDDB_Export_FullMethodName             = "/mddb.MDDB/Export"
+	MDDB_Backup_FullMethodName             = "/mddb.MDDB/Backup"
+	MDDB_StreamBackup_FullMethodName       = "/mddb.MDDB/StreamBackup"
+	MDDB_Restore_FullMethodName            = "/mddb.MDDB/Restore"
+	MDDB_StreamRestore_FullMethodName      = "/mddb.MDDB/StreamRestore"
+	MDDB_Dump_FullMethodName               = "/mddb.MDDB/Dump"
+	MDDB_Load_FullMethodName               = "/mddb.MDDB/Load"
+	MDDB_Truncate_FullMethodName           = "/mddb.MDDB/Truncate"
+	MDDB_Fsck_FullMethodName               = "/mddb.MDDB/Fsck"
+	MDDB_Compact_FullMethodName            = "/mddb.MDDB/Compact"
+	MDDB_Stats_FullMethodName              = "/mddb.MDDB/Stats"
+	MDDB_SetSchema_FullMethodName          = "/mddb.MDDB/SetSchema"
+	MDDB_GetSchema_FullMethodName          = "/mddb.MDDB/GetSchema"
+	MDDB_DeleteSchema_FullMethodName       = "/mddb.MDDB/DeleteSchema"
+	MDDB_CreateCollection_FullMethodName   = "/mddb.MDDB/CreateCollection"
+	MDDB_UpdateCollection_FullMethodName   = "/mddb.MDDB/UpdateCollection"
+	MDDB_ListCollections_FullMethodName    = "/mddb.MDDB/ListCollections"
+	MDDB_DescribeCollection_FullMethodName = "/mddb.MDDB/DescribeCollection"
+	MDDB_RenameCollection_FullMethodName   = "/mddb.MDDB/RenameCollection"
+	MDDB_CloneCollection_FullMethodName    = "/mddb.MDDB/CloneCollection"
+	MDDB_DeleteCollection_FullMethodName   = "/mddb.MDDB/DeleteCollection"
+	MDDB_Import_FullMethodName             = "/mddb.MDDB/Import"
+	MDDB_Delete_FullMethodName             = "/mddb.MDDB/Delete"
+	MDDB_Health_FullMethodName             = "/mddb.MDDB/Health"
+	MDDB_CreateAPIKey_FullMethodName       = "/mddb.MDDB/CreateAPIKey"
+	MDDB_ListAPIKeys_FullMethodName        = "/mddb.MDDB/ListAPIKeys"
+	MDDB_DeleteAPIKey_FullMethodName       = "/mddb.MDDB/DeleteAPIKey"
+	MDDB_WhoAmI_FullMethodName             = "/mddb.MDDB/WhoAmI"
 )
 
 // MDDBClient is the client API for MDDB service.
@@ -40,6 +65,10 @@ const (
 type MDDBClient interface {
 	// Add or update a document
 	Add(ctx context.Context, in *AddRequest, opts ...grpc.CallOption) (*Document, error)
+	// Apply meta operations and content changes to an existing document
+	Patch(ctx context.Context, in *PatchRequest, opts ...grpc.CallOption) (*Document, error)
+	// Apply add, patch and delete operations across collections atomically
+	Transaction(ctx context.Context, in *TransactionRequest, opts ...grpc.CallOption) (*TransactionResponse, error)
 	// Add or update multiple documents in a single transaction (batch)
 	AddBatch(ctx context.Context, in *AddBatchRequest, opts ...grpc.CallOption) (*AddBatchResponse, error)
 	// Update multiple documents in a single transaction (batch)
@@ -54,12 +83,57 @@ type MDDBClient interface {
 	Export(ctx context.Context, in *ExportRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[ExportChunk], error)
 	// Create database backup
 	Backup(ctx context.Context, in *BackupRequest, opts ...grpc.CallOption) (*BackupResponse, error)
+	// Stream a consistent backup of the whole database; the last chunk carries the manifest
+	StreamBackup(ctx context.Context, in *StreamBackupRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[BackupChunk], error)
 	// Restore database from backup
 	Restore(ctx context.Context, in *RestoreRequest, opts ...grpc.CallOption) (*RestoreResponse, error)
+	StreamRestore(ctx context.Context, opts ...grpc.CallOption) (grpc.ClientStreamingClient[RestoreChunk, RestoreResponse], error)
+	// Dump collections as a portable logical archive; the last chunk carries the manifest
+	Dump(ctx context.Context, in *DumpRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[DumpChunk], error)
+	// Load collections from a dump; the first chunk carries the options
+	Load(ctx context.Context, opts ...grpc.CallOption) (grpc.ClientStreamingClient[LoadChunk, LoadResponse], error)
 	// Truncate revision history
 	Truncate(ctx context.Context, in *TruncateRequest, opts ...grpc.CallOption) (*TruncateResponse, error)
+	// Check documents, key entries, meta indexes and revisions for consistency, optionally repairing them
+	Fsck(ctx context.Context, in *FsckRequest, opts ...grpc.CallOption) (*FsckResponse, error)
+	// Rewrite the database file without free pages and swap it in
+	Compact(ctx context.Context, in *CompactRequest, opts ...grpc.CallOption) (*CompactResponse, error)
 	// Get server statistics
 	Stats(ctx context.Context, in *StatsRequest, opts ...grpc.CallOption) (*StatsResponse, error)
+	// Set (create or replace) a collection schema
+	SetSchema(ctx context.Context, in *CollectionSchema, opts ...grpc.CallOption) (*CollectionSchema, error)
+	// Get a collection schema
+	GetSchema(ctx context.Context, in *SchemaRequest, opts ...grpc.CallOption) (*CollectionSchema, error)
+	// Delete a collection schema
+	DeleteSchema(ctx context.Context, in *SchemaRequest, opts ...grpc.CallOption) (*DeleteSchemaResponse, error)
+	// Register a collection with settings
+	CreateCollection(ctx context.Context, in *CollectionInfo, opts ...grpc.CallOption) (*CollectionInfo, error)
+	// Update collection settings
+	UpdateCollection(ctx context.Context, in *CollectionInfo, opts ...grpc.CallOption) (*CollectionInfo, error)
+	// List registered collections
+	ListCollections(ctx context.Context, in *ListCollectionsRequest, opts ...grpc.CallOption) (*ListCollectionsResponse, error)
+	// Describe a collection (settings and counts)
+	DescribeCollection(ctx context.Context, in *CollectionNameRequest, opts ...grpc.CallOption) (*CollectionDescription, error)
+	// Rename a collection
+	RenameCollection(ctx context.Context, in *CopyCollectionRequest, opts ...grpc.CallOption) (*CopyCollectionResponse, error)
+	// Clone a collection
+	CloneCollection(ctx context.Context, in *CopyCollectionRequest, opts ...grpc.CallOption) (*CopyCollectionResponse, error)
+	// Delete a collection with all documents, revisions and settings
+	DeleteCollection(ctx context.Context, in *DeleteCollectionRequest, opts ...grpc.CallOption) (*DeleteCollectionResponse, error)
+	// Import documents from an NDJSON, zip or tar upload (client streaming)
+	Import(ctx context.Context, opts ...grpc.CallOption) (grpc.ClientStreamingClient[ImportChunk, ImportResponse], error)
+	// Delete a single document with its revisions and index entries
+	Delete(ctx context.Context, in *DeleteRequest, opts ...grpc.CallOption) (*DeleteResponse, error)
+	// Check server health (see also the standard grpc.health.v1.Health service)
+	Health(ctx context.Context, in *HealthRequest, opts ...grpc.CallOption) (*HealthResponse, error)
+	// Create an API key; the response is the only time its secret is returned (admin)
+	CreateAPIKey(ctx context.Context, in *CreateAPIKeyRequest, opts ...grpc.CallOption) (*APIKey, error)
+	// List API keys without their secrets (admin)
+	ListAPIKeys(ctx context.Context, in *ListAPIKeysRequest, opts ...grpc.CallOption) (*ListAPIKeysResponse, error)
+	// Revoke an API key (admin)
+	DeleteAPIKey(ctx context.Context, in *DeleteAPIKeyRequest, opts ...grpc.CallOption) (*DeleteAPIKeyResponse, error)
+	// Return the authenticated caller and its roles
+	WhoAmI(ctx context.Context, in *WhoAmIRequest, opts ...grpc.CallOption) (*WhoAmIResponse, error)
 }
 
 type mDDBClient struct {
@@ -80,6 +154,26 @@ func (c *mDDBClient) Add(ctx context.Context, in *AddRequest, opts ...grpc.CallO
 	return out, nil
 }
 
+func (c *mDDBClient) Patch(ctx context.Context, in *PatchRequest, opts ...grpc.CallOption) (*Document, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(Document)
+	err := c.cc.Invoke(ctx, MDDB_Patch_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *mDDBClient) Transaction(ctx context.Context, in *TransactionRequest, opts ...grpc.CallOption) (*TransactionResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(TransactionResponse)
+	err := c.cc.Invoke(ctx, MDDB_Transaction_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 func (c *mDDBClient) AddBatch(ctx context.Context, in *AddBatchRequest, opts ...grpc.CallOption) (*AddBatchResponse, error) {
 	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
 	out := new(AddBatchResponse)
@@ -159,6 +253,25 @@ func (c *mDDBClient) Backup(ctx context.Context, in *BackupRequest, opts ...grpc
 	return out, nil
 }
 
+func (c *mDDBClient) StreamBackup(ctx context.Context, in *StreamBackupRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[BackupChunk], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &MDDB_ServiceDesc.Streams[1], MDDB_StreamBackup_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[StreamBackupRequest, BackupChunk]{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type MDDB_StreamBackupClient = grpc.ServerStreamingClient[BackupChunk]
+
 func (c *mDDBClient) Restore(ctx context.Context, in *RestoreRequest, opts ...grpc.CallOption) (*RestoreResponse, error) {
 	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
 	out := new(RestoreResponse)
@@ -169,6 +282,51 @@ func (c *mDDBClient) Restore(ctx context.Context, in *RestoreRequest, opts ...gr
 	return out, nil
 }
 
+func (c *mDDBClient) StreamRestore(ctx context.Context, opts ...grpc.CallOption) (grpc.ClientStreamingClient[RestoreChunk, RestoreResponse], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &MDDB_ServiceDesc.Streams[2], MDDB_StreamRestore_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[RestoreChunk, RestoreResponse]{ClientStream: stream}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type MDDB_StreamRestoreClient = grpc.ClientStreamingClient[RestoreChunk, RestoreResponse]
+
+func (c *mDDBClient) Dump(ctx context.Context, in *DumpRequest, opts ...grpc.CallOption) (grpc.ServerStreamingClient[DumpChunk], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &MDDB_ServiceDesc.Streams[3], MDDB_Dump_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[DumpRequest, DumpChunk]{ClientStream: stream}
+	if err := x.ClientStream.SendMsg(in); err != nil {
+		return nil, err
+	}
+	if err := x.ClientStream.CloseSend(); err != nil {
+		return nil, err
+	}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type MDDB_DumpClient = grpc.ServerStreamingClient[DumpChunk]
+
+func (c *mDDBClient) Load(ctx context.Context, opts ...grpc.CallOption) (grpc.ClientStreamingClient[LoadChunk, LoadResponse], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &MDDB_ServiceDesc.Streams[4], MDDB_Load_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[LoadChunk, LoadResponse]{ClientStream: stream}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type MDDB_LoadClient = grpc.ClientStreamingClient[LoadChunk, LoadResponse]
+
 func (c *mDDBClient) Truncate(ctx context.Context, in *TruncateRequest, opts ...grpc.CallOption) (*TruncateResponse, error) {
 	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
 	out := new(TruncateResponse)
@@ -179,6 +337,26 @@ func (c *mDDBClient) Truncate(ctx context.Context, in *TruncateRequest, opts ...
 	return out, nil
 }
 
+func (c *mDDBClient) Fsck(ctx context.Context, in *FsckRequest, opts ...grpc.CallOption) (*FsckResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(FsckResponse)
+	err := c.cc.Invoke(ctx, MDDB_Fsck_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *mDDBClient) Compact(ctx context.Context, in *CompactRequest, opts ...grpc.CallOption) (*CompactResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(CompactResponse)
+	err := c.cc.Invoke(ctx, MDDB_Compact_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 func (c *mDDBClient) Stats(ctx context.Context, in *StatsRequest, opts ...grpc.CallOption) (*StatsResponse, error) {
 	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
 	out := new(StatsResponse)
@@ -189,6 +367,179 @@ func (c *mDDBClient) Stats(ctx context.Context, in *StatsRequest, opts ...grpc.C
 	return out, nil
 }
 
+func (c *mDDBClient) SetSchema(ctx context.Context, in *CollectionSchema, opts ...grpc.CallOption) (*CollectionSchema, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(CollectionSchema)
+	err := c.cc.Invoke(ctx, MDDB_SetSchema_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *mDDBClient) GetSchema(ctx context.Context, in *SchemaRequest, opts ...grpc.CallOption) (*CollectionSchema, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(CollectionSchema)
+	err := c.cc.Invoke(ctx, MDDB_GetSchema_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *mDDBClient) DeleteSchema(ctx context.Context, in *SchemaRequest, opts ...grpc.CallOption) (*DeleteSchemaResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(DeleteSchemaResponse)
+	err := c.cc.Invoke(ctx, MDDB_DeleteSchema_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *mDDBClient) CreateCollection(ctx context.Context, in *CollectionInfo, opts ...grpc.CallOption) (*CollectionInfo, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(CollectionInfo)
+	err := c.cc.Invoke(ctx, MDDB_CreateCollection_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *mDDBClient) UpdateCollection(ctx context.Context, in *CollectionInfo, opts ...grpc.CallOption) (*CollectionInfo, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(CollectionInfo)
+	err := c.cc.Invoke(ctx, MDDB_UpdateCollection_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *mDDBClient) ListCollections(ctx context.Context, in *ListCollectionsRequest, opts ...grpc.CallOption) (*ListCollectionsResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListCollectionsResponse)
+	err := c.cc.Invoke(ctx, MDDB_ListCollections_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *mDDBClient) DescribeCollection(ctx context.Context, in *CollectionNameRequest, opts ...grpc.CallOption) (*CollectionDescription, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(CollectionDescription)
+	err := c.cc.Invoke(ctx, MDDB_DescribeCollection_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *mDDBClient) RenameCollection(ctx context.Context, in *CopyCollectionRequest, opts ...grpc.CallOption) (*CopyCollectionResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(CopyCollectionResponse)
+	err := c.cc.Invoke(ctx, MDDB_RenameCollection_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *mDDBClient) CloneCollection(ctx context.Context, in *CopyCollectionRequest, opts ...grpc.CallOption) (*CopyCollectionResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(CopyCollectionResponse)
+	err := c.cc.Invoke(ctx, MDDB_CloneCollection_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *mDDBClient) DeleteCollection(ctx context.Context, in *DeleteCollectionRequest, opts ...grpc.CallOption) (*DeleteCollectionResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(DeleteCollectionResponse)
+	err := c.cc.Invoke(ctx, MDDB_DeleteCollection_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *mDDBClient) Import(ctx context.Context, opts ...grpc.CallOption) (grpc.ClientStreamingClient[ImportChunk, ImportResponse], error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	stream, err := c.cc.NewStream(ctx, &MDDB_ServiceDesc.Streams[5], MDDB_Import_FullMethodName, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	x := &grpc.GenericClientStream[ImportChunk, ImportResponse]{ClientStream: stream}
+	return x, nil
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type MDDB_ImportClient = grpc.ClientStreamingClient[ImportChunk, ImportResponse]
+
+func (c *mDDBClient) Delete(ctx context.Context, in *DeleteRequest, opts ...grpc.CallOption) (*DeleteResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(DeleteResponse)
+	err := c.cc.Invoke(ctx, MDDB_Delete_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *mDDBClient) Health(ctx context.Context, in *HealthRequest, opts ...grpc.CallOption) (*HealthResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(HealthResponse)
+	err := c.cc.Invoke(ctx, MDDB_Health_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *mDDBClient) CreateAPIKey(ctx context.Context, in *CreateAPIKeyRequest, opts ...grpc.CallOption) (*APIKey, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(APIKey)
+	err := c.cc.Invoke(ctx, MDDB_CreateAPIKey_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *mDDBClient) ListAPIKeys(ctx context.Context, in *ListAPIKeysRequest, opts ...grpc.CallOption) (*ListAPIKeysResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(ListAPIKeysResponse)
+	err := c.cc.Invoke(ctx, MDDB_ListAPIKeys_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *mDDBClient) DeleteAPIKey(ctx context.Context, in *DeleteAPIKeyRequest, opts ...grpc.CallOption) (*DeleteAPIKeyResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(DeleteAPIKeyResponse)
+	err := c.cc.Invoke(ctx, MDDB_DeleteAPIKey_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
+func (c *mDDBClient) WhoAmI(ctx context.Context, in *WhoAmIRequest, opts ...grpc.CallOption) (*WhoAmIResponse, error) {
+	cOpts := append([]grpc.CallOption{grpc.StaticMethod()}, opts...)
+	out := new(WhoAmIResponse)
+	err := c.cc.Invoke(ctx, MDDB_WhoAmI_FullMethodName, in, out, cOpts...)
+	if err != nil {
+		return nil, err
+	}
+	return out, nil
+}
+
 // MDDBServer is the server API for MDDB service.
 // All implementations must embed UnimplementedMDDBServer
 // for forward compatibility.
@@ -197,6 +548,10 @@ func (c *mDDBClient) Stats(ctx context.Context, in *StatsRequest, opts ...grpc.C
 type MDDBServer interface {
 	// Add or update a document
 	Add(context.Context, *AddRequest) (*Document, error)
+	// Apply meta operations and content changes to an existing document
+	Patch(context.Context, *PatchRequest) (*Document, error)
+	// Apply add, patch and delete operations across collections atomically
+	Transaction(context.Context, *TransactionRequest) (*TransactionResponse, error)
 	// Add or update multiple documents in a single transaction (batch)
 	AddBatch(context.Context, *AddBatchRequest) (*AddBatchResponse, error)
 	// Update multiple documents in a single transaction (batch)
@@ -211,12 +566,57 @@ type MDDBServer interface {
 	Export(*ExportRequest, grpc.ServerStreamingServer[ExportChunk]) error
 	// Create database backup
 	Backup(context.Context, *BackupRequest) (*BackupResponse, error)
+	// Stream a consistent backup of the whole database; the last chunk carries the manifest
+	StreamBackup(*StreamBackupRequest, grpc.ServerStreamingServer[BackupChunk]) error
 	// Restore database from backup
 	Restore(context.Context, *RestoreRequest) (*RestoreResponse, error)
+	StreamRestore(grpc.ClientStreamingServer[RestoreChunk, RestoreResponse]) error
+	// Dump collections as a portable logical archive; the last chunk carries the manifest
+	Dump(*DumpRequest, grpc.ServerStreamingServer[DumpChunk]) error
+	// Load collections from a dump; the first chunk carries the options
+	Load(grpc.ClientStreamingServer[LoadChunk, LoadResponse]) error
 	// Truncate revision history
 	Truncate(context.Context, *TruncateRequest) (*TruncateResponse, error)
+	// Check documents, key entries, meta indexes and revisions for consistency, optionally repairing them
+	Fsck(context.Context, *FsckRequest) (*FsckResponse, error)
+	// Rewrite the database file without free pages and swap it in
+	Compact(context.Context, *CompactRequest) (*CompactResponse, error)
 	// Get server statistics
 	Stats(context.Context, *StatsRequest) (*StatsResponse, error)
+	// Set (create or replace) a collection schema
+	SetSchema(context.Context, *CollectionSchema) (*CollectionSchema, error)
+	// Get a collection schema
+	GetSchema(context.Context, *SchemaRequest) (*CollectionSchema, error)
+	// Delete a collection schema
+	DeleteSchema(context.Context, *SchemaRequest) (*DeleteSchemaResponse, error)
+	// Register a collection with settings
+	CreateCollection(context.Context, *CollectionInfo) (*CollectionInfo, error)
+	// Update collection settings
+	UpdateCollection(context.Context, *CollectionInfo) (*CollectionInfo, error)
+	// List registered collections
+	ListCollections(context.Context, *ListCollectionsRequest) (*ListCollectionsResponse, error)
+	// Describe a collection (settings and counts)
+	DescribeCollection(context.Context, *CollectionNameRequest) (*CollectionDescription, error)
+	// Rename a collection
+	RenameCollection(context.Context, *CopyCollectionRequest) (*CopyCollectionResponse, error)
+	// Clone a collection
+	CloneCollection(context.Context, *CopyCollectionRequest) (*CopyCollectionResponse, error)
+	// Delete a collection with all documents, revisions and settings
+	DeleteCollection(context.Context, *DeleteCollectionRequest) (*DeleteCollectionResponse, error)
+	// Import documents from an NDJSON, zip or tar upload (client streaming)
+	Import(grpc.ClientStreamingServer[ImportChunk, ImportResponse]) error
+	// Delete a single document with its revisions and index entries
+	Delete(context.Context, *DeleteRequest) (*DeleteResponse, error)
+	// Check server health (see also the standard grpc.health.v1.Health service)
+	Health(context.Context, *HealthRequest) (*HealthResponse, error)
+	// Create an API key; the response is the only time its secret is returned (admin)
+	CreateAPIKey(context.Context, *CreateAPIKeyRequest) (*APIKey, error)
+	// List API keys without their secrets (admin)
+	ListAPIKeys(context.Context, *ListAPIKeysRequest) (*ListAPIKeysResponse, error)
+	// Revoke an API key (admin)
+	DeleteAPIKey(context.Context, *DeleteAPIKeyRequest) (*DeleteAPIKeyResponse, error)
+	// Return the authenticated caller and its roles
+	WhoAmI(context.Context, *WhoAmIRequest) (*WhoAmIResponse, error)
 	mustEmbedUnimplementedMDDBServer()
 }
 
@@ -230,6 +630,12 @@ type UnimplementedMDDBServer struct{}
 func (UnimplementedMDDBServer) Add(context.Context, *AddRequest) (*Document, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method Add not implemented")
 }
+func (UnimplementedMDDBServer) Patch(context.Context, *PatchRequest) (*Document, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Patch not implemented")
+}
+func (UnimplementedMDDBServer) Transaction(context.Context, *TransactionRequest) (*TransactionResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Transaction not implemented")
+}
 func (UnimplementedMDDBServer) AddBatch(context.Context, *AddBatchRequest) (*AddBatchResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method AddBatch not implemented")
 }
@@ -251,15 +657,84 @@ func (UnimplementedMDDBServer) Export(*ExportRequest, grpc.ServerStreamingServer
 func (UnimplementedMDDBServer) Backup(context.Context, *BackupRequest) (*BackupResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method Backup not implemented")
 }
+func (UnimplementedMDDBServer) StreamBackup(*StreamBackupRequest, grpc.ServerStreamingServer[BackupChunk]) error {
+	return status.Errorf(codes.Unimplemented, "method StreamBackup not implemented")
+}
 func (UnimplementedMDDBServer) Restore(context.Context, *RestoreRequest) (*RestoreResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method Restore not implemented")
 }
+func (UnimplementedMDDBServer) StreamRestore(grpc.ClientStreamingServer[RestoreChunk, RestoreResponse]) error {
+	return status.Errorf(codes.Unimplemented, "method StreamRestore not implemented")
+}
+func (UnimplementedMDDBServer) Dump(*DumpRequest, grpc.ServerStreamingServer[DumpChunk]) error {
+	return status.Errorf(codes.Unimplemented, "method Dump not implemented")
+}
+func (UnimplementedMDDBServer) Load(grpc.ClientStreamingServer[LoadChunk, LoadResponse]) error {
+	return status.Errorf(codes.Unimplemented, "method Load not implemented")
+}
 func (UnimplementedMDDBServer) Truncate(context.Context, *TruncateRequest) (*TruncateResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method Truncate not implemented")
 }
+func (UnimplementedMDDBServer) Fsck(context.Context, *FsckRequest) (*FsckResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Fsck not implemented")
+}
+func (UnimplementedMDDBServer) Compact(context.Context, *CompactRequest) (*CompactResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Compact not implemented")
+}
 func (UnimplementedMDDBServer) Stats(context.Context, *StatsRequest) (*StatsResponse, error) {
 	return nil, status.Errorf(codes.Unimplemented, "method Stats not implemented")
 }
+func (UnimplementedMDDBServer) SetSchema(context.Context, *CollectionSchema) (*CollectionSchema, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method SetSchema not implemented")
+}
+func (UnimplementedMDDBServer) GetSchema(context.Context, *SchemaRequest) (*CollectionSchema, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method GetSchema not implemented")
+}
+func (UnimplementedMDDBServer) DeleteSchema(context.Context, *SchemaRequest) (*DeleteSchemaResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method DeleteSchema not implemented")
+}
+func (UnimplementedMDDBServer) CreateCollection(context.Context, *CollectionInfo) (*CollectionInfo, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CreateCollection not implemented")
+}
+func (UnimplementedMDDBServer) UpdateCollection(context.Context, *CollectionInfo) (*CollectionInfo, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method UpdateCollection not implemented")
+}
+func (UnimplementedMDDBServer) ListCollections(context.Context, *ListCollectionsRequest) (*ListCollectionsResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListCollections not implemented")
+}
+func (UnimplementedMDDBServer) DescribeCollection(context.Context, *CollectionNameRequest) (*CollectionDescription, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method DescribeCollection not implemented")
+}
+func (UnimplementedMDDBServer) RenameCollection(context.Context, *CopyCollectionRequest) (*CopyCollectionResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method RenameCollection not implemented")
+}
+func (UnimplementedMDDBServer) CloneCollection(context.Context, *CopyCollectionRequest) (*CopyCollectionResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CloneCollection not implemented")
+}
+func (UnimplementedMDDBServer) DeleteCollection(context.Context, *DeleteCollectionRequest) (*DeleteCollectionResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method DeleteCollection not implemented")
+}
+func (UnimplementedMDDBServer) Import(grpc.ClientStreamingServer[ImportChunk, ImportResponse]) error {
+	return status.Errorf(codes.Unimplemented, "method Import not implemented")
+}
+func (UnimplementedMDDBServer) Delete(context.Context, *DeleteRequest) (*DeleteResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Delete not implemented")
+}
+func (UnimplementedMDDBServer) Health(context.Context, *HealthRequest) (*HealthResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method Health not implemented")
+}
+func (UnimplementedMDDBServer) CreateAPIKey(context.Context, *CreateAPIKeyRequest) (*APIKey, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method CreateAPIKey not implemented")
+}
+func (UnimplementedMDDBServer) ListAPIKeys(context.Context, *ListAPIKeysRequest) (*ListAPIKeysResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method ListAPIKeys not implemented")
+}
+func (UnimplementedMDDBServer) DeleteAPIKey(context.Context, *DeleteAPIKeyRequest) (*DeleteAPIKeyResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method DeleteAPIKey not implemented")
+}
+func (UnimplementedMDDBServer) WhoAmI(context.Context, *WhoAmIRequest) (*WhoAmIResponse, error) {
+	return nil, status.Errorf(codes.Unimplemented, "method WhoAmI not implemented")
+}
 func (UnimplementedMDDBServer) mustEmbedUnimplementedMDDBServer() {}
 func (UnimplementedMDDBServer) testEmbeddedByValue()              {}
 
@@ -299,6 +774,42 @@ func _MDDB_Add_Handler(srv interface{}, ctx context.Context, dec func(interface{
 	return interceptor(ctx, in, info, handler)
 }
 
+func _MDDB_Patch_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(PatchRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MDDBServer).Patch(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: MDDB_Patch_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MDDBServer).Patch(ctx, req.(*PatchRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _MDDB_Transaction_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(TransactionRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MDDBServer).Transaction(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: MDDB_Transaction_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MDDBServer).Transaction(ctx, req.(*TransactionRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 func _MDDB_AddBatch_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
 	in := new(AddBatchRequest)
 	if err := dec(in); err != nil {
@@ -418,6 +929,17 @@ func _MDDB_Backup_Handler(srv interface{}, ctx context.Context, dec func(interfa
 	return interceptor(ctx, in, info, handler)
 }
 
+func _MDDB_StreamBackup_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(StreamBackupRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(MDDBServer).StreamBackup(m, &grpc.GenericServerStream[StreamBackupRequest, BackupChunk]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type MDDB_StreamBackupServer = grpc.ServerStreamingServer[BackupChunk]
+
 func _MDDB_Restore_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
 	in := new(RestoreRequest)
 	if err := dec(in); err != nil {
@@ -436,6 +958,31 @@ func _MDDB_Restore_Handler(srv interface{}, ctx context.Context, dec func(interf
 	return interceptor(ctx, in, info, handler)
 }
 
+func _MDDB_StreamRestore_Handler(srv interface{}, stream grpc.ServerStream) error {
+	return srv.(MDDBServer).StreamRestore(&grpc.GenericServerStream[RestoreChunk, RestoreResponse]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type MDDB_StreamRestoreServer = grpc.ClientStreamingServer[RestoreChunk, RestoreResponse]
+
+func _MDDB_Dump_Handler(srv interface{}, stream grpc.ServerStream) error {
+	m := new(DumpRequest)
+	if err := stream.RecvMsg(m); err != nil {
+		return err
+	}
+	return srv.(MDDBServer).Dump(m, &grpc.GenericServerStream[DumpRequest, DumpChunk]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type MDDB_DumpServer = grpc.ServerStreamingServer[DumpChunk]
+
+func _MDDB_Load_Handler(srv interface{}, stream grpc.ServerStream) error {
+	return srv.(MDDBServer).Load(&grpc.GenericServerStream[LoadChunk, LoadResponse]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type MDDB_LoadServer = grpc.ClientStreamingServer[LoadChunk, LoadResponse]
+
 func _MDDB_Truncate_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
 	in := new(TruncateRequest)
 	if err := dec(in); err != nil {
@@ -454,6 +1001,42 @@ func _MDDB_Truncate_Handler(srv interface{}, ctx context.Context, dec func(inter
 	return interceptor(ctx, in, info, handler)
 }
 
+func _MDDB_Fsck_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(FsckRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MDDBServer).Fsck(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: MDDB_Fsck_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MDDBServer).Fsck(ctx, req.(*FsckRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _MDDB_Compact_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CompactRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MDDBServer).Compact(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: MDDB_Compact_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MDDBServer).Compact(ctx, req.(*CompactRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 func _MDDB_Stats_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
 	in := new(StatsRequest)
 	if err := dec(in); err != nil {
@@ -472,6 +1055,301 @@ func _MDDB_Stats_Handler(srv interface{}, ctx context.Context, dec func(interfac
 	return interceptor(ctx, in, info, handler)
 }
 
+func _MDDB_SetSchema_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CollectionSchema)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MDDBServer).SetSchema(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: MDDB_SetSchema_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MDDBServer).SetSchema(ctx, req.(*CollectionSchema))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _MDDB_GetSchema_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SchemaRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MDDBServer).GetSchema(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: MDDB_GetSchema_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MDDBServer).GetSchema(ctx, req.(*SchemaRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _MDDB_DeleteSchema_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(SchemaRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MDDBServer).DeleteSchema(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: MDDB_DeleteSchema_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MDDBServer).DeleteSchema(ctx, req.(*SchemaRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _MDDB_CreateCollection_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CollectionInfo)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MDDBServer).CreateCollection(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: MDDB_CreateCollection_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MDDBServer).CreateCollection(ctx, req.(*CollectionInfo))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _MDDB_UpdateCollection_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CollectionInfo)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MDDBServer).UpdateCollection(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: MDDB_UpdateCollection_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MDDBServer).UpdateCollection(ctx, req.(*CollectionInfo))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _MDDB_ListCollections_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListCollectionsRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MDDBServer).ListCollections(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: MDDB_ListCollections_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MDDBServer).ListCollections(ctx, req.(*ListCollectionsRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _MDDB_DescribeCollection_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CollectionNameRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MDDBServer).DescribeCollection(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: MDDB_DescribeCollection_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MDDBServer).DescribeCollection(ctx, req.(*CollectionNameRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _MDDB_RenameCollection_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CopyCollectionRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MDDBServer).RenameCollection(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: MDDB_RenameCollection_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MDDBServer).RenameCollection(ctx, req.(*CopyCollectionRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _MDDB_CloneCollection_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CopyCollectionRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MDDBServer).CloneCollection(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: MDDB_CloneCollection_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MDDBServer).CloneCollection(ctx, req.(*CopyCollectionRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _MDDB_DeleteCollection_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DeleteCollectionRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MDDBServer).DeleteCollection(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: MDDB_DeleteCollection_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MDDBServer).DeleteCollection(ctx, req.(*DeleteCollectionRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _MDDB_Import_Handler(srv interface{}, stream grpc.ServerStream) error {
+	return srv.(MDDBServer).Import(&grpc.GenericServerStream[ImportChunk, ImportResponse]{ServerStream: stream})
+}
+
+// This type alias is provided for backwards compatibility with existing code that references the prior non-generic stream type by name.
+type MDDB_ImportServer = grpc.ClientStreamingServer[ImportChunk, ImportResponse]
+
+func _MDDB_Delete_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DeleteRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MDDBServer).Delete(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: MDDB_Delete_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MDDBServer).Delete(ctx, req.(*DeleteRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _MDDB_Health_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(HealthRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MDDBServer).Health(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: MDDB_Health_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MDDBServer).Health(ctx, req.(*HealthRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _MDDB_CreateAPIKey_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(CreateAPIKeyRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MDDBServer).CreateAPIKey(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: MDDB_CreateAPIKey_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MDDBServer).CreateAPIKey(ctx, req.(*CreateAPIKeyRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _MDDB_ListAPIKeys_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(ListAPIKeysRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MDDBServer).ListAPIKeys(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: MDDB_ListAPIKeys_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MDDBServer).ListAPIKeys(ctx, req.(*ListAPIKeysRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _MDDB_DeleteAPIKey_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(DeleteAPIKeyRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MDDBServer).DeleteAPIKey(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: MDDB_DeleteAPIKey_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MDDBServer).DeleteAPIKey(ctx, req.(*DeleteAPIKeyRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
+func _MDDB_WhoAmI_Handler(srv interface{}, ctx context.Context, dec func(interface{}) error, interceptor grpc.UnaryServerInterceptor) (interface{}, error) {
+	in := new(WhoAmIRequest)
+	if err := dec(in); err != nil {
+		return nil, err
+	}
+	if interceptor == nil {
+		return srv.(MDDBServer).WhoAmI(ctx, in)
+	}
+	info := &grpc.UnaryServerInfo{
+		Server:     srv,
+		FullMethod: MDDB_WhoAmI_FullMethodName,
+	}
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		return srv.(MDDBServer).WhoAmI(ctx, req.(*WhoAmIRequest))
+	}
+	return interceptor(ctx, in, info, handler)
+}
+
 // MDDB_ServiceDesc is the grpc.ServiceDesc for MDDB service.
 // It's only intended for direct use with grpc.RegisterService,
 // and not to be introspected or modified (even as a copy)
@@ -483,6 +1361,14 @@ var MDDB_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "Add",
 			Handler:    _MDDB_Add_Handler,
 		},
+		{
+			MethodName: "Patch",
+			Handler:    _MDDB_Patch_Handler,
+		},
+		{
+			MethodName: "Transaction",
+			Handler:    _MDDB_Transaction_Handler,
+		},
 		{
 			MethodName: "AddBatch",
 			Handler:    _MDDB_AddBatch_Handler,
@@ -515,10 +1401,82 @@ var MDDB_ServiceDesc = grpc.ServiceDesc{
 			MethodName: "Truncate",
 			Handler:    _MDDB_Truncate_Handler,
 		},
+		{
+			MethodName: "Fsck",
+			Handler:    _MDDB_Fsck_Handler,
+		},
+		{
+			MethodName: "Compact",
+			Handler:    _MDDB_Compact_Handler,
+		},
 		{
 			MethodName: "Stats",
 			Handler:    _MDDB_Stats_Handler,
 		},
+		{
+			MethodName: "SetSchema",
+			Handler:    _MDDB_SetSchema_Handler,
+		},
+		{
+			MethodName: "GetSchema",
+			Handler:    _MDDB_GetSchema_Handler,
+		},
+		{
+			MethodName: "DeleteSchema",
+			Handler:    _MDDB_DeleteSchema_Handler,
+		},
+		{
+			MethodName: "CreateCollection",
+			Handler:    _MDDB_CreateCollection_Handler,
+		},
+		{
+			MethodName: "UpdateCollection",
+			Handler:    _MDDB_UpdateCollection_Handler,
+		},
+		{
+			MethodName: "ListCollections",
+			Handler:    _MDDB_ListCollections_Handler,
+		},
+		{
+			MethodName: "DescribeCollection",
+			Handler:    _MDDB_DescribeCollection_Handler,
+		},
+		{
+			MethodName: "RenameCollection",
+			Handler:    _MDDB_RenameCollection_Handler,
+		},
+		{
+			MethodName: "CloneCollection",
+			Handler:    _MDDB_CloneCollection_Handler,
+		},
+		{
+			MethodName: "DeleteCollection",
+			Handler:    _MDDB_DeleteCollection_Handler,
+		},
+		{
+			MethodName: "Delete",
+			Handler:    _MDDB_Delete_Handler,
+		},
+		{
+			MethodName: "Health",
+			Handler:    _MDDB_Health_Handler,
+		},
+		{
+			MethodName: "CreateAPIKey",
+			Handler:    _MDDB_CreateAPIKey_Handler,
+		},
+		{
+			MethodName: "ListAPIKeys",
+			Handler:    _MDDB_ListAPIKeys_Handler,
+		},
+		{
+			MethodName: "DeleteAPIKey",
+			Handler:    _MDDB_DeleteAPIKey_Handler,
+		},
+		{
+			MethodName: "WhoAmI",
+			Handler:    _MDDB_WhoAmI_Handler,
+		},
 	},
 	Streams: []grpc.StreamDesc{
 		{
@@ -526,6 +1484,31 @@ var MDDB_ServiceDesc = grpc.ServiceDesc{
 			Handler:       _MDDB_Export_Handler,
 			ServerStreams: true,
 		},
+		{
+			StreamName:    "StreamBackup",
+			Handler:       _MDDB_StreamBackup_Handler,
+			ServerStreams: true,
+		},
+		{
+			StreamName:    "StreamRestore",
+			Handler:       _MDDB_StreamRestore_Handler,
+			ClientStreams: true,
+		},
+		{
+			StreamName:    "Dump",
+			Handler:       _MDDB_Dump_Handler,
+			ServerStreams: true,
+		},
+		{
+			StreamName:    "Load",
+			Handler:       _MDDB_Load_Handler,
+			ClientStreams: true,
+		},
+		{
+			StreamName:    "Import",
+			Handler:       _MDDB_Import_Handler,
+			ClientStreams: true,
+		},
 	},
 	Metadata: "proto/mddb.proto",
 }
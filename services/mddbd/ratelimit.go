@@ -0,0 +1,145 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"math"
+	"net"
+	"net/http"
+	"strconv"
+	"sync"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+)
+
+// errRateLimited rejects requests over the client's rate limit
+var errRateLimited = errors.New("rate limit exceeded, retry shortly")
+
+// rateLimitExempt lists the HTTP paths that monitoring polls and that are never limited.
+// The gRPC health service is exempt too since it isn't an mddb method.
+var rateLimitExempt = map[string]bool{
+	"/health":    true,
+	"/v1/health": true,
+	"/ready":     true,
+	"/v1/ready":  true,
+	"/metrics":   true,
+}
+
+// rateLimiter keeps a token bucket per client address. Its limit changes on config reload.
+type rateLimiter struct {
+	mu        sync.Mutex
+	rate      float64 // tokens per second; 0 disables the limit
+	burst     float64
+	buckets   map[string]*tokenBucket
+	lastSweep time.Time
+}
+
+type tokenBucket struct {
+	tokens float64
+	last   time.Time
+}
+
+// configure sets the limit; clients start over with full buckets
+func (l *rateLimiter) configure(c RateLimitConfig) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	l.rate, l.burst = c.RequestsPerSecond, float64(c.Burst)
+	l.buckets = make(map[string]*tokenBucket)
+}
+
+// allow takes a token from client's bucket. When it is empty it returns false and how
+// long until the next token.
+func (l *rateLimiter) allow(client string) (bool, time.Duration) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if l.rate <= 0 {
+		return true, 0
+	}
+	now := time.Now()
+	if now.Sub(l.lastSweep) > time.Minute {
+		// Forget clients whose buckets have refilled
+		for c, b := range l.buckets {
+			if b.tokens+now.Sub(b.last).Seconds()*l.rate >= l.burst {
+				delete(l.buckets, c)
+			}
+		}
+		l.lastSweep = now
+	}
+	b := l.buckets[client]
+	if b == nil {
+		b = &tokenBucket{tokens: l.burst, last: now}
+		l.buckets[client] = b
+	}
+	b.tokens = min(l.burst, b.tokens+now.Sub(b.last).Seconds()*l.rate)
+	b.last = now
+	if b.tokens < 1 {
+		return false, time.Duration((1 - b.tokens) / l.rate * float64(time.Second))
+	}
+	b.tokens--
+	return true, 0
+}
+
+// retryAfter formats wait in whole seconds for Retry-After
+func retryAfter(wait time.Duration) string {
+	return strconv.Itoa(max(1, int(math.Ceil(wait.Seconds()))))
+}
+
+// clientHost strips the port from a remote address
+func clientHost(addr string) string {
+	if host, _, err := net.SplitHostPort(addr); err == nil {
+		return host
+	}
+	return addr
+}
+
+// rateLimit is the HTTP side of the limiter
+func (s *Server) rateLimit(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if rateLimitExempt[r.URL.Path] {
+			next.ServeHTTP(w, r)
+			return
+		}
+		if ok, wait := s.limiter.allow(clientHost(r.RemoteAddr)); !ok {
+			w.Header().Set("Retry-After", retryAfter(wait))
+			writeError(w, errRateLimited, codes.ResourceExhausted)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// allowRPC applies the limiter to an mddb RPC
+func (s *Server) allowRPC(ctx context.Context, fullMethod string) error {
+	if _, ok := mddbMethod(fullMethod); !ok {
+		return nil
+	}
+	client := ""
+	if p, ok := peer.FromContext(ctx); ok {
+		client = clientHost(p.Addr.String())
+	}
+	if ok, wait := s.limiter.allow(client); !ok {
+		_ = grpc.SetHeader(ctx, metadata.Pairs("retry-after", retryAfter(wait)))
+		return grpcError(errRateLimited, codes.ResourceExhausted)
+	}
+	return nil
+}
+
+// unaryRateLimit is the gRPC side of the limiter for unary RPCs
+func (s *Server) unaryRateLimit(ctx context.Context, req any, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (any, error) {
+	if err := s.allowRPC(ctx, info.FullMethod); err != nil {
+		return nil, err
+	}
+	return handler(ctx, req)
+}
+
+// streamRateLimit is the gRPC side of the limiter for streaming RPCs
+func (s *Server) streamRateLimit(srv any, ss grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+	if err := s.allowRPC(ss.Context(), info.FullMethod); err != nil {
+		return err
+	}
+	return handler(srv, ss)
+}
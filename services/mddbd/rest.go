@@ -0,0 +1,265 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"mime"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+
+	json "github.com/goccy/go-json"
+	"github.com/tradik/mddb/services/mddbd/storage"
+	"google.golang.org/grpc/codes"
+)
+
+// maxDocumentBodySize limits the body of a single document PUT
+const maxDocumentBodySize = 32 << 20
+
+// DocumentBody is the JSON body of PUT /v1/collections/{collection}/docs/{key}/{lang}
+type DocumentBody struct {
+	Meta      map[string][]string `json:"meta"`
+	ContentMD string              `json:"contentMd"`
+}
+
+// PatchBody is the JSON body of PATCH /v1/collections/{collection}/docs/{key}/{lang}
+type PatchBody struct {
+	Meta      []storage.MetaPatch `json:"meta"`
+	ContentMD *string             `json:"contentMd"`
+	AppendMD  string              `json:"appendMd"`
+}
+
+// registerRESTRoutes adds the resource-oriented document routes next to the RPC-style endpoints
+func (s *Server) registerRESTRoutes(mux *http.ServeMux) {
+	mux.HandleFunc("GET /v1/collections/{collection}/docs", s.authorize(RoleRead, pathCollection, s.handleDocList))
+	mux.HandleFunc("GET /v1/collections/{collection}/docs/{key}/{lang}", s.authorize(RoleRead, pathCollection, s.handleDocGet))
+	mux.HandleFunc("PUT /v1/collections/{collection}/docs/{key}/{lang}", s.guardWrite(s.authorize(RoleWrite, pathCollection, s.handleDocPut)))
+	mux.HandleFunc("PATCH /v1/collections/{collection}/docs/{key}/{lang}", s.guardWrite(s.authorize(RoleWrite, pathCollection, s.handleDocPatch)))
+	mux.HandleFunc("DELETE /v1/collections/{collection}/docs/{key}/{lang}", s.guardWrite(s.authorize(RoleWrite, pathCollection, s.handleDocDelete)))
+}
+
+// etagMatches reports whether an If-Match / If-None-Match header matches etag ("*" matches any existing document).
+// If-None-Match compares weakly, ignoring W/; If-Match compares strongly, so a weak tag never matches it.
+func etagMatches(header, etag string, exists, weak bool) bool {
+	for _, t := range strings.Split(header, ",") {
+		t = strings.TrimSpace(t)
+		if weak {
+			t = strings.TrimPrefix(t, "W/")
+		}
+		if (t == "*" && exists) || (exists && t == etag) {
+			return true
+		}
+	}
+	return false
+}
+
+// metaQuery collects meta.<key>=<value> query parameters (repeat a parameter to OR values)
+func metaQuery(q url.Values) map[string][]string {
+	meta := make(map[string][]string)
+	for k, vals := range q {
+		if mk, found := strings.CutPrefix(k, "meta."); found && mk != "" {
+			meta[mk] = append(meta[mk], vals...)
+		}
+	}
+	return meta
+}
+
+// docPathParams returns the collection, key and lang of a document route (wildcards are already unescaped)
+func docPathParams(r *http.Request) (collection, key, lang string) {
+	return r.PathValue("collection"), r.PathValue("key"), r.PathValue("lang")
+}
+
+func docLocation(d *storage.Doc, collection string) string {
+	return "/v1/collections/" + url.PathEscape(collection) + "/docs/" + url.PathEscape(d.Key) + "/" + url.PathEscape(d.Lang)
+}
+
+// --- HTTP handlers
+
+// handleDocList lists documents: GET /v1/collections/{collection}/docs?meta.tag=go&sort=updatedAt&asc=true&limit=50&offset=0
+func (s *Server) handleDocList(w http.ResponseWriter, r *http.Request) {
+	collection := r.PathValue("collection")
+	if s.DB.Collections.Get(collection) == nil {
+		writeError(w, storage.ErrCollectionNotFound, codes.Internal)
+		return
+	}
+
+	q := r.URL.Query()
+	req := SearchRequest{
+		Collection:  collection,
+		FilterMeta:  metaQuery(q),
+		Sort:        q.Get("sort"),
+		Consistency: q.Get("consistency"),
+	}
+	var err error
+	if v := q.Get("asc"); v != "" {
+		if req.Asc, err = strconv.ParseBool(v); err != nil {
+			bad(w, fmt.Errorf("invalid asc: %w", err))
+			return
+		}
+	}
+	if v := q.Get("limit"); v != "" {
+		if req.Limit, err = strconv.Atoi(v); err != nil {
+			bad(w, fmt.Errorf("invalid limit: %w", err))
+			return
+		}
+	}
+	if v := q.Get("offset"); v != "" {
+		if req.Offset, err = strconv.Atoi(v); err != nil || req.Offset < 0 {
+			bad(w, fmt.Errorf("invalid offset: %s", v))
+			return
+		}
+	}
+
+	out, _, err := s.DB.Search(storage.SearchQuery(req))
+	if err != nil {
+		writeError(w, err, codes.Internal)
+		return
+	}
+	ok(w, out)
+}
+
+// handleDocGet returns one document with an ETag; env.<name>=<value> query parameters fill %%name%% templates
+func (s *Server) handleDocGet(w http.ResponseWriter, r *http.Request) {
+	collection, key, lang := docPathParams(r)
+
+	doc, err := s.DB.Get(collection, key, lang)
+	if err != nil {
+		writeError(w, err, codes.Internal)
+		return
+	}
+
+	etag := doc.ETag()
+	w.Header().Set("ETag", etag)
+	if inm := r.Header.Get("If-None-Match"); inm != "" && etagMatches(inm, etag, true, true) {
+		w.WriteHeader(http.StatusNotModified)
+		return
+	}
+
+	env := make(map[string]string)
+	for k, vals := range r.URL.Query() {
+		if name, found := strings.CutPrefix(k, "env."); found && len(vals) > 0 {
+			env[name] = vals[0]
+		}
+	}
+	if len(env) > 0 && doc.ContentMD != "" {
+		doc.ContentMD = applyEnv(doc.ContentMD, env)
+	}
+	ok(w, doc)
+}
+
+// handleDocPut creates or replaces a document. The body is a DocumentBody, or raw markdown
+// (Content-Type: text/markdown) with meta taken from meta.<key>=<value> query parameters.
+// If-Match makes the write conditional on the current ETag; If-None-Match: * makes it create-only.
+func (s *Server) handleDocPut(w http.ResponseWriter, r *http.Request) {
+	collection, key, lang := docPathParams(r)
+
+	r.Body = http.MaxBytesReader(w, r.Body, maxDocumentBodySize)
+	var body DocumentBody
+	mt, _, _ := mime.ParseMediaType(r.Header.Get("Content-Type"))
+	if mt == "text/markdown" || mt == "text/plain" {
+		b, err := io.ReadAll(r.Body)
+		if err != nil {
+			bad(w, err)
+			return
+		}
+		body.ContentMD = string(b)
+		body.Meta = metaQuery(r.URL.Query())
+	} else if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		bad(w, err)
+		return
+	}
+	if body.Meta == nil {
+		body.Meta = map[string][]string{}
+	}
+
+	ifMatch := r.Header.Get("If-Match")
+	ifNoneMatch := r.Header.Get("If-None-Match")
+	var check func(*storage.Doc) error
+	if ifMatch != "" || ifNoneMatch != "" {
+		check = func(existing *storage.Doc) error {
+			etag := ""
+			if existing != nil {
+				etag = existing.ETag()
+			}
+			if ifMatch != "" && !etagMatches(ifMatch, etag, existing != nil, false) {
+				return errPreconditionFailed
+			}
+			if ifNoneMatch != "" && etagMatches(ifNoneMatch, etag, existing != nil, true) {
+				return errPreconditionFailed
+			}
+			return nil
+		}
+	}
+
+	saved, created, err := s.DB.Put(storage.AddRequest{
+		Collection: collection, Key: key, Lang: lang,
+		Meta: body.Meta, ContentMD: body.ContentMD, SaveRevision: true,
+	}, check)
+	if err != nil {
+		writeError(w, err, codes.Internal)
+		return
+	}
+	if created {
+		s.runHooks(hookAdd, collection, saved)
+	} else {
+		s.runHooks(hookUpdate, collection, saved)
+	}
+
+	w.Header().Set("ETag", saved.ETag())
+	if !created {
+		ok(w, saved)
+		return
+	}
+	w.Header().Set("Location", docLocation(saved, collection))
+	b, _ := json.Marshal(saved)
+	w.WriteHeader(http.StatusCreated)
+	_, _ = w.Write(b)
+}
+
+// handleDocPatch applies a PatchBody to an existing document. If-Match makes it conditional
+// on the current ETag.
+func (s *Server) handleDocPatch(w http.ResponseWriter, r *http.Request) {
+	collection, key, lang := docPathParams(r)
+
+	r.Body = http.MaxBytesReader(w, r.Body, maxDocumentBodySize)
+	var body PatchBody
+	if err := json.NewDecoder(r.Body).Decode(&body); err != nil {
+		bad(w, err)
+		return
+	}
+
+	var check func(*storage.Doc) error
+	if ifMatch := r.Header.Get("If-Match"); ifMatch != "" {
+		check = func(existing *storage.Doc) error {
+			if !etagMatches(ifMatch, existing.ETag(), true, false) {
+				return errPreconditionFailed
+			}
+			return nil
+		}
+	}
+
+	saved, err := s.DB.Patch(storage.PatchRequest{
+		Collection: collection, Key: key, Lang: lang,
+		Meta: body.Meta, ContentMD: body.ContentMD, AppendMD: body.AppendMD, SaveRevision: true,
+	}, check)
+	if err != nil {
+		writeError(w, err, codes.Internal)
+		return
+	}
+	s.runHooks(hookUpdate, collection, saved)
+
+	w.Header().Set("ETag", saved.ETag())
+	ok(w, saved)
+}
+
+// handleDocDelete removes a document with its revisions and index entries
+func (s *Server) handleDocDelete(w http.ResponseWriter, r *http.Request) {
+	collection, key, lang := docPathParams(r)
+
+	if err := s.DB.Delete(collection, key, lang); err != nil {
+		writeError(w, err, codes.Internal)
+		return
+	}
+	w.WriteHeader(http.StatusNoContent)
+}
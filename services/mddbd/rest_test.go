@@ -0,0 +1,29 @@
+package main
+
+import "testing"
+
+func TestETagMatches(t *testing.T) {
+	const etag = `"9f86d081884c7d65"`
+	cases := []struct {
+		header string
+		exists bool
+		weak   bool
+		want   bool
+	}{
+		{header: etag, exists: true, want: true},
+		{header: `"other", ` + etag, exists: true, want: true},
+		{header: `"other"`, exists: true},
+		{header: "*", exists: true, want: true},
+		{header: "*"},
+		{header: etag},
+		// If-Match needs a strong match; If-None-Match ignores W/
+		{header: "W/" + etag, exists: true},
+		{header: "W/" + etag, exists: true, weak: true, want: true},
+		{header: `W/"other", W/` + etag, exists: true, weak: true, want: true},
+	}
+	for _, c := range cases {
+		if got := etagMatches(c.header, etag, c.exists, c.weak); got != c.want {
+			t.Errorf("etagMatches(%s, exists=%v, weak=%v) = %v, want %v", c.header, c.exists, c.weak, got, c.want)
+		}
+	}
+}
@@ -0,0 +1,71 @@
+package main
+
+import (
+	"errors"
+	"net/http"
+
+	json "github.com/goccy/go-json"
+	"github.com/tradik/mddb/services/mddbd/storage"
+)
+
+type SchemaRequest struct {
+	Collection string `json:"collection"`
+}
+
+// schemaSetRequest is the schema set body; see handleSchemaSet
+type schemaSetRequest storage.CollectionSchema
+
+func (s *Server) handleSchemaSet(w http.ResponseWriter, r *http.Request) {
+	// Unknown fields are rejected so a typo like "required" isn't silently stored as an
+	// empty rule
+	var req schemaSetRequest
+	if err := decodeStrict(r.Body, &req); err != nil {
+		bad(w, err)
+		return
+	}
+	schema := storage.CollectionSchema(req)
+	if err := s.DB.Schemas.Set(schema); err != nil {
+		bad(w, err)
+		return
+	}
+	ok(w, schema)
+}
+
+func (s *Server) handleSchemaGet(w http.ResponseWriter, r *http.Request) {
+	var req SchemaRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		bad(w, err)
+		return
+	}
+	if req.Collection == "" {
+		bad(w, errors.New("missing collection"))
+		return
+	}
+	schema, err := s.DB.Schemas.Get(req.Collection)
+	if err != nil {
+		bad(w, err)
+		return
+	}
+	if schema == nil {
+		bad(w, errSchemaNotFound)
+		return
+	}
+	ok(w, schema)
+}
+
+func (s *Server) handleSchemaDelete(w http.ResponseWriter, r *http.Request) {
+	var req SchemaRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		bad(w, err)
+		return
+	}
+	if req.Collection == "" {
+		bad(w, errors.New("missing collection"))
+		return
+	}
+	if err := s.DB.Schemas.Delete(req.Collection); err != nil {
+		bad(w, err)
+		return
+	}
+	ok(w, map[string]string{"status": "deleted", "collection": req.Collection})
+}
@@ -1,4 +1,4 @@
-package main
+package storage
 
 import (
 	"sync"
@@ -10,6 +10,7 @@ import (
 type AdaptiveIndexManager struct {
 	queryStats      sync.Map // query pattern -> *QueryStats
 	indexStrategies sync.Map // collection -> *IndexStrategy
+	done            chan struct{}
 }
 
 // QueryStats tracks query performance statistics
@@ -44,7 +45,7 @@ type IndexStrategy struct {
 
 // NewAdaptiveIndexManager creates a new adaptive index manager
 func NewAdaptiveIndexManager() *AdaptiveIndexManager {
-	aim := &AdaptiveIndexManager{}
+	aim := &AdaptiveIndexManager{done: make(chan struct{})}
 	
 	// Start optimization worker
 	go aim.optimizationWorker()
@@ -150,11 +151,21 @@ func (aim *AdaptiveIndexManager) optimizationWorker() {
 	ticker := time.NewTicker(5 * time.Minute)
 	defer ticker.Stop()
 	
-	for range ticker.C {
-		aim.optimize()
+	for {
+		select {
+		case <-ticker.C:
+			aim.optimize()
+		case <-aim.done:
+			return
+		}
 	}
 }
 
+// Close stops the optimization worker
+func (aim *AdaptiveIndexManager) Close() {
+	close(aim.done)
+}
+
 // optimize analyzes all query patterns and optimizes strategies
 func (aim *AdaptiveIndexManager) optimize() {
 	now := time.Now()
@@ -0,0 +1,153 @@
+package storage
+
+import (
+	"bytes"
+	"os"
+	"sort"
+	"time"
+
+	bolt "go.etcd.io/bbolt"
+)
+
+// CollectionStats counts the keys of one collection
+type CollectionStats struct {
+	Name           string `json:"name"`
+	DocumentCount  int    `json:"documentCount"`
+	RevisionCount  int    `json:"revisionCount"`
+	MetaIndexCount int    `json:"metaIndexCount"`
+}
+
+// Stats describes the database file and its collections
+type Stats struct {
+	DatabasePath     string            `json:"databasePath"`
+	DatabaseSize     int64             `json:"databaseSize"`
+	Collections      []CollectionStats `json:"collections"`
+	TotalDocuments   int               `json:"totalDocuments"`
+	TotalRevisions   int               `json:"totalRevisions"`
+	TotalMetaIndices int               `json:"totalMetaIndices"`
+	Cache            CacheStats        `json:"cache"`
+}
+
+// Stats counts documents, revisions and index entries per collection
+func (db *DB) Stats() (*Stats, error) {
+	stats := &Stats{DatabasePath: db.path, Collections: []CollectionStats{}, Cache: db.cache.Stats()}
+	if info, err := os.Stat(db.path); err == nil {
+		stats.DatabaseSize = info.Size()
+	}
+
+	collectionMap := make(map[string]*CollectionStats)
+	count := func(tx *bolt.Tx, bucket []byte, total *int, field func(*CollectionStats) *int) {
+		c := tx.Bucket(bucket).Cursor()
+		for k, _ := c.First(); k != nil; k, _ = c.Next() {
+			coll := keyCollection(k)
+			if coll == "" {
+				continue
+			}
+			cs, ok := collectionMap[coll]
+			if !ok {
+				cs = &CollectionStats{Name: coll}
+				collectionMap[coll] = cs
+			}
+			*field(cs)++
+			*total++
+		}
+	}
+	err := db.view(func(tx *bolt.Tx) error {
+		count(tx, db.buckets.Docs, &stats.TotalDocuments, func(cs *CollectionStats) *int { return &cs.DocumentCount })
+		count(tx, db.buckets.Rev, &stats.TotalRevisions, func(cs *CollectionStats) *int { return &cs.RevisionCount })
+		count(tx, db.buckets.IdxMeta, &stats.TotalMetaIndices, func(cs *CollectionStats) *int { return &cs.MetaIndexCount })
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	for _, cs := range collectionMap {
+		stats.Collections = append(stats.Collections, *cs)
+	}
+	sort.Slice(stats.Collections, func(i, j int) bool {
+		return stats.Collections[i].Name < stats.Collections[j].Name
+	})
+	return stats, nil
+}
+
+// Truncate keeps only the newest keepRevs revisions of every document in a collection
+// (0 drops all history, a negative value keeps everything)
+func (db *DB) Truncate(collection string, keepRevs int) error {
+	if collection == "" {
+		return ErrMissingFields
+	}
+	if keepRevs < 0 {
+		return nil
+	}
+	return db.update(func(tx *bolt.Tx) error {
+		bRev := tx.Bucket(db.buckets.Rev)
+		c := tx.Bucket(db.buckets.Docs).Cursor()
+		prefix := kCollPrefix("doc", collection)
+		for k, _ := c.Seek(prefix); k != nil && bytes.HasPrefix(k, prefix); k, _ = c.Next() {
+			// Revision keys sort by timestamp, so the oldest come first
+			rc := bRev.Cursor()
+			rp := kRevPrefix(collection, string(k[len(prefix):]))
+			var revKeys [][]byte
+			for rk, _ := rc.Seek(rp); rk != nil && bytes.HasPrefix(rk, rp); rk, _ = rc.Next() {
+				revKeys = append(revKeys, CopyBytes(rk))
+			}
+			if len(revKeys) > keepRevs {
+				for _, rk := range revKeys[:len(revKeys)-keepRevs] {
+					if err := bRev.Delete(rk); err != nil {
+						return err
+					}
+				}
+			}
+		}
+		db.logChange(change{Op: changeTruncate, Collection: collection, KeepRevs: keepRevs})
+		return nil
+	})
+}
+
+// Backup writes a consistent snapshot of the database to path
+func (db *DB) Backup(path string) error {
+	return db.view(func(tx *bolt.Tx) error {
+		return tx.CopyFile(path, 0600)
+	})
+}
+
+// swapFile closes the database file, runs replace and reopens the file. The caller holds
+// writeMu; readers wait only for the swap itself. With a timeout > 0 it gives up with
+// ErrCompactionAborted when open reads don't end in time.
+func (db *DB) swapFile(timeout time.Duration, replace func() error) error {
+	if !db.lockSwap(timeout) {
+		return ErrCompactionAborted
+	}
+	defer db.swapMu.Unlock()
+	if err := db.bolt.Close(); err != nil {
+		return err
+	}
+	replaceErr := replace()
+
+	// Reopen even if replace failed so the current database stays usable
+	bdb, err := bolt.Open(db.path, 0600, db.opts.boltOptions())
+	if err != nil {
+		return err
+	}
+	db.bolt = bdb
+	return replaceErr
+}
+
+// lockSwap takes swapMu for writing. A waiting Lock would queue every new transaction
+// behind the reads it waits for, so with a timeout it polls TryLock instead, which
+// keeps reads flowing, and reports false once the timeout has passed.
+func (db *DB) lockSwap(timeout time.Duration) bool {
+	if timeout <= 0 {
+		db.swapMu.Lock()
+		return true
+	}
+	deadline := time.Now().Add(timeout)
+	for !db.swapMu.TryLock() {
+		if time.Now().After(deadline) {
+			return false
+		}
+		time.Sleep(swapPollInterval)
+	}
+	return true
+}
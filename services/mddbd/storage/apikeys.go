@@ -0,0 +1,140 @@
+package storage
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"sort"
+	"strings"
+	"time"
+
+	json "github.com/goccy/go-json"
+	bolt "go.etcd.io/bbolt"
+)
+
+// APIKeyPrefix starts every API key, so keys can be told apart from JWTs
+const APIKeyPrefix = "mddb_"
+
+var (
+	ErrAPIKeyNotFound = errors.New("api key not found")
+	ErrInvalidAPIKey  = errors.New("invalid api key")
+)
+
+// APIKey describes a stored API key. The secret itself is only returned by Create;
+// the store keeps a SHA-256 hash of it.
+type APIKey struct {
+	ID        string   `json:"id"`
+	Name      string   `json:"name"`
+	Roles     []string `json:"roles"`
+	CreatedAt int64    `json:"createdAt"`
+}
+
+// apiKeyRecord is the stored form of an API key
+type apiKeyRecord struct {
+	APIKey
+	Hash string `json:"hash"`
+}
+
+// APIKeyStore manages API keys in the apikeys bucket
+type APIKeyStore struct {
+	db *DB
+}
+
+// NewAPIKeyStore creates a new API key store
+func NewAPIKeyStore(db *DB) *APIKeyStore {
+	return &APIKeyStore{db: db}
+}
+
+func hashAPIKey(key string) string {
+	sum := sha256.Sum256([]byte(key))
+	return hex.EncodeToString(sum[:])
+}
+
+// Create stores a new key and returns it with its secret, formatted as mddb_<id>_<secret>.
+// Roles are stored as given; the server interprets them.
+func (ks *APIKeyStore) Create(name string, roles []string) (*APIKey, string, error) {
+	id := make([]byte, 6)
+	secret := make([]byte, 32)
+	if _, err := rand.Read(id); err != nil {
+		return nil, "", err
+	}
+	if _, err := rand.Read(secret); err != nil {
+		return nil, "", err
+	}
+	rec := apiKeyRecord{APIKey: APIKey{
+		ID:        hex.EncodeToString(id),
+		Name:      name,
+		Roles:     roles,
+		CreatedAt: time.Now().Unix(),
+	}}
+	key := APIKeyPrefix + rec.ID + "_" + base64.RawURLEncoding.EncodeToString(secret)
+	rec.Hash = hashAPIKey(key)
+
+	buf, err := json.Marshal(rec)
+	if err != nil {
+		return nil, "", err
+	}
+	err = ks.db.update(func(tx *bolt.Tx) error {
+		ks.db.logKey(ks.db.buckets.APIKeys, rec.ID)
+		return tx.Bucket(ks.db.buckets.APIKeys).Put([]byte(rec.ID), buf)
+	})
+	if err != nil {
+		return nil, "", err
+	}
+	return &rec.APIKey, key, nil
+}
+
+// List returns all keys sorted by name, without secrets
+func (ks *APIKeyStore) List() ([]APIKey, error) {
+	out := []APIKey{}
+	err := ks.db.view(func(tx *bolt.Tx) error {
+		return tx.Bucket(ks.db.buckets.APIKeys).ForEach(func(_, v []byte) error {
+			var rec apiKeyRecord
+			if err := json.Unmarshal(v, &rec); err != nil {
+				return err
+			}
+			out = append(out, rec.APIKey)
+			return nil
+		})
+	})
+	sort.Slice(out, func(i, j int) bool { return out[i].Name < out[j].Name })
+	return out, err
+}
+
+// Delete revokes a key
+func (ks *APIKeyStore) Delete(id string) error {
+	return ks.db.update(func(tx *bolt.Tx) error {
+		b := tx.Bucket(ks.db.buckets.APIKeys)
+		if b.Get([]byte(id)) == nil {
+			return ErrAPIKeyNotFound
+		}
+		ks.db.logKey(ks.db.buckets.APIKeys, id)
+		return b.Delete([]byte(id))
+	})
+}
+
+// Verify returns the key that key is the secret of, or ErrInvalidAPIKey
+func (ks *APIKeyStore) Verify(key string) (*APIKey, error) {
+	id, _, ok := strings.Cut(strings.TrimPrefix(key, APIKeyPrefix), "_")
+	if !ok || !strings.HasPrefix(key, APIKeyPrefix) {
+		return nil, ErrInvalidAPIKey
+	}
+	var rec apiKeyRecord
+	err := ks.db.view(func(tx *bolt.Tx) error {
+		v := tx.Bucket(ks.db.buckets.APIKeys).Get([]byte(id))
+		if v == nil {
+			return ErrInvalidAPIKey
+		}
+		return json.Unmarshal(v, &rec)
+	})
+	if err != nil {
+		return nil, err
+	}
+	if subtle.ConstantTimeCompare([]byte(hashAPIKey(key)), []byte(rec.Hash)) != 1 {
+		return nil, ErrInvalidAPIKey
+	}
+	return &rec.APIKey, nil
+}
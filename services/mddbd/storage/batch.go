@@ -1,4 +1,4 @@
-package main
+package storage
 
 import (
 	"context"
@@ -7,22 +7,21 @@ import (
 	"time"
 
 	bolt "go.etcd.io/bbolt"
-	proto "mddb/proto"
 )
 
 // BatchProcessor handles batch document processing
 type BatchProcessor struct {
-	server     *Server
+	db         *DB
 	maxWorkers int
 }
 
 // NewBatchProcessor creates a new batch processor
-func NewBatchProcessor(server *Server, maxWorkers int) *BatchProcessor {
+func NewBatchProcessor(db *DB, maxWorkers int) *BatchProcessor {
 	if maxWorkers <= 0 {
 		maxWorkers = 4 // Default to 4 workers
 	}
 	return &BatchProcessor{
-		server:     server,
+		db:     db,
 		maxWorkers: maxWorkers,
 	}
 }
@@ -40,9 +39,9 @@ type ProcessedDoc struct {
 }
 
 // ProcessBatch processes multiple documents in parallel, then commits in single transaction
-func (bp *BatchProcessor) ProcessBatch(ctx context.Context, collection string, batchDocs []*proto.BatchDocument) (*proto.AddBatchResponse, error) {
+func (bp *BatchProcessor) ProcessBatch(ctx context.Context, collection string, batchDocs []BatchDocument) (*AddBatchResult, error) {
 	if len(batchDocs) == 0 {
-		return &proto.AddBatchResponse{}, nil
+		return &AddBatchResult{}, nil
 	}
 
 	now := time.Now().Unix()
@@ -57,7 +56,7 @@ func (bp *BatchProcessor) ProcessBatch(ctx context.Context, collection string, b
 }
 
 // parallelProcess processes documents in parallel
-func (bp *BatchProcessor) parallelProcess(ctx context.Context, collection string, batchDocs []*proto.BatchDocument, now int64) []*ProcessedDoc {
+func (bp *BatchProcessor) parallelProcess(ctx context.Context, collection string, batchDocs []BatchDocument, now int64) []*ProcessedDoc {
 	processed := make([]*ProcessedDoc, len(batchDocs))
 	
 	// Create worker pool
@@ -80,7 +79,7 @@ func (bp *BatchProcessor) parallelProcess(ctx context.Context, collection string
 				case <-ctx.Done():
 					return
 				default:
-					processed[idx] = bp.processDocument(collection, batchDocs[idx], now)
+					processed[idx] = bp.processDocument(collection, &batchDocs[idx], now)
 				}
 			}
 		}()
@@ -99,30 +98,34 @@ func (bp *BatchProcessor) parallelProcess(ctx context.Context, collection string
 }
 
 // processDocument processes a single document (validation, conversion, marshaling)
-func (bp *BatchProcessor) processDocument(collection string, batchDoc *proto.BatchDocument, now int64) *ProcessedDoc {
+func (bp *BatchProcessor) processDocument(collection string, batchDoc *BatchDocument, now int64) *ProcessedDoc {
 	result := &ProcessedDoc{}
+	lang := bp.db.Collections.DefaultLang(collection, batchDoc.Lang)
 	
 	// Validate
-	if batchDoc.Key == "" || batchDoc.Lang == "" {
+	if batchDoc.Key == "" || lang == "" {
 		result.Error = fmt.Errorf("missing key or lang")
 		return result
 	}
 	
-	// Convert meta
-	meta := make(map[string][]string)
-	for k, v := range batchDoc.Meta {
-		meta[k] = v.Values
-	}
+	meta := batchDoc.Meta
 	result.Meta = meta
 	
+	// Enforce collection schema
+	if err := bp.db.Schemas.Validate(collection, batchDoc.Key, lang, meta, batchDoc.ContentMD); err != nil {
+		result.Doc = Doc{Key: batchDoc.Key, Lang: lang}
+		result.Error = err
+		return result
+	}
+	
 	// Generate ID
-	docID := genID(collection, batchDoc.Key, batchDoc.Lang)
+	docID := genID(collection, batchDoc.Key, lang)
 	result.DocID = docID
 	
 	// Load existing (in read transaction)
 	existing := Doc{}
-	err := bp.server.DB.View(func(tx *bolt.Tx) error {
-		bDocs := tx.Bucket(bp.server.BucketNames.Docs)
+	err := bp.db.view(func(tx *bolt.Tx) error {
+		bDocs := tx.Bucket(bp.db.buckets.Docs)
 		if v := bDocs.Get(kDoc(collection, docID)); v != nil {
 			existingDoc, err := unmarshalDoc(v)
 			if err != nil {
@@ -148,12 +151,12 @@ func (bp *BatchProcessor) processDocument(collection string, batchDoc *proto.Bat
 	}
 	
 	doc := Doc{
-		ID: docID, Key: batchDoc.Key, Lang: batchDoc.Lang, Meta: meta,
-		ContentMD: batchDoc.ContentMd, AddedAt: added, UpdatedAt: now,
+		ID: docID, Key: batchDoc.Key, Lang: lang, Meta: meta,
+		ContentMD: batchDoc.ContentMD, AddedAt: added, UpdatedAt: now,
 	}
 	
 	// Marshal
-	buf, err := marshalDoc(&doc)
+	buf, err := marshalDocWith(&doc, bp.db.Collections.Compression(collection))
 	if err != nil {
 		result.Error = err
 		return result
@@ -161,27 +164,35 @@ func (bp *BatchProcessor) processDocument(collection string, batchDoc *proto.Bat
 	
 	result.Doc = doc
 	result.Buf = buf
-	result.SaveRevision = batchDoc.SaveRevision
+	result.SaveRevision = bp.db.Collections.SaveRevision(collection, batchDoc.SaveRevision)
 	
 	return result
 }
 
 // commitBatch commits all processed documents in a single transaction
-func (bp *BatchProcessor) commitBatch(collection string, processed []*ProcessedDoc, now int64) *proto.AddBatchResponse {
-	resp := &proto.AddBatchResponse{}
+func (bp *BatchProcessor) commitBatch(collection string, processed []*ProcessedDoc, now int64) *AddBatchResult {
+	resp := &AddBatchResult{}
 	
 	// Single transaction for all documents
-	err := bp.server.DB.Update(func(tx *bolt.Tx) error {
-		bDocs := tx.Bucket(bp.server.BucketNames.Docs)
-		bIdx := tx.Bucket(bp.server.BucketNames.IdxMeta)
-		bRev := tx.Bucket(bp.server.BucketNames.Rev)
-		bByK := tx.Bucket(bp.server.BucketNames.ByKey)
+	err := bp.db.update(func(tx *bolt.Tx) error {
+		bDocs := tx.Bucket(bp.db.buckets.Docs)
+		bIdx := tx.Bucket(bp.db.buckets.IdxMeta)
+		bByK := tx.Bucket(bp.db.buckets.ByKey)
+		
+		if err := bp.db.Collections.Ensure(tx, collection); err != nil {
+			return err
+		}
 		
 		for _, p := range processed {
 			// Skip failed documents
 			if p.Error != nil {
 				resp.Failed++
-				resp.Errors = append(resp.Errors, fmt.Sprintf("%s: %v", p.Doc.Key, p.Error))
+				resp.Errors = append(resp.Errors, fmt.Sprintf("%s/%s: %v", p.Doc.Key, p.Doc.Lang, p.Error))
+				continue
+			}
+			if err := bp.db.Schemas.checkLangs(tx, collection, p.Doc.Key, p.Doc.Lang); err != nil {
+				resp.Failed++
+				resp.Errors = append(resp.Errors, fmt.Sprintf("%s/%s: %v", p.Doc.Key, p.Doc.Lang, err))
 				continue
 			}
 			
@@ -191,6 +202,7 @@ func (bp *BatchProcessor) commitBatch(collection string, processed []*ProcessedD
 				resp.Errors = append(resp.Errors, fmt.Sprintf("%s: put error: %v", p.Doc.Key, err))
 				continue
 			}
+			bp.db.invalidateTx(tx, collection, p.Doc.Key, p.Doc.Lang)
 			
 			// Store key index
 			if err := bByK.Put(kByKey(collection, p.Doc.Key, p.Doc.Lang), []byte(p.DocID)); err != nil {
@@ -226,8 +238,7 @@ func (bp *BatchProcessor) commitBatch(collection string, processed []*ProcessedD
 			
 			// Revision (optional - only if requested)
 			if p.SaveRevision {
-				rkey := append(kRevPrefix(collection, p.Doc.ID), []byte(fmt.Sprintf("%020d", now))...)
-				if err := bRev.Put(rkey, p.Buf); err != nil {
+				if err := bp.db.putRevision(tx, collection, p.Doc.ID, now, p.Buf); err != nil {
 					resp.Failed++
 					resp.Errors = append(resp.Errors, fmt.Sprintf("%s: revision error: %v", p.Doc.Key, err))
 					continue
@@ -246,7 +257,7 @@ func (bp *BatchProcessor) commitBatch(collection string, processed []*ProcessedD
 	})
 	
 	if err != nil {
-		resp.Failed = int32(len(processed))
+		resp.Failed = len(processed)
 		resp.Errors = append(resp.Errors, fmt.Sprintf("transaction error: %v", err))
 	}
 	
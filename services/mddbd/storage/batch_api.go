@@ -0,0 +1,69 @@
+package storage
+
+import (
+	"context"
+)
+
+// BatchDocument is one document of a batch add or update
+type BatchDocument struct {
+	Key          string              `json:"key"`
+	Lang         string              `json:"lang"`
+	Meta         map[string][]string `json:"meta"`
+	ContentMD    string              `json:"content_md"`
+	SaveRevision bool                `json:"save_revision"`
+}
+
+// DocRef identifies a document of a collection
+type DocRef struct {
+	Key  string `json:"key"`
+	Lang string `json:"lang"`
+}
+
+type AddBatchResult struct {
+	Added   int      `json:"added"`
+	Updated int      `json:"updated"`
+	Failed  int      `json:"failed"`
+	Errors  []string `json:"errors,omitempty"`
+}
+
+type UpdateBatchResult struct {
+	Updated  int      `json:"updated"`
+	NotFound int      `json:"not_found"`
+	Failed   int      `json:"failed"`
+	Errors   []string `json:"errors,omitempty"`
+}
+
+type DeleteBatchResult struct {
+	Deleted  int      `json:"deleted"`
+	NotFound int      `json:"not_found"`
+	Failed   int      `json:"failed"`
+	Errors   []string `json:"errors,omitempty"`
+}
+
+// AddBatch creates or replaces documents in a single transaction. Documents that fail
+// validation are reported in the result and do not stop the others.
+func (db *DB) AddBatch(ctx context.Context, collection string, docs []BatchDocument) (*AddBatchResult, error) {
+	if collection == "" {
+		return nil, ErrMissingFields
+	}
+	if db.extreme && db.finalBatchProcessor != nil {
+		return db.finalBatchProcessor.ProcessBatch(ctx, collection, docs)
+	}
+	return db.batchProcessor.ProcessBatch(ctx, collection, docs)
+}
+
+// UpdateBatch replaces existing documents in a single transaction; missing ones are counted as not found
+func (db *DB) UpdateBatch(ctx context.Context, collection string, docs []BatchDocument) (*UpdateBatchResult, error) {
+	if collection == "" {
+		return nil, ErrMissingFields
+	}
+	return db.batchUpdater.ProcessBatchUpdate(ctx, collection, docs)
+}
+
+// DeleteBatch removes documents in a single transaction; missing ones are counted as not found
+func (db *DB) DeleteBatch(ctx context.Context, collection string, refs []DocRef) (*DeleteBatchResult, error) {
+	if collection == "" {
+		return nil, ErrMissingFields
+	}
+	return db.batchDeleter.ProcessBatchDelete(ctx, collection, refs)
+}
@@ -1,4 +1,4 @@
-package main
+package storage
 
 import (
 	"context"
@@ -7,32 +7,31 @@ import (
 	"time"
 
 	bolt "go.etcd.io/bbolt"
-	proto "mddb/proto"
 )
 
 // FinalBatchProcessor - FINAL optimized batch processor
 // Key optimization: SINGLE READ transaction for ALL documents
 type FinalBatchProcessor struct {
-	server     *Server
+	db         *DB
 	maxWorkers int
 }
 
 // NewFinalBatchProcessor creates final optimized batch processor
-func NewFinalBatchProcessor(server *Server, maxWorkers int) *FinalBatchProcessor {
+func NewFinalBatchProcessor(db *DB, maxWorkers int) *FinalBatchProcessor {
 	if maxWorkers <= 0 {
 		maxWorkers = 8
 	}
 	
 	return &FinalBatchProcessor{
-		server:     server,
+		db:     db,
 		maxWorkers: maxWorkers,
 	}
 }
 
 // ProcessBatch processes batch with SINGLE READ transaction
-func (fbp *FinalBatchProcessor) ProcessBatch(ctx context.Context, collection string, batchDocs []*proto.BatchDocument) (*proto.AddBatchResponse, error) {
+func (fbp *FinalBatchProcessor) ProcessBatch(ctx context.Context, collection string, batchDocs []BatchDocument) (*AddBatchResult, error) {
 	if len(batchDocs) == 0 {
-		return &proto.AddBatchResponse{}, nil
+		return &AddBatchResult{}, nil
 	}
 
 	now := time.Now().Unix()
@@ -50,27 +49,28 @@ func (fbp *FinalBatchProcessor) ProcessBatch(ctx context.Context, collection str
 }
 
 // batchReadAll reads ALL documents in SINGLE transaction
-func (fbp *FinalBatchProcessor) batchReadAll(collection string, batchDocs []*proto.BatchDocument) map[string][]byte {
+func (fbp *FinalBatchProcessor) batchReadAll(collection string, batchDocs []BatchDocument) map[string][]byte {
 	existingMap := make(map[string][]byte, len(batchDocs))
 	
 	// SINGLE READ TRANSACTION for ALL documents
-	_ = fbp.server.DB.View(func(tx *bolt.Tx) error {
-		bDocs := tx.Bucket(fbp.server.BucketNames.Docs)
+	_ = fbp.db.view(func(tx *bolt.Tx) error {
+		bDocs := tx.Bucket(fbp.db.buckets.Docs)
 		
 		// Pre-allocate buffer for key building
 		keyBuf := make([]byte, 0, 256)
 		
 		for _, batchDoc := range batchDocs {
-			if batchDoc.Key == "" || batchDoc.Lang == "" {
+			lang := fbp.db.Collections.DefaultLang(collection, batchDoc.Lang)
+			if batchDoc.Key == "" || lang == "" {
 				continue
 			}
 			
-			docID := genID(collection, batchDoc.Key, batchDoc.Lang)
+			docID := genID(collection, batchDoc.Key, lang)
 			
 			// Build key efficiently
 			keyBuf = keyBuf[:0]
 			keyBuf = append(keyBuf, "doc|"...)
-			keyBuf = append(keyBuf, collection...)
+			keyBuf = appendKeyPart(keyBuf, collection)
 			keyBuf = append(keyBuf, '|')
 			keyBuf = append(keyBuf, docID...)
 			
@@ -87,7 +87,7 @@ func (fbp *FinalBatchProcessor) batchReadAll(collection string, batchDocs []*pro
 }
 
 // parallelMarshal marshals documents in parallel (no DB access)
-func (fbp *FinalBatchProcessor) parallelMarshal(ctx context.Context, collection string, batchDocs []*proto.BatchDocument, existingMap map[string][]byte, now int64) []*ProcessedDoc {
+func (fbp *FinalBatchProcessor) parallelMarshal(ctx context.Context, collection string, batchDocs []BatchDocument, existingMap map[string][]byte, now int64) []*ProcessedDoc {
 	processed := make([]*ProcessedDoc, len(batchDocs))
 	
 	numWorkers := fbp.maxWorkers
@@ -108,7 +108,7 @@ func (fbp *FinalBatchProcessor) parallelMarshal(ctx context.Context, collection
 				case <-ctx.Done():
 					return
 				default:
-					processed[idx] = fbp.processDocumentFast(collection, batchDocs[idx], existingMap, now)
+					processed[idx] = fbp.processDocumentFast(collection, &batchDocs[idx], existingMap, now)
 				}
 			}
 		}()
@@ -125,22 +125,25 @@ func (fbp *FinalBatchProcessor) parallelMarshal(ctx context.Context, collection
 }
 
 // processDocumentFast processes document without DB access
-func (fbp *FinalBatchProcessor) processDocumentFast(collection string, batchDoc *proto.BatchDocument, existingMap map[string][]byte, now int64) *ProcessedDoc {
+func (fbp *FinalBatchProcessor) processDocumentFast(collection string, batchDoc *BatchDocument, existingMap map[string][]byte, now int64) *ProcessedDoc {
 	result := &ProcessedDoc{}
+	lang := fbp.db.Collections.DefaultLang(collection, batchDoc.Lang)
 	
-	if batchDoc.Key == "" || batchDoc.Lang == "" {
+	if batchDoc.Key == "" || lang == "" {
 		result.Error = fmt.Errorf("missing key or lang")
 		return result
 	}
 	
-	// Convert meta directly
-	meta := make(map[string][]string, len(batchDoc.Meta))
-	for k, v := range batchDoc.Meta {
-		meta[k] = v.Values
-	}
+	meta := batchDoc.Meta
 	result.Meta = meta
 	
-	docID := genID(collection, batchDoc.Key, batchDoc.Lang)
+	if err := fbp.db.Schemas.Validate(collection, batchDoc.Key, lang, meta, batchDoc.ContentMD); err != nil {
+		result.Doc = Doc{Key: batchDoc.Key, Lang: lang}
+		result.Error = err
+		return result
+	}
+	
+	docID := genID(collection, batchDoc.Key, lang)
 	result.DocID = docID
 	
 	// Check existing from pre-loaded map
@@ -159,11 +162,11 @@ func (fbp *FinalBatchProcessor) processDocumentFast(collection string, batchDoc
 	}
 	
 	doc := Doc{
-		ID: docID, Key: batchDoc.Key, Lang: batchDoc.Lang, Meta: meta,
-		ContentMD: batchDoc.ContentMd, AddedAt: added, UpdatedAt: now,
+		ID: docID, Key: batchDoc.Key, Lang: lang, Meta: meta,
+		ContentMD: batchDoc.ContentMD, AddedAt: added, UpdatedAt: now,
 	}
 	
-	buf, err := marshalDoc(&doc)
+	buf, err := marshalDocWith(&doc, fbp.db.Collections.Compression(collection))
 	if err != nil {
 		result.Error = err
 		return result
@@ -171,38 +174,45 @@ func (fbp *FinalBatchProcessor) processDocumentFast(collection string, batchDoc
 	
 	result.Doc = doc
 	result.Buf = buf
-	result.SaveRevision = batchDoc.SaveRevision
+	result.SaveRevision = fbp.db.Collections.SaveRevision(collection, batchDoc.SaveRevision)
 	
 	return result
 }
 
 // commitBatch commits with optimized key building
-func (fbp *FinalBatchProcessor) commitBatch(collection string, processed []*ProcessedDoc, now int64) *proto.AddBatchResponse {
-	resp := &proto.AddBatchResponse{}
+func (fbp *FinalBatchProcessor) commitBatch(collection string, processed []*ProcessedDoc, now int64) *AddBatchResult {
+	resp := &AddBatchResult{}
 	
-	err := fbp.server.DB.Update(func(tx *bolt.Tx) error {
-		bDocs := tx.Bucket(fbp.server.BucketNames.Docs)
-		bIdx := tx.Bucket(fbp.server.BucketNames.IdxMeta)
-		bRev := tx.Bucket(fbp.server.BucketNames.Rev)
-		bByK := tx.Bucket(fbp.server.BucketNames.ByKey)
+	err := fbp.db.update(func(tx *bolt.Tx) error {
+		bDocs := tx.Bucket(fbp.db.buckets.Docs)
+		bIdx := tx.Bucket(fbp.db.buckets.IdxMeta)
+		bByK := tx.Bucket(fbp.db.buckets.ByKey)
+		
+		if err := fbp.db.Collections.Ensure(tx, collection); err != nil {
+			return err
+		}
 		
 		// Pre-allocate reusable buffers
 		docKeyBuf := make([]byte, 0, 256)
 		byKeyBuf := make([]byte, 0, 256)
 		metaKeyBuf := make([]byte, 0, 256)
-		revKeyBuf := make([]byte, 0, 256)
 		
 		for _, p := range processed {
 			if p.Error != nil {
 				resp.Failed++
-				resp.Errors = append(resp.Errors, fmt.Sprintf("%s: %v", p.Doc.Key, p.Error))
+				resp.Errors = append(resp.Errors, fmt.Sprintf("%s/%s: %v", p.Doc.Key, p.Doc.Lang, p.Error))
+				continue
+			}
+			if err := fbp.db.Schemas.checkLangs(tx, collection, p.Doc.Key, p.Doc.Lang); err != nil {
+				resp.Failed++
+				resp.Errors = append(resp.Errors, fmt.Sprintf("%s/%s: %v", p.Doc.Key, p.Doc.Lang, err))
 				continue
 			}
 			
 			// Build doc key
 			docKeyBuf = docKeyBuf[:0]
 			docKeyBuf = append(docKeyBuf, "doc|"...)
-			docKeyBuf = append(docKeyBuf, collection...)
+			docKeyBuf = appendKeyPart(docKeyBuf, collection)
 			docKeyBuf = append(docKeyBuf, '|')
 			docKeyBuf = append(docKeyBuf, p.DocID...)
 			
@@ -210,15 +220,16 @@ func (fbp *FinalBatchProcessor) commitBatch(collection string, processed []*Proc
 				resp.Failed++
 				continue
 			}
+			fbp.db.invalidateTx(tx, collection, p.Doc.Key, p.Doc.Lang)
 			
 			// Build bykey index
 			byKeyBuf = byKeyBuf[:0]
 			byKeyBuf = append(byKeyBuf, "bykey|"...)
-			byKeyBuf = append(byKeyBuf, collection...)
+			byKeyBuf = appendKeyPart(byKeyBuf, collection)
 			byKeyBuf = append(byKeyBuf, '|')
-			byKeyBuf = append(byKeyBuf, p.Doc.Key...)
+			byKeyBuf = appendKeyPart(byKeyBuf, p.Doc.Key)
 			byKeyBuf = append(byKeyBuf, '|')
-			byKeyBuf = append(byKeyBuf, p.Doc.Lang...)
+			byKeyBuf = appendKeyPart(byKeyBuf, p.Doc.Lang)
 			
 			if err := bByK.Put(byKeyBuf, []byte(p.DocID)); err != nil {
 				resp.Failed++
@@ -233,11 +244,11 @@ func (fbp *FinalBatchProcessor) commitBatch(collection string, processed []*Proc
 						for _, mv := range vals {
 							metaKeyBuf = metaKeyBuf[:0]
 							metaKeyBuf = append(metaKeyBuf, "meta|"...)
-							metaKeyBuf = append(metaKeyBuf, collection...)
+							metaKeyBuf = appendKeyPart(metaKeyBuf, collection)
 							metaKeyBuf = append(metaKeyBuf, '|')
-							metaKeyBuf = append(metaKeyBuf, mk...)
+							metaKeyBuf = appendKeyPart(metaKeyBuf, mk)
 							metaKeyBuf = append(metaKeyBuf, '|')
-							metaKeyBuf = append(metaKeyBuf, mv...)
+							metaKeyBuf = appendKeyPart(metaKeyBuf, mv)
 							metaKeyBuf = append(metaKeyBuf, '|')
 							metaKeyBuf = append(metaKeyBuf, p.Existing.ID...)
 							_ = bIdx.Delete(metaKeyBuf)
@@ -250,11 +261,11 @@ func (fbp *FinalBatchProcessor) commitBatch(collection string, processed []*Proc
 					for _, mv := range vals {
 						metaKeyBuf = metaKeyBuf[:0]
 						metaKeyBuf = append(metaKeyBuf, "meta|"...)
-						metaKeyBuf = append(metaKeyBuf, collection...)
+						metaKeyBuf = appendKeyPart(metaKeyBuf, collection)
 						metaKeyBuf = append(metaKeyBuf, '|')
-						metaKeyBuf = append(metaKeyBuf, mk...)
+						metaKeyBuf = appendKeyPart(metaKeyBuf, mk)
 						metaKeyBuf = append(metaKeyBuf, '|')
-						metaKeyBuf = append(metaKeyBuf, mv...)
+						metaKeyBuf = appendKeyPart(metaKeyBuf, mv)
 						metaKeyBuf = append(metaKeyBuf, '|')
 						metaKeyBuf = append(metaKeyBuf, p.Doc.ID...)
 						_ = bIdx.Put(metaKeyBuf, []byte("1"))
@@ -264,26 +275,7 @@ func (fbp *FinalBatchProcessor) commitBatch(collection string, processed []*Proc
 			
 			// Revision
 			if p.SaveRevision {
-				revKeyBuf = revKeyBuf[:0]
-				revKeyBuf = append(revKeyBuf, "rev|"...)
-				revKeyBuf = append(revKeyBuf, collection...)
-				revKeyBuf = append(revKeyBuf, '|')
-				revKeyBuf = append(revKeyBuf, p.Doc.ID...)
-				revKeyBuf = append(revKeyBuf, '|')
-				
-				// Format timestamp efficiently
-				ts := FormatTimestamp(now, make([]byte, 20))
-				revKeyBuf = append(revKeyBuf, ts...)
-				
-				_ = bRev.Put(revKeyBuf, p.Buf)
-			}
-			
-			// Update cache
-			cacheKey := BuildCacheKey(collection, p.Doc.Key, p.Doc.Lang)
-			if fbp.server.UseExtreme && fbp.server.LockFreeCache != nil {
-				fbp.server.LockFreeCache.Set(cacheKey, p.Buf)
-			} else {
-				fbp.server.Cache.Set(cacheKey, p.Buf)
+				_ = fbp.db.putRevision(tx, collection, p.Doc.ID, now, p.Buf)
 			}
 			
 			if p.IsUpdate {
@@ -297,7 +289,7 @@ func (fbp *FinalBatchProcessor) commitBatch(collection string, processed []*Proc
 	})
 	
 	if err != nil {
-		resp.Failed = int32(len(processed))
+		resp.Failed = len(processed)
 		resp.Errors = append(resp.Errors, fmt.Sprintf("transaction error: %v", err))
 	}
 	
@@ -1,28 +1,27 @@
-package main
+package storage
 
 import (
 	"context"
 	"fmt"
 	"sync"
 
-	proto "mddb/proto"
 
 	bolt "go.etcd.io/bbolt"
 )
 
 // BatchDeleter handles batch delete operations
 type BatchDeleter struct {
-	server     *Server
+	db         *DB
 	maxWorkers int
 }
 
 // NewBatchDeleter creates a new batch deleter
-func NewBatchDeleter(server *Server, maxWorkers int) *BatchDeleter {
+func NewBatchDeleter(db *DB, maxWorkers int) *BatchDeleter {
 	if maxWorkers <= 0 {
 		maxWorkers = 8
 	}
 	return &BatchDeleter{
-		server:     server,
+		db:     db,
 		maxWorkers: maxWorkers,
 	}
 }
@@ -38,9 +37,9 @@ type DeletedDoc struct {
 }
 
 // ProcessBatchDelete processes multiple document deletions in parallel
-func (bd *BatchDeleter) ProcessBatchDelete(ctx context.Context, collection string, deleteDocs []*proto.DeleteDocument) (*proto.DeleteBatchResponse, error) {
+func (bd *BatchDeleter) ProcessBatchDelete(ctx context.Context, collection string, deleteDocs []DocRef) (*DeleteBatchResult, error) {
 	if len(deleteDocs) == 0 {
-		return &proto.DeleteBatchResponse{}, nil
+		return &DeleteBatchResult{}, nil
 	}
 
 	// Phase 1: Parallel lookup
@@ -53,7 +52,7 @@ func (bd *BatchDeleter) ProcessBatchDelete(ctx context.Context, collection strin
 }
 
 // parallelLookup looks up documents in parallel
-func (bd *BatchDeleter) parallelLookup(ctx context.Context, collection string, deleteDocs []*proto.DeleteDocument) []*DeletedDoc {
+func (bd *BatchDeleter) parallelLookup(ctx context.Context, collection string, deleteDocs []DocRef) []*DeletedDoc {
 	deleted := make([]*DeletedDoc, len(deleteDocs))
 	
 	numWorkers := bd.maxWorkers
@@ -70,7 +69,7 @@ func (bd *BatchDeleter) parallelLookup(ctx context.Context, collection string, d
 		go func() {
 			defer wg.Done()
 			for idx := range jobs {
-				deleted[idx] = bd.lookupDocument(collection, deleteDocs[idx])
+				deleted[idx] = bd.lookupDocument(collection, &deleteDocs[idx])
 			}
 		}()
 	}
@@ -86,7 +85,7 @@ func (bd *BatchDeleter) parallelLookup(ctx context.Context, collection string, d
 }
 
 // lookupDocument looks up a document for deletion
-func (bd *BatchDeleter) lookupDocument(collection string, deleteDoc *proto.DeleteDocument) *DeletedDoc {
+func (bd *BatchDeleter) lookupDocument(collection string, deleteDoc *DocRef) *DeletedDoc {
 	result := &DeletedDoc{
 		Key:  deleteDoc.Key,
 		Lang: deleteDoc.Lang,
@@ -103,8 +102,8 @@ func (bd *BatchDeleter) lookupDocument(collection string, deleteDoc *proto.Delet
 	result.DocID = docID
 	
 	// Load existing document (to get metadata for cleanup)
-	err := bd.server.DB.View(func(tx *bolt.Tx) error {
-		bDocs := tx.Bucket(bd.server.BucketNames.Docs)
+	err := bd.db.view(func(tx *bolt.Tx) error {
+		bDocs := tx.Bucket(bd.db.buckets.Docs)
 		if v := bDocs.Get(kDoc(collection, docID)); v != nil {
 			existingDoc, err := unmarshalDoc(v)
 			if err != nil {
@@ -125,15 +124,15 @@ func (bd *BatchDeleter) lookupDocument(collection string, deleteDoc *proto.Delet
 }
 
 // commitDelete commits all deletions in a single transaction
-func (bd *BatchDeleter) commitDelete(collection string, deleted []*DeletedDoc) *proto.DeleteBatchResponse {
-	resp := &proto.DeleteBatchResponse{}
+func (bd *BatchDeleter) commitDelete(collection string, deleted []*DeletedDoc) *DeleteBatchResult {
+	resp := &DeleteBatchResult{}
 	
 	// Single transaction for all deletions
-	err := bd.server.DB.Update(func(tx *bolt.Tx) error {
-		bDocs := tx.Bucket(bd.server.BucketNames.Docs)
-		bIdx := tx.Bucket(bd.server.BucketNames.IdxMeta)
-		bRev := tx.Bucket(bd.server.BucketNames.Rev)
-		bByK := tx.Bucket(bd.server.BucketNames.ByKey)
+	err := bd.db.update(func(tx *bolt.Tx) error {
+		bDocs := tx.Bucket(bd.db.buckets.Docs)
+		bIdx := tx.Bucket(bd.db.buckets.IdxMeta)
+		bRev := tx.Bucket(bd.db.buckets.Rev)
+		bByK := tx.Bucket(bd.db.buckets.ByKey)
 		
 		for _, d := range deleted {
 			if d.Error != nil {
@@ -179,9 +178,8 @@ func (bd *BatchDeleter) commitDelete(collection string, deleted []*DeletedDoc) *
 				_ = bRev.Delete(k)
 			}
 			
-			// Invalidate cache
-			cacheKey := BuildCacheKey(collection, d.Key, d.Lang)
-			bd.server.Cache.Delete(cacheKey)
+			// Invalidate cache once the deletes commit
+			bd.db.invalidateTx(tx, collection, d.Key, d.Lang)
 			
 			resp.Deleted++
 		}
@@ -1,4 +1,4 @@
-package main
+package storage
 
 import (
 	"context"
@@ -6,24 +6,23 @@ import (
 	"sync"
 	"time"
 
-	proto "mddb/proto"
 
 	bolt "go.etcd.io/bbolt"
 )
 
 // BatchUpdater handles batch update operations
 type BatchUpdater struct {
-	server     *Server
+	db         *DB
 	maxWorkers int
 }
 
 // NewBatchUpdater creates a new batch updater
-func NewBatchUpdater(server *Server, maxWorkers int) *BatchUpdater {
+func NewBatchUpdater(db *DB, maxWorkers int) *BatchUpdater {
 	if maxWorkers <= 0 {
 		maxWorkers = 8
 	}
 	return &BatchUpdater{
-		server:     server,
+		db:     db,
 		maxWorkers: maxWorkers,
 	}
 }
@@ -43,9 +42,9 @@ type UpdatedDoc struct {
 }
 
 // ProcessBatchUpdate processes multiple document updates in parallel
-func (bu *BatchUpdater) ProcessBatchUpdate(ctx context.Context, collection string, updateDocs []*proto.UpdateDocument) (*proto.UpdateBatchResponse, error) {
+func (bu *BatchUpdater) ProcessBatchUpdate(ctx context.Context, collection string, updateDocs []BatchDocument) (*UpdateBatchResult, error) {
 	if len(updateDocs) == 0 {
-		return &proto.UpdateBatchResponse{}, nil
+		return &UpdateBatchResult{}, nil
 	}
 
 	now := time.Now().Unix()
@@ -60,7 +59,7 @@ func (bu *BatchUpdater) ProcessBatchUpdate(ctx context.Context, collection strin
 }
 
 // parallelProcess processes updates in parallel
-func (bu *BatchUpdater) parallelProcess(ctx context.Context, collection string, updateDocs []*proto.UpdateDocument, now int64) []*UpdatedDoc {
+func (bu *BatchUpdater) parallelProcess(ctx context.Context, collection string, updateDocs []BatchDocument, now int64) []*UpdatedDoc {
 	updated := make([]*UpdatedDoc, len(updateDocs))
 	
 	numWorkers := bu.maxWorkers
@@ -77,7 +76,7 @@ func (bu *BatchUpdater) parallelProcess(ctx context.Context, collection string,
 		go func() {
 			defer wg.Done()
 			for idx := range jobs {
-				updated[idx] = bu.processDocument(collection, updateDocs[idx], now)
+				updated[idx] = bu.processDocument(collection, &updateDocs[idx], now)
 			}
 		}()
 	}
@@ -93,11 +92,11 @@ func (bu *BatchUpdater) parallelProcess(ctx context.Context, collection string,
 }
 
 // processDocument processes a single update
-func (bu *BatchUpdater) processDocument(collection string, updateDoc *proto.UpdateDocument, now int64) *UpdatedDoc {
+func (bu *BatchUpdater) processDocument(collection string, updateDoc *BatchDocument, now int64) *UpdatedDoc {
 	result := &UpdatedDoc{
 		Key:          updateDoc.Key,
 		Lang:         updateDoc.Lang,
-		SaveRevision: updateDoc.SaveRevision,
+		SaveRevision: bu.db.Collections.SaveRevision(collection, updateDoc.SaveRevision),
 	}
 	
 	// Validate
@@ -106,21 +105,23 @@ func (bu *BatchUpdater) processDocument(collection string, updateDoc *proto.Upda
 		return result
 	}
 	
-	// Convert meta
-	meta := make(map[string][]string)
-	for k, v := range updateDoc.Meta {
-		meta[k] = v.Values
-	}
+	meta := updateDoc.Meta
 	result.Meta = meta
 	
+	// Enforce collection schema
+	if err := bu.db.Schemas.Validate(collection, updateDoc.Key, updateDoc.Lang, meta, updateDoc.ContentMD); err != nil {
+		result.Error = err
+		return result
+	}
+	
 	// Generate ID
 	docID := genID(collection, updateDoc.Key, updateDoc.Lang)
 	result.DocID = docID
 	
 	// Load existing
 	existing := Doc{}
-	err := bu.server.DB.View(func(tx *bolt.Tx) error {
-		bDocs := tx.Bucket(bu.server.BucketNames.Docs)
+	err := bu.db.view(func(tx *bolt.Tx) error {
+		bDocs := tx.Bucket(bu.db.buckets.Docs)
 		if v := bDocs.Get(kDoc(collection, docID)); v != nil {
 			existingDoc, err := unmarshalDoc(v)
 			if err != nil {
@@ -150,13 +151,13 @@ func (bu *BatchUpdater) processDocument(collection string, updateDoc *proto.Upda
 		Key:       updateDoc.Key,
 		Lang:      updateDoc.Lang,
 		Meta:      meta,
-		ContentMD: updateDoc.ContentMd,
+		ContentMD: updateDoc.ContentMD,
 		AddedAt:   existing.AddedAt,
 		UpdatedAt: now,
 	}
 	
 	// Marshal
-	buf, err := marshalDoc(&doc)
+	buf, err := marshalDocWith(&doc, bu.db.Collections.Compression(collection))
 	if err != nil {
 		result.Error = err
 		return result
@@ -169,13 +170,14 @@ func (bu *BatchUpdater) processDocument(collection string, updateDoc *proto.Upda
 }
 
 // commitUpdate commits all updates in a single transaction
-func (bu *BatchUpdater) commitUpdate(collection string, updated []*UpdatedDoc, now int64) *proto.UpdateBatchResponse {
-	resp := &proto.UpdateBatchResponse{}
+func (bu *BatchUpdater) commitUpdate(collection string, updated []*UpdatedDoc, now int64) *UpdateBatchResult {
+	resp := &UpdateBatchResult{}
 	
+	bu.db.indexQueue.admit()
+
 	// Single transaction for all updates
-	err := bu.server.DB.Update(func(tx *bolt.Tx) error {
-		bDocs := tx.Bucket(bu.server.BucketNames.Docs)
-		bRev := tx.Bucket(bu.server.BucketNames.Rev)
+	err := bu.db.update(func(tx *bolt.Tx) error {
+		bDocs := tx.Bucket(bu.db.buckets.Docs)
 		
 		for _, u := range updated {
 			if u.Error != nil {
@@ -188,38 +190,42 @@ func (bu *BatchUpdater) commitUpdate(collection string, updated []*UpdatedDoc, n
 				continue
 			}
 			
-			// Update document
+			// Update document; the index job starts from the meta as of this transaction
 			docKey := kDoc(collection, u.DocID)
+			oldMeta := u.Existing.Meta
+			if v := bDocs.Get(docKey); v != nil {
+				if cur, err := unmarshalDoc(v); err == nil {
+					oldMeta = cur.Meta
+				}
+			}
 			if err := bDocs.Put(docKey, u.Buf); err != nil {
 				resp.Failed++
 				resp.Errors = append(resp.Errors, fmt.Sprintf("%s/%s: update error: %v", u.Key, u.Lang, err))
 				continue
 			}
+			bu.db.invalidateTx(tx, collection, u.Key, u.Lang)
 			
 			// Queue metadata reindexing (lazy)
-			if metadataChanged(u.Existing.Meta, u.Doc.Meta) {
-				bu.server.IndexQueue.Enqueue(&IndexJob{
+			if metadataChanged(oldMeta, u.Doc.Meta) {
+				if err := bu.db.indexQueue.enqueueTx(tx, &IndexJob{
 					Collection: collection,
 					DocID:      u.DocID,
-					OldMeta:    u.Existing.Meta,
+					OldMeta:    oldMeta,
 					NewMeta:    u.Doc.Meta,
-				})
+				}); err != nil {
+					return err
+				}
 			}
 			
 			// Revision (optional)
 			if u.SaveRevision {
-				rkey := append(kRevPrefix(collection, u.Doc.ID), []byte(fmt.Sprintf("%020d", now))...)
-				if err := bRev.Put(rkey, u.Buf); err != nil {
+				if err := bu.db.putRevision(tx, collection, u.Doc.ID, now, u.Buf); err != nil {
 					resp.Failed++
 					resp.Errors = append(resp.Errors, fmt.Sprintf("%s/%s: revision error: %v", u.Key, u.Lang, err))
 					continue
 				}
 			}
 			
-			// Update cache
-			cacheKey := BuildCacheKey(collection, u.Key, u.Lang)
-			bu.server.Cache.Set(cacheKey, u.Buf)
-			
 			resp.Updated++
 		}
 		
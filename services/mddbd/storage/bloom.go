@@ -1,4 +1,4 @@
-package main
+package storage
 
 import (
 	"sync"
@@ -43,7 +43,7 @@ func (bfm *BloomFilterManager) GetOrCreate(collection string, expectedItems uint
 // Add adds a key to the bloom filter
 func (bfm *BloomFilterManager) Add(collection, key, lang string) {
 	filter := bfm.GetOrCreate(collection, 10000) // Default 10k items
-	compositeKey := collection + "|" + key + "|" + lang
+	compositeKey := joinKeyParts(collection, key, lang)
 	filter.Add([]byte(compositeKey))
 }
 
@@ -54,7 +54,7 @@ func (bfm *BloomFilterManager) Test(collection, key, lang string) bool {
 		return false // Collection doesn't exist
 	}
 	
-	compositeKey := collection + "|" + key + "|" + lang
+	compositeKey := joinKeyParts(collection, key, lang)
 	return filter.(*bloom.BloomFilter).Test([]byte(compositeKey))
 }
 
@@ -98,16 +98,16 @@ type BloomStats struct {
 }
 
 // Rebuild rebuilds a bloom filter from database
-func (bfm *BloomFilterManager) Rebuild(s *Server, collection string) error {
+func (bfm *BloomFilterManager) Rebuild(db *DB, collection string) error {
 	// Clear existing filter
 	bfm.Clear(collection)
 	
 	// Count documents first
 	var count uint
-	err := s.DB.View(func(tx *bolt.Tx) error {
-		bDocs := tx.Bucket(s.BucketNames.Docs)
+	err := db.view(func(tx *bolt.Tx) error {
+		bDocs := tx.Bucket(db.buckets.Docs)
 		c := bDocs.Cursor()
-		prefix := []byte("doc|" + collection + "|")
+		prefix := kCollPrefix("doc", collection)
 		
 		for k, _ := c.Seek(prefix); k != nil && len(k) >= len(prefix); k, _ = c.Next() {
 			if string(k[:len(prefix)]) != string(prefix) {
@@ -126,10 +126,10 @@ func (bfm *BloomFilterManager) Rebuild(s *Server, collection string) error {
 	filter := bfm.GetOrCreate(collection, count+1000) // +1000 for growth
 	
 	// Populate filter
-	return s.DB.View(func(tx *bolt.Tx) error {
-		bDocs := tx.Bucket(s.BucketNames.Docs)
+	return db.view(func(tx *bolt.Tx) error {
+		bDocs := tx.Bucket(db.buckets.Docs)
 		c := bDocs.Cursor()
-		prefix := []byte("doc|" + collection + "|")
+		prefix := kCollPrefix("doc", collection)
 		
 		for k, v := c.Seek(prefix); k != nil && len(k) >= len(prefix); k, v = c.Next() {
 			if string(k[:len(prefix)]) != string(prefix) {
@@ -142,9 +142,24 @@ func (bfm *BloomFilterManager) Rebuild(s *Server, collection string) error {
 				continue
 			}
 			
-			compositeKey := collection + "|" + doc.Key + "|" + doc.Lang
+			compositeKey := joinKeyParts(collection, doc.Key, doc.Lang)
 			filter.Add([]byte(compositeKey))
 		}
 		return nil
 	})
 }
+
+// RebuildAll rebuilds every existing filter from the database, e.g. after it was restored
+func (bfm *BloomFilterManager) RebuildAll(db *DB) error {
+	var collections []string
+	bfm.filters.Range(func(key, _ interface{}) bool {
+		collections = append(collections, key.(string))
+		return true
+	})
+	for _, collection := range collections {
+		if err := bfm.Rebuild(db, collection); err != nil {
+			return err
+		}
+	}
+	return nil
+}
@@ -0,0 +1,296 @@
+package storage
+
+import (
+	"container/list"
+	"hash/maphash"
+	"math/bits"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+const (
+	cacheMaxShards   = 64
+	cacheShardTarget = 1 << 20 // bytes per shard the shard count aims for
+	cacheStripes     = 64      // version stripes per shard
+	cacheEntryCost   = 96      // bookkeeping bytes charged per entry on top of key and data
+	cacheAvgEntry    = 4 << 10 // assumed document size when sizing the frequency sketch
+)
+
+// CacheStats is a snapshot of the document cache
+type CacheStats struct {
+	Hits      uint64 `json:"hits"`
+	Misses    uint64 `json:"misses"`
+	Evictions uint64 `json:"evictions"`
+	Rejected  uint64 `json:"rejected"` // fills refused by the admission policy
+	Entries   int    `json:"entries"`
+	Bytes     int64  `json:"bytes"`
+	Budget    int64  `json:"budget"`
+}
+
+// DocumentCache keeps encoded documents in memory within a byte budget. It is sharded
+// LRU with TinyLFU admission: a new document only displaces others when it has been
+// requested more often recently, so scans and exports don't flush the hot set.
+//
+// Fills are versioned. Read paths take Version before reading the database and pass it
+// to Fill; a write that invalidated the key in between makes the fill a no-op, so a slow
+// reader never caches a document older than the latest commit.
+type DocumentCache struct {
+	seed   maphash.Seed
+	shards []*cacheShard
+	ttl    atomic.Int64 // time.Duration
+	budget atomic.Int64
+
+	hits, misses, evictions, rejected atomic.Uint64
+}
+
+type cacheShard struct {
+	mu       sync.Mutex
+	items    map[string]*list.Element
+	lru      *list.List // front = most recently used
+	bytes    int64
+	budget   int64
+	sketch   *cmSketch
+	versions [cacheStripes]uint64
+}
+
+type cacheEntry struct {
+	key       string
+	data      []byte
+	expiresAt time.Time
+}
+
+func (e *cacheEntry) cost() int64 { return int64(len(e.key) + len(e.data) + cacheEntryCost) }
+
+// NewDocumentCache creates a cache holding up to budget bytes; budget <= 0 disables it
+func NewDocumentCache(budget int64, ttl time.Duration) *DocumentCache {
+	if ttl <= 0 {
+		ttl = 5 * time.Minute
+	}
+	n := 1
+	if budget > 0 {
+		n = 1 << min(bits.Len64(uint64(budget/cacheShardTarget)), bits.Len(cacheMaxShards-1))
+	}
+	dc := &DocumentCache{seed: maphash.MakeSeed(), shards: make([]*cacheShard, n)}
+	dc.ttl.Store(int64(ttl))
+	dc.budget.Store(max(budget, 0))
+	for i := range dc.shards {
+		perShard := max(budget, 0) / int64(n)
+		dc.shards[i] = &cacheShard{
+			items:  make(map[string]*list.Element),
+			lru:    list.New(),
+			budget: perShard,
+			sketch: newCMSketch(int(perShard / cacheAvgEntry)),
+		}
+	}
+	return dc
+}
+
+// Resize changes the byte budget and TTL, evicting the least recently used documents
+// that no longer fit. The shard count stays as created, so a cache created disabled
+// grows as a single shard. budget <= 0 disables the cache, ttl <= 0 keeps the TTL.
+func (dc *DocumentCache) Resize(budget int64, ttl time.Duration) {
+	if ttl > 0 {
+		dc.ttl.Store(int64(ttl))
+	}
+	budget = max(budget, 0)
+	if dc.budget.Swap(budget) == budget {
+		return
+	}
+	perShard := budget / int64(len(dc.shards))
+	for _, s := range dc.shards {
+		s.mu.Lock()
+		s.budget = perShard
+		s.sketch = newCMSketch(int(perShard / cacheAvgEntry))
+		for s.bytes > s.budget {
+			s.remove(s.lru.Back())
+			dc.evictions.Add(1)
+		}
+		s.mu.Unlock()
+	}
+}
+
+func (dc *DocumentCache) locate(key string) (*cacheShard, uint64) {
+	h := maphash.String(dc.seed, key)
+	return dc.shards[h%uint64(len(dc.shards))], h
+}
+
+// Get returns the cached encoding of key
+func (dc *DocumentCache) Get(key string) ([]byte, bool) {
+	s, h := dc.locate(key)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.sketch.increment(h)
+	el, ok := s.items[key]
+	if ok && time.Now().After(el.Value.(*cacheEntry).expiresAt) {
+		s.remove(el)
+		ok = false
+	}
+	if !ok {
+		dc.misses.Add(1)
+		return nil, false
+	}
+	s.lru.MoveToFront(el)
+	dc.hits.Add(1)
+	return el.Value.(*cacheEntry).data, true
+}
+
+// Version returns the invalidation counter of key, to be passed to Fill
+func (dc *DocumentCache) Version(key string) uint64 {
+	s, h := dc.locate(key)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.versions[h%cacheStripes]
+}
+
+// Fill caches data for key unless key was invalidated since version was taken or the
+// admission policy prefers the documents it would evict
+func (dc *DocumentCache) Fill(key string, data []byte, version uint64) {
+	s, h := dc.locate(key)
+	e := &cacheEntry{key: key, data: data, expiresAt: time.Now().Add(time.Duration(dc.ttl.Load()))}
+	cost := e.cost()
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.versions[h%cacheStripes] != version || cost > s.budget {
+		return
+	}
+	if el, ok := s.items[key]; ok {
+		s.remove(el)
+	}
+
+	// Pick victims from the cold end; admit only if the newcomer is more popular than each
+	var victims []*list.Element
+	freed := int64(0)
+	for el := s.lru.Back(); s.bytes-freed+cost > s.budget; el = el.Prev() {
+		v := el.Value.(*cacheEntry)
+		if time.Now().Before(v.expiresAt) && s.sketch.estimate(maphash.String(dc.seed, v.key)) >= s.sketch.estimate(h) {
+			dc.rejected.Add(1)
+			return
+		}
+		victims = append(victims, el)
+		freed += v.cost()
+	}
+	for _, el := range victims {
+		s.remove(el)
+		dc.evictions.Add(1)
+	}
+	s.items[key] = s.lru.PushFront(e)
+	s.bytes += cost
+}
+
+// Invalidate drops key and fails fills of reads that started before
+func (dc *DocumentCache) Invalidate(key string) {
+	s, h := dc.locate(key)
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.versions[h%cacheStripes]++
+	if el, ok := s.items[key]; ok {
+		s.remove(el)
+	}
+}
+
+// InvalidatePrefix drops all keys starting with prefix; empty prefix clears the cache.
+// In-flight fills of any key are failed.
+func (dc *DocumentCache) InvalidatePrefix(prefix string) {
+	for _, s := range dc.shards {
+		s.mu.Lock()
+		for i := range s.versions {
+			s.versions[i]++
+		}
+		for key, el := range s.items {
+			if strings.HasPrefix(key, prefix) {
+				s.remove(el)
+			}
+		}
+		s.mu.Unlock()
+	}
+}
+
+// Clear removes all entries
+func (dc *DocumentCache) Clear() { dc.InvalidatePrefix("") }
+
+// Stats returns cache statistics
+func (dc *DocumentCache) Stats() CacheStats {
+	st := CacheStats{
+		Hits:      dc.hits.Load(),
+		Misses:    dc.misses.Load(),
+		Evictions: dc.evictions.Load(),
+		Rejected:  dc.rejected.Load(),
+		Budget:    dc.budget.Load(),
+	}
+	for _, s := range dc.shards {
+		s.mu.Lock()
+		st.Entries += len(s.items)
+		st.Bytes += s.bytes
+		s.mu.Unlock()
+	}
+	return st
+}
+
+func (s *cacheShard) remove(el *list.Element) {
+	e := s.lru.Remove(el).(*cacheEntry)
+	delete(s.items, e.key)
+	s.bytes -= e.cost()
+}
+
+// cmSketch is a count-min sketch of 4-bit counters estimating recent access frequency.
+// All counters are halved after a sample period so old popularity fades (TinyLFU).
+type cmSketch struct {
+	rows    [4][]uint64 // 16 counters per word
+	mask    uint64
+	adds    int
+	samples int
+}
+
+func newCMSketch(entries int) *cmSketch {
+	words := 1 << bits.Len(uint(max(entries, 64)/16))
+	s := &cmSketch{mask: uint64(words*16 - 1), samples: 10 * words * 16}
+	for i := range s.rows {
+		s.rows[i] = make([]uint64, words)
+	}
+	return s
+}
+
+// counter returns the word and shift of h's counter in row i
+func (s *cmSketch) counter(i int, h uint64) (*uint64, uint) {
+	idx := (h>>(16*i) ^ h*uint64(2*i+1)) & s.mask
+	return &s.rows[i][idx/16], uint(idx%16) * 4
+}
+
+func (s *cmSketch) increment(h uint64) {
+	for i := range s.rows {
+		w, shift := s.counter(i, h)
+		if (*w>>shift)&0xf < 0xf {
+			*w += 1 << shift
+		}
+	}
+	if s.adds++; s.adds >= s.samples {
+		for i := range s.rows {
+			for j := range s.rows[i] {
+				s.rows[i][j] = (s.rows[i][j] >> 1) & 0x7777777777777777
+			}
+		}
+		s.adds /= 2
+	}
+}
+
+func (s *cmSketch) estimate(h uint64) uint64 {
+	est := uint64(0xf)
+	for i := range s.rows {
+		w, shift := s.counter(i, h)
+		est = min(est, (*w>>shift)&0xf)
+	}
+	return est
+}
+
+// BuildCacheKey builds a cache key for a document
+func BuildCacheKey(collection, key, lang string) string {
+	return joinKeyParts(collection, key, lang)
+}
+
+// collectionCachePrefix is the prefix of the cache keys of a collection's documents
+func collectionCachePrefix(collection string) string {
+	return joinKeyParts(collection) + "|"
+}
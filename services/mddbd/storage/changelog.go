@@ -0,0 +1,580 @@
+package storage
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	json "github.com/goccy/go-json"
+	bolt "go.etcd.io/bbolt"
+)
+
+var (
+	// ErrNoRecoveryPoint is returned when the change log cannot roll a backup forward to the requested time
+	ErrNoRecoveryPoint = errors.New("point in time not recoverable")
+	// ErrBackupTooNew is returned by RestoreAt for a backup holding changes made after the requested time
+	ErrBackupTooNew = fmt.Errorf("%w: the backup has changes made after that time", ErrNoRecoveryPoint)
+)
+
+const (
+	changeSegmentPrefix = "changes-"
+	changeSegmentExt    = ".wal"
+	changeSegmentTime   = "20060102T150405.000000000Z" // segment names sort by time
+)
+
+// changeLogKey holds the changeLogState of the file in the system bucket
+var changeLogKey = []byte("changeLog")
+
+// Change log operations
+const (
+	changeDoc             = "doc" // pending only: logged as put or delete with the state the transaction left
+	changePut             = "put"
+	changeDelete          = "delete"
+	changeSet             = "set" // a key of a settings bucket (collections, schema, apikeys)
+	changeDropCollection  = "dropCollection"
+	changeCloneCollection = "cloneCollection"
+	changeTruncate        = "truncate"
+)
+
+// change is one change of a logged transaction
+type change struct {
+	Op         string `json:"op"`
+	Collection string `json:"collection,omitempty"`
+	Key        string `json:"key,omitempty"`
+	Lang       string `json:"lang,omitempty"`
+	Doc        *Doc   `json:"doc,omitempty"`      // put: the document as committed
+	Revision   bool   `json:"revision,omitempty"` // put: also saved as a revision at Doc.UpdatedAt
+	To         string `json:"to,omitempty"`       // cloneCollection
+	Bucket     string `json:"bucket,omitempty"`   // set
+	Value      []byte `json:"value,omitempty"`    // set: empty deletes the key
+	KeepRevs   int    `json:"keepRevs,omitempty"` // truncate
+}
+
+// changeRecord is the change log entry of one committed write transaction
+type changeRecord struct {
+	Timeline string   `json:"timeline"`
+	Seq      uint64   `json:"seq"`
+	Changes  []change `json:"changes"`
+}
+
+// changeLogState is stored in the database file: the timeline the file belongs to and
+// the last change logged for it. Backups carry it, so a replay knows where to start.
+type changeLogState struct {
+	Timeline string `json:"timeline"`
+	Seq      uint64 `json:"seq"`
+	Time     int64  `json:"time"` // unix nanoseconds of the last change
+}
+
+// timelineSwitch is logged when the database starts a new timeline: on open, where the
+// new timeline continues the file's old one, and when a restore replaced the file.
+type timelineSwitch struct {
+	From      string `json:"from"`
+	FromSeq   uint64 `json:"fromSeq"`
+	To        string `json:"to"`
+	Continued bool   `json:"continued"`
+}
+
+// ChangeLogStats is a snapshot of the change log
+type ChangeLogStats struct {
+	Records  uint64 // transactions logged since the database was opened
+	Failed   uint64 // committed transactions that could not be logged
+	Segments int
+	Size     int64 // bytes across all segments
+}
+
+// changeLog archives every committed write transaction in segment files, so a backup can
+// be rolled forward to any point in time after it (see RestoreAt). Records hold the
+// state a transaction left, not the request, so replaying them is exact.
+type changeLog struct {
+	dir string
+
+	// mu is held from the moment a transaction takes its sequence number until its record
+	// is appended, so records are in commit order
+	mu  sync.Mutex
+	seg *WAL
+
+	// pending collects the changes of the running write transaction; BoltDB runs one at a time
+	pending []change
+
+	records atomic.Uint64
+	failed  atomic.Uint64
+}
+
+// newChangeLog starts a new segment in dir
+func newChangeLog(dir string) (*changeLog, error) {
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return nil, err
+	}
+	cl := &changeLog{dir: dir}
+	if err := cl.rotate(); err != nil {
+		return nil, err
+	}
+	return cl, nil
+}
+
+// rotate closes the current segment and starts a new one; the caller holds mu
+func (cl *changeLog) rotate() error {
+	name := changeSegmentPrefix + time.Now().UTC().Format(changeSegmentTime) + changeSegmentExt
+	seg, err := openWAL(filepath.Join(cl.dir, name), SyncPeriodic)
+	if err != nil {
+		return err
+	}
+	if cl.seg != nil {
+		if err := cl.seg.Close(); err != nil {
+			log.Printf("Closing change log segment: %v", err)
+		}
+	}
+	cl.seg = seg
+	return nil
+}
+
+func (cl *changeLog) close() error {
+	cl.mu.Lock()
+	defer cl.mu.Unlock()
+	return cl.seg.Close()
+}
+
+// changeSegment is a change log file with the time it was started
+type changeSegment struct {
+	path  string
+	start time.Time
+	size  int64
+}
+
+// segments lists the change log files, oldest first
+func (cl *changeLog) segments() ([]changeSegment, error) {
+	entries, err := os.ReadDir(cl.dir)
+	if err != nil {
+		return nil, err
+	}
+	var segs []changeSegment
+	for _, e := range entries {
+		name := e.Name()
+		if e.IsDir() || !strings.HasPrefix(name, changeSegmentPrefix) || !strings.HasSuffix(name, changeSegmentExt) {
+			continue
+		}
+		start, err := time.Parse(changeSegmentTime, strings.TrimSuffix(strings.TrimPrefix(name, changeSegmentPrefix), changeSegmentExt))
+		if err != nil {
+			continue
+		}
+		seg := changeSegment{path: filepath.Join(cl.dir, name), start: start}
+		if info, err := e.Info(); err == nil {
+			seg.size = info.Size()
+		}
+		segs = append(segs, seg)
+	}
+	sort.Slice(segs, func(i, j int) bool { return segs[i].start.Before(segs[j].start) })
+	return segs, nil
+}
+
+func (cl *changeLog) stats() *ChangeLogStats {
+	s := &ChangeLogStats{Records: cl.records.Load(), Failed: cl.failed.Load()}
+	segs, _ := cl.segments()
+	s.Segments = len(segs)
+	for _, seg := range segs {
+		s.Size += seg.size
+	}
+	return s
+}
+
+// openChangeLog starts logging to dir on a new timeline that continues the file's
+func (db *DB) openChangeLog(dir string) error {
+	cl, err := newChangeLog(dir)
+	if err != nil {
+		return err
+	}
+	db.changeLog = cl
+	state, err := db.changeLogState()
+	if err != nil {
+		return err
+	}
+	lastChange := state.Time
+	if state.Timeline == "" {
+		// Changes made before the log was enabled have no record: the file's content is
+		// only known from now on
+		lastChange = time.Now().UnixNano()
+	}
+	return db.startTimeline(state, true, lastChange)
+}
+
+// RotateChangeLog starts a new change log segment. Every change in the earlier segments
+// is committed, so a backup started after the rotation contains them all and
+// PruneChangeLog can drop those segments once older backups are gone.
+func (db *DB) RotateChangeLog() error {
+	if db.changeLog == nil {
+		return nil
+	}
+	db.changeLog.mu.Lock()
+	defer db.changeLog.mu.Unlock()
+	return db.changeLog.rotate()
+}
+
+// PruneChangeLog removes the segments that were complete before the given time, e.g. the
+// start of the oldest backup that is kept. It returns the number of segments removed.
+func (db *DB) PruneChangeLog(before time.Time) (int, error) {
+	if db.changeLog == nil {
+		return 0, nil
+	}
+	segs, err := db.changeLog.segments()
+	if err != nil {
+		return 0, err
+	}
+	var removed int
+	// The newest segment is never removed: it is the one being written
+	for i := 0; i+1 < len(segs) && !segs[i+1].start.After(before); i++ {
+		if err := os.Remove(segs[i].path); err != nil {
+			return removed, err
+		}
+		removed++
+	}
+	return removed, nil
+}
+
+// logChange adds a change to the log record of the running write transaction
+func (db *DB) logChange(c change) {
+	if db.changeLog != nil {
+		db.changeLog.pending = append(db.changeLog.pending, c)
+	}
+}
+
+// logKey logs the value a write transaction leaves at key of a settings bucket
+func (db *DB) logKey(bucket []byte, key string) {
+	db.logChange(change{Op: changeSet, Bucket: string(bucket), Key: key})
+}
+
+// captureChanges turns the pending changes of tx into its log record and takes the next
+// sequence number in tx. The returned func appends the record once tx has committed.
+func (db *DB) captureChanges(tx *bolt.Tx) (func(committed bool), error) {
+	cl := db.changeLog
+	pending := cl.pending
+	cl.pending = nil
+	if len(pending) == 0 {
+		return nil, nil
+	}
+	changes, err := db.resolveChanges(tx, pending)
+	if err != nil {
+		return nil, err
+	}
+
+	cl.mu.Lock()
+	now := time.Now().UnixNano()
+	bSys := tx.Bucket(db.buckets.System)
+	state, err := readChangeLogState(bSys)
+	var data []byte
+	if err == nil {
+		state.Seq++
+		state.Time = now
+		err = putChangeLogState(bSys, state)
+	}
+	if err == nil {
+		data, err = json.Marshal(changeRecord{Timeline: state.Timeline, Seq: state.Seq, Changes: changes})
+	}
+	if err != nil {
+		cl.mu.Unlock()
+		return nil, err
+	}
+	return func(committed bool) {
+		defer cl.mu.Unlock()
+		if !committed {
+			return
+		}
+		if err := cl.seg.Write(&WALEntry{Type: EntryTypeCommit, Timestamp: now, Data: data}); err != nil {
+			// Replays through this change fail with a gap instead of skipping it
+			cl.failed.Add(1)
+			log.Printf("Change log: change %d not logged: %v", state.Seq, err)
+			return
+		}
+		cl.records.Add(1)
+	}, nil
+}
+
+// resolveChanges reads the state tx left for the documents and settings it touched
+func (db *DB) resolveChanges(tx *bolt.Tx, pending []change) ([]change, error) {
+	seen := make(map[string]bool, len(pending))
+	out := make([]change, 0, len(pending))
+	for _, c := range pending {
+		switch c.Op {
+		case changeDoc:
+			id := genID(c.Collection, c.Key, c.Lang)
+			if seen[c.Collection+"\x00"+id] {
+				continue
+			}
+			seen[c.Collection+"\x00"+id] = true
+			v := tx.Bucket(db.buckets.Docs).Get(kDoc(c.Collection, id))
+			if v == nil {
+				out = append(out, change{Op: changeDelete, Collection: c.Collection, Key: c.Key, Lang: c.Lang})
+				continue
+			}
+			doc, err := unmarshalDoc(v)
+			if err != nil {
+				return nil, err
+			}
+			rev := bytes.Equal(db.revisionAt(tx, c.Collection, id, doc.UpdatedAt), v)
+			out = append(out, change{Op: changePut, Collection: c.Collection, Doc: doc, Revision: rev})
+		case changeSet:
+			if seen[c.Bucket+"\x00"+c.Key] {
+				continue
+			}
+			seen[c.Bucket+"\x00"+c.Key] = true
+			c.Value = CopyBytes(tx.Bucket([]byte(c.Bucket)).Get([]byte(c.Key)))
+			out = append(out, c)
+		default:
+			out = append(out, c)
+		}
+	}
+	return out, nil
+}
+
+func readChangeLogState(b *bolt.Bucket) (changeLogState, error) {
+	var s changeLogState
+	if v := b.Get(changeLogKey); v != nil {
+		if err := json.Unmarshal(v, &s); err != nil {
+			return s, fmt.Errorf("change log state: %w", err)
+		}
+	}
+	return s, nil
+}
+
+func putChangeLogState(b *bolt.Bucket, s changeLogState) error {
+	buf, err := json.Marshal(s)
+	if err != nil {
+		return err
+	}
+	return b.Put(changeLogKey, buf)
+}
+
+// changeLogState reads the change log state of the open file. The caller keeps the file
+// from being swapped (holds writeMu or swapMu).
+func (db *DB) changeLogState() (changeLogState, error) {
+	var s changeLogState
+	err := db.bolt.View(func(tx *bolt.Tx) error {
+		var err error
+		s, err = readChangeLogState(tx.Bucket(db.buckets.System))
+		return err
+	})
+	return s, err
+}
+
+// startTimeline moves the open file to a new timeline and logs the switch from the
+// state it had. No write may run meanwhile: the caller holds writeMu or is opening the
+// database. lastChange is the time the file's content was last changed.
+func (db *DB) startTimeline(from changeLogState, continued bool, lastChange int64) error {
+	id := make([]byte, 8)
+	if _, err := rand.Read(id); err != nil {
+		return err
+	}
+	to := changeLogState{Timeline: hex.EncodeToString(id), Time: lastChange}
+	err := db.bolt.Update(func(tx *bolt.Tx) error {
+		return putChangeLogState(tx.Bucket(db.buckets.System), to)
+	})
+	if err != nil || from.Timeline == "" {
+		return err
+	}
+
+	data, err := json.Marshal(timelineSwitch{From: from.Timeline, FromSeq: from.Seq, To: to.Timeline, Continued: continued})
+	if err != nil {
+		return err
+	}
+	db.changeLog.mu.Lock()
+	defer db.changeLog.mu.Unlock()
+	return db.changeLog.seg.Write(&WALEntry{Type: EntryTypeTimeline, Timestamp: time.Now().UnixNano(), Data: data})
+}
+
+// replay rolls the database file at path forward to at with the logged changes of its
+// timeline, and returns the number of transactions replayed
+func (cl *changeLog) replay(path string, at time.Time, opts Options) (int, error) {
+	cl.mu.Lock()
+	err := cl.seg.Sync()
+	cl.mu.Unlock()
+	if err != nil {
+		return 0, err
+	}
+
+	sdb, err := Open(path, &opts)
+	if err != nil {
+		return 0, fmt.Errorf("%w: %v", ErrInvalidBackup, err)
+	}
+	defer func() { _ = sdb.Close() }()
+	state, err := sdb.changeLogState()
+	if err != nil {
+		return 0, err
+	}
+	if state.Timeline == "" {
+		return 0, fmt.Errorf("%w: the backup was taken before the change log was enabled", ErrNoRecoveryPoint)
+	}
+	if state.Time > at.UnixNano() {
+		return 0, ErrBackupTooNew
+	}
+
+	segs, err := cl.segments()
+	if err != nil {
+		return 0, err
+	}
+	var replayed int
+	for _, seg := range segs {
+		done, err := cl.replaySegment(sdb, seg.path, &state, at.UnixNano(), &replayed)
+		if err != nil || done {
+			return replayed, err
+		}
+	}
+	return replayed, nil
+}
+
+// replaySegment applies the records of one segment; done reports that at was reached
+func (cl *changeLog) replaySegment(sdb *DB, path string, state *changeLogState, at int64, replayed *int) (done bool, err error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return false, err
+	}
+	defer func() { _ = f.Close() }()
+
+	r := bufio.NewReader(f)
+	for {
+		entry, err := readWALEntry(r)
+		if err == io.EOF || err == io.ErrUnexpectedEOF {
+			return false, nil // a crash can leave the last entry torn
+		}
+		if err != nil {
+			// Changes missing past this point fail the replay with a gap
+			log.Printf("Change log %s: %v; skipping the rest of the segment", filepath.Base(path), err)
+			return false, nil
+		}
+		if entry.Timestamp > at {
+			return true, nil
+		}
+
+		switch entry.Type {
+		case EntryTypeTimeline:
+			var sw timelineSwitch
+			if err := json.Unmarshal(entry.Data, &sw); err != nil {
+				return false, err
+			}
+			if sw.From != state.Timeline {
+				continue
+			}
+			switch {
+			case !sw.Continued:
+				return false, fmt.Errorf("%w: a restore replaced the database at %s", ErrNoRecoveryPoint, time.Unix(0, entry.Timestamp).UTC().Format(time.RFC3339))
+			case sw.FromSeq > state.Seq:
+				return false, fmt.Errorf("%w: change %d is missing from the change log", ErrNoRecoveryPoint, state.Seq+1)
+			case sw.FromSeq < state.Seq:
+				return false, fmt.Errorf("%w: the database file was replaced at %s", ErrNoRecoveryPoint, time.Unix(0, entry.Timestamp).UTC().Format(time.RFC3339))
+			}
+			state.Timeline, state.Seq = sw.To, 0
+		case EntryTypeCommit:
+			var rec changeRecord
+			if err := json.Unmarshal(entry.Data, &rec); err != nil {
+				return false, err
+			}
+			if rec.Timeline != state.Timeline || rec.Seq <= state.Seq {
+				continue
+			}
+			if rec.Seq != state.Seq+1 {
+				return false, fmt.Errorf("%w: change %d is missing from the change log", ErrNoRecoveryPoint, state.Seq+1)
+			}
+			for i := range rec.Changes {
+				if err := sdb.applyChange(&rec.Changes[i]); err != nil {
+					return false, fmt.Errorf("replaying change %d: %w", rec.Seq, err)
+				}
+			}
+			state.Seq = rec.Seq
+			*replayed++
+		}
+	}
+}
+
+// applyChange replays a logged change
+func (db *DB) applyChange(c *change) error {
+	switch c.Op {
+	case changePut:
+		return db.update(func(tx *bolt.Tx) error {
+			return db.replayPutTx(tx, c.Collection, c.Doc, c.Revision)
+		})
+	case changeDelete:
+		err := db.update(func(tx *bolt.Tx) error {
+			return db.deleteTx(tx, c.Collection, c.Key, c.Lang, nil)
+		})
+		if errors.Is(err, ErrNotFound) {
+			return nil
+		}
+		return err
+	case changeSet:
+		err := db.update(func(tx *bolt.Tx) error {
+			b := tx.Bucket([]byte(c.Bucket))
+			if b == nil {
+				return fmt.Errorf("unknown bucket %q", c.Bucket)
+			}
+			if len(c.Value) == 0 {
+				return b.Delete([]byte(c.Key))
+			}
+			return b.Put([]byte(c.Key), c.Value)
+		})
+		if err != nil {
+			return err
+		}
+		switch c.Bucket {
+		case string(db.buckets.Collections):
+			return db.Collections.Load()
+		case string(db.buckets.Schema):
+			db.Schemas.Forget(c.Key)
+		}
+		return nil
+	case changeDropCollection:
+		_, err := db.Collections.Delete(c.Collection)
+		return err
+	case changeCloneCollection:
+		_, err := db.Collections.Clone(c.Collection, c.To)
+		return err
+	case changeTruncate:
+		return db.Truncate(c.Collection, c.KeepRevs)
+	}
+	return fmt.Errorf("unknown change %q", c.Op)
+}
+
+// replayPutTx stores a logged document as it was committed, timestamps included
+func (db *DB) replayPutTx(tx *bolt.Tx, collection string, doc *Doc, revision bool) error {
+	if err := db.Collections.Ensure(tx, collection); err != nil {
+		return err
+	}
+	bDocs := tx.Bucket(db.buckets.Docs)
+
+	var oldMeta map[string][]string
+	if v := bDocs.Get(kDoc(collection, doc.ID)); v != nil {
+		existing, err := unmarshalDoc(v)
+		if err != nil {
+			return err
+		}
+		oldMeta = existing.Meta
+	}
+	buf, err := marshalDocWith(doc, db.Collections.Compression(collection))
+	if err != nil {
+		return err
+	}
+	if err := bDocs.Put(kDoc(collection, doc.ID), buf); err != nil {
+		return err
+	}
+	if err := tx.Bucket(db.buckets.ByKey).Put(kByKey(collection, doc.Key, doc.Lang), []byte(doc.ID)); err != nil {
+		return err
+	}
+	db.invalidateTx(tx, collection, doc.Key, doc.Lang)
+	job := &IndexJob{Collection: collection, DocID: doc.ID, OldMeta: oldMeta, NewMeta: doc.Meta}
+	if err := job.apply(tx.Bucket(db.buckets.IdxMeta)); err != nil {
+		return err
+	}
+	if revision {
+		return db.putRevision(tx, collection, doc.ID, doc.UpdatedAt, buf)
+	}
+	return nil
+}
@@ -0,0 +1,121 @@
+package storage
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func openWithChangeLog(t *testing.T) *DB {
+	t.Helper()
+	dir := t.TempDir()
+	db, err := Open(filepath.Join(dir, "mddb.db"), &Options{ChangeLogDir: filepath.Join(dir, "changes")})
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = db.Close() })
+	return db
+}
+
+func addDoc(t *testing.T, db *DB, key string) {
+	t.Helper()
+	if _, err := db.Add(AddRequest{Collection: "blog", Key: key, Lang: "en", ContentMD: "# " + key, Meta: map[string][]string{"tag": {key}}}); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func backupTo(t *testing.T, db *DB, name string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	if err := db.Backup(path); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func restoreAt(db *DB, backup string, at time.Time) (*RestoreResult, error) {
+	f, err := os.Open(backup)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = f.Close() }()
+	return db.RestoreAt(f, at, RestoreOptions{})
+}
+
+func TestRestoreAtReplaysChanges(t *testing.T) {
+	db := openWithChangeLog(t)
+	addDoc(t, db, "first")
+	backup := backupTo(t, db, "backup.db")
+
+	addDoc(t, db, "second")
+	if err := db.Delete("blog", "first", "en"); err != nil {
+		t.Fatal(err)
+	}
+	time.Sleep(10 * time.Millisecond)
+	at := time.Now()
+	time.Sleep(10 * time.Millisecond)
+	addDoc(t, db, "third")
+	newer := backupTo(t, db, "newer.db")
+
+	res, err := restoreAt(db, backup, at)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.Replayed != 2 {
+		t.Errorf("replayed %d transactions, want 2", res.Replayed)
+	}
+	for key, want := range map[string]error{"first": ErrNotFound, "second": nil, "third": ErrNotFound} {
+		if _, err := db.Get("blog", key, "en"); !errors.Is(err, want) {
+			t.Errorf("Get(%s): %v, want %v", key, err, want)
+		}
+	}
+	// The replayed put is indexed like the original write
+	docs, _, err := db.Search(SearchQuery{Collection: "blog", FilterMeta: map[string][]string{"tag": {"second"}}, Consistency: "strong"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(docs) != 1 {
+		t.Errorf("search by replayed meta found %d documents, want 1", len(docs))
+	}
+
+	if _, err := restoreAt(db, newer, at); !errors.Is(err, ErrBackupTooNew) {
+		t.Errorf("RestoreAt with a newer backup: %v, want ErrBackupTooNew", err)
+	}
+}
+
+func TestRestoreAtDetectsGap(t *testing.T) {
+	db := openWithChangeLog(t)
+	addDoc(t, db, "first")
+	backup := backupTo(t, db, "backup.db")
+
+	// Each change in a segment of its own, then lose the middle one
+	addDoc(t, db, "second")
+	if err := db.RotateChangeLog(); err != nil {
+		t.Fatal(err)
+	}
+	addDoc(t, db, "lost")
+	segs, err := db.changeLog.segments()
+	if err != nil {
+		t.Fatal(err)
+	}
+	lost := segs[len(segs)-1].path
+	if err := db.RotateChangeLog(); err != nil {
+		t.Fatal(err)
+	}
+	addDoc(t, db, "third")
+	if err := os.Remove(lost); err != nil {
+		t.Fatal(err)
+	}
+
+	_, err = restoreAt(db, backup, time.Now())
+	if !errors.Is(err, ErrNoRecoveryPoint) || !strings.Contains(err.Error(), "missing") {
+		t.Fatalf("RestoreAt across a lost segment: %v, want a missing change", err)
+	}
+	// The failed restore left the database alone
+	if _, err := db.Get("blog", "third", "en"); err != nil {
+		t.Errorf("Get(third) after the failed restore: %v", err)
+	}
+}
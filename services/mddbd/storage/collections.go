@@ -0,0 +1,548 @@
+package storage
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	json "github.com/goccy/go-json"
+	bolt "go.etcd.io/bbolt"
+)
+
+// Revision policies
+const (
+	RevisionPolicyRequest = "request" // save revisions when the write asks for it (default)
+	RevisionPolicyAlways  = "always"  // save a revision on every write
+	RevisionPolicyNever   = "never"   // never save revisions
+)
+
+// Compression settings
+const (
+	CompressionAuto   = "auto" // adaptive: none/snappy/zstd by size (default)
+	CompressionNone   = "none"
+	CompressionSnappy = "snappy"
+	CompressionZstd   = "zstd"
+)
+
+var (
+	ErrCollectionExists   = errors.New("collection already exists")
+	ErrCollectionNotFound = errors.New("collection not found")
+)
+
+// CollectionInfo is a registry entry with per-collection settings
+type CollectionInfo struct {
+	Name           string `json:"name"`
+	Description    string `json:"description"`
+	DefaultLang    string `json:"defaultLang"`    // used when a write omits lang
+	RevisionPolicy string `json:"revisionPolicy"` // request|always|never
+	Compression    string `json:"compression"`    // auto|none|snappy|zstd
+	CreatedAt      int64  `json:"createdAt"`
+	UpdatedAt      int64  `json:"updatedAt"`
+}
+
+// CollectionDescription is a registry entry plus live counts
+type CollectionDescription struct {
+	CollectionInfo
+	DocumentCount int  `json:"documentCount"`
+	RevisionCount int  `json:"revisionCount"`
+	HasSchema     bool `json:"hasSchema"`
+}
+
+// CollectionRegistry keeps all registered collections in memory, backed by the collections bucket.
+// Listing is O(collections); the docs bucket is only scanned once to backfill old databases.
+type CollectionRegistry struct {
+	db          *DB
+	mu          sync.RWMutex
+	collections map[string]*CollectionInfo
+}
+
+// NewCollectionRegistry creates a new collection registry
+func NewCollectionRegistry(db *DB) *CollectionRegistry {
+	return &CollectionRegistry{
+		db:          db,
+		collections: make(map[string]*CollectionInfo),
+	}
+}
+
+// normalize fills defaults and checks settings
+func (ci *CollectionInfo) normalize() error {
+	if ci.Name == "" {
+		return errors.New("missing collection name")
+	}
+	switch ci.RevisionPolicy {
+	case "":
+		ci.RevisionPolicy = RevisionPolicyRequest
+	case RevisionPolicyRequest, RevisionPolicyAlways, RevisionPolicyNever:
+	default:
+		return fmt.Errorf("invalid revisionPolicy: %s", ci.RevisionPolicy)
+	}
+	switch ci.Compression {
+	case "":
+		ci.Compression = CompressionAuto
+	case CompressionAuto, CompressionNone, CompressionSnappy, CompressionZstd:
+	default:
+		return fmt.Errorf("invalid compression: %s", ci.Compression)
+	}
+	return nil
+}
+
+// Load reads the registry from the database, backfilling it from the docs bucket if it is empty
+func (cr *CollectionRegistry) Load() error {
+	loaded := make(map[string]*CollectionInfo)
+
+	err := cr.db.update(func(tx *bolt.Tx) error {
+		bColl := tx.Bucket(cr.db.buckets.Collections)
+		if err := bColl.ForEach(func(k, v []byte) error {
+			var ci CollectionInfo
+			if err := json.Unmarshal(v, &ci); err != nil {
+				return fmt.Errorf("collection %s: %w", k, err)
+			}
+			loaded[ci.Name] = &ci
+			return nil
+		}); err != nil {
+			return err
+		}
+		if len(loaded) > 0 {
+			return nil
+		}
+
+		// One-time migration: register collections that only exist as key prefixes.
+		// Open loads the registry before key migration, so keys may still be unescaped.
+		now := time.Now().Unix()
+		c := tx.Bucket(cr.db.buckets.Docs).Cursor()
+		prefix := []byte("doc|")
+		for k, v := c.Seek(prefix); k != nil && bytes.HasPrefix(k, prefix); k, v = c.Next() {
+			name := keyCollection(k)
+			if cr.db.isLegacyDocKey(tx, k) {
+				doc, err := unmarshalDoc(v)
+				if err != nil {
+					return fmt.Errorf("%s: %w", k, err)
+				}
+				name, _ = legacyDocCollection(k, doc)
+			}
+			if name == "" || loaded[name] != nil {
+				continue
+			}
+			ci := &CollectionInfo{Name: name, CreatedAt: now, UpdatedAt: now}
+			_ = ci.normalize()
+			loaded[ci.Name] = ci
+		}
+		for _, ci := range loaded {
+			if err := putCollectionInfo(bColl, ci); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	cr.mu.Lock()
+	cr.collections = loaded
+	cr.mu.Unlock()
+	return nil
+}
+
+func putCollectionInfo(b *bolt.Bucket, ci *CollectionInfo) error {
+	buf, err := json.Marshal(ci)
+	if err != nil {
+		return err
+	}
+	return b.Put([]byte(ci.Name), buf)
+}
+
+// Get returns a copy of a registry entry, or nil if the collection is not registered
+func (cr *CollectionRegistry) Get(name string) *CollectionInfo {
+	cr.mu.RLock()
+	defer cr.mu.RUnlock()
+	if ci, ok := cr.collections[name]; ok {
+		cp := *ci
+		return &cp
+	}
+	return nil
+}
+
+// List returns all registered collections sorted by name
+func (cr *CollectionRegistry) List() []CollectionInfo {
+	cr.mu.RLock()
+	out := make([]CollectionInfo, 0, len(cr.collections))
+	for _, ci := range cr.collections {
+		out = append(out, *ci)
+	}
+	cr.mu.RUnlock()
+
+	sort.Slice(out, func(i, j int) bool { return out[i].Name < out[j].Name })
+	return out
+}
+
+// Ensure registers a collection with default settings inside a write transaction.
+// Called from every write path so implicitly created collections show up in listings.
+// It goes by the bucket rather than the in-memory registry, which only catches up
+// once the transaction that deleted or created a collection has committed.
+func (cr *CollectionRegistry) Ensure(tx *bolt.Tx, name string) error {
+	bColl := tx.Bucket(cr.db.buckets.Collections)
+	if bColl.Get([]byte(name)) != nil {
+		return nil
+	}
+	now := time.Now().Unix()
+	ci := &CollectionInfo{Name: name, CreatedAt: now, UpdatedAt: now}
+	if err := ci.normalize(); err != nil {
+		return err
+	}
+	if err := putCollectionInfo(bColl, ci); err != nil {
+		return err
+	}
+	cr.db.logKey(cr.db.buckets.Collections, name)
+	cr.setOnCommit(tx, ci)
+	return nil
+}
+
+// setOnCommit puts ci into the in-memory registry once tx commits. Commit hooks run
+// in commit order (see DB.update), so the registry never sees an older write last.
+func (cr *CollectionRegistry) setOnCommit(tx *bolt.Tx, ci *CollectionInfo) {
+	tx.OnCommit(func() {
+		cr.mu.Lock()
+		cr.collections[ci.Name] = ci
+		cr.mu.Unlock()
+	})
+}
+
+// Create registers a new collection with the given settings
+func (cr *CollectionRegistry) Create(ci CollectionInfo) (*CollectionInfo, error) {
+	if err := ci.normalize(); err != nil {
+		return nil, err
+	}
+	now := time.Now().Unix()
+	ci.CreatedAt, ci.UpdatedAt = now, now
+
+	err := cr.db.update(func(tx *bolt.Tx) error {
+		bColl := tx.Bucket(cr.db.buckets.Collections)
+		if bColl.Get([]byte(ci.Name)) != nil {
+			return ErrCollectionExists
+		}
+		cr.db.logKey(cr.db.buckets.Collections, ci.Name)
+		cr.setOnCommit(tx, &ci)
+		return putCollectionInfo(bColl, &ci)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &ci, nil
+}
+
+// Update replaces the settings of an existing collection
+func (cr *CollectionRegistry) Update(ci CollectionInfo) (*CollectionInfo, error) {
+	if err := ci.normalize(); err != nil {
+		return nil, err
+	}
+	existing := cr.Get(ci.Name)
+	if existing == nil {
+		return nil, ErrCollectionNotFound
+	}
+	ci.CreatedAt = existing.CreatedAt
+	ci.UpdatedAt = time.Now().Unix()
+
+	err := cr.db.update(func(tx *bolt.Tx) error {
+		bColl := tx.Bucket(cr.db.buckets.Collections)
+		if bColl.Get([]byte(ci.Name)) == nil {
+			return ErrCollectionNotFound // deleted meanwhile
+		}
+		cr.db.logKey(cr.db.buckets.Collections, ci.Name)
+		cr.setOnCommit(tx, &ci)
+		return putCollectionInfo(bColl, &ci)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &ci, nil
+}
+
+// Describe returns a registry entry with document and revision counts
+func (cr *CollectionRegistry) Describe(name string) (*CollectionDescription, error) {
+	ci := cr.Get(name)
+	if ci == nil {
+		return nil, ErrCollectionNotFound
+	}
+	desc := &CollectionDescription{CollectionInfo: *ci}
+
+	err := cr.db.view(func(tx *bolt.Tx) error {
+		c := tx.Bucket(cr.db.buckets.Docs).Cursor()
+		prefix := kCollPrefix("doc", name)
+		for k, _ := c.Seek(prefix); k != nil && bytes.HasPrefix(k, prefix); k, _ = c.Next() {
+			desc.DocumentCount++
+		}
+		rc := tx.Bucket(cr.db.buckets.Rev).Cursor()
+		rprefix := kCollPrefix("rev", name)
+		for k, _ := rc.Seek(rprefix); k != nil && bytes.HasPrefix(k, rprefix); k, _ = rc.Next() {
+			desc.RevisionCount++
+		}
+		desc.HasSchema = tx.Bucket(cr.db.buckets.Schema).Get([]byte(name)) != nil
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return desc, nil
+}
+
+// Delete removes a collection with all its documents, indices, revisions and schema
+func (cr *CollectionRegistry) Delete(name string) (int, error) {
+	var deleted int
+	err := cr.db.update(func(tx *bolt.Tx) error {
+		n, err := cr.db.deleteCollectionTx(tx, name)
+		deleted = n
+		return err
+	})
+	if err != nil {
+		return 0, err
+	}
+	return deleted, nil
+}
+
+// Rename moves all documents of a collection under a new name
+func (cr *CollectionRegistry) Rename(from, to string) (int, error) {
+	return cr.copyCollection(from, to, true)
+}
+
+// Clone copies all documents of a collection under a new name
+func (cr *CollectionRegistry) Clone(from, to string) (int, error) {
+	return cr.copyCollection(from, to, false)
+}
+
+// copyCollection copies (and optionally removes) a collection in a single transaction.
+// Document IDs embed the collection name, so every doc, index entry and revision is rewritten.
+func (cr *CollectionRegistry) copyCollection(from, to string, move bool) (int, error) {
+	if from == "" || to == "" {
+		return 0, errors.New("missing collection name")
+	}
+	if from == to {
+		return 0, errors.New("source and target collection are the same")
+	}
+	src := cr.Get(from)
+	if src == nil {
+		return 0, ErrCollectionNotFound
+	}
+	dst := *src
+	dst.Name = to
+	if err := dst.normalize(); err != nil {
+		return 0, err
+	}
+	now := time.Now().Unix()
+	dst.CreatedAt, dst.UpdatedAt = now, now
+
+	var copied int
+	err := cr.db.update(func(tx *bolt.Tx) error {
+		bColl := tx.Bucket(cr.db.buckets.Collections)
+		bDocs := tx.Bucket(cr.db.buckets.Docs)
+		bIdx := tx.Bucket(cr.db.buckets.IdxMeta)
+		bRev := tx.Bucket(cr.db.buckets.Rev)
+		bByK := tx.Bucket(cr.db.buckets.ByKey)
+		bSchema := tx.Bucket(cr.db.buckets.Schema)
+
+		if bColl.Get([]byte(to)) != nil {
+			return ErrCollectionExists
+		}
+		dstPrefix := kCollPrefix("doc", to)
+		if k, _ := bDocs.Cursor().Seek(dstPrefix); k != nil && bytes.HasPrefix(k, dstPrefix) {
+			return ErrCollectionExists
+		}
+
+		// Collect first - writing into a bucket while a cursor walks it is unsafe
+		type entry struct{ k, v []byte }
+		var docs []entry
+		c := bDocs.Cursor()
+		prefix := kCollPrefix("doc", from)
+		for k, v := c.Seek(prefix); k != nil && bytes.HasPrefix(k, prefix); k, v = c.Next() {
+			docs = append(docs, entry{CopyBytes(k), CopyBytes(v)})
+		}
+
+		for _, e := range docs {
+			doc, isJSON, err := decodeStoredDoc(e.v)
+			if err != nil {
+				return fmt.Errorf("%s: %w", e.k, err)
+			}
+			oldID := doc.ID
+			doc.ID = genID(to, doc.Key, doc.Lang)
+			buf, err := encodeStoredDoc(doc, isJSON, dst.Compression)
+			if err != nil {
+				return err
+			}
+			if err := bDocs.Put(kDoc(to, doc.ID), buf); err != nil {
+				return err
+			}
+			if err := bByK.Put(kByKey(to, doc.Key, doc.Lang), []byte(doc.ID)); err != nil {
+				return err
+			}
+			for mk, vals := range doc.Meta {
+				for _, mv := range vals {
+					if err := bIdx.Put(append(kMetaKeyPrefix(to, mk, mv), doc.ID...), []byte("1")); err != nil {
+						return err
+					}
+				}
+			}
+
+			var revs []entry
+			rp := kRevPrefix(from, oldID)
+			rc := bRev.Cursor()
+			for rk, rv := rc.Seek(rp); rk != nil && bytes.HasPrefix(rk, rp); rk, rv = rc.Next() {
+				revs = append(revs, entry{CopyBytes(rk), CopyBytes(rv)})
+			}
+			for _, r := range revs {
+				rdoc, rIsJSON, err := decodeStoredDoc(r.v)
+				if err != nil {
+					return fmt.Errorf("%s: %w", r.k, err)
+				}
+				rdoc.ID = doc.ID
+				rbuf, err := encodeStoredDoc(rdoc, rIsJSON, dst.Compression)
+				if err != nil {
+					return err
+				}
+				if err := bRev.Put(append(kRevPrefix(to, doc.ID), r.k[len(rp):]...), rbuf); err != nil {
+					return err
+				}
+			}
+			copied++
+		}
+
+		if v := bSchema.Get([]byte(from)); v != nil {
+			var schema CollectionSchema
+			if err := json.Unmarshal(v, &schema); err != nil {
+				return err
+			}
+			schema.Collection = to
+			buf, err := json.Marshal(schema)
+			if err != nil {
+				return err
+			}
+			if err := bSchema.Put([]byte(to), buf); err != nil {
+				return err
+			}
+		}
+		if err := putCollectionInfo(bColl, &dst); err != nil {
+			return err
+		}
+		cr.setOnCommit(tx, &dst)
+		tx.OnCommit(func() { cr.db.Schemas.Forget(to) })
+		cr.db.logChange(change{Op: changeCloneCollection, Collection: from, To: to})
+		cr.db.logKey(cr.db.buckets.Collections, to)
+		cr.db.logKey(cr.db.buckets.Schema, to)
+
+		if move {
+			if _, err := cr.db.deleteCollectionTx(tx, from); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return 0, err
+	}
+
+	return copied, nil
+}
+
+// forget drops a collection from the in-memory registry
+func (cr *CollectionRegistry) forget(name string) {
+	cr.mu.Lock()
+	delete(cr.collections, name)
+	cr.mu.Unlock()
+}
+
+// DefaultLang returns lang, or the collection's default language when lang is empty
+func (cr *CollectionRegistry) DefaultLang(collection, lang string) string {
+	if lang != "" {
+		return lang
+	}
+	if ci := cr.Get(collection); ci != nil {
+		return ci.DefaultLang
+	}
+	return ""
+}
+
+// SaveRevision applies the collection revision policy to a write's own request
+func (cr *CollectionRegistry) SaveRevision(collection string, requested bool) bool {
+	if ci := cr.Get(collection); ci != nil {
+		switch ci.RevisionPolicy {
+		case RevisionPolicyAlways:
+			return true
+		case RevisionPolicyNever:
+			return false
+		}
+	}
+	return requested
+}
+
+// Compression returns the compression setting of a collection
+func (cr *CollectionRegistry) Compression(collection string) string {
+	if ci := cr.Get(collection); ci != nil {
+		return ci.Compression
+	}
+	return CompressionAuto
+}
+
+// deleteCollectionTx removes all documents, indices, revisions, the schema and the registry entry of a collection
+func (db *DB) deleteCollectionTx(tx *bolt.Tx, name string) (int, error) {
+	var deleted int
+	for _, bucket := range [][]byte{db.buckets.Docs, db.buckets.IdxMeta, db.buckets.Rev, db.buckets.ByKey} {
+		b := tx.Bucket(bucket)
+		var keys [][]byte
+		c := b.Cursor()
+		var prefix []byte
+		switch {
+		case bytes.Equal(bucket, db.buckets.Docs):
+			prefix = kCollPrefix("doc", name)
+		case bytes.Equal(bucket, db.buckets.IdxMeta):
+			prefix = kCollPrefix("meta", name)
+		case bytes.Equal(bucket, db.buckets.Rev):
+			prefix = kCollPrefix("rev", name)
+		default:
+			prefix = kCollPrefix("bykey", name)
+		}
+		for k, _ := c.Seek(prefix); k != nil && bytes.HasPrefix(k, prefix); k, _ = c.Next() {
+			keys = append(keys, CopyBytes(k))
+		}
+		for _, k := range keys {
+			if err := b.Delete(k); err != nil {
+				return 0, err
+			}
+		}
+		if bytes.Equal(bucket, db.buckets.Docs) {
+			deleted = len(keys)
+		}
+	}
+	if err := tx.Bucket(db.buckets.Schema).Delete([]byte(name)); err != nil {
+		return 0, err
+	}
+	if err := tx.Bucket(db.buckets.Collections).Delete([]byte(name)); err != nil {
+		return 0, err
+	}
+	db.logChange(change{Op: changeDropCollection, Collection: name})
+	tx.OnCommit(func() { db.invalidateCollection(name) })
+	return deleted, nil
+}
+
+// invalidateCollection drops in-memory state of a deleted collection
+func (db *DB) invalidateCollection(name string) {
+	db.Collections.forget(name)
+	db.Schemas.Forget(name)
+	db.cache.InvalidatePrefix(collectionCachePrefix(name))
+}
+
+// decodeStoredDoc decodes a stored document and reports whether it uses the JSON codec (older HTTP writes)
+// rather than compressed protobuf
+func decodeStoredDoc(v []byte) (*Doc, bool, error) {
+	d, err := unmarshalDoc(v)
+	return d, len(v) > 0 && v[0] == '{', err
+}
+
+// encodeStoredDoc encodes a document in the given codec
+func encodeStoredDoc(doc *Doc, isJSON bool, compression string) ([]byte, error) {
+	if isJSON {
+		return json.Marshal(doc)
+	}
+	return marshalDocWith(doc, compression)
+}
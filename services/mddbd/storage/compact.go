@@ -0,0 +1,197 @@
+package storage
+
+import (
+	"errors"
+	"log"
+	"os"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	bolt "go.etcd.io/bbolt"
+)
+
+// ErrCompactionRunning is returned when Compact is called while another compaction runs
+var ErrCompactionRunning = errors.New("compaction already running")
+
+// ErrCompactionAborted is returned when reads kept the database file open for longer than
+// CompactOptions.SwapTimeout; the compacted copy is discarded
+var ErrCompactionAborted = errors.New("compaction aborted: long-running reads kept the database file in use")
+
+const (
+	compactTxMaxSize     = 64 << 20        // bytes copied per transaction into the new file
+	compactCheckInterval = time.Minute     // how often CompactFreeRatio is checked
+	compactSwapTimeout   = 5 * time.Second // default CompactOptions.SwapTimeout
+	swapPollInterval     = 10 * time.Millisecond
+)
+
+// CompactOptions configures a compaction
+type CompactOptions struct {
+	MinFreeRatio float64       `json:"minFreeRatio"` // skip unless free pages are at least this fraction of the file
+	SwapTimeout  time.Duration `json:"-"`            // how long the swap waits for open reads (default 5s)
+}
+
+// CompactResult reports the file size before and after a compaction
+type CompactResult struct {
+	Compacted  bool  `json:"compacted"` // false when skipped by MinFreeRatio
+	SizeBefore int64 `json:"sizeBefore"`
+	SizeAfter  int64 `json:"sizeAfter"`
+	FreeBefore int64 `json:"freeBefore"` // bytes in free pages before compacting
+	Reclaimed  int64 `json:"reclaimed"`
+	DurationMs int64 `json:"durationMs"`
+}
+
+// CompactionStats counts the compactions since the database was opened
+type CompactionStats struct {
+	Runs           uint64
+	Failed         uint64
+	ReclaimedBytes uint64
+}
+
+// compactor serializes compactions and runs the scheduled ones
+type compactor struct {
+	running   sync.Mutex
+	stop      chan struct{}
+	wg        sync.WaitGroup
+	runs      atomic.Uint64
+	failed    atomic.Uint64
+	reclaimed atomic.Uint64
+}
+
+func (c *compactor) stats() CompactionStats {
+	return CompactionStats{Runs: c.runs.Load(), Failed: c.failed.Load(), ReclaimedBytes: c.reclaimed.Load()}
+}
+
+// shutdown stops the scheduler and waits for a scheduled compaction to finish
+func (c *compactor) shutdown() {
+	if c.stop != nil {
+		close(c.stop)
+		c.wg.Wait()
+	}
+}
+
+// Compact rewrites the database into a new file without free pages and swaps it in.
+// Writes wait until it is done; reads keep being served from the old file until the
+// swap, which waits for open read transactions to end. Reads that outlast SwapTimeout,
+// such as an export to a stalled client, abort it with ErrCompactionAborted.
+func (db *DB) Compact(opts CompactOptions) (*CompactResult, error) {
+	if !db.compactor.running.TryLock() {
+		return nil, ErrCompactionRunning
+	}
+	defer db.compactor.running.Unlock()
+
+	start := time.Now()
+	db.writeMu.Lock()
+	defer db.writeMu.Unlock()
+
+	res := &CompactResult{SizeBefore: fileSize(db.path), FreeBefore: int64(db.Bolt().Stats().FreeAlloc)}
+	if opts.MinFreeRatio > 0 && float64(res.FreeBefore) < opts.MinFreeRatio*float64(res.SizeBefore) {
+		res.SizeAfter = res.SizeBefore
+		return res, nil
+	}
+
+	if opts.SwapTimeout <= 0 {
+		opts.SwapTimeout = compactSwapTimeout
+	}
+	tmp := db.path + ".compact"
+	err := db.compactInto(tmp)
+	if err == nil {
+		err = db.swapFile(opts.SwapTimeout, func() error {
+			if err := os.Rename(tmp, db.path); err != nil {
+				return err
+			}
+			return syncDir(filepath.Dir(db.path))
+		})
+	}
+	if err != nil {
+		_ = os.Remove(tmp)
+		db.compactor.failed.Add(1)
+		return nil, err
+	}
+
+	res.Compacted = true
+	res.SizeAfter = fileSize(db.path)
+	res.Reclaimed = res.SizeBefore - res.SizeAfter
+	res.DurationMs = time.Since(start).Milliseconds()
+	db.compactor.runs.Add(1)
+	db.compactor.reclaimed.Add(uint64(max(res.Reclaimed, 0)))
+	return res, nil
+}
+
+// compactInto copies every bucket into a fresh file at path
+func (db *DB) compactInto(path string) error {
+	_ = os.Remove(path) // left over from an interrupted compaction
+	dst, err := bolt.Open(path, 0600, &bolt.Options{Timeout: db.opts.Timeout, NoSync: true})
+	if err != nil {
+		return err
+	}
+	db.swapMu.RLock()
+	err = bolt.Compact(dst, db.bolt, compactTxMaxSize)
+	db.swapMu.RUnlock()
+	if err == nil {
+		err = dst.Sync()
+	}
+	return errors.Join(err, dst.Close())
+}
+
+// startCompactor starts the scheduler when CompactInterval or CompactFreeRatio is set
+func (db *DB) startCompactor() {
+	if db.opts.CompactInterval <= 0 && db.opts.CompactFreeRatio <= 0 {
+		return
+	}
+	db.compactor.stop = make(chan struct{})
+	db.compactor.wg.Add(1)
+	go db.compactLoop()
+}
+
+// compactLoop compacts on every CompactInterval tick, and on every check that finds
+// the free page ratio at or above CompactFreeRatio
+func (db *DB) compactLoop() {
+	defer db.compactor.wg.Done()
+	var every, check <-chan time.Time
+	if db.opts.CompactInterval > 0 {
+		t := time.NewTicker(db.opts.CompactInterval)
+		defer t.Stop()
+		every = t.C
+	}
+	if db.opts.CompactFreeRatio > 0 {
+		t := time.NewTicker(compactCheckInterval)
+		defer t.Stop()
+		check = t.C
+	}
+	for {
+		var opts CompactOptions
+		select {
+		case <-db.compactor.stop:
+			return
+		case <-every:
+		case <-check:
+			opts.MinFreeRatio = db.opts.CompactFreeRatio
+		}
+		res, err := db.Compact(opts)
+		switch {
+		case err != nil:
+			log.Printf("Compaction failed: %v", err)
+		case res.Compacted:
+			log.Printf("Compacted %s: %d -> %d bytes in %dms", db.path, res.SizeBefore, res.SizeAfter, res.DurationMs)
+		}
+	}
+}
+
+func fileSize(path string) int64 {
+	if info, err := os.Stat(path); err == nil {
+		return info.Size()
+	}
+	return 0
+}
+
+// syncDir makes a rename in dir durable
+func syncDir(dir string) error {
+	d, err := os.Open(dir)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = d.Close() }()
+	return d.Sync()
+}
@@ -0,0 +1,93 @@
+package storage
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestCompactStalledExport(t *testing.T) {
+	db, err := Open(filepath.Join(t.TempDir(), "compact.db"), &Options{CacheBytes: -1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	for i := range 50 {
+		req := AddRequest{Collection: "blog", Key: fmt.Sprintf("post-%d", i), Lang: "en", ContentMD: strings.Repeat("x", 4096)}
+		if _, err := db.Add(req); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// An export to a client that stopped reading keeps its read transaction open
+	pr, pw := io.Pipe()
+	exported := make(chan error, 1)
+	go func() {
+		err := db.Export(pw, "blog", nil, "ndjson")
+		_ = pw.Close()
+		exported <- err
+	}()
+	if _, err := pr.Read(make([]byte, 1)); err != nil {
+		t.Fatal(err)
+	}
+
+	compacted := make(chan error, 1)
+	start := time.Now()
+	go func() {
+		_, err := db.Compact(CompactOptions{SwapTimeout: 300 * time.Millisecond})
+		compacted <- err
+	}()
+
+	// Reads go on while the swap waits
+	time.Sleep(50 * time.Millisecond)
+	got := make(chan error, 1)
+	go func() {
+		_, err := db.Get("blog", "post-1", "en")
+		got <- err
+	}()
+	select {
+	case err := <-got:
+		if err != nil {
+			t.Fatalf("Get during compaction: %v", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Get blocked behind the waiting compaction")
+	}
+
+	select {
+	case err := <-compacted:
+		if !errors.Is(err, ErrCompactionAborted) {
+			t.Fatalf("Compact: %v, want ErrCompactionAborted", err)
+		}
+		if d := time.Since(start); d > 2*time.Second {
+			t.Errorf("Compact gave up after %v", d)
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Compact did not give up on the stalled export")
+	}
+
+	// Writes resume after the abort, and compaction succeeds once the export is done
+	if _, err := db.Add(AddRequest{Collection: "blog", Key: "after", Lang: "en", ContentMD: "# after"}); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := io.Copy(io.Discard, pr); err != nil {
+		t.Fatal(err)
+	}
+	if err := <-exported; err != nil {
+		t.Fatal(err)
+	}
+	res, err := db.Compact(CompactOptions{SwapTimeout: 300 * time.Millisecond})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !res.Compacted {
+		t.Error("second compaction did not run")
+	}
+	if _, err := db.Get("blog", "after", "en"); err != nil {
+		t.Fatal(err)
+	}
+}
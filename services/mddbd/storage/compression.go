@@ -1,4 +1,4 @@
-package main
+package storage
 
 import (
 	"errors"
@@ -84,6 +84,27 @@ func compressDoc(data []byte) []byte {
 	return result
 }
 
+// compressDocWith compresses document data with a fixed method (auto = adaptive)
+func compressDocWith(data []byte, method string) []byte {
+	var flag byte
+	var payload []byte
+	switch method {
+	case "none":
+		flag, payload = flagUncompressed, data
+	case "snappy":
+		flag, payload = flagSnappy, snappy.Encode(nil, data)
+	case "zstd":
+		flag, payload = flagZstd, zstdEncoder.EncodeAll(data, nil)
+	default:
+		return compressDoc(data)
+	}
+	
+	result := make([]byte, len(payload)+1)
+	result[0] = flag
+	copy(result[1:], payload)
+	return result
+}
+
 // decompressDoc decompresses document data with adaptive decompression
 func decompressDoc(data []byte) ([]byte, error) {
 	if len(data) == 0 {
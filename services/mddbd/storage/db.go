@@ -0,0 +1,319 @@
+// Package storage is the mddb storage engine. It can be embedded in any Go program;
+// mddbd's HTTP and gRPC servers are thin adapters over it.
+//
+//	db, err := storage.Open("mddb.db", nil)
+//	if err != nil { ... }
+//	defer db.Close()
+//	doc, err := db.Add(storage.AddRequest{Collection: "blog", Key: "hello", Lang: "en", ContentMD: "# Hello"})
+package storage
+
+import (
+	"errors"
+	"sync"
+	"time"
+
+	bolt "go.etcd.io/bbolt"
+)
+
+// Options configures an opened database. The zero value (or nil) gives the mddbd defaults.
+type Options struct {
+	Timeout          time.Duration // how long Open waits for the file lock (default 2s)
+	Extreme          bool          // enable the extreme performance features (WAL, MVCC, final batch processor)
+	CacheBytes       int64         // memory budget of the document cache (default 64 MiB, 256 MiB in extreme mode; negative disables it)
+	CacheSize        int           // Deprecated: the cache is bounded by CacheBytes
+	CacheTTL         time.Duration // lifetime of a cached document (default 5m)
+	IndexWorkers     int           // Deprecated: queued index jobs are applied in order by a single worker
+	IndexQueueLimit  int           // queued index jobs before async writers block (default 10000)
+	BatchWorkers     int           // workers per batch processor (default 8)
+	Shards           int           // shards of the extreme-mode shard cluster (default 4)
+	ShardReplicas    int           // copies of each document across shards (default 2)
+	CompactInterval  time.Duration // compact the file this often (0 disables)
+	CompactFreeRatio float64       // compact when free pages reach this fraction of the file, checked every minute (0 disables)
+	ChangeLogDir     string        // archive committed changes here for RestoreAt ("" disables)
+}
+
+func (o *Options) withDefaults() Options {
+	var out Options
+	if o != nil {
+		out = *o
+	}
+	if out.Timeout <= 0 {
+		out.Timeout = 2 * time.Second
+	}
+	if out.CacheBytes == 0 {
+		out.CacheBytes = 64 << 20
+		if out.Extreme {
+			out.CacheBytes = 256 << 20
+		}
+	}
+	if out.CacheTTL <= 0 {
+		out.CacheTTL = 5 * time.Minute
+	}
+	if out.IndexQueueLimit <= 0 {
+		out.IndexQueueLimit = 10000
+	}
+	if out.BatchWorkers <= 0 {
+		out.BatchWorkers = 8
+	}
+	if out.Shards <= 0 {
+		out.Shards = 4
+	}
+	if out.ShardReplicas <= 0 {
+		out.ShardReplicas = min(2, out.Shards)
+	}
+	return out
+}
+
+// boltOptions returns the BoltDB options tuned for mddb's write pattern
+func (o *Options) boltOptions() *bolt.Options {
+	return &bolt.Options{
+		Timeout:         o.Timeout,
+		NoFreelistSync:  true,                 // Don't sync freelist to disk on every commit (faster writes)
+		FreelistType:    bolt.FreelistMapType, // Use hashmap for freelist (faster than array)
+		NoGrowSync:      false,                // Sync after growing mmap (safer)
+		InitialMmapSize: 100 * 1024 * 1024,    // 100MB initial mmap (reduce remapping)
+	}
+}
+
+// bucketNames caches bucket name byte slices to avoid repeated allocations
+type bucketNames struct {
+	Docs         []byte
+	IdxMeta      []byte
+	IdxQueue     []byte
+	Rev          []byte
+	ByKey        []byte
+	Schema       []byte
+	Collections  []byte
+	System       []byte
+	APIKeys      []byte
+	KeyMigration []byte
+}
+
+// DB is an open mddb database. It is safe for concurrent use.
+type DB struct {
+	bolt    *bolt.DB
+	path    string
+	opts    Options
+	buckets bucketNames
+
+	// Maintenance lock: writers share writeMu and every transaction shares swapMu.
+	// Compact and Restore hold writeMu to stop writes while reads go on, and swapMu
+	// only while they replace the file.
+	writeMu   sync.RWMutex
+	swapMu    sync.RWMutex
+	commitMu  sync.Mutex // held across each write transaction and its OnCommit hooks
+	compactor compactor
+	changeLog *changeLog // nil unless Options.ChangeLogDir is set
+
+	Collections *CollectionRegistry // Collection registry and settings
+	Schemas     *SchemaRegistry     // Per-collection schemas
+	APIKeys     *APIKeyStore        // Hashed API keys
+
+	extreme    bool
+	cache      *DocumentCache // Read-through cache
+	indexQueue *IndexQueue    // Async metadata indexing
+
+	wal           *WAL                  // Write-Ahead Log
+	mvcc          *MVCC                 // Multi-Version Concurrency Control
+	bloomFilters  *BloomFilterManager   // Bloom filters for negative lookups
+	deltaEncoder  *DeltaEncoder         // Delta encoding for revisions
+	adaptiveIndex *AdaptiveIndexManager // Adaptive indexing
+	asyncIO       *AsyncIO              // Async I/O
+	zeroCopy      *ZeroCopyManager      // Zero-copy I/O
+	simd          *SIMDProcessor        // Vectorized operations
+	shardCluster  *ShardCluster         // Distributed sharding
+
+	finalBatchProcessor *FinalBatchProcessor // Final optimized batch processor (extreme mode)
+	batchProcessor      *BatchProcessor      // Batch add
+	batchUpdater        *BatchUpdater        // Batch update
+	batchDeleter        *BatchDeleter        // Batch delete
+
+	closeOnce sync.Once
+}
+
+// Open opens (creating if needed) the database at path. Documents stored with the
+// pre-escaping key format are migrated before Open returns; an interrupted migration
+// resumes on the next Open.
+func Open(path string, opts *Options) (*DB, error) {
+	o := opts.withDefaults()
+	bdb, err := bolt.Open(path, 0600, o.boltOptions())
+	if err != nil {
+		return nil, err
+	}
+
+	db := &DB{
+		bolt: bdb,
+		path: path,
+		opts: o,
+		buckets: bucketNames{
+			Docs:         []byte("docs"),
+			IdxMeta:      []byte("idxmeta"),
+			IdxQueue:     []byte("idxqueue"),
+			Rev:          []byte("rev"),
+			ByKey:        []byte("bykey"),
+			Schema:       []byte("schema"),
+			Collections:  []byte("collections"),
+			System:       []byte("system"),
+			APIKeys:      []byte("apikeys"),
+			KeyMigration: []byte("keymigration"),
+		},
+		extreme:       o.Extreme,
+		cache:         NewDocumentCache(o.CacheBytes, o.CacheTTL),
+		bloomFilters:  NewBloomFilterManager(),
+		deltaEncoder:  NewDeltaEncoder(),
+		adaptiveIndex: NewAdaptiveIndexManager(),
+		asyncIO:       NewAsyncIO(),
+		zeroCopy:      NewZeroCopyManager(),
+		simd:          NewSIMDProcessor(),
+		shardCluster:  NewShardCluster(o.Shards, o.ShardReplicas),
+	}
+	db.indexQueue = NewIndexQueue(db, o.IndexQueueLimit)
+	db.Schemas = NewSchemaRegistry(db)
+	db.Collections = NewCollectionRegistry(db)
+	db.APIKeys = NewAPIKeyStore(db)
+
+	if o.Extreme {
+		wal, err := NewWAL(path, SyncPeriodic)
+		if err != nil {
+			_ = bdb.Close()
+			return nil, err
+		}
+		db.wal = wal
+		db.mvcc = NewMVCC()
+		db.finalBatchProcessor = NewFinalBatchProcessor(db, o.BatchWorkers)
+	}
+	db.batchProcessor = NewBatchProcessor(db, o.BatchWorkers)
+	db.batchUpdater = NewBatchUpdater(db, o.BatchWorkers)
+	db.batchDeleter = NewBatchDeleter(db, o.BatchWorkers)
+
+	if err := db.init(); err != nil {
+		_ = db.Close()
+		return nil, err
+	}
+	if o.ChangeLogDir != "" {
+		if err := db.openChangeLog(o.ChangeLogDir); err != nil {
+			_ = db.Close()
+			return nil, err
+		}
+	}
+	db.startCompactor()
+	return db, nil
+}
+
+// init prepares a freshly opened file: buckets, registries and key migration
+func (db *DB) init() error {
+	if err := db.ensureBuckets(); err != nil {
+		return err
+	}
+	if err := db.Collections.Load(); err != nil {
+		return err
+	}
+	if err := db.indexQueue.load(); err != nil {
+		return err
+	}
+	return db.migrateKeys()
+}
+
+func (db *DB) ensureBuckets() error {
+	return db.update(func(tx *bolt.Tx) error {
+		_, _ = tx.CreateBucketIfNotExists(db.buckets.Docs)        // doc|collection|id -> doc
+		_, _ = tx.CreateBucketIfNotExists(db.buckets.IdxMeta)     // meta|collection|key|value|docID -> 1
+		_, _ = tx.CreateBucketIfNotExists(db.buckets.IdxQueue)    // seq -> pending index job json
+		_, _ = tx.CreateBucketIfNotExists(db.buckets.Rev)         // rev|collection|docID|ts -> doc
+		_, _ = tx.CreateBucketIfNotExists(db.buckets.ByKey)       // bykey|collection|key|lang -> docID
+		_, _ = tx.CreateBucketIfNotExists(db.buckets.Schema)      // collection -> schema json
+		_, _ = tx.CreateBucketIfNotExists(db.buckets.Collections) // collection -> settings json
+		_, _ = tx.CreateBucketIfNotExists(db.buckets.System)      // keyFormat -> version
+		_, _ = tx.CreateBucketIfNotExists(db.buckets.APIKeys)     // key id -> api key json (hashed secret)
+		return nil
+	})
+}
+
+// Close stops the background workers and closes the database file
+func (db *DB) Close() error {
+	var err error
+	db.closeOnce.Do(func() {
+		db.compactor.shutdown()
+		// Apply what is queued so the next start has nothing to replay
+		err = db.indexQueue.Flush()
+		db.indexQueue.Shutdown()
+		if db.wal != nil {
+			err = errors.Join(err, db.wal.Close())
+		}
+		if db.mvcc != nil {
+			db.mvcc.Close()
+		}
+		db.adaptiveIndex.Close()
+		if db.changeLog != nil {
+			err = errors.Join(err, db.changeLog.close())
+		}
+		err = errors.Join(err, db.bolt.Close())
+	})
+	return err
+}
+
+// Path returns the database file path
+func (db *DB) Path() string { return db.path }
+
+// Extreme reports whether the extreme performance features are enabled
+func (db *DB) Extreme() bool { return db.extreme }
+
+// Bolt returns the underlying BoltDB handle for tools that need raw access.
+// Writes through it bypass caches, indices and validation. Compact and Restore
+// replace the handle, so don't keep it across them.
+func (db *DB) Bolt() *bolt.DB {
+	db.swapMu.RLock()
+	defer db.swapMu.RUnlock()
+	return db.bolt
+}
+
+// view runs fn in a read transaction
+func (db *DB) view(fn func(*bolt.Tx) error) error {
+	db.swapMu.RLock()
+	defer db.swapMu.RUnlock()
+	return db.bolt.View(fn)
+}
+
+// update runs fn in a write transaction; it waits while a compaction or restore runs.
+// With the change log on, the changes fn made are logged once the transaction commits.
+// Bolt runs OnCommit hooks after it lets the next writer in; commitMu keeps them in
+// commit order, so in-memory state they update can't be overwritten by an older write.
+func (db *DB) update(fn func(*bolt.Tx) error) error {
+	db.writeMu.RLock()
+	defer db.writeMu.RUnlock()
+	db.swapMu.RLock()
+	defer db.swapMu.RUnlock()
+	db.commitMu.Lock()
+	defer db.commitMu.Unlock()
+	if db.changeLog == nil {
+		return db.bolt.Update(fn)
+	}
+
+	var logged func(committed bool)
+	err := db.bolt.Update(func(tx *bolt.Tx) error {
+		db.changeLog.pending = db.changeLog.pending[:0]
+		if err := fn(tx); err != nil {
+			return err
+		}
+		var err error
+		logged, err = db.captureChanges(tx)
+		return err
+	})
+	if logged != nil {
+		logged(err == nil)
+	}
+	return err
+}
+
+// ResizeCache changes the document cache budget and TTL of an open database. The
+// budget is in bytes and <= 0 disables the cache; ttl <= 0 keeps the current TTL.
+func (db *DB) ResizeCache(budget int64, ttl time.Duration) {
+	db.cache.Resize(budget, ttl)
+}
+
+// Ping checks that the database file is open and readable
+func (db *DB) Ping() error {
+	return db.view(func(tx *bolt.Tx) error {
+		return nil
+	})
+}
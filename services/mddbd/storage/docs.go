@@ -0,0 +1,469 @@
+package storage
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	bolt "go.etcd.io/bbolt"
+)
+
+var (
+	ErrNotFound      = errors.New("document not found")
+	ErrMissingFields = errors.New("missing fields")
+	ErrInvalidQuery  = errors.New("invalid query")
+)
+
+// Doc is a stored document
+type Doc struct {
+	ID        string              `json:"id"`        // generated
+	Key       string              `json:"key"`       // e.g. "homepage"
+	Lang      string              `json:"lang"`      // e.g. "en_GB"
+	Meta      map[string][]string `json:"meta"`      // meta values (multi)
+	ContentMD string              `json:"contentMd"` // raw markdown
+	AddedAt   int64               `json:"addedAt"`
+	UpdatedAt int64               `json:"updatedAt"`
+}
+
+// ETag is a strong validator over everything a client can read back from a document
+func (d *Doc) ETag() string {
+	h := fnv.New64a()
+	fmt.Fprintf(h, "%s\x00%d\x00%s\x00", d.ID, d.UpdatedAt, d.ContentMD)
+	keys := make([]string, 0, len(d.Meta))
+	for k := range d.Meta {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	for _, k := range keys {
+		fmt.Fprintf(h, "%s\x00%s\x00", k, strings.Join(d.Meta[k], "\x00"))
+	}
+	return fmt.Sprintf(`"%016x"`, h.Sum64())
+}
+
+// AddRequest creates or replaces a document
+type AddRequest struct {
+	Collection   string              `json:"collection"`
+	Key          string              `json:"key"`
+	Lang         string              `json:"lang"` // empty = the collection's default language
+	Meta         map[string][]string `json:"meta"`
+	ContentMD    string              `json:"contentMd"`
+	SaveRevision bool                `json:"saveRevision"` // subject to the collection's revision policy
+	AsyncIndex   bool                `json:"-"`            // queue the meta index update instead of applying it in the write transaction
+}
+
+// Search consistency levels
+const (
+	ConsistencyEventual = "eventual" // may miss writes whose index update is still queued
+	ConsistencyStrong   = "strong"   // waits until every write committed before the search is indexed
+)
+
+// SearchQuery selects documents of a collection
+type SearchQuery struct {
+	Collection  string              `json:"collection"`
+	FilterMeta  map[string][]string `json:"filterMeta"` // AND over keys, OR over values
+	Sort        string              `json:"sort"`       // addedAt|updatedAt|key (empty = storage order)
+	Asc         bool                `json:"asc"`
+	Limit       int                 `json:"limit"` // default 50
+	Offset      int                 `json:"offset"`
+	Consistency string              `json:"consistency"` // eventual (default) | strong
+}
+
+// Revision is a saved version of a document
+type Revision struct {
+	SavedAt int64 `json:"savedAt"`
+	Doc     Doc   `json:"doc"`
+}
+
+// DocID returns the deterministic ID of a document
+func DocID(collection, key, lang string) string {
+	return genID(collection, key, lang)
+}
+
+// Add creates or replaces a document
+func (db *DB) Add(req AddRequest) (*Doc, error) {
+	doc, _, err := db.Put(req, nil)
+	return doc, err
+}
+
+// Put creates or replaces a document and reports whether it was created. check, if set,
+// sees the current document (nil when there is none) inside the write transaction and
+// can veto the write by returning an error.
+func (db *DB) Put(req AddRequest, check func(existing *Doc) error) (*Doc, bool, error) {
+	req.Lang = db.Collections.DefaultLang(req.Collection, req.Lang)
+	if req.Collection == "" || req.Key == "" || req.Lang == "" {
+		return nil, false, ErrMissingFields
+	}
+	if err := db.Schemas.Validate(req.Collection, req.Key, req.Lang, req.Meta, req.ContentMD); err != nil {
+		return nil, false, err
+	}
+
+	if req.AsyncIndex {
+		db.indexQueue.admit()
+	}
+	now := time.Now().Unix()
+	var saved Doc
+	var created bool
+	err := db.update(func(tx *bolt.Tx) error {
+		var err error
+		saved, created, err = db.putTx(tx, req, check, now)
+		return err
+	})
+	if err != nil {
+		return nil, false, err
+	}
+	return &saved, created, nil
+}
+
+// putTx writes req inside tx; req must have its language resolved and be validated. With
+// AsyncIndex the index update is queued in tx instead of applied.
+func (db *DB) putTx(tx *bolt.Tx, req AddRequest, check func(existing *Doc) error, now int64) (Doc, bool, error) {
+	docID := genID(req.Collection, req.Key, req.Lang) // deterministic ID (collection|key|lang)
+	compression := db.Collections.Compression(req.Collection)
+
+	// Use KeyBuilder for efficient key construction
+	var kb KeyBuilder
+
+	bDocs := tx.Bucket(db.buckets.Docs)
+	bIdx := tx.Bucket(db.buckets.IdxMeta)
+	bByK := tx.Bucket(db.buckets.ByKey)
+
+	if err := db.Collections.Ensure(tx, req.Collection); err != nil {
+		return Doc{}, false, err
+	}
+	if err := db.Schemas.checkLangs(tx, req.Collection, req.Key, req.Lang); err != nil {
+		return Doc{}, false, err
+	}
+
+	// load existing
+	existing := Doc{}
+	if v := bDocs.Get(kb.BuildDocKey(req.Collection, docID)); v != nil {
+		d, err := unmarshalDoc(v)
+		if err != nil {
+			return Doc{}, false, err
+		}
+		existing = *d
+	}
+	if check != nil {
+		var cur *Doc
+		if existing.ID != "" {
+			cur = &existing
+		}
+		if err := check(cur); err != nil {
+			return Doc{}, false, err
+		}
+	}
+	created := existing.ID == ""
+	added := existing.AddedAt
+	if added == 0 {
+		added = now
+	}
+
+	doc := Doc{
+		ID: docID, Key: req.Key, Lang: req.Lang, Meta: req.Meta,
+		ContentMD: req.ContentMD, AddedAt: added, UpdatedAt: now,
+	}
+	buf, err := marshalDocWith(&doc, compression)
+	if err != nil {
+		return Doc{}, false, err
+	}
+	if err := bDocs.Put(kb.BuildDocKey(req.Collection, docID), buf); err != nil {
+		return Doc{}, false, err
+	}
+	if err := bByK.Put(kb.BuildByKey(req.Collection, req.Key, req.Lang), []byte(docID)); err != nil {
+		return Doc{}, false, err
+	}
+	db.invalidateTx(tx, req.Collection, req.Key, req.Lang)
+
+	// Only reindex metadata if it has changed
+	if metadataChanged(existing.Meta, doc.Meta) {
+		job := &IndexJob{Collection: req.Collection, DocID: docID, OldMeta: existing.Meta, NewMeta: doc.Meta}
+		var err error
+		if req.AsyncIndex {
+			err = db.indexQueue.enqueueTx(tx, job)
+		} else {
+			err = job.apply(bIdx)
+		}
+		if err != nil {
+			return Doc{}, false, err
+		}
+	}
+
+	if db.Collections.SaveRevision(req.Collection, req.SaveRevision) {
+		if err := db.putRevision(tx, req.Collection, doc.ID, now, buf); err != nil {
+			return Doc{}, false, err
+		}
+	}
+
+	return doc, created, nil
+}
+
+// Get loads a document by collection, key and lang (empty lang = the collection's default)
+func (db *DB) Get(collection, key, lang string) (*Doc, error) {
+	lang = db.Collections.DefaultLang(collection, lang)
+	if collection == "" || key == "" || lang == "" {
+		return nil, ErrMissingFields
+	}
+
+	cacheKey := BuildCacheKey(collection, key, lang)
+	if cached, found := db.cache.Get(cacheKey); found {
+		if d, err := unmarshalDoc(cached); err == nil {
+			return d, nil
+		}
+	}
+	version := db.cache.Version(cacheKey) // taken before the read so a concurrent write wins
+
+	var doc *Doc
+	var docData []byte
+	err := db.view(func(tx *bolt.Tx) error {
+		docID := tx.Bucket(db.buckets.ByKey).Get(kByKey(collection, key, lang))
+		if docID == nil {
+			return ErrNotFound
+		}
+		v := tx.Bucket(db.buckets.Docs).Get(kDoc(collection, string(docID)))
+		if v == nil {
+			return ErrNotFound
+		}
+		docData = CopyBytes(v)
+		d, err := unmarshalDoc(v)
+		if err != nil {
+			return err
+		}
+		doc = d
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	db.cache.Fill(cacheKey, docData, version)
+	return doc, nil
+}
+
+// Search runs a query: meta filtering, sorting and pagination. total counts all matches.
+func (db *DB) Search(q SearchQuery) (docs []Doc, total int, err error) {
+	if q.Limit <= 0 {
+		q.Limit = 50
+	}
+	if q.Offset < 0 {
+		q.Offset = 0
+	}
+	switch q.Consistency {
+	case "", ConsistencyEventual:
+	case ConsistencyStrong:
+		// Only meta filters read the index
+		if len(q.FilterMeta) > 0 {
+			enqueued, _ := db.indexQueue.Watermarks()
+			if err := db.indexQueue.WaitFor(enqueued); err != nil {
+				return nil, 0, err
+			}
+		}
+	default:
+		return nil, 0, fmt.Errorf("%w: consistency must be %s or %s, got %q", ErrInvalidQuery, ConsistencyEventual, ConsistencyStrong, q.Consistency)
+	}
+
+	err = db.view(func(tx *bolt.Tx) error {
+		bDocs := tx.Bucket(db.buckets.Docs)
+		bIdx := tx.Bucket(db.buckets.IdxMeta)
+
+		// No meta filter: scan the whole collection
+		if len(q.FilterMeta) == 0 {
+			c := bDocs.Cursor()
+			prefix := kCollPrefix("doc", q.Collection)
+			for k, v := c.Seek(prefix); k != nil && bytes.HasPrefix(k, prefix); k, v = c.Next() {
+				d, err := unmarshalDoc(v)
+				if err != nil {
+					return err
+				}
+				docs = append(docs, *d)
+			}
+			return nil
+		}
+
+		// Intersect over meta keys, union over values
+		var sets [][]string
+		for mk, mvals := range q.FilterMeta {
+			var ids []string
+			for _, mv := range mvals {
+				prefix := kMetaKeyPrefix(q.Collection, mk, mv)
+				c := bIdx.Cursor()
+				for k, _ := c.Seek(prefix); k != nil && bytes.HasPrefix(k, prefix); k, _ = c.Next() {
+					// docID is everything after the meta value prefix
+					ids = append(ids, string(k[len(prefix):]))
+				}
+			}
+			sets = append(sets, unique(ids))
+		}
+		for _, id := range intersect(sets...) {
+			v := bDocs.Get(kDoc(q.Collection, id))
+			if v == nil {
+				continue
+			}
+			d, err := unmarshalDoc(v)
+			if err != nil {
+				return err
+			}
+			docs = append(docs, *d)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, 0, err
+	}
+
+	if q.Sort != "" {
+		sortDocs(docs, q.Sort, q.Asc)
+	}
+
+	// paginate
+	total = len(docs)
+	start := min(q.Offset, total)
+	end := min(start+q.Limit, total)
+	return docs[start:end:end], total, nil
+}
+
+// Delete removes a document with its revisions and index entries
+func (db *DB) Delete(collection, key, lang string) error {
+	lang = db.Collections.DefaultLang(collection, lang)
+	if collection == "" || key == "" || lang == "" {
+		return ErrMissingFields
+	}
+
+	return db.update(func(tx *bolt.Tx) error {
+		return db.deleteTx(tx, collection, key, lang, nil)
+	})
+}
+
+// deleteTx removes a document with its revisions and index entries inside tx
+func (db *DB) deleteTx(tx *bolt.Tx, collection, key, lang string, check func(existing *Doc) error) error {
+	docID := genID(collection, key, lang)
+	bDocs := tx.Bucket(db.buckets.Docs)
+	bIdx := tx.Bucket(db.buckets.IdxMeta)
+	bRev := tx.Bucket(db.buckets.Rev)
+	bByK := tx.Bucket(db.buckets.ByKey)
+
+	// Check if document exists and load it for index cleanup. A precondition that holds
+	// for a missing document (exists: false) makes the delete a no-op.
+	v := bDocs.Get(kDoc(collection, docID))
+	if v == nil {
+		if check != nil {
+			return check(nil)
+		}
+		return ErrNotFound
+	}
+	doc, err := unmarshalDoc(v)
+	if err != nil {
+		return err
+	}
+	if check != nil {
+		if err := check(doc); err != nil {
+			return err
+		}
+	}
+
+	if err := bDocs.Delete(kDoc(collection, docID)); err != nil {
+		return err
+	}
+	if err := bByK.Delete(kByKey(collection, key, lang)); err != nil {
+		return err
+	}
+	db.invalidateTx(tx, collection, key, lang)
+
+	// Delete all revisions
+	c := bRev.Cursor()
+	rp := kRevPrefix(collection, docID)
+	for k, _ := c.Seek(rp); k != nil && bytes.HasPrefix(k, rp); k, _ = c.Next() {
+		if err := bRev.Delete(k); err != nil {
+			return err
+		}
+	}
+
+	// Delete metadata indices
+	return (&IndexJob{Collection: collection, DocID: docID, OldMeta: doc.Meta}).apply(bIdx)
+}
+
+// Revisions returns the saved revisions of a document, oldest first
+func (db *DB) Revisions(collection, key, lang string) ([]Revision, error) {
+	lang = db.Collections.DefaultLang(collection, lang)
+	if collection == "" || key == "" || lang == "" {
+		return nil, ErrMissingFields
+	}
+	docID := genID(collection, key, lang)
+
+	var revs []Revision
+	err := db.view(func(tx *bolt.Tx) error {
+		if tx.Bucket(db.buckets.Docs).Get(kDoc(collection, docID)) == nil {
+			return ErrNotFound
+		}
+		c := tx.Bucket(db.buckets.Rev).Cursor()
+		rp := kRevPrefix(collection, docID)
+		for k, v := c.Seek(rp); k != nil && bytes.HasPrefix(k, rp); k, v = c.Next() {
+			d, err := unmarshalDoc(v)
+			if err != nil {
+				return err
+			}
+			revs = append(revs, Revision{SavedAt: revSavedAt(k[len(rp):]), Doc: *d})
+		}
+		return nil
+	})
+	return revs, err
+}
+
+// putRevision saves buf as a revision of document id taken at ts. Revisions saved in the
+// same second (e.g. two operations of one transaction) get a sequence suffix after the
+// timestamp instead of overwriting each other; they still sort in the order they were saved.
+func (db *DB) putRevision(tx *bolt.Tx, coll, id string, ts int64, buf []byte) error {
+	bRev := tx.Bucket(db.buckets.Rev)
+	return bRev.Put(nextRevisionKey(bRev, coll, id, ts), buf)
+}
+
+// nextRevisionKey returns the first unused revision key of document id for ts
+func nextRevisionKey(bRev *bolt.Bucket, coll, id string, ts int64) []byte {
+	base := fmt.Appendf(kRevPrefix(coll, id), "%020d", ts)
+	key := base
+	for n := 1; bRev.Get(key) != nil; n++ {
+		key = fmt.Appendf(base[:len(base):len(base)], "-%06d", n)
+	}
+	return key
+}
+
+// revisionAt returns the newest revision of document id saved at ts, or nil
+func (db *DB) revisionAt(tx *bolt.Tx, coll, id string, ts int64) []byte {
+	base := fmt.Appendf(kRevPrefix(coll, id), "%020d", ts)
+	var last []byte
+	c := tx.Bucket(db.buckets.Rev).Cursor()
+	for k, v := c.Seek(base); k != nil && bytes.HasPrefix(k, base); k, v = c.Next() {
+		last = v
+	}
+	return last
+}
+
+// revSavedAt parses the timestamp of a revision key after its prefix, ignoring any
+// sequence suffix added by putRevision
+func revSavedAt(suffix []byte) int64 {
+	ts, _ := strconv.ParseInt(string(suffix[:min(len(suffix), 20)]), 10, 64)
+	return ts
+}
+
+// --- cache
+
+// invalidateTx evicts a document from the cache once tx commits and adds it to the
+// change log. Every write path goes through it, so HTTP, gRPC and embedded writers
+// invalidate the same way.
+func (db *DB) invalidateTx(tx *bolt.Tx, collection, key, lang string) {
+	cacheKey := BuildCacheKey(collection, key, lang)
+	tx.OnCommit(func() { db.cache.Invalidate(cacheKey) })
+	db.logChange(change{Op: changeDoc, Collection: collection, Key: key, Lang: lang})
+}
+
+// DropCache evicts every cached document of collection, or of all collections when it is empty
+func (db *DB) DropCache(collection string) {
+	if collection == "" {
+		db.cache.Clear()
+		return
+	}
+	db.cache.InvalidatePrefix(collectionCachePrefix(collection))
+}
@@ -0,0 +1,629 @@
+package storage
+
+import (
+	"archive/tar"
+	"bufio"
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"strings"
+	"time"
+
+	json "github.com/goccy/go-json"
+	"github.com/klauspost/compress/zstd"
+	bolt "go.etcd.io/bbolt"
+)
+
+// A dump is a logical, storage-independent archive of collections: a tar stream,
+// optionally zstd-compressed, holding
+//
+//	mddb-dump.json                          format and version, always first
+//	collections/0001/collection.json        settings and schema
+//	collections/0001/documents-0001.ndjson  documents as JSON, split into parts
+//	collections/0001/revisions-0001.ndjson  revisions as JSON
+//	manifest.json                           counts and the SHA-256 of every other entry, always last
+//
+// Documents are written in their JSON form rather than the storage codec, so a dump
+// loads into any mddb version that reads its format version.
+const (
+	DumpFormat  = "mddb-dump"
+	DumpVersion = 1 // bumped on incompatible changes; Load reads every version up to this one
+)
+
+const (
+	dumpHeaderName   = "mddb-dump.json"
+	dumpManifestName = "manifest.json"
+	dumpPartSize     = 4 << 20 // bytes of ndjson per documents or revisions entry
+)
+
+// ErrInvalidDump is returned when a dump fails verification; nothing is loaded
+var ErrInvalidDump = errors.New("invalid dump")
+
+// DumpOptions configures a dump
+type DumpOptions struct {
+	Collections   []string `json:"collections"`   // empty dumps every collection
+	Compression   string   `json:"compression"`   // none (default) or zstd
+	SkipRevisions bool     `json:"skipRevisions"` // leave out revision history
+}
+
+// DumpManifest describes a dump and lets Load verify it
+type DumpManifest struct {
+	Format      string           `json:"format"`
+	Version     int              `json:"version"`
+	CreatedAt   int64            `json:"createdAt"`
+	TxID        int              `json:"txId"` // transaction the dump was read from
+	Collections []DumpCollection `json:"collections"`
+	Files       []DumpFile       `json:"files"` // every entry but the manifest, in archive order
+}
+
+// DumpCollection is a collection in a dump
+type DumpCollection struct {
+	Name      string `json:"name"`
+	Dir       string `json:"dir"` // its entries' directory inside the archive
+	Documents int    `json:"documents"`
+	Revisions int    `json:"revisions"`
+}
+
+// DumpFile is an archive entry with its checksum
+type DumpFile struct {
+	Name   string `json:"name"`
+	Size   int64  `json:"size"`
+	SHA256 string `json:"sha256"`
+}
+
+// dumpHeader is the first entry of a dump, so readers can reject one they don't understand
+type dumpHeader struct {
+	Format    string `json:"format"`
+	Version   int    `json:"version"`
+	CreatedAt int64  `json:"createdAt"`
+}
+
+// dumpedCollection is the collection.json entry of a collection
+type dumpedCollection struct {
+	Collection CollectionInfo    `json:"collection"`
+	Schema     *CollectionSchema `json:"schema,omitempty"`
+}
+
+// Dump writes the collections to w as a logical archive, from a single read transaction
+func (db *DB) Dump(w io.Writer, opts DumpOptions) (*DumpManifest, error) {
+	compression, err := BackupCompression(opts.Compression)
+	if err != nil {
+		return nil, err
+	}
+	names := opts.Collections
+	if len(names) == 0 {
+		for _, ci := range db.Collections.List() {
+			names = append(names, ci.Name)
+		}
+	}
+
+	var enc *zstd.Encoder
+	if compression == BackupCompressionZstd {
+		if enc, err = zstd.NewWriter(w, zstd.WithEncoderLevel(zstd.SpeedDefault)); err != nil {
+			return nil, err
+		}
+		w = enc
+	}
+	m := &DumpManifest{Format: DumpFormat, Version: DumpVersion, CreatedAt: time.Now().Unix()}
+	dw := &dumpWriter{tw: tar.NewWriter(w), m: m, modTime: time.Unix(m.CreatedAt, 0)}
+
+	err = db.view(func(tx *bolt.Tx) error {
+		m.TxID = tx.ID()
+		if err := dw.json(dumpHeaderName, dumpHeader{m.Format, m.Version, m.CreatedAt}); err != nil {
+			return err
+		}
+		for i, name := range names {
+			dc := DumpCollection{Name: name, Dir: fmt.Sprintf("collections/%04d", i+1)}
+			if err := db.dumpCollection(tx, dw, &dc, opts.SkipRevisions); err != nil {
+				return err
+			}
+			m.Collections = append(m.Collections, dc)
+		}
+		files := m.Files
+		if err := dw.json(dumpManifestName, m); err != nil {
+			return err
+		}
+		m.Files = files
+		return dw.tw.Close()
+	})
+	if enc != nil {
+		err = errors.Join(err, enc.Close())
+	}
+	if err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// dumpCollection writes the entries of one collection
+func (db *DB) dumpCollection(tx *bolt.Tx, dw *dumpWriter, dc *DumpCollection, skipRevisions bool) error {
+	v := tx.Bucket(db.buckets.Collections).Get([]byte(dc.Name))
+	if v == nil {
+		return fmt.Errorf("%w: %s", ErrCollectionNotFound, dc.Name)
+	}
+	var entry dumpedCollection
+	if err := json.Unmarshal(v, &entry.Collection); err != nil {
+		return fmt.Errorf("collection %s: %w", dc.Name, err)
+	}
+	if v := tx.Bucket(db.buckets.Schema).Get([]byte(dc.Name)); v != nil {
+		entry.Schema = &CollectionSchema{}
+		if err := json.Unmarshal(v, entry.Schema); err != nil {
+			return fmt.Errorf("schema %s: %w", dc.Name, err)
+		}
+	}
+	if err := dw.json(dc.Dir+"/collection.json", entry); err != nil {
+		return err
+	}
+
+	docs := dw.parts(dc.Dir + "/documents")
+	revs := dw.parts(dc.Dir + "/revisions")
+	bRev := tx.Bucket(db.buckets.Rev)
+	err := db.forEachDoc(tx, dc.Name, nil, func(d *Doc) error {
+		dc.Documents++
+		if err := docs.add(d); err != nil {
+			return err
+		}
+		if skipRevisions {
+			return nil
+		}
+		c := bRev.Cursor()
+		rp := kRevPrefix(dc.Name, d.ID)
+		for k, v := c.Seek(rp); k != nil && bytes.HasPrefix(k, rp); k, v = c.Next() {
+			rd, err := unmarshalDoc(v)
+			if err != nil {
+				return fmt.Errorf("%s: %w", k, err)
+			}
+			dc.Revisions++
+			if err := revs.add(Revision{SavedAt: revSavedAt(k[len(rp):]), Doc: *rd}); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+	return errors.Join(docs.flush(), revs.flush())
+}
+
+// dumpWriter writes checksummed tar entries and records them in the manifest
+type dumpWriter struct {
+	tw      *tar.Writer
+	m       *DumpManifest
+	modTime time.Time
+}
+
+func (dw *dumpWriter) file(name string, data []byte) error {
+	hdr := &tar.Header{Name: name, Mode: 0644, Size: int64(len(data)), ModTime: dw.modTime}
+	if err := dw.tw.WriteHeader(hdr); err != nil {
+		return err
+	}
+	if _, err := dw.tw.Write(data); err != nil {
+		return err
+	}
+	sum := sha256.Sum256(data)
+	dw.m.Files = append(dw.m.Files, DumpFile{Name: name, Size: hdr.Size, SHA256: hex.EncodeToString(sum[:])})
+	return nil
+}
+
+func (dw *dumpWriter) json(name string, v any) error {
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return err
+	}
+	return dw.file(name, data)
+}
+
+// parts splits ndjson lines into entries named prefix-0001.ndjson, prefix-0002.ndjson...
+func (dw *dumpWriter) parts(prefix string) *dumpParts {
+	dp := &dumpParts{dw: dw, prefix: prefix}
+	dp.enc = json.NewEncoder(&dp.buf)
+	return dp
+}
+
+type dumpParts struct {
+	dw     *dumpWriter
+	prefix string
+	n      int
+	buf    bytes.Buffer
+	enc    *json.Encoder
+}
+
+func (dp *dumpParts) add(v any) error {
+	if err := dp.enc.Encode(v); err != nil {
+		return err
+	}
+	if dp.buf.Len() >= dumpPartSize {
+		return dp.flush()
+	}
+	return nil
+}
+
+func (dp *dumpParts) flush() error {
+	if dp.buf.Len() == 0 {
+		return nil
+	}
+	dp.n++
+	err := dp.dw.file(fmt.Sprintf("%s-%04d.ndjson", dp.prefix, dp.n), dp.buf.Bytes())
+	dp.buf.Reset()
+	return err
+}
+
+// LoadOptions configures a load
+type LoadOptions struct {
+	Collections []string          `json:"collections"` // load only these collections of the dump (default all)
+	Rename      map[string]string `json:"rename"`      // dump name -> target collection
+	Replace     bool              `json:"replace"`     // replace target collections that exist instead of failing
+}
+
+// LoadResult describes a completed load
+type LoadResult struct {
+	Format      string             `json:"format"`
+	Version     int                `json:"version"`
+	Collections []LoadedCollection `json:"collections"`
+	DurationMs  int64              `json:"durationMs"`
+}
+
+// LoadedCollection is a collection written by a load
+type LoadedCollection struct {
+	From      string `json:"from"` // name in the dump
+	Name      string `json:"name"`
+	Documents int    `json:"documents"`
+	Revisions int    `json:"revisions"`
+	Replaced  bool   `json:"replaced"` // an existing collection was replaced
+}
+
+// Load writes the collections of a dump read from r, plain or zstd-compressed. The dump
+// is spooled to a temporary file and verified against its manifest first; then every
+// selected collection is written in one transaction, so a load applies completely or
+// not at all. Documents and revisions keep their timestamps and are stored with the
+// target collection's compression.
+func (db *DB) Load(r io.Reader, opts LoadOptions) (*LoadResult, error) {
+	start := time.Now()
+	spool, err := spoolDump(r)
+	if err != nil {
+		return nil, err
+	}
+	defer func() {
+		_ = spool.Close()
+		_ = os.Remove(spool.Name())
+	}()
+
+	m, err := verifyDump(spool)
+	if err != nil {
+		return nil, err
+	}
+	targets, err := loadTargets(m, opts)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := spool.Seek(0, io.SeekStart); err != nil {
+		return nil, err
+	}
+
+	res := &LoadResult{Format: m.Format, Version: m.Version}
+	err = db.update(func(tx *bolt.Tx) error {
+		res.Collections = nil
+		l := &loader{db: db, tx: tx, opts: opts, targets: targets}
+		tr := tar.NewReader(spool)
+		for {
+			hdr, err := tr.Next()
+			if err == io.EOF {
+				break
+			}
+			if err != nil {
+				return err
+			}
+			if err := l.entry(hdr.Name, tr); errors.Is(err, ErrInvalidDump) {
+				return fmt.Errorf("%s: %w", hdr.Name, err)
+			} else if err != nil {
+				return err
+			}
+		}
+		for _, dc := range m.Collections {
+			if lc := l.loaded[dc.Dir]; lc != nil {
+				res.Collections = append(res.Collections, *lc)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	res.DurationMs = time.Since(start).Milliseconds()
+	return res, nil
+}
+
+// spoolDump copies a dump, decompressed, into a temporary file
+func spoolDump(r io.Reader) (*os.File, error) {
+	br := bufio.NewReader(r)
+	var src io.Reader = br
+	if magic, _ := br.Peek(len(zstdMagic)); bytes.Equal(magic, zstdMagic) {
+		dec, err := zstd.NewReader(br)
+		if err != nil {
+			return nil, err
+		}
+		defer dec.Close()
+		src = invalidOnError{dec, ErrInvalidDump}
+	}
+	f, err := os.CreateTemp("", "mddb-load-*.tar")
+	if err != nil {
+		return nil, err
+	}
+	if _, err := io.Copy(f, src); err != nil {
+		_ = f.Close()
+		_ = os.Remove(f.Name())
+		return nil, err
+	}
+	if _, err := f.Seek(0, io.SeekStart); err != nil {
+		_ = f.Close()
+		_ = os.Remove(f.Name())
+		return nil, err
+	}
+	return f, nil
+}
+
+// verifyDump checks the format and every entry of a spooled dump against its manifest
+func verifyDump(r io.Reader) (*DumpManifest, error) {
+	tr := tar.NewReader(r)
+	var seen []DumpFile
+	var m *DumpManifest
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidDump, err)
+		}
+		if m != nil {
+			return nil, fmt.Errorf("%w: %s after the manifest", ErrInvalidDump, hdr.Name)
+		}
+		h := sha256.New()
+		var data []byte
+		if hdr.Name == dumpHeaderName || hdr.Name == dumpManifestName {
+			if data, err = io.ReadAll(io.TeeReader(tr, h)); err != nil {
+				return nil, fmt.Errorf("%w: %v", ErrInvalidDump, err)
+			}
+		} else if _, err := io.Copy(h, tr); err != nil {
+			return nil, fmt.Errorf("%w: %v", ErrInvalidDump, err)
+		}
+
+		switch {
+		case len(seen) == 0:
+			var hd dumpHeader
+			if hdr.Name != dumpHeaderName || json.Unmarshal(data, &hd) != nil || hd.Format != DumpFormat {
+				return nil, fmt.Errorf("%w: not an mddb dump", ErrInvalidDump)
+			}
+			if hd.Version > DumpVersion {
+				return nil, fmt.Errorf("%w: dump version %d is newer than this server reads (%d)", ErrUnsupportedFormat, hd.Version, DumpVersion)
+			}
+		case hdr.Name == dumpManifestName:
+			m = &DumpManifest{}
+			if err := json.Unmarshal(data, m); err != nil {
+				return nil, fmt.Errorf("%w: manifest: %v", ErrInvalidDump, err)
+			}
+			continue
+		}
+		seen = append(seen, DumpFile{Name: hdr.Name, Size: hdr.Size, SHA256: hex.EncodeToString(h.Sum(nil))})
+	}
+	if m == nil {
+		return nil, fmt.Errorf("%w: no manifest, the dump is truncated", ErrInvalidDump)
+	}
+	if len(seen) != len(m.Files) {
+		return nil, fmt.Errorf("%w: %d entries, the manifest lists %d", ErrInvalidDump, len(seen), len(m.Files))
+	}
+	for i, f := range m.Files {
+		if seen[i] != f {
+			return nil, fmt.Errorf("%w: %s does not match its checksum", ErrInvalidDump, seen[i].Name)
+		}
+	}
+	return m, nil
+}
+
+// loadTargets maps the directories of the selected collections to their target names
+func loadTargets(m *DumpManifest, opts LoadOptions) (map[string]string, error) {
+	dirs := make(map[string]string, len(m.Collections))
+	for _, dc := range m.Collections {
+		dirs[dc.Name] = dc.Dir
+	}
+	names := opts.Collections
+	if len(names) == 0 {
+		for _, dc := range m.Collections {
+			names = append(names, dc.Name)
+		}
+	}
+	for from := range opts.Rename {
+		if dirs[from] == "" {
+			return nil, fmt.Errorf("%w: %s is not in the dump", ErrCollectionNotFound, from)
+		}
+	}
+
+	targets := make(map[string]string, len(names))
+	used := make(map[string]bool, len(names))
+	for _, name := range names {
+		dir := dirs[name]
+		if dir == "" {
+			return nil, fmt.Errorf("%w: %s is not in the dump", ErrCollectionNotFound, name)
+		}
+		to := name
+		if r, ok := opts.Rename[name]; ok {
+			to = r
+		}
+		if to == "" {
+			return nil, fmt.Errorf("%w: empty target name for %s", ErrMissingFields, name)
+		}
+		if used[to] {
+			return nil, fmt.Errorf("%w: %s is loaded twice", ErrCollectionExists, to)
+		}
+		used[to] = true
+		targets[dir] = to
+	}
+	return targets, nil
+}
+
+// loader writes the entries of a verified dump inside one write transaction
+type loader struct {
+	db      *DB
+	tx      *bolt.Tx
+	opts    LoadOptions
+	targets map[string]string            // dump directory -> target collection
+	loaded  map[string]*LoadedCollection // by dump directory
+	info    map[string]*CollectionInfo   // target settings, by dump directory
+}
+
+func (l *loader) entry(name string, r io.Reader) error {
+	dir, file := path.Split(name)
+	dir = strings.TrimSuffix(dir, "/")
+	to, ok := l.targets[dir]
+	if !ok {
+		return nil // the header, the manifest or a collection that wasn't selected
+	}
+	switch {
+	case file == "collection.json":
+		return l.collection(dir, to, r)
+	case l.loaded[dir] == nil:
+		return fmt.Errorf("%w: comes before collection.json", ErrInvalidDump)
+	case strings.HasPrefix(file, "documents-"):
+		return l.ndjson(r, func(dec *json.Decoder) error {
+			var d Doc
+			if err := dec.Decode(&d); err != nil {
+				return fmt.Errorf("%w: %v", ErrInvalidDump, err)
+			}
+			return l.document(dir, to, &d)
+		})
+	case strings.HasPrefix(file, "revisions-"):
+		return l.ndjson(r, func(dec *json.Decoder) error {
+			var rev Revision
+			if err := dec.Decode(&rev); err != nil {
+				return fmt.Errorf("%w: %v", ErrInvalidDump, err)
+			}
+			return l.revision(dir, to, &rev)
+		})
+	}
+	return nil // written by a newer version of this format version; nothing to load
+}
+
+// collection replaces or creates the target collection with the dumped settings and schema
+func (l *loader) collection(dir, to string, r io.Reader) error {
+	var entry dumpedCollection
+	if err := json.NewDecoder(r).Decode(&entry); err != nil {
+		return fmt.Errorf("%w: %v", ErrInvalidDump, err)
+	}
+	db, tx := l.db, l.tx
+	lc := &LoadedCollection{From: entry.Collection.Name, Name: to}
+
+	bColl := tx.Bucket(db.buckets.Collections)
+	prefix := kCollPrefix("doc", to)
+	k, _ := tx.Bucket(db.buckets.Docs).Cursor().Seek(prefix)
+	if bColl.Get([]byte(to)) != nil || k != nil && bytes.HasPrefix(k, prefix) {
+		if !l.opts.Replace {
+			return fmt.Errorf("%w: %s", ErrCollectionExists, to)
+		}
+		if _, err := db.deleteCollectionTx(tx, to); err != nil {
+			return err
+		}
+		lc.Replaced = true
+	}
+
+	ci := entry.Collection
+	ci.Name = to
+	ci.UpdatedAt = time.Now().Unix()
+	if err := ci.normalize(); err != nil {
+		return err
+	}
+	if err := putCollectionInfo(bColl, &ci); err != nil {
+		return err
+	}
+	db.logKey(db.buckets.Collections, to)
+	if entry.Schema != nil {
+		schema := *entry.Schema
+		schema.Collection = to
+		if _, err := compileSchema(schema); err != nil {
+			return err
+		}
+		buf, err := json.Marshal(schema)
+		if err != nil {
+			return err
+		}
+		if err := tx.Bucket(db.buckets.Schema).Put([]byte(to), buf); err != nil {
+			return err
+		}
+		db.logKey(db.buckets.Schema, to)
+	}
+
+	tx.OnCommit(func() {
+		db.cache.InvalidatePrefix(collectionCachePrefix(to))
+		db.Schemas.Forget(to)
+		db.Collections.mu.Lock()
+		db.Collections.collections[to] = &ci
+		db.Collections.mu.Unlock()
+	})
+	if l.loaded == nil {
+		l.loaded = make(map[string]*LoadedCollection)
+		l.info = make(map[string]*CollectionInfo)
+	}
+	l.loaded[dir], l.info[dir] = lc, &ci
+	return nil
+}
+
+func (l *loader) ndjson(r io.Reader, decode func(*json.Decoder) error) error {
+	dec := json.NewDecoder(bufio.NewReader(r))
+	for line := 1; dec.More(); line++ {
+		if err := decode(dec); err != nil {
+			return fmt.Errorf("line %d: %w", line, err)
+		}
+	}
+	return nil
+}
+
+func (l *loader) document(dir, to string, d *Doc) error {
+	if d.Key == "" || d.Lang == "" {
+		return ErrMissingFields
+	}
+	db, tx := l.db, l.tx
+	d.ID = genID(to, d.Key, d.Lang)
+	buf, err := marshalDocWith(d, l.info[dir].Compression)
+	if err != nil {
+		return err
+	}
+	if err := tx.Bucket(db.buckets.Docs).Put(kDoc(to, d.ID), buf); err != nil {
+		return err
+	}
+	if err := tx.Bucket(db.buckets.ByKey).Put(kByKey(to, d.Key, d.Lang), []byte(d.ID)); err != nil {
+		return err
+	}
+	job := &IndexJob{Collection: to, DocID: d.ID, NewMeta: d.Meta}
+	if err := job.apply(tx.Bucket(db.buckets.IdxMeta)); err != nil {
+		return err
+	}
+	db.invalidateTx(tx, to, d.Key, d.Lang)
+	l.loaded[dir].Documents++
+	return nil
+}
+
+func (l *loader) revision(dir, to string, rev *Revision) error {
+	d := &rev.Doc
+	if d.Key == "" || d.Lang == "" {
+		return ErrMissingFields
+	}
+	db := l.db
+	d.ID = genID(to, d.Key, d.Lang)
+	buf, err := marshalDocWith(d, l.info[dir].Compression)
+	if err != nil {
+		return err
+	}
+	bRev := l.tx.Bucket(db.buckets.Rev)
+	key := nextRevisionKey(bRev, to, d.ID, rev.SavedAt)
+	if err := bRev.Put(key, buf); err != nil {
+		return err
+	}
+	db.logKey(db.buckets.Rev, string(key))
+	l.loaded[dir].Revisions++
+	return nil
+}
@@ -0,0 +1,133 @@
+package storage
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"time"
+
+	json "github.com/goccy/go-json"
+	bolt "go.etcd.io/bbolt"
+)
+
+var ErrUnsupportedFormat = errors.New("unsupported format")
+
+// ExportContentTypes maps export formats to their HTTP content types
+var ExportContentTypes = map[string]string{
+	"ndjson": "application/x-ndjson",
+	"zip":    "application/zip",
+	"tar":    "application/x-tar",
+}
+
+// forEachDoc calls fn for every document of a collection matching filterMeta.
+// Documents are decoded one at a time, so memory use does not grow with the collection.
+func (db *DB) forEachDoc(tx *bolt.Tx, collection string, filterMeta map[string][]string, fn func(*Doc) error) error {
+	bDocs := tx.Bucket(db.buckets.Docs)
+
+	visit := func(k, v []byte) error {
+		d, _, err := decodeStoredDoc(v)
+		if err != nil {
+			return fmt.Errorf("%s: %w", k, err)
+		}
+		return fn(d)
+	}
+
+	if len(filterMeta) == 0 {
+		c := bDocs.Cursor()
+		prefix := kCollPrefix("doc", collection)
+		for k, v := c.Seek(prefix); k != nil && bytes.HasPrefix(k, prefix); k, v = c.Next() {
+			if err := visit(k, v); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	// Only document IDs are kept in memory for the meta intersection
+	bIdx := tx.Bucket(db.buckets.IdxMeta)
+	var sets [][]string
+	for mk, mvals := range filterMeta {
+		var ids []string
+		for _, mv := range mvals {
+			prefix := kMetaKeyPrefix(collection, mk, mv)
+			c := bIdx.Cursor()
+			for k, _ := c.Seek(prefix); k != nil && bytes.HasPrefix(k, prefix); k, _ = c.Next() {
+				ids = append(ids, string(k[len(prefix):]))
+			}
+		}
+		sets = append(sets, unique(ids))
+	}
+	for _, id := range intersect(sets...) {
+		k := kDoc(collection, id)
+		if v := bDocs.Get(k); v != nil {
+			if err := visit(k, v); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// Export streams the matching documents to w as ndjson, zip ({key}.{lang}.md files) or tar
+func (db *DB) Export(w io.Writer, collection string, filterMeta map[string][]string, format string) error {
+	if _, ok := ExportContentTypes[format]; !ok {
+		return ErrUnsupportedFormat
+	}
+
+	return db.view(func(tx *bolt.Tx) error {
+		switch format {
+		case "zip":
+			zw := zip.NewWriter(w)
+			err := db.forEachDoc(tx, collection, filterMeta, func(d *Doc) error {
+				f, err := zw.CreateHeader(&zip.FileHeader{
+					Name:     exportFileName(d),
+					Method:   zip.Deflate,
+					Modified: time.Unix(d.UpdatedAt, 0),
+				})
+				if err != nil {
+					return err
+				}
+				_, err = io.WriteString(f, d.ContentMD)
+				return err
+			})
+			if err != nil {
+				return err
+			}
+			return zw.Close()
+
+		case "tar":
+			tw := tar.NewWriter(w)
+			err := db.forEachDoc(tx, collection, filterMeta, func(d *Doc) error {
+				hdr := &tar.Header{
+					Name:    exportFileName(d),
+					Mode:    0644,
+					Size:    int64(len(d.ContentMD)),
+					ModTime: time.Unix(d.UpdatedAt, 0),
+				}
+				if err := tw.WriteHeader(hdr); err != nil {
+					return err
+				}
+				_, err := io.WriteString(tw, d.ContentMD)
+				return err
+			})
+			if err != nil {
+				return err
+			}
+			return tw.Close()
+
+		default:
+			enc := json.NewEncoder(w)
+			return db.forEachDoc(tx, collection, filterMeta, func(d *Doc) error {
+				return enc.Encode(d)
+			})
+		}
+	})
+}
+
+// exportFileName names a document inside zip and tar archives
+func exportFileName(d *Doc) string {
+	return fmt.Sprintf("%s.%s.md", safe(d.Key), safe(d.Lang))
+}
@@ -0,0 +1,332 @@
+package storage
+
+import (
+	"bytes"
+	"slices"
+
+	json "github.com/goccy/go-json"
+	bolt "go.etcd.io/bbolt"
+)
+
+const (
+	fsckRepairBatch = 256  // repairs per write transaction
+	maxFsckIssues   = 1000 // problems listed in a report; all of them are counted and repaired
+	fsckDocMemo     = 4096 // documents remembered while checking index entries
+)
+
+// Problem kinds reported by Fsck
+const (
+	FsckOrphanMetaIndex     = "orphanMetaIndex"     // meta index entry of a missing document or of a value it no longer has
+	FsckMissingMetaIndex    = "missingMetaIndex"    // meta value of a document that is not indexed
+	FsckOrphanKeyEntry      = "orphanKeyEntry"      // bykey entry pointing to a missing document
+	FsckMissingKeyEntry     = "missingKeyEntry"     // document that cannot be found by its key and lang
+	FsckOrphanRevision      = "orphanRevision"      // revision of a deleted document
+	FsckUndecodableDocument = "undecodableDocument" // document value that cannot be decoded
+	FsckUndecodableRevision = "undecodableRevision" // revision value that cannot be decoded
+)
+
+// FsckOptions selects what Fsck checks and whether it repairs what it finds
+type FsckOptions struct {
+	Collection string `json:"collection"` // empty checks every collection
+	Repair     bool   `json:"repair"`
+}
+
+// FsckIssue is one inconsistency found by Fsck
+type FsckIssue struct {
+	Kind       string `json:"kind"`
+	Collection string `json:"collection"`
+	Key        string `json:"key"` // storage key of the offending (or missing) entry
+	Repaired   bool   `json:"repaired"`
+}
+
+// FsckReport is the result of a consistency check
+type FsckReport struct {
+	Documents   int            `json:"documents"` // entries scanned per bucket
+	KeyEntries  int            `json:"keyEntries"`
+	MetaIndices int            `json:"metaIndices"`
+	Revisions   int            `json:"revisions"`
+	Problems    map[string]int `json:"problems"` // number of problems per kind
+	Issues      []FsckIssue    `json:"issues"`   // the first 1000 problems
+	Repaired    int            `json:"repaired"`
+}
+
+// fsckProblem is an issue with the repair that fixes it, if it still applies
+type fsckProblem struct {
+	FsckIssue
+	fix func(f *fsckTx) (bool, error)
+}
+
+// Fsck checks that the docs, bykey, idxmeta and rev buckets agree. The scan runs on a
+// read snapshot, so writes continue meanwhile. With Repair every problem is checked again
+// and fixed in batched write transactions: orphaned entries are deleted, missing ones
+// written, and undecodable documents and revisions deleted along with their entries.
+// Index entries of documents with queued index jobs are not checked; they are catching up.
+func (db *DB) Fsck(opts FsckOptions) (*FsckReport, error) {
+	rep := &FsckReport{Problems: map[string]int{}, Issues: []FsckIssue{}}
+	var found []fsckProblem
+	err := db.view(func(tx *bolt.Tx) error {
+		f, err := db.newFsckTx(tx)
+		if err != nil {
+			return err
+		}
+		found = f.scan(opts.Collection, rep)
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	for i, p := range found {
+		rep.Problems[p.Kind]++
+		if i < maxFsckIssues {
+			rep.Issues = append(rep.Issues, p.FsckIssue)
+		}
+	}
+	if !opts.Repair {
+		return rep, nil
+	}
+
+	touched := make(map[string]bool)
+	for start := 0; start < len(found); start += fsckRepairBatch {
+		batch := found[start:min(start+fsckRepairBatch, len(found))]
+		fixed := make([]bool, len(batch))
+		err := db.update(func(tx *bolt.Tx) error {
+			f, err := db.newFsckTx(tx)
+			if err != nil {
+				return err
+			}
+			for i, p := range batch {
+				if fixed[i], err = p.fix(f); err != nil {
+					return err
+				}
+			}
+			return nil
+		})
+		if err != nil {
+			return rep, err
+		}
+		for i, ok := range fixed {
+			if !ok {
+				continue // fixed by a concurrent write
+			}
+			rep.Repaired++
+			touched[batch[i].Collection] = true
+			if start+i < len(rep.Issues) {
+				rep.Issues[start+i].Repaired = true
+			}
+		}
+	}
+	for coll := range touched {
+		db.DropCache(coll)
+	}
+	return rep, nil
+}
+
+// fsckTx answers the lookups of the checks within one transaction
+type fsckTx struct {
+	bDocs, bByK, bIdx, bRev *bolt.Bucket
+
+	queued map[string]bool // doc keys with queued index jobs
+	docs   map[string]*Doc // loaded documents; nil when missing or undecodable
+}
+
+func (db *DB) newFsckTx(tx *bolt.Tx) (*fsckTx, error) {
+	f := &fsckTx{
+		bDocs:  tx.Bucket(db.buckets.Docs),
+		bByK:   tx.Bucket(db.buckets.ByKey),
+		bIdx:   tx.Bucket(db.buckets.IdxMeta),
+		bRev:   tx.Bucket(db.buckets.Rev),
+		queued: make(map[string]bool),
+		docs:   make(map[string]*Doc),
+	}
+	err := tx.Bucket(db.buckets.IdxQueue).ForEach(func(_, v []byte) error {
+		var job IndexJob
+		if json.Unmarshal(v, &job) == nil {
+			f.queued[string(kDoc(job.Collection, job.DocID))] = true
+		}
+		return nil
+	})
+	return f, err
+}
+
+// scan walks the buckets (of one collection, or all) and returns every problem found
+func (f *fsckTx) scan(collection string, rep *FsckReport) []fsckProblem {
+	var out []fsckProblem
+	add := func(kind, coll string, key []byte, fix func(f *fsckTx) (bool, error)) {
+		out = append(out, fsckProblem{FsckIssue{Kind: kind, Collection: coll, Key: string(key)}, fix})
+	}
+	each := func(b *bolt.Bucket, kind string, fn func(k, v []byte)) {
+		prefix := []byte(kind + "|")
+		if collection != "" {
+			prefix = kCollPrefix(kind, collection)
+		}
+		c := b.Cursor()
+		for k, v := c.Seek(prefix); k != nil && bytes.HasPrefix(k, prefix); k, v = c.Next() {
+			fn(CopyBytes(k), v) // keys outlive the snapshot in the repairs
+		}
+	}
+
+	each(f.bDocs, "doc", func(k, v []byte) {
+		rep.Documents++
+		coll := keyCollection(k)
+		d, err := unmarshalDoc(v)
+		if err != nil {
+			add(FsckUndecodableDocument, coll, k, func(f *fsckTx) (bool, error) {
+				return fixIf(f.undecodable(f.bDocs, k), func() error { return f.bDocs.Delete(k) })
+			})
+			return
+		}
+		docID := k[len(kCollPrefix("doc", coll)):]
+		if kb := kByKey(coll, d.Key, d.Lang); !bytes.Equal(f.bByK.Get(kb), docID) {
+			add(FsckMissingKeyEntry, coll, kb, func(f *fsckTx) (bool, error) {
+				return fixIf(f.missingKey(k, docID, kb), func() error { return f.bByK.Put(kb, docID) })
+			})
+		}
+		if f.queued[string(k)] {
+			return
+		}
+		for mk, vals := range d.Meta {
+			for _, mv := range vals {
+				ik := append(kMetaKeyPrefix(coll, mk, mv), docID...)
+				if f.bIdx.Get(ik) == nil {
+					add(FsckMissingMetaIndex, coll, ik, func(f *fsckTx) (bool, error) {
+						return fixIf(f.missingMeta(k, mk, mv, ik), func() error { return f.bIdx.Put(ik, []byte("1")) })
+					})
+				}
+			}
+		}
+	})
+
+	each(f.bByK, "bykey", func(k, _ []byte) {
+		rep.KeyEntries++
+		if f.orphanKey(k) {
+			add(FsckOrphanKeyEntry, keyCollection(k), k, func(f *fsckTx) (bool, error) {
+				return fixIf(f.orphanKey(k), func() error { return f.bByK.Delete(k) })
+			})
+		}
+	})
+
+	each(f.bIdx, "meta", func(k, _ []byte) {
+		rep.MetaIndices++
+		if f.orphanMeta(k) {
+			add(FsckOrphanMetaIndex, keyCollection(k), k, func(f *fsckTx) (bool, error) {
+				return fixIf(f.orphanMeta(k), func() error { return f.bIdx.Delete(k) })
+			})
+		}
+	})
+
+	each(f.bRev, "rev", func(k, _ []byte) {
+		rep.Revisions++
+		switch {
+		case f.orphanRevision(k):
+			add(FsckOrphanRevision, keyCollection(k), k, func(f *fsckTx) (bool, error) {
+				return fixIf(f.orphanRevision(k), func() error { return f.bRev.Delete(k) })
+			})
+		case f.undecodable(f.bRev, k):
+			add(FsckUndecodableRevision, keyCollection(k), k, func(f *fsckTx) (bool, error) {
+				return fixIf(f.undecodable(f.bRev, k), func() error { return f.bRev.Delete(k) })
+			})
+		}
+	})
+	return out
+}
+
+// fixIf runs fix when the problem is still there and reports whether it did
+func fixIf(broken bool, fix func() error) (bool, error) {
+	if !broken {
+		return false, nil
+	}
+	return true, fix()
+}
+
+// doc loads the document stored under docKey; nil when it is missing or undecodable
+func (f *fsckTx) doc(docKey []byte) *Doc {
+	if d, ok := f.docs[string(docKey)]; ok {
+		return d
+	}
+	var d *Doc
+	if v := f.bDocs.Get(docKey); v != nil {
+		if decoded, err := unmarshalDoc(v); err == nil {
+			d = decoded
+		}
+	}
+	if len(f.docs) >= fsckDocMemo {
+		clear(f.docs)
+	}
+	f.docs[string(docKey)] = d
+	return d
+}
+
+// undecodable reports whether the value under k in b exists and cannot be decoded
+func (f *fsckTx) undecodable(b *bolt.Bucket, k []byte) bool {
+	v := b.Get(k)
+	if v == nil {
+		return false
+	}
+	_, err := unmarshalDoc(v)
+	return err != nil
+}
+
+// missingKey reports whether the document docID under docKey is not found through its bykey entry kb
+func (f *fsckTx) missingKey(docKey, docID, kb []byte) bool {
+	d := f.doc(docKey)
+	if d == nil || !bytes.Equal(kByKey(keyCollection(docKey), d.Key, d.Lang), kb) {
+		return false
+	}
+	return !bytes.Equal(f.bByK.Get(kb), docID)
+}
+
+// missingMeta reports whether the document under docKey has mk=mv but index entry ik is absent
+func (f *fsckTx) missingMeta(docKey []byte, mk, mv string, ik []byte) bool {
+	if f.queued[string(docKey)] || f.bIdx.Get(ik) != nil {
+		return false
+	}
+	d := f.doc(docKey)
+	return d != nil && slices.Contains(d.Meta[mk], mv)
+}
+
+// orphanKey reports whether bykey entry k points to a document that is missing or has another key
+func (f *fsckTx) orphanKey(k []byte) bool {
+	v := f.bByK.Get(k)
+	if v == nil {
+		return false
+	}
+	parts := bytes.SplitN(k, []byte("|"), 4)
+	if len(parts) != 4 {
+		return true
+	}
+	coll := unescapeKeyPart(parts[1])
+	return f.doc(kDoc(coll, string(v))) == nil || genID(coll, unescapeKeyPart(parts[2]), unescapeKeyPart(parts[3])) != string(v)
+}
+
+// orphanMeta reports whether meta index entry k belongs to a missing document or a value it no longer has
+func (f *fsckTx) orphanMeta(k []byte) bool {
+	if f.bIdx.Get(k) == nil {
+		return false
+	}
+	parts := bytes.SplitN(k, []byte("|"), 5)
+	if len(parts) != 5 {
+		return true
+	}
+	docKey := kDoc(unescapeKeyPart(parts[1]), string(parts[4]))
+	if f.queued[string(docKey)] {
+		return false
+	}
+	d := f.doc(docKey)
+	return d == nil || !slices.Contains(d.Meta[unescapeKeyPart(parts[2])], unescapeKeyPart(parts[3]))
+}
+
+// orphanRevision reports whether revision k belongs to a missing document
+func (f *fsckTx) orphanRevision(k []byte) bool {
+	if f.bRev.Get(k) == nil {
+		return false
+	}
+	parts := bytes.SplitN(k, []byte("|"), 3)
+	if len(parts) != 3 {
+		return true
+	}
+	i := bytes.LastIndexByte(parts[2], '|')
+	if i < 0 {
+		return true
+	}
+	return f.doc(kDoc(unescapeKeyPart(parts[1]), string(parts[2][:i]))) == nil
+}
@@ -0,0 +1,379 @@
+package storage
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bufio"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path"
+	"strings"
+	"time"
+
+	json "github.com/goccy/go-json"
+	bolt "go.etcd.io/bbolt"
+)
+
+// Import conflict policies
+const (
+	ConflictUpsert = "upsert" // overwrite existing documents
+	ConflictSkip   = "skip"   // keep existing documents
+	ConflictFail   = "fail"   // abort the import on the first existing document
+)
+
+const (
+	defaultImportChunkSize = 500
+	maxImportErrors        = 100 // per-document errors reported in the result
+	maxImportFileSize      = 64 << 20
+)
+
+// maxImportZipSize caps a zip upload, which is spooled to disk (a variable for tests)
+var maxImportZipSize int64 = 1 << 30
+
+var (
+	ErrImportConflict = errors.New("document already exists")
+	ErrImportTooLarge = errors.New("import archive too large")
+	errImportDryRun   = errors.New("dry run")
+	errImportSkip     = errors.New("document exists, skipped")
+)
+
+// ImportOptions controls a bulk import
+type ImportOptions struct {
+	Collection   string `json:"collection"`
+	Format       string `json:"format"`     // ndjson|zip|tar
+	OnConflict   string `json:"onConflict"` // upsert|skip|fail
+	DryRun       bool   `json:"dryRun"`     // validate and count without writing
+	Lang         string `json:"lang"`       // language for files named {key}.md
+	ChunkSize    int    `json:"chunkSize"`  // documents per transaction
+	SaveRevision bool   `json:"saveRevision"`
+}
+
+// ImportResult reports the progress or outcome of a bulk import
+type ImportResult struct {
+	Total   int      `json:"total"`
+	Added   int      `json:"added"`
+	Updated int      `json:"updated"`
+	Skipped int      `json:"skipped"`
+	Failed  int      `json:"failed"`
+	Chunks  int      `json:"chunks"` // committed transactions
+	Errors  []string `json:"errors,omitempty"`
+	DryRun  bool     `json:"dryRun"`
+	Done    bool     `json:"done"`
+	Error   string   `json:"error,omitempty"` // set when the import was aborted
+}
+
+func (r *ImportResult) fail(key, lang string, err error) {
+	r.Failed++
+	if len(r.Errors) < maxImportErrors {
+		r.Errors = append(r.Errors, fmt.Sprintf("%s/%s: %v", key, lang, err))
+	}
+}
+
+// Normalize fills defaults and checks options
+func (o *ImportOptions) Normalize() error {
+	if o.Collection == "" {
+		return errors.New("missing collection")
+	}
+	switch o.Format {
+	case "":
+		o.Format = "ndjson"
+	case "ndjson", "zip", "tar":
+	default:
+		return ErrUnsupportedFormat
+	}
+	switch o.OnConflict {
+	case "":
+		o.OnConflict = ConflictUpsert
+	case ConflictUpsert, ConflictSkip, ConflictFail:
+	default:
+		return fmt.Errorf("invalid onConflict: %s", o.OnConflict)
+	}
+	if o.ChunkSize <= 0 {
+		o.ChunkSize = defaultImportChunkSize
+	}
+	return nil
+}
+
+// Import reads documents from r and writes them in chunked transactions.
+// progress (optional) is called after every chunk. Chunks committed before a fatal
+// error stay committed; the result tells how far the import got.
+func (db *DB) Import(r io.Reader, opts ImportOptions, progress func(*ImportResult)) (*ImportResult, error) {
+	if err := opts.Normalize(); err != nil {
+		return nil, err
+	}
+	res := &ImportResult{DryRun: opts.DryRun}
+
+	var chunk []*Doc
+	flush := func() error {
+		if len(chunk) == 0 {
+			return nil
+		}
+		err := db.commitImportChunk(chunk, &opts, res)
+		chunk = chunk[:0]
+		if err != nil {
+			return err
+		}
+		res.Chunks++
+		log.Printf("Import into %s: %d documents processed (%d chunks)", opts.Collection, res.Total, res.Chunks)
+		if progress != nil {
+			progress(res)
+		}
+		return nil
+	}
+	emit := func(d *Doc) error {
+		res.Total++
+		d.Lang = db.Collections.DefaultLang(opts.Collection, d.Lang)
+		if d.Key == "" || d.Lang == "" {
+			res.fail(d.Key, d.Lang, errors.New("missing key or lang"))
+			return nil
+		}
+		chunk = append(chunk, d)
+		if len(chunk) >= opts.ChunkSize {
+			return flush()
+		}
+		return nil
+	}
+
+	var err error
+	switch opts.Format {
+	case "zip":
+		err = readImportZip(r, opts.Lang, emit)
+	case "tar":
+		err = readImportTar(r, opts.Lang, emit)
+	default:
+		err = readImportNDJSON(r, emit)
+	}
+	if err == nil {
+		err = flush()
+	}
+	res.Done = err == nil
+	if err != nil {
+		res.Error = err.Error()
+	}
+	return res, err
+}
+
+// commitImportChunk writes one chunk in a single transaction (rolled back for dry runs).
+// Every document is written by putTx, like a single add.
+func (db *DB) commitImportChunk(docs []*Doc, opts *ImportOptions, res *ImportResult) error {
+	coll := opts.Collection
+	now := time.Now().Unix()
+
+	// Counted separately so an aborted chunk does not show up in the result
+	var part ImportResult
+	err := db.update(func(tx *bolt.Tx) error {
+		for _, d := range docs {
+			if err := db.Schemas.Validate(coll, d.Key, d.Lang, d.Meta, d.ContentMD); err != nil {
+				part.fail(d.Key, d.Lang, err)
+				continue
+			}
+
+			req := AddRequest{Collection: coll, Key: d.Key, Lang: d.Lang, Meta: d.Meta, ContentMD: d.ContentMD, SaveRevision: opts.SaveRevision}
+			_, created, err := db.putTx(tx, req, func(existing *Doc) error {
+				if existing == nil {
+					return nil
+				}
+				switch opts.OnConflict {
+				case ConflictSkip:
+					return errImportSkip
+				case ConflictFail:
+					return fmt.Errorf("%s/%s: %w", d.Key, d.Lang, ErrImportConflict)
+				}
+				return nil
+			}, now)
+			var verr *ValidationError
+			switch {
+			case errors.Is(err, errImportSkip):
+				part.Skipped++
+			case errors.As(err, &verr):
+				part.fail(d.Key, d.Lang, err)
+			case err != nil:
+				return err
+			case created:
+				part.Added++
+			default:
+				part.Updated++
+			}
+		}
+
+		if opts.DryRun {
+			return errImportDryRun
+		}
+		return nil
+	})
+	if err != nil && !errors.Is(err, errImportDryRun) {
+		return err
+	}
+
+	res.Added += part.Added
+	res.Updated += part.Updated
+	res.Skipped += part.Skipped
+	res.Failed += part.Failed
+	for _, e := range part.Errors {
+		if len(res.Errors) < maxImportErrors {
+			res.Errors = append(res.Errors, e)
+		}
+	}
+	return nil
+}
+
+// readImportNDJSON reads one JSON document per line (the /v1/export ndjson format)
+func readImportNDJSON(r io.Reader, emit func(*Doc) error) error {
+	dec := json.NewDecoder(bufio.NewReader(r))
+	for line := 1; ; line++ {
+		var d Doc
+		if err := dec.Decode(&d); err != nil {
+			if err == io.EOF {
+				return nil
+			}
+			return fmt.Errorf("ndjson document %d: %w", line, err)
+		}
+		if err := emit(&d); err != nil {
+			return err
+		}
+	}
+}
+
+// readImportTar reads {key}.{lang}.md files from a tar stream
+func readImportTar(r io.Reader, lang string, emit func(*Doc) error) error {
+	tr := tar.NewReader(r)
+	for {
+		hdr, err := tr.Next()
+		if err == io.EOF {
+			return nil
+		}
+		if err != nil {
+			return err
+		}
+		if hdr.Typeflag != tar.TypeReg || !isMarkdownFile(hdr.Name) {
+			continue
+		}
+		if hdr.Size > maxImportFileSize {
+			return fmt.Errorf("%s: file too large", hdr.Name)
+		}
+		content, err := io.ReadAll(tr)
+		if err != nil {
+			return err
+		}
+		if err := emit(docFromFile(hdr.Name, lang, content)); err != nil {
+			return err
+		}
+	}
+}
+
+// readImportZip reads {key}.{lang}.md files from a zip archive.
+// Zip needs random access, so the upload is spooled to a temporary file first.
+func readImportZip(r io.Reader, lang string, emit func(*Doc) error) error {
+	f, err := os.CreateTemp("", "mddb-import-*.zip")
+	if err != nil {
+		return err
+	}
+	defer func() {
+		_ = f.Close()
+		_ = os.Remove(f.Name())
+	}()
+	size, err := io.Copy(f, io.LimitReader(r, maxImportZipSize+1))
+	if err != nil {
+		return err
+	}
+	if size > maxImportZipSize {
+		return fmt.Errorf("%w: zip uploads are limited to %d MiB", ErrImportTooLarge, maxImportZipSize>>20)
+	}
+	zr, err := zip.NewReader(f, size)
+	if err != nil {
+		return err
+	}
+	for _, zf := range zr.File {
+		if zf.FileInfo().IsDir() || !isMarkdownFile(zf.Name) {
+			continue
+		}
+		if zf.UncompressedSize64 > maxImportFileSize {
+			return fmt.Errorf("%s: file too large", zf.Name)
+		}
+		rc, err := zf.Open()
+		if err != nil {
+			return err
+		}
+		content, err := io.ReadAll(io.LimitReader(rc, maxImportFileSize+1))
+		_ = rc.Close()
+		if err != nil {
+			return err
+		}
+		if err := emit(docFromFile(zf.Name, lang, content)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func isMarkdownFile(name string) bool {
+	return strings.HasSuffix(strings.ToLower(name), ".md")
+}
+
+// docFromFile builds a document from an archive entry named {key}.{lang}.md (or {key}.md).
+// YAML-style frontmatter becomes meta; the content is stored as-is.
+func docFromFile(name, lang string, content []byte) *Doc {
+	base := path.Base(strings.ReplaceAll(name, "\\", "/"))
+	base = base[:len(base)-len(".md")]
+	key := base
+	if i := strings.LastIndexByte(base, '.'); i > 0 {
+		key, lang = base[:i], base[i+1:]
+	}
+	return &Doc{Key: key, Lang: lang, Meta: parseFrontmatter(string(content)), ContentMD: string(content)}
+}
+
+// parseFrontmatter reads a leading "---" block of "key: value", "key: [a, b]"
+// and "key:" followed by "- item" lines into meta
+func parseFrontmatter(content string) map[string][]string {
+	content = strings.TrimPrefix(content, "\ufeff")
+	if !strings.HasPrefix(content, "---\n") && !strings.HasPrefix(content, "---\r\n") {
+		return nil
+	}
+	meta := make(map[string][]string)
+	var listKey string
+	lines := strings.Split(content, "\n")
+	for _, line := range lines[1:] {
+		line = strings.TrimRight(line, "\r")
+		trimmed := strings.TrimSpace(line)
+		if trimmed == "---" || trimmed == "..." {
+			return meta
+		}
+		if trimmed == "" || strings.HasPrefix(trimmed, "#") {
+			continue
+		}
+		if strings.HasPrefix(trimmed, "- ") && listKey != "" {
+			meta[listKey] = append(meta[listKey], unquote(strings.TrimSpace(trimmed[2:])))
+			continue
+		}
+		k, v, found := strings.Cut(trimmed, ":")
+		if !found {
+			continue
+		}
+		k, v = strings.TrimSpace(k), strings.TrimSpace(v)
+		listKey = ""
+		switch {
+		case v == "":
+			listKey = k
+		case strings.HasPrefix(v, "[") && strings.HasSuffix(v, "]"):
+			for _, item := range strings.Split(v[1:len(v)-1], ",") {
+				if item = unquote(strings.TrimSpace(item)); item != "" {
+					meta[k] = append(meta[k], item)
+				}
+			}
+		default:
+			meta[k] = append(meta[k], unquote(v))
+		}
+	}
+	// No closing delimiter - not frontmatter
+	return nil
+}
+
+func unquote(s string) string {
+	if len(s) >= 2 && (s[0] == '"' && s[len(s)-1] == '"' || s[0] == '\'' && s[len(s)-1] == '\'') {
+		return s[1 : len(s)-1]
+	}
+	return s
+}
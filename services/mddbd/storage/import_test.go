@@ -0,0 +1,136 @@
+package storage
+
+import (
+	"archive/zip"
+	"bytes"
+	"errors"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+const importNDJSON = `{"key":"old","lang":"en","meta":{"tag":["new"]},"contentMd":"# old, imported"}
+{"key":"fresh","lang":"en","meta":{"tag":["new"]},"contentMd":"# fresh"}
+`
+
+func TestImportConflicts(t *testing.T) {
+	cases := []struct {
+		onConflict string
+		want       ImportResult
+		err        error
+		content    string // of "old" afterwards
+	}{
+		{onConflict: ConflictUpsert, want: ImportResult{Total: 2, Added: 1, Updated: 1, Chunks: 1, Done: true}, content: "# old, imported"},
+		{onConflict: ConflictSkip, want: ImportResult{Total: 2, Added: 1, Skipped: 1, Chunks: 1, Done: true}, content: "# old"},
+		{onConflict: ConflictFail, want: ImportResult{Total: 2}, err: ErrImportConflict, content: "# old"},
+	}
+	for _, c := range cases {
+		t.Run(c.onConflict, func(t *testing.T) {
+			db := openWithChangeLog(t)
+			addDoc(t, db, "old")
+			res, err := db.Import(strings.NewReader(importNDJSON), ImportOptions{Collection: "blog", OnConflict: c.onConflict, SaveRevision: true}, nil)
+			if !errors.Is(err, c.err) {
+				t.Fatalf("Import: %v, want %v", err, c.err)
+			}
+			res.Error = ""
+			if !reflect.DeepEqual(*res, c.want) {
+				t.Errorf("Import = %+v, want %+v", res, c.want)
+			}
+			doc, err := db.Get("blog", "old", "en")
+			if err != nil {
+				t.Fatal(err)
+			}
+			if doc.ContentMD != c.content {
+				t.Errorf("old is %q, want %q", doc.ContentMD, c.content)
+			}
+		})
+	}
+}
+
+func TestImportWritesLikeAdd(t *testing.T) {
+	db := openWithChangeLog(t)
+	addDoc(t, db, "old")
+	if _, err := db.Import(strings.NewReader(importNDJSON), ImportOptions{Collection: "blog", SaveRevision: true}, nil); err != nil {
+		t.Fatal(err)
+	}
+
+	// The meta index follows the imported meta, and the old values are gone
+	for tag, want := range map[string]int{"new": 2, "old": 0} {
+		docs, _, err := db.Search(SearchQuery{Collection: "blog", FilterMeta: map[string][]string{"tag": {tag}}, Consistency: "strong"})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(docs) != want {
+			t.Errorf("search for tag %s found %d documents, want %d", tag, len(docs), want)
+		}
+	}
+	revs, err := db.Revisions("blog", "old", "en")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(revs) != 1 || revs[0].Doc.ContentMD != "# old, imported" {
+		t.Errorf("revisions of old: %+v, want the imported version", revs)
+	}
+}
+
+func TestImportDryRun(t *testing.T) {
+	db := openWithChangeLog(t)
+	res, err := db.Import(strings.NewReader(importNDJSON), ImportOptions{Collection: "blog", DryRun: true}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.Added != 2 || !res.DryRun {
+		t.Errorf("dry run = %+v, want 2 added", res)
+	}
+	if _, err := db.Get("blog", "fresh", "en"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Get after a dry run: %v, want not found", err)
+	}
+}
+
+func TestImportZip(t *testing.T) {
+	var buf bytes.Buffer
+	zw := zip.NewWriter(&buf)
+	for name, content := range map[string]string{
+		"posts/hello.en.md": "---\ntitle: Hello\ntags: [a, b]\n---\n# Hello",
+		"posts/bare.md":     "# Bare",
+		"posts/notes.txt":   "skipped",
+	} {
+		w, err := zw.Create(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := w.Write([]byte(content)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := zw.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	db := openWithChangeLog(t)
+	res, err := db.Import(bytes.NewReader(buf.Bytes()), ImportOptions{Collection: "blog", Format: "zip", Lang: "pl"}, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if res.Added != 2 {
+		t.Errorf("zip import = %+v, want 2 added", res)
+	}
+	doc, err := db.Get("blog", "hello", "en")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if strings.Join(doc.Meta["tags"], ",") != "a,b" || doc.Meta["title"][0] != "Hello" {
+		t.Errorf("frontmatter meta = %v", doc.Meta)
+	}
+	if _, err := db.Get("blog", "bare", "pl"); err != nil {
+		t.Errorf("Get(bare/pl): %v", err)
+	}
+
+	// Uploads over the limit are refused before anything is written
+	defer func(n int64) { maxImportZipSize = n }(maxImportZipSize)
+	maxImportZipSize = int64(buf.Len() - 1)
+	db = openWithChangeLog(t)
+	if _, err := db.Import(bytes.NewReader(buf.Bytes()), ImportOptions{Collection: "blog", Format: "zip"}, nil); !errors.Is(err, ErrImportTooLarge) {
+		t.Errorf("Import of an oversized zip: %v, want ErrImportTooLarge", err)
+	}
+}
@@ -0,0 +1,313 @@
+package storage
+
+import (
+	"context"
+	"encoding/binary"
+	"log"
+	"sync"
+	"time"
+
+	json "github.com/goccy/go-json"
+	bolt "go.etcd.io/bbolt"
+)
+
+// indexDrainBatch is how many queued jobs are applied per write transaction
+const indexDrainBatch = 256
+
+// IndexJob represents a metadata indexing job
+type IndexJob struct {
+	Collection string              `json:"collection"`
+	DocID      string              `json:"docId"`
+	OldMeta    map[string][]string `json:"oldMeta,omitempty"`
+	NewMeta    map[string][]string `json:"-"` // not persisted: queued jobs index the document as it is when applied
+}
+
+// IndexQueue applies metadata index updates in the background. Jobs are stored in the
+// idxqueue bucket in the same transaction as the document write, so they survive a crash
+// and are replayed on startup. One worker applies them in commit order.
+type IndexQueue struct {
+	db     *DB
+	limit  int // pending jobs before async writers block
+	wake   chan struct{}
+	drain  sync.Mutex // serializes appliers (worker and strong reads)
+	wg     sync.WaitGroup
+	ctx    context.Context
+	cancel context.CancelFunc
+
+	mu        sync.Mutex
+	room      *sync.Cond // signaled when pending drops or the queue stops
+	pending   int        // may briefly go negative: commit hooks run after the write lock is released
+	enqueued  uint64     // highest committed job sequence
+	applied   uint64     // every job up to this sequence is applied
+	processed uint64
+	failed    uint64
+}
+
+// NewIndexQueue creates an index queue; call load before use
+func NewIndexQueue(db *DB, limit int) *IndexQueue {
+	if limit <= 0 {
+		limit = 10000
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	iq := &IndexQueue{
+		db:     db,
+		limit:  limit,
+		wake:   make(chan struct{}, 1),
+		ctx:    ctx,
+		cancel: cancel,
+	}
+	iq.room = sync.NewCond(&iq.mu)
+	iq.wg.Add(1)
+	go iq.worker()
+	return iq
+}
+
+// load reads the queued jobs left by a previous run (or a restored file) and replays them
+func (iq *IndexQueue) load() error {
+	var pending int
+	var seq, first uint64
+	err := iq.db.view(func(tx *bolt.Tx) error {
+		b := tx.Bucket(iq.db.buckets.IdxQueue)
+		seq = b.Sequence()
+		if k, _ := b.Cursor().First(); k != nil {
+			first = binary.BigEndian.Uint64(k)
+		}
+		pending = b.Stats().KeyN
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	iq.mu.Lock()
+	iq.pending, iq.enqueued, iq.applied = pending, seq, seq
+	if pending > 0 {
+		iq.applied = first - 1
+	}
+	iq.room.Broadcast()
+	iq.mu.Unlock()
+	if pending > 0 {
+		log.Printf("Replaying %d queued index jobs", pending)
+		iq.signal()
+	}
+	return nil
+}
+
+// enqueueTx stores job in tx; it is applied after tx commits
+func (iq *IndexQueue) enqueueTx(tx *bolt.Tx, job *IndexJob) error {
+	b := tx.Bucket(iq.db.buckets.IdxQueue)
+	seq, err := b.NextSequence()
+	if err != nil {
+		return err
+	}
+	data, err := json.Marshal(job)
+	if err != nil {
+		return err
+	}
+	if err := b.Put(seqKey(seq), data); err != nil {
+		return err
+	}
+	tx.OnCommit(func() {
+		iq.mu.Lock()
+		iq.pending++
+		iq.enqueued = max(iq.enqueued, seq)
+		iq.mu.Unlock()
+		iq.signal()
+	})
+	return nil
+}
+
+// admit blocks while the queue is full so async writers slow down to the indexing rate
+// instead of growing the backlog without bound
+func (iq *IndexQueue) admit() {
+	iq.mu.Lock()
+	defer iq.mu.Unlock()
+	for iq.pending >= iq.limit && iq.ctx.Err() == nil {
+		iq.room.Wait()
+	}
+}
+
+// signal wakes the worker
+func (iq *IndexQueue) signal() {
+	select {
+	case iq.wake <- struct{}{}:
+	default:
+	}
+}
+
+// worker applies queued jobs until the queue shuts down
+func (iq *IndexQueue) worker() {
+	defer iq.wg.Done()
+	for {
+		select {
+		case <-iq.wake:
+		case <-iq.ctx.Done():
+			return
+		}
+		for iq.ctx.Err() == nil {
+			n, err := iq.apply(0)
+			if err != nil {
+				log.Printf("Index queue: %v (retrying)", err)
+				select {
+				case <-time.After(time.Second):
+				case <-iq.ctx.Done():
+				}
+				continue
+			}
+			if n == 0 {
+				break
+			}
+		}
+	}
+}
+
+// apply applies up to indexDrainBatch queued jobs in one transaction and returns how
+// many were taken off the queue. With upTo > 0 it stops once that sequence is applied.
+func (iq *IndexQueue) apply(upTo uint64) (int, error) {
+	iq.drain.Lock()
+	defer iq.drain.Unlock()
+
+	var last uint64
+	var done, failed int
+	err := iq.db.update(func(tx *bolt.Tx) error {
+		b := tx.Bucket(iq.db.buckets.IdxQueue)
+		var keys [][]byte
+		c := b.Cursor()
+		for k, v := c.First(); k != nil && len(keys) < indexDrainBatch; k, v = c.Next() {
+			seq := binary.BigEndian.Uint64(k)
+			if upTo > 0 && seq > upTo {
+				break
+			}
+			keys = append(keys, CopyBytes(k))
+			last = seq
+
+			var job IndexJob
+			err := json.Unmarshal(v, &job)
+			if err == nil {
+				err = job.replay(tx, iq.db)
+			}
+			if err != nil {
+				// A job that cannot be applied would block the queue forever
+				log.Printf("Index queue: dropping job for doc %s: %v", job.DocID, err)
+				failed++
+			}
+		}
+		for _, k := range keys {
+			if err := b.Delete(k); err != nil {
+				return err
+			}
+		}
+		done = len(keys)
+		return nil
+	})
+	if err != nil || done == 0 {
+		return 0, err
+	}
+
+	iq.mu.Lock()
+	iq.pending -= done
+	iq.applied = max(iq.applied, last)
+	iq.processed += uint64(done - failed)
+	iq.failed += uint64(failed)
+	iq.room.Broadcast()
+	iq.mu.Unlock()
+	return done, nil
+}
+
+// WaitFor returns once every job up to seq is applied. Instead of waiting for the
+// worker it applies outstanding jobs itself, so the caller is never starved.
+func (iq *IndexQueue) WaitFor(seq uint64) error {
+	for {
+		iq.mu.Lock()
+		applied := iq.applied
+		iq.mu.Unlock()
+		if applied >= seq {
+			return nil
+		}
+		n, err := iq.apply(seq)
+		if err != nil {
+			return err
+		}
+		if n == 0 {
+			// Everything up to seq was applied by the worker meanwhile
+			return nil
+		}
+	}
+}
+
+// Watermarks returns the highest committed and the highest applied job sequence
+func (iq *IndexQueue) Watermarks() (enqueued, applied uint64) {
+	iq.mu.Lock()
+	defer iq.mu.Unlock()
+	return iq.enqueued, iq.applied
+}
+
+// replay removes the index entries of OldMeta and indexes the document's current meta.
+// Reading the document instead of trusting the job keeps the index correct when newer
+// writes to the same document were indexed synchronously in the meantime.
+func (job *IndexJob) replay(tx *bolt.Tx, db *DB) error {
+	cur := &IndexJob{Collection: job.Collection, DocID: job.DocID, OldMeta: job.OldMeta}
+	if v := tx.Bucket(db.buckets.Docs).Get(kDoc(job.Collection, job.DocID)); v != nil {
+		d, err := unmarshalDoc(v)
+		if err != nil {
+			return err
+		}
+		cur.NewMeta = d.Meta
+	}
+	return cur.apply(tx.Bucket(db.buckets.IdxMeta))
+}
+
+// apply replaces the index entries of OldMeta with those of NewMeta
+func (job *IndexJob) apply(bIdx *bolt.Bucket) error {
+	// Delete old indices
+	for mk, vals := range job.OldMeta {
+		for _, mv := range vals {
+			key := kMetaKeyPrefix(job.Collection, mk, mv)
+			key = append(key, []byte(job.DocID)...)
+			_ = bIdx.Delete(key)
+		}
+	}
+
+	// Add new indices
+	for mk, vals := range job.NewMeta {
+		for _, mv := range vals {
+			key := kMetaKeyPrefix(job.Collection, mk, mv)
+			key = append(key, []byte(job.DocID)...)
+			if err := bIdx.Put(key, []byte("1")); err != nil {
+				return err
+			}
+		}
+	}
+
+	return nil
+}
+
+// Flush applies every job committed so far
+func (iq *IndexQueue) Flush() error {
+	enqueued, _ := iq.Watermarks()
+	return iq.WaitFor(enqueued)
+}
+
+// Shutdown stops the worker. Jobs still queued stay in the database and are applied on
+// the next start.
+func (iq *IndexQueue) Shutdown() {
+	iq.cancel()
+	iq.mu.Lock()
+	iq.room.Broadcast()
+	iq.mu.Unlock()
+	iq.wg.Wait()
+}
+
+// Stats returns queue statistics
+func (iq *IndexQueue) Stats() (processed, failed uint64, queueLen int) {
+	iq.mu.Lock()
+	defer iq.mu.Unlock()
+	return iq.processed, iq.failed, max(iq.pending, 0)
+}
+
+// seqKey encodes a queue sequence so keys sort in commit order
+func seqKey(seq uint64) []byte {
+	k := make([]byte, 8)
+	binary.BigEndian.PutUint64(k, seq)
+	return k
+}
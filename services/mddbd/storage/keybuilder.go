@@ -0,0 +1,73 @@
+package storage
+
+// KeyBuilder provides efficient key construction without string allocations.
+// Components are escaped exactly like kDoc/kByKey/kRevPrefix/kMetaKeyPrefix (see keys.go);
+// keys longer than the buffer fall back to a heap allocation.
+type KeyBuilder struct {
+	buf [512]byte
+}
+
+// BuildDocKey builds a document key: doc|collection|id
+func (kb *KeyBuilder) BuildDocKey(coll, id string) []byte {
+	b := append(kb.buf[:0], "doc|"...)
+	b = appendKeyPart(b, coll)
+	b = append(b, '|')
+	b = append(b, id...)
+	return b
+}
+
+// BuildByKey builds a bykey index key: bykey|collection|key|lang
+func (kb *KeyBuilder) BuildByKey(coll, key, lang string) []byte {
+	b := append(kb.buf[:0], "bykey|"...)
+	b = appendKeyPart(b, coll)
+	b = append(b, '|')
+	b = appendKeyPart(b, key)
+	b = append(b, '|')
+	b = appendKeyPart(b, lang)
+	return b
+}
+
+// BuildRevPrefix builds a revision key prefix: rev|collection|docID|
+func (kb *KeyBuilder) BuildRevPrefix(coll, id string) []byte {
+	b := append(kb.buf[:0], "rev|"...)
+	b = appendKeyPart(b, coll)
+	b = append(b, '|')
+	b = append(b, id...)
+	b = append(b, '|')
+	return b
+}
+
+// BuildRevKey builds a complete revision key: rev|collection|docID|timestamp
+func (kb *KeyBuilder) BuildRevKey(coll, id string, timestamp int64) []byte {
+	b := kb.BuildRevPrefix(coll, id)
+
+	// Use optimized timestamp formatting
+	var ts [20]byte
+	b = append(b, FormatTimestamp(timestamp, ts[:])...)
+
+	return b
+}
+
+// BuildMetaKeyPrefix builds a metadata key prefix: meta|collection|key|value|
+func (kb *KeyBuilder) BuildMetaKeyPrefix(coll, mk, mv string) []byte {
+	b := append(kb.buf[:0], "meta|"...)
+	b = appendKeyPart(b, coll)
+	b = append(b, '|')
+	b = appendKeyPart(b, mk)
+	b = append(b, '|')
+	b = appendKeyPart(b, mv)
+	b = append(b, '|')
+	return b
+}
+
+// BuildMetaKey builds a complete metadata key: meta|collection|key|value|docID
+func (kb *KeyBuilder) BuildMetaKey(coll, mk, mv, docID string) []byte {
+	b := kb.BuildMetaKeyPrefix(coll, mk, mv)
+	b = append(b, docID...)
+	return b
+}
+
+// Reset clears the buffer (optional, for reuse)
+func (kb *KeyBuilder) Reset() {
+	// Buffer is reused automatically, no need to clear
+}
@@ -0,0 +1,257 @@
+package storage
+
+import (
+	"bytes"
+	"fmt"
+	"log"
+
+	bolt "go.etcd.io/bbolt"
+)
+
+// keyFormatVersion is stored in the system bucket once all keys use escaped components
+const keyFormatVersion = "2"
+
+// keyMigrationBatch is the number of documents examined per write transaction
+const keyMigrationBatch = 256
+
+var keyFormatKey = []byte("keyFormat")
+
+// migrateKeys rewrites keys written before component escaping. It is a no-op for empty
+// databases and for databases that were already migrated.
+//
+// The docs keys to rewrite are first recorded in the key migration bucket, which then
+// shrinks as batches are migrated. That makes the migration resumable after a crash
+// without having to tell legacy keys from escaped ones: the bucket names every key
+// still in the old format, whatever it looks like.
+func (db *DB) migrateKeys() error {
+	var done, resumed bool
+	err := db.update(func(tx *bolt.Tx) error {
+		bSys := tx.Bucket(db.buckets.System)
+		if string(bSys.Get(keyFormatKey)) == keyFormatVersion {
+			done = true
+			return nil
+		}
+		if tx.Bucket(db.buckets.KeyMigration) != nil {
+			resumed = true
+			return nil
+		}
+		c := tx.Bucket(db.buckets.Docs).Cursor()
+		k, _ := c.First()
+		if k == nil {
+			done = true
+			return db.finishKeyMigration(tx)
+		}
+		bPending, err := tx.CreateBucket(db.buckets.KeyMigration)
+		if err != nil {
+			return err
+		}
+		for ; k != nil; k, _ = c.Next() {
+			if err := bPending.Put(k, []byte("1")); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil || done {
+		return err
+	}
+
+	if resumed {
+		log.Println("Resuming storage key migration...")
+	} else {
+		log.Println("Migrating storage keys to escaped format...")
+	}
+	var migrated int
+	for !done {
+		err := db.update(func(tx *bolt.Tx) error {
+			bPending := tx.Bucket(db.buckets.KeyMigration)
+			bDocs := tx.Bucket(db.buckets.Docs)
+
+			// Collect first - deleting from a bucket while a cursor walks it is unsafe
+			var batch [][]byte
+			c := bPending.Cursor()
+			for k, _ := c.First(); k != nil && len(batch) < keyMigrationBatch; k, _ = c.Next() {
+				batch = append(batch, CopyBytes(k))
+			}
+			if len(batch) == 0 {
+				done = true
+				return db.finishKeyMigration(tx)
+			}
+
+			for _, k := range batch {
+				// Gone if it was deleted or rewritten since it was recorded
+				if v := bDocs.Get(k); v != nil {
+					ok, err := db.migrateDocKeys(tx, k, CopyBytes(v))
+					if err != nil {
+						return fmt.Errorf("%s: %w", k, err)
+					}
+					if ok {
+						migrated++
+					}
+				}
+				if err := bPending.Delete(k); err != nil {
+					return err
+				}
+			}
+			return nil
+		})
+		if err != nil {
+			log.Printf("Key migration failed after %d documents: %v", migrated, err)
+			return err
+		}
+	}
+
+	if migrated > 0 {
+		db.cache.Clear()
+	}
+	log.Printf("Key migration complete (%d documents rewritten)", migrated)
+	return nil
+}
+
+// finishKeyMigration records that every key uses the escaped format
+func (db *DB) finishKeyMigration(tx *bolt.Tx) error {
+	if tx.Bucket(db.buckets.KeyMigration) != nil {
+		if err := tx.DeleteBucket(db.buckets.KeyMigration); err != nil {
+			return err
+		}
+	}
+	return tx.Bucket(db.buckets.System).Put(keyFormatKey, []byte(keyFormatVersion))
+}
+
+// isLegacyDocKey reports whether a docs key still uses the pre-escaping format
+func (db *DB) isLegacyDocKey(tx *bolt.Tx, k []byte) bool {
+	if string(tx.Bucket(db.buckets.System).Get(keyFormatKey)) == keyFormatVersion {
+		return false
+	}
+	if b := tx.Bucket(db.buckets.KeyMigration); b != nil {
+		return b.Get(k) != nil
+	}
+	return true // not started yet
+}
+
+// migrateDocKeys rewrites the doc, bykey, meta and revision keys of one stored document.
+// It reports whether anything was changed.
+func (db *DB) migrateDocKeys(tx *bolt.Tx, k, v []byte) (bool, error) {
+	doc, isJSON, err := decodeStoredDoc(v)
+	if err != nil {
+		return false, err
+	}
+	rawColl, ok := legacyDocCollection(k, doc)
+	if !ok {
+		return false, nil
+	}
+
+	coll := rawColl
+	oldID := doc.ID
+	newID := genID(coll, doc.Key, doc.Lang)
+	moved := newID != oldID || !bytes.Equal(kDoc(coll, newID), k)
+	if !moved && !metaNeedsEscape(doc.Meta) {
+		return false, nil
+	}
+
+	bDocs := tx.Bucket(db.buckets.Docs)
+	bIdx := tx.Bucket(db.buckets.IdxMeta)
+	bRev := tx.Bucket(db.buckets.Rev)
+	bByK := tx.Bucket(db.buckets.ByKey)
+
+	// A write that already landed under the new key wins over the legacy copy
+	superseded := moved && bDocs.Get(kDoc(coll, newID)) != nil
+
+	// Legacy keys joined raw components with '|'
+	rawByKey := []byte("bykey|" + rawColl + "|" + doc.Key + "|" + doc.Lang)
+	if string(bByK.Get(rawByKey)) == oldID {
+		if err := bByK.Delete(rawByKey); err != nil {
+			return false, err
+		}
+	}
+	for mk, vals := range doc.Meta {
+		for _, mv := range vals {
+			if err := bIdx.Delete([]byte("meta|" + rawColl + "|" + mk + "|" + mv + "|" + oldID)); err != nil {
+				return false, err
+			}
+		}
+	}
+	if moved {
+		if err := bDocs.Delete(k); err != nil {
+			return false, err
+		}
+	}
+
+	if !superseded {
+		if moved {
+			doc.ID = newID
+			buf, err := encodeStoredDoc(doc, isJSON, db.Collections.Compression(coll))
+			if err != nil {
+				return false, err
+			}
+			if err := bDocs.Put(kDoc(coll, newID), buf); err != nil {
+				return false, err
+			}
+		}
+		if err := bByK.Put(kByKey(coll, doc.Key, doc.Lang), []byte(newID)); err != nil {
+			return false, err
+		}
+		for mk, vals := range doc.Meta {
+			for _, mv := range vals {
+				if err := bIdx.Put(append(kMetaKeyPrefix(coll, mk, mv), newID...), []byte("1")); err != nil {
+					return false, err
+				}
+			}
+		}
+	}
+
+	if moved {
+		type entry struct{ k, v []byte }
+		var revs []entry
+		rp := []byte("rev|" + rawColl + "|" + oldID + "|")
+		rc := bRev.Cursor()
+		for rk, rv := rc.Seek(rp); rk != nil && bytes.HasPrefix(rk, rp); rk, rv = rc.Next() {
+			revs = append(revs, entry{CopyBytes(rk), CopyBytes(rv)})
+		}
+		for _, r := range revs {
+			rdoc, rIsJSON, err := decodeStoredDoc(r.v)
+			if err != nil {
+				return false, fmt.Errorf("%s: %w", r.k, err)
+			}
+			rdoc.ID = newID
+			rbuf, err := encodeStoredDoc(rdoc, rIsJSON, db.Collections.Compression(coll))
+			if err != nil {
+				return false, err
+			}
+			if err := bRev.Delete(r.k); err != nil {
+				return false, err
+			}
+			if err := bRev.Put(append(kRevPrefix(coll, newID), r.k[len(rp):]...), rbuf); err != nil {
+				return false, err
+			}
+		}
+		if err := db.Collections.Ensure(tx, coll); err != nil {
+			return false, err
+		}
+	}
+	return true, nil
+}
+
+// metaNeedsEscape reports whether any meta key or value changes under the escaped key format
+func metaNeedsEscape(meta map[string][]string) bool {
+	for mk, vals := range meta {
+		if keyPartNeedsEscape(mk) {
+			return true
+		}
+		for _, mv := range vals {
+			if keyPartNeedsEscape(mv) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// legacyDocCollection returns the raw collection of a docs key written before escaping.
+// Both the collection and the ID may contain '|', so the ID is taken from the document.
+func legacyDocCollection(k []byte, doc *Doc) (string, bool) {
+	if !bytes.HasPrefix(k, []byte("doc|")) || !bytes.HasSuffix(k, []byte("|"+doc.ID)) || len(k) < len(doc.ID)+5 {
+		return "", false
+	}
+	return string(k[len("doc|") : len(k)-len(doc.ID)-1]), true
+}
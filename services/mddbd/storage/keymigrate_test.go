@@ -0,0 +1,156 @@
+package storage
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+
+	bolt "go.etcd.io/bbolt"
+)
+
+// writeLegacyDoc stores a document the way mddb did before key components were escaped
+func writeLegacyDoc(t *testing.T, tx *bolt.Tx, coll, key, lang string, meta map[string][]string) {
+	t.Helper()
+	id := strings.ToLower(coll + "|" + key + "|" + lang)
+	buf, err := marshalDoc(&Doc{ID: id, Key: key, Lang: lang, Meta: meta, ContentMD: "# " + key})
+	if err != nil {
+		t.Fatal(err)
+	}
+	puts := []struct{ bucket, k, v string }{
+		{"docs", "doc|" + coll + "|" + id, string(buf)},
+		{"bykey", "bykey|" + coll + "|" + key + "|" + lang, id},
+	}
+	for mk, vals := range meta {
+		for _, mv := range vals {
+			puts = append(puts, struct{ bucket, k, v string }{"idxmeta", "meta|" + coll + "|" + mk + "|" + mv + "|" + id, "1"})
+		}
+	}
+	for _, p := range puts {
+		if err := tx.Bucket([]byte(p.bucket)).Put([]byte(p.k), []byte(p.v)); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestKeyMigration(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "legacy.db")
+	db, err := Open(path, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := db.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	// A collection name containing a valid escape must not be taken for an escaped key
+	bdb, err := bolt.Open(path, 0600, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = bdb.Update(func(tx *bolt.Tx) error {
+		writeLegacyDoc(t, tx, "a%7cb", "k", "en", nil)
+		writeLegacyDoc(t, tx, "x|y", "k|1", "en", map[string][]string{"tag": {"p|q"}})
+		writeLegacyDoc(t, tx, "plain", "k", "en", map[string][]string{"tag": {"p"}})
+		return tx.Bucket([]byte("system")).Delete(keyFormatKey)
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := bdb.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	db, err = Open(path, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	for _, c := range []struct{ coll, key string }{{"a%7cb", "k"}, {"x|y", "k|1"}, {"plain", "k"}} {
+		doc, err := db.Get(c.coll, c.key, "en")
+		if err != nil {
+			t.Fatalf("Get(%q, %q): %v", c.coll, c.key, err)
+		}
+		if want := genID(c.coll, c.key, "en"); doc.ID != want {
+			t.Errorf("%s/%s: ID %q, want %q", c.coll, c.key, doc.ID, want)
+		}
+	}
+
+	var names []string
+	for _, ci := range db.Collections.List() {
+		names = append(names, ci.Name)
+	}
+	if want := []string{"a%7cb", "plain", "x|y"}; strings.Join(names, ",") != strings.Join(want, ",") {
+		t.Errorf("collections %q, want %q", names, want)
+	}
+
+	err = db.view(func(tx *bolt.Tx) error {
+		if v := tx.Bucket(db.buckets.System).Get(keyFormatKey); string(v) != keyFormatVersion {
+			t.Errorf("key format %q, want %q", v, keyFormatVersion)
+		}
+		if tx.Bucket(db.buckets.KeyMigration) != nil {
+			t.Error("key migration bucket left behind")
+		}
+		for _, k := range []string{"meta|x%7cy|tag|p%7cq|" + genID("x|y", "k|1", "en"), "meta|plain|tag|p|plain|k|en"} {
+			if tx.Bucket(db.buckets.IdxMeta).Get([]byte(k)) == nil {
+				t.Errorf("missing meta index entry %s", k)
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+}
+
+func TestKeyMigrationResumes(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "legacy.db")
+	db, err := Open(path, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := db.Add(AddRequest{Collection: "a|b", Key: "new", Lang: "en", ContentMD: "x"}); err != nil {
+		t.Fatal(err)
+	}
+	if err := db.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	// Interrupted: only the legacy document is still listed as pending. The escaped key of
+	// the document written before the crash looks exactly like a legacy key of a%7cb.
+	bdb, err := bolt.Open(path, 0600, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = bdb.Update(func(tx *bolt.Tx) error {
+		writeLegacyDoc(t, tx, "a|b", "old", "en", nil)
+		pending, err := tx.CreateBucket([]byte("keymigration"))
+		if err != nil {
+			return err
+		}
+		if err := pending.Put([]byte("doc|a|b|a|b|old|en"), []byte("1")); err != nil {
+			return err
+		}
+		return tx.Bucket([]byte("system")).Delete(keyFormatKey)
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := bdb.Close(); err != nil {
+		t.Fatal(err)
+	}
+
+	db, err = Open(path, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+	for _, key := range []string{"new", "old"} {
+		if _, err := db.Get("a|b", key, "en"); err != nil {
+			t.Errorf("Get(a|b, %s): %v", key, err)
+		}
+	}
+	if _, err := db.Get("a%7cb", "new", "en"); err == nil {
+		t.Error("migrated document turned up in collection a%7cb")
+	}
+}
@@ -0,0 +1,148 @@
+package storage
+
+// Storage keys are '|'-separated tuples:
+//
+//	doc|coll|docID  bykey|coll|key|lang  rev|coll|docID|ts  meta|coll|mk|mv|docID
+//
+// Every user-supplied component is escaped ('%' -> "%25", '|' -> "%7c") so it can
+// never contain the separator and prefix scans cannot bleed into another collection
+// or index value. Components without '|' or '%' encode to themselves, so keys written
+// before escaping was introduced only change when they contain one of those bytes.
+// Document IDs are built from escaped components (see genID) and are used verbatim.
+
+const hexDigits = "0123456789abcdef"
+
+// keyPartNeedsEscape reports whether a key component contains a byte that must be escaped
+func keyPartNeedsEscape(s string) bool {
+	for i := 0; i < len(s); i++ {
+		if s[i] == '|' || s[i] == '%' {
+			return true
+		}
+	}
+	return false
+}
+
+// appendKeyPart appends an escaped key component to dst
+func appendKeyPart(dst []byte, s string) []byte {
+	if !keyPartNeedsEscape(s) {
+		return append(dst, s...)
+	}
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if c == '|' || c == '%' {
+			dst = append(dst, '%', hexDigits[c>>4], hexDigits[c&0x0f])
+			continue
+		}
+		dst = append(dst, c)
+	}
+	return dst
+}
+
+// escapeKeyPart returns the escaped form of a key component
+func escapeKeyPart(s string) string {
+	if !keyPartNeedsEscape(s) {
+		return s
+	}
+	return string(appendKeyPart(make([]byte, 0, len(s)+8), s))
+}
+
+// unescapeKeyPart decodes an escaped key component; malformed escapes are kept as-is
+func unescapeKeyPart(b []byte) string {
+	i := BytesIndexByte(b, '%')
+	if i < 0 {
+		return string(b)
+	}
+	out := make([]byte, 0, len(b))
+	out = append(out, b[:i]...)
+	for ; i < len(b); i++ {
+		if b[i] == '%' && i+2 < len(b) {
+			hi, lo := unhex(b[i+1]), unhex(b[i+2])
+			if hi >= 0 && lo >= 0 {
+				out = append(out, byte(hi<<4|lo))
+				i += 2
+				continue
+			}
+		}
+		out = append(out, b[i])
+	}
+	return string(out)
+}
+
+func unhex(c byte) int {
+	switch {
+	case c >= '0' && c <= '9':
+		return int(c - '0')
+	case c >= 'a' && c <= 'f':
+		return int(c-'a') + 10
+	case c >= 'A' && c <= 'F':
+		return int(c-'A') + 10
+	}
+	return -1
+}
+
+// joinKeyParts escapes and joins components with '|'
+func joinKeyParts(parts ...string) string {
+	n := len(parts)
+	for _, p := range parts {
+		n += len(p)
+	}
+	buf := make([]byte, 0, n)
+	for i, p := range parts {
+		if i > 0 {
+			buf = append(buf, '|')
+		}
+		buf = appendKeyPart(buf, p)
+	}
+	return string(buf)
+}
+
+// kCollPrefix builds the prefix shared by all keys of a collection in one bucket: kind|coll|
+func kCollPrefix(kind, coll string) []byte {
+	b := make([]byte, 0, len(kind)+len(coll)+2)
+	b = append(b, kind...)
+	b = append(b, '|')
+	b = appendKeyPart(b, coll)
+	return append(b, '|')
+}
+
+// keyCollection returns the (unescaped) collection of a storage key
+func keyCollection(k []byte) string {
+	return unescapeKeyPart(ExtractPart(k, 1))
+}
+
+func kDoc(coll, id string) []byte { return append(kCollPrefix("doc", coll), id...) }
+func kByKey(coll, key, lang string) []byte {
+	b := appendKeyPart(kCollPrefix("bykey", coll), key)
+	b = append(b, '|')
+	return appendKeyPart(b, lang)
+}
+func kRevPrefix(coll, id string) []byte { return append(append(kCollPrefix("rev", coll), id...), '|') }
+func kMetaKeyPrefix(coll, mk, mv string) []byte {
+	b := appendKeyPart(kCollPrefix("meta", coll), mk)
+	b = append(b, '|')
+	b = appendKeyPart(b, mv)
+	return append(b, '|')
+}
+
+// genID builds the deterministic document ID of collection|key|lang
+func genID(parts ...string) string {
+	// Optimized ID generation: escaped parts joined with '|', lowercased in place
+	totalLen := 0
+	for i, part := range parts {
+		totalLen += len(part)
+		if i < len(parts)-1 {
+			totalLen++ // for '|'
+		}
+	}
+
+	buf := make([]byte, 0, totalLen)
+	for i, part := range parts {
+		buf = appendKeyPart(buf, part)
+		if i < len(parts)-1 {
+			buf = append(buf, '|')
+		}
+	}
+	BytesToLower(buf)
+
+	return string(buf)
+}
@@ -0,0 +1,40 @@
+package storage
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+func FuzzKeyEscaping(f *testing.F) {
+	for _, seed := range [][2]string{
+		{"docs", "home"},
+		{"a|b", "k"},
+		{"a%7cb", "a|b"},
+		{"100%", "%"},
+		{"|", "%7C"},
+		{"a%", "a%2"},
+		{"", "|%|%%"},
+	} {
+		f.Add(seed[0], seed[1])
+	}
+	f.Fuzz(func(t *testing.T, coll, key string) {
+		for _, s := range []string{coll, key} {
+			esc := escapeKeyPart(s)
+			if strings.Contains(esc, "|") {
+				t.Fatalf("escapeKeyPart(%q) = %q contains a separator", s, esc)
+			}
+			if got := unescapeKeyPart([]byte(esc)); got != s {
+				t.Fatalf("unescapeKeyPart(escapeKeyPart(%q)) = %q", s, got)
+			}
+		}
+
+		k := kDoc(coll, genID(coll, key, "en"))
+		if got := keyCollection(k); got != coll {
+			t.Fatalf("keyCollection(kDoc(%q)) = %q", coll, got)
+		}
+		if coll != key && bytes.HasPrefix(k, kCollPrefix("doc", key)) {
+			t.Fatalf("kDoc(%q) falls under the prefix of collection %q", coll, key)
+		}
+	})
+}
@@ -1,4 +1,4 @@
-package main
+package storage
 
 // metadataEqual checks if two metadata maps are equal
 func metadataEqual(a, b map[string][]string) bool {
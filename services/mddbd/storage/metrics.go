@@ -0,0 +1,76 @@
+package storage
+
+import (
+	"os"
+
+	bolt "go.etcd.io/bbolt"
+)
+
+// IndexQueueStats is a snapshot of the async metadata index queue
+type IndexQueueStats struct {
+	Processed uint64
+	Failed    uint64
+	Pending   int
+}
+
+// WALStats is a snapshot of the write-ahead log
+type WALStats struct {
+	Entries uint64
+	Size    int64
+}
+
+// MVCCStats is a snapshot of the version store
+type MVCCStats struct {
+	Keys     int
+	Versions int
+}
+
+// SubsystemStats collects the counters of every storage subsystem. It is cheap to take
+// and meant for periodic scraping; pointer fields are nil when the subsystem is off.
+type SubsystemStats struct {
+	FileSize      int64
+	Bolt          bolt.Stats
+	Cache         CacheStats
+	IndexQueue    IndexQueueStats
+	WAL           *WALStats
+	ChangeLog     *ChangeLogStats
+	MVCC          *MVCCStats
+	Bloom         map[string]BloomStats
+	AdaptiveIndex AdaptiveIndexStats
+	Shards        ShardClusterStats
+	SIMD          SIMDStats
+	ZeroCopy      ZeroCopyStats
+	AsyncIO       AsyncIOStats
+	Compaction    CompactionStats
+}
+
+// SubsystemStats returns a snapshot of the subsystem counters
+func (db *DB) SubsystemStats() SubsystemStats {
+	s := SubsystemStats{
+		Bolt:          db.Bolt().Stats(),
+		Bloom:         db.bloomFilters.Stats(),
+		AdaptiveIndex: db.adaptiveIndex.Stats(),
+		Shards:        db.shardCluster.Stats(),
+		SIMD:          db.simd.Stats(),
+		ZeroCopy:      db.zeroCopy.Stats(),
+		AsyncIO:       db.asyncIO.Stats(),
+		Compaction:    db.compactor.stats(),
+	}
+	if info, err := os.Stat(db.path); err == nil {
+		s.FileSize = info.Size()
+	}
+	s.Cache = db.cache.Stats()
+	s.IndexQueue.Processed, s.IndexQueue.Failed, s.IndexQueue.Pending = db.indexQueue.Stats()
+	if db.wal != nil {
+		s.WAL = &WALStats{}
+		s.WAL.Entries, s.WAL.Size = db.wal.Stats()
+	}
+	if db.mvcc != nil {
+		s.MVCC = &MVCCStats{}
+		s.MVCC.Keys, s.MVCC.Versions = db.mvcc.Stats()
+	}
+	if db.changeLog != nil {
+		s.ChangeLog = db.changeLog.stats()
+	}
+	return s
+}
@@ -0,0 +1,200 @@
+package storage
+
+import (
+	"errors"
+	"fmt"
+	"slices"
+	"time"
+
+	bolt "go.etcd.io/bbolt"
+)
+
+// ErrInvalidPatch is returned for malformed patch operations
+var ErrInvalidPatch = errors.New("invalid patch")
+
+// Meta patch operations
+const (
+	PatchSet    = "set"    // replace the values of a key (no values = unset)
+	PatchUnset  = "unset"  // remove a key
+	PatchAdd    = "add"    // add values that are not present yet
+	PatchRemove = "remove" // remove values; the key goes away with its last value
+)
+
+// MetaPatch is one operation on a meta key
+type MetaPatch struct {
+	Op     string   `json:"op"`
+	Key    string   `json:"key"`
+	Values []string `json:"values,omitempty"`
+}
+
+// PatchRequest changes parts of an existing document. Meta operations run in order;
+// ContentMD (if set) replaces the content before AppendMD is appended.
+type PatchRequest struct {
+	Collection   string      `json:"collection"`
+	Key          string      `json:"key"`
+	Lang         string      `json:"lang"` // empty = the collection's default language
+	Meta         []MetaPatch `json:"meta"`
+	ContentMD    *string     `json:"contentMd"`
+	AppendMD     string      `json:"appendMd"`
+	SaveRevision bool        `json:"saveRevision"` // subject to the collection's revision policy
+}
+
+// validate checks the operations without touching a document
+func (req *PatchRequest) validate() error {
+	for i, p := range req.Meta {
+		if p.Key == "" {
+			return fmt.Errorf("%w: meta[%d]: missing key", ErrInvalidPatch, i)
+		}
+		switch p.Op {
+		case PatchSet, PatchUnset:
+		case PatchAdd, PatchRemove:
+			if len(p.Values) == 0 {
+				return fmt.Errorf("%w: meta[%d]: %s needs values", ErrInvalidPatch, i, p.Op)
+			}
+		default:
+			return fmt.Errorf("%w: meta[%d]: unknown op %q", ErrInvalidPatch, i, p.Op)
+		}
+	}
+	return nil
+}
+
+// applyMeta returns a copy of meta with the operations applied
+func applyMeta(meta map[string][]string, ops []MetaPatch) map[string][]string {
+	out := make(map[string][]string, len(meta))
+	for k, v := range meta {
+		out[k] = slices.Clone(v)
+	}
+	for _, p := range ops {
+		switch p.Op {
+		case PatchSet:
+			if len(p.Values) == 0 {
+				delete(out, p.Key)
+			} else {
+				out[p.Key] = unique(slices.Clone(p.Values))
+			}
+		case PatchUnset:
+			delete(out, p.Key)
+		case PatchAdd:
+			for _, v := range p.Values {
+				if !slices.Contains(out[p.Key], v) {
+					out[p.Key] = append(out[p.Key], v)
+				}
+			}
+		case PatchRemove:
+			vals := slices.DeleteFunc(out[p.Key], func(v string) bool { return slices.Contains(p.Values, v) })
+			if len(vals) == 0 {
+				delete(out, p.Key)
+			} else {
+				out[p.Key] = vals
+			}
+		}
+	}
+	return out
+}
+
+// metaDiff returns the index entries to drop and to add when meta changes from old to new
+func metaDiff(old, new map[string][]string) (removed, added map[string][]string) {
+	removed = make(map[string][]string)
+	added = make(map[string][]string)
+	for k, vals := range old {
+		for _, v := range vals {
+			if !slices.Contains(new[k], v) {
+				removed[k] = append(removed[k], v)
+			}
+		}
+	}
+	for k, vals := range new {
+		for _, v := range vals {
+			if !slices.Contains(old[k], v) {
+				added[k] = append(added[k], v)
+			}
+		}
+	}
+	return removed, added
+}
+
+// Patch applies meta operations and content changes to an existing document in one write
+// transaction. Only the index entries of changed meta values are touched. check works as in Put.
+func (db *DB) Patch(req PatchRequest, check func(existing *Doc) error) (*Doc, error) {
+	req.Lang = db.Collections.DefaultLang(req.Collection, req.Lang)
+	if req.Collection == "" || req.Key == "" || req.Lang == "" {
+		return nil, ErrMissingFields
+	}
+	if err := req.validate(); err != nil {
+		return nil, err
+	}
+
+	now := time.Now().Unix()
+	var saved Doc
+	err := db.update(func(tx *bolt.Tx) error {
+		var err error
+		saved, err = db.patchTx(tx, req, check, now)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &saved, nil
+}
+
+// patchTx applies req inside tx; req must have its language resolved and be validated
+func (db *DB) patchTx(tx *bolt.Tx, req PatchRequest, check func(existing *Doc) error, now int64) (Doc, error) {
+	docID := genID(req.Collection, req.Key, req.Lang)
+	compression := db.Collections.Compression(req.Collection)
+
+	var kb KeyBuilder
+	bDocs := tx.Bucket(db.buckets.Docs)
+
+	v := bDocs.Get(kb.BuildDocKey(req.Collection, docID))
+	if v == nil {
+		if check != nil {
+			if err := check(nil); err != nil {
+				return Doc{}, err
+			}
+		}
+		return Doc{}, ErrNotFound
+	}
+	existing, err := unmarshalDoc(v)
+	if err != nil {
+		return Doc{}, err
+	}
+	if check != nil {
+		if err := check(existing); err != nil {
+			return Doc{}, err
+		}
+	}
+
+	doc := *existing
+	doc.Meta = applyMeta(existing.Meta, req.Meta)
+	if req.ContentMD != nil {
+		doc.ContentMD = *req.ContentMD
+	}
+	doc.ContentMD += req.AppendMD
+	doc.UpdatedAt = now
+	if err := db.Schemas.Validate(req.Collection, req.Key, req.Lang, doc.Meta, doc.ContentMD); err != nil {
+		return Doc{}, err
+	}
+
+	buf, err := marshalDocWith(&doc, compression)
+	if err != nil {
+		return Doc{}, err
+	}
+	if err := bDocs.Put(kb.BuildDocKey(req.Collection, docID), buf); err != nil {
+		return Doc{}, err
+	}
+	db.invalidateTx(tx, req.Collection, req.Key, req.Lang)
+
+	removed, added := metaDiff(existing.Meta, doc.Meta)
+	job := &IndexJob{Collection: req.Collection, DocID: docID, OldMeta: removed, NewMeta: added}
+	if err := job.apply(tx.Bucket(db.buckets.IdxMeta)); err != nil {
+		return Doc{}, err
+	}
+
+	if db.Collections.SaveRevision(req.Collection, req.SaveRevision) {
+		if err := db.putRevision(tx, req.Collection, docID, now, buf); err != nil {
+			return Doc{}, err
+		}
+	}
+
+	return doc, nil
+}
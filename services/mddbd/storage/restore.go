@@ -0,0 +1,291 @@
+package storage
+
+import (
+	"bufio"
+	"bytes"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/klauspost/compress/zstd"
+	bolt "go.etcd.io/bbolt"
+)
+
+var (
+	// ErrInvalidBackup is returned when a backup fails verification; the database is untouched
+	ErrInvalidBackup = errors.New("invalid backup")
+	// ErrNoPreviousFile is returned by Rollback when no restore has kept a previous file
+	ErrNoPreviousFile = errors.New("no previous database file to roll back to")
+)
+
+// previousSuffix names the file a restore keeps the replaced database in
+const previousSuffix = ".pre-restore"
+
+// zstdMagic starts every zstd frame; a BoltDB file starts with a page header instead
+var zstdMagic = []byte{0x28, 0xb5, 0x2f, 0xfd}
+
+// RestoreOptions configures a restore
+type RestoreOptions struct {
+	SHA256 string // expected hex SHA-256 of the backup as read, e.g. from its manifest; checked when set
+
+	// Quiesce is called once the backup is verified, right before the file is swapped;
+	// resume is called when the restore is done. An error aborts the restore.
+	Quiesce func() (resume func(), err error)
+}
+
+// RestoreResult describes a completed restore
+type RestoreResult struct {
+	Compression string `json:"compression"` // of the backup as read
+	SHA256      string `json:"sha256"`      // hex digest of the backup as read
+	Size        int64  `json:"size"`        // bytes of the restored database file
+	TxID        int    `json:"txId"`        // last transaction in the restored file
+	Previous    string `json:"previous"`    // the replaced file, kept for Rollback
+	Replayed    int    `json:"replayed"`    // transactions replayed from the change log (RestoreAt)
+	DurationMs  int64  `json:"durationMs"`
+}
+
+// Restore replaces the database with the backup file at from (see RestoreFrom)
+func (db *DB) Restore(from string) error {
+	f, err := os.Open(from)
+	if err != nil {
+		return err
+	}
+	defer func() { _ = f.Close() }()
+	_, err = db.RestoreFrom(f, RestoreOptions{})
+	return err
+}
+
+// RestoreFrom replaces the database with the backup read from r, plain or zstd-compressed.
+// The backup is staged next to the database file and verified with BoltDB's consistency
+// check before anything changes. Writes then wait while the file is swapped atomically;
+// the replaced file is kept and can be swapped back with Rollback. If the restored file
+// cannot be opened, the previous one is put back.
+func (db *DB) RestoreFrom(r io.Reader, opts RestoreOptions) (*RestoreResult, error) {
+	start := time.Now()
+	staged, res, err := db.stageBackup(r, opts.SHA256)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = os.Remove(staged) }()
+	return db.swapStaged(staged, res, opts, start)
+}
+
+// RestoreAt restores the database as it was at the given time: the backup read from r
+// is staged and verified like in RestoreFrom, rolled forward by replaying the change log
+// up to at, and swapped in. It fails with ErrBackupTooNew if the backup already holds
+// changes made after at, and with ErrNoRecoveryPoint if the change log does not cover
+// the time from the backup to at.
+func (db *DB) RestoreAt(r io.Reader, at time.Time, opts RestoreOptions) (*RestoreResult, error) {
+	if db.changeLog == nil {
+		return nil, fmt.Errorf("%w: the change log is disabled", ErrNoRecoveryPoint)
+	}
+	start := time.Now()
+	staged, res, err := db.stageBackup(r, opts.SHA256)
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = os.Remove(staged) }()
+
+	replayOpts := Options{Timeout: db.opts.Timeout, CacheBytes: -1}
+	if res.Replayed, err = db.changeLog.replay(staged, at, replayOpts); err != nil {
+		return nil, err
+	}
+	if res.TxID, err = db.verifyBackup(staged); err != nil {
+		return nil, err
+	}
+	return db.swapStaged(staged, res, opts, start)
+}
+
+// swapStaged swaps a verified staged file in for the database, keeping the current one
+func (db *DB) swapStaged(staged string, res *RestoreResult, opts RestoreOptions, start time.Time) (*RestoreResult, error) {
+	if opts.Quiesce != nil {
+		resume, err := opts.Quiesce()
+		if err != nil {
+			return nil, err
+		}
+		defer resume()
+	}
+
+	prev := db.path + previousSuffix
+	if err := db.swapIn(staged, prev); err != nil {
+		return nil, err
+	}
+	if err := db.reload(); err != nil {
+		// The file verified but mddb can't use it: put the previous one back
+		log.Printf("Restore failed, rolling back: %v", err)
+		if rbErr := db.swapIn(prev, ""); rbErr != nil {
+			return nil, errors.Join(err, rbErr)
+		}
+		return nil, errors.Join(err, db.reload())
+	}
+
+	res.Size = fileSize(db.path)
+	res.Previous = prev
+	res.DurationMs = time.Since(start).Milliseconds()
+	return res, nil
+}
+
+// Rollback swaps the file replaced by the last restore back in. The current file becomes
+// the previous one, so a rollback can itself be rolled back.
+func (db *DB) Rollback(opts RestoreOptions) (*RestoreResult, error) {
+	f, err := os.Open(db.path + previousSuffix)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, ErrNoPreviousFile
+	}
+	if err != nil {
+		return nil, err
+	}
+	defer func() { _ = f.Close() }()
+	return db.RestoreFrom(f, opts)
+}
+
+// stageBackup copies r, decompressed, into a temporary file next to the database and
+// verifies it. The caller removes the file.
+func (db *DB) stageBackup(r io.Reader, wantSHA256 string) (string, *RestoreResult, error) {
+	res := &RestoreResult{Compression: BackupCompressionNone}
+	hash := sha256.New()
+	br := bufio.NewReader(io.TeeReader(r, hash))
+	var src io.Reader = br
+	if magic, _ := br.Peek(len(zstdMagic)); bytes.Equal(magic, zstdMagic) {
+		dec, err := zstd.NewReader(br)
+		if err != nil {
+			return "", nil, err
+		}
+		defer dec.Close()
+		src, res.Compression = invalidOnError{dec, ErrInvalidBackup}, BackupCompressionZstd
+	}
+
+	f, err := os.CreateTemp(filepath.Dir(db.path), filepath.Base(db.path)+".restore-*")
+	if err != nil {
+		return "", nil, err
+	}
+	staged := f.Name()
+	n, err := io.Copy(f, src)
+	if err == nil && n == 0 {
+		err = fmt.Errorf("%w: empty", ErrInvalidBackup)
+	}
+	if err == nil {
+		// Hash what the sender sent, including anything after the zstd frame
+		_, err = io.Copy(io.Discard, br)
+	}
+	if err == nil {
+		err = f.Sync()
+	}
+	if err = errors.Join(err, f.Close()); err != nil {
+		_ = os.Remove(staged)
+		return "", nil, err
+	}
+
+	res.SHA256 = hex.EncodeToString(hash.Sum(nil))
+	if wantSHA256 != "" && res.SHA256 != wantSHA256 {
+		_ = os.Remove(staged)
+		return "", nil, fmt.Errorf("%w: sha256 %s, expected %s", ErrInvalidBackup, res.SHA256, wantSHA256)
+	}
+	if res.TxID, err = db.verifyBackup(staged); err != nil {
+		_ = os.Remove(staged)
+		return "", nil, err
+	}
+	return staged, res, nil
+}
+
+// verifyBackup opens a staged backup read-only and runs BoltDB's consistency check on it
+func (db *DB) verifyBackup(path string) (int, error) {
+	bdb, err := bolt.Open(path, 0600, &bolt.Options{ReadOnly: true, Timeout: db.opts.Timeout})
+	if err != nil {
+		return 0, fmt.Errorf("%w: %v", ErrInvalidBackup, err)
+	}
+	defer func() { _ = bdb.Close() }()
+
+	var txID int
+	err = bdb.View(func(tx *bolt.Tx) error {
+		txID = tx.ID()
+		if size := fileSize(path); size < tx.Size() {
+			return fmt.Errorf("%w: truncated to %d of %d bytes", ErrInvalidBackup, size, tx.Size())
+		}
+		if tx.Bucket(db.buckets.Docs) == nil {
+			return fmt.Errorf("%w: not an mddb database (no %s bucket)", ErrInvalidBackup, db.buckets.Docs)
+		}
+		var problems []error
+		for err := range tx.Check() {
+			problems = append(problems, err)
+		}
+		if len(problems) > 0 {
+			return fmt.Errorf("%w: consistency check failed: %v", ErrInvalidBackup, errors.Join(problems...))
+		}
+		return nil
+	})
+	return txID, err
+}
+
+// swapIn renames file over the database file while writes and reads wait, first moving
+// the current file to keep (unless keep is empty). Caches are cleared during the swap.
+// With the change log on, the new file starts a timeline of its own.
+func (db *DB) swapIn(file, keep string) error {
+	db.writeMu.Lock()
+	defer db.writeMu.Unlock()
+	if db.changeLog == nil {
+		return db.swapFile(0, db.replaceWith(file, keep))
+	}
+	prev, err := db.changeLogState()
+	if err != nil {
+		return err
+	}
+	if err := db.swapFile(0, db.replaceWith(file, keep)); err != nil {
+		return err
+	}
+	return db.startTimeline(prev, false, time.Now().UnixNano())
+}
+
+// replaceWith returns the swapFile step of swapIn
+func (db *DB) replaceWith(file, keep string) func() error {
+	return func() error {
+		if keep != "" {
+			if err := os.Rename(db.path, keep); err != nil {
+				return err
+			}
+		}
+		if err := os.Rename(file, db.path); err != nil {
+			if keep != "" {
+				_ = os.Rename(keep, db.path)
+			}
+			return err
+		}
+		db.cache.Clear()
+		return syncDir(filepath.Dir(db.path))
+	}
+}
+
+// reload rebuilds the in-memory state derived from the database file after a swap
+func (db *DB) reload() error {
+	db.Schemas.Reset()
+	if db.wal != nil {
+		// The log describes writes to the replaced file
+		if err := db.wal.Truncate(); err != nil {
+			return err
+		}
+	}
+	if err := db.init(); err != nil {
+		return err
+	}
+	return db.bloomFilters.RebuildAll(db)
+}
+
+// invalidOnError marks read errors of a decompressing reader as a broken backup or dump
+type invalidOnError struct {
+	r       io.Reader
+	invalid error // ErrInvalidBackup or ErrInvalidDump
+}
+
+func (ir invalidOnError) Read(p []byte) (int, error) {
+	n, err := ir.r.Read(p)
+	if err != nil && err != io.EOF {
+		err = fmt.Errorf("%w: %v", ir.invalid, err)
+	}
+	return n, err
+}
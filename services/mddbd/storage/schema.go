@@ -0,0 +1,287 @@
+package storage
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"sync"
+
+	json "github.com/goccy/go-json"
+	bolt "go.etcd.io/bbolt"
+)
+
+// CollectionSchema describes the documents a collection accepts.
+// An empty schema (or no schema at all) keeps the collection schemaless.
+type CollectionSchema struct {
+	Collection     string              `json:"collection"`
+	RequiredMeta   []string            `json:"requiredMeta"`   // meta keys every document must carry
+	OptionalMeta   []string            `json:"optionalMeta"`   // further meta keys that are allowed
+	Enums          map[string][]string `json:"enums"`          // meta key -> allowed values
+	Patterns       map[string]string   `json:"patterns"`       // meta key -> regexp every value must match
+	MaxContentSize int                 `json:"maxContentSize"` // max contentMd size in bytes (0 = unlimited)
+	RequiredLangs  []string            `json:"requiredLangs"`  // languages a key must exist in before others are added
+}
+
+// ValidationError lists every schema violation found in a document
+type ValidationError struct {
+	Key      string
+	Lang     string
+	Problems []string
+}
+
+func (e *ValidationError) Error() string {
+	return "schema validation failed: " + strings.Join(e.Problems, "; ")
+}
+
+// compiledSchema is a CollectionSchema with lookup sets and compiled patterns
+type compiledSchema struct {
+	schema   CollectionSchema
+	known    map[string]bool // nil = any meta key allowed
+	enums    map[string]map[string]bool
+	patterns map[string]*regexp.Regexp
+	langs    map[string]bool // required languages
+}
+
+// SchemaRegistry keeps compiled collection schemas in memory, backed by the schema bucket
+type SchemaRegistry struct {
+	db      *DB
+	mu      sync.RWMutex
+	schemas map[string]*compiledSchema // collection -> schema (nil = schemaless)
+}
+
+// NewSchemaRegistry creates a new schema registry
+func NewSchemaRegistry(db *DB) *SchemaRegistry {
+	return &SchemaRegistry{
+		db:      db,
+		schemas: make(map[string]*compiledSchema),
+	}
+}
+
+// compileSchema validates a schema definition and prepares it for fast checks
+func compileSchema(s CollectionSchema) (*compiledSchema, error) {
+	cs := &compiledSchema{
+		schema:   s,
+		enums:    make(map[string]map[string]bool, len(s.Enums)),
+		patterns: make(map[string]*regexp.Regexp, len(s.Patterns)),
+	}
+	if s.MaxContentSize < 0 {
+		return nil, errors.New("maxContentSize must not be negative")
+	}
+	if len(s.RequiredMeta)+len(s.OptionalMeta) > 0 {
+		cs.known = make(map[string]bool)
+		for _, k := range s.RequiredMeta {
+			cs.known[k] = true
+		}
+		for _, k := range s.OptionalMeta {
+			cs.known[k] = true
+		}
+	}
+	for k, vals := range s.Enums {
+		set := make(map[string]bool, len(vals))
+		for _, v := range vals {
+			set[v] = true
+		}
+		cs.enums[k] = set
+	}
+	for k, p := range s.Patterns {
+		re, err := regexp.Compile(p)
+		if err != nil {
+			return nil, fmt.Errorf("pattern for %q: %w", k, err)
+		}
+		cs.patterns[k] = re
+	}
+	if len(s.RequiredLangs) > 0 {
+		cs.langs = make(map[string]bool, len(s.RequiredLangs))
+		for _, l := range s.RequiredLangs {
+			cs.langs[l] = true
+		}
+	}
+	return cs, nil
+}
+
+// validate checks a document against the schema and collects all problems
+func (cs *compiledSchema) validate(key, lang string, meta map[string][]string, contentMD string) error {
+	var problems []string
+
+	for _, k := range cs.schema.RequiredMeta {
+		if len(meta[k]) == 0 {
+			problems = append(problems, fmt.Sprintf("missing required meta %q", k))
+		}
+	}
+
+	// Sorted for stable error messages
+	keys := make([]string, 0, len(meta))
+	for k := range meta {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+
+	for _, k := range keys {
+		if cs.known != nil && !cs.known[k] {
+			problems = append(problems, fmt.Sprintf("unknown meta key %q", k))
+			continue
+		}
+		for _, v := range meta[k] {
+			if allowed, ok := cs.enums[k]; ok && !allowed[v] {
+				problems = append(problems, fmt.Sprintf("meta %q: value %q not allowed", k, v))
+			}
+			if re, ok := cs.patterns[k]; ok && !re.MatchString(v) {
+				problems = append(problems, fmt.Sprintf("meta %q: value %q does not match %s", k, v, re.String()))
+			}
+		}
+	}
+
+	if cs.schema.MaxContentSize > 0 && len(contentMD) > cs.schema.MaxContentSize {
+		problems = append(problems, fmt.Sprintf("content size %d exceeds max %d", len(contentMD), cs.schema.MaxContentSize))
+	}
+
+	if len(problems) > 0 {
+		return &ValidationError{Key: key, Lang: lang, Problems: problems}
+	}
+	return nil
+}
+
+// lookup returns the compiled schema for a collection, loading it from the database on first use
+func (sr *SchemaRegistry) lookup(collection string) (*compiledSchema, error) {
+	sr.mu.RLock()
+	cs, ok := sr.schemas[collection]
+	sr.mu.RUnlock()
+	if ok {
+		return cs, nil
+	}
+
+	err := sr.db.view(func(tx *bolt.Tx) error {
+		var err error
+		cs, err = sr.lookupTx(tx, collection)
+		return err
+	})
+	return cs, err
+}
+
+// lookupTx is lookup for callers that already hold a transaction
+func (sr *SchemaRegistry) lookupTx(tx *bolt.Tx, collection string) (*compiledSchema, error) {
+	sr.mu.RLock()
+	cs, ok := sr.schemas[collection]
+	sr.mu.RUnlock()
+	if ok {
+		return cs, nil
+	}
+
+	if v := tx.Bucket(sr.db.buckets.Schema).Get([]byte(collection)); v != nil {
+		var s CollectionSchema
+		if err := json.Unmarshal(v, &s); err != nil {
+			return nil, err
+		}
+		var err error
+		if cs, err = compileSchema(s); err != nil {
+			return nil, err
+		}
+	}
+
+	sr.mu.Lock()
+	sr.schemas[collection] = cs
+	sr.mu.Unlock()
+	return cs, nil
+}
+
+// Validate checks a document against its collection schema (no-op for schemaless collections)
+func (sr *SchemaRegistry) Validate(collection, key, lang string, meta map[string][]string, contentMD string) error {
+	cs, err := sr.lookup(collection)
+	if err != nil {
+		return err
+	}
+	if cs == nil {
+		return nil
+	}
+	return cs.validate(key, lang, meta, contentMD)
+}
+
+// checkLangs rejects a document in a language outside requiredLangs while its key is
+// missing in one of the required languages. It runs inside the write transaction, so
+// documents written earlier in the same batch count.
+func (sr *SchemaRegistry) checkLangs(tx *bolt.Tx, collection, key, lang string) error {
+	cs, err := sr.lookupTx(tx, collection)
+	if err != nil || cs == nil || cs.langs == nil || cs.langs[lang] {
+		return err
+	}
+	var problems []string
+	bByK := tx.Bucket(sr.db.buckets.ByKey)
+	for _, l := range cs.schema.RequiredLangs {
+		if bByK.Get(kByKey(collection, key, l)) == nil {
+			problems = append(problems, fmt.Sprintf("missing required lang %q", l))
+		}
+	}
+	if len(problems) > 0 {
+		return &ValidationError{Key: key, Lang: lang, Problems: problems}
+	}
+	return nil
+}
+
+// Get returns the stored schema for a collection, or nil if it has none
+func (sr *SchemaRegistry) Get(collection string) (*CollectionSchema, error) {
+	cs, err := sr.lookup(collection)
+	if err != nil || cs == nil {
+		return nil, err
+	}
+	s := cs.schema
+	return &s, nil
+}
+
+// Set stores (or replaces) the schema of a collection
+func (sr *SchemaRegistry) Set(s CollectionSchema) error {
+	if s.Collection == "" {
+		return errors.New("missing collection")
+	}
+	cs, err := compileSchema(s)
+	if err != nil {
+		return err
+	}
+	buf, err := json.Marshal(s)
+	if err != nil {
+		return err
+	}
+	err = sr.db.update(func(tx *bolt.Tx) error {
+		sr.db.logKey(sr.db.buckets.Schema, s.Collection)
+		return tx.Bucket(sr.db.buckets.Schema).Put([]byte(s.Collection), buf)
+	})
+	if err != nil {
+		return err
+	}
+
+	sr.mu.Lock()
+	sr.schemas[s.Collection] = cs
+	sr.mu.Unlock()
+	return nil
+}
+
+// Delete removes the schema of a collection, making it schemaless again
+func (sr *SchemaRegistry) Delete(collection string) error {
+	err := sr.db.update(func(tx *bolt.Tx) error {
+		sr.db.logKey(sr.db.buckets.Schema, collection)
+		return tx.Bucket(sr.db.buckets.Schema).Delete([]byte(collection))
+	})
+	if err != nil {
+		return err
+	}
+
+	sr.mu.Lock()
+	delete(sr.schemas, collection)
+	sr.mu.Unlock()
+	return nil
+}
+
+// Forget drops the compiled schema of one collection so it is reloaded on next use
+func (sr *SchemaRegistry) Forget(collection string) {
+	sr.mu.Lock()
+	delete(sr.schemas, collection)
+	sr.mu.Unlock()
+}
+
+// Reset drops all compiled schemas so they are reloaded from the database (e.g. after restore)
+func (sr *SchemaRegistry) Reset() {
+	sr.mu.Lock()
+	sr.schemas = make(map[string]*compiledSchema)
+	sr.mu.Unlock()
+}
@@ -0,0 +1,132 @@
+package storage
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestCompileSchemaRejectsBadRules(t *testing.T) {
+	for name, s := range map[string]CollectionSchema{
+		"negative size": {Collection: "blog", MaxContentSize: -1},
+		"bad pattern":   {Collection: "blog", Patterns: map[string]string{"title": "("}},
+	} {
+		if _, err := compileSchema(s); err == nil {
+			t.Errorf("%s: compiled without an error", name)
+		}
+	}
+}
+
+func TestSchemaValidate(t *testing.T) {
+	cs, err := compileSchema(CollectionSchema{
+		Collection:     "blog",
+		RequiredMeta:   []string{"title"},
+		OptionalMeta:   []string{"category"},
+		Enums:          map[string][]string{"category": {"blog", "news"}},
+		Patterns:       map[string]string{"title": "^[A-Z]"},
+		MaxContentSize: 10,
+		RequiredLangs:  []string{"en"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	cases := []struct {
+		name     string
+		lang     string
+		meta     map[string][]string
+		content  string
+		problems []string
+	}{
+		{name: "valid", lang: "en", meta: map[string][]string{"title": {"Hello"}, "category": {"news"}}},
+		{name: "any lang", lang: "de", meta: map[string][]string{"title": {"Hallo"}}},
+		{name: "missing required", lang: "en", meta: map[string][]string{"category": {"blog"}}, problems: []string{`missing required meta "title"`}},
+		{name: "typo", lang: "en", meta: map[string][]string{"title": {"Hello"}, "categroy": {"blog"}}, problems: []string{`unknown meta key "categroy"`}},
+		{name: "enum", lang: "en", meta: map[string][]string{"title": {"Hello"}, "category": {"misc"}}, problems: []string{`meta "category": value "misc" not allowed`}},
+		{name: "pattern", lang: "en", meta: map[string][]string{"title": {"hello"}}, problems: []string{`meta "title": value "hello" does not match ^[A-Z]`}},
+		{name: "content size", lang: "en", meta: map[string][]string{"title": {"Hello"}}, content: "# too long!", problems: []string{"content size 11 exceeds max 10"}},
+		{
+			name:     "every problem",
+			lang:     "en",
+			meta:     map[string][]string{"title": {"hello"}, "tags": {"a"}},
+			content:  "# too long!",
+			problems: []string{`unknown meta key "tags"`, `meta "title": value "hello" does not match ^[A-Z]`, "content size 11 exceeds max 10"},
+		},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			err := cs.validate("post", c.lang, c.meta, c.content)
+			if c.problems == nil {
+				if err != nil {
+					t.Fatalf("validate: %v", err)
+				}
+				return
+			}
+			var verr *ValidationError
+			if !errors.As(err, &verr) {
+				t.Fatalf("validate: %v, want a ValidationError", err)
+			}
+			if !reflect.DeepEqual(verr.Problems, c.problems) {
+				t.Errorf("problems %q, want %q", verr.Problems, c.problems)
+			}
+		})
+	}
+}
+
+func TestSchemaRequiredLangs(t *testing.T) {
+	for _, extreme := range []bool{false, true} {
+		db, err := Open(filepath.Join(t.TempDir(), "mddb.db"), &Options{Extreme: extreme})
+		if err != nil {
+			t.Fatal(err)
+		}
+		t.Cleanup(func() { _ = db.Close() })
+		if err := db.Schemas.Set(CollectionSchema{Collection: "blog", RequiredLangs: []string{"en", "pl"}}); err != nil {
+			t.Fatal(err)
+		}
+		add := func(key, lang string) error {
+			_, err := db.Add(AddRequest{Collection: "blog", Key: key, Lang: lang, ContentMD: "# " + key})
+			return err
+		}
+
+		// A translation needs every required language first
+		if err := add("post", "de"); !isValidationError(err, `missing required lang "en"; missing required lang "pl"`) {
+			t.Fatalf("Add(de) without en and pl: %v", err)
+		}
+		if err := add("post", "en"); err != nil {
+			t.Fatal(err)
+		}
+		if err := add("post", "de"); !isValidationError(err, `missing required lang "pl"`) {
+			t.Fatalf("Add(de) without pl: %v", err)
+		}
+		if err := add("post", "pl"); err != nil {
+			t.Fatal(err)
+		}
+		if err := add("post", "de"); err != nil {
+			t.Fatalf("Add(de) with en and pl: %v", err)
+		}
+
+		// Batches see the documents written before them in the same batch
+		res, err := db.AddBatch(context.Background(), "blog", []BatchDocument{
+			{Key: "early", Lang: "de", ContentMD: "# early"},
+			{Key: "batch", Lang: "en", ContentMD: "# batch"},
+			{Key: "batch", Lang: "pl", ContentMD: "# batch"},
+			{Key: "batch", Lang: "de", ContentMD: "# batch"},
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		if res.Added != 3 || res.Failed != 1 || len(res.Errors) != 1 || !strings.HasPrefix(res.Errors[0], "early/de: ") {
+			t.Errorf("extreme=%v: AddBatch = %+v, want 3 added and early/de failed", extreme, res)
+		}
+		if _, err := db.Get("blog", "batch", "de"); err != nil {
+			t.Errorf("extreme=%v: Get(batch/de): %v", extreme, err)
+		}
+	}
+}
+
+func isValidationError(err error, msg string) bool {
+	var verr *ValidationError
+	return errors.As(err, &verr) && strings.Join(verr.Problems, "; ") == msg
+}
@@ -1,4 +1,4 @@
-package main
+package storage
 
 import (
 	"fmt"
@@ -19,7 +19,7 @@ type ShardCluster struct {
 type Shard struct {
 	ID       int
 	Name     string
-	Server   *Server
+	DB   *DB
 	Weight   int
 	Active   bool
 	DocCount atomic.Uint64
@@ -1,7 +1,8 @@
-package main
+package storage
 
 import (
-	pb "mddb/proto"
+	json "github.com/goccy/go-json"
+	pb "github.com/tradik/mddb/services/mddbd/proto"
 	"google.golang.org/protobuf/proto"
 )
 
@@ -17,8 +18,28 @@ func marshalDoc(doc *Doc) ([]byte, error) {
 	return compressDoc(data), nil
 }
 
-// Unmarshal document from protobuf bytes with decompression support
+// Marshal document to protobuf bytes using a collection compression setting
+func marshalDocWith(doc *Doc, compression string) ([]byte, error) {
+	protoDoc := docToProtoInternal(doc)
+	data, err := proto.Marshal(protoDoc)
+	if err != nil {
+		return nil, err
+	}
+	
+	return compressDocWith(data, compression), nil
+}
+
+// Unmarshal document from protobuf bytes with decompression support.
+// Documents stored as JSON by the HTTP API are decoded as well.
 func unmarshalDoc(data []byte) (*Doc, error) {
+	if len(data) > 0 && data[0] == '{' {
+		var d Doc
+		if err := json.Unmarshal(data, &d); err != nil {
+			return nil, err
+		}
+		return &d, nil
+	}
+	
 	// Decompress if needed
 	decompressed, err := decompressDoc(data)
 	if err != nil {
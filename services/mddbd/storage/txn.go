@@ -0,0 +1,203 @@
+package storage
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	bolt "go.etcd.io/bbolt"
+)
+
+// MaxTxOps limits the operations of one transaction
+const MaxTxOps = 1000
+
+// Transaction operation names, as reported in TxResult.Op
+const (
+	TxAdd    = "add"
+	TxPatch  = "patch"
+	TxDelete = "delete"
+)
+
+var (
+	ErrInvalidTransaction = errors.New("invalid transaction")
+	ErrPreconditionFailed = errors.New("precondition failed")
+)
+
+// DeleteRequest removes a document
+type DeleteRequest struct {
+	Collection string `json:"collection"`
+	Key        string `json:"key"`
+	Lang       string `json:"lang"` // empty = the collection's default language
+}
+
+// Precondition must hold for the document when its operation runs, after the earlier
+// operations of the transaction
+type Precondition struct {
+	Exists  *bool  `json:"exists,omitempty"`  // the document must (true) or must not (false) exist
+	IfMatch string `json:"ifMatch,omitempty"` // the document must exist with this ETag (strong comparison, W/ never matches)
+}
+
+// TxOp is one operation of a transaction; exactly one of Add, Patch and Delete is set
+type TxOp struct {
+	Add          *AddRequest    `json:"add,omitempty"`
+	Patch        *PatchRequest  `json:"patch,omitempty"`
+	Delete       *DeleteRequest `json:"delete,omitempty"`
+	Precondition *Precondition  `json:"precondition,omitempty"`
+}
+
+// TxResult is the outcome of one operation of a committed transaction
+type TxResult struct {
+	Op         string `json:"op"`
+	Collection string `json:"collection"`
+	Key        string `json:"key"`
+	Lang       string `json:"lang"`
+	Doc        *Doc   `json:"doc,omitempty"`     // the written document (add, patch)
+	Created    bool   `json:"created,omitempty"` // add created a new document
+}
+
+// TxError reports the operation that aborted a transaction
+type TxError struct {
+	Index int
+	Op    string
+	Err   error
+}
+
+func (e *TxError) Error() string {
+	if e.Op == "" {
+		return fmt.Sprintf("op %d: %v", e.Index, e.Err)
+	}
+	return fmt.Sprintf("op %d (%s): %v", e.Index, e.Op, e.Err)
+}
+
+func (e *TxError) Unwrap() error { return e.Err }
+
+// check returns ErrPreconditionFailed unless existing (nil = missing) satisfies p
+func (p *Precondition) check(existing *Doc) error {
+	if p == nil {
+		return nil
+	}
+	if p.Exists != nil && *p.Exists != (existing != nil) {
+		if existing != nil {
+			return fmt.Errorf("%w: document exists", ErrPreconditionFailed)
+		}
+		return fmt.Errorf("%w: document does not exist", ErrPreconditionFailed)
+	}
+	if p.IfMatch != "" && (existing == nil || p.IfMatch != existing.ETag()) {
+		return fmt.Errorf("%w: etag mismatch", ErrPreconditionFailed)
+	}
+	return nil
+}
+
+// name returns the operation name and checks that exactly one operation is set
+func (op *TxOp) name() (string, error) {
+	var names []string
+	if op.Add != nil {
+		names = append(names, TxAdd)
+	}
+	if op.Patch != nil {
+		names = append(names, TxPatch)
+	}
+	if op.Delete != nil {
+		names = append(names, TxDelete)
+	}
+	if len(names) != 1 {
+		return "", fmt.Errorf("%w: need exactly one of add, patch, delete", ErrInvalidTransaction)
+	}
+	return names[0], nil
+}
+
+// prepare resolves the language and validates op before the write transaction starts
+func (db *DB) prepare(op *TxOp) (string, error) {
+	name, err := op.name()
+	if err != nil {
+		return "", err
+	}
+	switch name {
+	case TxAdd:
+		req := *op.Add
+		req.Lang = db.Collections.DefaultLang(req.Collection, req.Lang)
+		req.AsyncIndex = false
+		if req.Collection == "" || req.Key == "" || req.Lang == "" {
+			return name, ErrMissingFields
+		}
+		if err := db.Schemas.Validate(req.Collection, req.Key, req.Lang, req.Meta, req.ContentMD); err != nil {
+			return name, err
+		}
+		op.Add = &req
+	case TxPatch:
+		req := *op.Patch
+		req.Lang = db.Collections.DefaultLang(req.Collection, req.Lang)
+		if req.Collection == "" || req.Key == "" || req.Lang == "" {
+			return name, ErrMissingFields
+		}
+		if err := req.validate(); err != nil {
+			return name, err
+		}
+		op.Patch = &req
+	case TxDelete:
+		req := *op.Delete
+		req.Lang = db.Collections.DefaultLang(req.Collection, req.Lang)
+		if req.Collection == "" || req.Key == "" || req.Lang == "" {
+			return name, ErrMissingFields
+		}
+		op.Delete = &req
+	}
+	return name, nil
+}
+
+// Transaction runs ops in order in a single write transaction: either all of them are
+// committed or none. Later operations see the writes of earlier ones, also in their
+// preconditions. A failing operation aborts the transaction with a *TxError.
+func (db *DB) Transaction(ops []TxOp) ([]TxResult, error) {
+	if len(ops) == 0 {
+		return nil, fmt.Errorf("%w: no operations", ErrInvalidTransaction)
+	}
+	if len(ops) > MaxTxOps {
+		return nil, fmt.Errorf("%w: more than %d operations", ErrInvalidTransaction, MaxTxOps)
+	}
+
+	ops = append([]TxOp(nil), ops...)
+	results := make([]TxResult, len(ops))
+	for i := range ops {
+		name, err := db.prepare(&ops[i])
+		if err != nil {
+			return nil, &TxError{Index: i, Op: name, Err: err}
+		}
+		results[i].Op = name
+	}
+
+	now := time.Now().Unix()
+	err := db.update(func(tx *bolt.Tx) error {
+		for i, op := range ops {
+			r := &results[i]
+			var check func(existing *Doc) error
+			if op.Precondition != nil {
+				check = op.Precondition.check
+			}
+			var err error
+			switch r.Op {
+			case TxAdd:
+				var doc Doc
+				r.Collection, r.Key, r.Lang = op.Add.Collection, op.Add.Key, op.Add.Lang
+				doc, r.Created, err = db.putTx(tx, *op.Add, check, now)
+				r.Doc = &doc
+			case TxPatch:
+				var doc Doc
+				r.Collection, r.Key, r.Lang = op.Patch.Collection, op.Patch.Key, op.Patch.Lang
+				doc, err = db.patchTx(tx, *op.Patch, check, now)
+				r.Doc = &doc
+			case TxDelete:
+				r.Collection, r.Key, r.Lang = op.Delete.Collection, op.Delete.Key, op.Delete.Lang
+				err = db.deleteTx(tx, r.Collection, r.Key, r.Lang, check)
+			}
+			if err != nil {
+				return &TxError{Index: i, Op: r.Op, Err: err}
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return results, nil
+}
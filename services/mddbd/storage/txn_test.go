@@ -0,0 +1,121 @@
+package storage
+
+import (
+	"errors"
+	"path/filepath"
+	"testing"
+)
+
+func openTxnDB(t *testing.T) *DB {
+	t.Helper()
+	db, err := Open(filepath.Join(t.TempDir(), "mddb.db"), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { _ = db.Close() })
+	return db
+}
+
+func txAdd(key string, pc *Precondition) TxOp {
+	return TxOp{Add: &AddRequest{Collection: "blog", Key: key, Lang: "en", ContentMD: "# " + key}, Precondition: pc}
+}
+
+func txPatch(key, appendMD string, pc *Precondition) TxOp {
+	return TxOp{Patch: &PatchRequest{Collection: "blog", Key: key, Lang: "en", AppendMD: appendMD, SaveRevision: true}, Precondition: pc}
+}
+
+func txDelete(key string, pc *Precondition) TxOp {
+	return TxOp{Delete: &DeleteRequest{Collection: "blog", Key: key, Lang: "en"}, Precondition: pc}
+}
+
+func TestTransactionRollsBack(t *testing.T) {
+	db := openTxnDB(t)
+	addDoc(t, db, "kept")
+
+	_, err := db.Transaction([]TxOp{txAdd("new", nil), txDelete("kept", nil), txPatch("missing", "!", nil)})
+	var txErr *TxError
+	if !errors.As(err, &txErr) || txErr.Index != 2 || txErr.Op != TxPatch || !errors.Is(err, ErrNotFound) {
+		t.Fatalf("Transaction: %v, want op 2 (patch) not found", err)
+	}
+	if _, err := db.Get("blog", "new", "en"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("Get(new) after the rollback: %v, want not found", err)
+	}
+	if _, err := db.Get("blog", "kept", "en"); err != nil {
+		t.Errorf("Get(kept) after the rollback: %v", err)
+	}
+}
+
+func TestTransactionPreconditions(t *testing.T) {
+	yes, no := true, false
+	cases := []struct {
+		name string
+		op   func(etag string) TxOp
+		err  error
+	}{
+		{name: "add if missing, exists", op: func(string) TxOp { return txAdd("post", &Precondition{Exists: &no}) }, err: ErrPreconditionFailed},
+		{name: "add if missing", op: func(string) TxOp { return txAdd("other", &Precondition{Exists: &no}) }},
+		{name: "add if present, missing", op: func(string) TxOp { return txAdd("other", &Precondition{Exists: &yes}) }, err: ErrPreconditionFailed},
+		{name: "patch if match", op: func(etag string) TxOp { return txPatch("post", "!", &Precondition{IfMatch: etag}) }},
+		{name: "patch if match, weak", op: func(etag string) TxOp { return txPatch("post", "!", &Precondition{IfMatch: "W/" + etag}) }, err: ErrPreconditionFailed},
+		{name: "patch if match, stale", op: func(string) TxOp { return txPatch("post", "!", &Precondition{IfMatch: `"stale"`}) }, err: ErrPreconditionFailed},
+		{name: "patch if match, missing", op: func(etag string) TxOp { return txPatch("other", "!", &Precondition{IfMatch: etag}) }, err: ErrPreconditionFailed},
+		{name: "patch missing", op: func(string) TxOp { return txPatch("other", "!", nil) }, err: ErrNotFound},
+		{name: "delete if present", op: func(string) TxOp { return txDelete("post", &Precondition{Exists: &yes}) }},
+		{name: "delete if missing, exists", op: func(string) TxOp { return txDelete("post", &Precondition{Exists: &no}) }, err: ErrPreconditionFailed},
+		{name: "delete if missing", op: func(string) TxOp { return txDelete("other", &Precondition{Exists: &no}) }},
+		{name: "delete missing", op: func(string) TxOp { return txDelete("other", nil) }, err: ErrNotFound},
+	}
+	for _, c := range cases {
+		t.Run(c.name, func(t *testing.T) {
+			db := openTxnDB(t)
+			doc, err := db.Add(AddRequest{Collection: "blog", Key: "post", Lang: "en", ContentMD: "# post"})
+			if err != nil {
+				t.Fatal(err)
+			}
+			_, err = db.Transaction([]TxOp{c.op(doc.ETag())})
+			if !errors.Is(err, c.err) {
+				t.Errorf("Transaction: %v, want %v", err, c.err)
+			}
+		})
+	}
+}
+
+func TestTransactionSameDocument(t *testing.T) {
+	db := openTxnDB(t)
+	yes := true
+	res, err := db.Transaction([]TxOp{
+		txAdd("post", nil),
+		txPatch("post", " one", &Precondition{Exists: &yes}), // sees the add
+		txPatch("post", " two", nil),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !res[0].Created || res[2].Doc.ContentMD != "# post one two" {
+		t.Errorf("results %+v", res)
+	}
+
+	// Each patch keeps its own revision although the transaction has a single timestamp
+	revs, err := db.Revisions("blog", "post", "en")
+	if err != nil {
+		t.Fatal(err)
+	}
+	var got []string
+	for _, r := range revs {
+		got = append(got, r.Doc.ContentMD)
+		if r.SavedAt != res[2].Doc.UpdatedAt {
+			t.Errorf("revision saved at %d, want %d", r.SavedAt, res[2].Doc.UpdatedAt)
+		}
+	}
+	if len(got) != 2 || got[0] != "# post one" || got[1] != "# post one two" {
+		t.Errorf("revisions %q, want both patches in order", got)
+	}
+
+	// Deleted and added again in one go
+	if _, err := db.Transaction([]TxOp{txDelete("post", nil), txAdd("post", nil)}); err != nil {
+		t.Fatal(err)
+	}
+	if revs, err := db.Revisions("blog", "post", "en"); err != nil || len(revs) != 0 {
+		t.Errorf("revisions after delete and add: %d, %v; want none", len(revs), err)
+	}
+}
@@ -0,0 +1,63 @@
+package storage
+
+import (
+	"sort"
+	"strings"
+)
+
+func safe(s string) string {
+	return strings.Map(func(r rune) rune {
+		if (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z') || (r >= '0' && r <= '9') || r == '-' || r == '_' {
+			return r
+		}
+		return '-'
+	}, s)
+}
+func unique(in []string) []string {
+	m := map[string]struct{}{}
+	out := make([]string, 0, len(in))
+	for _, x := range in {
+		if _, ok := m[x]; !ok {
+			m[x] = struct{}{}
+			out = append(out, x)
+		}
+	}
+	return out
+}
+func intersect(sets ...[]string) []string {
+	if len(sets) == 0 {
+		return nil
+	}
+	m := map[string]int{}
+	for _, s := range sets {
+		for _, id := range s {
+			m[id]++
+		}
+	}
+	out := []string{}
+	for id, c := range m {
+		if c == len(sets) {
+			out = append(out, id)
+		}
+	}
+	return out
+}
+func sortDocs(docs []Doc, field string, asc bool) {
+	sort.Slice(docs, func(i, j int) bool {
+		var less bool
+		switch field {
+		case "addedAt":
+			less = docs[i].AddedAt < docs[j].AddedAt
+		case "updatedAt":
+			less = docs[i].UpdatedAt < docs[j].UpdatedAt
+		case "key":
+			less = docs[i].Key < docs[j].Key
+		default:
+			less = docs[i].UpdatedAt < docs[j].UpdatedAt
+		}
+		if asc {
+			return less
+		}
+		return !less
+	})
+}
@@ -1,4 +1,4 @@
-package main
+package storage
 
 import (
 	"bufio"
@@ -51,12 +51,17 @@ const (
 	EntryTypeUpdate EntryType = 2
 	EntryTypeDelete EntryType = 3
 	EntryTypeCommit EntryType = 4
+
+	EntryTypeTimeline EntryType = 5 // change log: the database started a new timeline
 )
 
 // NewWAL creates a new Write-Ahead Log
 func NewWAL(dbPath string, policy SyncPolicy) (*WAL, error) {
-	walPath := filepath.Join(filepath.Dir(dbPath), "mddb.wal")
-	
+	return openWAL(filepath.Join(filepath.Dir(dbPath), "mddb.wal"), policy)
+}
+
+// openWAL opens (creating if needed) the log file at walPath for appending
+func openWAL(walPath string, policy SyncPolicy) (*WAL, error) {
 	file, err := os.OpenFile(walPath, os.O_CREATE|os.O_RDWR|os.O_APPEND, 0644)
 	if err != nil {
 		return nil, fmt.Errorf("failed to open WAL: %w", err)
@@ -184,7 +189,7 @@ func (w *WAL) Read() ([]*WALEntry, error) {
 	var entries []*WALEntry
 	
 	for {
-		entry, err := w.readEntry(reader)
+		entry, err := readWALEntry(reader)
 		if err == io.EOF {
 			break
 		}
@@ -197,8 +202,8 @@ func (w *WAL) Read() ([]*WALEntry, error) {
 	return entries, nil
 }
 
-// readEntry reads a single entry from reader
-func (w *WAL) readEntry(reader *bufio.Reader) (*WALEntry, error) {
+// readWALEntry reads a single entry from reader
+func readWALEntry(reader *bufio.Reader) (*WALEntry, error) {
 	// Read header
 	header := make([]byte, 17) // 1+8+4+4
 	if _, err := io.ReadFull(reader, header); err != nil {
@@ -229,6 +234,13 @@ func (w *WAL) readEntry(reader *bufio.Reader) (*WALEntry, error) {
 	}, nil
 }
 
+// Sync flushes buffered entries to disk
+func (w *WAL) Sync() error {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.flush()
+}
+
 // Truncate truncates the WAL (after successful checkpoint)
 func (w *WAL) Truncate() error {
 	w.mu.Lock()
@@ -0,0 +1,149 @@
+package main
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"os"
+	"sync"
+	"time"
+)
+
+// tlsReloadInterval is how often certificate files are checked for changes
+const tlsReloadInterval = 10 * time.Second
+
+// TLSFiles holds the current certificate, key and client CA of all listeners. Files are
+// re-read when they change on disk, so renewed certificates apply to new connections
+// without a restart.
+type TLSFiles struct {
+	certFile   string
+	keyFile    string
+	caFile     string // client CA for mTLS; empty = no client certificates
+	clientAuth tls.ClientAuthType
+
+	mu      sync.RWMutex
+	cert    *tls.Certificate
+	pool    *x509.CertPool
+	modTime time.Time // latest modification time of the loaded files
+}
+
+// newTLS loads the certificate files of the validated TLS settings. It returns nil when
+// no certificate is configured.
+func newTLS(cfg TLSConfig) (*TLSFiles, error) {
+	if cfg.Cert == "" {
+		return nil, nil
+	}
+	t := &TLSFiles{certFile: cfg.Cert, keyFile: cfg.Key, caFile: cfg.ClientCA}
+	switch {
+	case t.caFile == "":
+		t.clientAuth = tls.NoClientCert
+	case cfg.ClientAuth == "optional":
+		t.clientAuth = tls.VerifyClientCertIfGiven
+	default:
+		t.clientAuth = tls.RequireAndVerifyClientCert
+	}
+	if err := t.load(); err != nil {
+		return nil, err
+	}
+	go t.watch(tlsReloadInterval)
+	return t, nil
+}
+
+// files returns the files to load and watch
+func (t *TLSFiles) files() []string {
+	files := []string{t.certFile, t.keyFile}
+	if t.caFile != "" {
+		files = append(files, t.caFile)
+	}
+	return files
+}
+
+// latestModTime returns the newest modification time of the files
+func (t *TLSFiles) latestModTime() (time.Time, error) {
+	var latest time.Time
+	for _, f := range t.files() {
+		fi, err := os.Stat(f)
+		if err != nil {
+			return time.Time{}, err
+		}
+		if fi.ModTime().After(latest) {
+			latest = fi.ModTime()
+		}
+	}
+	return latest, nil
+}
+
+// load reads the files; on error the previously loaded certificate stays in use
+func (t *TLSFiles) load() error {
+	modTime, err := t.latestModTime()
+	if err != nil {
+		return err
+	}
+	cert, err := tls.LoadX509KeyPair(t.certFile, t.keyFile)
+	if err != nil {
+		return fmt.Errorf("load TLS certificate: %w", err)
+	}
+	var pool *x509.CertPool
+	if t.caFile != "" {
+		pem, err := os.ReadFile(t.caFile)
+		if err != nil {
+			return fmt.Errorf("load TLS client CA: %w", err)
+		}
+		pool = x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(pem) {
+			return fmt.Errorf("load TLS client CA: no certificates in %s", t.caFile)
+		}
+	}
+
+	t.mu.Lock()
+	t.cert, t.pool, t.modTime = &cert, pool, modTime
+	t.mu.Unlock()
+	return nil
+}
+
+// watch reloads the files whenever one of them changes
+func (t *TLSFiles) watch(interval time.Duration) {
+	for range time.Tick(interval) {
+		modTime, err := t.latestModTime()
+		t.mu.RLock()
+		changed := err == nil && !modTime.Equal(t.modTime)
+		t.mu.RUnlock()
+		if !changed {
+			continue
+		}
+		if err := t.load(); err != nil {
+			// Retried on the next change, e.g. once both halves of a renewal are written
+			warnf("⚠️  TLS reload failed, keeping the current certificate: %v", err)
+			t.mu.Lock()
+			t.modTime = modTime
+			t.mu.Unlock()
+			continue
+		}
+		infof("🔒 TLS certificate reloaded from %s", t.certFile)
+	}
+}
+
+// MutualTLS reports whether client certificates are verified
+func (t *TLSFiles) MutualTLS() bool {
+	return t.clientAuth != tls.NoClientCert
+}
+
+// Config returns a server config for the given ALPN protocols that picks up the current
+// files on every handshake
+func (t *TLSFiles) Config(nextProtos ...string) *tls.Config {
+	return &tls.Config{
+		MinVersion: tls.VersionTLS12,
+		NextProtos: nextProtos,
+		GetConfigForClient: func(*tls.ClientHelloInfo) (*tls.Config, error) {
+			t.mu.RLock()
+			defer t.mu.RUnlock()
+			return &tls.Config{
+				MinVersion:   tls.VersionTLS12,
+				NextProtos:   nextProtos,
+				Certificates: []tls.Certificate{*t.cert},
+				ClientCAs:    t.pool,
+				ClientAuth:   t.clientAuth,
+			}, nil
+		},
+	}
+}
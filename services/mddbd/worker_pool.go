@@ -5,7 +5,7 @@ import (
 	"sync"
 	"time"
 
-	proto "mddb/proto"
+	proto "github.com/tradik/mddb/services/mddbd/proto"
 )
 
 // WorkerPool manages a pool of workers for processing requests